@@ -9,8 +9,10 @@ const (
 
 	// Feature constants.
 
-	FeatureSnapshotRestore      = "SnapshotRestore"
-	FeatureSnapshotMirrorUpdate = "SnapshotMirrorUpdate"
-	FeatureReadOnlyClone        = "ReadOnlyClone"
-	FeatureInflightEncryption   = "InflightEncryption"
+	FeatureSnapshotRestore         = "SnapshotRestore"
+	FeatureSnapshotMirrorUpdate    = "SnapshotMirrorUpdate"
+	FeatureReadOnlyClone           = "ReadOnlyClone"
+	FeatureInflightEncryption      = "InflightEncryption"
+	FeatureVolumeShrink            = "VolumeShrink"
+	FeatureApplicationVolumeGroups = "ApplicationVolumeGroups"
 )
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -146,6 +147,8 @@ func WriteBackends(backends []storage.BackendExternal) {
 		WriteYAML(api.MultipleBackendResponse{Items: backends})
 	case FormatName:
 		writeBackendNames(backends)
+	case FormatWide:
+		writeWideBackendTable(backends)
 	default:
 		writeBackendTable(backends)
 	}
@@ -220,6 +223,54 @@ func writeBackendTable(backends []storage.BackendExternal) {
 	table.Render()
 }
 
+func writeWideBackendTable(backends []storage.BackendExternal) {
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{
+		"Name",
+		"Storage Driver",
+		"UUID",
+		"State",
+		"User-State",
+		"Volumes",
+		"Cache Age",
+		"Cache Stale",
+	}
+	table.SetHeader(header)
+
+	for _, b := range backends {
+		if b.Config == nil {
+			continue
+		}
+
+		if configAsMap, ok := b.Config.(map[string]interface{}); ok {
+			storageDriverName := configAsMap["storageDriverName"].(string)
+
+			// Only backends that cache discovered resources report on the cache
+			cacheAge, cacheStale := "", ""
+			if b.CacheStatus != nil {
+				cacheAge = b.CacheStatus.Age.Round(time.Second).String()
+				if b.CacheStatus.LastUpdateTime.IsZero() {
+					cacheAge = "never refreshed"
+				}
+				cacheStale = strconv.FormatBool(b.CacheStatus.Stale)
+			}
+
+			table.Append([]string{
+				b.Name,
+				storageDriverName,
+				b.BackendUUID,
+				b.State.String(),
+				b.UserState.String(),
+				strconv.Itoa(len(b.Volumes)),
+				cacheAge,
+				cacheStale,
+			})
+		}
+	}
+
+	table.Render()
+}
+
 func writeBackendNames(backends []storage.BackendExternal) {
 	for _, b := range backends {
 		fmt.Println(b.Name)
@@ -18,19 +18,23 @@ import (
 )
 
 var (
-	snapshotDirectory string
-	poolLevel         string
+	snapshotDirectory      string
+	poolLevel              string
+	upgradeNetworkFeatures string
 )
 
 func init() {
 	snapshotDirFlag := "snapshot-dir"
 	poolLevelFlag := "pool-level"
+	upgradeNetworkFeaturesFlag := "upgrade-network-features"
 
 	updateCmd.AddCommand(updateVolumeCmd)
 	updateVolumeCmd.Flags().StringVarP(&snapshotDirectory, snapshotDirFlag, "", "",
 		"Value of snapshot directory. Allowed values: true|false")
 	updateVolumeCmd.Flags().StringVarP(&poolLevel, poolLevelFlag, "", "false",
 		"Whether update is to be done at pool level. Allowed values: true|false")
+	updateVolumeCmd.Flags().StringVarP(&upgradeNetworkFeatures, upgradeNetworkFeaturesFlag, "", "false",
+		"Whether to upgrade the volume's network features to those of its storage pool. Allowed values: true|false")
 }
 
 var updateVolumeCmd = &cobra.Command{
@@ -38,10 +42,10 @@ var updateVolumeCmd = &cobra.Command{
 	Short:   "Update a volume in Trident",
 	Aliases: []string{"v"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		snapDir, poolLevelVal := snapshotDirectory, poolLevel
+		snapDir, poolLevelVal, upgradeNetworkFeaturesVal := snapshotDirectory, poolLevel, upgradeNetworkFeatures
 
 		// Validate command
-		err := validateCmd(args, snapDir, poolLevelVal)
+		err := validateCmd(args, snapDir, poolLevelVal, upgradeNetworkFeaturesVal)
 		if err != nil {
 			return err
 		}
@@ -51,17 +55,18 @@ var updateVolumeCmd = &cobra.Command{
 				"update", "volume",
 				"--snapshot-dir", snapDir,
 				"--pool-level", poolLevelVal,
+				"--upgrade-network-features", upgradeNetworkFeaturesVal,
 			}
 			out, err := TunnelCommand(append(command, args...))
 			printOutput(cmd, out, err)
 			return err
 		} else {
-			return updateVolume(args[0], snapDir, poolLevelVal)
+			return updateVolume(args[0], snapDir, poolLevelVal, upgradeNetworkFeaturesVal)
 		}
 	},
 }
 
-func validateCmd(args []string, snapshotDir, poolLevel string) error {
+func validateCmd(args []string, snapshotDir, poolLevel, upgradeNetworkFeatures string) error {
 	// Ensure one and only one volume name is passed
 	switch len(args) {
 	case 0:
@@ -72,13 +77,8 @@ func validateCmd(args []string, snapshotDir, poolLevel string) error {
 		return errors.New("multiple volume names specified")
 	}
 
-	// Ensure expected flags are present
-	if snapshotDir == "" {
-		return errors.New("no value for snapshot directory provided")
-	}
-
 	// Ensure flags are of the correct type
-	_, err := strconv.ParseBool(snapshotDir)
+	upgrade, err := strconv.ParseBool(upgradeNetworkFeatures)
 	if err != nil {
 		return err
 	}
@@ -88,17 +88,32 @@ func validateCmd(args []string, snapshotDir, poolLevel string) error {
 		return err
 	}
 
+	// Ensure expected flags are present
+	if snapshotDir == "" {
+		if !upgrade {
+			return errors.New("no value for snapshot directory provided")
+		}
+		return nil
+	}
+
+	_, err = strconv.ParseBool(snapshotDir)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func updateVolume(volumeName, snapDirValue, poolLevelVal string) error {
+func updateVolume(volumeName, snapDirValue, poolLevelVal, upgradeNetworkFeaturesVal string) error {
 	url := BaseURL() + "/volume/" + volumeName
 
 	poolLevelBool, _ := strconv.ParseBool(poolLevelVal)
+	upgradeNetworkFeaturesBool, _ := strconv.ParseBool(upgradeNetworkFeaturesVal)
 
 	request := utils.VolumeUpdateInfo{
-		SnapshotDirectory: snapDirValue,
-		PoolLevel:         poolLevelBool,
+		SnapshotDirectory:      snapDirValue,
+		PoolLevel:              poolLevelBool,
+		UpgradeNetworkFeatures: upgradeNetworkFeaturesBool,
 	}
 
 	requestBytes, err := json.Marshal(request)
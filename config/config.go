@@ -233,6 +233,7 @@ var (
 
 	UsingPassthroughStore bool
 	CurrentDriverContext  DriverContext
+	MetricsEnabled        bool
 	OrchestratorTelemetry = Telemetry{TridentVersion: OrchestratorVersion.String()}
 
 	// CSIAccessModes are defined by CSI
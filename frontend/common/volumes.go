@@ -140,6 +140,7 @@ func GetVolumeConfig(
 		SnapshotReserve:     utils.GetV(opts, "snapshotReserve", ""),
 		SnapshotDir:         utils.GetV(opts, "snapshotDir", ""),
 		ExportPolicy:        utils.GetV(opts, "exportPolicy", ""),
+		ExportRule:          utils.GetV(opts, "exportRule", ""),
 		UnixPermissions:     utils.GetV(opts, "unixPermissions", ""),
 		BlockSize:           utils.GetV(opts, "blocksize", ""),
 		Qos:                 utils.GetV(opts, "qos", ""),
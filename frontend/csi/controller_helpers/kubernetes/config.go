@@ -42,6 +42,7 @@ const (
 	AnnSnapshotDir          = annPrefix + "/snapshotDirectory"
 	AnnUnixPermissions      = annPrefix + "/unixPermissions"
 	AnnExportPolicy         = annPrefix + "/exportPolicy"
+	AnnExportRule           = annPrefix + "/exportRule"
 	AnnBlockSize            = annPrefix + "/blockSize"
 	AnnFileSystem           = annPrefix + "/fileSystem"
 	AnnCloneFromPVC         = annPrefix + "/cloneFromPVC"
@@ -54,6 +55,7 @@ const (
 	AnnVolumeShareFromPVC   = annPrefix + "/shareFromPVC"
 	AnnVolumeShareToNS      = annPrefix + "/shareToNamespace"
 	AnnReadOnlyClone        = annPrefix + "/readOnlyClone"
+	AnnRestoreFromSnapshot  = annPrefix + "/restoreFromSnapshot"
 )
 
 var features = map[controllerhelpers.Feature]*versionutils.Version{
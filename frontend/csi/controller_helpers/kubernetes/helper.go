@@ -118,6 +118,15 @@ func (h *helper) GetVolumeConfig(
 		return nil, err
 	}
 
+	// Record the requesting namespace and its annotations so that drivers may use them, e.g. for chargeback tags.
+	// These are informational only, so failure to read the namespace is not fatal.
+	volumeConfig.Namespace = pvc.Namespace
+	if namespace, err := h.kubeClient.CoreV1().Namespaces().Get(ctx, pvc.Namespace, getOpts); err != nil {
+		Logc(ctx).WithField("namespace", pvc.Namespace).WithError(err).Warning("Could not get namespace annotations.")
+	} else {
+		volumeConfig.NamespaceAnnotations = namespace.Annotations
+	}
+
 	return volumeConfig, nil
 }
 
@@ -585,6 +594,7 @@ func getVolumeConfig(
 		SnapshotReserve:     getAnnotation(annotations, AnnSnapshotReserve),
 		SnapshotDir:         getAnnotation(annotations, AnnSnapshotDir),
 		ExportPolicy:        getAnnotation(annotations, AnnExportPolicy),
+		ExportRule:          getAnnotation(annotations, AnnExportRule),
 		UnixPermissions:     getAnnotation(annotations, AnnUnixPermissions),
 		StorageClass:        storageClass.Name,
 		BlockSize:           getAnnotation(annotations, AnnBlockSize),
@@ -596,6 +606,7 @@ func getVolumeConfig(
 		ImportOriginalName:  getAnnotation(annotations, AnnImportOriginalName),
 		ImportBackendUUID:   getAnnotation(annotations, AnnImportBackendUUID),
 		ImportNotManaged:    notManaged,
+		RestoreFromSnapshot: getAnnotation(annotations, AnnRestoreFromSnapshot),
 		MountOptions:        strings.Join(storageClass.MountOptions, ","),
 		RequisiteTopologies: requisiteTopology,
 		PreferredTopologies: preferredTopology,
@@ -349,6 +349,7 @@ func main() {
 	orchestrator := core.NewTridentOrchestrator(storeClient)
 
 	// Create HTTP metrics frontend
+	config.MetricsEnabled = *enableMetrics
 	if *enableMetrics {
 		if *metricsPort == "" {
 			Log().Warning("HTTP metrics interface will not be available (port not specified).")
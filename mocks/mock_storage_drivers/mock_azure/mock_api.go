@@ -37,6 +37,63 @@ func (m *MockAzure) EXPECT() *MockAzureMockRecorder {
 	return m.recorder
 }
 
+// AuthorizeReplication mocks base method.
+func (m *MockAzure) AuthorizeReplication(arg0 context.Context, arg1 string, arg2 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeReplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeReplication indicates an expected call of AuthorizeReplication.
+func (mr *MockAzureMockRecorder) AuthorizeReplication(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeReplication", reflect.TypeOf((*MockAzure)(nil).AuthorizeReplication), arg0, arg1, arg2)
+}
+
+// BreakReplication mocks base method.
+func (m *MockAzure) BreakReplication(arg0 context.Context, arg1 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BreakReplication", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BreakReplication indicates an expected call of BreakReplication.
+func (mr *MockAzureMockRecorder) BreakReplication(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BreakReplication", reflect.TypeOf((*MockAzure)(nil).BreakReplication), arg0, arg1)
+}
+
+// CacheStatus mocks base method.
+func (m *MockAzure) CacheStatus() *storage.BackendCacheStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CacheStatus")
+	ret0, _ := ret[0].(*storage.BackendCacheStatus)
+	return ret0
+}
+
+// CacheStatus indicates an expected call of CacheStatus.
+func (mr *MockAzureMockRecorder) CacheStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheStatus", reflect.TypeOf((*MockAzure)(nil).CacheStatus))
+}
+
+// CapacityPoolUsedBytes mocks base method.
+func (m *MockAzure) CapacityPoolUsedBytes(arg0 context.Context, arg1 *api.CapacityPool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CapacityPoolUsedBytes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CapacityPoolUsedBytes indicates an expected call of CapacityPoolUsedBytes.
+func (mr *MockAzureMockRecorder) CapacityPoolUsedBytes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapacityPoolUsedBytes", reflect.TypeOf((*MockAzure)(nil).CapacityPoolUsedBytes), arg0, arg1)
+}
+
 // CapacityPools mocks base method.
 func (m *MockAzure) CapacityPools() *[]*api.CapacityPool {
 	m.ctrl.T.Helper()
@@ -79,6 +136,20 @@ func (mr *MockAzureMockRecorder) CapacityPoolsForStoragePools(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapacityPoolsForStoragePools", reflect.TypeOf((*MockAzure)(nil).CapacityPoolsForStoragePools), arg0)
 }
 
+// CreateBackup mocks base method.
+func (m *MockAzure) CreateBackup(arg0 context.Context, arg1 *api.FileSystem, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBackup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBackup indicates an expected call of CreateBackup.
+func (mr *MockAzureMockRecorder) CreateBackup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackup", reflect.TypeOf((*MockAzure)(nil).CreateBackup), arg0, arg1, arg2)
+}
+
 // CreateSnapshot mocks base method.
 func (m *MockAzure) CreateSnapshot(arg0 context.Context, arg1 *api.FileSystem, arg2 string) (*api.Snapshot, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +196,49 @@ func (mr *MockAzureMockRecorder) CreateVolume(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVolume", reflect.TypeOf((*MockAzure)(nil).CreateVolume), arg0, arg1)
 }
 
+// CreateVolumeGroup mocks base method.
+func (m *MockAzure) CreateVolumeGroup(arg0 context.Context, arg1 *api.VolumeGroupCreateRequest) ([]*api.FileSystem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVolumeGroup", arg0, arg1)
+	ret0, _ := ret[0].([]*api.FileSystem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVolumeGroup indicates an expected call of CreateVolumeGroup.
+func (mr *MockAzureMockRecorder) CreateVolumeGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVolumeGroup", reflect.TypeOf((*MockAzure)(nil).CreateVolumeGroup), arg0, arg1)
+}
+
+// DeleteBackup mocks base method.
+func (m *MockAzure) DeleteBackup(arg0 context.Context, arg1 *api.FileSystem, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBackup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBackup indicates an expected call of DeleteBackup.
+func (mr *MockAzureMockRecorder) DeleteBackup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBackup", reflect.TypeOf((*MockAzure)(nil).DeleteBackup), arg0, arg1, arg2)
+}
+
+// DeleteReplication mocks base method.
+func (m *MockAzure) DeleteReplication(arg0 context.Context, arg1 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReplication", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReplication indicates an expected call of DeleteReplication.
+func (mr *MockAzureMockRecorder) DeleteReplication(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplication", reflect.TypeOf((*MockAzure)(nil).DeleteReplication), arg0, arg1)
+}
+
 // DeleteSnapshot mocks base method.
 func (m *MockAzure) DeleteSnapshot(arg0 context.Context, arg1 *api.FileSystem, arg2 *api.Snapshot) error {
 	m.ctrl.T.Helper()
@@ -168,6 +282,20 @@ func (mr *MockAzureMockRecorder) DeleteVolume(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVolume", reflect.TypeOf((*MockAzure)(nil).DeleteVolume), arg0, arg1)
 }
 
+// DeleteVolumeGroup mocks base method.
+func (m *MockAzure) DeleteVolumeGroup(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVolumeGroup", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVolumeGroup indicates an expected call of DeleteVolumeGroup.
+func (mr *MockAzureMockRecorder) DeleteVolumeGroup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVolumeGroup", reflect.TypeOf((*MockAzure)(nil).DeleteVolumeGroup), arg0, arg1, arg2, arg3)
+}
+
 // DiscoverAzureResources mocks base method.
 func (m *MockAzure) DiscoverAzureResources(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -271,6 +399,62 @@ func (mr *MockAzureMockRecorder) ModifyVolume(arg0, arg1, arg2, arg3, arg4, arg5
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolume", reflect.TypeOf((*MockAzure)(nil).ModifyVolume), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// ModifyVolumeCoolAccess mocks base method.
+func (m *MockAzure) ModifyVolumeCoolAccess(arg0 context.Context, arg1 *api.FileSystem, arg2 bool, arg3 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyVolumeCoolAccess", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyVolumeCoolAccess indicates an expected call of ModifyVolumeCoolAccess.
+func (mr *MockAzureMockRecorder) ModifyVolumeCoolAccess(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolumeCoolAccess", reflect.TypeOf((*MockAzure)(nil).ModifyVolumeCoolAccess), arg0, arg1, arg2, arg3)
+}
+
+// ModifyVolumeExportPolicy mocks base method.
+func (m *MockAzure) ModifyVolumeExportPolicy(arg0 context.Context, arg1 *api.FileSystem, arg2 *api.ExportPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyVolumeExportPolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyVolumeExportPolicy indicates an expected call of ModifyVolumeExportPolicy.
+func (mr *MockAzureMockRecorder) ModifyVolumeExportPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolumeExportPolicy", reflect.TypeOf((*MockAzure)(nil).ModifyVolumeExportPolicy), arg0, arg1, arg2)
+}
+
+// ModifyVolumeSnapshotPolicy mocks base method.
+func (m *MockAzure) ModifyVolumeSnapshotPolicy(arg0 context.Context, arg1 *api.FileSystem, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyVolumeSnapshotPolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyVolumeSnapshotPolicy indicates an expected call of ModifyVolumeSnapshotPolicy.
+func (mr *MockAzureMockRecorder) ModifyVolumeSnapshotPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolumeSnapshotPolicy", reflect.TypeOf((*MockAzure)(nil).ModifyVolumeSnapshotPolicy), arg0, arg1, arg2)
+}
+
+// ModifyVolumeThroughput mocks base method.
+func (m *MockAzure) ModifyVolumeThroughput(arg0 context.Context, arg1 *api.FileSystem, arg2 float32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyVolumeThroughput", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyVolumeThroughput indicates an expected call of ModifyVolumeThroughput.
+func (mr *MockAzureMockRecorder) ModifyVolumeThroughput(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolumeThroughput", reflect.TypeOf((*MockAzure)(nil).ModifyVolumeThroughput), arg0, arg1, arg2)
+}
+
 // RandomSubnetForStoragePool mocks base method.
 func (m *MockAzure) RandomSubnetForStoragePool(arg0 context.Context, arg1 storage.Pool) *api.Subnet {
 	m.ctrl.T.Helper()
@@ -299,6 +483,35 @@ func (mr *MockAzureMockRecorder) RefreshAzureResources(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAzureResources", reflect.TypeOf((*MockAzure)(nil).RefreshAzureResources), arg0)
 }
 
+// RelocateVolume mocks base method.
+func (m *MockAzure) RelocateVolume(arg0 context.Context, arg1 *api.FileSystem, arg2 *api.CapacityPool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelocateVolume", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RelocateVolume indicates an expected call of RelocateVolume.
+func (mr *MockAzureMockRecorder) RelocateVolume(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelocateVolume", reflect.TypeOf((*MockAzure)(nil).RelocateVolume), arg0, arg1, arg2)
+}
+
+// ReplicationStatus mocks base method.
+func (m *MockAzure) ReplicationStatus(arg0 context.Context, arg1 *api.FileSystem) (*api.ReplicationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicationStatus", arg0, arg1)
+	ret0, _ := ret[0].(*api.ReplicationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplicationStatus indicates an expected call of ReplicationStatus.
+func (mr *MockAzureMockRecorder) ReplicationStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicationStatus", reflect.TypeOf((*MockAzure)(nil).ReplicationStatus), arg0, arg1)
+}
+
 // ResizeSubvolume mocks base method.
 func (m *MockAzure) ResizeSubvolume(arg0 context.Context, arg1 *api.Subvolume, arg2 int64) error {
 	m.ctrl.T.Helper()
@@ -341,6 +554,20 @@ func (mr *MockAzureMockRecorder) RestoreSnapshot(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSnapshot", reflect.TypeOf((*MockAzure)(nil).RestoreSnapshot), arg0, arg1, arg2)
 }
 
+// ResyncReplication mocks base method.
+func (m *MockAzure) ResyncReplication(arg0 context.Context, arg1 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResyncReplication", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResyncReplication indicates an expected call of ResyncReplication.
+func (mr *MockAzureMockRecorder) ResyncReplication(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResyncReplication", reflect.TypeOf((*MockAzure)(nil).ResyncReplication), arg0, arg1)
+}
+
 // SnapshotForVolume mocks base method.
 func (m *MockAzure) SnapshotForVolume(arg0 context.Context, arg1 *api.FileSystem, arg2 string) (*api.Snapshot, error) {
 	m.ctrl.T.Helper()
@@ -356,6 +583,21 @@ func (mr *MockAzureMockRecorder) SnapshotForVolume(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotForVolume", reflect.TypeOf((*MockAzure)(nil).SnapshotForVolume), arg0, arg1, arg2)
 }
 
+// SnapshotPolicyExists mocks base method.
+func (m *MockAzure) SnapshotPolicyExists(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotPolicyExists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotPolicyExists indicates an expected call of SnapshotPolicyExists.
+func (mr *MockAzureMockRecorder) SnapshotPolicyExists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotPolicyExists", reflect.TypeOf((*MockAzure)(nil).SnapshotPolicyExists), arg0, arg1)
+}
+
 // SnapshotsForVolume mocks base method.
 func (m *MockAzure) SnapshotsForVolume(arg0 context.Context, arg1 *api.FileSystem) (*[]*api.Snapshot, error) {
 	m.ctrl.T.Helper()
@@ -371,6 +613,34 @@ func (mr *MockAzureMockRecorder) SnapshotsForVolume(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotsForVolume", reflect.TypeOf((*MockAzure)(nil).SnapshotsForVolume), arg0, arg1)
 }
 
+// SplitCloneFromParent mocks base method.
+func (m *MockAzure) SplitCloneFromParent(arg0 context.Context, arg1 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SplitCloneFromParent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SplitCloneFromParent indicates an expected call of SplitCloneFromParent.
+func (mr *MockAzureMockRecorder) SplitCloneFromParent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SplitCloneFromParent", reflect.TypeOf((*MockAzure)(nil).SplitCloneFromParent), arg0, arg1)
+}
+
+// SubnetForStoragePool mocks base method.
+func (m *MockAzure) SubnetForStoragePool(arg0 context.Context, arg1 storage.Pool) *api.Subnet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubnetForStoragePool", arg0, arg1)
+	ret0, _ := ret[0].(*api.Subnet)
+	return ret0
+}
+
+// SubnetForStoragePool indicates an expected call of SubnetForStoragePool.
+func (mr *MockAzureMockRecorder) SubnetForStoragePool(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubnetForStoragePool", reflect.TypeOf((*MockAzure)(nil).SubnetForStoragePool), arg0, arg1)
+}
+
 // SubnetsForStoragePool mocks base method.
 func (m *MockAzure) SubnetsForStoragePool(arg0 context.Context, arg1 storage.Pool) []*api.Subnet {
 	m.ctrl.T.Helper()
@@ -508,6 +778,20 @@ func (mr *MockAzureMockRecorder) Subvolumes(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subvolumes", reflect.TypeOf((*MockAzure)(nil).Subvolumes), arg0, arg1)
 }
 
+// UpgradeVolumeNetworkFeatures mocks base method.
+func (m *MockAzure) UpgradeVolumeNetworkFeatures(arg0 context.Context, arg1 *api.FileSystem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeVolumeNetworkFeatures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeVolumeNetworkFeatures indicates an expected call of UpgradeVolumeNetworkFeatures.
+func (mr *MockAzureMockRecorder) UpgradeVolumeNetworkFeatures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeVolumeNetworkFeatures", reflect.TypeOf((*MockAzure)(nil).UpgradeVolumeNetworkFeatures), arg0, arg1)
+}
+
 // ValidateFilePoolVolumes mocks base method.
 func (m *MockAzure) ValidateFilePoolVolumes(arg0 context.Context, arg1 []string) ([]*api.FileSystem, error) {
 	m.ctrl.T.Helper()
@@ -616,6 +900,22 @@ func (mr *MockAzureMockRecorder) VolumeExistsByID(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeExistsByID", reflect.TypeOf((*MockAzure)(nil).VolumeExistsByID), arg0, arg1)
 }
 
+// VolumeUsedBytes mocks base method.
+func (m *MockAzure) VolumeUsedBytes(arg0 context.Context, arg1 *api.FileSystem) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VolumeUsedBytes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VolumeUsedBytes indicates an expected call of VolumeUsedBytes.
+func (mr *MockAzureMockRecorder) VolumeUsedBytes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VolumeUsedBytes", reflect.TypeOf((*MockAzure)(nil).VolumeUsedBytes), arg0, arg1)
+}
+
 // Volumes mocks base method.
 func (m *MockAzure) Volumes(arg0 context.Context) (*[]*api.FileSystem, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +97,12 @@ type Mirrorer interface {
 	GetMirrorTransferTime(ctx context.Context, pvcVolumeName string) (*time.Time, error)
 }
 
+// CacheStatusReporter provides a common interface for backends that cache discovered storage resources, so
+// the freshness of that cache can be reported with the backend.
+type CacheStatusReporter interface {
+	CacheStatus(ctx context.Context) *BackendCacheStatus
+}
+
 // StateGetter provides a common interface for backends that support polling backend for state information.
 type StateGetter interface {
 	GetBackendState(ctx context.Context) (string, *roaring.Bitmap)
@@ -971,6 +977,7 @@ const (
 	PasswordChange
 	PrefixChange
 	CredentialsChange
+	VolumeAccessInfoChange
 )
 
 const (
@@ -1115,6 +1122,16 @@ type BackendExternal struct {
 	StateReason string                 `json:"StateReason"`
 	Volumes     []string               `json:"volumes"`
 	ConfigRef   string                 `json:"configRef"`
+	CacheStatus *BackendCacheStatus    `json:"cacheStatus,omitempty"`
+}
+
+// BackendCacheStatus reports when a backend's cache of discovered storage resources was last refreshed,
+// how old it is, and whether it is older than the backend allows.
+type BackendCacheStatus struct {
+	LastUpdateTime time.Time     `json:"lastUpdateTime"`
+	Age            time.Duration `json:"age"`
+	MaxCacheAge    time.Duration `json:"maxCacheAge"`
+	Stale          bool          `json:"stale"`
 }
 
 func (b *StorageBackend) ConstructExternal(ctx context.Context) *BackendExternal {
@@ -1132,6 +1149,10 @@ func (b *StorageBackend) ConstructExternal(ctx context.Context) *BackendExternal
 		ConfigRef:   b.configRef,
 	}
 
+	if reporter, ok := b.driver.(CacheStatusReporter); ok {
+		backendExternal.CacheStatus = reporter.CacheStatus(ctx)
+	}
+
 	for name, pool := range b.storage {
 		backendExternal.Storage[name] = pool.ConstructExternal()
 	}
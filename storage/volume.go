@@ -26,6 +26,7 @@ type VolumeConfig struct {
 	SnapshotReserve             string                 `json:"snapshotReserve,omitempty"`
 	SnapshotDir                 string                 `json:"snapshotDirectory,omitempty"`
 	ExportPolicy                string                 `json:"exportPolicy,omitempty"`
+	ExportRule                  string                 `json:"exportRule,omitempty"`
 	UnixPermissions             string                 `json:"unixPermissions,omitempty"`
 	StorageClass                string                 `json:"storageClass,omitempty"`
 	AccessMode                  config.AccessMode      `json:"accessMode,omitempty"`
@@ -65,6 +66,14 @@ type VolumeConfig struct {
 	InternalID         string                 `json:"internalID,omitempty"`
 	ShareSourceVolume  string                 `json:"shareSourceVolume"`
 	SubordinateVolumes map[string]interface{} `json:"-"`
+	// Namespace is the namespace of the claim that requested this volume, if known
+	Namespace string `json:"namespace,omitempty"`
+	// NamespaceAnnotations are the annotations on the requesting namespace, available only at create time
+	NamespaceAnnotations map[string]string `json:"-"`
+	// RestoreFromSnapshot is a backend snapshot, as <volume internal name>/<snapshot name>, to create the volume from
+	RestoreFromSnapshot string `json:"restoreFromSnapshot,omitempty"`
+	// CapacityPool is the backend-specific pool in which the volume was placed, recorded for troubleshooting
+	CapacityPool string `json:"capacityPool,omitempty"`
 }
 
 type VolumeCreatingConfig struct {
@@ -176,6 +185,7 @@ type VolumeExternal struct {
 	Pool        string      `json:"pool"`
 	Orphaned    bool        `json:"orphaned"`
 	State       VolumeState `json:"state"`
+	UsedBytes   int64       `json:"usedBytes,omitempty"` // space consumed on the backend, if reported
 }
 
 func (v *VolumeExternal) GetCHAPSecretName() string {
@@ -8,18 +8,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	netapp "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v4"
 	resourcegraph "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	features "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
 	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/multierr"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 
 	. "github.com/netapp/trident/logging"
@@ -33,21 +38,31 @@ const (
 	SnapshotTimeout            = 240 * time.Second // Snapshotter sidecar has a timeout of 5 minutes.  Stay under that!
 	DefaultTimeout             = 120 * time.Second
 	MaxLabelLength             = 256
+	MaxTagKeyLength            = 512
 	DefaultSDKTimeout          = 30 * time.Second
 	DefaultSubvolumeSDKTimeout = 15 * time.Second
 	SDKRetryDelay              = 2 * time.Second
 	SDKMaxRetryDelay           = 15 * time.Second
 	CorrelationIDHeader        = "X-Ms-Correlation-Request-Id"
 	SubvolumeNameSeparator     = "-file-"
+	SplitCloneAPIVersion       = "2023-11-01" // First ANF API version that can split a clone from its parent
+	SplitClonePollFrequency    = 10 * time.Second
+	MetricsAPIVersion          = "2018-01-01"
+	VolumeUsageMetricsTimespan = time.Hour // ANF publishes volume metrics about every five minutes
+
+	MetricVolumeLogicalSize  = "VolumeLogicalSize"
+	MetricVolumeSnapshotSize = "VolumeSnapshotSize"
 )
 
 var (
-	capacityPoolIDRegex = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)$`)
-	volumeIDRegex       = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)$`)
-	volumeNameRegex     = regexp.MustCompile(`/?(?P<resourceGroup>[^/]+)/(?P<netappAccount>[^/]+)/(?P<capacityPool>[^/]+)/(?P<volume>[^/]+)?/?$`)
-	snapshotIDRegex     = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)/snapshots/(?P<snapshot>[^/]+)$`)
-	subvolumeIDRegex    = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)/subvolumes/(?P<subvolume>[^/]+)$`)
-	subnetIDRegex       = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/virtualNetworks/(?P<virtualNetwork>[^/]+)/subnets/(?P<subnet>[^/]+)$`)
+	netappAccountIDRegex  = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)$`)
+	capacityPoolIDRegex   = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)$`)
+	volumeIDRegex         = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)$`)
+	snapshotPolicyIDRegex = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/snapshotPolicies/(?P<snapshotPolicy>[^/]+)$`)
+	volumeNameRegex       = regexp.MustCompile(`/?(?P<resourceGroup>[^/]+)/(?P<netappAccount>[^/]+)/(?P<capacityPool>[^/]+)/(?P<volume>[^/]+)?/?$`)
+	snapshotIDRegex       = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)/snapshots/(?P<snapshot>[^/]+)$`)
+	subvolumeIDRegex      = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/netAppAccounts/(?P<netappAccount>[^/]+)/capacityPools/(?P<capacityPool>[^/]+)/volumes/(?P<volume>[^/]+)/subvolumes/(?P<subvolume>[^/]+)$`)
+	subnetIDRegex         = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/virtualNetworks/(?P<virtualNetwork>[^/]+)/subnets/(?P<subnet>[^/]+)$`)
 )
 
 // ClientConfig holds configuration data for the API driver object.
@@ -57,22 +72,38 @@ type ClientConfig struct {
 	SubscriptionID    string `json:"subscriptionId"`
 	Location          string `json:"location"`
 	StorageDriverName string
+	// CloudName selects the Azure environment, such as AzureUSGovernment; empty means the public cloud
+	CloudName string
+	// Credential, if set, is used instead of a credential built from the AzureAuthConfig
+	Credential azcore.TokenCredential `json:"-"`
 
 	// Options
 	DebugTraceFlags map[string]bool
 	SDKTimeout      time.Duration // Timeout applied to all calls to the Azure SDK
+	SDKMaxRetries   int32         // Retries of failed or throttled SDK calls; 0 means the SDK default, -1 none
+	SDKRetryBackoff time.Duration // Delay before the first SDK retry, which grows exponentially; 0 means default
+	SDKRetryTimeout time.Duration // Limit on the time spent on an SDK call including its retries; 0 means none
 	MaxCacheAge     time.Duration // The oldest data we should expect in the cached resources
+
+	DisableSDKMetrics bool // Don't collect metrics for SDK calls, even if Trident's metrics are enabled
 }
 
 // AzureClient holds operational Azure SDK objects.
 type AzureClient struct {
-	Credential       azcore.TokenCredential
-	FeaturesClient   *features.Client
-	GraphClient      *resourcegraph.Client
-	VolumesClient    *netapp.VolumesClient
-	SnapshotsClient  *netapp.SnapshotsClient
-	SubvolumesClient *netapp.SubvolumesClient
+	Credential         azcore.TokenCredential
+	FeaturesClient     *features.Client
+	GraphClient        *resourcegraph.Client
+	VolumesClient      *netapp.VolumesClient
+	SnapshotsClient    *netapp.SnapshotsClient
+	SubvolumesClient   *netapp.SubvolumesClient
+	PoliciesClient     *netapp.SnapshotPoliciesClient
+	BackupsClient      *netapp.BackupsClient
+	VolumeGroupsClient *netapp.VolumeGroupsClient
+	ResourceClient     *arm.Client
 	AzureResources
+
+	// resourcesLock guards AzureResources, which a background cache refresh may swap at any time
+	resourcesLock sync.RWMutex
 }
 
 type PollerSVCreateResponse struct {
@@ -135,23 +166,29 @@ func NewDriver(config ClientConfig) (Azure, error) {
 		return nil, errors.New("location must be specified in the config")
 	}
 
-	credential, err := GetAzureCredential(config)
+	cloudConfig, err := CloudConfiguration(config.CloudName)
 	if err != nil {
 		return nil, err
 	}
 
+	credential := config.Credential
+	if credential == nil {
+		if credential, err = GetAzureCredential(config); err != nil {
+			return nil, err
+		}
+	}
+
 	clientOptions := &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
-			Retry: policy.RetryOptions{
-				TryTimeout:    config.SDKTimeout,
-				RetryDelay:    SDKRetryDelay,
-				MaxRetryDelay: SDKMaxRetryDelay,
-			},
+			Cloud:           cloudConfig,
+			Retry:           sdkRetryOptions(config),
+			PerCallPolicies: sdkPerCallPolicies(config),
 		},
 	}
 
 	subvolumeClientOptions := &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
+			Cloud: cloudConfig,
 			Retry: policy.RetryOptions{
 				MaxRetries:    6, // 30 seconds, assuming hardcoded Retry-After value of 5 seconds
 				TryTimeout:    DefaultSubvolumeSDKTimeout,
@@ -181,14 +218,34 @@ func NewDriver(config ClientConfig) (Azure, error) {
 	if err != nil {
 		return nil, err
 	}
+	policiesClient, err := netapp.NewSnapshotPoliciesClient(config.SubscriptionID, credential, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	backupsClient, err := netapp.NewBackupsClient(config.SubscriptionID, credential, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	volumeGroupsClient, err := netapp.NewVolumeGroupsClient(config.SubscriptionID, credential, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	resourceClient, err := arm.NewClient("trident.ANFResourceClient", "v1.0.0", credential, clientOptions)
+	if err != nil {
+		return nil, err
+	}
 
 	sdkClient := &AzureClient{
-		Credential:       credential,
-		FeaturesClient:   featuresClient,
-		GraphClient:      graphClient,
-		VolumesClient:    volumesClient,
-		SnapshotsClient:  snapshotsClient,
-		SubvolumesClient: subvolumesClient,
+		Credential:         credential,
+		FeaturesClient:     featuresClient,
+		GraphClient:        graphClient,
+		VolumesClient:      volumesClient,
+		SnapshotsClient:    snapshotsClient,
+		SubvolumesClient:   subvolumesClient,
+		PoliciesClient:     policiesClient,
+		BackupsClient:      backupsClient,
+		VolumeGroupsClient: volumeGroupsClient,
+		ResourceClient:     resourceClient,
 	}
 
 	return Client{
@@ -197,8 +254,75 @@ func NewDriver(config ClientConfig) (Azure, error) {
 	}, nil
 }
 
+// sdkRetryStatusCodes are the HTTP statuses of SDK responses that are retried: request timeouts, throttling,
+// and server errors, all of which usually clear up on their own.  Any other status means the request itself
+// was rejected, so repeating it would only fail again.  Failures to reach Azure at all are retried as well,
+// while a cancelled call or one past its deadline never is.
+var sdkRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// sdkRetryOptions returns the retry policy for SDK calls.  The SDK backs off exponentially from the
+// initial delay, up to the maximum delay, and honors any Retry-After header on throttled responses.
+// A Retry-After delay beyond the maximum delay isn't waited for, and the throttled response is returned.
+func sdkRetryOptions(config ClientConfig) policy.RetryOptions {
+	retryDelay := SDKRetryDelay
+	if config.SDKRetryBackoff > 0 {
+		retryDelay = config.SDKRetryBackoff
+	}
+
+	maxRetryDelay := SDKMaxRetryDelay
+	if retryDelay > maxRetryDelay {
+		maxRetryDelay = retryDelay
+	}
+
+	return policy.RetryOptions{
+		MaxRetries:    config.SDKMaxRetries,
+		TryTimeout:    config.SDKTimeout,
+		RetryDelay:    retryDelay,
+		MaxRetryDelay: maxRetryDelay,
+		StatusCodes:   sdkRetryStatusCodes,
+	}
+}
+
+// sdkPerCallPolicies returns the policies applied once to each SDK call, ahead of its retries.
+func sdkPerCallPolicies(config ClientConfig) []policy.Policy {
+	if config.SDKRetryTimeout <= 0 {
+		return nil
+	}
+	return []policy.Policy{retryTimeoutPolicy{timeout: config.SDKRetryTimeout}}
+}
+
+// retryTimeoutPolicy limits the total time spent on an SDK call, including any retries after throttling or
+// transient failures, so that a struggling service can't hold up the driver indefinitely.  The deadline is
+// derived from the caller's context, so cancellation by the caller still ends the call immediately.
+type retryTimeoutPolicy struct {
+	timeout time.Duration
+}
+
+func (p retryTimeoutPolicy) Do(req *policy.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Raw().Context(), p.timeout)
+	defer cancel()
+	return req.Clone(ctx).Next()
+}
+
+// CloudConfiguration returns the Azure Active Directory authority and Resource Manager endpoint and audience
+// for the named Azure environment.  An empty name selects the public cloud.
+func CloudConfiguration(cloudName string) (cloud.Configuration, error) {
+	cloudConfig := azclient.AzureCloudConfigFromName(cloudName)
+	if cloudConfig == nil {
+		return cloud.Configuration{}, fmt.Errorf("unsupported Azure cloud %s", cloudName)
+	}
+	return *cloudConfig, nil
+}
+
 func GetAzureCredential(config ClientConfig) (credential azcore.TokenCredential, err error) {
-	clientOptions, err := azclient.GetDefaultAuthClientOption(nil)
+	clientOptions, err := azclient.GetDefaultAuthClientOption(&azclient.ARMClientConfig{Cloud: config.CloudName})
 	if err != nil {
 		return nil, errors.New("error getting default auth client option: " + err.Error())
 	}
@@ -220,11 +344,16 @@ func (c Client) Init(ctx context.Context, pools map[string]storage.Pool) error {
 
 // RegisterStoragePool makes a note of pools defined by the driver for later mapping.
 func (c Client) registerStoragePools(sPools map[string]storage.Pool) {
-	c.sdkClient.AzureResources.StoragePoolMap = make(map[string]storage.Pool)
+	storagePoolMap := make(map[string]storage.Pool)
 
 	for _, sPool := range sPools {
-		c.sdkClient.AzureResources.StoragePoolMap[sPool.Name()] = sPool
+		storagePoolMap[sPool.Name()] = sPool
 	}
+
+	c.sdkClient.resourcesLock.Lock()
+	defer c.sdkClient.resourcesLock.Unlock()
+
+	c.sdkClient.AzureResources.StoragePoolMap = storagePoolMap
 }
 
 // ///////////////////////////////////////////////////////////////////////////////
@@ -291,6 +420,32 @@ func CreateNetappAccountFullName(resourceGroup, netappAccount string) string {
 	return fmt.Sprintf("%s/%s", resourceGroup, netappAccount)
 }
 
+// ParseNetappAccountID parses the Azure-style ID for a netapp account.
+func ParseNetappAccountID(
+	netappAccountID string,
+) (subscriptionID, resourceGroup, provider, netappAccount string, err error) {
+	match := netappAccountIDRegex.FindStringSubmatch(netappAccountID)
+
+	if match == nil {
+		err = fmt.Errorf("netapp account ID %s is invalid", netappAccountID)
+		return
+	}
+
+	paramsMap := make(map[string]string)
+	for i, name := range netappAccountIDRegex.SubexpNames() {
+		if i > 0 && i <= len(match) {
+			paramsMap[name] = match[i]
+		}
+	}
+
+	subscriptionID = paramsMap["subscriptionID"]
+	resourceGroup = paramsMap["resourceGroup"]
+	provider = paramsMap["provider"]
+	netappAccount = paramsMap["netappAccount"]
+
+	return
+}
+
 // ParseCapacityPoolID parses the Azure-style ID for a capacity pool.
 func ParseCapacityPoolID(
 	capacityPoolID string,
@@ -437,6 +592,63 @@ func ParseSnapshotID(
 	return
 }
 
+// CreateSnapshotPolicyID creates the Azure-style ID for a snapshot policy.
+func CreateSnapshotPolicyID(subscriptionID, resourceGroup, netappAccount, snapshotPolicy string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.NetApp/netAppAccounts/%s/snapshotPolicies/%s",
+		subscriptionID, resourceGroup, netappAccount, snapshotPolicy)
+}
+
+// ParseSnapshotPolicyID parses the Azure-style ID for a snapshot policy.
+func ParseSnapshotPolicyID(
+	snapshotPolicyID string,
+) (subscriptionID, resourceGroup, provider, netappAccount, snapshotPolicy string, err error) {
+	match := snapshotPolicyIDRegex.FindStringSubmatch(snapshotPolicyID)
+
+	if match == nil {
+		err = fmt.Errorf("snapshot policy ID %s is invalid", snapshotPolicyID)
+		return
+	}
+
+	paramsMap := make(map[string]string)
+	for i, name := range snapshotPolicyIDRegex.SubexpNames() {
+		if i > 0 && i <= len(match) {
+			paramsMap[name] = match[i]
+		}
+	}
+
+	subscriptionID = paramsMap["subscriptionID"]
+	resourceGroup = paramsMap["resourceGroup"]
+	provider = paramsMap["provider"]
+	netappAccount = paramsMap["netappAccount"]
+	snapshotPolicy = paramsMap["snapshotPolicy"]
+
+	return
+}
+
+// CreateBackupPolicyID creates the Azure-style ID for a backup policy.
+func CreateBackupPolicyID(subscriptionID, resourceGroup, netappAccount, backupPolicy string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.NetApp/netAppAccounts/%s/backupPolicies/%s",
+		subscriptionID, resourceGroup, netappAccount, backupPolicy)
+}
+
+// backupPolicyID returns the ID of a backup policy, which may be specified either by ID or by name, in
+// which case it is assumed to reside in the specified NetApp account.
+func (c Client) backupPolicyID(resourceGroup, netappAccount, backupPolicy string) string {
+	if strings.HasPrefix(backupPolicy, "/subscriptions/") {
+		return backupPolicy
+	}
+	return CreateBackupPolicyID(c.config.SubscriptionID, resourceGroup, netappAccount, backupPolicy)
+}
+
+// snapshotPolicyID returns the ID of a snapshot policy, which may be specified either by ID or by
+// name, in which case it is assumed to reside in the specified NetApp account.
+func (c Client) snapshotPolicyID(resourceGroup, netappAccount, snapshotPolicy string) string {
+	if strings.HasPrefix(snapshotPolicy, "/subscriptions/") {
+		return snapshotPolicy
+	}
+	return CreateSnapshotPolicyID(c.config.SubscriptionID, resourceGroup, netappAccount, snapshotPolicy)
+}
+
 // CreateSubvolumeID creates the Azure-style ID for a subvolume.
 func CreateSubvolumeID(
 	subscriptionID, resourceGroup, netappAccount, capacityPool, volume, subvolume string,
@@ -536,6 +748,12 @@ func exportPolicyExport(exportPolicy *ExportPolicy) *netapp.VolumePropertiesExpo
 			Kerberos5IReadWrite: &kerberos5IReadWrite,
 			Kerberos5PReadOnly:  &kerberos5PReadOnly,
 			Kerberos5PReadWrite: &kerberos5PReadWrite,
+			HasRootAccess:       rule.HasRootAccess,
+		}
+
+		if rule.ChownMode != "" {
+			chownMode := netapp.ChownMode(rule.ChownMode)
+			anfRule.ChownMode = &chownMode
 		}
 
 		anfRules = append(anfRules, &anfRule)
@@ -570,6 +788,11 @@ func exportPolicyImport(anfExportPolicy *netapp.VolumePropertiesExportPolicy) *E
 			Kerberos5IReadWrite: DerefBool(anfRule.Kerberos5IReadWrite),
 			Kerberos5PReadOnly:  DerefBool(anfRule.Kerberos5PReadOnly),
 			Kerberos5PReadWrite: DerefBool(anfRule.Kerberos5PReadWrite),
+			HasRootAccess:       anfRule.HasRootAccess,
+		}
+
+		if anfRule.ChownMode != nil {
+			rule.ChownMode = string(*anfRule.ChownMode)
 		}
 
 		rules = append(rules, rule)
@@ -627,9 +850,36 @@ func (c Client) newFileSystemFromVolume(ctx context.Context, vol *netapp.Volume)
 		SubvolumesEnabled: c.getSubvolumesEnabledFromVolume(vol.Properties.EnableSubvolumes),
 		NetworkFeatures:   DerefNetworkFeatures(vol.Properties.NetworkFeatures),
 		KerberosEnabled:   DerefBool(vol.Properties.KerberosEnabled),
+		LdapEnabled:       DerefBool(vol.Properties.LdapEnabled),
+		SnapshotPolicyID:  snapshotPolicyIDFromVolume(vol),
+		BackupEnabled:     backupEnabledFromVolume(vol),
+		CoolAccess:        DerefBool(vol.Properties.CoolAccess),
+		CoolnessPeriod:    DerefInt32(vol.Properties.CoolnessPeriod),
+		ThroughputMibps:   DerefFloat32(vol.Properties.ThroughputMibps),
+		VolumeType:        DerefString(vol.Properties.VolumeType),
+		Zone:              zoneFromVolume(vol),
+		VolumeSpecName:    DerefString(vol.Properties.VolumeSpecName),
+
+		SmbContinuouslyAvailable: DerefBool(vol.Properties.SmbContinuouslyAvailable),
+		SmbEncryption:            DerefBool(vol.Properties.SmbEncryption),
+		IsLargeVolume:            DerefBool(vol.Properties.IsLargeVolume),
+
+		ReplicationEndpointType:   replicationEndpointTypeFromVolume(vol),
+		ReplicationRemoteVolumeID: replicationRemoteVolumeIDFromVolume(vol),
+		ReplicationSchedule:       replicationScheduleFromVolume(vol),
 	}, nil
 }
 
+// zoneFromVolume extracts the availability zone from an SDK volume, if it is pinned to one.
+func zoneFromVolume(vol *netapp.Volume) string {
+	for _, zone := range vol.Zones {
+		if zone != nil && *zone != "" {
+			return *zone
+		}
+	}
+	return ""
+}
+
 // getSubvolumesEnabledFromVolume extracts the SubvolumesEnabled from an SDK volume.
 func (c Client) getSubvolumesEnabledFromVolume(value *netapp.EnableSubvolumes) bool {
 	if value == nil || *value != netapp.EnableSubvolumesEnabled {
@@ -673,6 +923,57 @@ func (c Client) getLabelsFromVolume(vol *netapp.Volume) map[string]string {
 	return labels
 }
 
+// snapshotPolicyIDFromVolume returns the ID of the snapshot policy assigned to a volume, if any.
+func snapshotPolicyIDFromVolume(vol *netapp.Volume) string {
+	if vol.Properties.DataProtection == nil || vol.Properties.DataProtection.Snapshot == nil {
+		return ""
+	}
+	return DerefString(vol.Properties.DataProtection.Snapshot.SnapshotPolicyID)
+}
+
+// backupEnabledFromVolume returns whether backups are enabled for a volume.
+func backupEnabledFromVolume(vol *netapp.Volume) bool {
+	if vol.Properties.DataProtection == nil || vol.Properties.DataProtection.Backup == nil {
+		return false
+	}
+	return DerefBool(vol.Properties.DataProtection.Backup.BackupEnabled)
+}
+
+// replicationFromVolume returns the replication properties of an SDK volume, if any.
+func replicationFromVolume(vol *netapp.Volume) *netapp.ReplicationObject {
+	if vol.Properties.DataProtection == nil {
+		return nil
+	}
+	return vol.Properties.DataProtection.Replication
+}
+
+// replicationEndpointTypeFromVolume returns whether a volume is the source or destination of a replication.
+func replicationEndpointTypeFromVolume(vol *netapp.Volume) string {
+	replication := replicationFromVolume(vol)
+	if replication == nil || replication.EndpointType == nil {
+		return ""
+	}
+	return string(*replication.EndpointType)
+}
+
+// replicationRemoteVolumeIDFromVolume returns the ID of the volume at the other end of a replication.
+func replicationRemoteVolumeIDFromVolume(vol *netapp.Volume) string {
+	replication := replicationFromVolume(vol)
+	if replication == nil {
+		return ""
+	}
+	return DerefString(replication.RemoteVolumeResourceID)
+}
+
+// replicationScheduleFromVolume returns the schedule of a replication.
+func replicationScheduleFromVolume(vol *netapp.Volume) string {
+	replication := replicationFromVolume(vol)
+	if replication == nil || replication.ReplicationSchedule == nil {
+		return ""
+	}
+	return string(*replication.ReplicationSchedule)
+}
+
 // ///////////////////////////////////////////////////////////////////////////////
 // Functions to retrieve and manage volumes
 // ///////////////////////////////////////////////////////////////////////////////
@@ -719,8 +1020,27 @@ func (c Client) getVolumesFromPool(ctx context.Context, cPool *CapacityPool) (*[
 	return &filesystems, nil
 }
 
+// CapacityPoolUsedBytes returns the total quota of all volumes in a capacity pool, which is the portion
+// of the pool's size that is no longer available to new volumes.
+func (c Client) CapacityPoolUsedBytes(ctx context.Context, cPool *CapacityPool) (usedBytes int64, err error) {
+	defer c.recordOperation(OperationVolumeList, &err)()
+
+	filesystems, err := c.getVolumesFromPool(ctx, cPool)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, filesystem := range *filesystems {
+		usedBytes += filesystem.QuotaInBytes
+	}
+
+	return usedBytes, nil
+}
+
 // Volumes returns a list of all volumes.
-func (c Client) Volumes(ctx context.Context) (*[]*FileSystem, error) {
+func (c Client) Volumes(ctx context.Context) (volumeList *[]*FileSystem, err error) {
+	defer c.recordOperation(OperationVolumeList, &err)()
+
 	var filesystems []*FileSystem
 
 	cPools := c.CapacityPools()
@@ -805,7 +1125,9 @@ func (c Client) VolumeExistsByCreationToken(ctx context.Context, creationToken s
 }
 
 // VolumeByID returns a Filesystem based on its Azure-style ID.
-func (c Client) VolumeByID(ctx context.Context, id string) (*FileSystem, error) {
+func (c Client) VolumeByID(ctx context.Context, id string) (foundVolume *FileSystem, err error) {
+	defer c.recordOperation(OperationVolumeGet, &err)()
+
 	logFields := LogFields{
 		"API": "VolumesClient.Get",
 		"ID":  id,
@@ -854,6 +1176,96 @@ func (c Client) VolumeExistsByID(ctx context.Context, id string) (bool, *FileSys
 	}
 }
 
+// volumeMetricsResponse is the subset of an Azure Monitor metrics response needed to read volume usage.
+type volumeMetricsResponse struct {
+	Value []struct {
+		Name struct {
+			Value string `json:"value"`
+		} `json:"name"`
+		Timeseries []struct {
+			Data []struct {
+				Average *float64 `json:"average"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"value"`
+}
+
+// VolumeUsedBytes returns the space consumed by a volume and by its snapshots.  The ANF volume resource
+// doesn't report consumption, so the most recent VolumeLogicalSize and VolumeSnapshotSize values are read
+// from Azure Monitor, which has no client in the vendored SDK.  A volume too new to have published any
+// metrics is reported as empty.
+func (c Client) VolumeUsedBytes(
+	ctx context.Context, filesystem *FileSystem,
+) (usedBytes, snapshotUsedBytes int64, err error) {
+	defer c.recordOperation(OperationVolumeMetrics, &err)()
+
+	logFields := LogFields{
+		"API":    "MetricsClient.List",
+		"volume": filesystem.FullName,
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodGet, runtime.JoinPaths(c.sdkClient.ResourceClient.Endpoint(),
+		filesystem.ID, "providers/Microsoft.Insights/metrics"))
+	if err != nil {
+		return 0, 0, err
+	}
+	endTime := time.Now().UTC()
+	query := req.Raw().URL.Query()
+	query.Set("api-version", MetricsAPIVersion)
+	query.Set("metricnames", MetricVolumeLogicalSize+","+MetricVolumeSnapshotSize)
+	query.Set("aggregation", "Average")
+	query.Set("timespan", endTime.Add(-VolumeUsageMetricsTimespan).Format(time.RFC3339)+"/"+
+		endTime.Format(time.RFC3339))
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header["Accept"] = []string{"application/json"}
+
+	rawResponse, err := c.sdkClient.ResourceClient.Pipeline().Do(req)
+	if err == nil && !runtime.HasStatusCode(rawResponse, http.StatusOK) {
+		err = runtime.NewResponseError(rawResponse)
+	}
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error reading volume metrics.")
+		return 0, 0, err
+	}
+
+	var response volumeMetricsResponse
+	if err = runtime.UnmarshalAsJSON(rawResponse, &response); err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Could not parse volume metrics.")
+		return 0, 0, err
+	}
+
+	for _, metric := range response.Value {
+		// Keep the most recent value, since the last data points may not have been published yet
+		var latest int64
+		for _, timeseries := range metric.Timeseries {
+			for _, data := range timeseries.Data {
+				if data.Average != nil {
+					latest = int64(*data.Average)
+				}
+			}
+		}
+
+		switch metric.Name.Value {
+		case MetricVolumeLogicalSize:
+			usedBytes = latest
+		case MetricVolumeSnapshotSize:
+			snapshotUsedBytes = latest
+		}
+	}
+
+	if c.config.DebugTraceFlags["api"] {
+		Logc(ctx).WithFields(logFields).WithFields(LogFields{
+			"usedBytes":         usedBytes,
+			"snapshotUsedBytes": snapshotUsedBytes,
+		}).Debug("Read volume metrics.")
+	}
+
+	return usedBytes, snapshotUsedBytes, nil
+}
+
 // WaitForVolumeState watches for a desired volume state and returns when that state is achieved.
 func (c Client) WaitForVolumeState(
 	ctx context.Context, filesystem *FileSystem, desiredState string, abortStates []string,
@@ -914,9 +1326,13 @@ func (c Client) WaitForVolumeState(
 
 	Logc(ctx).WithField("desiredState", desiredState).Info("Waiting for volume state.")
 
-	if err := backoff.RetryNotify(checkVolumeState, stateBackoff, stateNotify); err != nil {
+	// Stop waiting as soon as the caller gives up
+	if err := backoff.RetryNotify(checkVolumeState, backoff.WithContext(stateBackoff, ctx),
+		stateNotify); err != nil {
 		if IsTerminalStateError(err) {
 			Logc(ctx).WithError(err).Error("Volume reached terminal state.")
+		} else if ctx.Err() != nil {
+			Logc(ctx).WithError(ctx.Err()).Warningf("Stopped waiting for volume state %s.", desiredState)
 		} else {
 			Logc(ctx).Warningf("Volume state was not %s after %3.2f seconds.",
 				desiredState, stateBackoff.MaxElapsedTime.Seconds())
@@ -930,20 +1346,71 @@ func (c Client) WaitForVolumeState(
 }
 
 // CreateVolume creates a new volume.
-func (c Client) CreateVolume(ctx context.Context, request *FilesystemCreateRequest) (*FileSystem, error) {
+func (c Client) CreateVolume(
+	ctx context.Context, request *FilesystemCreateRequest,
+) (newVolume *FileSystem, err error) {
+	defer c.recordOperation(OperationVolumeCreate, &err)()
+
 	resourceGroup := request.ResourceGroup
 	netappAccount := request.NetAppAccount
 	cPoolName := request.CapacityPool
 
 	// Get the capacity pool so we can determine location and service level
 	cPoolFullName := CreateCapacityPoolFullName(resourceGroup, netappAccount, cPoolName)
-	cPool, ok := c.sdkClient.AzureResources.CapacityPoolMap[cPoolFullName]
-	if !ok {
+	cPool := c.capacityPool(cPoolFullName)
+	if cPool == nil {
 		return nil, fmt.Errorf("unknown capacity pool %s", cPoolFullName)
 	}
 
 	volumeFullName := CreateVolumeFullName(resourceGroup, netappAccount, cPoolName, request.Name)
 
+	newVol := c.newSDKVolume(request, cPool)
+
+	Logc(ctx).WithFields(LogFields{
+		"name":          request.Name,
+		"creationToken": request.CreationToken,
+		"resourceGroup": resourceGroup,
+		"netAppAccount": netappAccount,
+		"capacityPool":  cPoolName,
+		"subnetID":      request.SubnetID,
+		"snapshotID":    request.SnapshotID,
+		"snapshotDir":   request.SnapshotDirectory,
+	}).Debug("Issuing create request.")
+
+	logFields := LogFields{
+		"API":           "VolumesClient.BeginCreateOrUpdate",
+		"volume":        volumeFullName,
+		"creationToken": request.CreationToken,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.VolumesClient.BeginCreateOrUpdate(responseCtx,
+		resourceGroup, netappAccount, cPoolName, request.Name, newVol, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error creating volume.")
+		return nil, err
+	}
+
+	Logc(ctx).WithFields(logFields).Info("Volume create request issued.")
+
+	// The volume doesn't exist yet, so forge the volume ID to enable conversion to a FileSystem struct
+	newVolID := CreateVolumeID(c.config.SubscriptionID, resourceGroup, netappAccount, cPoolName, request.Name)
+	newVol.ID = &newVolID
+
+	return c.newFileSystemFromVolume(ctx, &newVol)
+}
+
+// newSDKVolume builds the SDK volume to be created from a create request and the capacity pool the volume
+// is to be created in.
+func (c Client) newSDKVolume(request *FilesystemCreateRequest, cPool *CapacityPool) netapp.Volume {
+	resourceGroup := request.ResourceGroup
+	netappAccount := request.NetAppAccount
+
 	// Location is required and is derived from the capacity pool
 	location := cPool.Location
 
@@ -978,56 +1445,206 @@ func (c Client) CreateVolume(ctx context.Context, request *FilesystemCreateReque
 		newVol.Properties.SnapshotID = &request.SnapshotID
 	}
 
+	// Only pin the volume to an availability zone if one was requested
+	if request.Zone != "" {
+		newVol.Zones = []*string{utils.Ptr(request.Zone)}
+	}
+
 	// Only send unix permissions if specified, since it is not yet a GA feature
 	if request.UnixPermissions != "" {
 		newVol.Properties.UnixPermissions = &request.UnixPermissions
 	}
 
-	Logc(ctx).WithFields(LogFields{
-		"name":          request.Name,
-		"creationToken": request.CreationToken,
-		"resourceGroup": resourceGroup,
-		"netAppAccount": netappAccount,
-		"capacityPool":  cPoolName,
-		"subnetID":      request.SubnetID,
-		"snapshotID":    request.SnapshotID,
-		"snapshotDir":   request.SnapshotDirectory,
-	}).Debug("Issuing create request.")
+	// Only set the cool access fields if cool access was requested
+	if request.CoolAccess {
+		newVol.Properties.CoolAccess = &request.CoolAccess
+		if request.CoolnessPeriod != 0 {
+			newVol.Properties.CoolnessPeriod = &request.CoolnessPeriod
+		}
+	}
 
-	logFields := LogFields{
-		"API":           "VolumesClient.BeginCreateOrUpdate",
-		"volume":        volumeFullName,
-		"creationToken": request.CreationToken,
+	// Only enable LDAP if requested, since it requires an Active Directory connection on the account
+	if request.LdapEnabled {
+		newVol.Properties.LdapEnabled = &request.LdapEnabled
 	}
 
-	var rawResponse *http.Response
-	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+	// Only set the SMB share options if requested, since they are only valid for SMB volumes
+	if request.SmbContinuouslyAvailable {
+		newVol.Properties.SmbContinuouslyAvailable = &request.SmbContinuouslyAvailable
+	}
+	if request.SmbEncryption {
+		newVol.Properties.SmbEncryption = &request.SmbEncryption
+	}
 
-	_, err := c.sdkClient.VolumesClient.BeginCreateOrUpdate(responseCtx,
-		resourceGroup, netappAccount, cPoolName, request.Name, newVol, nil)
+	// Only set the large volume flag if requested, since ANF creates regular volumes by default
+	if request.IsLargeVolume {
+		newVol.Properties.IsLargeVolume = &request.IsLargeVolume
+	}
 
-	logFields["correlationID"] = GetCorrelationID(rawResponse)
+	// Only set the throughput if requested, since it is only valid for manual QoS capacity pools
+	if request.ThroughputMibps > 0 {
+		newVol.Properties.ThroughputMibps = &request.ThroughputMibps
+	}
 
-	if err != nil {
-		Logc(ctx).WithFields(logFields).WithError(err).Error("Error creating volume.")
-		return nil, err
+	// Only set the security style if requested, so ANF picks its own default for each protocol
+	if request.SecurityStyle != "" {
+		securityStyle := netapp.SecurityStyle(request.SecurityStyle)
+		newVol.Properties.SecurityStyle = &securityStyle
 	}
 
-	Logc(ctx).WithFields(logFields).Info("Volume create request issued.")
+	// Only set the snapshot policy if one was requested
+	if request.SnapshotPolicy != "" {
+		snapshotPolicyID := c.snapshotPolicyID(resourceGroup, netappAccount, request.SnapshotPolicy)
+		newVol.Properties.DataProtection = &netapp.VolumePropertiesDataProtection{
+			Snapshot: &netapp.VolumeSnapshotProperties{SnapshotPolicyID: &snapshotPolicyID},
+		}
+	}
 
-	// The volume doesn't exist yet, so forge the volume ID to enable conversion to a FileSystem struct
-	newVolID := CreateVolumeID(c.config.SubscriptionID, resourceGroup, netappAccount, cPoolName, request.Name)
-	newVol.ID = &newVolID
+	// Only enable backups if requested, optionally with a policy for scheduled backups
+	if request.BackupEnabled {
+		if newVol.Properties.DataProtection == nil {
+			newVol.Properties.DataProtection = &netapp.VolumePropertiesDataProtection{}
+		}
+		newVol.Properties.DataProtection.Backup = &netapp.VolumeBackupProperties{
+			BackupEnabled: utils.Ptr(true),
+		}
+		if request.BackupPolicy != "" {
+			backupPolicyID := c.backupPolicyID(resourceGroup, netappAccount, request.BackupPolicy)
+			newVol.Properties.DataProtection.Backup.BackupPolicyID = &backupPolicyID
+		}
+	}
 
-	return c.newFileSystemFromVolume(ctx, &newVol)
-}
+	// Create a data protection volume if this is to be a replication destination
+	if request.ReplicationSourceID != "" {
+		volumeType := VolumeTypeDataProtection
+		endpointType := netapp.EndpointTypeDst
+		schedule := netapp.ReplicationSchedule(request.ReplicationSchedule)
 
-// ModifyVolume updates attributes of a volume.
-func (c Client) ModifyVolume(
-	ctx context.Context, filesystem *FileSystem, labels map[string]string, unixPermissions *string, snapshotDirAccess *bool, exportRule *ExportRule,
-) error {
-	logFields := LogFields{
-		"API":    "VolumesClient.Get",
+		newVol.Properties.VolumeType = &volumeType
+		if newVol.Properties.DataProtection == nil {
+			newVol.Properties.DataProtection = &netapp.VolumePropertiesDataProtection{}
+		}
+		newVol.Properties.DataProtection.Replication = &netapp.ReplicationObject{
+			RemoteVolumeResourceID: &request.ReplicationSourceID,
+			EndpointType:           &endpointType,
+			ReplicationSchedule:    &schedule,
+		}
+	}
+
+	// Only set the application volume group fields on members of a group
+	if request.VolumeSpecName != "" {
+		newVol.Properties.VolumeSpecName = &request.VolumeSpecName
+	}
+	if request.ProximityPlacementGroup != "" {
+		newVol.Properties.ProximityPlacementGroup = &request.ProximityPlacementGroup
+	}
+
+	return newVol
+}
+
+// CreateVolumeGroup creates an application volume group, which creates all of its member volumes in one
+// request.  The member volumes are returned as requested, since they don't exist yet.
+func (c Client) CreateVolumeGroup(
+	ctx context.Context, request *VolumeGroupCreateRequest,
+) (newVolumes []*FileSystem, err error) {
+	defer c.recordOperation(OperationVolumeGroupCreate, &err)()
+
+	if len(request.Volumes) == 0 {
+		return nil, fmt.Errorf("volume group %s has no volumes", request.Name)
+	}
+
+	var location string
+	groupVolumes := make([]*netapp.VolumeGroupVolumeProperties, 0, len(request.Volumes))
+	sdkVolumes := make([]netapp.Volume, 0, len(request.Volumes))
+
+	for _, volumeRequest := range request.Volumes {
+
+		// Get the capacity pool so we can determine location and service level
+		cPoolFullName := CreateCapacityPoolFullName(volumeRequest.ResourceGroup, volumeRequest.NetAppAccount,
+			volumeRequest.CapacityPool)
+		cPool := c.capacityPool(cPoolFullName)
+		if cPool == nil {
+			return nil, fmt.Errorf("unknown capacity pool %s", cPoolFullName)
+		}
+		location = cPool.Location
+
+		newVol := c.newSDKVolume(volumeRequest, cPool)
+
+		// Group members aren't created under their capacity pool, so they must name it
+		newVol.Properties.CapacityPoolResourceID = &cPool.ID
+
+		groupVolumes = append(groupVolumes, &netapp.VolumeGroupVolumeProperties{
+			Name:       newVol.Name,
+			Tags:       newVol.Tags,
+			Properties: newVol.Properties,
+		})
+
+		// The volume doesn't exist yet, so forge the volume ID to enable conversion to a FileSystem struct
+		newVolID := CreateVolumeID(c.config.SubscriptionID, volumeRequest.ResourceGroup,
+			volumeRequest.NetAppAccount, volumeRequest.CapacityPool, volumeRequest.Name)
+		newVol.ID = &newVolID
+		sdkVolumes = append(sdkVolumes, newVol)
+	}
+
+	applicationType := netapp.ApplicationType(request.ApplicationType)
+	group := netapp.VolumeGroupDetails{
+		Location: &location,
+		Properties: &netapp.VolumeGroupProperties{
+			GroupMetaData: &netapp.VolumeGroupMetaData{
+				ApplicationIdentifier: &request.ApplicationIdentifier,
+				ApplicationType:       &applicationType,
+				GroupDescription:      &request.Description,
+			},
+			Volumes: groupVolumes,
+		},
+	}
+
+	// SAP HANA volume groups must follow the SAP HANA deployment specification
+	if request.ApplicationType == ApplicationTypeSAPHANA {
+		group.Properties.GroupMetaData.DeploymentSpecID = utils.Ptr(SAPHANADeploymentSpecID)
+	}
+
+	logFields := LogFields{
+		"API":         "VolumeGroupsClient.BeginCreate",
+		"volumeGroup": request.ResourceGroup + "/" + request.NetAppAccount + "/" + request.Name,
+		"volumes":     len(groupVolumes),
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.VolumeGroupsClient.BeginCreate(responseCtx,
+		request.ResourceGroup, request.NetAppAccount, request.Name, group, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error creating volume group.")
+		return nil, err
+	}
+
+	Logc(ctx).WithFields(logFields).Info("Volume group create request issued.")
+
+	newVolumes = make([]*FileSystem, 0, len(sdkVolumes))
+	for i := range sdkVolumes {
+		var newVolume *FileSystem
+		if newVolume, err = c.newFileSystemFromVolume(ctx, &sdkVolumes[i]); err != nil {
+			return nil, err
+		}
+		newVolumes = append(newVolumes, newVolume)
+	}
+
+	return newVolumes, nil
+}
+
+// ModifyVolume updates attributes of a volume.
+func (c Client) ModifyVolume(
+	ctx context.Context, filesystem *FileSystem, labels map[string]string, unixPermissions *string, snapshotDirAccess *bool, exportRule *ExportRule,
+) (err error) {
+	defer c.recordOperation(OperationVolumeModify, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.Get",
 		"volume": filesystem.FullName,
 	}
 
@@ -1064,66 +1681,576 @@ func (c Client) ModifyVolume(
 			tags[k] = &tag[0]
 		}
 
-		anfVolume.Tags = tags
+		anfVolume.Tags = tags
+	}
+
+	if unixPermissions != nil {
+		anfVolume.Properties.UnixPermissions = unixPermissions
+	}
+
+	if snapshotDirAccess != nil {
+		anfVolume.Properties.SnapshotDirectoryVisible = snapshotDirAccess
+	}
+
+	// Modify the export-rule to update the allowed clients and restrict the kerberos protocol type
+	if &anfVolume.Properties.ExportPolicy.Rules[0] != nil && &exportRule != nil {
+		if exportRule.AllowedClients != "" {
+			anfVolume.Properties.ExportPolicy.Rules[0].AllowedClients = &exportRule.AllowedClients
+		}
+		anfVolume.Properties.ExportPolicy.Rules[0].Nfsv41 = &exportRule.Nfsv41
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5ReadWrite = &exportRule.Kerberos5ReadWrite
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5ReadOnly = &exportRule.Kerberos5ReadOnly
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5IReadWrite = &exportRule.Kerberos5IReadWrite
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5IReadOnly = &exportRule.Kerberos5IReadOnly
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5PReadWrite = &exportRule.Kerberos5PReadWrite
+		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5PReadOnly = &exportRule.Kerberos5PReadOnly
+	}
+
+	// Clear out ReadOnly and other fields that we don't want to change when merely relabeling.
+	serviceLevel := netapp.ServiceLevel("")
+	anfVolume.Properties.ServiceLevel = &serviceLevel
+	anfVolume.Properties.ProvisioningState = nil
+	anfVolume.Properties.MountTargets = nil
+	anfVolume.Properties.ThroughputMibps = nil
+	anfVolume.Properties.BaremetalTenantID = nil
+
+	Logc(ctx).WithFields(LogFields{
+		"name":          anfVolume.Name,
+		"creationToken": anfVolume.Properties.CreationToken,
+	}).Debug("Modifying volume.")
+
+	logFields = LogFields{
+		"API":    "VolumesClient.BeginCreateOrUpdate",
+		"volume": filesystem.FullName,
+	}
+
+	_, err = c.sdkClient.VolumesClient.BeginCreateOrUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, anfVolume, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume modified.")
+
+	return nil
+}
+
+// ResizeVolume sends a VolumePatch to update a volume's quota.
+func (c Client) ResizeVolume(ctx context.Context, filesystem *FileSystem, newSizeBytes int64) (err error) {
+	defer c.recordOperation(OperationVolumeResize, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginUpdate",
+		"volume": filesystem.FullName,
+	}
+
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			UsageThreshold: &newSizeBytes,
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error resizing volume.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume resize request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume resize result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume resize complete.")
+
+	return nil
+}
+
+// RelocateVolume moves a volume to another capacity pool in the same NetApp account, which changes the
+// volume's service level.  The volume's identifying fields are updated to reflect its new location.
+func (c Client) RelocateVolume(ctx context.Context, filesystem *FileSystem, cPool *CapacityPool) (err error) {
+	defer c.recordOperation(OperationVolumeRelocate, &err)()
+
+	logFields := LogFields{
+		"API":          "VolumesClient.BeginPoolChange",
+		"volume":       filesystem.FullName,
+		"capacityPool": cPool.FullName,
+	}
+
+	body := netapp.PoolChangeRequest{
+		NewPoolResourceID: &cPool.ID,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginPoolChange(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, body, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error relocating volume.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume relocate request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume relocate result.")
+		return err
+	}
+
+	filesystem.CapacityPool = cPool.Name
+	filesystem.ServiceLevel = cPool.ServiceLevel
+	filesystem.ID = CreateVolumeID(c.config.SubscriptionID, filesystem.ResourceGroup, filesystem.NetAppAccount,
+		cPool.Name, filesystem.Name)
+	filesystem.FullName = CreateVolumeFullName(filesystem.ResourceGroup, filesystem.NetAppAccount, cPool.Name,
+		filesystem.Name)
+
+	Logc(ctx).WithFields(logFields).Debug("Volume relocate complete.")
+
+	return nil
+}
+
+// UpgradeVolumeNetworkFeatures upgrades a volume's network features from Basic to Standard in place.
+// ANF doesn't support the reverse, so this is the only network features change allowed.
+func (c Client) UpgradeVolumeNetworkFeatures(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationVolumeNetworkFeaturesUpgrade, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginUpdate",
+		"volume": filesystem.FullName,
+	}
+
+	if filesystem.NetworkFeatures != NetworkFeaturesBasic {
+		return fmt.Errorf("volume %s network features are %s, not %s", filesystem.FullName,
+			filesystem.NetworkFeatures, NetworkFeaturesBasic)
+	}
+
+	networkFeatures := netapp.NetworkFeaturesStandard
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			NetworkFeatures: &networkFeatures,
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error upgrading volume network features.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume network features upgrade request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume network features upgrade result.")
+		return err
+	}
+
+	filesystem.NetworkFeatures = NetworkFeaturesStandard
+
+	Logc(ctx).WithFields(logFields).Debug("Volume network features upgrade complete.")
+
+	return nil
+}
+
+// AuthorizeReplication authorizes a source volume, specified by its resource ID, to replicate to a
+// destination volume.  This establishes the replication relationship.
+func (c Client) AuthorizeReplication(
+	ctx context.Context, sourceVolumeID string, destination *FileSystem,
+) (err error) {
+	defer c.recordOperation(OperationReplicationAuthorize, &err)()
+
+	_, resourceGroup, _, netappAccount, cPoolName, volumeName, err := ParseVolumeID(sourceVolumeID)
+	if err != nil {
+		return err
+	}
+
+	logFields := LogFields{
+		"API":         "VolumesClient.BeginAuthorizeReplication",
+		"volume":      CreateVolumeFullName(resourceGroup, netappAccount, cPoolName, volumeName),
+		"destination": destination.FullName,
+	}
+
+	body := netapp.AuthorizeRequest{
+		RemoteVolumeResourceID: &destination.ID,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginAuthorizeReplication(responseCtx,
+		resourceGroup, netappAccount, cPoolName, volumeName, body, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error authorizing replication.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication authorize request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for replication authorize result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication authorize complete.")
+
+	return nil
+}
+
+// BreakReplication stops replication to a destination volume, making it writable.
+func (c Client) BreakReplication(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationReplicationBreak, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginBreakReplication",
+		"volume": filesystem.FullName,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginBreakReplication(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error breaking replication.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication break request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for replication break result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication break complete.")
+
+	return nil
+}
+
+// ResyncReplication resumes replication to a destination volume after it was broken.  Any changes made
+// to the destination volume since the break are discarded.
+func (c Client) ResyncReplication(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationReplicationResync, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginResyncReplication",
+		"volume": filesystem.FullName,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginResyncReplication(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error resyncing replication.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication resync request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for replication resync result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication resync complete.")
+
+	return nil
+}
+
+// DeleteReplication removes the replication relationship from a destination volume.
+func (c Client) DeleteReplication(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationReplicationDelete, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginDeleteReplication",
+		"volume": filesystem.FullName,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginDeleteReplication(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error deleting replication.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication delete request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for replication delete result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Replication delete complete.")
+
+	return nil
+}
+
+// ReplicationStatus returns the state of the replication relationship of a destination volume.
+func (c Client) ReplicationStatus(
+	ctx context.Context, filesystem *FileSystem,
+) (status *ReplicationStatus, err error) {
+	defer c.recordOperation(OperationReplicationStatus, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.ReplicationStatus",
+		"volume": filesystem.FullName,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	response, err := c.sdkClient.VolumesClient.ReplicationStatus(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error getting replication status.")
+		return nil, err
+	}
+
+	status = &ReplicationStatus{
+		Healthy:       DerefBool(response.Healthy),
+		TotalProgress: DerefString(response.TotalProgress),
+		ErrorMessage:  DerefString(response.ErrorMessage),
+	}
+	if response.MirrorState != nil {
+		status.MirrorState = string(*response.MirrorState)
+	}
+	if response.RelationshipStatus != nil {
+		status.RelationshipStatus = string(*response.RelationshipStatus)
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Read replication status.")
+
+	return status, nil
+}
+
+// ModifyVolumeExportPolicy replaces the rules of a volume's export policy.
+func (c Client) ModifyVolumeExportPolicy(
+	ctx context.Context, filesystem *FileSystem, exportPolicy *ExportPolicy,
+) (err error) {
+	defer c.recordOperation(OperationVolumeModify, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginUpdate",
+		"volume": filesystem.FullName,
+		"rules":  len(exportPolicy.Rules),
+	}
+
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			ExportPolicy: &netapp.VolumePatchPropertiesExportPolicy{
+				Rules: exportPolicyExport(exportPolicy).Rules,
+			},
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume export policy.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume export policy modify request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume export policy modify result.")
+		return err
+	}
+
+	filesystem.ExportPolicy = *exportPolicy
+
+	Logc(ctx).WithFields(logFields).Debug("Volume export policy modify complete.")
+
+	return nil
+}
+
+// ModifyVolumeCoolAccess enables or disables cool access (tiering) on a volume.  The coolness period is
+// only sent if cool access is enabled and a period is specified.
+func (c Client) ModifyVolumeCoolAccess(
+	ctx context.Context, filesystem *FileSystem, coolAccess bool, coolnessPeriod int32,
+) (err error) {
+	defer c.recordOperation(OperationVolumeModify, &err)()
+
+	logFields := LogFields{
+		"API":        "VolumesClient.BeginUpdate",
+		"volume":     filesystem.FullName,
+		"coolAccess": coolAccess,
+	}
+
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			CoolAccess: &coolAccess,
+		},
+	}
+	if coolAccess && coolnessPeriod != 0 {
+		patch.Properties.CoolnessPeriod = &coolnessPeriod
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume cool access.")
+		return err
 	}
 
-	if unixPermissions != nil {
-		anfVolume.Properties.UnixPermissions = unixPermissions
-	}
+	Logc(ctx).WithFields(logFields).Debug("Volume cool access modify request issued.")
 
-	if snapshotDirAccess != nil {
-		anfVolume.Properties.SnapshotDirectoryVisible = snapshotDirAccess
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume cool access modify result.")
+		return err
 	}
 
-	// Modify the export-rule to restrict the kerberos protocol type
-	if &anfVolume.Properties.ExportPolicy.Rules[0] != nil && &exportRule != nil {
-		anfVolume.Properties.ExportPolicy.Rules[0].Nfsv41 = &exportRule.Nfsv41
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5ReadWrite = &exportRule.Kerberos5ReadWrite
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5ReadOnly = &exportRule.Kerberos5ReadOnly
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5IReadWrite = &exportRule.Kerberos5IReadWrite
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5IReadOnly = &exportRule.Kerberos5IReadOnly
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5PReadWrite = &exportRule.Kerberos5PReadWrite
-		anfVolume.Properties.ExportPolicy.Rules[0].Kerberos5PReadOnly = &exportRule.Kerberos5PReadOnly
+	filesystem.CoolAccess = coolAccess
+	if coolAccess && coolnessPeriod != 0 {
+		filesystem.CoolnessPeriod = coolnessPeriod
 	}
 
-	// Clear out ReadOnly and other fields that we don't want to change when merely relabeling.
-	serviceLevel := netapp.ServiceLevel("")
-	anfVolume.Properties.ServiceLevel = &serviceLevel
-	anfVolume.Properties.ProvisioningState = nil
-	anfVolume.Properties.MountTargets = nil
-	anfVolume.Properties.ThroughputMibps = nil
-	anfVolume.Properties.BaremetalTenantID = nil
+	Logc(ctx).WithFields(logFields).Debug("Volume cool access modify complete.")
 
-	Logc(ctx).WithFields(LogFields{
-		"name":          anfVolume.Name,
-		"creationToken": anfVolume.Properties.CreationToken,
-	}).Debug("Modifying volume.")
+	return nil
+}
 
-	logFields = LogFields{
-		"API":    "VolumesClient.BeginCreateOrUpdate",
-		"volume": filesystem.FullName,
+// ModifyVolumeThroughput sets the throughput of a volume in a manual QoS capacity pool.
+func (c Client) ModifyVolumeThroughput(
+	ctx context.Context, filesystem *FileSystem, throughputMibps float32,
+) (err error) {
+	defer c.recordOperation(OperationVolumeModify, &err)()
+
+	logFields := LogFields{
+		"API":        "VolumesClient.BeginUpdate",
+		"volume":     filesystem.FullName,
+		"throughput": throughputMibps,
 	}
 
-	_, err = c.sdkClient.VolumesClient.BeginCreateOrUpdate(responseCtx,
-		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, anfVolume, nil)
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			ThroughputMibps: &throughputMibps,
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
 
 	logFields["correlationID"] = GetCorrelationID(rawResponse)
 
 	if err != nil {
-		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume.")
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume throughput.")
 		return err
 	}
 
-	Logc(ctx).WithFields(logFields).Debug("Volume modified.")
+	Logc(ctx).WithFields(logFields).Debug("Volume throughput modify request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume throughput modify result.")
+		return err
+	}
+
+	filesystem.ThroughputMibps = throughputMibps
+
+	Logc(ctx).WithFields(logFields).Debug("Volume throughput modify complete.")
 
 	return nil
 }
 
-// ResizeVolume sends a VolumePatch to update a volume's quota.
-func (c Client) ResizeVolume(ctx context.Context, filesystem *FileSystem, newSizeBytes int64) error {
+// ModifyVolumeSnapshotPolicy assigns a snapshot policy, specified by name or ID, to a volume.  Nothing is
+// done if the volume already has the policy.
+func (c Client) ModifyVolumeSnapshotPolicy(
+	ctx context.Context, filesystem *FileSystem, snapshotPolicy string,
+) (err error) {
+	snapshotPolicyID := c.snapshotPolicyID(filesystem.ResourceGroup, filesystem.NetAppAccount, snapshotPolicy)
+	if strings.EqualFold(snapshotPolicyID, filesystem.SnapshotPolicyID) {
+		return nil
+	}
+
+	defer c.recordOperation(OperationVolumeModify, &err)()
+
 	logFields := LogFields{
-		"API":    "VolumesClient.BeginUpdate",
-		"volume": filesystem.FullName,
+		"API":            "VolumesClient.BeginUpdate",
+		"volume":         filesystem.FullName,
+		"snapshotPolicy": snapshotPolicyID,
 	}
 
 	patch := netapp.VolumePatch{
@@ -1131,7 +2258,9 @@ func (c Client) ResizeVolume(ctx context.Context, filesystem *FileSystem, newSiz
 		Location: &filesystem.Location,
 		Name:     &filesystem.Name,
 		Properties: &netapp.VolumePatchProperties{
-			UsageThreshold: &newSizeBytes,
+			DataProtection: &netapp.VolumePatchPropertiesDataProtection{
+				Snapshot: &netapp.VolumeSnapshotProperties{SnapshotPolicyID: &snapshotPolicyID},
+			},
 		},
 	}
 
@@ -1144,25 +2273,65 @@ func (c Client) ResizeVolume(ctx context.Context, filesystem *FileSystem, newSiz
 	logFields["correlationID"] = GetCorrelationID(rawResponse)
 
 	if err != nil {
-		Logc(ctx).WithFields(logFields).WithError(err).Error("Error resizing volume.")
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume snapshot policy.")
 		return err
 	}
 
-	Logc(ctx).WithFields(logFields).Debug("Volume resize request issued.")
+	Logc(ctx).WithFields(logFields).Debug("Volume snapshot policy modify request issued.")
 
 	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
 	if err != nil {
-		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume resize result.")
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume snapshot policy modify result.")
 		return err
 	}
 
-	Logc(ctx).WithFields(logFields).Debug("Volume resize complete.")
+	filesystem.SnapshotPolicyID = snapshotPolicyID
+
+	Logc(ctx).WithFields(logFields).Debug("Volume snapshot policy modify complete.")
 
 	return nil
 }
 
+// SnapshotPolicyExists checks whether a snapshot policy, specified by ID, exists.
+func (c Client) SnapshotPolicyExists(ctx context.Context, snapshotPolicyID string) (exists bool, err error) {
+	defer c.recordOperation(OperationSnapshotPolicyGet, &err)()
+
+	_, resourceGroup, _, netappAccount, snapshotPolicy, err := ParseSnapshotPolicyID(snapshotPolicyID)
+	if err != nil {
+		return false, err
+	}
+
+	logFields := LogFields{
+		"API":            "SnapshotPoliciesClient.Get",
+		"snapshotPolicy": snapshotPolicyID,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.PoliciesClient.Get(responseCtx, resourceGroup, netappAccount, snapshotPolicy, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		if IsANFNotFoundError(err) {
+			Logc(ctx).WithFields(logFields).Debug("Snapshot policy not found.")
+			return false, nil
+		}
+
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error fetching snapshot policy.")
+		return false, err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Found snapshot policy.")
+
+	return true, nil
+}
+
 // DeleteVolume deletes a volume.
-func (c Client) DeleteVolume(ctx context.Context, filesystem *FileSystem) error {
+func (c Client) DeleteVolume(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationVolumeDelete, &err)()
+
 	logFields := LogFields{
 		"API":    "VolumesClient.BeginDelete",
 		"volume": filesystem.FullName,
@@ -1171,7 +2340,7 @@ func (c Client) DeleteVolume(ctx context.Context, filesystem *FileSystem) error
 	var rawResponse *http.Response
 	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
 
-	_, err := c.sdkClient.VolumesClient.BeginDelete(responseCtx,
+	_, err = c.sdkClient.VolumesClient.BeginDelete(responseCtx,
 		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
 
 	logFields["correlationID"] = GetCorrelationID(rawResponse)
@@ -1191,6 +2360,38 @@ func (c Client) DeleteVolume(ctx context.Context, filesystem *FileSystem) error
 	return nil
 }
 
+// DeleteVolumeGroup deletes an application volume group.  ANF only deletes a group once its member volumes
+// are gone, so those must be deleted first.
+func (c Client) DeleteVolumeGroup(ctx context.Context, resourceGroup, netAppAccount, name string) (err error) {
+	defer c.recordOperation(OperationVolumeGroupDelete, &err)()
+
+	logFields := LogFields{
+		"API":         "VolumeGroupsClient.BeginDelete",
+		"volumeGroup": resourceGroup + "/" + netAppAccount + "/" + name,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.VolumeGroupsClient.BeginDelete(responseCtx, resourceGroup, netAppAccount, name, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		if IsANFNotFoundError(err) {
+			Logc(ctx).WithFields(logFields).Info("Volume group already deleted.")
+			return nil
+		}
+
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error deleting volume group.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume group deleted.")
+
+	return nil
+}
+
 // ///////////////////////////////////////////////////////////////////////////////
 // Functions to retrieve and manage snapshots
 // ///////////////////////////////////////////////////////////////////////////////
@@ -1236,7 +2437,11 @@ func (c Client) newSnapshotFromANFSnapshot(_ context.Context, anfSnapshot *netap
 }
 
 // SnapshotsForVolume returns a list of snapshots on a volume.
-func (c Client) SnapshotsForVolume(ctx context.Context, filesystem *FileSystem) (*[]*Snapshot, error) {
+func (c Client) SnapshotsForVolume(
+	ctx context.Context, filesystem *FileSystem,
+) (snapshotList *[]*Snapshot, err error) {
+	defer c.recordOperation(OperationSnapshotList, &err)()
+
 	logFields := LogFields{
 		"API":    "SnapshotsClient.NewListPager",
 		"volume": filesystem.FullName,
@@ -1278,7 +2483,9 @@ func (c Client) SnapshotsForVolume(ctx context.Context, filesystem *FileSystem)
 // SnapshotForVolume fetches a specific snapshot on a volume by its name.
 func (c Client) SnapshotForVolume(
 	ctx context.Context, filesystem *FileSystem, snapshotName string,
-) (*Snapshot, error) {
+) (foundSnapshot *Snapshot, err error) {
+	defer c.recordOperation(OperationSnapshotGet, &err)()
+
 	logFields := LogFields{
 		"API":      "SnapshotsClient.Get",
 		"volume":   filesystem.FullName,
@@ -1306,7 +2513,12 @@ func (c Client) SnapshotForVolume(
 
 	Logc(ctx).WithFields(logFields).Debug("Found snapshot.")
 
-	return c.newSnapshotFromANFSnapshot(ctx, &response.Snapshot)
+	snapshot, err := c.newSnapshotFromANFSnapshot(ctx, &response.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
 }
 
 // WaitForSnapshotState waits for a desired snapshot state and returns once that state is achieved.
@@ -1358,9 +2570,13 @@ func (c Client) WaitForSnapshotState(
 
 	Logc(ctx).WithField("desiredState", desiredState).Info("Waiting for snapshot state.")
 
-	if err := backoff.RetryNotify(checkSnapshotState, stateBackoff, stateNotify); err != nil {
+	// Stop waiting as soon as the caller gives up
+	if err := backoff.RetryNotify(checkSnapshotState, backoff.WithContext(stateBackoff, ctx),
+		stateNotify); err != nil {
 		if IsTerminalStateError(err) {
 			Logc(ctx).WithError(err).Error("Snapshot reached terminal state.")
+		} else if ctx.Err() != nil {
+			Logc(ctx).WithError(ctx.Err()).Warningf("Stopped waiting for snapshot state %s.", desiredState)
 		} else {
 			Logc(ctx).Warningf("Snapshot state was not %s after %3.2f seconds.",
 				desiredState, stateBackoff.MaxElapsedTime.Seconds())
@@ -1374,7 +2590,11 @@ func (c Client) WaitForSnapshotState(
 }
 
 // CreateSnapshot creates a new snapshot.
-func (c Client) CreateSnapshot(ctx context.Context, filesystem *FileSystem, name string) (*Snapshot, error) {
+func (c Client) CreateSnapshot(
+	ctx context.Context, filesystem *FileSystem, name string,
+) (newSnapshot *Snapshot, err error) {
+	defer c.recordOperation(OperationSnapshotCreate, &err)()
+
 	logFields := LogFields{
 		"API":      "SnapshotsClient.BeginCreate",
 		"volume":   filesystem.FullName,
@@ -1390,7 +2610,7 @@ func (c Client) CreateSnapshot(ctx context.Context, filesystem *FileSystem, name
 	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
 
 	// Create the snapshot
-	_, err := c.sdkClient.SnapshotsClient.BeginCreate(responseCtx,
+	_, err = c.sdkClient.SnapshotsClient.BeginCreate(responseCtx,
 		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool,
 		filesystem.Name, name, anfSnapshot, nil)
 
@@ -1410,11 +2630,18 @@ func (c Client) CreateSnapshot(ctx context.Context, filesystem *FileSystem, name
 
 	anfSnapshot.Properties = &netapp.SnapshotProperties{}
 
-	return c.newSnapshotFromANFSnapshot(ctx, &anfSnapshot)
+	snapshot, err := c.newSnapshotFromANFSnapshot(ctx, &anfSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
 }
 
 // RestoreSnapshot restores a volume to a snapshot.
-func (c Client) RestoreSnapshot(ctx context.Context, filesystem *FileSystem, snapshot *Snapshot) error {
+func (c Client) RestoreSnapshot(ctx context.Context, filesystem *FileSystem, snapshot *Snapshot) (err error) {
+	defer c.recordOperation(OperationSnapshotRestore, &err)()
+
 	logFields := LogFields{
 		"API":      "SnapshotsClient.BeginRevert",
 		"volume":   filesystem.FullName,
@@ -1428,7 +2655,7 @@ func (c Client) RestoreSnapshot(ctx context.Context, filesystem *FileSystem, sna
 		SnapshotID: utils.Ptr(snapshot.SnapshotID),
 	}
 
-	_, err := c.sdkClient.VolumesClient.BeginRevert(responseCtx,
+	_, err = c.sdkClient.VolumesClient.BeginRevert(responseCtx,
 		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool,
 		filesystem.Name, revertBody, nil)
 
@@ -1444,8 +2671,62 @@ func (c Client) RestoreSnapshot(ctx context.Context, filesystem *FileSystem, sna
 	return nil
 }
 
+// SplitCloneFromParent splits a clone from the snapshot it was created from and waits for the split to finish.
+// The vendored SDK predates this operation, so the request is sent directly to the ANF REST API.
+func (c Client) SplitCloneFromParent(ctx context.Context, filesystem *FileSystem) (err error) {
+	defer c.recordOperation(OperationVolumeSplit, &err)()
+
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginSplitCloneFromParent",
+		"volume": filesystem.FullName,
+	}
+
+	pipeline := c.sdkClient.ResourceClient.Pipeline()
+
+	req, err := runtime.NewRequest(ctx, http.MethodPost,
+		runtime.JoinPaths(c.sdkClient.ResourceClient.Endpoint(), filesystem.ID, "splitCloneFromParent"))
+	if err != nil {
+		return err
+	}
+	query := req.Raw().URL.Query()
+	query.Set("api-version", SplitCloneAPIVersion)
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header["Accept"] = []string{"application/json"}
+
+	rawResponse, err := pipeline.Do(req)
+	if err == nil && !runtime.HasStatusCode(rawResponse, http.StatusOK, http.StatusAccepted) {
+		err = runtime.NewResponseError(rawResponse)
+	}
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error splitting clone from parent.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Clone split started.")
+
+	poller, err := runtime.NewPoller[struct{}](rawResponse, pipeline, nil)
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for clone split.")
+		return err
+	}
+
+	if _, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: SplitClonePollFrequency}); err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error splitting clone from parent.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Clone split from parent.")
+
+	return nil
+}
+
 // DeleteSnapshot deletes a snapshot.
-func (c Client) DeleteSnapshot(ctx context.Context, filesystem *FileSystem, snapshot *Snapshot) error {
+func (c Client) DeleteSnapshot(ctx context.Context, filesystem *FileSystem, snapshot *Snapshot) (err error) {
+	defer c.recordOperation(OperationSnapshotDelete, &err)()
+
 	logFields := LogFields{
 		"API":      "SnapshotsClient.BeginDelete",
 		"volume":   filesystem.FullName,
@@ -1455,7 +2736,7 @@ func (c Client) DeleteSnapshot(ctx context.Context, filesystem *FileSystem, snap
 	var rawResponse *http.Response
 	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
 
-	_, err := c.sdkClient.SnapshotsClient.BeginDelete(responseCtx,
+	_, err = c.sdkClient.SnapshotsClient.BeginDelete(responseCtx,
 		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool,
 		filesystem.Name, snapshot.Name, nil)
 
@@ -1476,6 +2757,77 @@ func (c Client) DeleteSnapshot(ctx context.Context, filesystem *FileSystem, snap
 	return nil
 }
 
+// CreateBackup creates a backup of an existing snapshot.  The backup has the same name as the snapshot.
+func (c Client) CreateBackup(ctx context.Context, filesystem *FileSystem, snapshotName string) (err error) {
+	defer c.recordOperation(OperationBackupCreate, &err)()
+
+	logFields := LogFields{
+		"API":    "BackupsClient.BeginCreate",
+		"volume": filesystem.FullName,
+		"backup": snapshotName,
+	}
+
+	anfBackup := netapp.Backup{
+		Location: &filesystem.Location,
+		Properties: &netapp.BackupProperties{
+			Label:               utils.Ptr("trident"),
+			UseExistingSnapshot: utils.Ptr(true),
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.BackupsClient.BeginCreate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool,
+		filesystem.Name, snapshotName, anfBackup, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error creating backup.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Info("Backup create request issued.")
+
+	return nil
+}
+
+// DeleteBackup deletes a backup of a volume.
+func (c Client) DeleteBackup(ctx context.Context, filesystem *FileSystem, backupName string) (err error) {
+	defer c.recordOperation(OperationBackupDelete, &err)()
+
+	logFields := LogFields{
+		"API":    "BackupsClient.BeginDelete",
+		"volume": filesystem.FullName,
+		"backup": backupName,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	_, err = c.sdkClient.BackupsClient.BeginDelete(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool,
+		filesystem.Name, backupName, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		if IsANFNotFoundError(err) {
+			Logc(ctx).WithFields(logFields).Info("Backup already deleted.")
+			return nil
+		}
+
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error deleting backup.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Backup delete request issued.")
+
+	return nil
+}
+
 // ///////////////////////////////////////////////////////////////////////////////
 // Functions to retrieve and manage subvolumes
 // ///////////////////////////////////////////////////////////////////////////////
@@ -1540,7 +2892,11 @@ func (c Client) updateSubvolumeFromSubvolumeModel(
 }
 
 // SubvolumesForVolume returns a list of subvolume on a volume.
-func (c Client) SubvolumesForVolume(ctx context.Context, filesystem *FileSystem) (*[]*Subvolume, error) {
+func (c Client) SubvolumesForVolume(
+	ctx context.Context, filesystem *FileSystem,
+) (subvolumeList *[]*Subvolume, err error) {
+	defer c.recordOperation(OperationSubvolumeList, &err)()
+
 	logFields := LogFields{
 		"API":    "SubvolumesClient.NewListByVolumePager",
 		"volume": filesystem.FullName,
@@ -1726,7 +3082,11 @@ func (c Client) SubvolumeByCreationToken(
 }
 
 // SubvolumeByID returns a Subvolume based on its Azure-style ID.
-func (c Client) SubvolumeByID(ctx context.Context, subvolumeID string, queryMetadata bool) (*Subvolume, error) {
+func (c Client) SubvolumeByID(
+	ctx context.Context, subvolumeID string, queryMetadata bool,
+) (foundSubvolume *Subvolume, err error) {
+	defer c.recordOperation(OperationSubvolumeGet, &err)()
+
 	logFields := LogFields{
 		"API": "SubvolumesClient.Get",
 		"ID":  subvolumeID,
@@ -1775,7 +3135,9 @@ func (c Client) SubvolumeByID(ctx context.Context, subvolumeID string, queryMeta
 }
 
 // SubvolumeMetadata fetches a Subvolume metadata, melds it into the supplied subvolume, and returns the result.
-func (c Client) SubvolumeMetadata(ctx context.Context, subvolume *Subvolume) (*Subvolume, error) {
+func (c Client) SubvolumeMetadata(ctx context.Context, subvolume *Subvolume) (foundSubvolume *Subvolume, err error) {
+	defer c.recordOperation(OperationSubvolumeMetadata, &err)()
+
 	logFields := LogFields{
 		"API": "SubvolumesClient.BeginGetMetadata",
 		"ID":  subvolume.ID,
@@ -1914,7 +3276,11 @@ func (c Client) WaitForSubvolumeState(
 }
 
 // CreateSubvolume creates a new subvolume
-func (c Client) CreateSubvolume(ctx context.Context, request *SubvolumeCreateRequest) (*Subvolume, PollerResponse, error) {
+func (c Client) CreateSubvolume(
+	ctx context.Context, request *SubvolumeCreateRequest,
+) (newSubvolume *Subvolume, pollerResponse PollerResponse, err error) {
+	defer c.recordOperation(OperationSubvolumeCreate, &err)()
+
 	subvolumeName := request.CreationToken
 
 	resourceGroup, netappAccount, cpoolName, volumeName, err := ParseVolumeName(request.Volume)
@@ -1980,7 +3346,9 @@ func (c Client) CreateSubvolume(ctx context.Context, request *SubvolumeCreateReq
 }
 
 // ResizeSubvolume sends a SubvolumePatchRequest to update a subvolume's size.
-func (c Client) ResizeSubvolume(ctx context.Context, subvolume *Subvolume, newSizeBytes int64) error {
+func (c Client) ResizeSubvolume(ctx context.Context, subvolume *Subvolume, newSizeBytes int64) (err error) {
+	defer c.recordOperation(OperationSubvolumeResize, &err)()
+
 	logFields := LogFields{
 		"API": "SubvolumesClient.BeginUpdate",
 		"ID":  subvolume.ID,
@@ -2023,7 +3391,11 @@ func (c Client) ResizeSubvolume(ctx context.Context, subvolume *Subvolume, newSi
 }
 
 // DeleteSubvolume deletes a subvolume.
-func (c Client) DeleteSubvolume(ctx context.Context, subvolume *Subvolume) (PollerResponse, error) {
+func (c Client) DeleteSubvolume(
+	ctx context.Context, subvolume *Subvolume,
+) (pollerResponse PollerResponse, err error) {
+	defer c.recordOperation(OperationSubvolumeDelete, &err)()
+
 	logFields := LogFields{
 		"API": "SubvolumesClient.BeginDelete",
 		"ID":  subvolume.ID,
@@ -2129,6 +3501,76 @@ func IsANFTooManyRequestsError(err error) bool {
 	return false
 }
 
+// IsANFConflictError checks whether an error returned from the ANF SDK contains a 409 (Conflict) error, which
+// ANF returns when a request clashes with another operation still in progress on the same resources.  Unlike
+// throttling, server errors, and network failures, the SDK doesn't retry conflicts by itself.
+func IsANFConflictError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var detailedErr *azcore.ResponseError
+	if errors.As(err, &detailedErr) {
+		return detailedErr.RawResponse != nil && detailedErr.RawResponse.StatusCode == http.StatusConflict
+	}
+
+	return false
+}
+
+// subnetExhaustedErrorCodes are the error codes Azure returns when a volume can't be given an IP address
+// because its delegated subnet has none left.
+var subnetExhaustedErrorCodes = []string{"SubnetIsFull", "InsufficientSubnetSize"}
+
+// IsANFSubnetExhaustedError checks whether an error, or any error combined into it, means that a volume's
+// delegated subnet has no IP addresses left.  A subnet is shared by every capacity pool, so one such error
+// means that no capacity pool can use the subnet.
+func IsANFSubnetExhaustedError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, e := range multierr.Errors(err) {
+		var detailedErr *azcore.ResponseError
+		if errors.As(e, &detailedErr) && utils.SliceContainsString(subnetExhaustedErrorCodes, detailedErr.ErrorCode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUnavailableError checks whether an error, or every error combined into it, means that Azure could not
+// be reached or could not serve a request for the time being, rather than that the request or the
+// credentials are wrong.  Network failures of any kind, timeouts, throttling, and server errors qualify.
+func IsUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, e := range multierr.Errors(err) {
+		var detailedErr *azcore.ResponseError
+		var netErr net.Error
+
+		switch {
+		case errors.As(e, &detailedErr):
+			if detailedErr.RawResponse == nil {
+				return false
+			}
+			statusCode := detailedErr.RawResponse.StatusCode
+			if statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests &&
+				statusCode < http.StatusInternalServerError {
+				return false
+			}
+		case errors.As(e, &netErr), errors.Is(e, context.DeadlineExceeded):
+			continue
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 // GetCorrelationIDFromError accepts an error returned from the ANF SDK and extracts the correlation
 // header, if present.
 func GetCorrelationIDFromError(err error) (id string) {
@@ -2250,6 +3692,14 @@ func DerefInt32(i *int32) int32 {
 	return 0
 }
 
+// DerefFloat32 accepts a float32 pointer and returns the value of the float32, or 0 if the pointer is nil.
+func DerefFloat32(f *float32) float32 {
+	if f != nil {
+		return *f
+	}
+	return 0
+}
+
 // DerefInt64 accepts an int64 pointer and returns the value of the int64, or 0 if the pointer is nil.
 func DerefInt64(i *int64) int64 {
 	if i != nil {
@@ -6,12 +6,15 @@ package api
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
-	netapp "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v4"
 	resourcegraph "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	features "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
 	"github.com/cenkalti/backoff/v4"
@@ -28,6 +31,7 @@ const (
 	PServiceLevel      = "serviceLevel"
 	PVirtualNetwork    = "virtualNetwork"
 	PSubnet            = "subnet"
+	PSubnetSelection   = "subnetSelection"
 	PResourceGroups    = "resourceGroups"
 	PNetappAccounts    = "netappAccounts"
 	PCapacityPools     = "capacityPools"
@@ -41,16 +45,28 @@ const (
 // RefreshAzureResources refreshes the cache of discovered Azure resources and validates
 // them against our known storage pools.
 func (c Client) RefreshAzureResources(ctx context.Context) error {
+	resources := c.resources()
+
 	// Check if it is time to update the cache
-	if time.Now().Before(c.sdkClient.AzureResources.lastUpdateTime.Add(c.config.MaxCacheAge)) {
+	if time.Now().Before(resources.lastUpdateTime.Add(c.config.MaxCacheAge)) {
 		Logc(ctx).Debugf("Cached resources not yet %v old, skipping refresh.", c.config.MaxCacheAge)
 		return nil
 	}
 
+	// Remember the previously discovered capacity pools so we can spot any changes to them
+	previousCapacityPools := resources.CapacityPoolMap
+
 	// (re-)Discover what we have to work with in Azure
 	Logc(ctx).Debugf("Discovering Azure resources.")
 	discoveryErr := multierr.Combine(c.DiscoverAzureResources(ctx))
 
+	// Warn about any capacity pools whose QoS type was changed outside of Trident
+	if discoveryErr == nil {
+		for _, change := range c.checkForChangedQosTypes(ctx, previousCapacityPools) {
+			c.logQosTypeChangeImpact(ctx, change)
+		}
+	}
+
 	// This is noisy, hide it behind api tracing.
 	c.dumpAzureResources(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["api"])
 
@@ -58,6 +74,7 @@ func (c Client) RefreshAzureResources(ctx context.Context) error {
 	c.checkForNonexistentResourceGroups(ctx)
 	c.checkForNonexistentNetAppAccounts(ctx)
 	c.checkForNonexistentCapacityPools(ctx)
+	c.checkForAmbiguousCapacityPools(ctx)
 	c.checkForNonexistentVirtualNetworks(ctx)
 	c.checkForNonexistentSubnets(ctx)
 
@@ -68,9 +85,45 @@ func (c Client) RefreshAzureResources(ctx context.Context) error {
 	Logc(ctx).Debugf("Discovering Azure preview features.")
 	discoveryErr = multierr.Combine(discoveryErr, c.EnableAzureFeatures(ctx, FeatureUnixPermissions))
 
+	if discoveryErr != nil {
+		cacheStatus := c.CacheStatus()
+		Logc(ctx).WithFields(LogFields{
+			"lastUpdateTime": cacheStatus.LastUpdateTime,
+			"cacheAge":       cacheStatus.Age,
+			"stale":          cacheStatus.Stale,
+		}).WithError(discoveryErr).Warning("Could not refresh Azure resource cache.")
+	}
+
 	return discoveryErr
 }
 
+// CacheStatus reports when the Azure resource cache was last refreshed successfully, how old it is,
+// and whether it is older than the configured maximum cache age.
+func (c Client) CacheStatus() *storage.BackendCacheStatus {
+	status := &storage.BackendCacheStatus{
+		LastUpdateTime: c.resources().lastUpdateTime,
+		MaxCacheAge:    c.config.MaxCacheAge,
+		Stale:          true,
+	}
+
+	// A cache that has never been populated is always stale
+	if !status.LastUpdateTime.IsZero() {
+		status.Age = time.Since(status.LastUpdateTime)
+		status.Stale = status.Age > status.MaxCacheAge
+	}
+
+	return status
+}
+
+// resources returns a snapshot of the discovered Azure resources.  The cached maps are replaced rather
+// than modified whenever resources are rediscovered, so the snapshot may be read without holding the lock.
+func (c Client) resources() AzureResources {
+	c.sdkClient.resourcesLock.RLock()
+	defer c.sdkClient.resourcesLock.RUnlock()
+
+	return c.sdkClient.AzureResources
+}
+
 // DiscoverAzureResources rediscovers the Azure resources we care about and updates the cache.
 func (c Client) DiscoverAzureResources(ctx context.Context) (returnError error) {
 	// Start from scratch each time we are called.  All discovered resources are nested under ResourceGroups.
@@ -88,6 +141,9 @@ func (c Client) DiscoverAzureResources(ctx context.Context) (returnError error)
 		}
 
 		// Swap the newly discovered resources into the cache only if discovery succeeded.
+		c.sdkClient.resourcesLock.Lock()
+		defer c.sdkClient.resourcesLock.Unlock()
+
 		c.sdkClient.AzureResources.ResourceGroups = newResourceGroups
 		c.sdkClient.AzureResources.ResourceGroupMap = newResourceGroupMap
 		c.sdkClient.AzureResources.NetAppAccountMap = newNetAppAccountMap
@@ -105,6 +161,12 @@ func (c Client) DiscoverAzureResources(ctx context.Context) (returnError error)
 		return
 	}
 
+	// Discover which NetApp accounts have Active Directory connections
+	adAccounts, returnError := c.discoverActiveDirectoryAccountsWithRetry(ctx)
+	if returnError != nil {
+		return
+	}
+
 	// Update maps with all data from discovered capacity pools
 	for _, cPool := range *cPools {
 
@@ -135,12 +197,19 @@ func (c Client) DiscoverAzureResources(ctx context.Context) (returnError error)
 				Location:      cPool.Location,
 				Type:          "Microsoft.NetApp/netAppAccounts",
 				CapacityPools: make([]*CapacityPool, 0),
+
+				ActiveDirectoryConfigured: adAccounts[naaFullName] != nil,
+			}
+			if adAccount, ok := adAccounts[naaFullName]; ok {
+				naa.KerberosConfigured = adAccount.KerberosConfigured
 			}
 			newNetAppAccountMap[naaFullName] = naa
 			rg.NetAppAccounts = append(rg.NetAppAccounts, naa)
 		}
 
 		// Add capacity pool to account
+		cPool.ActiveDirectoryConfigured = naa.ActiveDirectoryConfigured
+		cPool.KerberosConfigured = naa.KerberosConfigured
 		naa.CapacityPools = append(naa.CapacityPools, cPool)
 		newCapacityPoolMap[cPool.FullName] = cPool
 	}
@@ -219,8 +288,10 @@ func (c Client) DiscoverAzureResources(ctx context.Context) (returnError error)
 
 // dumpAzureResources writes a hierarchical representation of discovered resources to the log.
 func (c Client) dumpAzureResources(ctx context.Context, driverName string, discoveryTraceEnabled bool) {
+	resources := c.resources()
+
 	Logd(ctx, driverName, discoveryTraceEnabled).Tracef("Discovered Azure Resources:")
-	for _, rg := range c.sdkClient.AzureResources.ResourceGroups {
+	for _, rg := range resources.ResourceGroups {
 		Logd(ctx, driverName, discoveryTraceEnabled).Tracef("  Resource Group: %s", rg.Name)
 		for _, na := range rg.NetAppAccounts {
 			Logd(ctx, driverName, discoveryTraceEnabled).Tracef("    ANF Account: %s, Location: %s", na.Name, na.Location)
@@ -240,8 +311,10 @@ func (c Client) dumpAzureResources(ctx context.Context, driverName string, disco
 // checkForUnsatisfiedPools returns one or more errors if one or more configured storage pools
 // are satisfied by no capacity pools.
 func (c Client) checkForUnsatisfiedPools(ctx context.Context) (discoveryErrors []error) {
+	resources := c.resources()
+
 	// Ensure every storage pool matches one or more capacity pools
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Find all capacity pools that work for this storage pool
 		cPools := c.CapacityPoolsForStoragePool(ctx, sPool, sPool.InternalAttributes()[PServiceLevel])
@@ -270,11 +343,13 @@ func (c Client) checkForUnsatisfiedPools(ctx context.Context) (discoveryErrors [
 // checkForNonexistentResourceGroups logs warnings if any configured resource groups do not
 // match discovered resource groups in the resource cache.
 func (c Client) checkForNonexistentResourceGroups(ctx context.Context) (anyMismatches bool) {
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	resources := c.resources()
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Build list of resource group names
 		rgNames := make([]string, 0)
-		for _, cacheRG := range c.sdkClient.AzureResources.ResourceGroupMap {
+		for _, cacheRG := range resources.ResourceGroupMap {
 			rgNames = append(rgNames, cacheRG.Name)
 		}
 
@@ -297,11 +372,13 @@ func (c Client) checkForNonexistentResourceGroups(ctx context.Context) (anyMisma
 // checkForNonexistentNetAppAccounts logs warnings if any configured NetApp accounts do not
 // match discovered NetApp accounts in the resource cache.
 func (c Client) checkForNonexistentNetAppAccounts(ctx context.Context) (anyMismatches bool) {
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	resources := c.resources()
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Build list of short and long netapp account names
 		naNames := make([]string, 0)
-		for _, cacheNA := range c.sdkClient.AzureResources.NetAppAccountMap {
+		for _, cacheNA := range resources.NetAppAccountMap {
 			naNames = append(naNames, cacheNA.Name)
 			naNames = append(naNames, cacheNA.FullName)
 		}
@@ -325,11 +402,13 @@ func (c Client) checkForNonexistentNetAppAccounts(ctx context.Context) (anyMisma
 // checkForNonexistentCapacityPools logs warnings if any configured capacity pools do not
 // match discovered capacity pools in the resource cache.
 func (c Client) checkForNonexistentCapacityPools(ctx context.Context) (anyMismatches bool) {
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	resources := c.resources()
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Build list of short and long capacity pool names
 		cpNames := make([]string, 0)
-		for _, cacheCP := range c.sdkClient.AzureResources.CapacityPoolMap {
+		for _, cacheCP := range resources.CapacityPoolMap {
 			cpNames = append(cpNames, cacheCP.Name)
 			cpNames = append(cpNames, cacheCP.FullName)
 		}
@@ -350,14 +429,116 @@ func (c Client) checkForNonexistentCapacityPools(ctx context.Context) (anyMismat
 	return
 }
 
+// checkForChangedQosTypes logs warnings if any capacity pools in the resource cache have a different
+// QoS type than they did when previously discovered, such as when an operator converts a capacity pool
+// from auto to manual QoS.  Capacity pools that weren't previously discovered are not considered changed.
+func (c Client) checkForChangedQosTypes(
+	ctx context.Context, previousCapacityPools map[string]*CapacityPool,
+) []*CapacityPoolQosChange {
+	resources := c.resources()
+
+	changes := make([]*CapacityPoolQosChange, 0)
+
+	for cPoolFullName, cPool := range resources.CapacityPoolMap {
+		previousCPool, ok := previousCapacityPools[cPoolFullName]
+		if !ok || strings.EqualFold(previousCPool.QosType, cPool.QosType) {
+			continue
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"capacityPool": cPoolFullName,
+			"oldQosType":   previousCPool.QosType,
+			"newQosType":   cPool.QosType,
+			"serviceLevel": cPool.ServiceLevel,
+		}).Warning("Capacity pool QoS type changed.")
+
+		changes = append(changes, &CapacityPoolQosChange{
+			CapacityPool: cPool,
+			OldQosType:   previousCPool.QosType,
+			NewQosType:   cPool.QosType,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CapacityPool.FullName < changes[j].CapacityPool.FullName
+	})
+
+	return changes
+}
+
+// logQosTypeChangeImpact logs how a change to a capacity pool's QoS type affects the throughput of the
+// volumes in that pool.  Volumes in a pool converted to manual QoS keep their current throughput, which
+// no longer follows their size, while volumes in a pool converted to auto QoS lose any throughput that
+// was set explicitly.
+func (c Client) logQosTypeChangeImpact(ctx context.Context, change *CapacityPoolQosChange) {
+	filesystems, err := c.getVolumesFromPool(ctx, change.CapacityPool)
+	if err != nil {
+		Logc(ctx).WithField("capacityPool", change.CapacityPool.FullName).WithError(err).Warning(
+			"Could not determine volumes affected by capacity pool QoS type change.")
+		return
+	}
+
+	var impact string
+	if strings.EqualFold(change.NewQosType, QosTypeManual) {
+		impact = "Volume throughput is now fixed and no longer scales with volume size."
+	} else {
+		impact = "Volume throughput is now determined by volume size; any explicit throughput no longer applies."
+	}
+
+	for _, filesystem := range *filesystems {
+		Logc(ctx).WithFields(LogFields{
+			"capacityPool":  change.CapacityPool.FullName,
+			"volume":        filesystem.Name,
+			"creationToken": filesystem.CreationToken,
+			"quotaInBytes":  filesystem.QuotaInBytes,
+			"throughput":    filesystem.ThroughputMibps,
+			"qosType":       change.NewQosType,
+		}).Warning(impact)
+	}
+}
+
+// checkForAmbiguousCapacityPools logs warnings if any capacity pools configured by short name
+// match capacity pools in more than one resource group or NetApp account.
+func (c Client) checkForAmbiguousCapacityPools(ctx context.Context) (anyAmbiguous bool) {
+	resources := c.resources()
+
+	// Build map of short capacity pool names to full names
+	cpFullNames := make(map[string][]string)
+	for _, cacheCP := range resources.CapacityPoolMap {
+		cpFullNames[cacheCP.Name] = append(cpFullNames[cacheCP.Name], cacheCP.FullName)
+	}
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
+
+		// Find any capacity pools value in this storage pool that matches more than one known capacity pool
+		for _, configCP := range utils.SplitString(ctx, sPool.InternalAttributes()[PCapacityPools], ",") {
+			if matches := cpFullNames[configCP]; len(matches) > 1 {
+				anyAmbiguous = true
+
+				sort.Strings(matches)
+				Logc(ctx).WithFields(LogFields{
+					"pool":          sPoolName,
+					"capacityPool":  configCP,
+					"capacityPools": matches,
+				}).Warning("Capacity pool referenced in pool matches multiple capacity pools; use " +
+					"resourceGroup/netappAccount/capacityPool to reference a single capacity pool.")
+			}
+		}
+	}
+
+	return
+}
+
 // checkForNonexistentVirtualNetworks logs warnings if any configured virtual networks do not
 // match discovered virtual networks in the resource cache.
 func (c Client) checkForNonexistentVirtualNetworks(ctx context.Context) (anyMismatches bool) {
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	resources := c.resources()
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Build list of short and long capacity virtual network names
 		vnNames := make([]string, 0)
-		for _, cacheVN := range c.sdkClient.AzureResources.VirtualNetworkMap {
+		for _, cacheVN := range resources.VirtualNetworkMap {
 			vnNames = append(vnNames, cacheVN.Name)
 			vnNames = append(vnNames, cacheVN.FullName)
 		}
@@ -380,11 +561,13 @@ func (c Client) checkForNonexistentVirtualNetworks(ctx context.Context) (anyMism
 // checkForNonexistentSubnets logs warnings if any configured subnets do not
 // match discovered subnets in the resource cache.
 func (c Client) checkForNonexistentSubnets(ctx context.Context) (anyMismatches bool) {
-	for sPoolName, sPool := range c.sdkClient.AzureResources.StoragePoolMap {
+	resources := c.resources()
+
+	for sPoolName, sPool := range resources.StoragePoolMap {
 
 		// Build list of short and long capacity subnet names
 		snNames := make([]string, 0)
-		for _, cacheSN := range c.sdkClient.AzureResources.SubnetMap {
+		for _, cacheSN := range resources.SubnetMap {
 			snNames = append(snNames, cacheSN.Name)
 			snNames = append(snNames, cacheSN.FullName)
 		}
@@ -416,7 +599,9 @@ func (c Client) EnableAzureFeatures(ctx context.Context, featureNames ...string)
 		}
 
 		// Swap the newly discovered features into the cache only if discovery succeeded.
+		c.sdkClient.resourcesLock.Lock()
 		c.sdkClient.AzureResources.Features = featureMap
+		c.sdkClient.resourcesLock.Unlock()
 
 		Logc(ctx).Debug("Switched to newly discovered features.")
 	}()
@@ -437,6 +622,8 @@ func (c Client) EnableAzureFeatures(ctx context.Context, featureNames ...string)
 func (c Client) enableAzureFeature(
 	ctx context.Context, provider, feature string, featureMap map[string]bool,
 ) (returnError error) {
+	defer c.recordOperation(OperationFeatureGet, &returnError)()
+
 	logFields := LogFields{"feature": feature}
 
 	var rawResponse *http.Response
@@ -490,8 +677,9 @@ func (c Client) enableAzureFeature(
 
 // Features returns the map of preview features believed to be available in the current subscription.
 func (c Client) Features() map[string]bool {
+	resources := c.resources()
 	featureMap := make(map[string]bool)
-	for k, v := range c.sdkClient.Features {
+	for k, v := range resources.Features {
 		featureMap[k] = v
 	}
 	return featureMap
@@ -499,7 +687,8 @@ func (c Client) Features() map[string]bool {
 
 // HasFeature returns true if the named preview feature is believed to be available in the current subscription.
 func (c Client) HasFeature(feature string) bool {
-	value, ok := c.sdkClient.Features[feature]
+	resources := c.resources()
+	value, ok := resources.Features[feature]
 	return ok && value
 }
 
@@ -536,7 +725,9 @@ func (c Client) discoverCapacityPoolsWithRetry(ctx context.Context) (pools *[]*C
 }
 
 // discoverCapacityPools queries the Azure Resource Graph for all ANF capacity pools in the current location.
-func (c Client) discoverCapacityPools(ctx context.Context) (*[]*CapacityPool, error) {
+func (c Client) discoverCapacityPools(ctx context.Context) (cPools *[]*CapacityPool, err error) {
+	defer c.recordOperation(OperationCapacityPoolDiscover, &err)()
+
 	logFields := LogFields{
 		"API": "GraphClient.Resources",
 	}
@@ -615,12 +806,6 @@ func (c Client) discoverCapacityPools(ctx context.Context) (*[]*CapacityPool, er
 			continue
 		}
 
-		if qosType == string(netapp.QosTypeManual) {
-			Logc(ctx).WithFields(logFields).Warningf("Ignoring capacity pool %s because it uses manual QoS.",
-				cPoolFullName)
-			continue
-		}
-
 		if poolID, ok = rawProperties["poolId"].(string); !ok {
 			Logc(ctx).WithFields(logFields).Error("Capacity pool query returned invalid poolId.")
 			continue
@@ -636,6 +821,12 @@ func (c Client) discoverCapacityPools(ctx context.Context) (*[]*CapacityPool, er
 			continue
 		}
 
+		// The pool size is only used for capacity checks, so tolerate its absence
+		var size int64
+		if rawSize, sizeOK := rawProperties["size"].(float64); sizeOK {
+			size = int64(rawSize)
+		}
+
 		cpools = append(cpools,
 			&CapacityPool{
 				ID:                id,
@@ -649,12 +840,133 @@ func (c Client) discoverCapacityPools(ctx context.Context) (*[]*CapacityPool, er
 				ServiceLevel:      serviceLevel,
 				ProvisioningState: provisioningState,
 				QosType:           qosType,
+				Size:              size,
 			})
 	}
 
 	return &cpools, nil
 }
 
+// discoverActiveDirectoryAccountsWithRetry queries the Azure Resource Graph for the ANF NetApp accounts in the
+// current location that have Active Directory connections, retrying if the API request is throttled.
+func (c Client) discoverActiveDirectoryAccountsWithRetry(
+	ctx context.Context,
+) (accounts map[string]*ActiveDirectoryAccount, err error) {
+	discover := func() error {
+		if accounts, err = c.discoverActiveDirectoryAccounts(ctx); err != nil && IsANFTooManyRequestsError(err) {
+			return err
+		}
+		return backoff.Permanent(err)
+	}
+
+	notify := func(err error, duration time.Duration) {
+		Logc(ctx).WithFields(LogFields{
+			"increment": duration.Truncate(10 * time.Millisecond),
+		}).Debugf("Retrying NetApp accounts resource graph query.")
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = DefaultSDKTimeout
+	expBackoff.MaxInterval = 5 * time.Second
+	expBackoff.RandomizationFactor = 0.1
+	expBackoff.InitialInterval = 5 * time.Second
+	expBackoff.Multiplier = 1
+
+	err = backoff.RetryNotify(discover, expBackoff, notify)
+
+	return
+}
+
+// discoverActiveDirectoryAccounts queries the Azure Resource Graph for the ANF NetApp accounts in the current
+// location that have Active Directory connections.  The result is keyed by the accounts' full names.  An account
+// may have only one Active Directory connection, so only the first one is inspected.
+func (c Client) discoverActiveDirectoryAccounts(
+	ctx context.Context,
+) (accounts map[string]*ActiveDirectoryAccount, err error) {
+	defer c.recordOperation(OperationNetAppAccountDiscover, &err)()
+
+	logFields := LogFields{
+		"API": "GraphClient.Resources",
+	}
+
+	subscriptions := []string{c.config.SubscriptionID}
+	query := fmt.Sprintf(`
+    Resources
+    | where type =~ 'Microsoft.NetApp/netAppAccounts' and location =~ '%s'
+    | where array_length(properties.activeDirectories) > 0
+    | project id, adName = tostring(properties.activeDirectories[0].adName),
+        kdcIP = tostring(properties.activeDirectories[0].kdcIP)`, c.config.Location)
+	resultFormat := resourcegraph.ResultFormat("objectArray")
+	requestOptions := resourcegraph.QueryRequestOptions{ResultFormat: &resultFormat}
+
+	request := resourcegraph.QueryRequest{
+		Subscriptions: CreateStringPtrArray(subscriptions),
+		Query:         &query,
+		Options:       &requestOptions,
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	response, err := c.sdkClient.GraphClient.Resources(responseCtx, request, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("NetApp account query failed.")
+		return nil, err
+	}
+
+	resourceList := response.QueryResponse
+
+	Logc(ctx).WithFields(logFields).Debug("Read NetApp accounts from resource graph.")
+
+	accounts = make(map[string]*ActiveDirectoryAccount)
+
+	// Having no accounts with Active Directory connections is normal
+	if resourceList.Data == nil || resourceList.Count == nil || *resourceList.Count == 0 {
+		return accounts, nil
+	}
+
+	data, ok := resourceList.Data.([]interface{})
+	if !ok {
+		Logc(ctx).WithFields(logFields).Error("NetApp account query returned invalid data.")
+		return nil, errors.New("netapp account query returned invalid data")
+	}
+
+	for _, rawAccountInterface := range data {
+
+		var rawAccountMap map[string]interface{}
+		var id string
+
+		if rawAccountMap, ok = rawAccountInterface.(map[string]interface{}); !ok {
+			Logc(ctx).WithFields(logFields).Error("NetApp account query returned non-map data.")
+			continue
+		}
+
+		if id, ok = rawAccountMap["id"].(string); !ok {
+			Logc(ctx).WithFields(logFields).Error("NetApp account query returned non-string ID.")
+			continue
+		}
+
+		_, resourceGroup, _, netappAccount, err := ParseNetappAccountID(id)
+		if err != nil {
+			Logc(ctx).WithFields(logFields).Error("NetApp account query returned invalid ID.")
+			continue
+		}
+
+		// A Kerberos realm requires both the AD server name and the KDC IP address
+		adName, _ := rawAccountMap["adName"].(string)
+		kdcIP, _ := rawAccountMap["kdcIP"].(string)
+
+		accounts[CreateNetappAccountFullName(resourceGroup, netappAccount)] = &ActiveDirectoryAccount{
+			KerberosConfigured: adName != "" && kdcIP != "",
+		}
+	}
+
+	return accounts, nil
+}
+
 // discoverSubnetsWithRetry queries the Azure Resource Graph for all ANF-delegated subnets in the current location,
 // retrying if the API request is throttled.
 func (c Client) discoverSubnetsWithRetry(ctx context.Context) (subnets *[]*Subnet, err error) {
@@ -684,7 +996,13 @@ func (c Client) discoverSubnetsWithRetry(ctx context.Context) (subnets *[]*Subne
 }
 
 // discoverSubnets queries the Azure Resource Graph for all ANF-delegated subnets in the current location.
-func (c Client) discoverSubnets(ctx context.Context) (*[]*Subnet, error) {
+// Each subnet's usage is estimated from the number of IP configurations attached to it.  ANF volumes take
+// their addresses from the delegated subnet without showing up there, and Resource Graph data may lag
+// behind recent changes, so the estimate is only good for ranking subnets.  It can't tell that a subnet is
+// full, which is why volume creation moves to another subnet if ANF reports that one has run out.
+func (c Client) discoverSubnets(ctx context.Context) (subnetList *[]*Subnet, err error) {
+	defer c.recordOperation(OperationSubnetDiscover, &err)()
+
 	logFields := LogFields{
 		"API": "GraphClient.Resources",
 	}
@@ -695,9 +1013,10 @@ func (c Client) discoverSubnets(ctx context.Context) (*[]*Subnet, error) {
 	| where type =~ 'Microsoft.Network/virtualNetworks' and location =~ '%s'
 	| project subnets = (properties.subnets)
 	| mv-expand subnets
-	| project subnetID = (subnets.id), delegations = (subnets.properties.delegations)
+	| project subnetID = (subnets.id), addressPrefix = (subnets.properties.addressPrefix),
+		usedIPs = array_length(subnets.properties.ipConfigurations), delegations = (subnets.properties.delegations)
 	| mv-expand delegations
-	| project subnetID, serviceName = (delegations.properties.serviceName)
+	| project subnetID, addressPrefix, usedIPs, serviceName = (delegations.properties.serviceName)
 	| where serviceName =~ 'Microsoft.NetApp/volumes'`, c.config.Location)
 	resultFormat := resourcegraph.ResultFormat("objectArray")
 	requestOptions := resourcegraph.QueryRequestOptions{ResultFormat: &resultFormat}
@@ -757,6 +1076,9 @@ func (c Client) discoverSubnets(ctx context.Context) (*[]*Subnet, error) {
 			continue
 		}
 
+		addressPrefix, _ := rawSubnetMap["addressPrefix"].(string)
+		usedIPs, _ := rawSubnetMap["usedIPs"].(float64)
+
 		subnets = append(subnets,
 			&Subnet{
 				ID:             id,
@@ -766,21 +1088,48 @@ func (c Client) discoverSubnets(ctx context.Context) (*[]*Subnet, error) {
 				FullName:       CreateSubnetFullName(resourceGroup, virtualNetwork, subnet),
 				Location:       c.config.Location,
 				Type:           "Microsoft.Network/virtualNetworks/subnets",
+				AddressPrefix:  addressPrefix,
+				AvailableIPs:   availableSubnetIPs(addressPrefix, int(usedIPs)),
 			})
 	}
 
 	return &subnets, nil
 }
 
+// availableSubnetIPs estimates how many addresses remain in a subnet, given its address prefix and the
+// number of IP configurations already allocated from it.  Returns 0 if the prefix cannot be parsed.  The
+// result is an upper bound, since addresses used by ANF volumes aren't counted.
+func availableSubnetIPs(addressPrefix string, usedIPs int) int {
+	_, ipNet, err := net.ParseCIDR(addressPrefix)
+	if err != nil {
+		return 0
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones >= 31 {
+		// Far larger than any delegated subnet, so just report the largest plausible value
+		return math.MaxInt32
+	}
+
+	available := (1 << (bits - ones)) - SubnetReservedIPCount - usedIPs
+	if available < 0 {
+		return 0
+	}
+
+	return available
+}
+
 // ///////////////////////////////////////////////////////////////////////////////
 // API functions to match/search capacity pools
 // ///////////////////////////////////////////////////////////////////////////////
 
 // CapacityPools returns a list of all discovered ANF capacity pools.
 func (c Client) CapacityPools() *[]*CapacityPool {
+	resources := c.resources()
+
 	var cPools []*CapacityPool
 
-	for _, cPool := range c.sdkClient.AzureResources.CapacityPoolMap {
+	for _, cPool := range resources.CapacityPoolMap {
 		cPools = append(cPools, cPool)
 	}
 
@@ -789,17 +1138,19 @@ func (c Client) CapacityPools() *[]*CapacityPool {
 
 // capacityPool returns a single discovered capacity pool by its full name.
 func (c Client) capacityPool(cPoolFullName string) *CapacityPool {
-	return c.sdkClient.AzureResources.CapacityPoolMap[cPoolFullName]
+	return c.resources().CapacityPoolMap[cPoolFullName]
 }
 
 // CapacityPoolsForStoragePools returns all discovered capacity pools matching all known storage pools,
 // regardless of service levels.
 func (c Client) CapacityPoolsForStoragePools(ctx context.Context) []*CapacityPool {
+	resources := c.resources()
+
 	// This map deduplicates cPools from multiple storage pools
 	cPoolMap := make(map[*CapacityPool]bool)
 
 	// Build deduplicated map of cPools
-	for _, sPool := range c.sdkClient.StoragePoolMap {
+	for _, sPool := range resources.StoragePoolMap {
 		for _, cPool := range c.CapacityPoolsForStoragePool(ctx, sPool, "") {
 			cPoolMap[cPool] = true
 		}
@@ -823,18 +1174,20 @@ func (c Client) CapacityPoolsForStoragePool(
 	Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).WithField("storagePool", sPool.Name()).
 		Tracef("Determining capacity pools for storage pool.")
 
+	resources := c.resources()
+
 	// This map tracks which capacity pools have passed the filters
 	filteredCapacityPoolMap := make(map[string]bool)
 
 	// Start with all capacity pools marked as passing the filters
-	for cPoolFullName := range c.sdkClient.CapacityPoolMap {
+	for cPoolFullName := range resources.CapacityPoolMap {
 		filteredCapacityPoolMap[cPoolFullName] = true
 	}
 
 	// If resource groups were specified, filter out non-matching capacity pools
 	rgList := utils.SplitString(ctx, sPool.InternalAttributes()[PResourceGroups], ",")
 	if len(rgList) > 0 {
-		for cPoolFullName, cPool := range c.sdkClient.CapacityPoolMap {
+		for cPoolFullName, cPool := range resources.CapacityPoolMap {
 			if !utils.SliceContainsString(rgList, cPool.ResourceGroup) {
 				Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).Tracef("Ignoring capacity pool %s, not in resource groups [%s].",
 					cPoolFullName, rgList)
@@ -846,7 +1199,7 @@ func (c Client) CapacityPoolsForStoragePool(
 	// If netapp accounts were specified, filter out non-matching capacity pools
 	naList := utils.SplitString(ctx, sPool.InternalAttributes()[PNetappAccounts], ",")
 	if len(naList) > 0 {
-		for cPoolFullName, cPool := range c.sdkClient.CapacityPoolMap {
+		for cPoolFullName, cPool := range resources.CapacityPoolMap {
 			naName := cPool.NetAppAccount
 			naFullName := CreateNetappAccountFullName(cPool.ResourceGroup, cPool.NetAppAccount)
 			if !utils.SliceContainsString(naList, naName) && !utils.SliceContainsString(naList, naFullName) {
@@ -860,7 +1213,7 @@ func (c Client) CapacityPoolsForStoragePool(
 	// If capacity pools were specified, filter out non-matching capacity pools
 	cpList := utils.SplitString(ctx, sPool.InternalAttributes()[PCapacityPools], ",")
 	if len(cpList) > 0 {
-		for cPoolFullName, cPool := range c.sdkClient.CapacityPoolMap {
+		for cPoolFullName, cPool := range resources.CapacityPoolMap {
 			if !utils.SliceContainsString(cpList, cPool.Name) && !utils.SliceContainsString(cpList, cPoolFullName) {
 				Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).Tracef("Ignoring capacity pool %s, not in capacity pools [%s].",
 					cPoolFullName, cpList)
@@ -871,7 +1224,7 @@ func (c Client) CapacityPoolsForStoragePool(
 
 	// Filter out pools with non-matching service levels
 	if serviceLevel != "" {
-		for cPoolFullName, cPool := range c.sdkClient.CapacityPoolMap {
+		for cPoolFullName, cPool := range resources.CapacityPoolMap {
 			if cPool.ServiceLevel != serviceLevel {
 				Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).Tracef("Ignoring capacity pool %s, not service level %s.",
 					cPoolFullName, serviceLevel)
@@ -884,7 +1237,7 @@ func (c Client) CapacityPoolsForStoragePool(
 	cPools := make([]*CapacityPool, 0)
 	for cPoolFullName, match := range filteredCapacityPoolMap {
 		if match {
-			cPools = append(cPools, c.sdkClient.CapacityPoolMap[cPoolFullName])
+			cPools = append(cPools, resources.CapacityPoolMap[cPoolFullName])
 		}
 	}
 
@@ -924,25 +1277,27 @@ func (c Client) EnsureVolumeInValidCapacityPool(ctx context.Context, volume *Fil
 
 // subnet returns a single subnet by its full name
 func (c Client) subnet(subnetFullName string) *Subnet {
-	return c.sdkClient.AzureResources.SubnetMap[subnetFullName]
+	return c.resources().SubnetMap[subnetFullName]
 }
 
 // SubnetsForStoragePool returns all discovered subnets matching the specified storage pool.
 func (c Client) SubnetsForStoragePool(ctx context.Context, sPool storage.Pool) []*Subnet {
 	Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).WithField("storagePool", sPool.Name()).Tracef("Determining subnets for storage pool.")
 
+	resources := c.resources()
+
 	// This map tracks which subnets have passed the filters
 	filteredSubnetMap := make(map[string]bool)
 
 	// Start with all subnets marked as passing the filters
-	for subnetFullName := range c.sdkClient.SubnetMap {
+	for subnetFullName := range resources.SubnetMap {
 		filteredSubnetMap[subnetFullName] = true
 	}
 
 	// If resource groups were specified, filter out non-matching subnets
 	rgList := utils.SplitString(ctx, sPool.InternalAttributes()[PResourceGroups], ",")
 	if len(rgList) > 0 {
-		for subnetFullName, subnet := range c.sdkClient.SubnetMap {
+		for subnetFullName, subnet := range resources.SubnetMap {
 			if !utils.SliceContainsString(rgList, subnet.ResourceGroup) {
 				Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).Tracef("Ignoring subnet %s, not in resource groups [%s].",
 					subnetFullName, rgList)
@@ -954,7 +1309,7 @@ func (c Client) SubnetsForStoragePool(ctx context.Context, sPool storage.Pool) [
 	// If virtual network was specified, filter out non-matching subnets
 	vn := sPool.InternalAttributes()[PVirtualNetwork]
 	if vn != "" {
-		for subnetFullName, subnet := range c.sdkClient.SubnetMap {
+		for subnetFullName, subnet := range resources.SubnetMap {
 			vnName := subnet.VirtualNetwork
 			vnFullName := CreateVirtualNetworkFullName(subnet.ResourceGroup, subnet.VirtualNetwork)
 			if vn != vnName && vn != vnFullName {
@@ -968,7 +1323,7 @@ func (c Client) SubnetsForStoragePool(ctx context.Context, sPool storage.Pool) [
 	// If subnet was specified, filter out non-matching capacity subnets
 	sn := sPool.InternalAttributes()[PSubnet]
 	if sn != "" {
-		for subnetFullName, subnet := range c.sdkClient.SubnetMap {
+		for subnetFullName, subnet := range resources.SubnetMap {
 			if sn != subnet.Name && sn != subnetFullName {
 				Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).Tracef("Ignoring subnet %s, not equal to subnet %s.",
 					subnetFullName, sn)
@@ -981,7 +1336,7 @@ func (c Client) SubnetsForStoragePool(ctx context.Context, sPool storage.Pool) [
 	filteredSubnetList := make([]*Subnet, 0)
 	for subnetFullName, match := range filteredSubnetMap {
 		if match {
-			filteredSubnetList = append(filteredSubnetList, c.sdkClient.SubnetMap[subnetFullName])
+			filteredSubnetList = append(filteredSubnetList, resources.SubnetMap[subnetFullName])
 		}
 	}
 
@@ -999,3 +1354,58 @@ func (c Client) RandomSubnetForStoragePool(ctx context.Context, sPool storage.Po
 
 	return filteredSubnets[crypto.GetRandomNumber(len(filteredSubnets))]
 }
+
+// SubnetForStoragePool finds all discovered subnets matching the specified storage pool, and then
+// returns one according to the pool's subnet selection mode.  If the pool specifies a single subnet,
+// every mode resolves to that subnet.
+func (c Client) SubnetForStoragePool(ctx context.Context, sPool storage.Pool) *Subnet {
+	switch sPool.InternalAttributes()[PSubnetSelection] {
+	case SubnetSelectionFirst:
+		return c.firstSubnetForStoragePool(ctx, sPool)
+	case SubnetSelectionCapacity:
+		return c.largestSubnetForStoragePool(ctx, sPool)
+	default:
+		return c.RandomSubnetForStoragePool(ctx, sPool)
+	}
+}
+
+// firstSubnetForStoragePool finds all discovered subnets matching the specified storage pool,
+// and then returns the first one in order of full name.
+func (c Client) firstSubnetForStoragePool(ctx context.Context, sPool storage.Pool) *Subnet {
+	filteredSubnets := c.SubnetsForStoragePool(ctx, sPool)
+
+	if len(filteredSubnets) == 0 {
+		return nil
+	}
+
+	sort.Slice(filteredSubnets, func(i, j int) bool {
+		return filteredSubnets[i].FullName < filteredSubnets[j].FullName
+	})
+
+	return filteredSubnets[0]
+}
+
+// largestSubnetForStoragePool finds all discovered subnets matching the specified storage pool,
+// and then returns the one with the most available IP addresses.  Ties are broken by full name.
+func (c Client) largestSubnetForStoragePool(ctx context.Context, sPool storage.Pool) *Subnet {
+	filteredSubnets := c.SubnetsForStoragePool(ctx, sPool)
+
+	if len(filteredSubnets) == 0 {
+		return nil
+	}
+
+	sort.Slice(filteredSubnets, func(i, j int) bool {
+		if filteredSubnets[i].AvailableIPs != filteredSubnets[j].AvailableIPs {
+			return filteredSubnets[i].AvailableIPs > filteredSubnets[j].AvailableIPs
+		}
+		return filteredSubnets[i].FullName < filteredSubnets[j].FullName
+	})
+
+	Logd(ctx, c.config.StorageDriverName, c.config.DebugTraceFlags["discovery"]).WithFields(LogFields{
+		"storagePool":  sPool.Name(),
+		"subnet":       filteredSubnets[0].FullName,
+		"availableIPs": filteredSubnets[0].AvailableIPs,
+	}).Trace("Selected subnet with the most available IP addresses.")
+
+	return filteredSubnets[0]
+}
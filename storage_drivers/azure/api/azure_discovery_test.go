@@ -5,6 +5,7 @@ package api
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -490,6 +491,105 @@ func TestCheckForNonexistentCapacityPools_Missing(t *testing.T) {
 	assert.True(t, result, "expected error")
 }
 
+func TestCheckForChangedQosTypes_NoPreviousPools(t *testing.T) {
+	sdk := getFakeSDK()
+
+	result := sdk.checkForChangedQosTypes(ctx, make(map[string]*CapacityPool))
+
+	assert.Empty(t, result, "expected no changes")
+}
+
+func TestCheckForChangedQosTypes_Unchanged(t *testing.T) {
+	sdk := getFakeSDK()
+
+	previousCapacityPools := make(map[string]*CapacityPool)
+	for name, cPool := range sdk.sdkClient.CapacityPoolMap {
+		previous := *cPool
+		previousCapacityPools[name] = &previous
+	}
+
+	result := sdk.checkForChangedQosTypes(ctx, previousCapacityPools)
+
+	assert.Empty(t, result, "expected no changes")
+}
+
+func TestCheckForChangedQosTypes_Changed(t *testing.T) {
+	sdk := getFakeSDK()
+
+	previousCapacityPools := make(map[string]*CapacityPool)
+	for name, cPool := range sdk.sdkClient.CapacityPoolMap {
+		cPool.QosType = QosTypeAuto
+		previous := *cPool
+		previousCapacityPools[name] = &previous
+	}
+
+	// An operator converts two capacity pools to manual QoS
+	sdk.sdkClient.CapacityPoolMap["RG1/NA1/CP2"].QosType = QosTypeManual
+	sdk.sdkClient.CapacityPoolMap["RG1/NA1/CP1"].QosType = QosTypeManual
+
+	result := sdk.checkForChangedQosTypes(ctx, previousCapacityPools)
+
+	expected := []*CapacityPoolQosChange{
+		{
+			CapacityPool: sdk.sdkClient.CapacityPoolMap["RG1/NA1/CP1"],
+			OldQosType:   QosTypeAuto,
+			NewQosType:   QosTypeManual,
+		},
+		{
+			CapacityPool: sdk.sdkClient.CapacityPoolMap["RG1/NA1/CP2"],
+			OldQosType:   QosTypeAuto,
+			NewQosType:   QosTypeManual,
+		},
+	}
+
+	assert.Equal(t, expected, result, "changes do not match")
+}
+
+func TestCheckForAmbiguousCapacityPools_NoPools(t *testing.T) {
+	sdk := getFakeSDK()
+	sdk.sdkClient.StoragePoolMap = make(map[string]storage.Pool)
+
+	result := sdk.checkForAmbiguousCapacityPools(ctx)
+
+	assert.False(t, result, "expected no ambiguity")
+}
+
+func TestCheckForAmbiguousCapacityPools_FullNames(t *testing.T) {
+	sPool := storage.NewStoragePool(nil, "pool")
+	sPool.InternalAttributes()[PCapacityPools] = "RG1/NA1/CP1,RG2/NA1/CP1"
+
+	sdk := getFakeSDK()
+	sdk.sdkClient.StoragePoolMap = map[string]storage.Pool{"pool": sPool}
+
+	result := sdk.checkForAmbiguousCapacityPools(ctx)
+
+	assert.False(t, result, "expected no ambiguity")
+}
+
+func TestCheckForAmbiguousCapacityPools_UniqueShortName(t *testing.T) {
+	sPool := storage.NewStoragePool(nil, "pool")
+	sPool.InternalAttributes()[PCapacityPools] = "CP3"
+
+	sdk := getFakeSDK()
+	sdk.sdkClient.StoragePoolMap = map[string]storage.Pool{"pool": sPool}
+
+	result := sdk.checkForAmbiguousCapacityPools(ctx)
+
+	assert.False(t, result, "expected no ambiguity")
+}
+
+func TestCheckForAmbiguousCapacityPools_DuplicateShortName(t *testing.T) {
+	sPool := storage.NewStoragePool(nil, "pool")
+	sPool.InternalAttributes()[PCapacityPools] = "CP1"
+
+	sdk := getFakeSDK()
+	sdk.sdkClient.StoragePoolMap = map[string]storage.Pool{"pool": sPool}
+
+	result := sdk.checkForAmbiguousCapacityPools(ctx)
+
+	assert.True(t, result, "expected ambiguity")
+}
+
 func TestCheckForNonexistentVirtualNetworks_NoPools(t *testing.T) {
 	sdk := getFakeSDK()
 	sdk.sdkClient.StoragePoolMap = make(map[string]storage.Pool)
@@ -625,6 +725,64 @@ func TestFeatures(t *testing.T) {
 	}
 }
 
+func TestCacheStatus(t *testing.T) {
+	sdk := getFakeSDK()
+	sdk.config.MaxCacheAge = 10 * time.Minute
+
+	// Never refreshed
+	status := sdk.CacheStatus()
+	assert.True(t, status.LastUpdateTime.IsZero(), "expected zero last update time")
+	assert.True(t, status.Stale, "expected stale cache")
+
+	// Recently refreshed
+	lastUpdateTime := time.Now().Add(-1 * time.Minute)
+	sdk.sdkClient.AzureResources.lastUpdateTime = lastUpdateTime
+
+	status = sdk.CacheStatus()
+	assert.Equal(t, lastUpdateTime, status.LastUpdateTime, "last update time mismatch")
+	assert.Equal(t, 10*time.Minute, status.MaxCacheAge, "max cache age mismatch")
+	assert.GreaterOrEqual(t, status.Age, 1*time.Minute, "cache age too small")
+	assert.False(t, status.Stale, "expected fresh cache")
+
+	// Refreshed longer ago than the max cache age
+	lastUpdateTime = time.Now().Add(-20 * time.Minute)
+	sdk.sdkClient.AzureResources.lastUpdateTime = lastUpdateTime
+
+	status = sdk.CacheStatus()
+	assert.Equal(t, lastUpdateTime, status.LastUpdateTime, "last update time mismatch")
+	assert.GreaterOrEqual(t, status.Age, 20*time.Minute, "cache age too small")
+	assert.True(t, status.Stale, "expected stale cache")
+}
+
+func TestResources_ConcurrentSwap(t *testing.T) {
+	sdk := getFakeSDK()
+
+	sPool := storage.NewStoragePool(nil, "pool")
+	sPool.InternalAttributes()[PCapacityPools] = "CP1"
+	sPools := map[string]storage.Pool{"pool": sPool}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Swap the cached storage pools while another goroutine reads them; the race detector flags any unguarded access
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			sdk.registerStoragePools(sPools)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			sdk.CapacityPoolsForStoragePools(ctx)
+		}
+	}()
+
+	wg.Wait()
+
+	assert.Len(t, sdk.CapacityPoolsForStoragePools(ctx), 3, "capacity pools mismatch")
+}
+
 func TestCapacityPools(t *testing.T) {
 	sdk := getFakeSDK()
 	sdk.sdkClient.StoragePoolMap = make(map[string]storage.Pool)
@@ -972,3 +1130,64 @@ func TestSubnetsForStoragePool(t *testing.T) {
 		}
 	}
 }
+
+func TestSubnetForStoragePool(t *testing.T) {
+	sdk := getFakeSDK()
+
+	RG1_VN2_SN2 := sdk.subnet("RG1/VN2/SN2")
+	RG1_VN2_SN3 := sdk.subnet("RG1/VN2/SN3")
+	RG1_VN2_SN2.AvailableIPs = 10
+	RG1_VN2_SN3.AvailableIPs = 200
+
+	tests := []struct {
+		selection string
+		expected  *Subnet
+	}{
+		{selection: SubnetSelectionCapacity, expected: RG1_VN2_SN3},
+		{selection: SubnetSelectionFirst, expected: RG1_VN2_SN2},
+	}
+
+	for _, test := range tests {
+		sPool := storage.NewStoragePool(nil, "pool")
+		sPool.InternalAttributes()[PResourceGroups] = "RG1"
+		sPool.InternalAttributes()[PVirtualNetwork] = "VN2"
+		sPool.InternalAttributes()[PSubnetSelection] = test.selection
+
+		subnet := sdk.SubnetForStoragePool(context.TODO(), sPool)
+
+		assert.Equal(t, test.expected, subnet, "subnet mismatch for mode %s", test.selection)
+	}
+
+	// Random selection returns any matching subnet
+	sPool := storage.NewStoragePool(nil, "pool")
+	sPool.InternalAttributes()[PResourceGroups] = "RG1"
+	sPool.InternalAttributes()[PVirtualNetwork] = "VN2"
+
+	subnet := sdk.SubnetForStoragePool(context.TODO(), sPool)
+
+	assert.Contains(t, []*Subnet{RG1_VN2_SN2, RG1_VN2_SN3}, subnet)
+
+	// No matching subnets
+	sPool.InternalAttributes()[PVirtualNetwork] = "VN9"
+	sPool.InternalAttributes()[PSubnetSelection] = SubnetSelectionCapacity
+
+	assert.Nil(t, sdk.SubnetForStoragePool(context.TODO(), sPool))
+}
+
+func TestAvailableSubnetIPs(t *testing.T) {
+	tests := []struct {
+		addressPrefix string
+		usedIPs       int
+		expected      int
+	}{
+		{"10.0.0.0/24", 0, 251},
+		{"10.0.0.0/24", 50, 201},
+		{"10.0.0.0/28", 20, 0},
+		{"invalid", 0, 0},
+		{"", 0, 0},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, availableSubnetIPs(test.addressPrefix, test.usedIPs), test.addressPrefix)
+	}
+}
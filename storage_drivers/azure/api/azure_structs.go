@@ -31,10 +31,61 @@ const (
 	ServiceLevelPremium  = "Premium"
 	ServiceLevelUltra    = "Ultra"
 
+	QosTypeAuto   = "Auto"
+	QosTypeManual = "Manual"
+
+	ChownModeRestricted   = "Restricted"
+	ChownModeUnrestricted = "Unrestricted"
+
+	SecurityStyleNTFS = "ntfs"
+	SecurityStyleUnix = "unix"
+
+	CloudAzurePublic       = "AzurePublicCloud"
+	CloudAzureUSGovernment = "AzureUSGovernment"
+	CloudAzureChina        = "AzureChinaCloud"
+
 	FeatureUnixPermissions = "ANFUnixPermissions"
 
+	CoolAccessRetrievalPolicyDefault = "Default"
+
+	MinCoolnessPeriodDays = 2
+	MaxCoolnessPeriodDays = 183
+
 	NetworkFeaturesBasic    = "Basic"
 	NetworkFeaturesStandard = "Standard"
+
+	VolumeTypeDataProtection = "DataProtection"
+
+	ApplicationTypeSAPHANA = "SAP-HANA"
+	// SAPHANADeploymentSpecID identifies the ANF deployment specification for SAP HANA volume groups
+	SAPHANADeploymentSpecID = "20542149-bfca-5618-1879-9863dc6767f1"
+
+	VolumeSpecNameData       = "data"
+	VolumeSpecNameLog        = "log"
+	VolumeSpecNameShared     = "shared"
+	VolumeSpecNameDataBackup = "data-backup"
+	VolumeSpecNameLogBackup  = "log-backup"
+
+	ReplicationEndpointTypeSource      = "src"
+	ReplicationEndpointTypeDestination = "dst"
+
+	ReplicationSchedule10Minutely = "_10minutely"
+	ReplicationScheduleHourly     = "hourly"
+	ReplicationScheduleDaily      = "daily"
+
+	MirrorStateUninitialized = "Uninitialized"
+	MirrorStateMirrored      = "Mirrored"
+	MirrorStateBroken        = "Broken"
+
+	RelationshipStatusIdle         = "Idle"
+	RelationshipStatusTransferring = "Transferring"
+
+	SubnetSelectionRandom   = "random"
+	SubnetSelectionFirst    = "first"
+	SubnetSelectionCapacity = "capacity"
+
+	// Azure reserves the first four and the last IP address in every subnet
+	SubnetReservedIPCount = 5
 )
 
 // AzureResources is the toplevel cache for the set of things we discover about our Azure environment.
@@ -66,6 +117,15 @@ type NetAppAccount struct {
 	Location      string
 	Type          string
 	CapacityPools []*CapacityPool
+	// ActiveDirectoryConfigured is whether the account has an Active Directory connection
+	ActiveDirectoryConfigured bool
+	// KerberosConfigured is whether the account's Active Directory connection has a Kerberos realm
+	KerberosConfigured bool
+}
+
+// ActiveDirectoryAccount records the Active Directory details of a discovered ANF NetAppAccount.
+type ActiveDirectoryAccount struct {
+	KerberosConfigured bool
 }
 
 // VirtualNetwork records details of a discovered Azure Virtual Network.
@@ -88,6 +148,8 @@ type Subnet struct {
 	FullName       string
 	Location       string
 	Type           string
+	AddressPrefix  string
+	AvailableIPs   int
 }
 
 // CapacityPool records details of a discovered Azure Subnet.
@@ -103,6 +165,19 @@ type CapacityPool struct {
 	ServiceLevel      string
 	ProvisioningState string
 	QosType           string
+	// Size is the provisioned size of the capacity pool in bytes, or zero if unknown
+	Size int64
+	// ActiveDirectoryConfigured is whether the capacity pool's NetApp account has an Active Directory connection
+	ActiveDirectoryConfigured bool
+	// KerberosConfigured is whether the capacity pool's NetApp account has a Kerberos realm configured
+	KerberosConfigured bool
+}
+
+// CapacityPoolQosChange records a change to the QoS type of a discovered capacity pool.
+type CapacityPoolQosChange struct {
+	CapacityPool *CapacityPool
+	OldQosType   string
+	NewQosType   string
 }
 
 // FileSystem records details of a discovered Azure Subnet.
@@ -124,13 +199,38 @@ type FileSystem struct {
 	QuotaInBytes      int64
 	ServiceLevel      string
 	SnapshotDirectory bool
-	UsedBytes         int
+	// UsedBytes is the space consumed by the volume, distinct from its quota (QuotaInBytes).  The ANF
+	// volume resource does not report consumption, so this is zero unless read with VolumeUsedBytes.
+	UsedBytes int64
+	// SnapshotUsedBytes is the space consumed by the volume's snapshots, which is likewise zero unless read
+	// with VolumeUsedBytes.
+	SnapshotUsedBytes int64
 	SubnetID          string
 	UnixPermissions   string
 	MountTargets      []MountTarget
 	SubvolumesEnabled bool
 	NetworkFeatures   string
 	KerberosEnabled   bool
+	LdapEnabled       bool
+	SnapshotPolicyID  string
+	BackupEnabled     bool
+	CoolAccess        bool
+	CoolnessPeriod    int32
+	ThroughputMibps   float32
+	VolumeType        string
+	// SmbContinuouslyAvailable and SmbEncryption are only set on SMB volumes
+	SmbContinuouslyAvailable bool
+	SmbEncryption            bool
+	// IsLargeVolume is set on volumes that may grow beyond ANF's regular volume size limit
+	IsLargeVolume bool
+	// Zone is the availability zone, such as 1, to which the volume is pinned, if any
+	Zone string
+	// VolumeSpecName is only set on members of an application volume group
+	VolumeSpecName string
+	// Replication details are only set on volumes in a cross-region replication relationship
+	ReplicationEndpointType   string
+	ReplicationRemoteVolumeID string
+	ReplicationSchedule       string
 }
 
 // FilesystemCreateRequest embodies all the details of a volume to be created.
@@ -150,6 +250,44 @@ type FilesystemCreateRequest struct {
 	UnixPermissions   string
 	NetworkFeatures   string
 	KerberosEnabled   bool
+	// LdapEnabled looks up UNIX identities in the Active Directory of the volume's NetApp account
+	LdapEnabled bool
+	// SnapshotPolicy is the name of a snapshot policy in the volume's NetApp account, or a snapshot policy ID
+	SnapshotPolicy string
+	CoolAccess     bool
+	CoolnessPeriod int32
+	// ThroughputMibps is only honored by capacity pools that use manual QoS
+	ThroughputMibps float32
+	// BackupEnabled allows backups of the volume's snapshots; BackupPolicy is a backup policy name or ID
+	BackupEnabled bool
+	BackupPolicy  string
+	// SecurityStyle is only meaningful for dual-protocol volumes; ANF chooses a default if it is empty
+	SecurityStyle string
+	// SmbContinuouslyAvailable and SmbEncryption are only meaningful for SMB volumes
+	SmbContinuouslyAvailable bool
+	SmbEncryption            bool
+	// IsLargeVolume creates a large volume, which can't be converted to or from a regular volume later
+	IsLargeVolume bool
+	// ReplicationSourceID is the resource ID of the volume to replicate, if creating a replication destination
+	ReplicationSourceID string
+	ReplicationSchedule string
+	// Zone is the availability zone, such as 1, in which to place the volume; ANF chooses if it is empty
+	Zone string
+	// VolumeSpecName and ProximityPlacementGroup are only set on members of an application volume group
+	VolumeSpecName          string
+	ProximityPlacementGroup string
+}
+
+// VolumeGroupCreateRequest embodies all the details of an application volume group to be created.  The
+// group and all of its member volumes belong to one NetApp account.
+type VolumeGroupCreateRequest struct {
+	ResourceGroup         string
+	NetAppAccount         string
+	Name                  string
+	ApplicationType       string
+	ApplicationIdentifier string
+	Description           string
+	Volumes               []*FilesystemCreateRequest
 }
 
 // ExportPolicy records details of a discovered Azure volume export policy.
@@ -172,6 +310,19 @@ type ExportRule struct {
 	Kerberos5IReadWrite bool
 	Kerberos5PReadOnly  bool
 	Kerberos5PReadWrite bool
+	// HasRootAccess is whether root has access to the volume, or nil to leave the ANF default
+	HasRootAccess *bool
+	// ChownMode is who may change the ownership of files, or empty to leave the ANF default
+	ChownMode string
+}
+
+// ReplicationStatus records the state of a cross-region replication relationship.
+type ReplicationStatus struct {
+	Healthy            bool
+	MirrorState        string
+	RelationshipStatus string
+	TotalProgress      string
+	ErrorMessage       string
 }
 
 // MountTarget records details of a discovered Azure volume mount target.
@@ -3,15 +3,551 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	netapp "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v4"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
 
 	"github.com/netapp/trident/utils/errors"
 )
 
+type fakeTokenCredential struct{}
+
+func (c *fakeTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestNewDriver_Credential(t *testing.T) {
+	credential := &fakeTokenCredential{}
+
+	sdk, err := NewDriver(ClientConfig{
+		SubscriptionID: "mySubscription",
+		Location:       "myLocation",
+		Credential:     credential,
+	})
+
+	assert.NoError(t, err, "unexpected error")
+	assert.Same(t, credential, sdk.(Client).sdkClient.Credential, "credential not used")
+}
+
+func TestGetAzureCredential_ManagedIdentity(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+
+	config := ClientConfig{SubscriptionID: "mySubscription"}
+	config.UseManagedIdentityExtension = true
+	config.UserAssignedIdentityID = "myIdentityClientID"
+
+	credential, err := GetAzureCredential(config)
+
+	assert.NoError(t, err, "unexpected error")
+	assert.NotNil(t, credential, "no credential")
+}
+
+func TestGetAzureCredential_NoAuth(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+
+	_, err := GetAzureCredential(ClientConfig{SubscriptionID: "mySubscription"})
+
+	assert.Error(t, err, "expected error")
+}
+
+func TestSDKRetryOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ClientConfig
+		expected policy.RetryOptions
+	}{
+		{
+			name:   "Defaults",
+			config: ClientConfig{SDKTimeout: DefaultSDKTimeout},
+			expected: policy.RetryOptions{
+				TryTimeout: DefaultSDKTimeout, RetryDelay: SDKRetryDelay, MaxRetryDelay: SDKMaxRetryDelay,
+				StatusCodes: sdkRetryStatusCodes,
+			},
+		},
+		{
+			name:   "Configured",
+			config: ClientConfig{SDKTimeout: DefaultSDKTimeout, SDKMaxRetries: 5, SDKRetryBackoff: 4 * time.Second},
+			expected: policy.RetryOptions{
+				MaxRetries: 5, TryTimeout: DefaultSDKTimeout, RetryDelay: 4 * time.Second,
+				MaxRetryDelay: SDKMaxRetryDelay, StatusCodes: sdkRetryStatusCodes,
+			},
+		},
+		{
+			name:   "BackoffAboveMaxDelay",
+			config: ClientConfig{SDKTimeout: DefaultSDKTimeout, SDKRetryBackoff: time.Minute},
+			expected: policy.RetryOptions{
+				TryTimeout: DefaultSDKTimeout, RetryDelay: time.Minute, MaxRetryDelay: time.Minute,
+				StatusCodes: sdkRetryStatusCodes,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sdkRetryOptions(test.config), "retry options mismatch")
+		})
+	}
+}
+
+// sequenceTransport returns the given responses in order, repeating the last one once they run out.
+type sequenceTransport struct {
+	responses []*http.Response
+	requests  int
+}
+
+func (t *sequenceTransport) Do(req *http.Request) (*http.Response, error) {
+	resp := t.responses[len(t.responses)-1]
+	if t.requests < len(t.responses) {
+		resp = t.responses[t.requests]
+	}
+	t.requests++
+	return &http.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: http.NoBody, Request: req}, nil
+}
+
+func retryAfterResponse(statusCode int, retryAfter string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{StatusCode: statusCode, Header: header}
+}
+
+func TestSDKRetryOptions_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		responses        []*http.Response
+		expectedStatus   int
+		expectedRequests int
+		minElapsed       time.Duration
+	}{
+		{
+			"RetryAfter",
+			[]*http.Response{
+				retryAfterResponse(http.StatusTooManyRequests, "1"),
+				retryAfterResponse(http.StatusOK, ""),
+			},
+			http.StatusOK, 2, time.Second,
+		},
+		{
+			"RetryAfterAboveMaxDelay",
+			[]*http.Response{
+				retryAfterResponse(http.StatusTooManyRequests, "3600"),
+				retryAfterResponse(http.StatusOK, ""),
+			},
+			http.StatusTooManyRequests, 1, 0,
+		},
+		{
+			"ServerError",
+			[]*http.Response{
+				retryAfterResponse(http.StatusServiceUnavailable, ""),
+				retryAfterResponse(http.StatusOK, ""),
+			},
+			http.StatusOK, 2, 0,
+		},
+		{
+			"Conflict",
+			[]*http.Response{
+				retryAfterResponse(http.StatusConflict, ""),
+				retryAfterResponse(http.StatusOK, ""),
+			},
+			http.StatusConflict, 1, 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &sequenceTransport{responses: test.responses}
+			pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{}, &policy.ClientOptions{
+				Transport: transport,
+				Retry:     sdkRetryOptions(ClientConfig{SDKRetryBackoff: time.Millisecond}),
+			})
+
+			req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://example.com")
+			assert.NoError(t, err, "unexpected error")
+
+			start := time.Now()
+			resp, err := pipeline.Do(req)
+
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedStatus, resp.StatusCode, "status mismatch")
+			assert.Equal(t, test.expectedRequests, transport.requests, "request count mismatch")
+			assert.GreaterOrEqual(t, time.Since(start), test.minElapsed, "Retry-After not honored")
+		})
+	}
+}
+
+func TestSDKRetryOptions_RetryAfterPastDeadline(t *testing.T) {
+	transport := &sequenceTransport{responses: []*http.Response{
+		retryAfterResponse(http.StatusTooManyRequests, "10"),
+		retryAfterResponse(http.StatusOK, ""),
+	}}
+	pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:       transport,
+		Retry:           sdkRetryOptions(ClientConfig{}),
+		PerCallPolicies: sdkPerCallPolicies(ClientConfig{SDKRetryTimeout: 100 * time.Millisecond}),
+	})
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://example.com")
+	assert.NoError(t, err, "unexpected error")
+
+	start := time.Now()
+	_, err = pipeline.Do(req)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "expected deadline error")
+	assert.Equal(t, 1, transport.requests, "request count mismatch")
+	assert.Less(t, time.Since(start), 5*time.Second, "Retry-After delay not cut short by deadline")
+}
+
+type deadlineTransport struct {
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (t *deadlineTransport) Do(req *http.Request) (*http.Response, error) {
+	t.deadline, t.hasDeadline = req.Context().Deadline()
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestSDKPerCallPolicies(t *testing.T) {
+	assert.Empty(t, sdkPerCallPolicies(ClientConfig{}), "unexpected policies")
+
+	transport := &deadlineTransport{}
+	pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:       transport,
+		PerCallPolicies: sdkPerCallPolicies(ClientConfig{SDKRetryTimeout: time.Minute}),
+	})
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://example.com")
+	assert.NoError(t, err, "unexpected error")
+
+	_, err = pipeline.Do(req)
+
+	assert.NoError(t, err, "unexpected error")
+	assert.True(t, transport.hasDeadline, "no deadline set")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), transport.deadline, 5*time.Second, "deadline mismatch")
+}
+
+func TestSDKPerCallPolicies_CallerDeadline(t *testing.T) {
+	transport := &deadlineTransport{}
+	pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:       transport,
+		PerCallPolicies: sdkPerCallPolicies(ClientConfig{SDKRetryTimeout: time.Hour}),
+	})
+
+	callerCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req, err := runtime.NewRequest(callerCtx, http.MethodGet, "https://example.com")
+	assert.NoError(t, err, "unexpected error")
+
+	_, err = pipeline.Do(req)
+
+	assert.NoError(t, err, "unexpected error")
+	callerDeadline, _ := callerCtx.Deadline()
+	assert.Equal(t, callerDeadline, transport.deadline, "caller deadline not kept")
+}
+
+type statusTransport struct {
+	statusCode  int
+	body        string
+	request     *http.Request
+	requestBody string
+}
+
+func (t *statusTransport) Do(req *http.Request) (*http.Response, error) {
+	t.request = req
+	if req.Body != nil {
+		requestBody, _ := io.ReadAll(req.Body)
+		t.requestBody = string(requestBody)
+	}
+	body := io.NopCloser(strings.NewReader(t.body))
+	return &http.Response{StatusCode: t.statusCode, Header: http.Header{}, Body: body, Request: req}, nil
+}
+
+func TestSplitCloneFromParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectError bool
+	}{
+		{"Split", http.StatusOK, false},
+		{"Conflict", http.StatusConflict, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode}
+			resourceClient, err := arm.NewClient("trident.ANFResourceClient", "v1.0.0", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{sdkClient: &AzureClient{ResourceClient: resourceClient}}
+			filesystem := &FileSystem{
+				ID:       "/subscriptions/mySubscription/resourceGroups/myRG/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool/volumes/myVolume",
+				FullName: "myRG/myNetappAccount/myCapacityPool/myVolume",
+			}
+
+			err = c.SplitCloneFromParent(context.Background(), filesystem)
+
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+			assert.Equal(t, http.MethodPost, transport.request.Method, "method mismatch")
+			assert.Equal(t, filesystem.ID+"/splitCloneFromParent", transport.request.URL.Path, "path mismatch")
+			assert.Equal(t, SplitCloneAPIVersion, transport.request.URL.Query().Get("api-version"),
+				"API version mismatch")
+		})
+	}
+}
+
+func TestUpgradeVolumeNetworkFeatures(t *testing.T) {
+	tests := []struct {
+		name            string
+		networkFeatures string
+		statusCode      int
+		expectRequest   bool
+		expectError     bool
+	}{
+		{"Upgrade", NetworkFeaturesBasic, http.StatusOK, true, false},
+		{"Conflict", NetworkFeaturesBasic, http.StatusConflict, true, true},
+		{"NotBasic", NetworkFeaturesStandard, http.StatusOK, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode, body: `{}`}
+			volumesClient, err := netapp.NewVolumesClient("mySubscription", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{config: &ClientConfig{}, sdkClient: &AzureClient{VolumesClient: volumesClient}}
+			filesystem := &FileSystem{
+				ID:              "/subscriptions/mySubscription/resourceGroups/myRG/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool/volumes/myVolume",
+				ResourceGroup:   "myRG",
+				NetAppAccount:   "myNetappAccount",
+				CapacityPool:    "myCapacityPool",
+				Name:            "myVolume",
+				FullName:        "myRG/myNetappAccount/myCapacityPool/myVolume",
+				NetworkFeatures: test.networkFeatures,
+			}
+
+			err = c.UpgradeVolumeNetworkFeatures(context.Background(), filesystem)
+
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, NetworkFeaturesStandard, filesystem.NetworkFeatures, "network features not upgraded")
+			}
+			if test.expectRequest {
+				assert.Equal(t, http.MethodPatch, transport.request.Method, "method mismatch")
+				assert.Equal(t, filesystem.ID, transport.request.URL.Path, "path mismatch")
+			} else {
+				assert.Nil(t, transport.request, "unexpected request")
+			}
+		})
+	}
+}
+
+func TestCreateVolumeGroup_DeploymentSpecID(t *testing.T) {
+	tests := []struct {
+		name             string
+		applicationType  string
+		expectDeployment bool
+	}{
+		{"SAPHANA", ApplicationTypeSAPHANA, true},
+		{"Oracle", "ORACLE", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: http.StatusCreated, body: `{}`}
+			volumeGroupsClient, err := netapp.NewVolumeGroupsClient("mySubscription", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			cPool := &CapacityPool{
+				ID:           "/subscriptions/mySubscription/resourceGroups/myRG/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool",
+				FullName:     "myRG/myNetappAccount/myCapacityPool",
+				Location:     "eastus",
+				ServiceLevel: ServiceLevelUltra,
+			}
+			c := Client{
+				config: &ClientConfig{SubscriptionID: "mySubscription"},
+				sdkClient: &AzureClient{
+					VolumeGroupsClient: volumeGroupsClient,
+					AzureResources: AzureResources{
+						CapacityPoolMap: map[string]*CapacityPool{cPool.FullName: cPool},
+					},
+				},
+			}
+			request := &VolumeGroupCreateRequest{
+				ResourceGroup:   "myRG",
+				NetAppAccount:   "myNetappAccount",
+				Name:            "myGroup",
+				ApplicationType: test.applicationType,
+				Volumes: []*FilesystemCreateRequest{{
+					ResourceGroup: "myRG",
+					NetAppAccount: "myNetappAccount",
+					CapacityPool:  "myCapacityPool",
+					Name:          "myVolume",
+					CreationToken: "myVolume",
+				}},
+			}
+
+			_, _ = c.CreateVolumeGroup(context.Background(), request)
+
+			assert.Equal(t, http.MethodPut, transport.request.Method, "method mismatch")
+			assert.Equal(t, test.expectDeployment,
+				strings.Contains(transport.requestBody, `"deploymentSpecId":"`+SAPHANADeploymentSpecID+`"`),
+				"deployment specification mismatch")
+		})
+	}
+}
+
+func TestModifyVolumeExportPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectError bool
+	}{
+		{"Modify", http.StatusOK, false},
+		{"Conflict", http.StatusConflict, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode, body: `{}`}
+			volumesClient, err := netapp.NewVolumesClient("mySubscription", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{config: &ClientConfig{}, sdkClient: &AzureClient{VolumesClient: volumesClient}}
+			filesystem := &FileSystem{
+				ID:            "/subscriptions/mySubscription/resourceGroups/myRG/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool/volumes/myVolume",
+				ResourceGroup: "myRG",
+				NetAppAccount: "myNetappAccount",
+				CapacityPool:  "myCapacityPool",
+				Name:          "myVolume",
+				FullName:      "myRG/myNetappAccount/myCapacityPool/myVolume",
+				ExportPolicy: ExportPolicy{
+					Rules: []ExportRule{{AllowedClients: "10.0.0.1", Nfsv3: true, RuleIndex: 1}},
+				},
+			}
+			exportPolicy := &ExportPolicy{
+				Rules: []ExportRule{{AllowedClients: "10.0.0.4,10.0.0.5", Nfsv3: true, RuleIndex: 1}},
+			}
+
+			err = c.ModifyVolumeExportPolicy(context.Background(), filesystem, exportPolicy)
+
+			assert.Equal(t, http.MethodPatch, transport.request.Method, "method mismatch")
+			assert.Equal(t, filesystem.ID, transport.request.URL.Path, "path mismatch")
+			assert.Contains(t, transport.requestBody, `"allowedClients":"10.0.0.4,10.0.0.5"`, "body mismatch")
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+				assert.Equal(t, "10.0.0.1", filesystem.ExportPolicy.Rules[0].AllowedClients, "export policy modified")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, *exportPolicy, filesystem.ExportPolicy, "export policy not modified")
+			}
+		})
+	}
+}
+
+func TestVolumeUsedBytes(t *testing.T) {
+	tests := []struct {
+		name                      string
+		statusCode                int
+		body                      string
+		expectedUsedBytes         int64
+		expectedSnapshotUsedBytes int64
+		expectError               bool
+	}{
+		{
+			"Usage", http.StatusOK, `{"value": [
+				{"name": {"value": "VolumeLogicalSize"}, "timeseries": [{"data": [{"average": 1000}, {"average": 2000}, {}]}]},
+				{"name": {"value": "VolumeSnapshotSize"}, "timeseries": [{"data": [{"average": 300}]}]}]}`,
+			2000, 300, false,
+		},
+		{"NoData", http.StatusOK, `{"value": [{"name": {"value": "VolumeLogicalSize"}, "timeseries": []}]}`, 0, 0, false},
+		{"NotFound", http.StatusNotFound, `{}`, 0, 0, true},
+		{"InvalidBody", http.StatusOK, `not json`, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode, body: test.body}
+			resourceClient, err := arm.NewClient("trident.ANFResourceClient", "v1.0.0", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{config: &ClientConfig{}, sdkClient: &AzureClient{ResourceClient: resourceClient}}
+			filesystem := &FileSystem{
+				ID:       "/subscriptions/mySubscription/resourceGroups/myRG/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool/volumes/myVolume",
+				FullName: "myRG/myNetappAccount/myCapacityPool/myVolume",
+			}
+
+			usedBytes, snapshotUsedBytes, err := c.VolumeUsedBytes(context.Background(), filesystem)
+
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+			assert.Equal(t, test.expectedUsedBytes, usedBytes, "used bytes mismatch")
+			assert.Equal(t, test.expectedSnapshotUsedBytes, snapshotUsedBytes, "snapshot used bytes mismatch")
+			assert.Equal(t, http.MethodGet, transport.request.Method, "method mismatch")
+			assert.Equal(t, filesystem.ID+"/providers/Microsoft.Insights/metrics", transport.request.URL.Path,
+				"path mismatch")
+			assert.Equal(t, MetricVolumeLogicalSize+","+MetricVolumeSnapshotSize,
+				transport.request.URL.Query().Get("metricnames"), "metric names mismatch")
+		})
+	}
+}
+
+func TestCloudConfiguration(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected cloud.Configuration
+	}{
+		{"", cloud.AzurePublic},
+		{CloudAzurePublic, cloud.AzurePublic},
+		{CloudAzureUSGovernment, cloud.AzureGovernment},
+		{CloudAzureChina, cloud.AzureChina},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := CloudConfiguration(test.name)
+
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expected.Services[cloud.ResourceManager], actual.Services[cloud.ResourceManager],
+				"resource manager endpoint mismatch")
+		})
+	}
+}
+
+func TestCloudConfigurationNegative(t *testing.T) {
+	_, err := CloudConfiguration("AzureMoonCloud")
+
+	assert.Error(t, err, "expected error")
+}
+
 func TestCreateVirtualNetworkID(t *testing.T) {
 	actual := CreateVirtualNetworkID("mySubscription", "myResourceGroup", "myVnet")
 
@@ -126,6 +662,28 @@ func TestCreateNetappAccountFullName(t *testing.T) {
 	assert.Equal(t, expected, actual, "netapp account full names not equal")
 }
 
+func TestParseNetappAccountID(t *testing.T) {
+	subscriptionID, resourceGroup, provider, netappAccount, err := ParseNetappAccountID(
+		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount")
+
+	assert.Equal(t, "mySubscription", subscriptionID, "subscriptionID not correct")
+	assert.Equal(t, "myResourceGroup", resourceGroup, "resourceGroup not correct")
+	assert.Equal(t, "Microsoft.NetApp", provider, "provider not correct")
+	assert.Equal(t, "myNetappAccount", netappAccount, "netappAccount not correct")
+	assert.NoError(t, err, "error is not nil")
+}
+
+func TestParseNetappAccountIDNegative(t *testing.T) {
+	for _, id := range []string{
+		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts",
+		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool",
+		"/subscriptions/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount",
+	} {
+		_, _, _, _, err := ParseNetappAccountID(id)
+		assert.Error(t, err, "error is nil for %s", id)
+	}
+}
+
 func TestParseCapacityPoolID(t *testing.T) {
 	subscriptionID, resourceGroup, provider, netappAccount, capacityPool, err := ParseCapacityPoolID(
 		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool")
@@ -200,6 +758,41 @@ func TestCreateCapacityPoolFullName(t *testing.T) {
 	assert.Equal(t, expected, actual, "capacity pool full names not equal")
 }
 
+func TestCreateSnapshotPolicyID(t *testing.T) {
+	actual := CreateSnapshotPolicyID("mySubscription", "myResourceGroup", "myNetappAccount", "mySnapshotPolicy")
+
+	expected := "/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/snapshotPolicies/mySnapshotPolicy"
+
+	assert.Equal(t, expected, actual, "snapshot policy IDs not equal")
+}
+
+func TestCreateBackupPolicyID(t *testing.T) {
+	actual := CreateBackupPolicyID("mySubscription", "myResourceGroup", "myNetappAccount", "myBackupPolicy")
+
+	expected := "/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/backupPolicies/myBackupPolicy"
+
+	assert.Equal(t, expected, actual, "backup policy IDs not equal")
+}
+
+func TestParseSnapshotPolicyID(t *testing.T) {
+	subscriptionID, resourceGroup, provider, netappAccount, snapshotPolicy, err := ParseSnapshotPolicyID(
+		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/snapshotPolicies/mySnapshotPolicy")
+
+	assert.Equal(t, "mySubscription", subscriptionID, "subscriptionID not correct")
+	assert.Equal(t, "myResourceGroup", resourceGroup, "resourceGroup not correct")
+	assert.Equal(t, "Microsoft.NetApp", provider, "provider not correct")
+	assert.Equal(t, "myNetappAccount", netappAccount, "netappAccount not correct")
+	assert.Equal(t, "mySnapshotPolicy", snapshotPolicy, "snapshotPolicy not correct")
+	assert.NoError(t, err, "error is not nil")
+}
+
+func TestParseSnapshotPolicyIDNegative(t *testing.T) {
+	_, _, _, _, _, err := ParseSnapshotPolicyID(
+		"/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/netAppAccounts/myNetappAccount/capacityPools/myCapacityPool")
+
+	assert.Error(t, err, "error is nil")
+}
+
 func TestCreateVolumeID(t *testing.T) {
 	actual := CreateVolumeID("mySubscription", "myResourceGroup", "myNetappAccount", "myCapacityPool", "myVolume")
 
@@ -584,6 +1177,111 @@ func TestIsANFNotFoundError_OtherError(t *testing.T) {
 	assert.False(t, result, "result should be false")
 }
 
+type fakeNetError struct {
+	timeout bool
+}
+
+func (e *fakeNetError) Error() string   { return "network error" }
+func (e *fakeNetError) Timeout() bool   { return e.timeout }
+func (e *fakeNetError) Temporary() bool { return false }
+
+func TestIsANFConflictError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"Other", errors.New("failed"), false},
+		{"NoResponse", &azcore.ResponseError{}, false},
+		{"BadRequest", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusBadRequest}}, false},
+		{"Conflict", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusConflict}}, true},
+		{"Throttled", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests}}, false},
+		{
+			"ServiceUnavailable",
+			&azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusServiceUnavailable}}, false,
+		},
+		{"Wrapped", fmt.Errorf("create failed; %w", &azcore.ResponseError{
+			RawResponse: &http.Response{StatusCode: http.StatusConflict},
+		}), true},
+		{"NetworkTimeout", &fakeNetError{timeout: true}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsANFConflictError(test.err), "conflict mismatch")
+		})
+	}
+}
+
+func TestIsANFSubnetExhaustedError(t *testing.T) {
+	subnetFullErr := &azcore.ResponseError{
+		ErrorCode:   "SubnetIsFull",
+		RawResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+	badRequestErr := &azcore.ResponseError{
+		ErrorCode:   "InvalidParameter",
+		RawResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"Other", errors.New("failed"), false},
+		{"NoErrorCode", &azcore.ResponseError{}, false},
+		{"BadRequest", badRequestErr, false},
+		{"SubnetIsFull", subnetFullErr, true},
+		{"InsufficientSubnetSize", &azcore.ResponseError{ErrorCode: "InsufficientSubnetSize"}, true},
+		{"Wrapped", fmt.Errorf("create failed; %w", subnetFullErr), true},
+		{"Combined", multierr.Combine(badRequestErr, fmt.Errorf("create failed; %w", subnetFullErr)), true},
+		{"CombinedOther", multierr.Combine(badRequestErr, errors.New("failed")), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsANFSubnetExhaustedError(test.err), "subnet exhausted mismatch")
+		})
+	}
+}
+
+func TestIsUnavailableError(t *testing.T) {
+	unavailableErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+	unauthorizedErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusUnauthorized}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"Other", errors.New("no capacity pools found for storage pool pool1"), false},
+		{"NoResponse", &azcore.ResponseError{}, false},
+		{"Unauthorized", unauthorizedErr, false},
+		{"Forbidden", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusForbidden}}, false},
+		{
+			"RequestTimeout",
+			&azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusRequestTimeout}}, true,
+		},
+		{"Throttled", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests}}, true},
+		{"ServiceUnavailable", unavailableErr, true},
+		{"Wrapped", fmt.Errorf("discovery failed; %w", unavailableErr), true},
+		{"NetworkTimeout", &fakeNetError{timeout: true}, true},
+		{"NetworkError", &fakeNetError{timeout: false}, true},
+		{"DeadlineExceeded", context.DeadlineExceeded, true},
+		{"AllUnavailable", multierr.Combine(unavailableErr, &fakeNetError{}), true},
+		{"SomeNotUnavailable", multierr.Combine(unavailableErr, unauthorizedErr), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsUnavailableError(test.err), "unavailable mismatch")
+		})
+	}
+}
+
 func TestGetCorrelationIDFromError_Nil(t *testing.T) {
 	result := GetCorrelationIDFromError(nil)
 
@@ -723,6 +1421,25 @@ func TestDerefInt32(t *testing.T) {
 	}
 }
 
+func TestDerefFloat32(t *testing.T) {
+	f1 := float32(0)
+	f2 := float32(12.5)
+
+	testCases := []struct {
+		Ptr            *float32
+		ExpectedResult float32
+	}{
+		{nil, 0},
+		{&f1, 0},
+		{&f2, 12.5},
+	}
+
+	for _, testCase := range testCases {
+		result := DerefFloat32(testCase.Ptr)
+		assert.Equal(t, testCase.ExpectedResult, result)
+	}
+}
+
 func TestDerefInt64(t *testing.T) {
 	i1 := int64(0)
 	i2 := int64(42)
@@ -751,3 +1468,41 @@ func TestIsTerminalStateError(t *testing.T) {
 	assert.False(t, IsTerminalStateError(nil))
 	assert.False(t, IsTerminalStateError(errors.New("not terminal")))
 }
+
+func TestReplicationFromVolume(t *testing.T) {
+	remoteID := "/subscriptions/mySubscription/resourceGroups/myResourceGroup/providers/Microsoft.NetApp/" +
+		"netAppAccounts/myNetappAccount/capacityPools/myCapacityPool/volumes/myVolume"
+	endpointType := netapp.EndpointTypeDst
+	schedule := netapp.ReplicationScheduleHourly
+
+	vol := &netapp.Volume{
+		Properties: &netapp.VolumeProperties{
+			DataProtection: &netapp.VolumePropertiesDataProtection{
+				Replication: &netapp.ReplicationObject{
+					RemoteVolumeResourceID: &remoteID,
+					EndpointType:           &endpointType,
+					ReplicationSchedule:    &schedule,
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, ReplicationEndpointTypeDestination, replicationEndpointTypeFromVolume(vol))
+	assert.Equal(t, remoteID, replicationRemoteVolumeIDFromVolume(vol))
+	assert.Equal(t, ReplicationScheduleHourly, replicationScheduleFromVolume(vol))
+}
+
+func TestReplicationFromVolume_NoReplication(t *testing.T) {
+	vol := &netapp.Volume{Properties: &netapp.VolumeProperties{}}
+
+	assert.Equal(t, "", replicationEndpointTypeFromVolume(vol))
+	assert.Equal(t, "", replicationRemoteVolumeIDFromVolume(vol))
+	assert.Equal(t, "", replicationScheduleFromVolume(vol))
+}
+
+func TestZoneFromVolume(t *testing.T) {
+	zone := "2"
+
+	assert.Equal(t, "2", zoneFromVolume(&netapp.Volume{Zones: []*string{nil, &zone}}))
+	assert.Equal(t, "", zoneFromVolume(&netapp.Volume{}))
+}
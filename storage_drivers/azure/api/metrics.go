@@ -0,0 +1,103 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	tridentconfig "github.com/netapp/trident/config"
+)
+
+const (
+	OperationVolumeList                   = "volume_list"
+	OperationVolumeGet                    = "volume_get"
+	OperationVolumeCreate                 = "volume_create"
+	OperationVolumeModify                 = "volume_modify"
+	OperationVolumeResize                 = "volume_resize"
+	OperationVolumeDelete                 = "volume_delete"
+	OperationVolumeSplit                  = "volume_split"
+	OperationVolumeMetrics                = "volume_metrics"
+	OperationVolumeNetworkFeaturesUpgrade = "volume_network_features_upgrade"
+	OperationVolumeRelocate               = "volume_relocate"
+	OperationVolumeGroupCreate            = "volume_group_create"
+	OperationVolumeGroupDelete            = "volume_group_delete"
+	OperationSnapshotList                 = "snapshot_list"
+	OperationSnapshotGet                  = "snapshot_get"
+	OperationSnapshotCreate               = "snapshot_create"
+	OperationSnapshotRestore              = "snapshot_restore"
+	OperationSnapshotDelete               = "snapshot_delete"
+	OperationReplicationAuthorize         = "replication_authorize"
+	OperationReplicationBreak             = "replication_break"
+	OperationReplicationResync            = "replication_resync"
+	OperationReplicationDelete            = "replication_delete"
+	OperationReplicationStatus            = "replication_status"
+	OperationBackupCreate                 = "backup_create"
+	OperationBackupDelete                 = "backup_delete"
+	OperationSnapshotPolicyGet            = "snapshot_policy_get"
+	OperationSubvolumeList                = "subvolume_list"
+	OperationSubvolumeGet                 = "subvolume_get"
+	OperationSubvolumeMetadata            = "subvolume_metadata"
+	OperationSubvolumeCreate              = "subvolume_create"
+	OperationSubvolumeResize              = "subvolume_resize"
+	OperationSubvolumeDelete              = "subvolume_delete"
+	OperationFeatureGet                   = "feature_get"
+	OperationCapacityPoolDiscover         = "capacity_pool_discover"
+	OperationNetAppAccountDiscover        = "netapp_account_discover"
+	OperationSubnetDiscover               = "subnet_discover"
+
+	OperationResultSuccess   = "success"
+	OperationResultThrottled = "throttled"
+	OperationResultError     = "error"
+)
+
+var (
+	sdkOperationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: tridentconfig.OrchestratorName,
+			Subsystem: "anf",
+			Name:      "sdk_operations_total",
+			Help:      "The total number of ANF SDK operations",
+		},
+		[]string{"operation", "result"},
+	)
+	sdkOperationDurationHistogram = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: tridentconfig.OrchestratorName,
+			Subsystem: "anf",
+			Name:      "sdk_operation_duration_seconds",
+			Help:      "The duration of ANF SDK operations, including any retries",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		},
+		[]string{"operation", "result"},
+	)
+)
+
+// operationResult classifies the outcome of an SDK operation for reporting.
+func operationResult(err error) string {
+	switch {
+	case err == nil:
+		return OperationResultSuccess
+	case IsANFTooManyRequestsError(err):
+		return OperationResultThrottled
+	default:
+		return OperationResultError
+	}
+}
+
+// recordOperation returns a function that, when deferred, counts an SDK operation and records its duration
+// along with its result.  Nothing is collected unless metrics are enabled for Trident and for the client.
+func (c Client) recordOperation(operation string, err *error) func() {
+	if !tridentconfig.MetricsEnabled || c.config == nil || c.config.DisableSDKMetrics {
+		return func() {}
+	}
+
+	startTime := time.Now()
+	return func() {
+		result := operationResult(*err)
+		sdkOperationsTotal.WithLabelValues(operation, result).Inc()
+		sdkOperationDurationHistogram.WithLabelValues(operation, result).Observe(time.Since(startTime).Seconds())
+	}
+}
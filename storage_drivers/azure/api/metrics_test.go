@@ -0,0 +1,231 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	netapp "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	tridentconfig "github.com/netapp/trident/config"
+)
+
+func TestOperationResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Success", nil, OperationResultSuccess},
+		{"Throttled", &azcore.ResponseError{
+			RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests},
+		}, OperationResultThrottled},
+		{"ServerError", &azcore.ResponseError{
+			RawResponse: &http.Response{StatusCode: http.StatusInternalServerError},
+		}, OperationResultError},
+		{"OtherError", errors.New("failed"), OperationResultError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, operationResult(test.err), "result mismatch")
+		})
+	}
+}
+
+func TestRecordOperation(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	c := Client{config: &ClientConfig{}}
+	successCount := testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeGet, OperationResultSuccess))
+	errorCount := testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeGet, OperationResultError))
+
+	var err error
+	c.recordOperation(OperationVolumeGet, &err)()
+
+	err = errors.New("failed")
+	c.recordOperation(OperationVolumeGet, &err)()
+
+	assert.Equal(t, successCount+1,
+		testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeGet, OperationResultSuccess)),
+		"success count mismatch")
+	assert.Equal(t, errorCount+1,
+		testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeGet, OperationResultError)),
+		"error count mismatch")
+}
+
+func TestRecordOperation_MetricsDisabled(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+
+	tests := []struct {
+		name           string
+		metricsEnabled bool
+		config         *ClientConfig
+	}{
+		{"TridentMetricsDisabled", false, &ClientConfig{}},
+		{"SDKMetricsDisabled", true, &ClientConfig{DisableSDKMetrics: true}},
+		{"NoConfig", true, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tridentconfig.MetricsEnabled = test.metricsEnabled
+
+			c := Client{config: test.config}
+			count := testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeDelete, OperationResultSuccess))
+
+			var err error
+			c.recordOperation(OperationVolumeDelete, &err)()
+
+			assert.Equal(t, count,
+				testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationVolumeDelete, OperationResultSuccess)),
+				"operation counted")
+		})
+	}
+}
+
+func TestDeleteBackup_RecordsOperation(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	tests := []struct {
+		name       string
+		statusCode int
+		result     string
+	}{
+		{"Deleted", http.StatusOK, OperationResultSuccess},
+		{"AlreadyDeleted", http.StatusNotFound, OperationResultSuccess},
+		{"Conflict", http.StatusConflict, OperationResultError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode, body: `{}`}
+			backupsClient, err := netapp.NewBackupsClient("mySubscription", &fakeTokenCredential{},
+				&arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}})
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{config: &ClientConfig{}, sdkClient: &AzureClient{BackupsClient: backupsClient}}
+			filesystem := &FileSystem{
+				ResourceGroup: "myRG",
+				NetAppAccount: "myNetappAccount",
+				CapacityPool:  "myCapacityPool",
+				Name:          "myVolume",
+				FullName:      "myRG/myNetappAccount/myCapacityPool/myVolume",
+			}
+			count := testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationBackupDelete, test.result))
+
+			_ = c.DeleteBackup(context.Background(), filesystem, "myBackup")
+
+			assert.Equal(t, count+1,
+				testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(OperationBackupDelete, test.result)),
+				"operation count mismatch")
+		})
+	}
+}
+
+func TestSDKCalls_RecordOperation(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	ctx := context.Background()
+	clientOptions := func(transport *statusTransport) *arm.ClientOptions {
+		return &arm.ClientOptions{ClientOptions: policy.ClientOptions{Transport: transport}}
+	}
+	filesystem := &FileSystem{
+		ID:            CreateVolumeID("mySubscription", "myRG", "myNetappAccount", "myCapacityPool", "myVolume"),
+		ResourceGroup: "myRG",
+		NetAppAccount: "myNetappAccount",
+		CapacityPool:  "myCapacityPool",
+		Name:          "myVolume",
+		FullName:      "myRG/myNetappAccount/myCapacityPool/myVolume",
+	}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		operation  string
+		result     string
+		call       func(c Client) error
+	}{
+		{
+			name:       "ModifyVolumeThroughput",
+			statusCode: http.StatusConflict,
+			operation:  OperationVolumeModify,
+			result:     OperationResultError,
+			call: func(c Client) error {
+				return c.ModifyVolumeThroughput(ctx, filesystem, 128)
+			},
+		},
+		{
+			name:       "SnapshotPolicyExists",
+			statusCode: http.StatusNotFound,
+			operation:  OperationSnapshotPolicyGet,
+			result:     OperationResultSuccess,
+			call: func(c Client) error {
+				_, err := c.SnapshotPolicyExists(ctx, CreateSnapshotPolicyID("mySubscription", "myRG",
+					"myNetappAccount", "myPolicy"))
+				return err
+			},
+		},
+		{
+			name:       "SubvolumeByID",
+			statusCode: http.StatusInternalServerError,
+			operation:  OperationSubvolumeGet,
+			result:     OperationResultError,
+			call: func(c Client) error {
+				_, err := c.SubvolumeByID(ctx, CreateSubvolumeID("mySubscription", "myRG", "myNetappAccount",
+					"myCapacityPool", "myVolume", "mySubvolume"), false)
+				return err
+			},
+		},
+		{
+			name:       "VolumeExistsByID",
+			statusCode: http.StatusInternalServerError,
+			operation:  OperationVolumeGet,
+			result:     OperationResultError,
+			call: func(c Client) error {
+				_, _, err := c.VolumeExistsByID(ctx, filesystem.ID)
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &statusTransport{statusCode: test.statusCode, body: `{}`}
+
+			volumesClient, err := netapp.NewVolumesClient("mySubscription", &fakeTokenCredential{},
+				clientOptions(transport))
+			assert.NoError(t, err, "unexpected error")
+			policiesClient, err := netapp.NewSnapshotPoliciesClient("mySubscription", &fakeTokenCredential{},
+				clientOptions(transport))
+			assert.NoError(t, err, "unexpected error")
+			subvolumesClient, err := netapp.NewSubvolumesClient("mySubscription", &fakeTokenCredential{},
+				clientOptions(transport))
+			assert.NoError(t, err, "unexpected error")
+
+			c := Client{config: &ClientConfig{}, sdkClient: &AzureClient{
+				VolumesClient:    volumesClient,
+				PoliciesClient:   policiesClient,
+				SubvolumesClient: subvolumesClient,
+			}}
+			count := testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(test.operation, test.result))
+
+			_ = test.call(c)
+
+			assert.Equal(t, count+1,
+				testutil.ToFloat64(sdkOperationsTotal.WithLabelValues(test.operation, test.result)),
+				"operation count mismatch")
+		})
+	}
+}
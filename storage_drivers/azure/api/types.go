@@ -16,6 +16,7 @@ type Azure interface {
 
 	RefreshAzureResources(context.Context) error
 	DiscoverAzureResources(context.Context) error
+	CacheStatus() *storage.BackendCacheStatus
 	EnableAzureFeatures(context.Context, ...string) error
 	Features() map[string]bool
 	HasFeature(string) bool
@@ -25,6 +26,7 @@ type Azure interface {
 	EnsureVolumeInValidCapacityPool(context.Context, *FileSystem) error
 	SubnetsForStoragePool(context.Context, storage.Pool) []*Subnet
 	RandomSubnetForStoragePool(context.Context, storage.Pool) *Subnet
+	SubnetForStoragePool(context.Context, storage.Pool) *Subnet
 
 	Volumes(context.Context) (*[]*FileSystem, error)
 	Volume(context.Context, *storage.VolumeConfig) (*FileSystem, error)
@@ -35,9 +37,26 @@ type Azure interface {
 	VolumeExistsByID(context.Context, string) (bool, *FileSystem, error)
 	WaitForVolumeState(context.Context, *FileSystem, string, []string, time.Duration) (string, error)
 	CreateVolume(context.Context, *FilesystemCreateRequest) (*FileSystem, error)
+	CreateVolumeGroup(context.Context, *VolumeGroupCreateRequest) ([]*FileSystem, error)
 	ModifyVolume(context.Context, *FileSystem, map[string]string, *string, *bool, *ExportRule) error
 	ResizeVolume(context.Context, *FileSystem, int64) error
+	ModifyVolumeSnapshotPolicy(context.Context, *FileSystem, string) error
+	SnapshotPolicyExists(context.Context, string) (bool, error)
+	ModifyVolumeThroughput(context.Context, *FileSystem, float32) error
+	CapacityPoolUsedBytes(context.Context, *CapacityPool) (int64, error)
+	VolumeUsedBytes(context.Context, *FileSystem) (int64, int64, error)
+	ModifyVolumeExportPolicy(context.Context, *FileSystem, *ExportPolicy) error
+	ModifyVolumeCoolAccess(context.Context, *FileSystem, bool, int32) error
+	RelocateVolume(context.Context, *FileSystem, *CapacityPool) error
+	UpgradeVolumeNetworkFeatures(context.Context, *FileSystem) error
+	SplitCloneFromParent(context.Context, *FileSystem) error
+	AuthorizeReplication(context.Context, string, *FileSystem) error
+	BreakReplication(context.Context, *FileSystem) error
+	ResyncReplication(context.Context, *FileSystem) error
+	DeleteReplication(context.Context, *FileSystem) error
+	ReplicationStatus(context.Context, *FileSystem) (*ReplicationStatus, error)
 	DeleteVolume(context.Context, *FileSystem) error
+	DeleteVolumeGroup(context.Context, string, string, string) error
 
 	Subvolumes(context.Context, []string) (*[]*Subvolume, error)
 	Subvolume(context.Context, *storage.VolumeConfig, bool) (*Subvolume, error)
@@ -59,4 +78,7 @@ type Azure interface {
 	CreateSnapshot(context.Context, *FileSystem, string) (*Snapshot, error)
 	RestoreSnapshot(context.Context, *FileSystem, *Snapshot) error
 	DeleteSnapshot(context.Context, *FileSystem, *Snapshot) error
+
+	CreateBackup(context.Context, *FileSystem, string) error
+	DeleteBackup(context.Context, *FileSystem, string) error
 }
@@ -7,11 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
@@ -22,6 +23,7 @@ import (
 	"github.com/netapp/trident/acp"
 	tridentconfig "github.com/netapp/trident/config"
 	. "github.com/netapp/trident/logging"
+	v1 "github.com/netapp/trident/persistent_store/crd/apis/netapp/v1"
 	"github.com/netapp/trident/storage"
 	sa "github.com/netapp/trident/storage_attribute"
 	drivers "github.com/netapp/trident/storage_drivers"
@@ -31,48 +33,169 @@ import (
 )
 
 const (
-	MinimumVolumeSizeBytes    = uint64(1000000000)   // 1 GB
-	MinimumANFVolumeSizeBytes = uint64(107374182400) // 100 GiB
-
-	defaultUnixPermissions         = "" // TODO (cknight): change to "0777" when whitelisted permissions feature reaches GA
-	defaultNfsMountOptions         = "nfsvers=3"
-	defaultKerberosNfsMountOptions = "nfsvers=4.1"
-	defaultSnapshotDir             = "false"
-	defaultLimitVolumeSize         = ""
-	defaultExportRule              = "0.0.0.0/0"
-	defaultVolumeSizeStr           = "107374182400"
-	defaultNetworkFeatures         = "" // Leave empty, some regions may never support this
+	MinimumVolumeSizeBytes    = uint64(1000000000)      // 1 GB
+	MinimumANFVolumeSizeBytes = uint64(107374182400)    // 100 GiB
+	MaximumANFVolumeSizeBytes = uint64(109951162777600) // 100 TiB, above which only large volumes may grow
+
+	MinimumANFLargeVolumeSizeBytes = uint64(54975581388800) // 50 TiB
+
+	defaultUnixPermissions      = ""
+	defaultUnixPermissionsMode  = UnixPermissionsModeFeatureGated
+	defaultExportRuleValidation = ExportRuleValidationLenient
+	featureGatedUnixPermissions = "0777"
+	defaultNfsMountOptions      = "nfsvers=3"
+	defaultKerberosNfsVersion   = nfsVersion41
+	defaultSnapshotDir          = "false"
+	defaultCoolAccess           = "false"
+	defaultSplitOnClone         = "false"
+	defaultBackupEnabled        = "false"
+	defaultCoolnessPeriod       = "31"
+	defaultLimitVolumeSize      = ""
+	defaultExportRule           = "0.0.0.0/0"
+	defaultVolumeSizeStr        = "107374182400"
+	defaultNetworkFeatures      = "" // Leave empty, some regions may never support this
+	maxSnapshotReserve          = 90
+
+	// A new volume whose state can't be read is checked this many times before the create is retried later
+	maxVolumeCreateReadAttempts        = 3
+	defaultVolumeCreateConflictRetries = 2
+	volumeCreateReadRetryTimeout       = 30 * time.Second
+
+	// Volumes whose creation failed are only cleaned up once they have been failed this long
+	defaultFailedVolumeCleanup    = FailedVolumeCleanupDisabled
+	defaultFailedVolumeCleanupAge = time.Hour
+
+	// Volumes being created asynchronously are checked this far apart
+	pendingVolumePollInterval = 10 * time.Second
+
+	// The background cache refresh checks the age of the Azure resource cache at most this far apart
+	maxCacheRefreshCheckInterval = time.Minute
+	minCacheRefreshCheckInterval = time.Second
+
+	// Once the caller's deadline has passed, waits are cut short to this rather than to zero, which the
+	// backoff retries treat as no limit at all
+	minEffectiveTimeout = time.Second
+
+	// Modes for choosing the permissions of volumes when none are requested or configured
+
+	UnixPermissionsModeFeatureGated = "featureGated" // 0777 if the subscription has the permissions feature
+	UnixPermissionsModeEmpty        = "empty"        // Leave permissions unset, so ANF applies its own default
+	UnixPermissionsModeExplicit     = "explicit"     // Use the backend's defaultUnixPermissions value
+
+	// Modes for handling suspicious export rule addresses, such as loopback or multicast ranges
+
+	ExportRuleValidationLenient = "lenient" // Accept any valid address or CIDR
+	ExportRuleValidationWarn    = "warn"    // Log a warning for suspicious addresses
+	ExportRuleValidationReject  = "reject"  // Fail validation for suspicious addresses
+
+	// Access values of the export rule shorthand
+
+	exportRuleAccessReadOnly  = "ro"
+	exportRuleAccessReadWrite = "rw"
+
+	// Modes for cleaning up volumes whose creation failed
+
+	FailedVolumeCleanupDisabled = "disabled" // Leave failed volumes alone
+	FailedVolumeCleanupDryRun   = "dryRun"   // Log the failed volumes that would be deleted
+	FailedVolumeCleanupDelete   = "delete"   // Delete failed volumes
+
+	// NASTypeDual creates volumes accessible via both NFSv3 and SMB
+	NASTypeDual = "dual"
+
+	// States of a volume's replication relationship, as reported for DR monitoring
+
+	ReplicationStateNone         = "none"         // The volume isn't replicated
+	ReplicationStateInitializing = "initializing" // The baseline transfer hasn't completed
+	ReplicationStateHealthy      = "healthy"      // Transfers are keeping up with the replication schedule
+	ReplicationStateLagging      = "lagging"      // A scheduled transfer has been missed
+	ReplicationStateBroken       = "broken"       // The relationship is broken or unhealthy
+
+	// ANF names the snapshots it creates for each replication transfer with this prefix
+	replicationSnapshotPrefix = "snapmirror."
 
 	// Constants for internal pool attributes
 
-	Size            = "size"
-	UnixPermissions = "unixPermissions"
-	ServiceLevel    = "serviceLevel"
-	SnapshotDir     = "snapshotDir"
-	ExportRule      = "exportRule"
-	VirtualNetwork  = "virtualNetwork"
-	NetworkFeatures = "networkFeatures"
-	Subnet          = "subnet"
-	ResourceGroups  = "resourceGroups"
-	NetappAccounts  = "netappAccounts"
-	CapacityPools   = "capacityPools"
-	FilePoolVolumes = "filePoolVolumes"
-	Kerberos        = "kerberos"
+	Size                      = "size"
+	UnixPermissions           = "unixPermissions"
+	ServiceLevel              = "serviceLevel"
+	SnapshotDir               = "snapshotDir"
+	ExportRule                = "exportRule"
+	ExportRules               = "exportRules"
+	VirtualNetwork            = "virtualNetwork"
+	NetworkFeatures           = "networkFeatures"
+	Subnet                    = "subnet"
+	ResourceGroups            = "resourceGroups"
+	NetappAccounts            = "netappAccounts"
+	CapacityPools             = "capacityPools"
+	FilePoolVolumes           = "filePoolVolumes"
+	Kerberos                  = "kerberos"
+	SubnetSelection           = "subnetSelection"
+	SnapshotPolicy            = "snapshotPolicy"
+	CoolAccess                = "coolAccess"
+	CoolnessPeriod            = "coolnessPeriod"
+	CoolAccessRetrievalPolicy = "coolAccessRetrievalPolicy"
+	Throughput                = "throughput"
+	SnapshotReserve           = "snapshotReserve"
+	BackupEnabled             = "backupEnabled"
+	BackupPolicy              = "backupPolicy"
+	LDAPEnabled               = "ldapEnabled"
+	HasRootAccess             = "hasRootAccess"
+	ChownMode                 = "chownMode"
+	SplitOnClone              = "splitOnClone"
+	SMBContinuousAvailability = "smbContinuousAvailability"
+	SMBEncryption             = "smbEncryption"
+	LargeVolume               = "largeVolume"
 
 	nfsVersion3  = "3"
 	nfsVersion4  = "4"
+	nfsVersion40 = "4.0"
 	nfsVersion41 = "4.1"
 
 	DefaultConfigurationFilePath = "/etc/kubernetes/azure.json"
+
+	// Environment variables injected by the AKS workload identity webhook
+	DefaultFederatedTokenFileEnv = "AZURE_FEDERATED_TOKEN_FILE"
+	DefaultClientIDEnv           = "AZURE_CLIENT_ID"
+	DefaultTenantIDEnv           = "AZURE_TENANT_ID"
+
+	// TridentVersionTag is the volume tag recording the Trident version that created the volume
+	TridentVersionTag = "trident-version"
+	// TridentNameTag is the volume tag recording the name Trident knows a volume by, since creation tokens
+	// are immutable
+	TridentNameTag = "trident-name"
+	// SnapshotReserveTag is the volume tag recording the percentage of a volume's quota set aside for snapshots
+	SnapshotReserveTag = "trident-snapshot-reserve"
+	// StoragePoolTag is the volume tag recording the virtual pool a volume was created in, so that the pool's
+	// export rules may be applied when node access is reconciled
+	StoragePoolTag = "trident-storage-pool"
+	// ExplicitExportRuleTag is the volume tag marking a volume whose export rule was set explicitly, such as by
+	// a PVC annotation, which node access reconciliation leaves alone
+	ExplicitExportRuleTag = "trident-explicit-export-rule"
+
+	topologyZoneLabel   = drivers.TopologyLabelPrefix + "/" + sa.Zone
+	topologyRegionLabel = drivers.TopologyLabelPrefix + "/" + sa.Region
 )
 
 var (
 	supportedNFSVersions = []string{nfsVersion3, nfsVersion4, nfsVersion41}
 
+	// replicationScheduleIntervals are how far apart ANF transfers data for each replication schedule
+	replicationScheduleIntervals = map[string]time.Duration{
+		api.ReplicationSchedule10Minutely: 10 * time.Minute,
+		api.ReplicationScheduleHourly:     time.Hour,
+		api.ReplicationScheduleDaily:      24 * time.Hour,
+	}
+	// ANF offers Kerberos only for NFSv4.1 volumes, which NFSv4 clients mount using the highest minor version
+	kerberosNFSVersions = []string{nfsVersion4, nfsVersion41}
+
 	storagePrefixRegex       = regexp.MustCompile(`^$|^[a-zA-Z][a-zA-Z-]*$`)
 	volumeNameRegex          = regexp.MustCompile(`^[a-zA-Z][a-zA-Z\d-_]{0,63}$`)
 	volumeCreationTokenRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z\d-]{0,79}$`)
 	csiRegex                 = regexp.MustCompile(`^pvc-[\da-fA-F]{8}-[\da-fA-F]{4}-[\da-fA-F]{4}-[\da-fA-F]{4}-[\da-fA-F]{12}$`)
+	snapshotPolicyRegex      = regexp.MustCompile(`^[a-zA-Z\d][a-zA-Z\d-_]{0,63}$`)
+	kerberosMountOptionRegex = regexp.MustCompile(`^sec=(.*:)?krb5[ip]?(:.*)?$`)
+	smbMountOptionRegex      = regexp.MustCompile(
+		`^(username|user|password|pass|domain|dom|workgroup|credentials|cred|file_mode|dir_mode)=|^sec=ntlm`)
 )
 
 // NASStorageDriver is for storage provisioning using the Azure NetApp Files service.
@@ -82,7 +205,61 @@ type NASStorageDriver struct {
 	telemetry           *Telemetry
 	SDK                 api.Azure
 	pools               map[string]storage.Pool
+	poolsLock           sync.RWMutex
 	volumeCreateTimeout time.Duration
+
+	// volumeCreateConflictRetries is how many times a volume create request rejected with a 409 Conflict is
+	// repeated.  Other errors are never repeated, and neither are requests issued in parallel, whose conflicts
+	// usually come from each other.
+	volumeCreateConflictRetries    int
+	volumeCreateConflictRetryDelay time.Duration
+
+	// parallelCreatePools is how many capacity pools a volume create is attempted in at once
+	parallelCreatePools int
+
+	// cloneSnapshotReuseAge is how old a snapshot may be for a clone to use it instead of a new snapshot,
+	// or zero if clones always use a new snapshot
+	cloneSnapshotReuseAge time.Duration
+
+	// cacheRefreshDone stops the background refresh of the Azure resource cache, if one is running
+	cacheRefreshDone      chan struct{}
+	cacheRefreshWaitGroup sync.WaitGroup
+
+	// autoExportClients is the list of node addresses most recently applied by ReconcileNodeAccess
+	autoExportClients     string
+	autoExportClientsLock sync.RWMutex
+
+	// credentialProviders supply the Azure credentials; if empty, the built-in providers are used
+	credentialProviders []CredentialProvider
+
+	// snapshotDirConfigured is whether the backend config sets snapshotDir, rather than taking the default
+	snapshotDirConfigured bool
+
+	// initialDiscoveryFailed is whether the Azure resources could not be discovered during initialization,
+	// in which case the checks of the config against the discovered resources wait for the next discovery
+	initialDiscoveryFailed bool
+	initialDiscoveryLock   sync.Mutex
+
+	provisioningLatency provisioningLatencyTracker
+	volumeUsage         volumeUsageCache
+
+	// failedVolumes records when each failed volume was first seen, keyed by the volume's full name
+	failedVolumes          map[string]time.Time
+	failedVolumesLock      sync.Mutex
+	failedVolumeCleanupAge time.Duration
+
+	// pendingVolumes are the volumes being created asynchronously, keyed by creation token, which the
+	// background poller watches until they are available or have failed
+	pendingVolumes          map[string]*pendingVolume
+	pendingVolumesLock      sync.Mutex
+	pendingVolumesDone      chan struct{}
+	pendingVolumesWaitGroup sync.WaitGroup
+}
+
+// pendingVolume is a volume being created asynchronously.
+type pendingVolume struct {
+	volume    *api.FileSystem
+	startTime time.Time
 }
 
 type Telemetry struct {
@@ -148,6 +325,23 @@ func (d *NASStorageDriver) defaultCreateTimeout() time.Duration {
 	}
 }
 
+// minimumVolumeSize returns the size to which smaller volume requests are increased, which is the configured
+// minimumVolumeSize if it is valid and ANF's usual minimum otherwise.
+func (d *NASStorageDriver) minimumVolumeSize() uint64 {
+	if d.Config.MinimumVolumeSize == "" {
+		return MinimumANFVolumeSizeBytes
+	}
+	minimumBytesStr, err := utils.ConvertSizeToBytes(d.Config.MinimumVolumeSize)
+	if err != nil {
+		return MinimumANFVolumeSizeBytes
+	}
+	minimumBytes, err := strconv.ParseUint(minimumBytesStr, 10, 64)
+	if err != nil || minimumBytes < MinimumVolumeSizeBytes {
+		return MinimumANFVolumeSizeBytes
+	}
+	return minimumBytes
+}
+
 // defaultTimeout controls the driver timeout for most workflows.
 func (d *NASStorageDriver) defaultTimeout() time.Duration {
 	switch d.Config.DriverContext {
@@ -158,6 +352,32 @@ func (d *NASStorageDriver) defaultTimeout() time.Duration {
 	}
 }
 
+// effectiveTimeout returns the lesser of the specified timeout and the time remaining before the context's
+// deadline, so that the driver doesn't keep working after the caller has given up.  The result is always
+// positive, so a deadline that has already passed still bounds the wait.
+func (d *NASStorageDriver) effectiveTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+
+	remaining := time.Until(deadline)
+	if remaining < minEffectiveTimeout {
+		Logc(ctx).WithField("remaining", remaining.Truncate(time.Millisecond)).Debug(
+			"Context deadline has passed or is imminent, using minimum timeout.")
+		return minEffectiveTimeout
+	}
+	if remaining < timeout {
+		Logc(ctx).WithFields(LogFields{
+			"timeout":   timeout,
+			"remaining": remaining.Truncate(time.Millisecond),
+		}).Debug("Using context deadline as timeout.")
+		return remaining
+	}
+
+	return timeout
+}
+
 // Initialize initializes this driver from the provided config.
 func (d *NASStorageDriver) Initialize(
 	ctx context.Context, context tridentconfig.DriverContext, configJSON string,
@@ -212,12 +432,65 @@ func (d *NASStorageDriver) Initialize(
 	}
 	d.volumeCreateTimeout = volumeCreateTimeout
 
+	volumeCreateConflictRetries := defaultVolumeCreateConflictRetries
+	if config.VolumeCreateConflictRetries != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.VolumeCreateConflictRetries, 10, 8); parseErr != nil {
+			Logc(ctx).WithField("retries", d.Config.VolumeCreateConflictRetries).WithError(parseErr).Error(
+				"Invalid volume create retries.")
+			return parseErr
+		} else {
+			volumeCreateConflictRetries = int(i)
+		}
+	}
+	d.volumeCreateConflictRetries = volumeCreateConflictRetries
+	d.volumeCreateConflictRetryDelay = api.SDKRetryDelay
+
+	parallelCreatePools := 1
+	if config.ParallelCreatePools != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.ParallelCreatePools, 10, 8); parseErr != nil || i == 0 {
+			Logc(ctx).WithField("pools", d.Config.ParallelCreatePools).WithError(parseErr).Error(
+				"Invalid number of parallel create pools.")
+			return fmt.Errorf("invalid value for parallelCreatePools: %s", d.Config.ParallelCreatePools)
+		}
+		parallelCreatePools = int(i)
+	}
+	d.parallelCreatePools = parallelCreatePools
+
+	var cloneSnapshotReuseAge time.Duration
+	if config.CloneSnapshotReuseAge != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.CloneSnapshotReuseAge, 10, 32); parseErr != nil {
+			Logc(ctx).WithField("age", d.Config.CloneSnapshotReuseAge).WithError(parseErr).Error(
+				"Invalid clone snapshot reuse age.")
+			return fmt.Errorf("invalid value for cloneSnapshotReuseAge: %s", d.Config.CloneSnapshotReuseAge)
+		} else {
+			cloneSnapshotReuseAge = time.Duration(i) * time.Second
+		}
+	}
+	d.cloneSnapshotReuseAge = cloneSnapshotReuseAge
+
+	failedVolumeCleanupAge := defaultFailedVolumeCleanupAge
+	if config.FailedVolumeCleanupAge != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.FailedVolumeCleanupAge, 10, 32); parseErr != nil || i == 0 {
+			Logc(ctx).WithField("age", d.Config.FailedVolumeCleanupAge).WithError(parseErr).Error(
+				"Invalid failed volume cleanup age.")
+			return fmt.Errorf("invalid value for failedVolumeCleanupAge: %s", d.Config.FailedVolumeCleanupAge)
+		} else {
+			failedVolumeCleanupAge = time.Duration(i) * time.Second
+		}
+	}
+	d.failedVolumeCleanupAge = failedVolumeCleanupAge
+
+	if config.BackgroundCacheRefresh {
+		d.startCacheRefresh(ctx)
+	}
+
 	Logc(ctx).WithFields(LogFields{
 		"StoragePrefix":              *config.StoragePrefix,
 		"Size":                       config.Size,
 		"ServiceLevel":               config.ServiceLevel,
 		"NfsMountOptions":            config.NfsMountOptions,
 		"LimitVolumeSize":            config.LimitVolumeSize,
+		"MinimumVolumeSize":          config.MinimumVolumeSize,
 		"ExportRule":                 config.ExportRule,
 		"VolumeCreateTimeoutSeconds": config.VolumeCreateTimeout,
 	})
@@ -237,9 +510,96 @@ func (d *NASStorageDriver) Terminate(ctx context.Context, _ string) {
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Terminate")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Terminate")
 
+	d.stopCacheRefresh()
+	d.stopPendingVolumePoller()
+
 	d.initialized = false
 }
 
+// refreshAzureResources refreshes the Azure resource cache if it is stale.  If the cache is refreshed in the
+// background, operations use the cached resources as they are instead of waiting for a refresh, unless the
+// cache was never populated.
+func (d *NASStorageDriver) refreshAzureResources(ctx context.Context) error {
+	if !d.Config.BackgroundCacheRefresh {
+		if err := d.SDK.RefreshAzureResources(ctx); err != nil {
+			return err
+		}
+	} else if d.SDK.CacheStatus().LastUpdateTime.IsZero() {
+		return errors.New("Azure resources have not been discovered yet; waiting for background cache refresh")
+	}
+	return d.validateAfterInitialDiscovery(ctx)
+}
+
+// validateAfterInitialDiscovery checks the storage pools against the discovered Azure resources if that
+// check could not be done during initialization because the resources could not be discovered then.
+func (d *NASStorageDriver) validateAfterInitialDiscovery(ctx context.Context) error {
+	d.initialDiscoveryLock.Lock()
+	defer d.initialDiscoveryLock.Unlock()
+
+	if !d.initialDiscoveryFailed {
+		return nil
+	}
+	if err := d.validateDiscoveredResources(ctx); err != nil {
+		return fmt.Errorf("backend config does not match the discovered Azure resources; %v", err)
+	}
+
+	Logc(ctx).Debug("Validated storage pools against the discovered Azure resources.")
+	d.initialDiscoveryFailed = false
+	return nil
+}
+
+// startCacheRefresh starts a goroutine that refreshes the Azure resource cache whenever it becomes stale.
+func (d *NASStorageDriver) startCacheRefresh(ctx context.Context) {
+	if d.cacheRefreshDone != nil {
+		return
+	}
+
+	// Check often enough that the cache is refreshed soon after reaching the maximum cache age
+	interval := d.SDK.CacheStatus().MaxCacheAge / 2
+	if interval > maxCacheRefreshCheckInterval {
+		interval = maxCacheRefreshCheckInterval
+	} else if interval < minCacheRefreshCheckInterval {
+		interval = minCacheRefreshCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	d.cacheRefreshDone = done
+
+	// The refresh outlives the request that started it, so it must not be cancelled along with that request
+	refreshCtx := context.WithoutCancel(ctx)
+
+	Logc(ctx).WithField("interval", interval).Debug("Starting background refresh of Azure resource cache.")
+
+	d.cacheRefreshWaitGroup.Add(1)
+	go func() {
+		defer d.cacheRefreshWaitGroup.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.SDK.RefreshAzureResources(refreshCtx); err != nil {
+					Logc(refreshCtx).WithError(err).Warning("Background refresh of Azure resource cache failed.")
+				}
+			case <-done:
+				Logc(refreshCtx).WithField("driver", d.Name()).Debug("Stopped background refresh of Azure resource cache.")
+				return
+			}
+		}
+	}()
+}
+
+// stopCacheRefresh stops the background refresh of the Azure resource cache, if it is running, and waits
+// for any refresh in progress to finish.
+func (d *NASStorageDriver) stopCacheRefresh() {
+	if d.cacheRefreshDone == nil {
+		return
+	}
+	close(d.cacheRefreshDone)
+	d.cacheRefreshDone = nil
+	d.cacheRefreshWaitGroup.Wait()
+}
+
 // populateConfigurationDefaults fills in default values for configuration settings if not supplied in the config.
 func (d *NASStorageDriver) populateConfigurationDefaults(
 	ctx context.Context, config *drivers.AzureNASStorageDriverConfig,
@@ -264,23 +624,66 @@ func (d *NASStorageDriver) populateConfigurationDefaults(
 		config.UnixPermissions = defaultUnixPermissions
 	}
 
-	if config.NfsMountOptions == "" {
+	if config.DefaultUnixPermissionsMode == "" {
+		config.DefaultUnixPermissionsMode = defaultUnixPermissionsMode
+	}
+
+	if config.FailedVolumeCleanup == "" {
+		config.FailedVolumeCleanup = defaultFailedVolumeCleanup
+	}
+
+	if config.ExportRuleValidation == "" {
+		config.ExportRuleValidation = defaultExportRuleValidation
+	}
+
+	if config.KerberosNfsVersion == "" {
+		config.KerberosNfsVersion = defaultKerberosNfsVersion
+	}
+
+	// NFS mount options don't apply to SMB volumes, so SMB backends are left without any
+	if config.NfsMountOptions == "" && config.NASType != sa.SMB {
 		if config.Kerberos != "" {
-			config.NfsMountOptions = defaultKerberosNfsMountOptions
+			config.NfsMountOptions = "nfsvers=" + config.KerberosNfsVersion
 		} else {
 			config.NfsMountOptions = defaultNfsMountOptions
 		}
 	}
 
+	d.snapshotDirConfigured = config.SnapshotDir != ""
 	if config.SnapshotDir == "" {
 		config.SnapshotDir = defaultSnapshotDir
 	}
 
+	if config.CoolAccess == "" {
+		config.CoolAccess = defaultCoolAccess
+	}
+
+	if config.SplitOnClone == "" {
+		config.SplitOnClone = defaultSplitOnClone
+	}
+
+	if config.BackupEnabled == "" {
+		config.BackupEnabled = defaultBackupEnabled
+	}
+
+	if config.CoolnessPeriod == "" {
+		config.CoolnessPeriod = defaultCoolnessPeriod
+	}
+
 	if config.LimitVolumeSize == "" {
 		config.LimitVolumeSize = defaultLimitVolumeSize
 	}
 
-	if config.ExportRule == "" {
+	if config.NASType == "" {
+		config.NASType = sa.NFS
+	}
+
+	if config.Cloud == "" {
+		config.Cloud = api.CloudAzurePublic
+	}
+
+	// Export rules only apply to NFS and dual-protocol volumes
+	if config.ExportRule == "" && (config.NASType == sa.NFS || config.NASType == NASTypeDual) {
 		config.ExportRule = defaultExportRule
 	}
 
@@ -288,8 +691,8 @@ func (d *NASStorageDriver) populateConfigurationDefaults(
 		config.NetworkFeatures = defaultNetworkFeatures
 	}
 
-	if config.NASType == "" {
-		config.NASType = sa.NFS
+	if config.AutoExportPolicy && len(config.AutoExportCIDRs) == 0 {
+		config.AutoExportCIDRs = []string{"0.0.0.0/0", "::/0"}
 	}
 
 	Logc(ctx).WithFields(LogFields{
@@ -301,6 +704,9 @@ func (d *NASStorageDriver) populateConfigurationDefaults(
 		"SnapshotDir":     config.SnapshotDir,
 		"LimitVolumeSize": config.LimitVolumeSize,
 		"ExportRule":      config.ExportRule,
+		"CoolAccess":      config.CoolAccess,
+		"CoolnessPeriod":  config.CoolnessPeriod,
+		"SplitOnClone":    config.SplitOnClone,
 	}).Debugf("Configuration defaults")
 
 	return
@@ -308,7 +714,7 @@ func (d *NASStorageDriver) populateConfigurationDefaults(
 
 // initializeStoragePools defines the pools reported to Trident, whether physical or virtual.
 func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
-	d.pools = make(map[string]storage.Pool)
+	pools := make(map[string]storage.Pool)
 
 	if len(d.Config.Storage) == 0 {
 
@@ -321,7 +727,7 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 		pool.Attributes()[sa.Snapshots] = sa.NewBoolOffer(true)
 		pool.Attributes()[sa.Clones] = sa.NewBoolOffer(true)
 		pool.Attributes()[sa.Encryption] = sa.NewBoolOffer(false)
-		pool.Attributes()[sa.Replication] = sa.NewBoolOffer(false)
+		pool.Attributes()[sa.Replication] = sa.NewBoolOffer(d.Config.ReplicationSchedule != "")
 		pool.Attributes()[sa.Labels] = sa.NewLabelOffer(d.Config.Labels)
 		pool.Attributes()[sa.NASType] = sa.NewStringOffer(d.Config.NASType)
 
@@ -337,6 +743,7 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 		pool.InternalAttributes()[ServiceLevel] = utils.Title(d.Config.ServiceLevel)
 		pool.InternalAttributes()[SnapshotDir] = d.Config.SnapshotDir
 		pool.InternalAttributes()[ExportRule] = d.Config.ExportRule
+		pool.InternalAttributes()[ExportRules] = encodeExportRules(d.Config.ExportRules)
 		pool.InternalAttributes()[VirtualNetwork] = d.Config.VirtualNetwork
 		pool.InternalAttributes()[NetworkFeatures] = d.Config.NetworkFeatures
 		pool.InternalAttributes()[Subnet] = d.Config.Subnet
@@ -344,10 +751,26 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 		pool.InternalAttributes()[NetappAccounts] = strings.Join(d.Config.NetappAccounts, ",")
 		pool.InternalAttributes()[CapacityPools] = strings.Join(d.Config.CapacityPools, ",")
 		pool.InternalAttributes()[Kerberos] = d.Config.Kerberos
+		pool.InternalAttributes()[BackupPolicy] = d.Config.BackupPolicy
+		pool.InternalAttributes()[BackupEnabled] = d.Config.BackupEnabled
+		pool.InternalAttributes()[LDAPEnabled] = d.Config.LDAPEnabled
+		pool.InternalAttributes()[HasRootAccess] = d.Config.HasRootAccess
+		pool.InternalAttributes()[ChownMode] = d.Config.ChownMode
+		pool.InternalAttributes()[SnapshotReserve] = d.Config.SnapshotReserve
+		pool.InternalAttributes()[Throughput] = d.Config.Throughput
+		pool.InternalAttributes()[CoolAccess] = d.Config.CoolAccess
+		pool.InternalAttributes()[CoolnessPeriod] = d.Config.CoolnessPeriod
+		pool.InternalAttributes()[CoolAccessRetrievalPolicy] = d.Config.CoolAccessRetrievalPolicy
+		pool.InternalAttributes()[SnapshotPolicy] = d.Config.SnapshotPolicy
+		pool.InternalAttributes()[SubnetSelection] = d.Config.SubnetSelection
+		pool.InternalAttributes()[SplitOnClone] = d.Config.SplitOnClone
+		pool.InternalAttributes()[SMBContinuousAvailability] = d.Config.SMBContinuousAvailability
+		pool.InternalAttributes()[SMBEncryption] = d.Config.SMBEncryption
+		pool.InternalAttributes()[LargeVolume] = d.Config.LargeVolume
 
 		pool.SetSupportedTopologies(d.Config.SupportedTopologies)
 
-		d.pools[pool.Name()] = pool
+		pools[pool.Name()] = pool
 	} else {
 
 		Logc(ctx).Debug("One or more vpools defined.")
@@ -410,6 +833,11 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 				exportRule = vpool.ExportRule
 			}
 
+			exportRules := d.Config.ExportRules
+			if len(vpool.ExportRules) > 0 {
+				exportRules = vpool.ExportRules
+			}
+
 			vnet := d.Config.VirtualNetwork
 			if vpool.VirtualNetwork != "" {
 				vnet = vpool.VirtualNetwork
@@ -430,13 +858,93 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 				kerberos = vpool.Kerberos
 			}
 
+			backupPolicy := d.Config.BackupPolicy
+			if vpool.BackupPolicy != "" {
+				backupPolicy = vpool.BackupPolicy
+			}
+
+			backupEnabled := d.Config.BackupEnabled
+			if vpool.BackupEnabled != "" {
+				backupEnabled = vpool.BackupEnabled
+			}
+
+			ldapEnabled := d.Config.LDAPEnabled
+			if vpool.LDAPEnabled != "" {
+				ldapEnabled = vpool.LDAPEnabled
+			}
+
+			hasRootAccess := d.Config.HasRootAccess
+			if vpool.HasRootAccess != "" {
+				hasRootAccess = vpool.HasRootAccess
+			}
+
+			chownMode := d.Config.ChownMode
+			if vpool.ChownMode != "" {
+				chownMode = vpool.ChownMode
+			}
+
+			snapshotReserve := d.Config.SnapshotReserve
+			if vpool.SnapshotReserve != "" {
+				snapshotReserve = vpool.SnapshotReserve
+			}
+
+			throughput := d.Config.Throughput
+			if vpool.Throughput != "" {
+				throughput = vpool.Throughput
+			}
+
+			coolAccess := d.Config.CoolAccess
+			if vpool.CoolAccess != "" {
+				coolAccess = vpool.CoolAccess
+			}
+
+			coolnessPeriod := d.Config.CoolnessPeriod
+			if vpool.CoolnessPeriod != "" {
+				coolnessPeriod = vpool.CoolnessPeriod
+			}
+
+			coolAccessRetrievalPolicy := d.Config.CoolAccessRetrievalPolicy
+			if vpool.CoolAccessRetrievalPolicy != "" {
+				coolAccessRetrievalPolicy = vpool.CoolAccessRetrievalPolicy
+			}
+
+			snapshotPolicy := d.Config.SnapshotPolicy
+			if vpool.SnapshotPolicy != "" {
+				snapshotPolicy = vpool.SnapshotPolicy
+			}
+
+			subnetSelection := d.Config.SubnetSelection
+			if vpool.SubnetSelection != "" {
+				subnetSelection = vpool.SubnetSelection
+			}
+
+			splitOnClone := d.Config.SplitOnClone
+			if vpool.SplitOnClone != "" {
+				splitOnClone = vpool.SplitOnClone
+			}
+
+			smbContinuousAvailability := d.Config.SMBContinuousAvailability
+			if vpool.SMBContinuousAvailability != "" {
+				smbContinuousAvailability = vpool.SMBContinuousAvailability
+			}
+
+			smbEncryption := d.Config.SMBEncryption
+			if vpool.SMBEncryption != "" {
+				smbEncryption = vpool.SMBEncryption
+			}
+
+			largeVolume := d.Config.LargeVolume
+			if vpool.LargeVolume != "" {
+				largeVolume = vpool.LargeVolume
+			}
+
 			pool := storage.NewStoragePool(nil, d.poolName(fmt.Sprintf("pool_%d", index)))
 
 			pool.Attributes()[sa.BackendType] = sa.NewStringOffer(d.Name())
 			pool.Attributes()[sa.Snapshots] = sa.NewBoolOffer(true)
 			pool.Attributes()[sa.Clones] = sa.NewBoolOffer(true)
 			pool.Attributes()[sa.Encryption] = sa.NewBoolOffer(false)
-			pool.Attributes()[sa.Replication] = sa.NewBoolOffer(false)
+			pool.Attributes()[sa.Replication] = sa.NewBoolOffer(d.Config.ReplicationSchedule != "")
 			pool.Attributes()[sa.Labels] = sa.NewLabelOffer(d.Config.Labels, vpool.Labels)
 
 			nasType := d.Config.NASType
@@ -458,6 +966,7 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 			pool.InternalAttributes()[ServiceLevel] = utils.Title(serviceLevel)
 			pool.InternalAttributes()[SnapshotDir] = snapshotDir
 			pool.InternalAttributes()[ExportRule] = exportRule
+			pool.InternalAttributes()[ExportRules] = encodeExportRules(exportRules)
 			pool.InternalAttributes()[VirtualNetwork] = vnet
 			pool.InternalAttributes()[NetworkFeatures] = networkFeatures
 			pool.InternalAttributes()[Subnet] = subnet
@@ -465,14 +974,60 @@ func (d *NASStorageDriver) initializeStoragePools(ctx context.Context) {
 			pool.InternalAttributes()[NetappAccounts] = strings.Join(netappAccounts, ",")
 			pool.InternalAttributes()[CapacityPools] = strings.Join(capacityPools, ",")
 			pool.InternalAttributes()[Kerberos] = kerberos
+			pool.InternalAttributes()[BackupPolicy] = backupPolicy
+			pool.InternalAttributes()[BackupEnabled] = backupEnabled
+			pool.InternalAttributes()[LDAPEnabled] = ldapEnabled
+			pool.InternalAttributes()[HasRootAccess] = hasRootAccess
+			pool.InternalAttributes()[ChownMode] = chownMode
+			pool.InternalAttributes()[SnapshotReserve] = snapshotReserve
+			pool.InternalAttributes()[Throughput] = throughput
+			pool.InternalAttributes()[CoolAccess] = coolAccess
+			pool.InternalAttributes()[CoolnessPeriod] = coolnessPeriod
+			pool.InternalAttributes()[CoolAccessRetrievalPolicy] = coolAccessRetrievalPolicy
+			pool.InternalAttributes()[SnapshotPolicy] = snapshotPolicy
+			pool.InternalAttributes()[SubnetSelection] = subnetSelection
+			pool.InternalAttributes()[SplitOnClone] = splitOnClone
+			pool.InternalAttributes()[SMBContinuousAvailability] = smbContinuousAvailability
+			pool.InternalAttributes()[SMBEncryption] = smbEncryption
+			pool.InternalAttributes()[LargeVolume] = largeVolume
 
 			pool.SetSupportedTopologies(supportedTopologies)
 
-			d.pools[pool.Name()] = pool
+			pools[pool.Name()] = pool
 		}
 	}
 
-	return
+	d.setPools(pools)
+}
+
+// getPool returns the storage pool with the specified name, if it exists.
+func (d *NASStorageDriver) getPool(name string) (storage.Pool, bool) {
+	d.poolsLock.RLock()
+	defer d.poolsLock.RUnlock()
+
+	pool, ok := d.pools[name]
+	return pool, ok
+}
+
+// getPools returns a copy of the storage pools map, so that callers may range over the pools while they
+// are being replaced.
+func (d *NASStorageDriver) getPools() map[string]storage.Pool {
+	d.poolsLock.RLock()
+	defer d.poolsLock.RUnlock()
+
+	pools := make(map[string]storage.Pool, len(d.pools))
+	for name, pool := range d.pools {
+		pools[name] = pool
+	}
+	return pools
+}
+
+// setPools replaces the storage pools.
+func (d *NASStorageDriver) setPools(pools map[string]storage.Pool) {
+	d.poolsLock.Lock()
+	defer d.poolsLock.Unlock()
+
+	d.pools = pools
 }
 
 // initializeTelemetry assembles all the telemetry data to be used as volume labels.
@@ -525,29 +1080,109 @@ func (d *NASStorageDriver) initializeAzureSDKClient(
 	defer Logd(ctx, config.StorageDriverName,
 		config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< initializeAzureSDKClient")
 
+	clientConfig, err := d.newClientConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err = resolveAzureAuthConfig(ctx, d.credentialProviders, config, clientConfig); err != nil {
+		return err
+	}
+
+	// Set SubscriptionID, which may have come from the credential file
+	d.Config.SubscriptionID = clientConfig.SubscriptionID
+
+	client, err := api.NewDriver(*clientConfig)
+	if err != nil {
+		return err
+	}
+
+	// Unit tests mock the API layer, so we only use the real API interface if it doesn't already exist.
+	if d.SDK == nil {
+		d.SDK = client
+	}
+
+	// The storage pools should already be set up by this point. We register the pools with the
+	// API layer to enable matching of storage pools with discovered ANF resources.  This also discovers
+	// the Azure resources, so the cache is warm for the first driver operation.  A discovery that failed
+	// only because Azure could not be reached isn't fatal, since the next operation that needs the cache
+	// tries again, but any other failure points to a problem with the config or the credentials.
+	if err = d.SDK.Init(ctx, d.getPools()); err != nil {
+		if !api.IsUnavailableError(err) {
+			return err
+		}
+		Logc(ctx).WithError(err).Warning("Could not discover Azure resources during initialization; " +
+			"discovery will be retried by the next driver operation.")
+		d.initialDiscoveryFailed = true
+	}
+
+	return nil
+}
+
+// newClientConfig builds the API client config from the backend config, apart from the authentication
+// parameters, which are left to the credential providers.
+func (d *NASStorageDriver) newClientConfig(
+	ctx context.Context, config *drivers.AzureNASStorageDriverConfig,
+) (*api.ClientConfig, error) {
 	sdkTimeout := api.DefaultSDKTimeout
 	if config.SDKTimeout != "" {
 		if i, parseErr := strconv.ParseUint(d.Config.SDKTimeout, 10, 64); parseErr != nil {
 			Logc(ctx).WithField("interval", d.Config.SDKTimeout).WithError(parseErr).Error(
 				"Invalid value for SDK timeout.")
-			return parseErr
+			return nil, parseErr
 		} else {
 			sdkTimeout = time.Duration(i) * time.Second
 		}
 	}
 
+	// No retries at all is -1 to the SDK, which treats 0 as its default
+	var sdkMaxRetries int32
+	if config.SDKMaxRetries != "" {
+		if i, parseErr := strconv.ParseUint(config.SDKMaxRetries, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("retries", config.SDKMaxRetries).WithError(parseErr).Error(
+				"Invalid value for SDK max retries.")
+			return nil, parseErr
+		} else if i == 0 {
+			sdkMaxRetries = -1
+		} else {
+			sdkMaxRetries = int32(i)
+		}
+	}
+
+	var sdkRetryBackoff time.Duration
+	if config.SDKRetryBackoff != "" {
+		if i, parseErr := strconv.ParseUint(config.SDKRetryBackoff, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", config.SDKRetryBackoff).WithError(parseErr).Error(
+				"Invalid value for SDK retry backoff.")
+			return nil, parseErr
+		} else {
+			sdkRetryBackoff = time.Duration(i) * time.Second
+		}
+	}
+
+	var sdkRetryTimeout time.Duration
+	if config.SDKRetryTimeout != "" {
+		if i, parseErr := strconv.ParseUint(config.SDKRetryTimeout, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", config.SDKRetryTimeout).WithError(parseErr).Error(
+				"Invalid value for SDK retry timeout.")
+			return nil, parseErr
+		} else {
+			sdkRetryTimeout = time.Duration(i) * time.Second
+		}
+	}
+
 	maxCacheAge := api.DefaultMaxCacheAge
 	if config.MaxCacheAge != "" {
 		if i, parseErr := strconv.ParseUint(d.Config.MaxCacheAge, 10, 64); parseErr != nil {
 			Logc(ctx).WithField("interval", d.Config.MaxCacheAge).WithError(parseErr).Error(
 				"Invalid value for max cache age.")
-			return parseErr
+			return nil, parseErr
 		} else {
 			maxCacheAge = time.Duration(i) * time.Second
 		}
 	}
 
-	clientConfig := api.ClientConfig{
+	return &api.ClientConfig{
 		SubscriptionID: config.SubscriptionID,
 		AzureAuthConfig: azclient.AzureAuthConfig{
 			TenantID:        config.TenantID,
@@ -556,42 +1191,15 @@ func (d *NASStorageDriver) initializeAzureSDKClient(
 		},
 		Location:          config.Location,
 		StorageDriverName: config.StorageDriverName,
+		CloudName:         config.Cloud,
 		DebugTraceFlags:   config.DebugTraceFlags,
 		SDKTimeout:        sdkTimeout,
+		SDKMaxRetries:     sdkMaxRetries,
+		SDKRetryBackoff:   sdkRetryBackoff,
+		SDKRetryTimeout:   sdkRetryTimeout,
+		DisableSDKMetrics: config.DisableSDKMetrics,
 		MaxCacheAge:       maxCacheAge,
-	}
-
-	if config.ClientSecret == "" && config.ClientID == "" {
-		credFilePath := os.Getenv("AZURE_CREDENTIAL_FILE")
-		if credFilePath == "" {
-			credFilePath = DefaultConfigurationFilePath
-		}
-		Logc(ctx).WithField("credFilePath", credFilePath).Info("Using Azure credential config file.")
-		credFile, err := os.ReadFile(credFilePath)
-		if err != nil {
-			return errors.New("error reading from azure config file: " + err.Error())
-		}
-		if err = json.Unmarshal(credFile, &clientConfig); err != nil {
-			return errors.New("error parsing azureAuthConfig: " + err.Error())
-		}
-
-		// Set SubscriptionID
-		d.Config.SubscriptionID = clientConfig.SubscriptionID
-	}
-
-	client, err := api.NewDriver(clientConfig)
-	if err != nil {
-		return err
-	}
-
-	// Unit tests mock the API layer, so we only use the real API interface if it doesn't already exist.
-	if d.SDK == nil {
-		d.SDK = client
-	}
-
-	// The storage pools should already be set up by this point. We register the pools with the
-	// API layer to enable matching of storage pools with discovered ANF resources.
-	return d.SDK.Init(ctx, d.pools)
+	}, nil
 }
 
 // validate ensures the driver configuration and execution environment are valid and working.
@@ -604,38 +1212,253 @@ func (d *NASStorageDriver) validate(ctx context.Context) error {
 	if err := validateStoragePrefix(*d.Config.StoragePrefix); err != nil {
 		return err
 	}
+	if d.hasUnscopedEmptyPrefix() {
+		Logc(ctx).Warning("The storage prefix is empty and the backend is not limited to any resource groups, " +
+			"NetApp accounts, or capacity pools, so every ANF volume in the subscription will be listed as " +
+			"belonging to this backend. Set a storage prefix that is unique to this backend.")
+	}
 
-	// Validate pool-level attributes
-	for poolName, pool := range d.pools {
-
-		// Validate service level (it is allowed to be blank)
-		serviceLevel := pool.InternalAttributes()[ServiceLevel]
-		switch serviceLevel {
-		case api.ServiceLevelStandard, api.ServiceLevelPremium, api.ServiceLevelUltra, "":
-			break
-		default:
-			return fmt.Errorf("invalid service level in pool %s: %s",
-				poolName, pool.InternalAttributes()[ServiceLevel])
+	// Ensure the minimum volume size is one ANF could create
+	if d.Config.MinimumVolumeSize != "" {
+		minimumBytesStr, err := utils.ConvertSizeToBytes(d.Config.MinimumVolumeSize)
+		if err != nil {
+			return fmt.Errorf("invalid value for minimumVolumeSize; %v", err)
 		}
-
-		// Validate export rules
-		for _, rule := range strings.Split(pool.InternalAttributes()[ExportRule], ",") {
-			ipAddr := net.ParseIP(rule)
-			_, netAddr, _ := net.ParseCIDR(rule)
-			if ipAddr == nil && netAddr == nil {
-				return fmt.Errorf("invalid address/CIDR for exportRule in pool %s: %s", poolName, rule)
-			}
+		minimumBytes, err := strconv.ParseUint(minimumBytesStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for minimumVolumeSize; %v", err)
 		}
+		if minimumBytes < MinimumVolumeSizeBytes {
+			return fmt.Errorf("minimumVolumeSize %s is less than the smallest allowed volume size of %d bytes",
+				d.Config.MinimumVolumeSize, MinimumVolumeSizeBytes)
+		}
+	}
 
-		// Validate snapshot dir
-		if pool.InternalAttributes()[SnapshotDir] != "" {
-			_, err := strconv.ParseBool(pool.InternalAttributes()[SnapshotDir])
-			if err != nil {
-				return fmt.Errorf("invalid value for snapshotDir in pool %s; %v", poolName, err)
-			}
+	// Ensure the volume size limit leaves room for the smallest volume, since smaller requests are
+	// increased to that size before the limit is checked
+	if d.Config.LimitVolumeSize != "" {
+		limitBytesStr, err := utils.ConvertSizeToBytes(d.Config.LimitVolumeSize)
+		if err != nil {
+			return fmt.Errorf("invalid value for limitVolumeSize; %v", err)
+		}
+		limitBytes, _ := strconv.ParseUint(limitBytesStr, 10, 64)
+		if minimumBytes := d.minimumVolumeSize(); limitBytes < minimumBytes {
+			return fmt.Errorf("limitVolumeSize %s is less than the minimum volume size of %d bytes, "+
+				"so no volume could be created", d.Config.LimitVolumeSize, minimumBytes)
 		}
+	}
 
-		// Validate unix permissions
+	// Ensure config has a set of valid autoExportCIDRs
+	if d.Config.AutoExportPolicy {
+		if err := utils.ValidateCIDRs(ctx, d.Config.AutoExportCIDRs); err != nil {
+			return fmt.Errorf("failed to validate auto-export CIDR(s): %w", err)
+		}
+		if d.Config.DriverContext == tridentconfig.ContextDocker {
+			Logc(ctx).Warning("Auto export policy is not supported in Docker, using the configured export rules.")
+		}
+	}
+
+	// Validate the default permissions behavior
+	switch d.Config.DefaultUnixPermissionsMode {
+	case UnixPermissionsModeFeatureGated, UnixPermissionsModeEmpty:
+		if d.Config.DefaultUnixPermissions != "" {
+			return fmt.Errorf("defaultUnixPermissions may only be set when defaultUnixPermissionsMode is %s",
+				UnixPermissionsModeExplicit)
+		}
+	case UnixPermissionsModeExplicit:
+		if d.Config.DefaultUnixPermissions == "" {
+			return fmt.Errorf("defaultUnixPermissions must be set when defaultUnixPermissionsMode is %s",
+				UnixPermissionsModeExplicit)
+		}
+		if err := utils.ValidateOctalUnixPermissions(d.Config.DefaultUnixPermissions); err != nil {
+			return fmt.Errorf("invalid value for defaultUnixPermissions; %v", err)
+		}
+	default:
+		return fmt.Errorf("invalid value for defaultUnixPermissionsMode: %s", d.Config.DefaultUnixPermissionsMode)
+	}
+
+	// Validate the failed volume cleanup behavior
+	switch d.Config.FailedVolumeCleanup {
+	case FailedVolumeCleanupDisabled, FailedVolumeCleanupDryRun, FailedVolumeCleanupDelete:
+	default:
+		return fmt.Errorf("invalid value for failedVolumeCleanup: %s", d.Config.FailedVolumeCleanup)
+	}
+
+	// Validate the Azure environment
+	if _, err := api.CloudConfiguration(d.Config.Cloud); err != nil {
+		return fmt.Errorf("invalid value for cloud; %v", err)
+	}
+
+	// Validate the NFS version of Kerberos volumes
+	if !utils.SliceContainsString(kerberosNFSVersions, d.Config.KerberosNfsVersion) {
+		return fmt.Errorf("invalid value for kerberosNfsVersion: %s; Kerberos requires NFS version %s",
+			d.Config.KerberosNfsVersion, strings.Join(kerberosNFSVersions, " or "))
+	}
+
+	// Validate the NAS type
+	switch d.Config.NASType {
+	case sa.NFS, sa.SMB, NASTypeDual:
+		break
+	default:
+		return fmt.Errorf("invalid value for nasType: %s", d.Config.NASType)
+	}
+
+	// Validate that the mount options suit the NAS type
+	if d.Config.NASType == sa.SMB {
+		if d.Config.NfsMountOptions != "" {
+			return fmt.Errorf("invalid value for nfsMountOptions: %s; NFS mount options may not be set when "+
+				"nasType is %s", d.Config.NfsMountOptions, sa.SMB)
+		}
+	} else if option := matchingMountOption(d.Config.NfsMountOptions, smbMountOptionRegex); option != "" {
+		return fmt.Errorf("invalid value for nfsMountOptions: %s; %s is an SMB mount option",
+			d.Config.NfsMountOptions, option)
+	} else if _, err := nfsVersionFromMountOptions(d.Config.NfsMountOptions, nfsVersion3); err != nil {
+		return fmt.Errorf("invalid value for nfsMountOptions: %s; %v", d.Config.NfsMountOptions, err)
+	}
+
+	// Validate the export rule validation mode
+	switch d.Config.ExportRuleValidation {
+	case ExportRuleValidationLenient, ExportRuleValidationWarn, ExportRuleValidationReject:
+		break
+	default:
+		return fmt.Errorf("invalid value for exportRuleValidation: %s", d.Config.ExportRuleValidation)
+	}
+
+	// Validate replication schedule
+	switch d.Config.ReplicationSchedule {
+	case api.ReplicationSchedule10Minutely, api.ReplicationScheduleHourly, api.ReplicationScheduleDaily, "":
+		break
+	default:
+		return fmt.Errorf("invalid value for replicationSchedule: %s", d.Config.ReplicationSchedule)
+	}
+
+	// Validate namespace annotation to tag mappings
+	for annotation, tag := range d.Config.NamespaceAnnotationTags {
+		if err := validateTagKey(tag); err != nil {
+			return fmt.Errorf("invalid tag for namespace annotation %s; %v", annotation, err)
+		}
+	}
+
+	// Validate custom tags
+	for tag, value := range d.Config.CustomTags {
+		if err := validateTagKey(tag); err != nil {
+			return fmt.Errorf("invalid custom tag; %v", err)
+		}
+		if len(value) > api.MaxLabelLength {
+			return fmt.Errorf("value of custom tag %s exceeds the maximum length of %d", tag, api.MaxLabelLength)
+		}
+	}
+
+	// Validate the default service level, which must be offered by a discovered capacity pool
+	if d.Config.DefaultServiceLevel != "" {
+		if err := d.validateDefaultServiceLevel(); err != nil {
+			return err
+		}
+	} else if unconstrained := d.poolsWithoutServiceLevel(); len(unconstrained) > 0 {
+		Logc(ctx).WithField("pools", unconstrained).Warning(
+			"No service level or defaultServiceLevel is set for some storage pools; volumes in these pools " +
+				"may be placed in a capacity pool of any service level.")
+	}
+
+	// Validate pool-level attributes
+	for poolName, pool := range d.getPools() {
+
+		// Validate service level (it is allowed to be blank)
+		serviceLevel := pool.InternalAttributes()[ServiceLevel]
+		switch serviceLevel {
+		case api.ServiceLevelStandard, api.ServiceLevelPremium, api.ServiceLevelUltra, "":
+			break
+		default:
+			return fmt.Errorf("invalid service level in pool %s: %s",
+				poolName, pool.InternalAttributes()[ServiceLevel])
+		}
+
+		// Validate export rules, which are meaningless for SMB volumes
+		if d.Config.NASType != sa.SMB {
+			for _, rule := range strings.Split(pool.InternalAttributes()[ExportRule], ",") {
+				ipAddr := net.ParseIP(rule)
+				_, netAddr, _ := net.ParseCIDR(rule)
+				if ipAddr == nil && netAddr == nil {
+					return fmt.Errorf("invalid address/CIDR for exportRule in pool %s: %s", poolName, rule)
+				}
+			}
+
+			exportRules, err := exportRulesFromPool(pool)
+			if err != nil {
+				return fmt.Errorf("invalid value for exportRules in pool %s; %v", poolName, err)
+			}
+			for i, rule := range exportRules {
+				if err = validateExportRule(rule); err != nil {
+					return fmt.Errorf("invalid export rule %d in pool %s; %v", i+1, poolName, err)
+				}
+			}
+
+			// Check for suspicious addresses in whichever export rules will be applied to new volumes
+			if len(exportRules) > 0 {
+				for i, rule := range exportRules {
+					for _, client := range strings.Split(rule.AllowedClients, ",") {
+						if err = d.checkExportRuleAddress(ctx, poolName, strings.TrimSpace(client)); err != nil {
+							return fmt.Errorf("invalid export rule %d in pool %s; %v", i+1, poolName, err)
+						}
+					}
+				}
+			} else {
+				for _, rule := range strings.Split(pool.InternalAttributes()[ExportRule], ",") {
+					if err = d.checkExportRuleAddress(ctx, poolName, rule); err != nil {
+						return fmt.Errorf("invalid address/CIDR for exportRule in pool %s; %v", poolName, err)
+					}
+				}
+			}
+		}
+
+		// Validate snapshot dir
+		if pool.InternalAttributes()[SnapshotDir] != "" {
+			_, err := strconv.ParseBool(pool.InternalAttributes()[SnapshotDir])
+			if err != nil {
+				return fmt.Errorf("invalid value for snapshotDir in pool %s; %v", poolName, err)
+			}
+		}
+
+		// Validate split on clone
+		if pool.InternalAttributes()[SplitOnClone] != "" {
+			if _, err := strconv.ParseBool(pool.InternalAttributes()[SplitOnClone]); err != nil {
+				return fmt.Errorf("invalid value for splitOnClone in pool %s; %v", poolName, err)
+			}
+		}
+
+		// Validate SMB share options, which only apply to SMB volumes
+		if continuousAvailability, encryption, err := smbOptionsFromPool(pool); err != nil {
+			return fmt.Errorf("invalid SMB share option in pool %s; %v", poolName, err)
+		} else if (continuousAvailability || encryption) && d.Config.NASType != sa.SMB {
+			return fmt.Errorf("smbContinuousAvailability and smbEncryption in pool %s are only supported for "+
+				"SMB volumes", poolName)
+		}
+
+		// Validate large volume setting, ensuring the volume size limit leaves room for the smallest large volume
+		if largeVolume, err := largeVolumeFromPool(pool, 0); err != nil {
+			return fmt.Errorf("invalid value for largeVolume in pool %s; %v", poolName, err)
+		} else if largeVolume && d.Config.LimitVolumeSize != "" {
+			limitBytesStr, _ := utils.ConvertSizeToBytes(d.Config.LimitVolumeSize)
+			if limitBytes, _ := strconv.ParseUint(limitBytesStr, 10, 64); limitBytes < MinimumANFLargeVolumeSizeBytes {
+				return fmt.Errorf("limitVolumeSize %s is less than the minimum large volume size of %d bytes, "+
+					"so no volume could be created in pool %s", d.Config.LimitVolumeSize,
+					MinimumANFLargeVolumeSizeBytes, poolName)
+			}
+		}
+
+		// Validate snapshot policy
+		if err := validateSnapshotPolicy(pool.InternalAttributes()[SnapshotPolicy]); err != nil {
+			return fmt.Errorf("invalid value for snapshotPolicy in pool %s; %v", poolName, err)
+		}
+
+		// Validate cool access settings, which are ignored for SMB volumes
+		if d.Config.NASType != sa.SMB {
+			if _, _, err := coolAccessFromPool(pool, serviceLevel); err != nil {
+				return fmt.Errorf("invalid cool access configuration in pool %s; %v", poolName, err)
+			}
+		}
+
+		// Validate unix permissions
 		if pool.InternalAttributes()[UnixPermissions] != "" {
 			err := utils.ValidateOctalUnixPermissions(pool.InternalAttributes()[UnixPermissions])
 			if err != nil {
@@ -648,11 +1471,87 @@ func (d *NASStorageDriver) validate(ctx context.Context) error {
 			return fmt.Errorf("invalid value for default volume size in pool %s; %v", poolName, err)
 		}
 
+		// Validate throughput, which is only allowed for manual QoS capacity pools
+		if pool.InternalAttributes()[Throughput] != "" {
+			if _, err := throughputFromPool(pool); err != nil {
+				return fmt.Errorf("invalid value for throughput in pool %s; %v", poolName, err)
+			}
+		}
+
+		// Validate backup settings
+		if backupEnabled, err := backupEnabledFromPool(pool); err != nil {
+			return fmt.Errorf("invalid value for backupEnabled in pool %s; %v", poolName, err)
+		} else if !backupEnabled && pool.InternalAttributes()[BackupPolicy] != "" {
+			return fmt.Errorf("invalid value for backupPolicy in pool %s; backups are not enabled", poolName)
+		}
+
+		// Validate snapshot reserve
+		if _, err := snapshotReserveFromPool(pool); err != nil {
+			return fmt.Errorf("invalid value for snapshotReserve in pool %s; %v", poolName, err)
+		}
+
+		// Validate LDAP, which is only offered for SMB, dual-protocol, and NFSv4.1 volumes in accounts joined
+		// to a domain
+		if ldapEnabled, err := ldapEnabledFromPool(pool); err != nil {
+			return fmt.Errorf("invalid value for ldapEnabled in pool %s; %v", poolName, err)
+		} else if ldapEnabled {
+			if d.Config.NASType == sa.NFS {
+				// Kerberos volumes use the Kerberos NFS version, else the mount options determine the version
+				nfsVersion := d.Config.KerberosNfsVersion
+				if pool.InternalAttributes()[Kerberos] == "" {
+					if nfsVersion, err = nfsVersionFromMountOptions(d.Config.NfsMountOptions, nfsVersion3); err != nil {
+						return err
+					}
+				}
+				if nfsProtocolType(nfsVersion) != api.ProtocolTypeNFSv41 {
+					return fmt.Errorf("invalid value for ldapEnabled in pool %s; LDAP is only supported for SMB, "+
+						"dual-protocol, and NFSv%s volumes", poolName, nfsVersion41)
+				}
+			}
+		}
+
+		// Validate NFSv4.1 ACL export options, which ANF offers only for NFSv4.1 volumes
+		hasRootAccess, err := hasRootAccessFromPool(pool)
+		if err != nil {
+			return fmt.Errorf("invalid value for hasRootAccess in pool %s; %v", poolName, err)
+		}
+		chownMode, err := chownModeFromPool(pool)
+		if err != nil {
+			return fmt.Errorf("invalid value for chownMode in pool %s; %v", poolName, err)
+		}
+		if hasRootAccess != nil || chownMode != "" {
+			if d.Config.NASType == sa.SMB {
+				return fmt.Errorf("hasRootAccess and chownMode in pool %s are only supported for NFSv%s volumes",
+					poolName, nfsVersion41)
+			}
+
+			// Kerberos volumes use the Kerberos NFS version, else the mount options determine the version
+			nfsVersion := d.Config.KerberosNfsVersion
+			if pool.InternalAttributes()[Kerberos] == "" {
+				if nfsVersion, err = nfsVersionFromMountOptions(d.Config.NfsMountOptions, nfsVersion3); err != nil {
+					return err
+				}
+			}
+			if nfsProtocolType(nfsVersion) != api.ProtocolTypeNFSv41 {
+				return fmt.Errorf("hasRootAccess and chownMode in pool %s are only supported for NFSv%s "+
+					"volumes", poolName, nfsVersion41)
+			}
+		}
+
 		// Validate pool labels
 		if _, err := pool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength); err != nil {
 			return fmt.Errorf("invalid value for label in pool %s; %v", poolName, err)
 		}
 
+		// Validate subnet selection mode
+		switch pool.InternalAttributes()[SubnetSelection] {
+		case "", api.SubnetSelectionRandom, api.SubnetSelectionFirst, api.SubnetSelectionCapacity:
+			break
+		default:
+			return fmt.Errorf("invalid value for subnetSelection in pool %s: %s",
+				poolName, pool.InternalAttributes()[SubnetSelection])
+		}
+
 		// Validate vnet features
 		switch pool.InternalAttributes()[NetworkFeatures] {
 		case "", api.NetworkFeaturesBasic, api.NetworkFeaturesStandard:
@@ -661,6 +1560,20 @@ func (d *NASStorageDriver) validate(ctx context.Context) error {
 			return fmt.Errorf("invalid value for networkFeatures in pool %s", poolName)
 		}
 
+		// Validate that NFS Kerberos is not configured for SMB or dual-protocol volumes
+		if d.Config.NASType != sa.NFS && pool.InternalAttributes()[Kerberos] != "" {
+			return fmt.Errorf("invalid value for kerberos in pool %s; kerberos is only supported for NFS volumes",
+				poolName)
+		}
+
+		// Validate that Kerberos mount options are only used with Kerberos volumes
+		if pool.InternalAttributes()[Kerberos] == "" {
+			if option := matchingMountOption(d.Config.NfsMountOptions, kerberosMountOptionRegex); option != "" {
+				return fmt.Errorf("invalid value for nfsMountOptions: %s; %s requires kerberos to be set in pool %s",
+					d.Config.NfsMountOptions, option, poolName)
+			}
+		}
+
 		if pool.InternalAttributes()[Kerberos] != "" {
 			if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
 				// Log a warning to avoid putting the backend into a failed state.
@@ -672,6 +1585,52 @@ func (d *NASStorageDriver) validate(ctx context.Context) error {
 		}
 	}
 
+	// The pools can only be checked against the discovered resources once those resources are known
+	d.initialDiscoveryLock.Lock()
+	defer d.initialDiscoveryLock.Unlock()
+
+	if d.initialDiscoveryFailed {
+		return nil
+	}
+	return d.validateDiscoveredResources(ctx)
+}
+
+// validateDiscoveredResources checks the storage pools against the discovered Azure resources, such as
+// whether any capacity pool can satisfy the throughput, LDAP, and Kerberos settings of each storage pool.
+func (d *NASStorageDriver) validateDiscoveredResources(ctx context.Context) error {
+	for poolName, pool := range d.pools {
+		ldapEnabled, _ := ldapEnabledFromPool(pool)
+		throughput := pool.InternalAttributes()[Throughput]
+		kerberos := pool.InternalAttributes()[Kerberos]
+		if throughput == "" && !ldapEnabled && kerberos == "" {
+			continue
+		}
+
+		cPools := d.SDK.CapacityPoolsForStoragePool(ctx, pool, pool.InternalAttributes()[ServiceLevel])
+
+		// Throughput is only allowed for manual QoS capacity pools
+		if throughput != "" {
+			for _, cPool := range cPools {
+				if !strings.EqualFold(cPool.QosType, api.QosTypeManual) {
+					return fmt.Errorf("invalid value for throughput in pool %s; capacity pool %s does not use "+
+						"manual QoS", poolName, cPool.FullName)
+				}
+			}
+		}
+
+		// LDAP volumes may only be created in accounts joined to a domain
+		if ldapEnabled && len(filterCapacityPoolsByActiveDirectory(cPools)) == 0 {
+			return fmt.Errorf("invalid value for ldapEnabled in pool %s; no capacity pools found in NetApp "+
+				"accounts with an Active Directory connection", poolName)
+		}
+
+		// Kerberos volumes may only be created in accounts whose AD connection has a Kerberos realm
+		if kerberos != "" && len(filterCapacityPoolsByKerberos(cPools)) == 0 {
+			return fmt.Errorf("invalid value for kerberos in pool %s; no capacity pools found in NetApp "+
+				"accounts with a Kerberos realm configured in their Active Directory connection", poolName)
+		}
+	}
+
 	return nil
 }
 
@@ -691,7 +1650,7 @@ func (d *NASStorageDriver) Create(
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Create")
 
 	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
+	if err := d.refreshAzureResources(ctx); err != nil {
 		return fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
@@ -709,11 +1668,18 @@ func (d *NASStorageDriver) Create(
 	if storagePool == nil {
 		return errors.New("pool not specified")
 	}
-	pool, ok := d.pools[storagePool.Name()]
+	pool, ok := d.getPool(storagePool.Name())
 	if !ok {
 		return fmt.Errorf("pool %s does not exist", storagePool.Name())
 	}
 
+	// NFS Kerberos has no meaning for SMB volumes, which have their own encryption mechanism, and isn't
+	// supported for dual-protocol volumes
+	if d.Config.NASType != sa.NFS && pool.InternalAttributes()[Kerberos] != "" {
+		return fmt.Errorf("kerberos option %s is only supported for NFS volumes, not SMB or dual-protocol",
+			pool.InternalAttributes()[Kerberos])
+	}
+
 	// Check if this volume landed on a Kerberos-enabled storage pool. If so, check if ACP allows it.
 	if pool.InternalAttributes()[Kerberos] != "" {
 		if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
@@ -735,6 +1701,11 @@ func (d *NASStorageDriver) Create(
 			return errors.VolumeCreatingError(
 				fmt.Sprintf("volume state is still %s, not %s", api.StateCreating, api.StateAvailable))
 		}
+		if d.isPendingVolume(name) {
+			// The background poller hasn't seen the volume become available or cleaned it up yet
+			return errors.VolumeCreatingError(fmt.Sprintf("volume state is %s, not %s",
+				extantVolume.ProvisioningState, api.StateAvailable))
+		}
 
 		Logc(ctx).WithFields(LogFields{
 			"name":  name,
@@ -744,42 +1715,25 @@ func (d *NASStorageDriver) Create(
 		return drivers.NewVolumeExistsError(name)
 	}
 
-	// Determine volume size in bytes
-	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
-	if err != nil {
-		return fmt.Errorf("could not convert volume size %s; %v", volConfig.Size, err)
-	}
-	sizeBytes, err := strconv.ParseUint(requestedSize, 10, 64)
+	sizeBytes, largeVolume, err := d.createVolumeSize(ctx, volConfig, pool)
 	if err != nil {
-		return fmt.Errorf("%v is an invalid volume size; %v", volConfig.Size, err)
-	}
-	if sizeBytes == 0 {
-		defaultSize, _ := utils.ConvertSizeToBytes(pool.InternalAttributes()[Size])
-		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
-	}
-	if err = drivers.CheckMinVolumeSize(sizeBytes, MinimumVolumeSizeBytes); err != nil {
 		return err
 	}
 
-	if sizeBytes < MinimumANFVolumeSizeBytes {
-
-		Logc(ctx).WithFields(LogFields{
-			"name": name,
-			"size": sizeBytes,
-		}).Warningf("Requested size is too small. Setting volume size to the minimum allowable (100 GB).")
-
-		sizeBytes = MinimumANFVolumeSizeBytes
-	}
-
-	if _, _, err = drivers.CheckVolumeSizeLimits(ctx, sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
-		return err
+	// Start the volume with the contents of an existing snapshot, if requested
+	if volConfig.RestoreFromSnapshot != "" {
+		return d.restoreSnapshotToNewVolume(ctx, volConfig, pool, sizeBytes)
 	}
 
-	// Take service level from volume config first (handles Docker case), then from pool
+	// Take service level from volume config first (handles Docker case), then from pool, then from the
+	// backend's default so that placement doesn't depend on whichever capacity pool happens to match
 	serviceLevel := utils.Title(volConfig.ServiceLevel)
 	if serviceLevel == "" {
 		serviceLevel = pool.InternalAttributes()[ServiceLevel]
 	}
+	if serviceLevel == "" {
+		serviceLevel = utils.Title(d.Config.DefaultServiceLevel)
+	}
 
 	// Take snapshot directory from volume config first (handles Docker case), then from pool
 	snapshotDir := volConfig.SnapshotDir
@@ -791,92 +1745,98 @@ func (d *NASStorageDriver) Create(
 		return fmt.Errorf("invalid value for snapshotDir; %v", err)
 	}
 
-	// Take unix permissions from volume config first (handles Docker case & PVC annotations), then from pool
-	unixPermissions := volConfig.UnixPermissions
-	if unixPermissions == "" {
-		unixPermissions = pool.InternalAttributes()[UnixPermissions]
+	// Take snapshot policy from volume config first (handles PVC annotations), then from pool
+	snapshotPolicy := volConfig.SnapshotPolicy
+	if snapshotPolicy == "" {
+		snapshotPolicy = pool.InternalAttributes()[SnapshotPolicy]
+	}
+	if err = validateSnapshotPolicy(snapshotPolicy); err != nil {
+		return err
 	}
 
-	// TODO (cknight): remove when preview permissions feature reaches GA
-	if unixPermissions == "" && d.SDK.HasFeature(api.FeatureUnixPermissions) {
-		unixPermissions = "0777"
+	// Take cool access settings from pool, ensuring they suit the requested service level.  Cool access
+	// isn't applicable to SMB volumes, so any such settings are ignored.
+	var coolAccess bool
+	var coolnessPeriod int32
+	if d.Config.NASType == sa.SMB {
+		if pool.InternalAttributes()[CoolAccess] != "" && pool.InternalAttributes()[CoolAccess] != defaultCoolAccess {
+			Logc(ctx).WithField("pool", pool.Name()).Debug("Ignoring cool access settings for SMB volume.")
+		}
+	} else if coolAccess, coolnessPeriod, err = coolAccessFromPool(pool, serviceLevel); err != nil {
+		return err
 	}
 
-	// Determine mount options (volume config wins, followed by backend config)
-	mountOptions := d.Config.NfsMountOptions
-	if volConfig.MountOptions != "" {
-		mountOptions = volConfig.MountOptions
+	// Take throughput from pool, which limits the volume to capacity pools that use manual QoS
+	throughput, err := throughputFromPool(pool)
+	if err != nil {
+		return err
 	}
 
-	// Take kerberos option from pool
-	kerberos := pool.InternalAttributes()[Kerberos]
+	// Take backup settings from pool, which allow snapshots to be backed up
+	backupEnabled, err := backupEnabledFromPool(pool)
+	if err != nil {
+		return err
+	}
 
-	// Determine protocol from mount options
-	var protocolTypes []string
-	var cifsAccess, nfsV3Access, nfsV41Access, kerberosEnabled bool
-	var apiExportRule api.ExportRule
-	var exportPolicy api.ExportPolicy
+	// Take LDAP setting from pool, which applies to SMB, dual-protocol, and NFSv4.1 volumes
+	ldapEnabled, err := ldapEnabledFromPool(pool)
+	if err != nil {
+		return err
+	}
 
-	switch kerberos {
-	case api.MountOptionKerberos5, api.MountOptionKerberos5I, api.MountOptionKerberos5P:
-		kerberosEnabled = true
-	case "":
-		kerberosEnabled = false
-	default:
-		return fmt.Errorf("unsupported kerberos type: %s", kerberos)
+	// Take SMB share options from pool, which only apply to SMB volumes
+	smbContinuousAvailability, smbEncryption, err := smbOptionsFromPool(pool)
+	if err != nil {
+		return err
 	}
 
-	if d.Config.NASType == sa.SMB {
-		protocolTypes = []string{api.ProtocolTypeCIFS}
-	} else {
-		nfsVersion, err := utils.GetNFSVersionFromMountOptions(mountOptions, nfsVersion3, supportedNFSVersions)
-		if err != nil {
-			return err
-		}
-		switch nfsVersion {
-		case nfsVersion3:
-			nfsV3Access = true
-			protocolTypes = []string{api.ProtocolTypeNFSv3}
-		case nfsVersion4:
-			fallthrough
-		case nfsVersion41:
-			nfsV41Access = true
-			protocolTypes = []string{api.ProtocolTypeNFSv41}
+	// ANF snapshots consume the volume's quota, so a snapshot reserve is set aside by enlarging the quota.
+	// Take the snapshot reserve from volume config first (handles PVC annotations), then from pool.
+	snapshotReserve, err := snapshotReserveFromPool(pool)
+	if err != nil {
+		return err
+	}
+	if volConfig.SnapshotReserve != "" {
+		if snapshotReserve, err = parseSnapshotReserve(volConfig.SnapshotReserve); err != nil {
+			return fmt.Errorf("invalid value for snapshotReserve; %v", err)
 		}
+	}
+	quotaBytes := quotaForSnapshotReserve(sizeBytes, snapshotReserve)
 
-		apiExportRule = api.ExportRule{
-			AllowedClients: pool.InternalAttributes()[ExportRule],
-			Cifs:           cifsAccess,
-			Nfsv3:          nfsV3Access,
-			Nfsv41:         nfsV41Access,
-			RuleIndex:      1,
-			UnixReadOnly:   false,
-			UnixReadWrite:  true,
+	// Mirror destinations are created as data protection volumes replicating from the peer volume
+	if volConfig.IsMirrorDestination {
+		if d.Config.ReplicationSchedule == "" {
+			return fmt.Errorf("could not create mirror destination volume %s; replication is not configured "+
+				"on backend %s", name, d.BackendName())
+		}
+		if volConfig.PeerVolumeHandle == "" {
+			return fmt.Errorf("could not create mirror destination volume %s; no peer volume specified", name)
 		}
+		if _, _, _, _, _, _, err = api.ParseVolumeID(volConfig.PeerVolumeHandle); err != nil {
+			return fmt.Errorf("could not create mirror destination volume %s; invalid peer volume handle; %v",
+				name, err)
+		}
+	}
 
-		if kerberosEnabled {
-			protocolTypes = []string{api.ProtocolTypeNFSv41}
-			apiExportRule.Nfsv3 = false
-			apiExportRule.Nfsv41 = true
-			apiExportRule.UnixReadOnly = false
-			apiExportRule.UnixReadWrite = false
+	// Take unix permissions from volume config first (handles Docker case & PVC annotations), then from pool
+	unixPermissions := volConfig.UnixPermissions
+	if unixPermissions == "" {
+		unixPermissions = pool.InternalAttributes()[UnixPermissions]
+	}
 
-			switch kerberos {
-			case api.MountOptionKerberos5:
-				apiExportRule.Kerberos5ReadWrite = true
-			case api.MountOptionKerberos5I:
-				apiExportRule.Kerberos5IReadWrite = true
-			case api.MountOptionKerberos5P:
-				apiExportRule.Kerberos5PReadWrite = true
-			}
-		}
+	// Fall back to the configured default permissions behavior
+	if unixPermissions == "" {
+		unixPermissions = d.defaultVolumeUnixPermissions()
+	}
 
-		exportPolicy = api.ExportPolicy{
-			Rules: []api.ExportRule{apiExportRule},
-		}
+	// Determine the protocols and, for NFS and dual-protocol volumes, the export policy
+	template := &api.FilesystemCreateRequest{}
+	if err = d.setCreateRequestProtocols(ctx, template, volConfig, pool, ldapEnabled); err != nil {
+		return err
 	}
 
-	labels := make(map[string]string)
+	// Custom tags go first so that any tag set by Trident takes precedence
+	labels := d.getCustomTags(ctx)
 	labels[drivers.TridentLabelTag] = d.getTelemetryLabels(ctx)
 
 	poolLabels, err := pool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
@@ -885,603 +1845,3308 @@ func (d *NASStorageDriver) Create(
 	}
 	labels[storage.ProvisioningLabelTag] = poolLabels
 
+	for tag, value := range d.getNamespaceAnnotationTags(ctx, volConfig) {
+		labels[tag] = value
+	}
+
+	for tag, value := range d.getVersionTags(ctx) {
+		labels[tag] = value
+	}
+
+	// Record where the volume's export rules came from, so that node access reconciliation can apply them
+	labels[StoragePoolTag] = pool.Name()
+	if volConfig.ExportRule != "" && !d.Config.AutoExportPolicy {
+		labels[ExplicitExportRuleTag] = "true"
+	}
+
 	networkFeatures := pool.InternalAttributes()[NetworkFeatures]
 
 	// Update config to reflect values used to create volume
 	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
 	volConfig.ServiceLevel = serviceLevel
 	volConfig.SnapshotDir = snapshotDir
+	volConfig.SnapshotPolicy = snapshotPolicy
 	volConfig.UnixPermissions = unixPermissions
-
-	// Find a subnet
-	subnet := d.SDK.RandomSubnetForStoragePool(ctx, pool)
-	if subnet == nil {
-		return fmt.Errorf("no subnets found for storage pool %s", pool.Name())
+	if snapshotReserve >= 0 {
+		volConfig.SnapshotReserve = strconv.Itoa(snapshotReserve)
+		labels[SnapshotReserveTag] = volConfig.SnapshotReserve
 	}
 
-	// Find matching capacity pools
-	cPools := d.SDK.CapacityPoolsForStoragePool(ctx, pool, serviceLevel)
-	if len(cPools) == 0 {
-		return fmt.Errorf("no capacity pools found for storage pool %s", pool.Name())
+	placement, err := d.placeVolume(ctx, volConfig, pool, serviceLevel, throughput, ldapEnabled, quotaBytes)
+	if err != nil {
+		return err
 	}
 
-	createErrors := multierr.Combine()
+	template.Name = volConfig.Name
+	template.SubnetID = placement.subnet.ID
+	template.CreationToken = name
+	template.Labels = labels
+	template.QuotaInBytes = int64(quotaBytes)
+	template.SnapshotDirectory = snapshotDirBool
+	template.NetworkFeatures = networkFeatures
+	template.SnapshotPolicy = snapshotPolicy
+	template.ThroughputMibps = throughput
+	template.Zone = placement.availabilityZone
+	template.IsLargeVolume = largeVolume
 
-	// Try each capacity pool until one works
-	for _, cPool := range cPools {
+	if backupEnabled {
+		template.BackupEnabled = true
+		template.BackupPolicy = pool.InternalAttributes()[BackupPolicy]
+	}
 
-		if d.Config.NASType == sa.SMB {
-			Logc(ctx).WithFields(LogFields{
-				"capacityPool":    cPool.Name,
-				"creationToken":   name,
-				"size":            sizeBytes,
-				"serviceLevel":    serviceLevel,
-				"snapshotDir":     snapshotDirBool,
-				"protocolTypes":   protocolTypes,
-				"networkFeatures": networkFeatures,
-			}).Debug("Creating volume.")
-		} else {
-			Logc(ctx).WithFields(LogFields{
-				"capacityPool":    cPool.Name,
-				"creationToken":   name,
-				"size":            sizeBytes,
-				"unixPermissions": unixPermissions,
-				"serviceLevel":    serviceLevel,
-				"snapshotDir":     snapshotDirBool,
-				"protocolTypes":   protocolTypes,
-				"exportPolicy":    fmt.Sprintf("%+v", exportPolicy),
-				"networkFeatures": networkFeatures,
-			}).Debug("Creating volume.")
-		}
-
-		createRequest := &api.FilesystemCreateRequest{
-			ResourceGroup:     cPool.ResourceGroup,
-			NetAppAccount:     cPool.NetAppAccount,
-			CapacityPool:      cPool.Name,
-			Name:              volConfig.Name,
-			SubnetID:          subnet.ID,
-			CreationToken:     name,
-			Labels:            labels,
-			ProtocolTypes:     protocolTypes,
-			QuotaInBytes:      int64(sizeBytes),
-			SnapshotDirectory: snapshotDirBool,
-			NetworkFeatures:   networkFeatures,
-			KerberosEnabled:   kerberosEnabled,
-		}
-
-		// Add unix permissions and export policy fields only to NFS volume
-		if d.Config.NASType == sa.NFS {
-			createRequest.UnixPermissions = unixPermissions
-			createRequest.ExportPolicy = exportPolicy
-		}
-
-		// Create the volume
-		volume, createErr := d.SDK.CreateVolume(ctx, createRequest)
-		if createErr != nil {
-			errMessage := fmt.Sprintf("ANF pool %s; error creating volume %s: %v", cPool.Name, name, createErr)
-			Logc(ctx).Error(errMessage)
-			createErrors = multierr.Combine(createErrors, fmt.Errorf(errMessage))
-			continue
-		}
+	if ldapEnabled {
+		template.LdapEnabled = true
+	}
 
-		// Always save the ID so we can find the volume efficiently later
-		volConfig.InternalID = volume.ID
+	// Only request cool access if enabled, since the coolness period is meaningless otherwise
+	if coolAccess {
+		template.CoolAccess = true
+		template.CoolnessPeriod = coolnessPeriod
+	}
 
-		// Wait for creation to complete so that the mount targets are available
-		return d.waitForVolumeCreate(ctx, volume)
+	if volConfig.IsMirrorDestination {
+		template.ReplicationSourceID = volConfig.PeerVolumeHandle
+		template.ReplicationSchedule = d.Config.ReplicationSchedule
 	}
 
-	return createErrors
-}
+	// Add unix permissions only to NFS and dual-protocol volumes
+	if d.Config.NASType != sa.SMB {
+		template.UnixPermissions = unixPermissions
+	}
 
-// CreateClone clones an existing volume.  If a snapshot is not specified, one is created.
-func (d *NASStorageDriver) CreateClone(
+	// Dual-protocol volumes use UNIX security so NFS clients see the configured permissions
+	if d.Config.NASType == NASTypeDual {
+		template.SecurityStyle = api.SecurityStyleUnix
+	}
+
+	if d.Config.NASType == sa.SMB {
+		template.SmbContinuouslyAvailable = smbContinuousAvailability
+		template.SmbEncryption = smbEncryption
+	}
+
+	logFields := LogFields{
+		"creationToken":   name,
+		"size":            sizeBytes,
+		"serviceLevel":    serviceLevel,
+		"snapshotDir":     snapshotDirBool,
+		"protocolTypes":   template.ProtocolTypes,
+		"networkFeatures": networkFeatures,
+		"throughput":      throughput,
+	}
+	if d.Config.NASType != sa.SMB {
+		logFields["unixPermissions"] = unixPermissions
+		logFields["exportPolicy"] = fmt.Sprintf("%+v", template.ExportPolicy)
+	}
+
+	// Create the volume in the first capacity pool that works, moving to another subnet if this one is full
+	createRequests := createRequestsForCapacityPools(ctx, template, placement.capacityPools, logFields)
+	volume, err := d.createVolumeInSubnets(ctx, pool, createRequests, placement.subnet,
+		placement.requisiteLocations, placement.preferredLocations)
+	if err != nil {
+		return err
+	}
+
+	// Always save the ID so we can find the volume efficiently later
+	volConfig.InternalID = volume.ID
+	recordVolumePlacement(volConfig, volume)
+
+	// Wait for creation to complete so that the mount targets are available
+	return d.finishVolumeCreate(ctx, volume)
+}
+
+// createVolumeSize returns the size of a new volume in bytes, and whether it must be a large volume.  The
+// requested size falls back to the pool's default and is raised to the minimum size the volume allows.
+func (d *NASStorageDriver) createVolumeSize(
+	ctx context.Context, volConfig *storage.VolumeConfig, pool storage.Pool,
+) (uint64, bool, error) {
+	name := volConfig.InternalName
+
+	// Determine volume size in bytes
+	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return 0, false, fmt.Errorf("could not convert volume size %s; %v", volConfig.Size, err)
+	}
+	sizeBytes, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%v is an invalid volume size; %v", volConfig.Size, err)
+	}
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(pool.InternalAttributes()[Size])
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+	if err = drivers.CheckMinVolumeSize(sizeBytes, MinimumVolumeSizeBytes); err != nil {
+		return 0, false, err
+	}
+
+	if minimumBytes := d.minimumVolumeSize(); sizeBytes < minimumBytes {
+
+		Logc(ctx).WithFields(LogFields{
+			"name":    name,
+			"size":    sizeBytes,
+			"minimum": minimumBytes,
+		}).Warningf("Requested size is too small. Setting volume size to the minimum allowable.")
+
+		sizeBytes = minimumBytes
+	}
+
+	// Volumes above ANF's regular size limit must be large volumes, which have a much larger minimum size.
+	// Volumes restored from a snapshot are always the same type as the snapshot's volume.
+	var largeVolume bool
+	if volConfig.RestoreFromSnapshot == "" {
+		if largeVolume, err = largeVolumeFromPool(pool, sizeBytes); err != nil {
+			return 0, false, err
+		}
+		if largeVolume && sizeBytes < MinimumANFLargeVolumeSizeBytes {
+
+			Logc(ctx).WithFields(LogFields{
+				"name":    name,
+				"size":    sizeBytes,
+				"minimum": MinimumANFLargeVolumeSizeBytes,
+			}).Warningf("Requested size is too small for a large volume. Setting volume size to the minimum allowable.")
+
+			sizeBytes = MinimumANFLargeVolumeSizeBytes
+		} else if !largeVolume && sizeBytes > MaximumANFVolumeSizeBytes {
+			return 0, false, fmt.Errorf("requested size %d is greater than the maximum size %d of a regular "+
+				"volume, and %s is disabled", sizeBytes, MaximumANFVolumeSizeBytes, LargeVolume)
+		}
+	}
+
+	if _, _, err = drivers.CheckVolumeSizeLimits(ctx, sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, false, err
+	}
+
+	return sizeBytes, largeVolume, nil
+}
+
+// setCreateRequestProtocols sets the protocol types, Kerberos setting, and export policy of a volume create
+// request.  NFS volumes take their NFS version from the mount options, unless Kerberos is enabled, and their
+// export rules from the volume config, the pool, or the automatically managed export policy, in that order.
+func (d *NASStorageDriver) setCreateRequestProtocols(
+	ctx context.Context, request *api.FilesystemCreateRequest, volConfig *storage.VolumeConfig, pool storage.Pool,
+	ldapEnabled bool,
+) error {
+	// Determine mount options (volume config wins, followed by backend config)
+	mountOptions := d.Config.NfsMountOptions
+	if volConfig.MountOptions != "" {
+		mountOptions = volConfig.MountOptions
+	}
+
+	// Take kerberos option from pool
+	kerberos := pool.InternalAttributes()[Kerberos]
+
+	// Determine protocol from mount options
+	var protocolTypes []string
+	var cifsAccess, nfsV3Access, nfsV41Access, kerberosEnabled bool
+	var apiExportRule api.ExportRule
+	var exportPolicy api.ExportPolicy
+
+	switch kerberos {
+	case api.MountOptionKerberos5, api.MountOptionKerberos5I, api.MountOptionKerberos5P:
+		kerberosEnabled = true
+	case "":
+		kerberosEnabled = false
+	default:
+		return fmt.Errorf("unsupported kerberos type: %s", kerberos)
+	}
+
+	// Kerberos volumes requested as ReadOnlyMany are exported with the read-only Kerberos flags
+	kerberosReadOnly := kerberosEnabled && volConfig.AccessMode == tridentconfig.ReadOnlyMany
+
+	if d.Config.NASType == sa.SMB {
+		protocolTypes = []string{api.ProtocolTypeCIFS}
+	} else {
+		nfsVersion, err := nfsVersionFromMountOptions(mountOptions, nfsVersion3)
+		if err != nil {
+			return err
+		}
+
+		// Kerberos volumes use the Kerberos NFS version regardless of the mount options
+		if kerberosEnabled {
+			// Read-only Kerberos access can't be offered over other versions, so don't quietly override a request
+			if kerberosReadOnly {
+				requestedVersion, err := nfsVersionFromMountOptions(mountOptions, "")
+				if err != nil {
+					return err
+				}
+				if requestedVersion != "" && !utils.SliceContainsString(kerberosNFSVersions, requestedVersion) {
+					return fmt.Errorf("read-only Kerberos access requires NFS version %s",
+						d.Config.KerberosNfsVersion)
+				}
+			}
+
+			nfsVersion = d.Config.KerberosNfsVersion
+		}
+
+		protocolTypes = []string{nfsProtocolType(nfsVersion)}
+		nfsV3Access = protocolTypes[0] == api.ProtocolTypeNFSv3
+		nfsV41Access = protocolTypes[0] == api.ProtocolTypeNFSv41
+
+		// NFS volumes can only map Kerberos principals to UNIX identities with LDAP over NFSv4.1
+		if ldapEnabled && d.Config.NASType == sa.NFS && !nfsV41Access {
+			return fmt.Errorf("LDAP is only supported for NFSv%s volumes, not NFSv%s", nfsVersion41, nfsVersion)
+		}
+
+		// Dual-protocol volumes are also shared via SMB, which the export rules must admit
+		if d.Config.NASType == NASTypeDual {
+			cifsAccess = true
+			protocolTypes = append(protocolTypes, api.ProtocolTypeCIFS)
+		}
+
+		// NFSv4.1 ACL export options can't be applied to NFSv3 volumes
+		hasRootAccess, err := hasRootAccessFromPool(pool)
+		if err != nil {
+			return err
+		}
+		chownMode, err := chownModeFromPool(pool)
+		if err != nil {
+			return err
+		}
+		if (hasRootAccess != nil || chownMode != "") && !nfsV41Access {
+			return fmt.Errorf("hasRootAccess and chownMode are only supported for NFSv%s volumes", nfsVersion41)
+		}
+
+		exportRules, err := poolExportRules(pool)
+		if err != nil {
+			return err
+		}
+
+		// Take export rule from volume config first (handles PVC annotations), then from pool
+		if volConfig.ExportRule != "" {
+			for _, rule := range strings.Split(volConfig.ExportRule, ",") {
+				ipAddr := net.ParseIP(rule)
+				_, netAddr, _ := net.ParseCIDR(rule)
+				if ipAddr == nil && netAddr == nil {
+					return fmt.Errorf("invalid address/CIDR for exportRule of volume %s: %s", volConfig.Name, rule)
+				}
+				if err = d.checkExportRuleAddress(ctx, pool.Name(), rule); err != nil {
+					return fmt.Errorf("invalid address/CIDR for exportRule of volume %s; %v", volConfig.Name, err)
+				}
+			}
+			exportRules = []drivers.AzureNASExportRule{{AllowedClients: volConfig.ExportRule}}
+		}
+
+		// Admit only the known cluster nodes if the export policy is managed automatically
+		if d.Config.AutoExportPolicy {
+			if volConfig.ExportRule != "" {
+				Logc(ctx).WithField("exportRule", volConfig.ExportRule).Warning(
+					"Ignoring volume export rule, since the export policy is managed automatically.")
+			}
+			exportRules = []drivers.AzureNASExportRule{{AllowedClients: d.autoExportAllowedClients()}}
+		}
+
+		for _, rule := range exportRules {
+
+			// Skip any rules limited to an NFS version other than the volume's
+			if (rule.Nfsv3 || rule.Nfsv41) && !(rule.Nfsv3 && nfsV3Access) && !(rule.Nfsv41 && nfsV41Access) {
+				continue
+			}
+
+			apiExportRule = api.ExportRule{
+				AllowedClients: rule.AllowedClients,
+				Cifs:           cifsAccess,
+				Nfsv3:          nfsV3Access,
+				Nfsv41:         nfsV41Access,
+				RuleIndex:      int32(len(exportPolicy.Rules) + 1),
+				UnixReadOnly:   rule.UnixReadOnly,
+				UnixReadWrite:  !rule.UnixReadOnly,
+				HasRootAccess:  hasRootAccess,
+				ChownMode:      chownMode,
+			}
+
+			if kerberosEnabled {
+				apiExportRule.UnixReadOnly = false
+				apiExportRule.UnixReadWrite = false
+				setKerberosExportRuleAccess(&apiExportRule, kerberos, rule.UnixReadOnly || kerberosReadOnly)
+			}
+
+			exportPolicy.Rules = append(exportPolicy.Rules, apiExportRule)
+		}
+
+		if len(exportPolicy.Rules) == 0 {
+			return fmt.Errorf("no export rules in pool %s apply to protocol %s", pool.Name(), protocolTypes[0])
+		}
+	}
+
+	request.ProtocolTypes = protocolTypes
+	request.KerberosEnabled = kerberosEnabled
+	request.ExportPolicy = exportPolicy
+
+	return nil
+}
+
+// volumePlacement records where a new volume may be created.
+type volumePlacement struct {
+	subnet             *api.Subnet
+	capacityPools      []*api.CapacityPool
+	availabilityZone   string
+	requisiteLocations []string
+	preferredLocations []string
+}
+
+// placeVolume selects the subnet and the candidate capacity pools, in order of preference, for a new volume.
+// Both are limited by the CSI topology and the pool's availability zones, and capacity pools are also limited
+// by service level, QoS type, Active Directory connection, and, if so configured, free space.
+func (d *NASStorageDriver) placeVolume(
+	ctx context.Context, volConfig *storage.VolumeConfig, pool storage.Pool, serviceLevel string, throughput float32,
+	ldapEnabled bool, quotaBytes uint64,
+) (*volumePlacement, error) {
+	// Limit the volume to the locations required by the CSI topology, favoring the preferred ones
+	requisiteLocations := topologyLocations(volConfig.RequisiteTopologies)
+	preferredLocations := topologyLocations(volConfig.PreferredTopologies)
+	topologyRequested := len(requisiteLocations) > 0 || len(preferredLocations) > 0
+	if topologyRequested {
+		Logc(ctx).WithFields(LogFields{
+			"requisiteLocations": requisiteLocations,
+			"preferredLocations": preferredLocations,
+		}).Debug("Selecting subnet and capacity pools by topology.")
+	}
+
+	// Find a subnet
+	var subnet *api.Subnet
+	if topologyRequested {
+		subnets := filterByTopology(d.SDK.SubnetsForStoragePool(ctx, pool),
+			func(subnet *api.Subnet) string { return subnet.Location }, requisiteLocations, preferredLocations)
+		if len(subnets) > 0 {
+			subnet = subnets[0]
+		}
+	} else {
+		subnet = d.SDK.SubnetForStoragePool(ctx, pool)
+	}
+	// A pool without subnets or capacity pools can never hold the volume, so report a config error that
+	// isn't worth retrying
+	if subnet == nil {
+		if len(requisiteLocations) > 0 {
+			return nil, errors.UnsupportedConfigError("no subnets found for storage pool %s in locations [%s]",
+				pool.Name(), strings.Join(requisiteLocations, ","))
+		}
+		return nil, errors.UnsupportedConfigError("no subnets found for storage pool %s", pool.Name())
+	}
+
+	// Find matching capacity pools, using manual QoS pools only if a throughput was specified
+	cPools := filterCapacityPoolsByQosType(d.SDK.CapacityPoolsForStoragePool(ctx, pool, serviceLevel), throughput > 0)
+	if len(cPools) == 0 {
+		if throughput > 0 {
+			return nil, errors.UnsupportedConfigError("no manual QoS capacity pools found for storage pool %s",
+				pool.Name())
+		}
+		return nil, errors.UnsupportedConfigError("no capacity pools found for storage pool %s", pool.Name())
+	}
+
+	if topologyRequested {
+		cPools = filterByTopology(cPools,
+			func(cPool *api.CapacityPool) string { return cPool.Location }, requisiteLocations, preferredLocations)
+		if len(cPools) == 0 {
+			return nil, errors.UnsupportedConfigError("no capacity pools found for storage pool %s in locations [%s]",
+				pool.Name(), strings.Join(requisiteLocations, ","))
+		}
+	}
+
+	// Place the volume in the first candidate availability zone in which a capacity pool exists
+	var availabilityZone string
+	if zones := availabilityZones(volConfig, pool); len(zones) > 0 {
+		for _, zone := range zones {
+			zoneLocation, zoneNumber, _ := parseAvailabilityZone(zone)
+			zoneCPools := cPools
+			if zoneLocation != "" {
+				zoneCPools = filterByTopology(cPools,
+					func(cPool *api.CapacityPool) string { return cPool.Location }, []string{zoneLocation}, nil)
+			}
+			if len(zoneCPools) > 0 {
+				availabilityZone = zoneNumber
+				cPools = zoneCPools
+				break
+			}
+		}
+		if availabilityZone == "" {
+			return nil, errors.UnsupportedConfigError(
+				"no capacity pools found for storage pool %s in availability zones [%s]",
+				pool.Name(), strings.Join(zones, ","))
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"candidateZones":   zones,
+			"availabilityZone": availabilityZone,
+		}).Debug("Selected availability zone.")
+	}
+
+	// LDAP requires an Active Directory connection on the volume's NetApp account
+	if ldapEnabled {
+		if cPools = filterCapacityPoolsByActiveDirectory(cPools); len(cPools) == 0 {
+			return nil, errors.UnsupportedConfigError("no capacity pools found for storage pool %s in NetApp "+
+				"accounts with an Active Directory connection", pool.Name())
+		}
+	}
+
+	// Rule out capacity pools that cannot hold the volume, if so configured
+	if d.Config.EnforcePoolCapacity {
+		var err error
+		if cPools, err = d.capacityPoolsWithFreeSpace(ctx, pool, cPools, quotaBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	return &volumePlacement{
+		subnet:             subnet,
+		capacityPools:      cPools,
+		availabilityZone:   availabilityZone,
+		requisiteLocations: requisiteLocations,
+		preferredLocations: preferredLocations,
+	}, nil
+}
+
+// createRequestsForCapacityPools returns a copy of the create request template for each capacity pool, in the
+// same order.
+func createRequestsForCapacityPools(
+	ctx context.Context, template *api.FilesystemCreateRequest, cPools []*api.CapacityPool, logFields LogFields,
+) []*api.FilesystemCreateRequest {
+	createRequests := make([]*api.FilesystemCreateRequest, 0, len(cPools))
+
+	for _, cPool := range cPools {
+
+		// Capacity pool names are only unique within a resource group and account, so always log the full name
+		cPoolFullName := api.CreateCapacityPoolFullName(cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name)
+		Logc(ctx).WithFields(logFields).WithField("capacityPool", cPoolFullName).Debug("Creating volume.")
+
+		createRequest := *template
+		createRequest.ResourceGroup = cPool.ResourceGroup
+		createRequest.NetAppAccount = cPool.NetAppAccount
+		createRequest.CapacityPool = cPool.Name
+
+		createRequests = append(createRequests, &createRequest)
+	}
+
+	return createRequests
+}
+
+// recordVolumePlacement saves the capacity pool and service level a volume landed in to its config, so that
+// operators can see where the volume is without parsing its resource ID.
+func recordVolumePlacement(volConfig *storage.VolumeConfig, volume *api.FileSystem) {
+	volConfig.CapacityPool = api.CreateCapacityPoolFullName(volume.ResourceGroup, volume.NetAppAccount,
+		volume.CapacityPool)
+	if volume.ServiceLevel != "" {
+		volConfig.ServiceLevel = volume.ServiceLevel
+	}
+}
+
+// createVolumeInCapacityPools issues the create requests, one per candidate capacity pool, until one succeeds.
+// If parallel creates are enabled, the requests are issued in batches of that size, and once a request in a
+// batch succeeds, the others are cancelled.  Otherwise, the requests are issued one at a time.
+func (d *NASStorageDriver) createVolumeInCapacityPools(
+	ctx context.Context, requests []*api.FilesystemCreateRequest,
+) (*api.FileSystem, error) {
+	batchSize := d.parallelCreatePools
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	createErrors := multierr.Combine()
+
+	for start := 0; start < len(requests); start += batchSize {
+		end := start + batchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		var volume *api.FileSystem
+		var err error
+		if end-start == 1 {
+			volume, err = d.createVolumeInCapacityPool(ctx, requests[start], d.volumeCreateConflictRetries)
+		} else {
+			volume, err = d.createVolumeInCapacityPoolsConcurrently(ctx, requests[start:end])
+		}
+		if err == nil {
+			return volume, nil
+		}
+		createErrors = multierr.Combine(createErrors, err)
+	}
+
+	return nil, createErrors
+}
+
+// createVolumeInSubnets issues the create requests in the selected subnet.  If ANF reports that the subnet has
+// no IP addresses left, the requests are issued again in each of the storage pool's other subnets in turn, until
+// the volume is created or it fails for some other reason.  Subnet usage is only an estimate, so even a subnet
+// chosen for its free addresses may turn out to be full.
+func (d *NASStorageDriver) createVolumeInSubnets(
+	ctx context.Context, pool storage.Pool, requests []*api.FilesystemCreateRequest, subnet *api.Subnet,
+	requisiteLocations, preferredLocations []string,
+) (*api.FileSystem, error) {
+	volume, err := d.createVolumeInCapacityPools(ctx, requests)
+	if err == nil || !api.IsANFSubnetExhaustedError(err) {
+		return volume, err
+	}
+
+	for _, nextSubnet := range d.fallbackSubnets(ctx, pool, subnet, requisiteLocations, preferredLocations) {
+		Logc(ctx).WithFields(LogFields{
+			"subnet":     subnet.FullName,
+			"nextSubnet": nextSubnet.FullName,
+		}).WithError(err).Warning("Subnet has no IP addresses left, trying another subnet.")
+
+		for _, request := range requests {
+			request.SubnetID = nextSubnet.ID
+		}
+		subnet = nextSubnet
+
+		volume, err = d.createVolumeInCapacityPools(ctx, requests)
+		if err == nil || !api.IsANFSubnetExhaustedError(err) {
+			return volume, err
+		}
+	}
+
+	return nil, err
+}
+
+// fallbackSubnets returns the storage pool's subnets other than the one already tried, in the order they should
+// be tried.  If a topology was requested, subnets in a preferred location come first, as when the first subnet
+// was selected.  Otherwise, the subnets with the most available IP addresses come first.
+func (d *NASStorageDriver) fallbackSubnets(
+	ctx context.Context, pool storage.Pool, triedSubnet *api.Subnet, requisiteLocations, preferredLocations []string,
+) []*api.Subnet {
+	subnets := make([]*api.Subnet, 0)
+	for _, subnet := range d.SDK.SubnetsForStoragePool(ctx, pool) {
+		if subnet.ID != triedSubnet.ID {
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	if len(requisiteLocations) > 0 || len(preferredLocations) > 0 {
+		return filterByTopology(subnets,
+			func(subnet *api.Subnet) string { return subnet.Location }, requisiteLocations, preferredLocations)
+	}
+
+	sort.Slice(subnets, func(i, j int) bool {
+		if subnets[i].AvailableIPs != subnets[j].AvailableIPs {
+			return subnets[i].AvailableIPs > subnets[j].AvailableIPs
+		}
+		return subnets[i].FullName < subnets[j].FullName
+	})
+
+	return subnets
+}
+
+// createVolumeInCapacityPoolsConcurrently issues several create requests at once and returns the volume
+// from the first request, in order of preference, that succeeded.  The remaining requests are cancelled as
+// soon as any request succeeds, and any volume that another request created regardless is deleted, including
+// one that Azure accepted before its request was cancelled.  The requests share a creation token, so a request
+// rejected with a conflict has most likely lost to another request in the batch and isn't retried.
+func (d *NASStorageDriver) createVolumeInCapacityPoolsConcurrently(
+	ctx context.Context, requests []*api.FilesystemCreateRequest,
+) (*api.FileSystem, error) {
+	createCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	volumes := make([]*api.FileSystem, len(requests))
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func(i int, request *api.FilesystemCreateRequest) {
+			defer wg.Done()
+			volumes[i], errs[i] = d.createVolumeInCapacityPool(createCtx, request, 0)
+			if errs[i] == nil {
+				cancel()
+			}
+		}(i, request)
+	}
+	wg.Wait()
+
+	var winner *api.FileSystem
+	createErrors := multierr.Combine()
+
+	for i := range requests {
+		if errs[i] != nil {
+			createErrors = multierr.Combine(createErrors, errs[i])
+		} else if winner == nil {
+			winner = volumes[i]
+		}
+	}
+
+	if winner == nil {
+		return nil, createErrors
+	}
+
+	// Only one volume is wanted, so remove any others
+	for i, request := range requests {
+		if volumes[i] != winner {
+			d.deleteExtraVolume(ctx, request, volumes[i])
+		}
+	}
+
+	return winner, nil
+}
+
+// deleteExtraVolume deletes a volume created by a losing parallel create request.  If the request failed or
+// was cancelled, Azure may still have accepted it, so the volume is looked up in the request's capacity pool.
+func (d *NASStorageDriver) deleteExtraVolume(
+	ctx context.Context, request *api.FilesystemCreateRequest, volume *api.FileSystem,
+) {
+	if volume == nil {
+		volumeID := api.CreateVolumeID(d.Config.SubscriptionID, request.ResourceGroup, request.NetAppAccount,
+			request.CapacityPool, request.Name)
+
+		exists, extantVolume, err := d.SDK.VolumeExistsByID(ctx, volumeID)
+		if err != nil {
+			Logc(ctx).WithField("volume", volumeID).WithError(err).Error(
+				"Could not check for extra volume from parallel create.")
+			return
+		}
+		if !exists {
+			return
+		}
+		volume = extantVolume
+	}
+
+	Logc(ctx).WithField("volume", volume.FullName).Warning("Deleting extra volume from parallel create.")
+	if err := d.SDK.DeleteVolume(ctx, volume); err != nil {
+		Logc(ctx).WithField("volume", volume.FullName).WithError(err).Error("Could not delete extra volume.")
+	}
+}
+
+// createVolumeInCapacityPool issues a single create request, repeating it up to the specified number of times
+// if it is rejected with a conflict, and returns an error naming the capacity pool if it fails.
+func (d *NASStorageDriver) createVolumeInCapacityPool(
+	ctx context.Context, request *api.FilesystemCreateRequest, retries int,
+) (*api.FileSystem, error) {
+	volume, err := d.createVolumeWithRetry(ctx, request, retries)
+	if err != nil {
+		cPoolFullName := api.CreateCapacityPoolFullName(request.ResourceGroup, request.NetAppAccount,
+			request.CapacityPool)
+		Logc(ctx).Errorf("ANF pool %s; error creating volume %s: %v", cPoolFullName, request.CreationToken, err)
+		return nil, fmt.Errorf("ANF pool %s; error creating volume %s: %w", cPoolFullName, request.CreationToken, err)
+	}
+	return volume, nil
+}
+
+// capacityPoolsWithFreeSpace returns the capacity pools with enough free space for a volume of the specified
+// size.  If there are none, the error distinguishes a volume that is too large for every capacity pool from
+// capacity pools that are merely full.  Pools whose size or usage is unknown are left for ANF to judge.
+func (d *NASStorageDriver) capacityPoolsWithFreeSpace(
+	ctx context.Context, pool storage.Pool, cPools []*api.CapacityPool, sizeBytes uint64,
+) ([]*api.CapacityPool, error) {
+	fittingPools := make([]*api.CapacityPool, 0, len(cPools))
+	tooLarge := true
+	var largestPoolSize int64
+
+	for _, cPool := range cPools {
+		cPoolFullName := api.CreateCapacityPoolFullName(cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name)
+
+		if cPool.Size == 0 {
+			tooLarge = false
+			fittingPools = append(fittingPools, cPool)
+			continue
+		}
+
+		if cPool.Size > largestPoolSize {
+			largestPoolSize = cPool.Size
+		}
+		if int64(sizeBytes) > cPool.Size {
+			Logc(ctx).WithFields(LogFields{
+				"capacityPool": cPoolFullName,
+				"poolSize":     cPool.Size,
+				"size":         sizeBytes,
+			}).Debug("Volume is too large for capacity pool.")
+			continue
+		}
+		tooLarge = false
+
+		usedBytes, err := d.SDK.CapacityPoolUsedBytes(ctx, cPool)
+		if err != nil {
+			Logc(ctx).WithField("capacityPool", cPoolFullName).WithError(err).Warning(
+				"Could not determine capacity pool free space.")
+			fittingPools = append(fittingPools, cPool)
+			continue
+		}
+
+		if freeBytes := cPool.Size - usedBytes; int64(sizeBytes) > freeBytes {
+			Logc(ctx).WithFields(LogFields{
+				"capacityPool": cPoolFullName,
+				"freeBytes":    freeBytes,
+				"size":         sizeBytes,
+			}).Debug("Capacity pool does not have enough free space for volume.")
+			continue
+		}
+
+		fittingPools = append(fittingPools, cPool)
+	}
+
+	if len(fittingPools) == 0 {
+		if tooLarge {
+			return nil, fmt.Errorf("requested volume size %d bytes is larger than every capacity pool for storage "+
+				"pool %s (largest is %d bytes); use a larger capacity pool or request a smaller volume",
+				sizeBytes, pool.Name(), largestPoolSize)
+		}
+		return nil, fmt.Errorf("capacity pools for storage pool %s are full and cannot hold a volume of %d bytes; "+
+			"free space in or grow a capacity pool", pool.Name(), sizeBytes)
+	}
+
+	return fittingPools, nil
+}
+
+// encodeExportRules serializes a list of export rules so it may be stored as a pool attribute.
+func encodeExportRules(rules []drivers.AzureNASExportRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(rules)
+	return string(encoded)
+}
+
+// poolExportRules returns a pool's structured export rules if any, else a single read-write rule for the
+// clients listed in its export rule.
+func poolExportRules(pool storage.Pool) ([]drivers.AzureNASExportRule, error) {
+	exportRules, err := exportRulesFromPool(pool)
+	if err != nil {
+		return nil, err
+	}
+	if len(exportRules) == 0 {
+		exportRules = []drivers.AzureNASExportRule{{AllowedClients: pool.InternalAttributes()[ExportRule]}}
+	}
+	return exportRules, nil
+}
+
+// exportRulesFromPool returns the structured export rules, if any, configured for a storage pool.
+func exportRulesFromPool(pool storage.Pool) ([]drivers.AzureNASExportRule, error) {
+	encoded := pool.InternalAttributes()[ExportRules]
+	if encoded == "" {
+		return nil, nil
+	}
+
+	var rules []drivers.AzureNASExportRule
+	if err := json.Unmarshal([]byte(encoded), &rules); err != nil {
+		return nil, fmt.Errorf("could not parse export rules; %v", err)
+	}
+	for i := range rules {
+		if err := expandExportRuleShorthand(&rules[i]); err != nil {
+			return nil, fmt.Errorf("invalid export rule %d; %v", i, err)
+		}
+	}
+	return rules, nil
+}
+
+// expandExportRuleShorthand replaces an export rule's cidr, access, and protocol shorthand with the
+// equivalent clients, access, and NFS version fields.
+func expandExportRuleShorthand(rule *drivers.AzureNASExportRule) error {
+	if rule.CIDR != "" {
+		if rule.AllowedClients != "" {
+			return errors.New("cidr and allowedClients are mutually exclusive")
+		}
+		rule.AllowedClients = rule.CIDR
+		rule.CIDR = ""
+	}
+
+	switch strings.ToLower(rule.Access) {
+	case "":
+	case exportRuleAccessReadOnly:
+		if rule.UnixReadWrite {
+			return errors.New("access ro conflicts with unixReadWrite")
+		}
+		rule.UnixReadOnly = true
+	case exportRuleAccessReadWrite:
+		if rule.UnixReadOnly {
+			return errors.New("access rw conflicts with unixReadOnly")
+		}
+		rule.UnixReadWrite = true
+	default:
+		return fmt.Errorf("invalid value for access: %s", rule.Access)
+	}
+	rule.Access = ""
+
+	switch strings.ToLower(rule.Protocol) {
+	case "":
+	case strings.ToLower(api.ProtocolTypeNFSv3):
+		rule.Nfsv3 = true
+	case strings.ToLower(api.ProtocolTypeNFSv41):
+		rule.Nfsv41 = true
+	default:
+		return fmt.Errorf("invalid value for protocol: %s", rule.Protocol)
+	}
+	rule.Protocol = ""
+
+	return nil
+}
+
+// validateExportRule ensures a structured export rule lists only valid client addresses/CIDRs and
+// doesn't request conflicting access.
+func validateExportRule(rule drivers.AzureNASExportRule) error {
+	if rule.AllowedClients == "" {
+		return errors.New("allowedClients must be specified")
+	}
+	for _, client := range strings.Split(rule.AllowedClients, ",") {
+		client = strings.TrimSpace(client)
+		ipAddr := net.ParseIP(client)
+		_, netAddr, _ := net.ParseCIDR(client)
+		if ipAddr == nil && netAddr == nil {
+			return fmt.Errorf("invalid address/CIDR for allowedClients: %s", client)
+		}
+	}
+	if rule.UnixReadOnly && rule.UnixReadWrite {
+		return errors.New("unixReadOnly and unixReadWrite are mutually exclusive")
+	}
+	return nil
+}
+
+// suspiciousExportRuleAddress returns a description of why an export rule address or CIDR is almost certainly
+// a misconfiguration, or an empty string if it looks reasonable.  The address must already be valid.
+func suspiciousExportRuleAddress(rule string) string {
+	ipAddr := net.ParseIP(rule)
+	ones, bits := -1, -1
+	if ipAddr == nil {
+		var netAddr *net.IPNet
+		ipAddr, netAddr, _ = net.ParseCIDR(rule)
+		if netAddr == nil {
+			return ""
+		}
+		ones, bits = netAddr.Mask.Size()
+		ipAddr = netAddr.IP
+	}
+
+	switch {
+	case ones == 0 && bits > 0:
+		return "it allows access from any address"
+	case ipAddr.IsUnspecified():
+		return "it is the unspecified address"
+	case ipAddr.IsLoopback():
+		return "it is a loopback address"
+	case ipAddr.IsLinkLocalUnicast():
+		return "it is a link-local address"
+	case ipAddr.IsMulticast(), ipAddr.IsLinkLocalMulticast(), ipAddr.IsInterfaceLocalMulticast():
+		return "it is a multicast address"
+	}
+	return ""
+}
+
+// checkExportRuleAddress warns about or rejects a suspicious export rule address, according to the backend's
+// exportRuleValidation mode.  A rule allowing access from any address is accepted if allowOpenExportRule is set.
+func (d *NASStorageDriver) checkExportRuleAddress(ctx context.Context, poolName, rule string) error {
+	if d.Config.ExportRuleValidation == ExportRuleValidationLenient {
+		return nil
+	}
+
+	reason := suspiciousExportRuleAddress(rule)
+	if reason == "" {
+		return nil
+	}
+	if _, netAddr, _ := net.ParseCIDR(rule); netAddr != nil && d.Config.AllowOpenExportRule {
+		if ones, _ := netAddr.Mask.Size(); ones == 0 {
+			return nil
+		}
+	}
+
+	if d.Config.ExportRuleValidation == ExportRuleValidationReject {
+		return fmt.Errorf("export rule address %s is not allowed because %s", rule, reason)
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"pool":   poolName,
+		"rule":   rule,
+		"reason": reason,
+	}).Warning("Export rule address is probably a misconfiguration.")
+	return nil
+}
+
+// defaultVolumeUnixPermissions returns the permissions for a new volume for which none were requested
+// or configured, according to the backend's defaultUnixPermissionsMode.
+func (d *NASStorageDriver) defaultVolumeUnixPermissions() string {
+	switch d.Config.DefaultUnixPermissionsMode {
+	case UnixPermissionsModeExplicit:
+		return d.Config.DefaultUnixPermissions
+	case UnixPermissionsModeEmpty:
+		return ""
+	default:
+		if d.SDK.HasFeature(api.FeatureUnixPermissions) {
+			return featureGatedUnixPermissions
+		}
+		return ""
+	}
+}
+
+// reusableCloneSnapshot returns the newest available snapshot of a clone's source volume that is recent enough
+// to be used instead of a new snapshot, or nil if there is none or reuse is not configured.
+func (d *NASStorageDriver) reusableCloneSnapshot(ctx context.Context, sourceVolume *api.FileSystem) *api.Snapshot {
+	if d.cloneSnapshotReuseAge == 0 {
+		return nil
+	}
+
+	snapshots, err := d.SDK.SnapshotsForVolume(ctx, sourceVolume)
+	if err != nil {
+		Logc(ctx).WithField("source", sourceVolume.Name).WithError(err).Warning(
+			"Could not list source volume snapshots, so a new snapshot will be created.")
+		return nil
+	}
+
+	var newestSnapshot *api.Snapshot
+	for _, snapshot := range *snapshots {
+		if snapshot.ProvisioningState != api.StateAvailable || time.Since(snapshot.Created) > d.cloneSnapshotReuseAge {
+			continue
+		}
+		if newestSnapshot == nil || snapshot.Created.After(newestSnapshot.Created) {
+			newestSnapshot = snapshot
+		}
+	}
+	return newestSnapshot
+}
+
+// sameAzureLocation reports whether two Azure locations are the same, ignoring differences in case and spacing
+// such as between "eastus" and "East US".
+func sameAzureLocation(location1, location2 string) bool {
+	return normalizeAzureLocation(location1) == normalizeAzureLocation(location2)
+}
+
+// normalizeAzureLocation converts an Azure location display name, such as "East US", to its name, as in eastus.
+func normalizeAzureLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// CreateClone clones an existing volume.  If a snapshot is not specified, one is created.
+func (d *NASStorageDriver) CreateClone(
 	ctx context.Context, sourceVolConfig, cloneVolConfig *storage.VolumeConfig, storagePool storage.Pool,
 ) error {
-	name := cloneVolConfig.InternalName
-	source := cloneVolConfig.CloneSourceVolumeInternal
-	snapshot := cloneVolConfig.CloneSourceSnapshotInternal
+	name := cloneVolConfig.InternalName
+	source := cloneVolConfig.CloneSourceVolumeInternal
+	snapshot := cloneVolConfig.CloneSourceSnapshotInternal
+
+	fields := LogFields{
+		"Method":   "CreateClone",
+		"Type":     "NASStorageDriver",
+		"name":     name,
+		"source":   source,
+		"snapshot": snapshot,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateClone")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateClone")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// ensure new volume doesn't exist, fail if so
+	// get source volume, fail if nonexistent or if wrong region
+	// if snapshot specified, read snapshots from source, fail if nonexistent
+	// if no snap specified, create one, fail if error
+	// create volume from snapshot
+
+	// Make sure we got a valid name
+	if err := d.validateVolumeName(cloneVolConfig.Name); err != nil {
+		return err
+	}
+
+	// Make sure we got a valid creation token
+	if err := d.validateCreationToken(name); err != nil {
+		return err
+	}
+
+	// The clone's own splitOnClone value (which core takes from the PVC or the source volume) wins, then that
+	// of the source volume's pool, then that of the backend
+	splitOnClone := d.Config.SplitOnClone
+	if !storage.IsStoragePoolUnset(storagePool) && storagePool.InternalAttributes()[SplitOnClone] != "" {
+		splitOnClone = storagePool.InternalAttributes()[SplitOnClone]
+	}
+	if cloneVolConfig.SplitOnClone != "" {
+		splitOnClone = cloneVolConfig.SplitOnClone
+	}
+	split := false
+	if splitOnClone != "" {
+		var err error
+		if split, err = strconv.ParseBool(splitOnClone); err != nil {
+			return fmt.Errorf("invalid boolean value for splitOnClone: %v", err)
+		}
+	}
+
+	// Get the source volume
+	sourceVolume, err := d.SDK.Volume(ctx, sourceVolConfig)
+	if err != nil {
+		return fmt.Errorf("could not find source volume; %v", err)
+	}
+
+	// ANF can only clone a volume within its own region
+	if sourceVolume.Location != "" && !sameAzureLocation(sourceVolume.Location, d.Config.Location) {
+		return fmt.Errorf("source volume %s is in location %s, but this backend is in location %s; ANF cannot "+
+			"clone volumes across regions, so use cross-region replication to copy the volume instead",
+			sourceVolume.FullName, sourceVolume.Location, d.Config.Location)
+	}
+
+	// If source volume has kerberos enabled, check if ACP allows it.
+	if sourceVolume.KerberosEnabled {
+		if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
+			Logc(ctx).WithField(
+				"feature", acp.FeatureInflightEncryption,
+			).WithError(err).Errorf("Failed to clone volume.")
+			return fmt.Errorf("feature %s requires ACP; %w", acp.FeatureInflightEncryption, err)
+		}
+	}
+
+	// We always clone to the same capacity pool, so we can use the source volume's info to find
+	// a pre-existing clone more efficiently.
+	cloneID := api.CreateVolumeID(d.Config.SubscriptionID, sourceVolume.ResourceGroup, sourceVolume.NetAppAccount,
+		sourceVolume.CapacityPool, cloneVolConfig.Name)
+
+	// If the volume already exists, bail out
+	volumeExists, extantVolume, err := d.SDK.VolumeExistsByID(ctx, cloneID)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume %s; %v", name, err)
+	}
+	if volumeExists {
+		if extantVolume.ProvisioningState == api.StateCreating {
+			// This is a retry and the volume still isn't ready, so no need to wait further.
+			return errors.VolumeCreatingError(
+				fmt.Sprintf("volume state is still %s, not %s", api.StateCreating, api.StateAvailable))
+		}
+		return drivers.NewVolumeExistsError(name)
+	}
+
+	var sourceSnapshot *api.Snapshot
+
+	if snapshot != "" {
+
+		// Get the source snapshot
+		sourceSnapshot, err = d.SDK.SnapshotForVolume(ctx, sourceVolume, snapshot)
+		if err != nil {
+			return fmt.Errorf("could not find source snapshot; %v", err)
+		}
+
+		// Ensure snapshot is in a usable state
+		if sourceSnapshot.ProvisioningState != api.StateAvailable {
+			return fmt.Errorf("source snapshot state is %s, it must be %s",
+				sourceSnapshot.ProvisioningState, api.StateAvailable)
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"snapshot": snapshot,
+			"source":   sourceVolume.Name,
+		}).Debug("Found source snapshot.")
+
+	} else if sourceSnapshot = d.reusableCloneSnapshot(ctx, sourceVolume); sourceSnapshot != nil {
+
+		Logc(ctx).WithFields(LogFields{
+			"snapshot": sourceSnapshot.Name,
+			"source":   sourceVolume.Name,
+			"created":  sourceSnapshot.Created,
+		}).Debug("Using recent source snapshot.")
+
+	} else {
+
+		// No source snapshot specified, so create one
+		snapName := time.Now().UTC().Format(storage.SnapshotNameFormat)
+
+		Logc(ctx).WithFields(LogFields{
+			"snapshot": snapName,
+			"source":   sourceVolume.Name,
+		}).Debug("Creating source snapshot.")
+
+		sourceSnapshot, err = d.SDK.CreateSnapshot(ctx, sourceVolume, snapName)
+		if err != nil {
+			return fmt.Errorf("could not create source snapshot; %v", err)
+		}
+
+		// Wait for snapshot creation to complete
+		err = d.SDK.WaitForSnapshotState(
+			ctx, sourceSnapshot, sourceVolume, api.StateAvailable, []string{api.StateError},
+			d.effectiveTimeout(ctx, api.SnapshotTimeout))
+		if err != nil {
+			return err
+		}
+
+		// Re-fetch the snapshot to populate the properties after create has completed
+		sourceSnapshot, err = d.SDK.SnapshotForVolume(ctx, sourceVolume, snapName)
+		if err != nil {
+			return fmt.Errorf("could not retrieve newly-created snapshot")
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"snapshot": sourceSnapshot.Name,
+			"source":   sourceVolume.Name,
+		}).Debug("Created source snapshot.")
+	}
+
+	// If RO clone is requested, don't create the volume on ANF backend and return nil
+	if cloneVolConfig.ReadOnlyClone {
+		// Return error , if snapshot directory is not enabled for RO clone
+		if !sourceVolume.SnapshotDirectory {
+			return fmt.Errorf("snapshot directory access is set to %t and readOnly clone is set to %t ",
+				sourceVolume.SnapshotDirectory, cloneVolConfig.ReadOnlyClone)
+		}
+		return nil
+	}
+
+	var labels map[string]string
+	labels = d.updateTelemetryLabels(ctx, sourceVolume)
+
+	// The clone gets the backend's current custom tags, which may differ from those of the source volume
+	for tag, value := range d.getCustomTags(ctx) {
+		labels[tag] = value
+	}
+
+	// The clone was created by this Trident version, not the one that created the source volume
+	for tag, value := range d.getVersionTags(ctx) {
+		labels[tag] = value
+	}
+
+	if storage.IsStoragePoolUnset(storagePool) {
+		// Set the base label
+		storagePoolTemp := &storage.StoragePool{}
+		storagePoolTemp.SetAttributes(map[string]sa.Offer{
+			sa.Labels: sa.NewLabelOffer(d.Config.Labels),
+		})
+		poolLabels, err := storagePoolTemp.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+		if err != nil {
+			return err
+		}
+
+		labels[storage.ProvisioningLabelTag] = poolLabels
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"creationToken":   name,
+		"sourceVolume":    sourceVolume.CreationToken,
+		"sourceSnapshot":  sourceSnapshot.Name,
+		"unixPermissions": sourceVolume.UnixPermissions,
+		"networkFeatures": sourceVolume.NetworkFeatures,
+	}).Debug("Cloning volume.")
+
+	createRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     sourceVolume.ResourceGroup,
+		NetAppAccount:     sourceVolume.NetAppAccount,
+		CapacityPool:      sourceVolume.CapacityPool,
+		Name:              cloneVolConfig.Name,
+		SubnetID:          sourceVolume.SubnetID,
+		CreationToken:     name,
+		Labels:            labels,
+		ProtocolTypes:     sourceVolume.ProtocolTypes,
+		QuotaInBytes:      sourceVolume.QuotaInBytes,
+		SnapshotDirectory: sourceVolume.SnapshotDirectory,
+		SnapshotID:        sourceSnapshot.SnapshotID,
+		NetworkFeatures:   sourceVolume.NetworkFeatures,
+		IsLargeVolume:     sourceVolume.IsLargeVolume,
+	}
+
+	// Add unix permissions and export policy fields only to NFS and dual-protocol volumes
+	if d.Config.NASType != sa.SMB {
+		createRequest.ExportPolicy = sourceVolume.ExportPolicy
+		createRequest.UnixPermissions = sourceVolume.UnixPermissions
+		createRequest.KerberosEnabled = sourceVolume.KerberosEnabled
+	}
+
+	// Volumes in manual QoS capacity pools need a throughput, which comes from the pool or else the source volume
+	poolThroughput, err := throughputFromPool(storagePool)
+	if err != nil {
+		return err
+	}
+	if poolThroughput > 0 || sourceVolume.ThroughputMibps > 0 {
+		cPool := d.capacityPoolForVolume(sourceVolume)
+		if cPool != nil && strings.EqualFold(cPool.QosType, api.QosTypeManual) {
+			createRequest.ThroughputMibps = sourceVolume.ThroughputMibps
+			if poolThroughput > 0 {
+				createRequest.ThroughputMibps = poolThroughput
+			}
+		} else if poolThroughput > 0 {
+			return fmt.Errorf("cannot set throughput on clone %s; capacity pool %s does not use manual QoS",
+				name, api.CreateCapacityPoolFullName(sourceVolume.ResourceGroup, sourceVolume.NetAppAccount,
+					sourceVolume.CapacityPool))
+		}
+	}
+
+	// Attach the source volume's snapshot policy if so configured, as long as the policy still exists
+	if d.Config.CloneInheritSnapshotPolicy && sourceVolume.SnapshotPolicyID != "" {
+		policyExists, err := d.SDK.SnapshotPolicyExists(ctx, sourceVolume.SnapshotPolicyID)
+		if err != nil {
+			return fmt.Errorf("could not check snapshot policy of source volume %s; %v",
+				sourceVolume.CreationToken, err)
+		}
+		if !policyExists {
+			return fmt.Errorf("snapshot policy %s of source volume %s no longer exists",
+				sourceVolume.SnapshotPolicyID, sourceVolume.CreationToken)
+		}
+
+		createRequest.SnapshotPolicy = sourceVolume.SnapshotPolicyID
+		cloneVolConfig.SnapshotPolicy = sourceVolume.SnapshotPolicyID
+	}
+
+	// Clone the volume
+	clone, err := d.SDK.CreateVolume(ctx, createRequest)
+	if err != nil {
+		return err
+	}
+
+	// Always save the ID so we can find the volume efficiently later
+	cloneVolConfig.InternalID = clone.ID
+	recordVolumePlacement(cloneVolConfig, clone)
+
+	if !split {
+		// Wait for creation to complete so that the mount targets are available
+		return d.finishVolumeCreate(ctx, clone)
+	}
+
+	// A clone can only be split once it exists, so always wait for it, even if creates are asynchronous
+	if err = d.waitForVolumeCreate(ctx, clone); err != nil {
+		return err
+	}
+
+	return d.splitClone(ctx, clone, sourceSnapshot)
+}
+
+// splitClone splits a new clone from its source snapshot, so that the snapshot may be deleted, and waits for
+// the split to finish before the clone is reported as available.  A clone whose split fails or doesn't finish
+// in time is deleted, so that a retry of the clone starts over instead of finding an unsplit clone.
+func (d *NASStorageDriver) splitClone(ctx context.Context, clone *api.FileSystem, sourceSnapshot *api.Snapshot) error {
+	logFields := LogFields{
+		"clone":          clone.CreationToken,
+		"sourceSnapshot": sourceSnapshot.Name,
+		"size":           clone.QuotaInBytes,
+	}
+
+	// Splitting copies all data the clone still shares with the snapshot, so it is neither quick nor free
+	Logc(ctx).WithFields(logFields).Info("Splitting clone from its source snapshot. The clone will consume up " +
+		"to its full size of additional capacity pool space, and the split may take a long time for large volumes.")
+
+	splitCtx, cancel := context.WithTimeout(ctx, d.volumeCreateTimeout)
+	defer cancel()
+
+	startTime := time.Now()
+	if err := d.SDK.SplitCloneFromParent(splitCtx, clone); err != nil {
+		if errDelete := d.SDK.DeleteVolume(ctx, clone); errDelete != nil {
+			Logc(ctx).WithFields(logFields).WithError(errDelete).Error("Unsplit clone could not be deleted.")
+		}
+		return fmt.Errorf("could not split clone %s from its source snapshot; %v", clone.CreationToken, err)
+	}
+
+	logFields["duration"] = time.Since(startTime).Round(time.Second)
+	Logc(ctx).WithFields(logFields).Info("Split clone from its source snapshot.")
+
+	return nil
+}
+
+// restoreSnapshotToNewVolume creates a volume from a snapshot of another volume, leaving both the source
+// volume and its snapshot untouched.  Unlike a clone, the new volume has no lineage to the source volume.
+// The snapshot is specified by volConfig.RestoreFromSnapshot as <volume internal name>/<snapshot name>.
+// ANF creates the new volume in the source volume's capacity pool.
+func (d *NASStorageDriver) restoreSnapshotToNewVolume(
+	ctx context.Context, volConfig *storage.VolumeConfig, pool storage.Pool, sizeBytes uint64,
+) error {
+	name := volConfig.InternalName
+
+	sourceVolumeName, snapshotName, err := parseRestoreFromSnapshot(volConfig.RestoreFromSnapshot)
+	if err != nil {
+		return err
+	}
+
+	// Get the source volume and snapshot
+	sourceVolume, err := d.SDK.VolumeByCreationToken(ctx, sourceVolumeName)
+	if err != nil {
+		return fmt.Errorf("could not find source volume %s; %v", sourceVolumeName, err)
+	}
+
+	sourceSnapshot, err := d.SDK.SnapshotForVolume(ctx, sourceVolume, snapshotName)
+	if err != nil {
+		return fmt.Errorf("could not find source snapshot %s; %v", snapshotName, err)
+	}
+	if sourceSnapshot.ProvisioningState != api.StateAvailable {
+		return fmt.Errorf("source snapshot state is %s, it must be %s",
+			sourceSnapshot.ProvisioningState, api.StateAvailable)
+	}
+
+	// The new volume must be able to hold all of the snapshot's data
+	quotaBytes := int64(sizeBytes)
+	if quotaBytes < sourceVolume.QuotaInBytes {
+		quotaBytes = sourceVolume.QuotaInBytes
+	}
+
+	labels := d.getCustomTags(ctx)
+	labels[drivers.TridentLabelTag] = d.getTelemetryLabels(ctx)
+	for tag, value := range d.getVersionTags(ctx) {
+		labels[tag] = value
+	}
+	poolLabels, err := pool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+	if err != nil {
+		return err
+	}
+	labels[storage.ProvisioningLabelTag] = poolLabels
+
+	Logc(ctx).WithFields(LogFields{
+		"creationToken":  name,
+		"sourceVolume":   sourceVolume.CreationToken,
+		"sourceSnapshot": sourceSnapshot.Name,
+		"size":           quotaBytes,
+	}).Debug("Restoring snapshot to new volume.")
+
+	createRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     sourceVolume.ResourceGroup,
+		NetAppAccount:     sourceVolume.NetAppAccount,
+		CapacityPool:      sourceVolume.CapacityPool,
+		Name:              volConfig.Name,
+		SubnetID:          sourceVolume.SubnetID,
+		CreationToken:     name,
+		Labels:            labels,
+		ProtocolTypes:     sourceVolume.ProtocolTypes,
+		QuotaInBytes:      quotaBytes,
+		SnapshotDirectory: sourceVolume.SnapshotDirectory,
+		SnapshotID:        sourceSnapshot.SnapshotID,
+		NetworkFeatures:   sourceVolume.NetworkFeatures,
+		ThroughputMibps:   sourceVolume.ThroughputMibps,
+		IsLargeVolume:     sourceVolume.IsLargeVolume,
+	}
+
+	// Add unix permissions and export policy fields only to NFS and dual-protocol volumes
+	if d.Config.NASType != sa.SMB {
+		createRequest.ExportPolicy = sourceVolume.ExportPolicy
+		createRequest.UnixPermissions = sourceVolume.UnixPermissions
+		createRequest.KerberosEnabled = sourceVolume.KerberosEnabled
+	}
+
+	volume, err := d.SDK.CreateVolume(ctx, createRequest)
+	if err != nil {
+		return err
+	}
+
+	// Always save the ID so we can find the volume efficiently later
+	volConfig.InternalID = volume.ID
+	volConfig.Size = strconv.FormatInt(quotaBytes, 10)
+	volConfig.ServiceLevel = sourceVolume.ServiceLevel
+	volConfig.SnapshotDir = strconv.FormatBool(sourceVolume.SnapshotDirectory)
+	volConfig.UnixPermissions = createRequest.UnixPermissions
+	recordVolumePlacement(volConfig, volume)
+
+	// Wait for creation to complete so that the mount targets are available
+	return d.finishVolumeCreate(ctx, volume)
+}
+
+// parseRestoreFromSnapshot splits a snapshot reference of the form <volume internal name>/<snapshot name>.
+func parseRestoreFromSnapshot(restoreFromSnapshot string) (string, string, error) {
+	parts := strings.Split(restoreFromSnapshot, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid snapshot %s to restore from; expected <volume>/<snapshot>",
+			restoreFromSnapshot)
+	}
+	return parts[0], parts[1], nil
+}
+
+// Import finds an existing volume and makes it available for containers.  If ImportNotManaged is false, the
+// volume is fully brought under Trident's management.
+func (d *NASStorageDriver) Import(ctx context.Context, volConfig *storage.VolumeConfig, originalName string) error {
+	fields := LogFields{
+		"Method":       "Import",
+		"Type":         "NASStorageDriver",
+		"originalName": originalName,
+		"newName":      volConfig.InternalName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Import")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Import")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volume, err := d.SDK.VolumeByCreationToken(ctx, originalName)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s; %v", originalName, err)
+	}
+
+	// The creation token becomes the internal name, so it must be one Trident could have created
+	if err = d.validateCreationToken(volume.CreationToken); err != nil {
+		return fmt.Errorf("could not import volume %s; %v", originalName, err)
+	}
+
+	// Ensure the volume may be imported by a capacity pool managed by this backend
+	if err = d.SDK.EnsureVolumeInValidCapacityPool(ctx, volume); err != nil {
+		return err
+	}
+
+	// Managed volumes without a snapshot reserve adopt the one requested for the volume, if any, or else the
+	// backend's, which leaves less of the quota for data
+	snapshotReserve := snapshotReserveFromVolume(volume)
+	adoptSnapshotReserve := false
+	if snapshotReserve < 0 && !volConfig.ImportNotManaged {
+		requestedReserve := d.Config.SnapshotReserve
+		if volConfig.SnapshotReserve != "" {
+			requestedReserve = volConfig.SnapshotReserve
+		}
+		if snapshotReserve, err = parseSnapshotReserve(requestedReserve); err != nil {
+			return fmt.Errorf("could not import volume %s; %v", originalName, err)
+		}
+		adoptSnapshotReserve = snapshotReserve >= 0
+	}
+	if snapshotReserve >= 0 {
+		volConfig.SnapshotReserve = strconv.Itoa(snapshotReserve)
+	}
+
+	// Get the volume size
+	volConfig.Size = strconv.FormatInt(dataSizeForSnapshotReserve(volume.QuotaInBytes, snapshotReserve), 10)
+
+	Logc(ctx).WithFields(LogFields{
+		"creationToken": volume.CreationToken,
+		"managed":       !volConfig.ImportNotManaged,
+		"state":         volume.ProvisioningState,
+		"capacityPool":  volume.CapacityPool,
+		"sizeBytes":     volume.QuotaInBytes,
+	}).Debug("Found volume to import.")
+
+	var snapshotDirAccess bool
+	// Modify the volume if Trident will manage its lifecycle
+	if !volConfig.ImportNotManaged {
+		if volConfig.SnapshotDir != "" {
+			if snapshotDirAccess, err = strconv.ParseBool(volConfig.SnapshotDir); err != nil {
+				return fmt.Errorf("could not import volume %s, snapshot directory access is set to %s",
+					originalName, volConfig.SnapshotDir)
+			}
+		}
+
+		// Check for kerberos option from backend config
+		kerberos := d.Config.Kerberos
+		if kerberos != "" {
+			// Check if this volume landed on a Kerberos-enabled storage pool. If so, check if ACP allows it.
+			if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
+				Logc(ctx).WithField(
+					"feature", acp.FeatureInflightEncryption,
+				).WithError(err).Errorf("Could not import volume.")
+				return fmt.Errorf("feature %s requires ACP; %w", acp.FeatureInflightEncryption, err)
+			}
+		}
+
+		if kerberos != "" && !volume.KerberosEnabled {
+			return fmt.Errorf("could not import non-kerberos volume '%s', on a kerberos enabled backend", originalName)
+		}
+
+		if kerberos == "" && volume.KerberosEnabled {
+			return fmt.Errorf("could not import kerberos volume '%s', on a non-kerberos enabled backend", originalName)
+		}
+
+		// ANF can't change whether a volume uses LDAP after it is created, so the backend must agree with the
+		// volume wherever that affects how users are mapped
+		var ldapEnabled bool
+		if d.Config.LDAPEnabled != "" {
+			if ldapEnabled, err = strconv.ParseBool(d.Config.LDAPEnabled); err != nil {
+				return fmt.Errorf("could not import volume %s; invalid value for ldapEnabled; %v", originalName, err)
+			}
+		}
+		if volume.LdapEnabled && !ldapEnabled {
+			return fmt.Errorf("could not import LDAP-enabled volume '%s', on a backend without LDAP", originalName)
+		}
+		if ldapEnabled && !volume.LdapEnabled {
+			Logc(ctx).WithField("originalName", originalName).Warning(
+				"Imported volume does not use LDAP, which cannot be enabled on an existing volume.")
+		}
+
+		modifiedExportRule := api.ExportRule{}
+		if kerberos != "" {
+			modifiedExportRule.Nfsv41 = nfsProtocolType(d.Config.KerberosNfsVersion) == api.ProtocolTypeNFSv41
+			setKerberosExportRuleAccess(&modifiedExportRule, kerberos,
+				volConfig.AccessMode == tridentconfig.ReadOnlyMany)
+		}
+
+		// Update the volume labels
+		if storage.AllowPoolLabelOverwrite(storage.ProvisioningLabelTag, volume.Labels[storage.ProvisioningLabelTag]) {
+			volume.Labels[storage.ProvisioningLabelTag] = ""
+		}
+		labels := d.updateTelemetryLabels(ctx, volume)
+		if adoptSnapshotReserve {
+			labels[SnapshotReserveTag] = volConfig.SnapshotReserve
+		}
+
+		// Dual-protocol volumes ([NFSv3, CIFS]) are managed using whichever protocol this backend serves, and
+		// dual-protocol backends only import volumes that support both protocols
+		if d.Config.NASType == sa.SMB && volumeSupportsNASType(volume, sa.SMB) {
+			// ANF can't add continuous availability or encryption to an existing share with a volume update, so
+			// the volume must already offer whatever the backend requires
+			var continuousAvailability, encryption bool
+			if continuousAvailability, encryption, err = parseSMBOptions(d.Config.SMBContinuousAvailability,
+				d.Config.SMBEncryption); err != nil {
+				return fmt.Errorf("could not import volume %s; %v", originalName, err)
+			}
+			if continuousAvailability && !volume.SmbContinuouslyAvailable {
+				return fmt.Errorf("could not import volume '%s' without continuous availability, on a backend "+
+					"that requires it", originalName)
+			}
+			if encryption && !volume.SmbEncryption {
+				return fmt.Errorf("could not import volume '%s' without SMB encryption, on a backend that requires it",
+					originalName)
+			}
+
+			if err = d.SDK.ModifyVolume(ctx, volume, labels, nil, &snapshotDirAccess, &modifiedExportRule); err != nil {
+				Logc(ctx).WithField("originalName", originalName).WithError(err).Error(
+					"Could not import volume, volume modify failed.")
+				return fmt.Errorf("could not import volume %s, volume modify failed; %v", originalName, err)
+			}
+
+			Logc(ctx).WithFields(LogFields{
+				"name":          volume.Name,
+				"creationToken": volume.CreationToken,
+				"labels":        labels,
+			}).Info("Volume modified.")
+
+		} else if d.Config.NASType != sa.SMB && volumeSupportsNASType(volume, d.Config.NASType) {
+			// Update volume unix permissions.  Permissions specified in a PVC annotation take precedence
+			// over the backend's unixPermissions config.
+			unixPermissions := volConfig.UnixPermissions
+			if unixPermissions == "" {
+				unixPermissions = d.Config.UnixPermissions
+			}
+			if unixPermissions == "" {
+				unixPermissions = volume.UnixPermissions
+			}
+			if unixPermissions != "" {
+				if err = utils.ValidateOctalUnixPermissions(unixPermissions); err != nil {
+					return fmt.Errorf("could not import volume %s; %v", originalName, err)
+				}
+			}
+
+			if err = d.SDK.ModifyVolume(ctx, volume, labels, &unixPermissions, &snapshotDirAccess, &modifiedExportRule); err != nil {
+				Logc(ctx).WithField("originalName", originalName).WithError(err).Error(
+					"Could not import volume, volume modify failed.")
+				return fmt.Errorf("could not import volume %s, volume modify failed; %v", originalName, err)
+			}
+
+			Logc(ctx).WithFields(LogFields{
+				"name":            volume.Name,
+				"creationToken":   volume.CreationToken,
+				"labels":          labels,
+				"unixPermissions": unixPermissions,
+			}).Info("Volume modified.")
+		} else {
+			return fmt.Errorf("could not import volume '%s' due to backend and volume mismatch", originalName)
+		}
+
+		if _, err = d.SDK.WaitForVolumeState(
+			ctx, volume, api.StateAvailable, []string{api.StateError},
+			d.effectiveTimeout(ctx, d.defaultTimeout())); err != nil {
+			return fmt.Errorf("could not import volume %s; %v", originalName, err)
+		}
+
+		// Apply the snapshot policy from a PVC annotation or the backend config, if any
+		snapshotPolicy := volConfig.SnapshotPolicy
+		if snapshotPolicy == "" {
+			snapshotPolicy = d.Config.SnapshotPolicy
+		}
+		if snapshotPolicy != "" {
+			if err = validateSnapshotPolicy(snapshotPolicy); err != nil {
+				return fmt.Errorf("could not import volume %s; %v", originalName, err)
+			}
+			if err = d.SDK.ModifyVolumeSnapshotPolicy(ctx, volume, snapshotPolicy); err != nil {
+				return fmt.Errorf("could not import volume %s, snapshot policy modify failed; %v", originalName, err)
+			}
+			volConfig.SnapshotPolicy = snapshotPolicy
+		}
+
+		// Enable cool access if the backend config requests it, which isn't applicable to SMB volumes
+		if d.Config.NASType != sa.SMB {
+			coolAccess, coolnessPeriod, err := parseCoolAccess(d.Config.CoolAccess, d.Config.CoolnessPeriod,
+				d.Config.CoolAccessRetrievalPolicy, volume.ServiceLevel)
+			if err != nil {
+				return fmt.Errorf("could not import volume %s; %v", originalName, err)
+			}
+			if coolAccess && !volume.CoolAccess {
+				if err = d.SDK.ModifyVolumeCoolAccess(ctx, volume, coolAccess, coolnessPeriod); err != nil {
+					return fmt.Errorf("could not import volume %s, cool access modify failed; %v", originalName, err)
+				}
+			}
+		}
+	}
+
+	// The ANF creation token cannot be changed, so use it as the internal name
+	volConfig.InternalName = originalName
+
+	// Always save the ID so we can find the volume efficiently later
+	volConfig.InternalID = volume.ID
+
+	return nil
+}
+
+// Rename changes the name of a volume.  ANF creation tokens are immutable, so only the name recorded in
+// the volume's tags changes, and the volume is still found by its original creation token.
+func (d *NASStorageDriver) Rename(ctx context.Context, name, newName string) error {
+	fields := LogFields{
+		"Method":  "Rename",
+		"Type":    "NASStorageDriver",
+		"name":    name,
+		"newName": newName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Rename")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Rename")
+
+	// Import doesn't rename ANF volumes, so import failure cleanup asks to rename a volume to its own
+	// name.  There is nothing to record in that case, and tagging the volume would wrongly mark it as
+	// known to Trident.
+	if name == newName {
+		return nil
+	}
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	volume, err := d.SDK.VolumeByCreationToken(ctx, name)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s; %v", name, err)
+	}
+
+	if volume.Labels[TridentNameTag] == newName {
+		Logc(ctx).WithFields(fields).Debug("Volume already renamed.")
+		return nil
+	}
+
+	// Only the name tag is passed, so the volume's other tags are preserved
+	labels := map[string]string{TridentNameTag: newName}
+	if err = d.SDK.ModifyVolume(ctx, volume, labels, nil, nil, nil); err != nil {
+		return fmt.Errorf("could not rename volume %s; %v", name, err)
+	}
+
+	Logc(ctx).WithFields(fields).Debug("Volume renamed.")
+
+	return nil
+}
+
+// getTelemetryLabels builds the standard telemetry labels that are set on each volume.
+func (d *NASStorageDriver) getTelemetryLabels(ctx context.Context) string {
+	telemetry := map[string]Telemetry{drivers.TridentLabelTag: *d.telemetry}
+
+	telemetryJSON, err := json.Marshal(telemetry)
+	if err != nil {
+		Logc(ctx).Errorf("Failed to marshal telemetry: %+v", telemetry)
+	}
+
+	return strings.ReplaceAll(string(telemetryJSON), " ", "")
+}
+
+// updateTelemetryLabels updates a volume's labels to include the standard telemetry labels.
+func (d *NASStorageDriver) updateTelemetryLabels(ctx context.Context, volume *api.FileSystem) map[string]string {
+	if volume.Labels == nil {
+		volume.Labels = make(map[string]string)
+	}
+
+	newLabels := volume.Labels
+	newLabels[drivers.TridentLabelTag] = d.getTelemetryLabels(ctx)
+	return newLabels
+}
+
+// createVolumeWithRetry issues a volume create request, repeating it up to the specified number of times with
+// an increasing delay if ANF rejects it because of a conflicting operation in progress, so that a brief clash
+// doesn't fail the whole create attempt.  The SDK already retries throttling, server errors, and network
+// failures, so any other error is returned immediately rather than being retried a second time here.
+func (d *NASStorageDriver) createVolumeWithRetry(
+	ctx context.Context, request *api.FilesystemCreateRequest, retries int,
+) (*api.FileSystem, error) {
+	delay := d.volumeCreateConflictRetryDelay
+	for attempt := 0; ; attempt++ {
+		volume, err := d.SDK.CreateVolume(ctx, request)
+		if err == nil || !api.IsANFConflictError(err) || attempt >= retries {
+			return volume, err
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"creationToken": request.CreationToken,
+			"attempt":       attempt + 1,
+			"delay":         delay,
+		}).WithError(err).Warning("Conflict creating volume, retrying.")
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// asyncVolumeCreate returns whether volume creates return once ANF accepts them rather than waiting for the
+// volumes to become available.  Docker has no way to retry a create, so it always waits.
+func (d *NASStorageDriver) asyncVolumeCreate() bool {
+	return d.Config.AsyncVolumeCreate && d.Config.DriverContext != tridentconfig.ContextDocker
+}
+
+// finishVolumeCreate waits for a new volume to become available or, if volumes are created asynchronously,
+// hands the volume to the background poller and returns a VolumeCreatingError so the caller tries again later.
+func (d *NASStorageDriver) finishVolumeCreate(ctx context.Context, volume *api.FileSystem) error {
+	if !d.asyncVolumeCreate() {
+		return d.waitForVolumeCreate(ctx, volume)
+	}
+
+	d.watchPendingVolume(ctx, volume)
+
+	return errors.VolumeCreatingError(fmt.Sprintf("volume %s was accepted and is being created",
+		volume.CreationToken))
+}
+
+// watchPendingVolume adds a volume being created asynchronously to those checked by the background poller,
+// starting the poller if needed.
+func (d *NASStorageDriver) watchPendingVolume(ctx context.Context, volume *api.FileSystem) {
+	d.pendingVolumesLock.Lock()
+	defer d.pendingVolumesLock.Unlock()
+
+	if d.pendingVolumes == nil {
+		d.pendingVolumes = make(map[string]*pendingVolume)
+	}
+	d.pendingVolumes[volume.CreationToken] = &pendingVolume{volume: volume, startTime: time.Now()}
+
+	if d.pendingVolumesDone != nil {
+		return
+	}
+
+	// The poller outlives the create request, so it must not be stopped when the request's context is
+	pollCtx := context.WithoutCancel(ctx)
+	ticker := time.NewTicker(pendingVolumePollInterval)
+	done := make(chan struct{})
+	d.pendingVolumesDone = done
+
+	Logc(ctx).WithField("interval", pendingVolumePollInterval).Debug("Starting background poller of new volumes.")
+
+	d.pendingVolumesWaitGroup.Add(1)
+	go func() {
+		defer d.pendingVolumesWaitGroup.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.pollPendingVolumes(pollCtx)
+			case <-done:
+				Logc(pollCtx).WithField("driver", d.Name()).Debug("Stopped background poller of new volumes.")
+				return
+			}
+		}
+	}()
+}
+
+// stopPendingVolumePoller stops the background poller of volumes being created asynchronously, if it is
+// running, and waits for any poll in progress to finish.
+func (d *NASStorageDriver) stopPendingVolumePoller() {
+	d.pendingVolumesLock.Lock()
+	done := d.pendingVolumesDone
+	d.pendingVolumesDone = nil
+	d.pendingVolumesLock.Unlock()
+
+	if done == nil {
+		return
+	}
+	close(done)
+	d.pendingVolumesWaitGroup.Wait()
+}
+
+// isPendingVolume returns whether a volume is being created asynchronously and hasn't yet become available.
+func (d *NASStorageDriver) isPendingVolume(creationToken string) bool {
+	d.pendingVolumesLock.Lock()
+	defer d.pendingVolumesLock.Unlock()
+
+	_, ok := d.pendingVolumes[creationToken]
+	return ok
+}
+
+// pollPendingVolumes checks on each volume being created asynchronously, and stops watching those that are
+// available, or that have failed or taken longer than the volume create timeout and been deleted.
+func (d *NASStorageDriver) pollPendingVolumes(ctx context.Context) {
+	d.pendingVolumesLock.Lock()
+	pendingVolumes := make([]*pendingVolume, 0, len(d.pendingVolumes))
+	for _, pending := range d.pendingVolumes {
+		pendingVolumes = append(pendingVolumes, pending)
+	}
+	d.pendingVolumesLock.Unlock()
+
+	for _, pending := range pendingVolumes {
+		if d.checkPendingVolume(ctx, pending) {
+			d.pendingVolumesLock.Lock()
+			delete(d.pendingVolumes, pending.volume.CreationToken)
+			d.pendingVolumesLock.Unlock()
+		}
+	}
+}
+
+// checkPendingVolume checks on a volume being created asynchronously, deleting it if it failed or is taking
+// too long, and returns whether the volume no longer needs to be watched.
+func (d *NASStorageDriver) checkPendingVolume(ctx context.Context, pending *pendingVolume) bool {
+	logFields := LogFields{"volume": pending.volume.CreationToken}
+
+	volume, err := d.SDK.VolumeByID(ctx, pending.volume.ID)
+	if err != nil {
+		volume = pending.volume
+		if errors.IsNotFoundError(err) {
+			Logc(ctx).WithFields(logFields).Warning("New volume no longer exists.")
+			return true
+		}
+		Logc(ctx).WithFields(logFields).WithError(err).Debug("Could not read state of new volume.")
+	} else {
+		logFields["state"] = volume.ProvisioningState
+
+		switch volume.ProvisioningState {
+		case api.StateAvailable:
+			d.provisioningLatency.record(volume.ServiceLevel, volume.CapacityPool, time.Since(pending.startTime))
+			Logc(ctx).WithFields(logFields).Debug("New volume is available.")
+			return true
+
+		case api.StateError:
+			// Delete a failed volume, so that the next create attempt starts over
+			if errDelete := d.SDK.DeleteVolume(ctx, volume); errDelete != nil {
+				Logc(ctx).WithFields(logFields).WithError(errDelete).Error("Failed volume could not be deleted.")
+			} else {
+				Logc(ctx).WithFields(logFields).Info("Failed volume deleted.")
+				return true
+			}
+		}
+	}
+
+	// Delete a volume that is taking too long, so it isn't left behind once the caller gives up on it.  A failed
+	// volume that couldn't be deleted is left to the cleanup of failed volumes instead.
+	if time.Since(pending.startTime) > d.volumeCreateTimeout {
+		if volume.ProvisioningState == api.StateError {
+			Logc(ctx).WithFields(logFields).Warning("Stopped watching failed volume that could not be deleted.")
+			return true
+		}
+		if errDelete := d.SDK.DeleteVolume(ctx, volume); errDelete != nil {
+			Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
+				"New volume that is taking too long could not be deleted.")
+			return false
+		}
+		Logc(ctx).WithFields(logFields).Warning("Deleted new volume that was taking too long.")
+		return true
+	}
+
+	return false
+}
+
+// waitForVolumeCreate waits for volume creation to complete by reaching the Available state.  If the
+// volume reaches a terminal state (Error), the volume is deleted.  If the wait times out and the volume
+// is still creating, a VolumeCreatingError is returned so the caller may try again.
+func (d *NASStorageDriver) waitForVolumeCreate(ctx context.Context, volume *api.FileSystem) error {
+	startTime := time.Now()
+	state, err := d.SDK.WaitForVolumeState(
+		ctx, volume, api.StateAvailable, []string{api.StateError}, d.effectiveTimeout(ctx, d.volumeCreateTimeout))
+
+	// No state means the new volume could not be read at all, which is not the same as the volume reaching an
+	// error state, so give what may be a transient read failure right after creation a few more chances
+	for attempt := 1; err != nil && state == "" && ctx.Err() == nil &&
+		attempt < maxVolumeCreateReadAttempts; attempt++ {
+		Logc(ctx).WithField("volume", volume.CreationToken).WithError(err).Debug(
+			"Could not read state of new volume, retrying.")
+		state, err = d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable, []string{api.StateError},
+			d.effectiveTimeout(ctx, volumeCreateReadRetryTimeout))
+	}
+
+	if err == nil {
+		d.provisioningLatency.record(volume.ServiceLevel, volume.CapacityPool, time.Since(startTime))
+	} else {
+
+		logFields := LogFields{"volume": volume.CreationToken}
+
+		// The caller gave up, so leave the volume alone and let the caller try again later
+		if ctx.Err() != nil {
+			Logc(ctx).WithFields(logFields).WithError(err).Warning("Stopped waiting for new volume.")
+			return errors.VolumeCreatingError(fmt.Sprintf("volume %s is still being created; %v",
+				volume.CreationToken, ctx.Err()))
+		}
+
+		switch state {
+
+		case "":
+			// The volume is probably still being created, so leave it alone and let the caller try again later
+			Logc(ctx).WithFields(logFields).WithError(err).Warning("Could not read state of new volume.")
+			return errors.VolumeCreatingError(err.Error())
+
+		case api.StateAccepted, api.StateCreating:
+			Logc(ctx).WithFields(logFields).Debugf("Volume is in %s state.", state)
+			return errors.VolumeCreatingError(err.Error())
+
+		case api.StateDeleting:
+			// Wait for deletion to complete
+			_, errDelete := d.SDK.WaitForVolumeState(
+				ctx, volume, api.StateDeleted, []string{api.StateError}, d.effectiveTimeout(ctx, d.defaultTimeout()))
+			if errDelete != nil {
+				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
+					"Volume could not be cleaned up and must be manually deleted.")
+			}
+
+		case api.StateError:
+			// Delete a failed volume
+			errDelete := d.SDK.DeleteVolume(ctx, volume)
+			if errDelete != nil {
+				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
+					"Volume could not be cleaned up and must be manually deleted.")
+			} else {
+				Logc(ctx).WithField("volume", volume.Name).Info("Volume deleted.")
+			}
+
+		case api.StateMoving, api.StateReverting:
+			fallthrough
+
+		default:
+			Logc(ctx).WithFields(logFields).Errorf("unexpected volume state %s found for volume", state)
+		}
+	}
+
+	return nil
+}
+
+// VolumeUsage returns the provisioned size of a volume and the space consumed by it and its snapshots.  The
+// usage is read from ANF and the volume's metrics no more often than the maximum cache age, so that it may
+// be collected frequently.
+func (d *NASStorageDriver) VolumeUsage(ctx context.Context, volConfig *storage.VolumeConfig) (*VolumeUsage, error) {
+	name := volConfig.InternalName
+	fields := LogFields{
+		"Method": "VolumeUsage",
+		"Type":   "NASStorageDriver",
+		"name":   name,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> VolumeUsage")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< VolumeUsage")
+
+	if usage, ok := d.volumeUsage.get(name, d.SDK.CacheStatus().MaxCacheAge); ok {
+		return &usage, nil
+	}
+
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s; %v", name, err)
+	}
+
+	usage, err := d.readVolumeUsage(ctx, volume)
+	if err != nil {
+		return nil, fmt.Errorf("could not read usage of volume %s; %v", name, err)
+	}
+
+	return &usage, nil
+}
+
+// ProvisioningLatencyByServiceLevel returns the time taken by volumes created by this backend to become
+// available, aggregated by service level.  Nothing is collected unless metrics are enabled.
+func (d *NASStorageDriver) ProvisioningLatencyByServiceLevel() map[string]ProvisioningLatency {
+	return d.provisioningLatency.byServiceLevel()
+}
+
+// ProvisioningLatencyByCapacityPool returns the time taken by volumes created by this backend to become
+// available, keyed by service level and then capacity pool.  Nothing is collected unless metrics are enabled.
+func (d *NASStorageDriver) ProvisioningLatencyByCapacityPool() map[string]map[string]ProvisioningLatency {
+	return d.provisioningLatency.byCapacityPool()
+}
+
+// Destroy deletes a volume.
+func (d *NASStorageDriver) Destroy(ctx context.Context, volConfig *storage.VolumeConfig) error {
+	name := volConfig.InternalName
+
+	fields := LogFields{
+		"Method": "Destroy",
+		"Type":   "NASStorageDriver",
+		"name":   name,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Destroy")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Destroy")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// If volume doesn't exist, return success
+	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume %s; %v", name, err)
+	}
+	if !volumeExists {
+		Logc(ctx).WithField("volume", name).Warn("Volume already deleted.")
+		return nil
+	} else if extantVolume.ProvisioningState == api.StateDeleting {
+		// This is a retry, so give it more time before giving up again.
+		_, err = d.SDK.WaitForVolumeState(
+			ctx, extantVolume, api.StateDeleted, []string{api.StateError},
+			d.effectiveTimeout(ctx, d.volumeCreateTimeout))
+		return d.volumeDeleteWaitError(ctx, name, err)
+	}
+
+	// A replication destination may not be deleted until its replication is removed
+	if extantVolume.ReplicationEndpointType == api.ReplicationEndpointTypeDestination {
+		if err = d.SDK.DeleteReplication(ctx, extantVolume); err != nil {
+			return fmt.Errorf("could not delete replication of volume %s; %v", name, err)
+		}
+	}
+
+	// Delete the volume
+	if err = d.SDK.DeleteVolume(ctx, extantVolume); err != nil {
+		return err
+	}
+	d.volumeUsage.remove(name)
+
+	Logc(ctx).WithField("volume", extantVolume.Name).Info("Volume deleted.")
+
+	// Wait for deletion to complete
+	_, err = d.SDK.WaitForVolumeState(ctx, extantVolume, api.StateDeleted, []string{api.StateError},
+		d.effectiveTimeout(ctx, d.defaultTimeout()))
+	return d.volumeDeleteWaitError(ctx, name, err)
+}
+
+// volumeDeleteWaitError returns a VolumeDeletingError if waiting for a volume's deletion was cut short by the
+// caller, so that the caller may try again later, else it returns the specified error.
+func (d *NASStorageDriver) volumeDeleteWaitError(ctx context.Context, name string, err error) error {
+	if err != nil && ctx.Err() != nil {
+		Logc(ctx).WithField("volume", name).WithError(err).Warning("Stopped waiting for volume deletion.")
+		return errors.VolumeDeletingError(fmt.Sprintf("volume %s is still being deleted; %v", name, ctx.Err()))
+	}
+	return err
+}
+
+// Publish the volume to the host specified in publishInfo.  This method may or may not be running on the host
+// where the volume will be mounted, so it should limit itself to updating access rules, initiator groups, etc.
+// that require some host identity (but not locality) as well as storage controller API access.
+func (d *NASStorageDriver) Publish(
+	ctx context.Context, volConfig *storage.VolumeConfig, publishInfo *utils.VolumePublishInfo,
+) error {
+	var volume *api.FileSystem
+	var err error
+
+	name := volConfig.InternalName
+	fields := LogFields{
+		"Method": "Publish",
+		"Type":   "NASStorageDriver",
+		"name":   name,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Publish")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Publish")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// If it's a RO clone, get source volume to populate publish info
+	if volConfig.ReadOnlyClone {
+		volume, err = d.SDK.VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal)
+		if err != nil {
+			return fmt.Errorf("could not find volume %s; %v", name, err)
+		}
+	} else {
+		// Get the volume
+		volume, err = d.SDK.Volume(ctx, volConfig)
+		if err != nil {
+			return fmt.Errorf("could not find volume %s; %v", name, err)
+		}
+
+		// Heal the ID on legacy volumes
+		if volConfig.InternalID == "" {
+			volConfig.InternalID = volume.ID
+		}
+	}
+
+	if len(volume.MountTargets) == 0 {
+		return fmt.Errorf("volume %s has no mount targets", name)
+	}
+
+	// Determine mount options (volume config wins, followed by backend config)
+	mountOptions := d.Config.NfsMountOptions
+	if volConfig.MountOptions != "" {
+		mountOptions = volConfig.MountOptions
+	}
+
+	// Add required fields for attaching SMB volume
+	if d.Config.NASType == sa.SMB {
+		mountTarget, err := mountTargetForProtocol(volume, sa.SMB)
+		if err != nil {
+			return err
+		}
+		publishInfo.SMBPath = volConfig.AccessInfo.SMBPath
+		publishInfo.SMBServer = mountTarget.ServerFqdn
+		publishInfo.FilesystemType = sa.SMB
+	} else {
+		mountTarget, err := mountTargetForProtocol(volume, sa.NFS)
+		if err != nil {
+			return err
+		}
+
+		// Add fields needed by Attach, using the FQDN rather than the IP address for Kerberos volumes
+		publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+		publishInfo.NfsServerIP = mountTarget.IPAddress
+		if volume.KerberosEnabled {
+			publishInfo.NfsServerIP = mountTarget.ServerFqdn
+		}
+		publishInfo.FilesystemType = sa.NFS
+		publishInfo.MountOptions = mountOptions
+
+		// Dual-protocol volumes are attached via NFS, but the SMB share is reported as well
+		if d.Config.NASType == NASTypeDual {
+			if smbMountTarget, smbErr := mountTargetForProtocol(volume, sa.SMB); smbErr == nil {
+				publishInfo.SMBPath = volConfig.AccessInfo.SMBPath
+				publishInfo.SMBServer = smbMountTarget.ServerFqdn
+			}
+		}
+	}
+
+	return nil
+}
+
+// CanSnapshot determines whether a snapshot as specified in the provided snapshot config may be taken.
+func (d *NASStorageDriver) CanSnapshot(_ context.Context, _ *storage.SnapshotConfig, _ *storage.VolumeConfig) error {
+	return nil
+}
+
+// GetSnapshot returns a snapshot of a volume, or an error if it does not exist.
+func (d *NASStorageDriver) GetSnapshot(
+	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
+) (*storage.Snapshot, error) {
+	internalSnapName := snapConfig.InternalName
+	internalVolName := snapConfig.VolumeInternalName
+	fields := LogFields{
+		"Method":       "GetSnapshot",
+		"Type":         "NASStorageDriver",
+		"snapshotName": internalSnapName,
+		"volumeName":   internalVolName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetSnapshot")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetSnapshot")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	}
+	if !volumeExists {
+		// The ANF volume is backed by ONTAP, so if the volume doesn't exist, neither does the snapshot.
+		return nil, nil
+	}
+
+	// Get the snapshot
+	snapshot, err := d.SDK.SnapshotForVolume(ctx, extantVolume, internalSnapName)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not check for existing snapshot; %v", err)
+	}
+
+	if snapshot.ProvisioningState != api.StateAvailable {
+		return nil, fmt.Errorf("snapshot %s state is %s", internalSnapName, snapshot.ProvisioningState)
+	}
+
+	created := snapshot.Created.UTC().Format(utils.TimestampFormat)
+
+	Logc(ctx).WithFields(LogFields{
+		"snapshotName": internalSnapName,
+		"volumeName":   internalVolName,
+		"created":      created,
+	}).Debug("Found snapshot.")
+
+	return &storage.Snapshot{
+		Config:    snapConfig,
+		Created:   created,
+		SizeBytes: snapshotSizeBytes(extantVolume),
+		State:     storage.SnapshotStateOnline,
+	}, nil
+}
+
+// GetSnapshots returns the list of snapshots associated with the specified volume.
+func (d *NASStorageDriver) GetSnapshots(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+) ([]*storage.Snapshot, error) {
+	internalVolName := volConfig.InternalName
+	fields := LogFields{
+		"Method":     "GetSnapshots",
+		"Type":       "NASStorageDriver",
+		"volumeName": internalVolName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetSnapshots")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetSnapshots")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s; %v", internalVolName, err)
+	}
+
+	snapshots, err := d.SDK.SnapshotsForVolume(ctx, volume)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotList := make([]*storage.Snapshot, 0)
+
+	for _, snapshot := range *snapshots {
+
+		// Filter out snapshots in an unavailable state
+		if snapshot.ProvisioningState != api.StateAvailable {
+			continue
+		}
+
+		snapshotList = append(snapshotList, &storage.Snapshot{
+			Config: &storage.SnapshotConfig{
+				Version:            tridentconfig.OrchestratorAPIVersion,
+				Name:               snapshot.Name,
+				InternalName:       snapshot.Name,
+				VolumeName:         volConfig.Name,
+				VolumeInternalName: volConfig.InternalName,
+			},
+			Created:   snapshot.Created.UTC().Format(utils.TimestampFormat),
+			SizeBytes: snapshotSizeBytes(volume),
+			State:     storage.SnapshotStateOnline,
+		})
+	}
+
+	return snapshotList, nil
+}
+
+// CreateSnapshot creates a snapshot for the given volume.
+func (d *NASStorageDriver) CreateSnapshot(
+	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
+) (*storage.Snapshot, error) {
+	internalSnapName := snapConfig.InternalName
+	internalVolName := snapConfig.VolumeInternalName
+	fields := LogFields{
+		"Method":       "CreateSnapshot",
+		"Type":         "NASStorageDriver",
+		"snapshotName": internalSnapName,
+		"volumeName":   internalVolName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateSnapshot")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateSnapshot")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Check if volume exists
+	volumeExists, sourceVolume, err := d.SDK.VolumeExists(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	}
+	if !volumeExists {
+		return nil, fmt.Errorf("volume %s does not exist", internalVolName)
+	}
+
+	// Create the snapshot
+	snapshot, err := d.SDK.CreateSnapshot(ctx, sourceVolume, internalSnapName)
+	if err != nil {
+		return nil, fmt.Errorf("could not create snapshot; %v", err)
+	}
+
+	// Wait for snapshot creation to complete
+	err = d.SDK.WaitForSnapshotState(
+		ctx, snapshot, sourceVolume, api.StateAvailable, []string{api.StateError},
+		d.effectiveTimeout(ctx, api.SnapshotTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"snapshotName": snapConfig.InternalName,
+		"volumeName":   snapConfig.VolumeInternalName,
+	}).Info("Snapshot created.")
+
+	// Offload the snapshot to a backup if the volume allows it
+	if sourceVolume.BackupEnabled {
+		if err = d.SDK.CreateBackup(ctx, sourceVolume, internalSnapName); err != nil {
+			return nil, fmt.Errorf("snapshot %s created, but could not be backed up; %v", internalSnapName, err)
+		}
+	}
+
+	return &storage.Snapshot{
+		Config:    snapConfig,
+		Created:   snapshot.Created.UTC().Format(utils.TimestampFormat),
+		SizeBytes: snapshotSizeBytes(sourceVolume),
+		State:     storage.SnapshotStateOnline,
+	}, nil
+}
+
+// snapshotSizeBytes returns the size reported for a snapshot of a volume.  ANF doesn't report how much
+// space a snapshot consumes, so this is the parent volume's quota.  That is an upper bound on what the
+// snapshot can consume, not its actual size.
+func snapshotSizeBytes(volume *api.FileSystem) int64 {
+	return volume.QuotaInBytes
+}
+
+// RestoreSnapshot restores a volume (in place) from a snapshot.
+func (d *NASStorageDriver) RestoreSnapshot(
+	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
+) error {
+	internalSnapName := snapConfig.InternalName
+	internalVolName := snapConfig.VolumeInternalName
+	fields := LogFields{
+		"Method":       "RestoreSnapshot",
+		"Type":         "NASStorageDriver",
+		"snapshotName": internalSnapName,
+		"volumeName":   internalVolName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> RestoreSnapshot")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< RestoreSnapshot")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s; %v", internalVolName, err)
+	}
+
+	// Get the snapshot
+	snapshot, err := d.SDK.SnapshotForVolume(ctx, volume, internalSnapName)
+	if err != nil {
+		return fmt.Errorf("unable to find snapshot %s: %v", internalSnapName, err)
+	}
+
+	// Do the restore
+	if err = d.SDK.RestoreSnapshot(ctx, volume, snapshot); err != nil {
+		return err
+	}
+
+	// Wait for snapshot deletion to complete
+	_, err = d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable,
+		[]string{api.StateError, api.StateDeleting, api.StateDeleted},
+		d.effectiveTimeout(ctx, api.DefaultSDKTimeout),
+	)
+	if err != nil && ctx.Err() != nil {
+		Logc(ctx).WithField("volume", internalVolName).WithError(err).Warning(
+			"Stopped waiting for snapshot restore.")
+		return errors.TimeoutError(fmt.Sprintf("volume %s is still being restored from snapshot %s; %v",
+			internalVolName, internalSnapName, ctx.Err()))
+	}
+	return err
+}
+
+// DeleteSnapshot deletes a snapshot of a volume.
+func (d *NASStorageDriver) DeleteSnapshot(
+	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
+) error {
+	internalSnapName := snapConfig.InternalName
+	internalVolName := snapConfig.VolumeInternalName
+	fields := LogFields{
+		"Method":       "DeleteSnapshot",
+		"Type":         "NASStorageDriver",
+		"snapshotName": internalSnapName,
+		"volumeName":   internalVolName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> DeleteSnapshot")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< DeleteSnapshot")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	}
+	if !volumeExists {
+		// The ANF volume is backed by ONTAP, so if the volume doesn't exist, neither does the snapshot.
+		return nil
+	}
+
+	snapshot, err := d.SDK.SnapshotForVolume(ctx, extantVolume, internalSnapName)
+	if err != nil {
+		// If the snapshot is already gone, return success
+		if errors.IsNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to find snapshot %s; %v", internalSnapName, err)
+	}
+
+	// Remove the snapshot's backup, if any, since the two share a lifecycle
+	if extantVolume.BackupEnabled {
+		if err = d.SDK.DeleteBackup(ctx, extantVolume, internalSnapName); err != nil {
+			return fmt.Errorf("could not delete backup of snapshot %s; %v", internalSnapName, err)
+		}
+	}
+
+	if err = d.SDK.DeleteSnapshot(ctx, extantVolume, snapshot); err != nil {
+		return err
+	}
+
+	// Wait for snapshot deletion to complete
+	return d.SDK.WaitForSnapshotState(
+		ctx, snapshot, extantVolume, api.StateDeleted, []string{api.StateError},
+		d.effectiveTimeout(ctx, api.SnapshotTimeout),
+	)
+}
+
+// List returns the list of volumes associated with this backend.
+func (d *NASStorageDriver) List(ctx context.Context) ([]string, error) {
+	fields := LogFields{"Method": "List", "Type": "NASStorageDriver"}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> List")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< List")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	volumes, err := d.SDK.Volumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.cleanUpFailedVolumes(ctx, *volumes)
+
+	prefix := *d.Config.StoragePrefix
+	volumeNames := make([]string, 0)
+
+	for _, volume := range *volumes {
+
+		// Filter out volumes in an unavailable state
+		switch volume.ProvisioningState {
+		case api.StateDeleting, api.StateDeleted, api.StateError:
+			continue
+		}
+
+		// Filter out volumes without the prefix (pass all if prefix is empty)
+		if !strings.HasPrefix(volume.CreationToken, prefix) {
+			continue
+		}
+
+		volumeName := volume.CreationToken[len(prefix):]
+		volumeNames = append(volumeNames, volumeName)
+	}
+
+	return volumeNames, nil
+}
+
+// cleanUpFailedVolumes deletes, or in dry-run mode only reports, the volumes managed by this backend that
+// have been in the error state for longer than the configured age.  ANF doesn't report when a volume failed,
+// so a volume's age is measured from when this driver first found it failed, and restarting Trident starts
+// the clock again.  Errors are logged rather than returned, so cleanup never interferes with the caller.
+func (d *NASStorageDriver) cleanUpFailedVolumes(ctx context.Context, volumes []*api.FileSystem) {
+	if d.Config.FailedVolumeCleanup == "" || d.Config.FailedVolumeCleanup == FailedVolumeCleanupDisabled {
+		return
+	}
+
+	d.failedVolumesLock.Lock()
+	defer d.failedVolumesLock.Unlock()
+
+	now := time.Now()
+	prefix := *d.Config.StoragePrefix
+	failedVolumes := make(map[string]time.Time)
+
+	for _, volume := range volumes {
+
+		if volume.ProvisioningState != api.StateError {
+			continue
+		}
+
+		// Never touch volumes that weren't created by this backend
+		if !strings.HasPrefix(volume.CreationToken, prefix) || !d.isManagedByThisBackend(volume) {
+			continue
+		}
+
+		failedTime, ok := d.failedVolumes[volume.FullName]
+		if !ok {
+			failedTime = now
+		}
+		failedVolumes[volume.FullName] = failedTime
+
+		failedFor := now.Sub(failedTime)
+		if failedFor < d.failedVolumeCleanupAge {
+			continue
+		}
+
+		logFields := LogFields{
+			"volume":        volume.FullName,
+			"creationToken": volume.CreationToken,
+			"failedFor":     failedFor.Truncate(time.Second).String(),
+		}
+
+		if d.Config.FailedVolumeCleanup == FailedVolumeCleanupDryRun {
+			Logc(ctx).WithFields(logFields).Info("Dry run, would delete failed volume.")
+			continue
+		}
+
+		if err := d.SDK.DeleteVolume(ctx, volume); err != nil {
+			Logc(ctx).WithFields(logFields).WithError(err).Error("Could not delete failed volume.")
+			continue
+		}
+
+		Logc(ctx).WithFields(logFields).Info("Deleted failed volume.")
+		delete(failedVolumes, volume.FullName)
+	}
+
+	// Forget volumes that are no longer failed, so they start over if they fail again
+	d.failedVolumes = failedVolumes
+}
+
+// Get tests for the existence of a volume.
+func (d *NASStorageDriver) Get(ctx context.Context, name string) error {
+	fields := LogFields{"Method": "Get", "Type": "NASStorageDriver"}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Get")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Get")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
 
+	if _, err := d.SDK.VolumeByCreationToken(ctx, name); err != nil {
+		return fmt.Errorf("could not get volume %s; %v", name, err)
+	}
+
+	return nil
+}
+
+// Resize increases a volume's quota.
+func (d *NASStorageDriver) Resize(ctx context.Context, volConfig *storage.VolumeConfig, sizeBytes uint64) error {
+	name := volConfig.InternalName
 	fields := LogFields{
-		"Method":   "CreateClone",
-		"Type":     "NASStorageDriver",
-		"name":     name,
-		"source":   source,
-		"snapshot": snapshot,
+		"Method":    "Resize",
+		"Type":      "NASStorageDriver",
+		"name":      name,
+		"sizeBytes": sizeBytes,
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateClone")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateClone")
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Resize")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Resize")
 
 	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
+	if err := d.refreshAzureResources(ctx); err != nil {
 		return fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
-	// ensure new volume doesn't exist, fail if so
-	// get source volume, fail if nonexistent or if wrong region
-	// if snapshot specified, read snapshots from source, fail if nonexistent
-	// if no snap specified, create one, fail if error
-	// create volume from snapshot
+	// Get the volume
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("could not find volume %s; %v", name, err)
+	}
 
-	// Make sure we got a valid name
-	if err := d.validateVolumeName(cloneVolConfig.Name); err != nil {
-		return err
+	// Heal the ID on legacy volumes
+	if volConfig.InternalID == "" {
+		volConfig.InternalID = volume.ID
 	}
 
-	// Make sure we got a valid creation token
-	if err := d.validateCreationToken(name); err != nil {
+	// If the volume state isn't Available, return an error
+	if volume.ProvisioningState != api.StateAvailable {
+		return fmt.Errorf("volume %s state is %s, not %s", name, volume.ProvisioningState, api.StateAvailable)
+	}
+
+	// Move the volume to a capacity pool of the requested service level, if that has changed
+	if volConfig.ServiceLevel != "" && !strings.EqualFold(volConfig.ServiceLevel, volume.ServiceLevel) {
+		if err = d.changeVolumeServiceLevel(ctx, volume, volConfig.ServiceLevel); err != nil {
+			return err
+		}
+		volConfig.InternalID = volume.ID
+		recordVolumePlacement(volConfig, volume)
+	}
+
+	// Show or hide the snapshot directory, if the backend's snapshotDir setting has changed
+	if err = d.updateVolumeSnapshotDir(ctx, volConfig, volume); err != nil {
 		return err
 	}
 
-	// Get the source volume
-	sourceVolume, err := d.SDK.Volume(ctx, sourceVolConfig)
-	if err != nil {
-		return fmt.Errorf("could not find source volume; %v", err)
+	// Keep any snapshot reserve the volume was created with
+	snapshotReserve := snapshotReserveFromVolume(volume)
+	quotaBytes := quotaForSnapshotReserve(sizeBytes, snapshotReserve)
+
+	volConfig.Size = strconv.FormatInt(dataSizeForSnapshotReserve(volume.QuotaInBytes, snapshotReserve), 10)
+
+	// If the volume is already the requested size, there's nothing to do
+	if int64(quotaBytes) == volume.QuotaInBytes {
+		return nil
 	}
 
-	// If source volume has kerberos enabled, check if ACP allows it.
-	if sourceVolume.KerberosEnabled {
-		if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
-			Logc(ctx).WithField(
-				"feature", acp.FeatureInflightEncryption,
-			).WithError(err).Errorf("Failed to clone volume.")
-			return fmt.Errorf("feature %s requires ACP; %w", acp.FeatureInflightEncryption, err)
+	// Make sure we're not shrinking the volume, unless the backend explicitly allows it
+	if int64(quotaBytes) < volume.QuotaInBytes {
+		if err = d.validateVolumeShrink(ctx, volume, quotaBytes); err != nil {
+			return err
 		}
 	}
 
-	// We always clone to the same capacity pool, so we can use the source volume's info to find
-	// a pre-existing clone more efficiently.
-	cloneID := api.CreateVolumeID(d.Config.SubscriptionID, sourceVolume.ResourceGroup, sourceVolume.NetAppAccount,
-		sourceVolume.CapacityPool, cloneVolConfig.Name)
+	// ANF can't convert between regular and large volumes, so each must stay within the sizes its type allows
+	if volume.IsLargeVolume && quotaBytes < MinimumANFLargeVolumeSizeBytes {
+		return fmt.Errorf("requested size %d is less than the minimum size %d of large volume %s",
+			quotaBytes, MinimumANFLargeVolumeSizeBytes, name)
+	} else if !volume.IsLargeVolume && quotaBytes > MaximumANFVolumeSizeBytes {
+		return fmt.Errorf("requested size %d is greater than the maximum size %d of regular volume %s",
+			quotaBytes, MaximumANFVolumeSizeBytes, name)
+	}
 
-	// If the volume already exists, bail out
-	volumeExists, extantVolume, err := d.SDK.VolumeExistsByID(ctx, cloneID)
-	if err != nil {
-		return fmt.Errorf("error checking for existing volume %s; %v", name, err)
+	// Make sure the request isn't above the configured maximum volume size (if any)
+	if _, _, err = drivers.CheckVolumeSizeLimits(ctx, sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return err
 	}
-	if volumeExists {
-		if extantVolume.ProvisioningState == api.StateCreating {
-			// This is a retry and the volume still isn't ready, so no need to wait further.
-			return errors.VolumeCreatingError(
-				fmt.Sprintf("volume state is still %s, not %s", api.StateCreating, api.StateAvailable))
+
+	oldSizeBytes := volume.QuotaInBytes
+
+	// Resize the volume
+	if err = d.SDK.ResizeVolume(ctx, volume, int64(quotaBytes)); err != nil {
+		return err
+	}
+
+	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
+
+	// Scale the throughput of manual QoS volumes along with their size, if so configured
+	if d.Config.ScaleThroughputOnResize && volume.ThroughputMibps > 0 && oldSizeBytes > 0 {
+		if cPool := d.capacityPoolForVolume(volume); cPool != nil && strings.EqualFold(cPool.QosType,
+			api.QosTypeManual) {
+			throughput := volume.ThroughputMibps * float32(quotaBytes) / float32(oldSizeBytes)
+			if err = d.SDK.ModifyVolumeThroughput(ctx, volume, throughput); err != nil {
+				return fmt.Errorf("volume %s resized, but could not change its throughput; %v", name, err)
+			}
 		}
-		return drivers.NewVolumeExistsError(name)
 	}
 
-	var sourceSnapshot *api.Snapshot
+	return nil
+}
 
-	if snapshot != "" {
+// capacityPoolForVolume returns the discovered capacity pool containing a volume, or nil if it isn't known.
+func (d *NASStorageDriver) capacityPoolForVolume(volume *api.FileSystem) *api.CapacityPool {
+	for _, cPool := range *d.SDK.CapacityPools() {
+		if cPool.ResourceGroup == volume.ResourceGroup && cPool.NetAppAccount == volume.NetAppAccount &&
+			cPool.Name == volume.CapacityPool {
+			return cPool
+		}
+	}
+	return nil
+}
 
-		// Get the source snapshot
-		sourceSnapshot, err = d.SDK.SnapshotForVolume(ctx, sourceVolume, snapshot)
-		if err != nil {
-			return fmt.Errorf("could not find source snapshot; %v", err)
+// changeVolumeServiceLevel moves a volume to a capacity pool of the specified service level in the
+// volume's NetApp account, and waits for the volume to become available again.  Only capacity pools
+// matching this backend's storage pools are considered, so a volume is never moved outside the backend.
+func (d *NASStorageDriver) changeVolumeServiceLevel(
+	ctx context.Context, volume *api.FileSystem, serviceLevel string,
+) error {
+	candidates := d.SDK.CapacityPoolsForStoragePools(ctx)
+
+	// The candidates are unordered, so sort them to make the choice of capacity pool deterministic
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].FullName < candidates[j].FullName })
+
+	// Only auto QoS capacity pools are considered, since a volume moved into a manual QoS pool needs a throughput
+	var cPool *api.CapacityPool
+	for _, candidate := range filterCapacityPoolsByQosType(candidates, false) {
+		if candidate.ResourceGroup == volume.ResourceGroup && candidate.NetAppAccount == volume.NetAppAccount &&
+			strings.EqualFold(candidate.ServiceLevel, serviceLevel) {
+			cPool = candidate
+			break
 		}
+	}
+	if cPool == nil {
+		return fmt.Errorf("no capacity pool with service level %s found in NetApp account %s; cannot change "+
+			"service level of volume %s", serviceLevel,
+			api.CreateNetappAccountFullName(volume.ResourceGroup, volume.NetAppAccount), volume.CreationToken)
+	}
 
-		// Ensure snapshot is in a usable state
-		if sourceSnapshot.ProvisioningState != api.StateAvailable {
-			return fmt.Errorf("source snapshot state is %s, it must be %s",
-				sourceSnapshot.ProvisioningState, api.StateAvailable)
+	cPoolFullName := api.CreateCapacityPoolFullName(cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name)
+
+	Logc(ctx).WithFields(LogFields{
+		"volume":          volume.CreationToken,
+		"oldServiceLevel": volume.ServiceLevel,
+		"newServiceLevel": cPool.ServiceLevel,
+		"capacityPool":    cPoolFullName,
+	}).Info("Changing volume service level.")
+
+	if err := d.SDK.RelocateVolume(ctx, volume, cPool); err != nil {
+		return fmt.Errorf("could not move volume %s to capacity pool %s; %v", volume.CreationToken, cPoolFullName, err)
+	}
+
+	if _, err := d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable, []string{api.StateError},
+		d.effectiveTimeout(ctx, d.defaultTimeout())); err != nil {
+		return fmt.Errorf("volume %s did not become available after changing service level; %v",
+			volume.CreationToken, err)
+	}
+
+	return nil
+}
+
+// Update applies the requested changes to an existing volume.  The only change ANF supports is upgrading
+// the volume's network features from Basic to Standard, to match those of the volume's storage pool.
+func (d *NASStorageDriver) Update(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+	updateInfo *utils.VolumeUpdateInfo, allVolumes map[string]*storage.Volume,
+) (map[string]*storage.Volume, error) {
+	name := volConfig.InternalName
+	fields := LogFields{
+		"Method":     "Update",
+		"Type":       "NASStorageDriver",
+		"name":       name,
+		"updateInfo": updateInfo,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Update")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Update")
+
+	if updateInfo == nil {
+		return nil, errors.InvalidInputError(fmt.Sprintf("nothing to update for volume %s", name))
+	}
+	if updateInfo.SnapshotDirectory != "" {
+		return nil, errors.UnsupportedError(
+			fmt.Sprintf("changing the snapshot directory of volume %s is not supported", name))
+	}
+	if !updateInfo.UpgradeNetworkFeatures {
+		return nil, errors.InvalidInputError(fmt.Sprintf("nothing to update for volume %s", name))
+	}
+	if volConfig.ImportNotManaged {
+		return nil, errors.UnsupportedError(fmt.Sprintf("volume %s is not managed by Trident", name))
+	}
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	// Get the volume
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s; %v", name, err)
+	}
+
+	// If the volume state isn't Available, return an error
+	if volume.ProvisioningState != api.StateAvailable {
+		return nil, fmt.Errorf("volume %s state is %s, not %s", name, volume.ProvisioningState, api.StateAvailable)
+	}
+
+	if err = d.upgradeVolumeNetworkFeatures(ctx, volume, d.volumeNetworkFeatures(volConfig, allVolumes)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// volumeNetworkFeatures returns the network features of the storage pool a volume was created in, or the
+// backend's network features if that pool is no longer known.
+func (d *NASStorageDriver) volumeNetworkFeatures(
+	volConfig *storage.VolumeConfig, allVolumes map[string]*storage.Volume,
+) string {
+	if vol, ok := allVolumes[volConfig.Name]; ok && vol != nil {
+		if pool, ok := d.getPools()[vol.Pool]; ok {
+			return pool.InternalAttributes()[NetworkFeatures]
 		}
+	}
+	return d.Config.NetworkFeatures
+}
+
+// upgradeVolumeNetworkFeatures upgrades a volume's network features from Basic to Standard, and waits for
+// the volume to become available again.  Nothing is done if the volume already has the requested network
+// features, and since ANF can't downgrade network features, any change other than an upgrade is rejected.
+func (d *NASStorageDriver) upgradeVolumeNetworkFeatures(
+	ctx context.Context, volume *api.FileSystem, networkFeatures string,
+) error {
+	if networkFeatures == "" || volume.NetworkFeatures == networkFeatures {
+		return nil
+	}
+
+	if volume.NetworkFeatures != api.NetworkFeaturesBasic || networkFeatures != api.NetworkFeaturesStandard {
+		return errors.UnsupportedError(fmt.Sprintf("cannot change network features of volume %s from %s to %s; "+
+			"only an upgrade from %s to %s is supported", volume.CreationToken, volume.NetworkFeatures,
+			networkFeatures, api.NetworkFeaturesBasic, api.NetworkFeaturesStandard))
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"volume":             volume.CreationToken,
+		"oldNetworkFeatures": volume.NetworkFeatures,
+		"newNetworkFeatures": networkFeatures,
+	}).Info("Upgrading volume network features.")
+
+	if err := d.SDK.UpgradeVolumeNetworkFeatures(ctx, volume); err != nil {
+		return fmt.Errorf("could not upgrade network features of volume %s; %v", volume.CreationToken, err)
+	}
+
+	if _, err := d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable, []string{api.StateError},
+		d.effectiveTimeout(ctx, d.defaultTimeout())); err != nil {
+		return fmt.Errorf("volume %s did not become available after upgrading network features; %v",
+			volume.CreationToken, err)
+	}
+
+	return nil
+}
+
+// updateVolumeSnapshotDir makes the snapshot directory of an available managed volume visible or hidden to
+// match the backend's snapshotDir setting, which may have changed since the volume was created, and waits for
+// the volume to become available again.  Only a setting that applies to all of the backend's volumes is
+// enforced, so volumes whose snapshot directory came from the default, a virtual pool, or a PVC annotation
+// are left alone.
+func (d *NASStorageDriver) updateVolumeSnapshotDir(
+	ctx context.Context, volConfig *storage.VolumeConfig, volume *api.FileSystem,
+) error {
+	if volConfig.ImportNotManaged || volume.ProvisioningState != api.StateAvailable {
+		return nil
+	}
 
-		Logc(ctx).WithFields(LogFields{
-			"snapshot": snapshot,
-			"source":   sourceVolume.Name,
-		}).Debug("Found source snapshot.")
+	snapshotDir, ok := d.backendSnapshotDir()
+	if !ok {
+		return nil
+	}
+	snapshotDirAccess, err := strconv.ParseBool(snapshotDir)
+	if err != nil {
+		return fmt.Errorf("invalid value for snapshotDir: %s", snapshotDir)
+	}
 
-	} else {
+	// The setting hasn't changed for a volume that was created or last updated with it
+	if volumeSnapshotDir, err := strconv.ParseBool(volConfig.SnapshotDir); err == nil &&
+		volumeSnapshotDir == snapshotDirAccess {
+		return nil
+	}
+	if volume.SnapshotDirectory == snapshotDirAccess {
+		return nil
+	}
 
-		// No source snapshot specified, so create one
-		snapName := time.Now().UTC().Format(storage.SnapshotNameFormat)
+	Logc(ctx).WithFields(LogFields{
+		"volume":         volume.CreationToken,
+		"oldSnapshotDir": volume.SnapshotDirectory,
+		"newSnapshotDir": snapshotDirAccess,
+	}).Info("Changing volume snapshot directory access.")
 
-		Logc(ctx).WithFields(LogFields{
-			"snapshot": snapName,
-			"source":   sourceVolume.Name,
-		}).Debug("Creating source snapshot.")
+	if err = d.SDK.ModifyVolume(ctx, volume, nil, nil, &snapshotDirAccess, nil); err != nil {
+		return fmt.Errorf("could not change snapshot directory access of volume %s; %v", volume.CreationToken, err)
+	}
 
-		sourceSnapshot, err = d.SDK.CreateSnapshot(ctx, sourceVolume, snapName)
-		if err != nil {
-			return fmt.Errorf("could not create source snapshot; %v", err)
-		}
+	if _, err = d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable, []string{api.StateError},
+		d.effectiveTimeout(ctx, d.defaultTimeout())); err != nil {
+		return fmt.Errorf("volume %s did not become available after changing snapshot directory access; %v",
+			volume.CreationToken, err)
+	}
 
-		// Wait for snapshot creation to complete
-		err = d.SDK.WaitForSnapshotState(
-			ctx, sourceSnapshot, sourceVolume, api.StateAvailable, []string{api.StateError}, api.SnapshotTimeout)
-		if err != nil {
-			return err
-		}
+	volume.SnapshotDirectory = snapshotDirAccess
+	volConfig.SnapshotDir = strconv.FormatBool(snapshotDirAccess)
 
-		// Re-fetch the snapshot to populate the properties after create has completed
-		sourceSnapshot, err = d.SDK.SnapshotForVolume(ctx, sourceVolume, snapName)
-		if err != nil {
-			return fmt.Errorf("could not retrieve newly-created snapshot")
+	return nil
+}
+
+// backendSnapshotDir returns the snapshotDir setting that applies to every volume of this backend, if the
+// backend config sets one explicitly and no virtual pool overrides it.
+func (d *NASStorageDriver) backendSnapshotDir() (string, bool) {
+	if !d.snapshotDirConfigured {
+		return "", false
+	}
+	for _, vpool := range d.Config.Storage {
+		if vpool.SnapshotDir != "" && vpool.SnapshotDir != d.Config.SnapshotDir {
+			return "", false
 		}
+	}
+	return d.Config.SnapshotDir, true
+}
 
-		Logc(ctx).WithFields(LogFields{
-			"snapshot": sourceSnapshot.Name,
-			"source":   sourceVolume.Name,
-		}).Debug("Created source snapshot.")
+// validateVolumeShrink checks whether a volume may be shrunk to the requested size.  Shrinking is
+// disabled unless allowVolumeShrink is set, and ANF never permits a volume below its minimum size
+// or below the space it already consumes.
+func (d *NASStorageDriver) validateVolumeShrink(
+	ctx context.Context, volume *api.FileSystem, sizeBytes uint64,
+) error {
+	if !d.Config.AllowVolumeShrink {
+		return fmt.Errorf("requested size %d is less than existing volume size %d", sizeBytes, volume.QuotaInBytes)
 	}
 
-	// If RO clone is requested, don't create the volume on ANF backend and return nil
-	if cloneVolConfig.ReadOnlyClone {
-		// Return error , if snapshot directory is not enabled for RO clone
-		if !sourceVolume.SnapshotDirectory {
-			return fmt.Errorf("snapshot directory access is set to %t and readOnly clone is set to %t ",
-				sourceVolume.SnapshotDirectory, cloneVolConfig.ReadOnlyClone)
-		}
-		return nil
+	if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink); err != nil {
+		Logc(ctx).WithField("feature", acp.FeatureVolumeShrink).WithError(err).Error("Could not shrink volume.")
+		return fmt.Errorf("feature %s requires ACP; %w", acp.FeatureVolumeShrink, err)
 	}
 
-	var labels map[string]string
-	labels = d.updateTelemetryLabels(ctx, sourceVolume)
+	if minimumBytes := d.minimumVolumeSize(); sizeBytes < minimumBytes {
+		return fmt.Errorf("requested size %d is less than the minimum volume size %d", sizeBytes, minimumBytes)
+	}
 
-	if storage.IsStoragePoolUnset(storagePool) {
-		// Set the base label
-		storagePoolTemp := &storage.StoragePool{}
-		storagePoolTemp.SetAttributes(map[string]sa.Offer{
-			sa.Labels: sa.NewLabelOffer(d.Config.Labels),
-		})
-		poolLabels, err := storagePoolTemp.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
-		if err != nil {
-			return err
-		}
+	// The volume resource doesn't report consumption, so read it from the volume's metrics
+	usedBytes, _, err := d.SDK.VolumeUsedBytes(ctx, volume)
+	if err != nil {
+		return fmt.Errorf("could not determine the space used by volume %s; %v", volume.CreationToken, err)
+	}
+	volume.UsedBytes = usedBytes
 
-		labels[storage.ProvisioningLabelTag] = poolLabels
+	if int64(sizeBytes) < volume.UsedBytes {
+		return fmt.Errorf("requested size %d is less than the %d bytes used by volume %s",
+			sizeBytes, volume.UsedBytes, volume.CreationToken)
 	}
 
-	Logc(ctx).WithFields(LogFields{
-		"creationToken":   name,
-		"sourceVolume":    sourceVolume.CreationToken,
-		"sourceSnapshot":  sourceSnapshot.Name,
-		"unixPermissions": sourceVolume.UnixPermissions,
-		"networkFeatures": sourceVolume.NetworkFeatures,
-	}).Debug("Cloning volume.")
+	return nil
+}
 
-	createRequest := &api.FilesystemCreateRequest{
-		ResourceGroup:     sourceVolume.ResourceGroup,
-		NetAppAccount:     sourceVolume.NetAppAccount,
-		CapacityPool:      sourceVolume.CapacityPool,
-		Name:              cloneVolConfig.Name,
-		SubnetID:          sourceVolume.SubnetID,
-		CreationToken:     name,
-		Labels:            labels,
-		ProtocolTypes:     sourceVolume.ProtocolTypes,
-		QuotaInBytes:      sourceVolume.QuotaInBytes,
-		SnapshotDirectory: sourceVolume.SnapshotDirectory,
-		SnapshotID:        sourceSnapshot.SnapshotID,
-		NetworkFeatures:   sourceVolume.NetworkFeatures,
+// mirrorDestination returns a local volume that is the destination of a replication relationship.
+func (d *NASStorageDriver) mirrorDestination(ctx context.Context, localInternalVolumeName string) (*api.FileSystem, error) {
+	if localInternalVolumeName == "" {
+		return nil, fmt.Errorf("invalid volume name")
 	}
 
-	// Add unix permissions and export policy fields only to NFS volume
-	if d.Config.NASType == sa.NFS {
-		createRequest.ExportPolicy = sourceVolume.ExportPolicy
-		createRequest.UnixPermissions = sourceVolume.UnixPermissions
-		createRequest.KerberosEnabled = sourceVolume.KerberosEnabled
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
-	// Clone the volume
-	clone, err := d.SDK.CreateVolume(ctx, createRequest)
+	volume, err := d.SDK.VolumeByCreationToken(ctx, localInternalVolumeName)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("could not find volume %s; %v", localInternalVolumeName, err)
 	}
 
-	// Always save the ID so we can find the volume efficiently later
-	cloneVolConfig.InternalID = clone.ID
+	if volume.VolumeType != api.VolumeTypeDataProtection {
+		return nil, fmt.Errorf("volume %s is not a data protection volume", localInternalVolumeName)
+	}
 
-	// Wait for creation to complete so that the mount targets are available
-	return d.waitForVolumeCreate(ctx, clone)
+	return volume, nil
 }
 
-// Import finds an existing volume and makes it available for containers.  If ImportNotManaged is false, the
-// volume is fully brought under Trident's management.
-func (d *NASStorageDriver) Import(ctx context.Context, volConfig *storage.VolumeConfig, originalName string) error {
+// EstablishMirror authorizes the remote (source) volume to replicate to the local data protection
+// volume, which must have been created as a mirror destination.  Replication policies are not
+// meaningful to ANF, and the replication schedule is fixed when the destination volume is created.
+func (d *NASStorageDriver) EstablishMirror(
+	ctx context.Context, localInternalVolumeName, remoteVolumeHandle, _, _ string,
+) error {
 	fields := LogFields{
-		"Method":       "Import",
+		"Method":       "EstablishMirror",
 		"Type":         "NASStorageDriver",
-		"originalName": originalName,
-		"newName":      volConfig.InternalName,
+		"localVolume":  localInternalVolumeName,
+		"remoteVolume": remoteVolumeHandle,
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Import")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Import")
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> EstablishMirror")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< EstablishMirror")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	volume, err := d.mirrorDestination(ctx, localInternalVolumeName)
+	if err != nil {
+		return err
 	}
 
-	// Get the volume
-	volume, err := d.SDK.VolumeByCreationToken(ctx, originalName)
+	status, err := d.SDK.ReplicationStatus(ctx, volume)
 	if err != nil {
-		return fmt.Errorf("could not find volume %s; %v", originalName, err)
+		return err
 	}
 
-	// Don't allow import for dual-protocol volume.
-	// For dual-protocol volume the ProtocolTypes has two values [NFSv3, CIFS]
-	if len(volume.ProtocolTypes) > 1 {
-		return fmt.Errorf("trident doesn't support importing a dual-protocol volume '%s'", originalName)
+	if status.MirrorState == api.MirrorStateUninitialized {
+		if status.RelationshipStatus != api.RelationshipStatusTransferring {
+			if err = d.SDK.AuthorizeReplication(ctx, remoteVolumeHandle, volume); err != nil {
+				return fmt.Errorf("could not authorize replication to volume %s; %v", localInternalVolumeName, err)
+			}
+		}
+		return errors.NotReadyError()
 	}
 
-	// Ensure the volume may be imported by a capacity pool managed by this backend
-	if err = d.SDK.EnsureVolumeInValidCapacityPool(ctx, volume); err != nil {
-		return err
+	return nil
+}
+
+// ReestablishMirror resumes replication to a local data protection volume whose replication was broken.
+func (d *NASStorageDriver) ReestablishMirror(
+	ctx context.Context, localInternalVolumeName, remoteVolumeHandle, _, _ string,
+) error {
+	fields := LogFields{
+		"Method":       "ReestablishMirror",
+		"Type":         "NASStorageDriver",
+		"localVolume":  localInternalVolumeName,
+		"remoteVolume": remoteVolumeHandle,
 	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> ReestablishMirror")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< ReestablishMirror")
 
-	// Get the volume size
-	volConfig.Size = strconv.FormatInt(volume.QuotaInBytes, 10)
+	volume, err := d.mirrorDestination(ctx, localInternalVolumeName)
+	if err != nil {
+		return err
+	}
 
-	Logc(ctx).WithFields(LogFields{
-		"creationToken": volume.CreationToken,
-		"managed":       !volConfig.ImportNotManaged,
-		"state":         volume.ProvisioningState,
-		"capacityPool":  volume.CapacityPool,
-		"sizeBytes":     volume.QuotaInBytes,
-	}).Debug("Found volume to import.")
+	status, err := d.SDK.ReplicationStatus(ctx, volume)
+	if err != nil {
+		return err
+	}
 
-	var snapshotDirAccess bool
-	// Modify the volume if Trident will manage its lifecycle
-	if !volConfig.ImportNotManaged {
-		if volConfig.SnapshotDir != "" {
-			if snapshotDirAccess, err = strconv.ParseBool(volConfig.SnapshotDir); err != nil {
-				return fmt.Errorf("could not import volume %s, snapshot directory access is set to %s",
-					originalName, volConfig.SnapshotDir)
-			}
+	switch status.MirrorState {
+	case api.MirrorStateBroken:
+		if err = d.SDK.ResyncReplication(ctx, volume); err != nil {
+			return fmt.Errorf("could not resync replication to volume %s; %v", localInternalVolumeName, err)
 		}
+		return errors.NotReadyError()
+	case api.MirrorStateUninitialized:
+		return errors.NotReadyError()
+	}
 
-		// Check for kerberos option from backend config
-		kerberos := d.Config.Kerberos
-		if kerberos != "" {
-			// Check if this volume landed on a Kerberos-enabled storage pool. If so, check if ACP allows it.
-			if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption); err != nil {
-				Logc(ctx).WithField(
-					"feature", acp.FeatureInflightEncryption,
-				).WithError(err).Errorf("Could not import volume.")
-				return fmt.Errorf("feature %s requires ACP; %w", acp.FeatureInflightEncryption, err)
-			}
-		}
+	return nil
+}
 
-		if kerberos != "" && !volume.KerberosEnabled {
-			return fmt.Errorf("could not import non-kerberos volume '%s', on a kerberos enabled backend", originalName)
-		}
+// PromoteMirror breaks replication to a local data protection volume, making it writable.  If a snapshot
+// is specified, the break is deferred until that snapshot has been replicated, in which case true is returned.
+func (d *NASStorageDriver) PromoteMirror(
+	ctx context.Context, localInternalVolumeName, remoteVolumeHandle, snapshotName string,
+) (bool, error) {
+	fields := LogFields{
+		"Method":       "PromoteMirror",
+		"Type":         "NASStorageDriver",
+		"localVolume":  localInternalVolumeName,
+		"remoteVolume": remoteVolumeHandle,
+		"snapshot":     snapshotName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> PromoteMirror")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< PromoteMirror")
 
-		if kerberos == "" && volume.KerberosEnabled {
-			return fmt.Errorf("could not import kerberos volume '%s', on a non-kerberos enabled backend", originalName)
-		}
+	volume, err := d.mirrorDestination(ctx, localInternalVolumeName)
+	if err != nil {
+		return false, err
+	}
 
-		modifiedExportRule := api.ExportRule{}
-		switch kerberos {
-		case api.MountOptionKerberos5:
-			modifiedExportRule.Nfsv41 = true
-			modifiedExportRule.Kerberos5ReadWrite = true
-		case api.MountOptionKerberos5I:
-			modifiedExportRule.Nfsv41 = true
-			modifiedExportRule.Kerberos5IReadWrite = true
-		case api.MountOptionKerberos5P:
-			modifiedExportRule.Nfsv41 = true
-			modifiedExportRule.Kerberos5PReadWrite = true
-		}
+	status, err := d.SDK.ReplicationStatus(ctx, volume)
+	if err != nil {
+		return false, err
+	}
 
-		// Update the volume labels
-		if storage.AllowPoolLabelOverwrite(storage.ProvisioningLabelTag, volume.Labels[storage.ProvisioningLabelTag]) {
-			volume.Labels[storage.ProvisioningLabelTag] = ""
-		}
-		labels := d.updateTelemetryLabels(ctx, volume)
+	if status.MirrorState == api.MirrorStateBroken {
+		return false, nil
+	}
 
-		if d.Config.NASType == sa.SMB && volume.ProtocolTypes[0] == api.ProtocolTypeCIFS {
-			if err = d.SDK.ModifyVolume(ctx, volume, labels, nil, &snapshotDirAccess, &modifiedExportRule); err != nil {
-				Logc(ctx).WithField("originalName", originalName).WithError(err).Error(
-					"Could not import volume, volume modify failed.")
-				return fmt.Errorf("could not import volume %s, volume modify failed; %v", originalName, err)
+	if snapshotName != "" {
+		_, snapshotName, err = storage.ParseSnapshotID(snapshotName)
+		if err != nil {
+			return false, err
+		}
+		if _, err = d.SDK.SnapshotForVolume(ctx, volume, snapshotName); err != nil {
+			if errors.IsNotFoundError(err) {
+				return true, nil
 			}
+			return false, err
+		}
+	}
 
-			Logc(ctx).WithFields(LogFields{
-				"name":          volume.Name,
-				"creationToken": volume.CreationToken,
-				"labels":        labels,
-			}).Info("Volume modified.")
+	if err = d.SDK.BreakReplication(ctx, volume); err != nil {
+		return false, fmt.Errorf("could not break replication to volume %s; %v", localInternalVolumeName, err)
+	}
 
-		} else if d.Config.NASType == sa.NFS && (volume.ProtocolTypes[0] == api.ProtocolTypeNFSv3 || volume.
-			ProtocolTypes[0] == api.ProtocolTypeNFSv41) {
-			// Update volume unix permissions.  Permissions specified in a PVC annotation take precedence
-			// over the backend's unixPermissions config.
-			unixPermissions := volConfig.UnixPermissions
-			if unixPermissions == "" {
-				unixPermissions = d.Config.UnixPermissions
-			}
-			if unixPermissions == "" {
-				unixPermissions = volume.UnixPermissions
-			}
-			if unixPermissions != "" {
-				if err = utils.ValidateOctalUnixPermissions(unixPermissions); err != nil {
-					return fmt.Errorf("could not import volume %s; %v", originalName, err)
-				}
-			}
+	return false, nil
+}
 
-			if err = d.SDK.ModifyVolume(ctx, volume, labels, &unixPermissions, &snapshotDirAccess, &modifiedExportRule); err != nil {
-				Logc(ctx).WithField("originalName", originalName).WithError(err).Error(
-					"Could not import volume, volume modify failed.")
-				return fmt.Errorf("could not import volume %s, volume modify failed; %v", originalName, err)
-			}
+// GetMirrorStatus returns the state of the replication relationship of a local data protection volume.
+func (d *NASStorageDriver) GetMirrorStatus(
+	ctx context.Context, localInternalVolumeName, remoteVolumeHandle string,
+) (string, error) {
+	// Empty remote means there is no mirror to check for
+	if remoteVolumeHandle == "" {
+		return "", nil
+	}
 
-			Logc(ctx).WithFields(LogFields{
-				"name":            volume.Name,
-				"creationToken":   volume.CreationToken,
-				"labels":          labels,
-				"unixPermissions": unixPermissions,
-			}).Info("Volume modified.")
-		} else {
-			return fmt.Errorf("could not import volume '%s' due to backend and volume mismatch", originalName)
-		}
+	volume, err := d.mirrorDestination(ctx, localInternalVolumeName)
+	if err != nil {
+		return "", err
+	}
 
-		if _, err = d.SDK.WaitForVolumeState(
-			ctx, volume, api.StateAvailable, []string{api.StateError}, d.defaultTimeout()); err != nil {
-			return fmt.Errorf("could not import volume %s; %v", originalName, err)
-		}
+	status, err := d.SDK.ReplicationStatus(ctx, volume)
+	if err != nil {
+		return "", err
 	}
 
-	// The ANF creation token cannot be changed, so use it as the internal name
-	volConfig.InternalName = originalName
+	switch status.MirrorState {
+	case api.MirrorStateUninitialized:
+		return v1.MirrorStateEstablishing, nil
+	case api.MirrorStateMirrored:
+		return v1.MirrorStateEstablished, nil
+	case api.MirrorStateBroken:
+		if status.RelationshipStatus == api.RelationshipStatusTransferring {
+			return v1.MirrorStateEstablishing, nil
+		}
+		return v1.MirrorStatePromoted, nil
+	}
 
-	// Always save the ID so we can find the volume efficiently later
-	volConfig.InternalID = volume.ID
+	Logc(ctx).WithField("mirrorState", status.MirrorState).Error("Unknown replication status returned.")
+	return "", nil
+}
 
-	return nil
+// VolumeReplicationStatus reports the state of an ANF volume's cross-region replication relationship.
+type VolumeReplicationStatus struct {
+	State              string `json:"state"`
+	EndpointType       string `json:"endpointType,omitempty"`
+	MirrorState        string `json:"mirrorState,omitempty"`
+	RelationshipStatus string `json:"relationshipStatus,omitempty"`
+	// LastTransferTime is when the newest replication snapshot was taken, or nil if there is none
+	LastTransferTime *time.Time    `json:"lastTransferTime,omitempty"`
+	Lag              time.Duration `json:"lag,omitempty"`
+	ErrorMessage     string        `json:"errorMessage,omitempty"`
 }
 
-// Rename changes the name of a volume.  Not supported by this driver.
-func (d *NASStorageDriver) Rename(ctx context.Context, name, newName string) error {
-	fields := LogFields{
-		"Method":  "Rename",
-		"Type":    "NASStorageDriver",
-		"name":    name,
-		"newName": newName,
+// VolumeReplicationStatus returns the state of a volume's replication relationship, including when data was
+// last transferred and how far the replica lags behind, so that DR setups may be monitored.  A volume that
+// isn't replicated is reported as having no relationship.
+func (d *NASStorageDriver) VolumeReplicationStatus(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+) (*VolumeReplicationStatus, error) {
+	name := volConfig.InternalName
+	fields := LogFields{
+		"Method": "VolumeReplicationStatus",
+		"Type":   "NASStorageDriver",
+		"name":   name,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> VolumeReplicationStatus")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< VolumeReplicationStatus")
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Rename")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Rename")
 
-	// Rename is only needed for the import workflow, and we aren't currently renaming the
-	// ANF volume when importing, so do nothing here lest we set the volume name incorrectly
-	// during an import failure cleanup.
-	return nil
-}
+	volume, err := d.SDK.Volume(ctx, volConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not find volume %s; %v", name, err)
+	}
 
-// getTelemetryLabels builds the standard telemetry labels that are set on each volume.
-func (d *NASStorageDriver) getTelemetryLabels(ctx context.Context) string {
-	telemetry := map[string]Telemetry{drivers.TridentLabelTag: *d.telemetry}
+	if volume.ReplicationEndpointType == "" {
+		return &VolumeReplicationStatus{State: ReplicationStateNone}, nil
+	}
 
-	telemetryJSON, err := json.Marshal(telemetry)
+	replicationStatus, err := d.SDK.ReplicationStatus(ctx, volume)
 	if err != nil {
-		Logc(ctx).Errorf("Failed to marshal telemetry: %+v", telemetry)
+		return nil, fmt.Errorf("could not get replication status of volume %s; %v", name, err)
 	}
 
-	return strings.ReplaceAll(string(telemetryJSON), " ", "")
-}
+	status := &VolumeReplicationStatus{
+		EndpointType:       volume.ReplicationEndpointType,
+		MirrorState:        replicationStatus.MirrorState,
+		RelationshipStatus: replicationStatus.RelationshipStatus,
+		ErrorMessage:       replicationStatus.ErrorMessage,
+	}
 
-// updateTelemetryLabels updates a volume's labels to include the standard telemetry labels.
-func (d *NASStorageDriver) updateTelemetryLabels(ctx context.Context, volume *api.FileSystem) map[string]string {
-	if volume.Labels == nil {
-		volume.Labels = make(map[string]string)
+	// Each transfer leaves a snapshot behind, so the newest one shows when data was last replicated
+	snapshots, err := d.SDK.SnapshotsForVolume(ctx, volume)
+	if err != nil {
+		return nil, fmt.Errorf("could not list snapshots of volume %s; %v", name, err)
+	}
+	for _, snapshot := range *snapshots {
+		if !strings.HasPrefix(snapshot.Name, replicationSnapshotPrefix) {
+			continue
+		}
+		if status.LastTransferTime == nil || snapshot.Created.After(*status.LastTransferTime) {
+			created := snapshot.Created
+			status.LastTransferTime = &created
+		}
+	}
+	if status.LastTransferTime != nil {
+		status.Lag = time.Since(*status.LastTransferTime).Truncate(time.Second)
 	}
 
-	newLabels := volume.Labels
-	newLabels[drivers.TridentLabelTag] = d.getTelemetryLabels(ctx)
-	return newLabels
+	switch {
+	case !replicationStatus.Healthy,
+		replicationStatus.MirrorState == api.MirrorStateBroken &&
+			replicationStatus.RelationshipStatus != api.RelationshipStatusTransferring:
+		status.State = ReplicationStateBroken
+	case replicationStatus.MirrorState != api.MirrorStateMirrored || status.LastTransferTime == nil:
+		status.State = ReplicationStateInitializing
+	default:
+		// Missing a whole scheduled transfer means the replica is falling behind
+		status.State = ReplicationStateHealthy
+		if interval, ok := replicationScheduleIntervals[volume.ReplicationSchedule]; ok && status.Lag > 2*interval {
+			status.State = ReplicationStateLagging
+		}
+	}
+
+	return status, nil
 }
 
-// waitForVolumeCreate waits for volume creation to complete by reaching the Available state.  If the
-// volume reaches a terminal state (Error), the volume is deleted.  If the wait times out and the volume
-// is still creating, a VolumeCreatingError is returned so the caller may try again.
-func (d *NASStorageDriver) waitForVolumeCreate(ctx context.Context, volume *api.FileSystem) error {
-	state, err := d.SDK.WaitForVolumeState(
-		ctx, volume, api.StateAvailable, []string{api.StateError}, d.volumeCreateTimeout)
-	if err != nil {
+// ReleaseMirror is a no-op for ANF, since deleting a replication from the destination volume also
+// releases the source volume.
+func (d *NASStorageDriver) ReleaseMirror(_ context.Context, _ string) error {
+	return nil
+}
 
-		logFields := LogFields{"volume": volume.CreationToken}
+// GetReplicationDetails returns the replication policy, schedule, and SVM name of a replication
+// relationship.  Only the schedule is meaningful to ANF.
+func (d *NASStorageDriver) GetReplicationDetails(
+	ctx context.Context, localInternalVolumeName, _ string,
+) (string, string, string, error) {
+	volume, err := d.mirrorDestination(ctx, localInternalVolumeName)
+	if err != nil {
+		return "", "", "", err
+	}
 
-		switch state {
+	return "", volume.ReplicationSchedule, "", nil
+}
 
-		case api.StateAccepted, api.StateCreating:
-			Logc(ctx).WithFields(logFields).Debugf("Volume is in %s state.", state)
-			return errors.VolumeCreatingError(err.Error())
+// UpdateMirror is not supported by ANF, which replicates only on the schedule of the relationship.
+func (d *NASStorageDriver) UpdateMirror(_ context.Context, _, _ string) error {
+	return errors.UnsupportedError(fmt.Sprintf("mirror update is not supported by the %s driver", d.Name()))
+}
 
-		case api.StateDeleting:
-			// Wait for deletion to complete
-			_, errDelete := d.SDK.WaitForVolumeState(
-				ctx, volume, api.StateDeleted, []string{api.StateError}, d.defaultTimeout())
-			if errDelete != nil {
-				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
-					"Volume could not be cleaned up and must be manually deleted.")
-			}
+// CheckMirrorTransferState is not supported by ANF, which doesn't report replication transfer times.
+func (d *NASStorageDriver) CheckMirrorTransferState(_ context.Context, _ string) (*time.Time, error) {
+	return nil, errors.UnsupportedError(fmt.Sprintf("mirror transfer state is not supported by the %s driver",
+		d.Name()))
+}
 
-		case api.StateError:
-			// Delete a failed volume
-			errDelete := d.SDK.DeleteVolume(ctx, volume)
-			if errDelete != nil {
-				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
-					"Volume could not be cleaned up and must be manually deleted.")
-			} else {
-				Logc(ctx).WithField("volume", volume.Name).Info("Volume deleted.")
-			}
+// GetMirrorTransferTime is not supported by ANF, which doesn't report replication transfer times.
+func (d *NASStorageDriver) GetMirrorTransferTime(_ context.Context, _ string) (*time.Time, error) {
+	return nil, nil
+}
 
-		case api.StateMoving, api.StateReverting:
-			fallthrough
+// GetStorageBackendSpecs retrieves storage capabilities and register pools with specified backend.
+func (d *NASStorageDriver) GetStorageBackendSpecs(_ context.Context, backend storage.Backend) error {
+	backend.SetName(d.BackendName())
 
-		default:
-			Logc(ctx).WithFields(logFields).Errorf("unexpected volume state %s found for volume", state)
-		}
+	for _, pool := range d.getPools() {
+		pool.SetBackend(backend)
+		backend.AddStoragePool(pool)
 	}
 
 	return nil
 }
 
-// Destroy deletes a volume.
-func (d *NASStorageDriver) Destroy(ctx context.Context, volConfig *storage.VolumeConfig) error {
-	name := volConfig.InternalName
+// CreatePrepare is called prior to volume creation.  Currently its only role is to create the internal volume name.
+func (d *NASStorageDriver) CreatePrepare(ctx context.Context, volConfig *storage.VolumeConfig) {
+	volConfig.InternalName = d.GetInternalVolumeName(ctx, volConfig.Name)
+}
 
-	fields := LogFields{
-		"Method": "Destroy",
-		"Type":   "NASStorageDriver",
-		"name":   name,
-	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Destroy")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Destroy")
+// GetStorageBackendPhysicalPoolNames retrieves storage backend physical pools
+func (d *NASStorageDriver) GetStorageBackendPhysicalPoolNames(context.Context) []string {
+	return []string{}
+}
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
-	}
+// getStorageBackendPools determines any non-overlapping, discrete storage pools present on a driver's storage backend.
+func (d *NASStorageDriver) getStorageBackendPools(ctx context.Context) []drivers.ANFStorageBackendPool {
+	fields := LogFields{"Method": "getStorageBackendPools", "Type": "NASStorageDriver"}
+	Logc(ctx).WithFields(fields).Debug(">>>> getStorageBackendPools")
+	defer Logc(ctx).WithFields(fields).Debug("<<<< getStorageBackendPools")
 
-	// If volume doesn't exist, return success
-	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
-	if err != nil {
-		return fmt.Errorf("error checking for existing volume %s; %v", name, err)
-	}
-	if !volumeExists {
-		Logc(ctx).WithField("volume", name).Warn("Volume already deleted.")
-		return nil
-	} else if extantVolume.ProvisioningState == api.StateDeleting {
-		// This is a retry, so give it more time before giving up again.
-		_, err = d.SDK.WaitForVolumeState(
-			ctx, extantVolume, api.StateDeleted, []string{api.StateError}, d.volumeCreateTimeout)
-		return err
-	}
+	// For this driver, a discrete storage pool is composed of the following:
+	// 1. Resource Group - contains at least one netapp account; names are unique within a subscription.
+	// 2. NetappAccount - contains at least one capacity pool; names are unique to resource group OR region;
+	//   names can only be reused if resource group AND region differ from matching account name.
+	// 3. Capacity Pool - names are unique within a given ResourceGroup and NetappAccount.
 
-	// Delete the volume
-	if err = d.SDK.DeleteVolume(ctx, extantVolume); err != nil {
-		return err
+	// CapacityPoolsForStoragePools relies on an internal mapping of storage pools created from the driver config.
+	// If the behavior of that method should ever change, this method will need to change as well.
+	cPools := d.SDK.CapacityPoolsForStoragePools(ctx)
+	backendPools := make([]drivers.ANFStorageBackendPool, 0, len(cPools))
+	for _, cPool := range cPools {
+		Logc(ctx).WithFields(LogFields{
+			"resourceGroup": cPool.ResourceGroup,
+			"netappAccount": cPool.NetAppAccount,
+			"capacityPool":  cPool.Name,
+		}).Debug("Found storage backend pool.")
+
+		backendPools = append(backendPools, drivers.ANFStorageBackendPool{
+			SubscriptionID: d.Config.SubscriptionID,
+			ResourceGroup:  cPool.ResourceGroup,
+			NetappAccount:  cPool.NetAppAccount,
+			Location:       cPool.Location,
+			CapacityPool:   cPool.Name,
+		})
 	}
 
-	Logc(ctx).WithField("volume", extantVolume.Name).Info("Volume deleted.")
+	return backendPools
+}
 
-	// Wait for deletion to complete
-	_, err = d.SDK.WaitForVolumeState(ctx, extantVolume, api.StateDeleted, []string{api.StateError}, d.defaultTimeout())
-	return err
+// GetInternalVolumeName accepts the name of a volume being created and returns what the internal name
+// should be, depending on backend requirements and Trident's operating context.
+func (d *NASStorageDriver) GetInternalVolumeName(ctx context.Context, name string) string {
+	if tridentconfig.UsingPassthroughStore {
+		// With a passthrough store, the name mapping must remain reversible
+		return *d.Config.StoragePrefix + name
+	} else if csiRegex.MatchString(name) {
+		// If the name is from CSI (i.e. contains a UUID), just use it as-is
+		Logc(ctx).WithField("volumeInternal", name).Debug("Using volume name as internal name.")
+		return name
+	} else {
+		// Cloud volumes have strict limits on volume mount paths, so for cloud
+		// infrastructure like Trident, the simplest approach is to generate a
+		// UUID-based name with a prefix that won't exceed the 36-character limit.
+		return "anf-" + uuid.NewString()
+	}
 }
 
-// Publish the volume to the host specified in publishInfo.  This method may or may not be running on the host
-// where the volume will be mounted, so it should limit itself to updating access rules, initiator groups, etc.
-// that require some host identity (but not locality) as well as storage controller API access.
-func (d *NASStorageDriver) Publish(
-	ctx context.Context, volConfig *storage.VolumeConfig, publishInfo *utils.VolumePublishInfo,
-) error {
+// CreateFollowup is called after volume creation and sets the access info in the volume config.
+func (d *NASStorageDriver) CreateFollowup(ctx context.Context, volConfig *storage.VolumeConfig) error {
 	var volume *api.FileSystem
 	var err error
 
 	name := volConfig.InternalName
 	fields := LogFields{
-		"Method": "Publish",
+		"Method": "CreateFollowup",
 		"Type":   "NASStorageDriver",
 		"name":   name,
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Publish")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Publish")
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateFollowup")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateFollowup")
 
 	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
+	if err := d.refreshAzureResources(ctx); err != nil {
 		return fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
-	// If it's a RO clone, get source volume to populate publish info
+	// If it's a RO clone, get source volume to populate access details
 	if volConfig.ReadOnlyClone {
 		volume, err = d.SDK.VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal)
 		if err != nil {
@@ -1493,726 +5158,1250 @@ func (d *NASStorageDriver) Publish(
 		if err != nil {
 			return fmt.Errorf("could not find volume %s; %v", name, err)
 		}
+	}
 
-		// Heal the ID on legacy volumes
-		if volConfig.InternalID == "" {
-			volConfig.InternalID = volume.ID
-		}
+	// Ensure volume is in a good state
+	if volume.ProvisioningState != api.StateAvailable {
+		return fmt.Errorf("volume %s is in %s state, not %s", name, volume.ProvisioningState, api.StateAvailable)
 	}
 
 	if len(volume.MountTargets) == 0 {
-		return fmt.Errorf("volume %s has no mount targets", name)
+		return fmt.Errorf("volume %s has no mount targets", volConfig.InternalName)
 	}
 
-	// Determine mount options (volume config wins, followed by backend config)
-	mountOptions := d.Config.NfsMountOptions
-	if volConfig.MountOptions != "" {
-		mountOptions = volConfig.MountOptions
+	if volume.LdapEnabled {
+		Logc(ctx).WithField("volume", name).Debug("Volume looks up UNIX identities with LDAP.")
 	}
 
-	// Add required fields for attaching SMB volume
+	// Set the mount target based on the NASType, which also selects the protocol used for dual-protocol volumes
 	if d.Config.NASType == sa.SMB {
-		publishInfo.SMBPath = volConfig.AccessInfo.SMBPath
-		publishInfo.SMBServer = (volume.MountTargets)[0].ServerFqdn
-		publishInfo.FilesystemType = sa.SMB
+		mountTarget, err := mountTargetForProtocol(volume, sa.SMB)
+		if err != nil {
+			return err
+		}
+		volConfig.AccessInfo.SMBPath = constructVolumeAccessPath(volConfig, volume, sa.SMB)
+		volConfig.AccessInfo.SMBServer = mountTarget.ServerFqdn
+		volConfig.FileSystem = sa.SMB
 	} else {
-		// Add fields needed by Attach
-		publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
-		publishInfo.NfsServerIP = (volume.MountTargets)[0].IPAddress
-		publishInfo.FilesystemType = sa.NFS
-		publishInfo.MountOptions = mountOptions
+		mountTarget, err := mountTargetForProtocol(volume, sa.NFS)
+		if err != nil {
+			return err
+		}
+
+		// Use the FQDN rather than the IP address for Kerberos volumes
+		volConfig.AccessInfo.NfsPath = constructVolumeAccessPath(volConfig, volume, sa.NFS)
+		volConfig.AccessInfo.NfsServerIP = mountTarget.IPAddress
+		if volume.KerberosEnabled {
+			volConfig.AccessInfo.NfsServerIP = mountTarget.ServerFqdn
+		}
+		volConfig.FileSystem = sa.NFS
+
+		// Dual-protocol volumes are also reachable via SMB, so record that path too
+		if d.Config.NASType == NASTypeDual {
+			if smbMountTarget, smbErr := mountTargetForProtocol(volume, sa.SMB); smbErr == nil {
+				volConfig.AccessInfo.SMBPath = constructVolumeAccessPath(volConfig, volume, sa.SMB)
+				volConfig.AccessInfo.SMBServer = smbMountTarget.ServerFqdn
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetProtocol returns the protocol supported by this driver (File).
+func (d *NASStorageDriver) GetProtocol(context.Context) tridentconfig.Protocol {
+	return tridentconfig.File
+}
+
+// StoreConfig adds this backend's config to the persistent config struct, as needed by Trident's persistence layer.
+func (d *NASStorageDriver) StoreConfig(_ context.Context, b *storage.PersistentStorageBackendConfig) {
+	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
+	b.AzureConfig = &d.Config
+}
+
+// GetExternalConfig returns a clone of this backend's config, sanitized for external consumption.
+func (d *NASStorageDriver) GetExternalConfig(ctx context.Context) interface{} {
+	// Clone the config so we don't risk altering the original
+	var cloneConfig drivers.AzureNASStorageDriverConfig
+	drivers.Clone(ctx, d.Config, &cloneConfig)
+	cloneConfig.ClientSecret = utils.REDACTED // redact the Secret
+	cloneConfig.Credentials = map[string]string{
+		drivers.KeyName: utils.REDACTED,
+		drivers.KeyType: utils.REDACTED,
+	} // redact the credentials
+	return cloneConfig
+}
+
+// GetVolumeExternal queries the storage backend for all relevant info about
+// a single container volume managed by this driver and returns a VolumeExternal
+// representation of the volume.
+func (d *NASStorageDriver) GetVolumeExternal(ctx context.Context, name string) (*storage.VolumeExternal, error) {
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
-	// Replace server IP with FQDN for kerberos volume
-	if volume.KerberosEnabled {
-		publishInfo.NfsServerIP = (volume.MountTargets)[0].ServerFqdn
+	filesystem, err := d.SDK.VolumeByCreationToken(ctx, name)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	// The volume resource doesn't report consumption, so read it from the volume's metrics
+	if usage, err := d.readVolumeUsage(ctx, filesystem); err != nil {
+		Logc(ctx).WithField("volume", name).WithError(err).Warning("Could not read volume usage.")
+	} else {
+		filesystem.UsedBytes = usage.UsedBytes
+		filesystem.SnapshotUsedBytes = usage.SnapshotUsedBytes
+	}
 
-// CanSnapshot determines whether a snapshot as specified in the provided snapshot config may be taken.
-func (d *NASStorageDriver) CanSnapshot(_ context.Context, _ *storage.SnapshotConfig, _ *storage.VolumeConfig) error {
-	return nil
+	return d.getVolumeExternal(filesystem), nil
 }
 
-// GetSnapshot returns a snapshot of a volume, or an error if it does not exist.
-func (d *NASStorageDriver) GetSnapshot(
-	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) (*storage.Snapshot, error) {
-	internalSnapName := snapConfig.InternalName
-	internalVolName := snapConfig.VolumeInternalName
-	fields := LogFields{
-		"Method":       "GetSnapshot",
-		"Type":         "NASStorageDriver",
-		"snapshotName": internalSnapName,
-		"volumeName":   internalVolName,
+// readVolumeUsage returns the provisioned size of a volume and the space consumed by it and its snapshots,
+// reading the consumption from the volume's metrics no more often than the maximum cache age.
+func (d *NASStorageDriver) readVolumeUsage(ctx context.Context, volume *api.FileSystem) (VolumeUsage, error) {
+	if usage, ok := d.volumeUsage.get(volume.CreationToken, d.SDK.CacheStatus().MaxCacheAge); ok {
+		return usage, nil
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetSnapshot")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetSnapshot")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	usedBytes, snapshotUsedBytes, err := d.SDK.VolumeUsedBytes(ctx, volume)
+	if err != nil {
+		return VolumeUsage{}, err
 	}
 
-	// Get the volume
-	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
-	if err != nil {
-		return nil, fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	usage := VolumeUsage{
+		ProvisionedBytes:  volume.QuotaInBytes,
+		UsedBytes:         usedBytes,
+		SnapshotUsedBytes: snapshotUsedBytes,
 	}
-	if !volumeExists {
-		// The ANF volume is backed by ONTAP, so if the volume doesn't exist, neither does the snapshot.
-		return nil, nil
+	d.volumeUsage.put(volume.CreationToken, usage)
+
+	return usage, nil
+}
+
+// GetVolumeExternalWrappers queries the storage backend for all relevant info about
+// container volumes managed by this driver.  It then writes a VolumeExternal
+// representation of each volume to the supplied channel, closing the channel
+// when finished.
+func (d *NASStorageDriver) GetVolumeExternalWrappers(ctx context.Context, channel chan *storage.VolumeExternalWrapper) {
+	fields := LogFields{"Method": "GetVolumeExternalWrappers", "Type": "NASStorageDriver"}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetVolumeExternalWrappers")
+	defer Logd(ctx, d.Name(),
+		d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetVolumeExternalWrappers")
+
+	// Let the caller know we're done by closing the channel
+	defer close(channel)
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
+		return
 	}
 
-	// Get the snapshot
-	snapshot, err := d.SDK.SnapshotForVolume(ctx, extantVolume, internalSnapName)
+	// Get all volumes
+	volumes, err := d.SDK.Volumes(ctx)
 	if err != nil {
-		if errors.IsNotFoundError(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("could not check for existing snapshot; %v", err)
+		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
+		return
 	}
 
-	if snapshot.ProvisioningState != api.StateAvailable {
-		return nil, fmt.Errorf("snapshot %s state is %s", internalSnapName, snapshot.ProvisioningState)
-	}
+	prefix := *d.Config.StoragePrefix
 
-	created := snapshot.Created.UTC().Format(utils.TimestampFormat)
+	// Convert all volumes to VolumeExternal and write them to the channel
+	for _, volume := range *volumes {
 
-	Logc(ctx).WithFields(LogFields{
-		"snapshotName": internalSnapName,
-		"volumeName":   internalVolName,
-		"created":      created,
-	}).Debug("Found snapshot.")
+		// Filter out volumes in an unavailable state
+		switch volume.ProvisioningState {
+		case api.StateDeleting, api.StateDeleted, api.StateError:
+			continue
+		}
 
-	return &storage.Snapshot{
-		Config:    snapConfig,
-		Created:   created,
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
-	}, nil
-}
+		// Filter out volumes without the prefix (pass all if prefix is empty)
+		if !strings.HasPrefix(volume.CreationToken, prefix) {
+			continue
+		}
 
-// GetSnapshots returns the list of snapshots associated with the specified volume.
-func (d *NASStorageDriver) GetSnapshots(
-	ctx context.Context, volConfig *storage.VolumeConfig,
-) ([]*storage.Snapshot, error) {
-	internalVolName := volConfig.InternalName
-	fields := LogFields{
-		"Method":     "GetSnapshots",
-		"Type":       "NASStorageDriver",
-		"volumeName": internalVolName,
+		// Reading every volume's metrics would be far too slow, so only report usage that was read recently
+		if usage, ok := d.volumeUsage.get(volume.CreationToken, d.SDK.CacheStatus().MaxCacheAge); ok {
+			volume.UsedBytes = usage.UsedBytes
+			volume.SnapshotUsedBytes = usage.SnapshotUsedBytes
+		}
+
+		channel <- &storage.VolumeExternalWrapper{Volume: d.getVolumeExternal(volume), Error: nil}
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetSnapshots")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetSnapshots")
+}
+
+// OrphanedVolumes returns the volumes managed by this backend whose internal names are not among those
+// known to the orchestrator, such as volumes left behind after a PV was forcibly deleted.  The volumes are
+// only candidates for an operator to review; they are never deleted here.
+func (d *NASStorageDriver) OrphanedVolumes(
+	ctx context.Context, knownInternalNames []string,
+) ([]*storage.VolumeExternal, error) {
+	fields := LogFields{"Method": "OrphanedVolumes", "Type": "NASStorageDriver"}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> OrphanedVolumes")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< OrphanedVolumes")
 
 	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
+	if err := d.refreshAzureResources(ctx); err != nil {
 		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
-	// Get the volume
-	volume, err := d.SDK.Volume(ctx, volConfig)
+	volumes, err := d.SDK.Volumes(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not find volume %s; %v", internalVolName, err)
+		return nil, err
 	}
 
-	snapshots, err := d.SDK.SnapshotsForVolume(ctx, volume)
-	if err != nil {
-		return nil, err
+	known := make(map[string]bool, len(knownInternalNames))
+	for _, name := range knownInternalNames {
+		known[name] = true
 	}
 
-	snapshotList := make([]*storage.Snapshot, 0)
+	prefix := *d.Config.StoragePrefix
+	orphans := make([]*storage.VolumeExternal, 0)
 
-	for _, snapshot := range *snapshots {
+	for _, volume := range *volumes {
 
-		// Filter out snapshots in an unavailable state
-		if snapshot.ProvisioningState != api.StateAvailable {
+		// Volumes already on their way out aren't orphans
+		switch volume.ProvisioningState {
+		case api.StateDeleting, api.StateDeleted:
 			continue
 		}
 
-		snapshotList = append(snapshotList, &storage.Snapshot{
-			Config: &storage.SnapshotConfig{
-				Version:            tridentconfig.OrchestratorAPIVersion,
-				Name:               snapshot.Name,
-				InternalName:       snapshot.Name,
-				VolumeName:         volConfig.Name,
-				VolumeInternalName: volConfig.InternalName,
-			},
-			Created:   snapshot.Created.UTC().Format(utils.TimestampFormat),
-			SizeBytes: 0,
-			State:     storage.SnapshotStateOnline,
-		})
+		// Filter out volumes without the prefix (pass all if prefix is empty)
+		if !strings.HasPrefix(volume.CreationToken, prefix) {
+			continue
+		}
+
+		if known[volume.CreationToken] || !d.isManagedByThisBackend(volume) {
+			continue
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"volume":        volume.FullName,
+			"creationToken": volume.CreationToken,
+		}).Warning("Found orphaned volume.")
+
+		orphans = append(orphans, d.getVolumeExternal(volume))
 	}
 
-	return snapshotList, nil
+	return orphans, nil
 }
 
-// CreateSnapshot creates a snapshot for the given volume.
-func (d *NASStorageDriver) CreateSnapshot(
-	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) (*storage.Snapshot, error) {
-	internalSnapName := snapConfig.InternalName
-	internalVolName := snapConfig.VolumeInternalName
-	fields := LogFields{
-		"Method":       "CreateSnapshot",
-		"Type":         "NASStorageDriver",
-		"snapshotName": internalSnapName,
-		"volumeName":   internalVolName,
+// isManagedByThisBackend returns whether a volume carries the Trident telemetry label for this backend.
+func (d *NASStorageDriver) isManagedByThisBackend(volume *api.FileSystem) bool {
+	telemetryJSON, ok := volume.Labels[drivers.TridentLabelTag]
+	if !ok || d.telemetry == nil {
+		return false
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateSnapshot")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateSnapshot")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	var telemetry map[string]Telemetry
+	if err := json.Unmarshal([]byte(telemetryJSON), &telemetry); err != nil {
+		return false
 	}
 
-	// Check if volume exists
-	volumeExists, sourceVolume, err := d.SDK.VolumeExists(ctx, volConfig)
-	if err != nil {
-		return nil, fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	volumeTelemetry, ok := telemetry[drivers.TridentLabelTag]
+	return ok && volumeTelemetry.TridentBackendUUID == d.telemetry.TridentBackendUUID
+}
+
+// getExternalVolume is a private method that accepts info about a volume
+// as returned by the storage backend and formats it as a VolumeExternal
+// object.
+func (d *NASStorageDriver) getVolumeExternal(volumeAttrs *api.FileSystem) *storage.VolumeExternal {
+	// ANF export policies are unnamed, so report the clients allowed by each rule, as in the exportRule config
+	allowedClients := make([]string, 0, len(volumeAttrs.ExportPolicy.Rules))
+	for _, rule := range volumeAttrs.ExportPolicy.Rules {
+		allowedClients = append(allowedClients, rule.AllowedClients)
 	}
-	if !volumeExists {
-		return nil, fmt.Errorf("volume %s does not exist", internalVolName)
+
+	snapshotReserve := snapshotReserveFromVolume(volumeAttrs)
+
+	volumeConfig := &storage.VolumeConfig{
+		Version:         tridentconfig.OrchestratorAPIVersion,
+		Name:            volumeAttrs.Name,
+		InternalName:    volumeAttrs.CreationToken,
+		Size:            strconv.FormatInt(dataSizeForSnapshotReserve(volumeAttrs.QuotaInBytes, snapshotReserve), 10),
+		Protocol:        tridentconfig.File,
+		SnapshotPolicy:  volumeAttrs.SnapshotPolicyID,
+		ExportPolicy:    strings.Join(allowedClients, ","),
+		SnapshotDir:     strconv.FormatBool(volumeAttrs.SnapshotDirectory),
+		UnixPermissions: volumeAttrs.UnixPermissions,
+		StorageClass:    "",
+		AccessMode:      tridentconfig.ReadWriteMany,
+		AccessInfo:      utils.VolumeAccessInfo{},
+		BlockSize:       "",
+		FileSystem:      "",
+		ServiceLevel:    volumeAttrs.ServiceLevel,
 	}
 
-	// Create the snapshot
-	snapshot, err := d.SDK.CreateSnapshot(ctx, sourceVolume, internalSnapName)
-	if err != nil {
-		return nil, fmt.Errorf("could not create snapshot; %v", err)
+	if snapshotReserve >= 0 {
+		volumeConfig.SnapshotReserve = strconv.Itoa(snapshotReserve)
 	}
 
-	// Wait for snapshot creation to complete
-	err = d.SDK.WaitForSnapshotState(
-		ctx, snapshot, sourceVolume, api.StateAvailable, []string{api.StateError}, api.SnapshotTimeout)
-	if err != nil {
-		return nil, err
+	// Report the mount details for each protocol the volume supports
+	if volumeSupportsNASType(volumeAttrs, sa.NFS) {
+		if mountTarget, err := mountTargetForProtocol(volumeAttrs, sa.NFS); err == nil {
+			volumeConfig.AccessInfo.NfsPath = constructVolumeAccessPath(volumeConfig, volumeAttrs, sa.NFS)
+			volumeConfig.AccessInfo.NfsServerIP = mountTarget.IPAddress
+			if volumeAttrs.KerberosEnabled {
+				volumeConfig.AccessInfo.NfsServerIP = mountTarget.ServerFqdn
+			}
+		}
+	}
+	if volumeSupportsNASType(volumeAttrs, sa.SMB) {
+		if mountTarget, err := mountTargetForProtocol(volumeAttrs, sa.SMB); err == nil {
+			volumeConfig.AccessInfo.SMBPath = constructVolumeAccessPath(volumeConfig, volumeAttrs, sa.SMB)
+			volumeConfig.AccessInfo.SMBServer = mountTarget.ServerFqdn
+		}
 	}
 
-	Logc(ctx).WithFields(LogFields{
-		"snapshotName": snapConfig.InternalName,
-		"volumeName":   snapConfig.VolumeInternalName,
-	}).Info("Snapshot created.")
+	return &storage.VolumeExternal{
+		Config:    volumeConfig,
+		Pool:      drivers.UnsetPool,
+		UsedBytes: volumeAttrs.UsedBytes,
+	}
+}
 
-	return &storage.Snapshot{
-		Config:    snapConfig,
-		Created:   snapshot.Created.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
-	}, nil
+// String implements stringer interface for the NASStorageDriver driver.
+func (d *NASStorageDriver) String() string {
+	return utils.ToStringRedacted(d, []string{"SDK"}, d.GetExternalConfig(context.Background()))
 }
 
-// RestoreSnapshot restores a volume (in place) from a snapshot.
-func (d *NASStorageDriver) RestoreSnapshot(
-	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) error {
-	internalSnapName := snapConfig.InternalName
-	internalVolName := snapConfig.VolumeInternalName
-	fields := LogFields{
-		"Method":       "RestoreSnapshot",
-		"Type":         "NASStorageDriver",
-		"snapshotName": internalSnapName,
-		"volumeName":   internalVolName,
+// GoString implements GoStringer interface for the NASStorageDriver driver.
+func (d *NASStorageDriver) GoString() string {
+	return d.String()
+}
+
+// GetUpdateType returns a bitmap populated with updates to the driver.
+func (d *NASStorageDriver) GetUpdateType(_ context.Context, driverOrig storage.Driver) *roaring.Bitmap {
+	bitmap := roaring.New()
+	dOrig, ok := driverOrig.(*NASStorageDriver)
+	if !ok {
+		bitmap.Add(storage.InvalidUpdate)
+		return bitmap
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> RestoreSnapshot")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< RestoreSnapshot")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	if !reflect.DeepEqual(d.Config.StoragePrefix, dOrig.Config.StoragePrefix) {
+		bitmap.Add(storage.PrefixChange)
 	}
 
-	// Get the volume
-	volume, err := d.SDK.Volume(ctx, volConfig)
-	if err != nil {
-		return fmt.Errorf("could not find volume %s; %v", internalVolName, err)
+	if !drivers.AreSameCredentials(d.Config.Credentials, dOrig.Config.Credentials) ||
+		!sameAzureAuthMode(&d.Config, &dOrig.Config) {
+		bitmap.Add(storage.CredentialsChange)
 	}
 
-	// Get the snapshot
-	snapshot, err := d.SDK.SnapshotForVolume(ctx, volume, internalSnapName)
-	if err != nil {
-		return fmt.Errorf("unable to find snapshot %s: %v", internalSnapName, err)
+	if !sameVolumeAccessAttributes(d, dOrig) {
+		bitmap.Add(storage.VolumeAccessInfoChange)
+	}
+
+	return bitmap
+}
+
+// volumeAccessAttributes are the pool attributes that govern how clients may access a volume's contents.
+var volumeAccessAttributes = []string{UnixPermissions, ExportRule, SnapshotDir}
+
+// sameVolumeAccessAttributes reports whether two driver instances apply the same volume access settings,
+// both at the backend level and in each storage pool the two instances have in common.
+func sameVolumeAccessAttributes(d, dOrig *NASStorageDriver) bool {
+	if d.Config.UnixPermissions != dOrig.Config.UnixPermissions ||
+		d.Config.ExportRule != dOrig.Config.ExportRule ||
+		d.Config.SnapshotDir != dOrig.Config.SnapshotDir {
+		return false
 	}
 
-	// Do the restore
-	if err = d.SDK.RestoreSnapshot(ctx, volume, snapshot); err != nil {
-		return err
+	for poolName, pool := range d.pools {
+		poolOrig, ok := dOrig.pools[poolName]
+		if !ok {
+			continue
+		}
+		for _, attribute := range volumeAccessAttributes {
+			if pool.InternalAttributes()[attribute] != poolOrig.InternalAttributes()[attribute] {
+				return false
+			}
+		}
 	}
 
-	// Wait for snapshot deletion to complete
-	_, err = d.SDK.WaitForVolumeState(ctx, volume, api.StateAvailable,
-		[]string{api.StateError, api.StateDeleting, api.StateDeleted}, api.DefaultSDKTimeout,
-	)
-	return err
+	return true
 }
 
-// DeleteSnapshot deletes a snapshot of a volume.
-func (d *NASStorageDriver) DeleteSnapshot(
-	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) error {
-	internalSnapName := snapConfig.InternalName
-	internalVolName := snapConfig.VolumeInternalName
-	fields := LogFields{
-		"Method":       "DeleteSnapshot",
-		"Type":         "NASStorageDriver",
-		"snapshotName": internalSnapName,
-		"volumeName":   internalVolName,
+// ReconcileNodeAccess updates the export rules of each NFS volume managed by this backend to match the
+// set of Kubernetes cluster nodes.  Each export rule Trident created from the virtual pool of a volume acts as a
+// filter on the node addresses, so a rule that admits every client is left alone.  Volumes with an export rule
+// of their own and members of application volume groups are also left alone.
+func (d *NASStorageDriver) ReconcileNodeAccess(ctx context.Context, nodes []*utils.Node, _, _ string) error {
+	nodeNames := make([]string, 0)
+	nodeIPs := make([]string, 0)
+	for _, node := range nodes {
+		nodeNames = append(nodeNames, node.Name)
+		nodeIPs = append(nodeIPs, node.IPs...)
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> DeleteSnapshot")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< DeleteSnapshot")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	fields := LogFields{
+		"Method": "ReconcileNodeAccess",
+		"Type":   "NASStorageDriver",
+		"Nodes":  nodeNames,
 	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> ReconcileNodeAccess")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< ReconcileNodeAccess")
 
-	// Get the volume
-	volumeExists, extantVolume, err := d.SDK.VolumeExists(ctx, volConfig)
-	if err != nil {
-		return fmt.Errorf("error checking for existing volume %s; %v", internalVolName, err)
+	// Export rules only apply to NFS volumes
+	if d.Config.NASType == sa.SMB {
+		return nil
 	}
-	if !volumeExists {
-		// The ANF volume is backed by ONTAP, so if the volume doesn't exist, neither does the snapshot.
+
+	// Docker has no node list, so its volumes keep the statically configured export rules
+	if d.Config.DriverContext == tridentconfig.ContextDocker {
+		Logc(ctx).Debug("Docker context, skipping node access reconciliation.")
 		return nil
 	}
 
-	snapshot, err := d.SDK.SnapshotForVolume(ctx, extantVolume, internalSnapName)
-	if err != nil {
-		// If the snapshot is already gone, return success
-		if errors.IsNotFoundError(err) {
+	var autoExportClients string
+	if d.Config.AutoExportPolicy {
+		var err error
+		autoExportClients, err = d.nodeAllowedClients(ctx, nodeIPs, strings.Join(d.Config.AutoExportCIDRs, ","))
+		if err != nil {
+			return err
+		}
+		if autoExportClients == "" {
+			Logc(ctx).WithField("autoExportCIDRs", d.Config.AutoExportCIDRs).Warning(
+				"No node IP addresses match the auto export CIDRs, skipping node access reconciliation.")
 			return nil
 		}
-		return fmt.Errorf("unable to find snapshot %s; %v", internalSnapName, err)
-	}
 
-	if err = d.SDK.DeleteSnapshot(ctx, extantVolume, snapshot); err != nil {
-		return err
+		// Remember the node addresses so that new volumes are created with the same access
+		d.autoExportClientsLock.Lock()
+		d.autoExportClients = autoExportClients
+		d.autoExportClientsLock.Unlock()
+	} else if !d.exportRulesRestrictClients() {
+		// Without an auto export policy, the default export rule admits every client, so there is nothing to restrict
+		Logc(ctx).Debug("Default export rule in use, skipping node access reconciliation.")
+		return nil
 	}
 
-	// Wait for snapshot deletion to complete
-	return d.SDK.WaitForSnapshotState(
-		ctx, snapshot, extantVolume, api.StateDeleted, []string{api.StateError}, api.SnapshotTimeout,
-	)
-}
-
-// List returns the list of volumes associated with this backend.
-func (d *NASStorageDriver) List(ctx context.Context) ([]string, error) {
-	fields := LogFields{"Method": "List", "Type": "NASStorageDriver"}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> List")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< List")
-
 	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return fmt.Errorf("could not update ANF resource cache; %v", err)
 	}
 
 	volumes, err := d.SDK.Volumes(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	prefix := *d.Config.StoragePrefix
-	volumeNames := make([]string, 0)
+
+	var reconcileErrors error
 
 	for _, volume := range *volumes {
 
-		// Filter out volumes in an unavailable state
-		switch volume.ProvisioningState {
-		case api.StateDeleting, api.StateDeleted, api.StateError:
+		// Only reconcile available NFS volumes managed by this backend
+		if volume.ProvisioningState != api.StateAvailable || !strings.HasPrefix(volume.CreationToken, prefix) {
+			continue
+		}
+		if utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeCIFS) ||
+			len(volume.ExportPolicy.Rules) == 0 {
 			continue
 		}
 
-		// Filter out volumes without the prefix (pass all if prefix is empty)
-		if !strings.HasPrefix(volume.CreationToken, prefix) {
+		// Leave volumes whose export rule was set explicitly, and volume group members, as they were created
+		if volume.Labels[ExplicitExportRuleTag] != "" || volume.VolumeSpecName != "" {
 			continue
 		}
 
-		volumeName := volume.CreationToken[len(prefix):]
-		volumeNames = append(volumeNames, volumeName)
+		exportPolicy, changed, err := d.reconciledExportPolicy(ctx, volume, nodeIPs, autoExportClients)
+		if err != nil {
+			Logc(ctx).WithField("volume", volume.CreationToken).WithError(err).Error(
+				"Could not determine volume export rules.")
+			reconcileErrors = multierr.Append(reconcileErrors, err)
+			continue
+		}
+
+		// Avoid needless API calls if the volume already admits exactly the current nodes
+		if !changed {
+			continue
+		}
+
+		if err = d.SDK.ModifyVolumeExportPolicy(ctx, volume, &exportPolicy); err != nil {
+			Logc(ctx).WithField("volume", volume.CreationToken).WithError(err).Error(
+				"Could not update volume export rules.")
+			reconcileErrors = multierr.Append(reconcileErrors, err)
+		}
 	}
 
-	return volumeNames, nil
+	return reconcileErrors
 }
 
-// Get tests for the existence of a volume.
-func (d *NASStorageDriver) Get(ctx context.Context, name string) error {
-	fields := LogFields{"Method": "Get", "Type": "NASStorageDriver"}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Get")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Get")
+// reconciledExportPolicy returns a copy of a volume's export policy in which each rule Trident created from the
+// export rules of the volume's virtual pool admits only the node addresses that pool rule allows, or, if the
+// export policy is managed automatically, only the current node addresses.  Rules added to the volume by other
+// means are left alone.  The boolean result reports whether any rule changed.
+func (d *NASStorageDriver) reconciledExportPolicy(
+	ctx context.Context, volume *api.FileSystem, nodeIPs []string, autoExportClients string,
+) (api.ExportPolicy, bool, error) {
+	// Map the index of each rule Trident manages to the clients that rule should admit
+	managedClients := make(map[int32]string)
+
+	if d.Config.AutoExportPolicy {
+		managedClients[1] = autoExportClients
+	} else {
+		exportRules, err := d.volumeExportRules(volume)
+		if err != nil {
+			return api.ExportPolicy{}, false, err
+		}
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+		nfsV3Access := utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv3)
+		nfsV41Access := utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv41)
+
+		ruleIndex := int32(0)
+		for _, rule := range exportRules {
+
+			// Create skipped any rules limited to an NFS version other than the volume's
+			if (rule.Nfsv3 || rule.Nfsv41) && !(rule.Nfsv3 && nfsV3Access) && !(rule.Nfsv41 && nfsV41Access) {
+				continue
+			}
+			ruleIndex++
+
+			// A rule that admits every client has nothing to restrict
+			if rule.AllowedClients == "" || rule.AllowedClients == defaultExportRule {
+				continue
+			}
+
+			allowedClients, err := d.nodeAllowedClients(ctx, nodeIPs, rule.AllowedClients)
+			if err != nil {
+				return api.ExportPolicy{}, false, err
+			}
+			if allowedClients == "" {
+				Logc(ctx).WithFields(LogFields{
+					"volume":     volume.CreationToken,
+					"exportRule": rule.AllowedClients,
+				}).Debug("No node IP addresses match the export rule, leaving it unchanged.")
+				continue
+			}
+			managedClients[ruleIndex] = allowedClients
+		}
 	}
 
-	if _, err := d.SDK.VolumeByCreationToken(ctx, name); err != nil {
-		return fmt.Errorf("could not get volume %s; %v", name, err)
+	exportPolicy := api.ExportPolicy{Rules: make([]api.ExportRule, len(volume.ExportPolicy.Rules))}
+	copy(exportPolicy.Rules, volume.ExportPolicy.Rules)
+
+	changed := false
+	for i, rule := range exportPolicy.Rules {
+		allowedClients, ok := managedClients[rule.RuleIndex]
+		if !ok || normalizeAllowedClients(rule.AllowedClients) == allowedClients {
+			continue
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"volume":    volume.CreationToken,
+			"ruleIndex": rule.RuleIndex,
+			"current":   rule.AllowedClients,
+			"desired":   allowedClients,
+		}).Debug("Updating volume export rule.")
+
+		exportPolicy.Rules[i].AllowedClients = allowedClients
+		changed = true
 	}
 
-	return nil
+	return exportPolicy, changed, nil
 }
 
-// Resize increases a volume's quota.
-func (d *NASStorageDriver) Resize(ctx context.Context, volConfig *storage.VolumeConfig, sizeBytes uint64) error {
-	name := volConfig.InternalName
-	fields := LogFields{
-		"Method":    "Resize",
-		"Type":      "NASStorageDriver",
-		"name":      name,
-		"sizeBytes": sizeBytes,
+// volumeExportRules returns the export rules of the virtual pool a volume was created in, or the backend's
+// export rules if that pool isn't known, such as for volumes created before the pool was recorded.
+func (d *NASStorageDriver) volumeExportRules(volume *api.FileSystem) ([]drivers.AzureNASExportRule, error) {
+	if pool, ok := d.getPool(volume.Labels[StoragePoolTag]); ok {
+		return poolExportRules(pool)
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Resize")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Resize")
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+	if len(d.Config.ExportRules) == 0 {
+		return []drivers.AzureNASExportRule{{AllowedClients: d.Config.ExportRule}}, nil
 	}
 
-	// Get the volume
-	volume, err := d.SDK.Volume(ctx, volConfig)
-	if err != nil {
-		return fmt.Errorf("could not find volume %s; %v", name, err)
+	exportRules := make([]drivers.AzureNASExportRule, len(d.Config.ExportRules))
+	copy(exportRules, d.Config.ExportRules)
+	for i := range exportRules {
+		if err := expandExportRuleShorthand(&exportRules[i]); err != nil {
+			return nil, fmt.Errorf("invalid export rule %d; %v", i, err)
+		}
 	}
+	return exportRules, nil
+}
 
-	// Heal the ID on legacy volumes
-	if volConfig.InternalID == "" {
-		volConfig.InternalID = volume.ID
+// exportRulesRestrictClients reports whether any export rule of the backend or its virtual pools admits only
+// some clients, so that there may be node access to reconcile.  Rules that can't be parsed are assumed to
+// restrict clients, so that the volumes using them report the error.
+func (d *NASStorageDriver) exportRulesRestrictClients() bool {
+	ruleSets := [][]drivers.AzureNASExportRule{
+		append([]drivers.AzureNASExportRule{{AllowedClients: d.Config.ExportRule}}, d.Config.ExportRules...),
+	}
+	for _, pool := range d.getPools() {
+		exportRules, err := poolExportRules(pool)
+		if err != nil {
+			return true
+		}
+		ruleSets = append(ruleSets, exportRules)
 	}
 
-	// If the volume state isn't Available, return an error
-	if volume.ProvisioningState != api.StateAvailable {
-		return fmt.Errorf("volume %s state is %s, not %s", name, volume.ProvisioningState, api.StateAvailable)
+	for _, exportRules := range ruleSets {
+		for _, rule := range exportRules {
+			allowedClients := rule.AllowedClients
+			if allowedClients == "" {
+				allowedClients = rule.CIDR
+			}
+			if allowedClients != "" && allowedClients != defaultExportRule {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	volConfig.Size = strconv.FormatUint(uint64(volume.QuotaInBytes), 10)
+// autoExportAllowedClients returns the clients admitted to new volumes when the export policy is managed
+// automatically.  These are the node addresses most recently applied by ReconcileNodeAccess or, until that has
+// run since the driver started, the backend's autoExportCIDRs, which every node address must fall within.
+func (d *NASStorageDriver) autoExportAllowedClients() string {
+	d.autoExportClientsLock.RLock()
+	defer d.autoExportClientsLock.RUnlock()
 
-	// If the volume is already the requested size, there's nothing to do
-	if int64(sizeBytes) == volume.QuotaInBytes {
-		return nil
+	if d.autoExportClients != "" {
+		return d.autoExportClients
 	}
+	return strings.Join(d.Config.AutoExportCIDRs, ",")
+}
 
-	// Make sure we're not shrinking the volume
-	if int64(sizeBytes) < volume.QuotaInBytes {
-		return fmt.Errorf("requested size %d is less than existing volume size %d", sizeBytes, volume.QuotaInBytes)
+// nodeAllowedClients returns the sorted, comma-separated list of node IP addresses admitted by an export
+// rule's comma-separated list of addresses and CIDRs.
+func (d *NASStorageDriver) nodeAllowedClients(ctx context.Context, nodeIPs []string, rule string) (string, error) {
+	cidrs := make([]string, 0)
+	for _, client := range strings.Split(rule, ",") {
+		client = strings.TrimSpace(client)
+		if ip := net.ParseIP(client); ip != nil {
+			if ip.To4() != nil {
+				client += "/32"
+			} else {
+				client += "/128"
+			}
+		}
+		cidrs = append(cidrs, client)
 	}
 
-	// Make sure the request isn't above the configured maximum volume size (if any)
-	if _, _, err = drivers.CheckVolumeSizeLimits(ctx, sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
-		return err
+	filteredIPs, err := utils.FilterIPs(ctx, nodeIPs, cidrs)
+	if err != nil {
+		return "", fmt.Errorf("could not determine node access; %v", err)
 	}
 
-	// Resize the volume
-	if err = d.SDK.ResizeVolume(ctx, volume, int64(sizeBytes)); err != nil {
-		return err
+	// The filtered list is sorted, so any duplicates are adjacent
+	uniqueIPs := make([]string, 0)
+	for i, ip := range filteredIPs {
+		if i == 0 || ip != filteredIPs[i-1] {
+			uniqueIPs = append(uniqueIPs, ip)
+		}
 	}
 
-	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
+	return strings.Join(uniqueIPs, ","), nil
+}
+
+// normalizeAllowedClients sorts a comma-separated list of export rule clients so that it may be compared.
+func normalizeAllowedClients(allowedClients string) string {
+	clients := make([]string, 0)
+	for _, client := range strings.Split(allowedClients, ",") {
+		if client = strings.TrimSpace(client); client != "" {
+			clients = append(clients, client)
+		}
+	}
+	sort.Strings(clients)
+	return strings.Join(clients, ",")
+}
+
+// validateStoragePrefix ensures the storage prefix is valid
+func validateStoragePrefix(storagePrefix string) error {
+	if !storagePrefixRegex.MatchString(storagePrefix) {
+		return fmt.Errorf("storage prefix may only contain letters and hyphens and must begin with a letter")
+	}
 	return nil
 }
 
-// GetStorageBackendSpecs retrieves storage capabilities and register pools with specified backend.
-func (d *NASStorageDriver) GetStorageBackendSpecs(_ context.Context, backend storage.Backend) error {
-	backend.SetName(d.BackendName())
+// hasUnscopedEmptyPrefix reports whether the backend has an empty storage prefix, which disables filtering
+// of volumes by name, and also has no resource group, NetApp account, or capacity pool filters, so that it
+// can't tell its own volumes apart from those of any other backend sharing the subscription.
+func (d *NASStorageDriver) hasUnscopedEmptyPrefix() bool {
+	return *d.Config.StoragePrefix == "" && len(d.Config.ResourceGroups) == 0 &&
+		len(d.Config.NetappAccounts) == 0 && len(d.Config.CapacityPools) == 0
+}
 
-	for _, pool := range d.pools {
-		pool.SetBackend(backend)
-		backend.AddStoragePool(pool)
+// throughputFromPool returns the throughput in MiB/s configured for a pool, or 0 if none is set.
+func throughputFromPool(pool storage.Pool) (float32, error) {
+	if storage.IsStoragePoolUnset(pool) || pool.InternalAttributes()[Throughput] == "" {
+		return 0, nil
 	}
 
-	return nil
+	throughput, err := strconv.ParseFloat(pool.InternalAttributes()[Throughput], 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid throughput %s; %v", pool.InternalAttributes()[Throughput], err)
+	}
+	if throughput <= 0 {
+		return 0, fmt.Errorf("throughput %s must be greater than zero", pool.InternalAttributes()[Throughput])
+	}
+	return float32(throughput), nil
 }
 
-// CreatePrepare is called prior to volume creation.  Currently its only role is to create the internal volume name.
-func (d *NASStorageDriver) CreatePrepare(ctx context.Context, volConfig *storage.VolumeConfig) {
-	volConfig.InternalName = d.GetInternalVolumeName(ctx, volConfig.Name)
+// validateDefaultServiceLevel ensures the configured default service level is a known ANF service level
+// and is offered by at least one discovered capacity pool.
+func (d *NASStorageDriver) validateDefaultServiceLevel() error {
+	defaultServiceLevel := utils.Title(d.Config.DefaultServiceLevel)
+
+	switch defaultServiceLevel {
+	case api.ServiceLevelStandard, api.ServiceLevelPremium, api.ServiceLevelUltra:
+		break
+	default:
+		return fmt.Errorf("invalid value for defaultServiceLevel: %s", d.Config.DefaultServiceLevel)
+	}
+
+	if cPools := d.SDK.CapacityPools(); cPools != nil {
+		for _, cPool := range *cPools {
+			if strings.EqualFold(cPool.ServiceLevel, defaultServiceLevel) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("invalid value for defaultServiceLevel; no capacity pools with service level %s found",
+		defaultServiceLevel)
 }
 
-// GetStorageBackendPhysicalPoolNames retrieves storage backend physical pools
-func (d *NASStorageDriver) GetStorageBackendPhysicalPoolNames(context.Context) []string {
-	return []string{}
+// poolsWithoutServiceLevel returns the names of any storage pools that don't specify a service level.
+func (d *NASStorageDriver) poolsWithoutServiceLevel() []string {
+	poolNames := make([]string, 0)
+	for poolName, pool := range d.getPools() {
+		if pool.InternalAttributes()[ServiceLevel] == "" {
+			poolNames = append(poolNames, poolName)
+		}
+	}
+	sort.Strings(poolNames)
+	return poolNames
 }
 
-// getStorageBackendPools determines any non-overlapping, discrete storage pools present on a driver's storage backend.
-func (d *NASStorageDriver) getStorageBackendPools(ctx context.Context) []drivers.ANFStorageBackendPool {
-	fields := LogFields{"Method": "getStorageBackendPools", "Type": "NASStorageDriver"}
-	Logc(ctx).WithFields(fields).Debug(">>>> getStorageBackendPools")
-	defer Logc(ctx).WithFields(fields).Debug("<<<< getStorageBackendPools")
+// ldapEnabledFromPool returns whether LDAP is enabled for volumes in a pool.
+func ldapEnabledFromPool(pool storage.Pool) (bool, error) {
+	if storage.IsStoragePoolUnset(pool) || pool.InternalAttributes()[LDAPEnabled] == "" {
+		return false, nil
+	}
 
-	// For this driver, a discrete storage pool is composed of the following:
-	// 1. Resource Group - contains at least one netapp account; names are unique within a subscription.
-	// 2. NetappAccount - contains at least one capacity pool; names are unique to resource group OR region;
-	//   names can only be reused if resource group AND region differ from matching account name.
-	// 3. Capacity Pool - names are unique within a given ResourceGroup and NetappAccount.
+	ldapEnabled, err := strconv.ParseBool(pool.InternalAttributes()[LDAPEnabled])
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value %s; %v", pool.InternalAttributes()[LDAPEnabled], err)
+	}
+	return ldapEnabled, nil
+}
 
-	// CapacityPoolsForStoragePools relies on an internal mapping of storage pools created from the driver config.
-	// If the behavior of that method should ever change, this method will need to change as well.
-	cPools := d.SDK.CapacityPoolsForStoragePools(ctx)
-	backendPools := make([]drivers.ANFStorageBackendPool, 0, len(cPools))
-	for _, cPool := range cPools {
-		backendPools = append(backendPools, drivers.ANFStorageBackendPool{
-			SubscriptionID: d.Config.SubscriptionID,
-			ResourceGroup:  cPool.ResourceGroup,
-			NetappAccount:  cPool.NetAppAccount,
-			Location:       cPool.Location,
-			CapacityPool:   cPool.Name,
-		})
+// hasRootAccessFromPool returns whether root has access to NFSv4.1 volumes in a pool, or nil if not configured.
+func hasRootAccessFromPool(pool storage.Pool) (*bool, error) {
+	if storage.IsStoragePoolUnset(pool) || pool.InternalAttributes()[HasRootAccess] == "" {
+		return nil, nil
+	}
+
+	hasRootAccess, err := strconv.ParseBool(pool.InternalAttributes()[HasRootAccess])
+	if err != nil {
+		return nil, fmt.Errorf("invalid boolean value %s; %v", pool.InternalAttributes()[HasRootAccess], err)
+	}
+	return &hasRootAccess, nil
+}
+
+// chownModeFromPool returns the ANF chown mode for NFSv4.1 volumes in a pool, or an empty string if not configured.
+func chownModeFromPool(pool storage.Pool) (string, error) {
+	if storage.IsStoragePoolUnset(pool) {
+		return "", nil
+	}
+
+	switch chownMode := pool.InternalAttributes()[ChownMode]; strings.ToLower(chownMode) {
+	case "":
+		return "", nil
+	case strings.ToLower(api.ChownModeRestricted):
+		return api.ChownModeRestricted, nil
+	case strings.ToLower(api.ChownModeUnrestricted):
+		return api.ChownModeUnrestricted, nil
+	default:
+		return "", fmt.Errorf("invalid chown mode %s; must be %s or %s", chownMode,
+			api.ChownModeRestricted, api.ChownModeUnrestricted)
+	}
+}
+
+// smbOptionsFromPool returns whether SMB volumes in a pool are shared with continuous availability and
+// whether they require SMB3 encryption.
+func smbOptionsFromPool(pool storage.Pool) (continuousAvailability, encryption bool, err error) {
+	if storage.IsStoragePoolUnset(pool) {
+		return false, false, nil
+	}
+	return parseSMBOptions(pool.InternalAttributes()[SMBContinuousAvailability],
+		pool.InternalAttributes()[SMBEncryption])
+}
+
+// parseSMBOptions parses the SMB continuous availability and encryption settings, either of which may be empty.
+func parseSMBOptions(continuousAvailabilityValue, encryptionValue string) (
+	continuousAvailability, encryption bool, err error,
+) {
+	if continuousAvailabilityValue != "" {
+		if continuousAvailability, err = strconv.ParseBool(continuousAvailabilityValue); err != nil {
+			return false, false, fmt.Errorf("invalid boolean value %s for %s; %v", continuousAvailabilityValue,
+				SMBContinuousAvailability, err)
+		}
+	}
+	if encryptionValue != "" {
+		if encryption, err = strconv.ParseBool(encryptionValue); err != nil {
+			return false, false, fmt.Errorf("invalid boolean value %s for %s; %v", encryptionValue,
+				SMBEncryption, err)
+		}
+	}
+	return continuousAvailability, encryption, nil
+}
+
+// largeVolumeFromPool returns whether a volume of the specified size in a pool is created as a large volume.
+// Unless the pool says otherwise, only volumes above ANF's regular size limit are large volumes.
+func largeVolumeFromPool(pool storage.Pool, sizeBytes uint64) (bool, error) {
+	if storage.IsStoragePoolUnset(pool) || pool.InternalAttributes()[LargeVolume] == "" {
+		return sizeBytes > MaximumANFVolumeSizeBytes, nil
+	}
+	largeVolume, err := strconv.ParseBool(pool.InternalAttributes()[LargeVolume])
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value for %s; %v", LargeVolume, err)
+	}
+	return largeVolume, nil
+}
+
+// backupEnabledFromPool returns whether backups are enabled for volumes in a pool.
+func backupEnabledFromPool(pool storage.Pool) (bool, error) {
+	if storage.IsStoragePoolUnset(pool) || pool.InternalAttributes()[BackupEnabled] == "" {
+		return false, nil
 	}
 
-	return backendPools
+	backupEnabled, err := strconv.ParseBool(pool.InternalAttributes()[BackupEnabled])
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value %s; %v", pool.InternalAttributes()[BackupEnabled], err)
+	}
+	return backupEnabled, nil
 }
 
-// GetInternalVolumeName accepts the name of a volume being created and returns what the internal name
-// should be, depending on backend requirements and Trident's operating context.
-func (d *NASStorageDriver) GetInternalVolumeName(ctx context.Context, name string) string {
-	if tridentconfig.UsingPassthroughStore {
-		// With a passthrough store, the name mapping must remain reversible
-		return *d.Config.StoragePrefix + name
-	} else if csiRegex.MatchString(name) {
-		// If the name is from CSI (i.e. contains a UUID), just use it as-is
-		Logc(ctx).WithField("volumeInternal", name).Debug("Using volume name as internal name.")
-		return name
-	} else {
-		// Cloud volumes have strict limits on volume mount paths, so for cloud
-		// infrastructure like Trident, the simplest approach is to generate a
-		// UUID-based name with a prefix that won't exceed the 36-character limit.
-		return "anf-" + uuid.NewString()
+// parseSnapshotReserve returns a snapshot reserve percentage, or -1 if none is set.
+func parseSnapshotReserve(value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+
+	snapshotReserve, err := strconv.Atoi(value)
+	if err != nil {
+		return -1, fmt.Errorf("invalid snapshot reserve %s; %v", value, err)
+	}
+	if snapshotReserve < 0 || snapshotReserve > maxSnapshotReserve {
+		return -1, fmt.Errorf("snapshot reserve %s must be between 0 and %d", value, maxSnapshotReserve)
 	}
+	return snapshotReserve, nil
 }
 
-// CreateFollowup is called after volume creation and sets the access info in the volume config.
-func (d *NASStorageDriver) CreateFollowup(ctx context.Context, volConfig *storage.VolumeConfig) error {
-	var volume *api.FileSystem
-	var err error
+// snapshotReserveFromPool returns the snapshot reserve percentage configured for a pool, or -1 if none is set.
+func snapshotReserveFromPool(pool storage.Pool) (int, error) {
+	if storage.IsStoragePoolUnset(pool) {
+		return -1, nil
+	}
+	return parseSnapshotReserve(pool.InternalAttributes()[SnapshotReserve])
+}
 
-	name := volConfig.InternalName
-	fields := LogFields{
-		"Method": "CreateFollowup",
-		"Type":   "NASStorageDriver",
-		"name":   name,
+// snapshotReserveFromVolume returns the snapshot reserve percentage recorded on a volume, or -1 if none is.
+func snapshotReserveFromVolume(volume *api.FileSystem) int {
+	snapshotReserve, err := parseSnapshotReserve(volume.Labels[SnapshotReserveTag])
+	if err != nil {
+		return -1
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateFollowup")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateFollowup")
+	return snapshotReserve
+}
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return fmt.Errorf("could not update ANF resource cache; %v", err)
+// quotaForSnapshotReserve returns the quota needed for a volume to hold sizeBytes of data in addition to its
+// snapshot reserve.
+func quotaForSnapshotReserve(sizeBytes uint64, snapshotReserve int) uint64 {
+	if snapshotReserve <= 0 {
+		return sizeBytes
 	}
+	divisor := uint64(100 - snapshotReserve)
+	return (sizeBytes*100 + divisor - 1) / divisor
+}
 
-	// If it's a RO clone, get source volume to populate access details
-	if volConfig.ReadOnlyClone {
-		volume, err = d.SDK.VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal)
-		if err != nil {
-			return fmt.Errorf("could not find volume %s; %v", name, err)
-		}
-	} else {
-		// Get the volume
-		volume, err = d.SDK.Volume(ctx, volConfig)
-		if err != nil {
-			return fmt.Errorf("could not find volume %s; %v", name, err)
-		}
+// dataSizeForSnapshotReserve returns the part of a volume's quota not set aside for snapshots.
+func dataSizeForSnapshotReserve(quotaBytes int64, snapshotReserve int) int64 {
+	if snapshotReserve <= 0 {
+		return quotaBytes
 	}
+	return quotaBytes * int64(100-snapshotReserve) / 100
+}
 
-	// Ensure volume is in a good state
-	if volume.ProvisioningState != api.StateAvailable {
-		return fmt.Errorf("volume %s is in %s state, not %s", name, volume.ProvisioningState, api.StateAvailable)
+// setKerberosExportRuleAccess sets either the read-only or the read-write flag of an export rule for
+// the specified Kerberos security type.
+func setKerberosExportRuleAccess(rule *api.ExportRule, kerberos string, readOnly bool) {
+	switch kerberos {
+	case api.MountOptionKerberos5:
+		rule.Kerberos5ReadOnly = readOnly
+		rule.Kerberos5ReadWrite = !readOnly
+	case api.MountOptionKerberos5I:
+		rule.Kerberos5IReadOnly = readOnly
+		rule.Kerberos5IReadWrite = !readOnly
+	case api.MountOptionKerberos5P:
+		rule.Kerberos5PReadOnly = readOnly
+		rule.Kerberos5PReadWrite = !readOnly
 	}
+}
 
-	if len(volume.MountTargets) == 0 {
-		return fmt.Errorf("volume %s has no mount targets", volConfig.InternalName)
+// topologyLocations returns the Azure locations named by a set of CSI topology segments.  A segment names
+// its location by region, or else by zone, since AKS zones are named for their region, as in eastus2-1.
+func topologyLocations(segments []map[string]string) []string {
+	locations := make([]string, 0)
+	for _, segment := range segments {
+		location := segment[topologyRegionLabel]
+		if location == "" {
+			zone := segment[topologyZoneLabel]
+			if i := strings.LastIndex(zone, "-"); i > 0 {
+				location = zone[:i]
+			}
+		}
+		location = normalizeAzureLocation(location)
+		if location != "" && !utils.SliceContainsString(locations, location) {
+			locations = append(locations, location)
+		}
 	}
+	return locations
+}
 
-	// Set the mount target based on the NASType
-	if d.Config.NASType == sa.SMB {
-		volConfig.AccessInfo.SMBPath = constructVolumeAccessPath(volConfig, volume, sa.SMB)
-		volConfig.AccessInfo.SMBServer = (volume.MountTargets)[0].ServerFqdn
-		volConfig.FileSystem = sa.SMB
-	} else {
-		volConfig.AccessInfo.NfsPath = constructVolumeAccessPath(volConfig, volume, sa.NFS)
-		volConfig.AccessInfo.NfsServerIP = (volume.MountTargets)[0].IPAddress
-		volConfig.FileSystem = sa.NFS
+// availabilityZones returns the Azure availability zones in which a volume may be created, in order of
+// preference.  These are the zones named by the CSI topology, preferred segments first, or else the pool's
+// zone.  Only zones named as AKS names them, as in eastus2-1, or by number alone are availability zones, so
+// any other zone is just a label and doesn't constrain where the volume is placed.
+func availabilityZones(volConfig *storage.VolumeConfig, pool storage.Pool) []string {
+	zones := make([]string, 0)
+	for _, segments := range [][]map[string]string{volConfig.PreferredTopologies, volConfig.RequisiteTopologies} {
+		for _, segment := range segments {
+			zone := segment[topologyZoneLabel]
+			if _, _, ok := parseAvailabilityZone(zone); ok && !utils.SliceContainsString(zones, zone) {
+				zones = append(zones, zone)
+			}
+		}
 	}
 
-	// Replace server IP with FQDN for kerberos volume
-	if volume.KerberosEnabled {
-		volConfig.AccessInfo.NfsServerIP = (volume.MountTargets)[0].ServerFqdn
+	if len(zones) == 0 && pool != nil {
+		if offer, ok := pool.Attributes()[sa.Zone]; ok {
+			if _, _, ok := parseAvailabilityZone(offer.ToString()); ok {
+				zones = append(zones, offer.ToString())
+			}
+		}
 	}
 
-	return nil
+	return zones
 }
 
-// GetProtocol returns the protocol supported by this driver (File).
-func (d *NASStorageDriver) GetProtocol(context.Context) tridentconfig.Protocol {
-	return tridentconfig.File
+// parseAvailabilityZone splits an availability zone name, such as eastus2-1 or 1, into its location, which
+// is empty if the name doesn't include one, and its zone number.
+func parseAvailabilityZone(zone string) (location, number string, ok bool) {
+	number = zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		location, number = normalizeAzureLocation(zone[:i]), zone[i+1:]
+	}
+
+	// Zone 0 is how AKS labels nodes that aren't in any availability zone
+	if n, err := strconv.Atoi(number); err != nil || n < 1 || strconv.Itoa(n) != number {
+		return "", "", false
+	}
+
+	return location, number, true
 }
 
-// StoreConfig adds this backend's config to the persistent config struct, as needed by Trident's persistence layer.
-func (d *NASStorageDriver) StoreConfig(_ context.Context, b *storage.PersistentStorageBackendConfig) {
-	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
-	b.AzureConfig = &d.Config
+// filterByTopology returns the resources in one of the requisite locations, or all of them if no locations
+// are required, ordered so those in a preferred location come first.
+func filterByTopology[T any](resources []T, location func(T) string, requisite, preferred []string) []T {
+	preferredResources := make([]T, 0, len(resources))
+	otherResources := make([]T, 0, len(resources))
+	for _, resource := range resources {
+		resourceLocation := normalizeAzureLocation(location(resource))
+		if len(requisite) > 0 && !utils.SliceContainsString(requisite, resourceLocation) {
+			continue
+		}
+		if utils.SliceContainsString(preferred, resourceLocation) {
+			preferredResources = append(preferredResources, resource)
+		} else {
+			otherResources = append(otherResources, resource)
+		}
+	}
+	return append(preferredResources, otherResources...)
 }
 
-// GetExternalConfig returns a clone of this backend's config, sanitized for external consumption.
-func (d *NASStorageDriver) GetExternalConfig(ctx context.Context) interface{} {
-	// Clone the config so we don't risk altering the original
-	var cloneConfig drivers.AzureNASStorageDriverConfig
-	drivers.Clone(ctx, d.Config, &cloneConfig)
-	cloneConfig.ClientSecret = utils.REDACTED // redact the Secret
-	cloneConfig.Credentials = map[string]string{
-		drivers.KeyName: utils.REDACTED,
-		drivers.KeyType: utils.REDACTED,
-	} // redact the credentials
-	return cloneConfig
+// filterCapacityPoolsByQosType returns the capacity pools that use manual QoS if manual is true, or else
+// the capacity pools that use auto QoS.
+func filterCapacityPoolsByQosType(cPools []*api.CapacityPool, manual bool) []*api.CapacityPool {
+	filteredPools := make([]*api.CapacityPool, 0, len(cPools))
+	for _, cPool := range cPools {
+		if strings.EqualFold(cPool.QosType, api.QosTypeManual) == manual {
+			filteredPools = append(filteredPools, cPool)
+		}
+	}
+	return filteredPools
 }
 
-// GetVolumeExternal queries the storage backend for all relevant info about
-// a single container volume managed by this driver and returns a VolumeExternal
-// representation of the volume.
-func (d *NASStorageDriver) GetVolumeExternal(ctx context.Context, name string) (*storage.VolumeExternal, error) {
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+// filterCapacityPoolsByActiveDirectory returns the capacity pools whose NetApp accounts have an Active
+// Directory connection.
+func filterCapacityPoolsByActiveDirectory(cPools []*api.CapacityPool) []*api.CapacityPool {
+	filteredPools := make([]*api.CapacityPool, 0, len(cPools))
+	for _, cPool := range cPools {
+		if cPool.ActiveDirectoryConfigured {
+			filteredPools = append(filteredPools, cPool)
+		}
 	}
+	return filteredPools
+}
 
-	filesystem, err := d.SDK.VolumeByCreationToken(ctx, name)
-	if err != nil {
-		return nil, err
+// filterCapacityPoolsByKerberos returns the capacity pools whose NetApp accounts have a Kerberos realm
+// configured in their Active Directory connection.
+func filterCapacityPoolsByKerberos(cPools []*api.CapacityPool) []*api.CapacityPool {
+	filteredPools := make([]*api.CapacityPool, 0, len(cPools))
+	for _, cPool := range cPools {
+		if cPool.KerberosConfigured {
+			filteredPools = append(filteredPools, cPool)
+		}
 	}
+	return filteredPools
+}
 
-	return d.getVolumeExternal(filesystem), nil
+// coolAccessFromPool returns the cool access settings for a pool after ensuring they are valid for the
+// specified service level.
+func coolAccessFromPool(pool storage.Pool, serviceLevel string) (bool, int32, error) {
+	return parseCoolAccess(pool.InternalAttributes()[CoolAccess], pool.InternalAttributes()[CoolnessPeriod],
+		pool.InternalAttributes()[CoolAccessRetrievalPolicy], serviceLevel)
 }
 
-// GetVolumeExternalWrappers queries the storage backend for all relevant info about
-// container volumes managed by this driver.  It then writes a VolumeExternal
-// representation of each volume to the supplied channel, closing the channel
-// when finished.
-func (d *NASStorageDriver) GetVolumeExternalWrappers(ctx context.Context, channel chan *storage.VolumeExternalWrapper) {
-	fields := LogFields{"Method": "GetVolumeExternalWrappers", "Type": "NASStorageDriver"}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetVolumeExternalWrappers")
-	defer Logd(ctx, d.Name(),
-		d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetVolumeExternalWrappers")
+// parseCoolAccess converts cool access settings from their configured form, ensuring they are valid for
+// the specified service level.
+func parseCoolAccess(
+	coolAccessValue, coolnessPeriodValue, retrievalPolicy, serviceLevel string,
+) (bool, int32, error) {
+	coolAccess := false
+	if coolAccessValue != "" {
+		var err error
+		if coolAccess, err = strconv.ParseBool(coolAccessValue); err != nil {
+			return false, 0, fmt.Errorf("invalid value for coolAccess; %v", err)
+		}
+	}
 
-	// Let the caller know we're done by closing the channel
-	defer close(channel)
+	var coolnessPeriod int32
+	if value := coolnessPeriodValue; value != "" {
+		period, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid value for coolnessPeriod; %v", err)
+		}
+		if period < api.MinCoolnessPeriodDays || period > api.MaxCoolnessPeriodDays {
+			return false, 0, fmt.Errorf("coolnessPeriod %d must be between %d and %d days",
+				period, api.MinCoolnessPeriodDays, api.MaxCoolnessPeriodDays)
+		}
+		coolnessPeriod = int32(period)
+	}
 
-	// Update resource cache as needed
-	if err := d.SDK.RefreshAzureResources(ctx); err != nil {
-		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
-		return
+	switch retrievalPolicy {
+	case "", api.CoolAccessRetrievalPolicyDefault:
+		break
+	default:
+		return false, 0, fmt.Errorf("coolAccessRetrievalPolicy %s is not supported by the ANF API version in use",
+			retrievalPolicy)
 	}
 
-	// Get all volumes
-	volumes, err := d.SDK.Volumes(ctx)
-	if err != nil {
-		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
-		return
+	if coolAccess && serviceLevel != "" && serviceLevel != api.ServiceLevelStandard {
+		return false, 0, fmt.Errorf("cool access is not supported for the %s service level", serviceLevel)
 	}
 
-	prefix := *d.Config.StoragePrefix
+	return coolAccess, coolnessPeriod, nil
+}
 
-	// Convert all volumes to VolumeExternal and write them to the channel
-	for _, volume := range *volumes {
+// validateSnapshotPolicy ensures a snapshot policy is either a valid policy name or a snapshot policy ID.
+func validateSnapshotPolicy(snapshotPolicy string) error {
+	if snapshotPolicy == "" {
+		return nil
+	}
+	if strings.HasPrefix(snapshotPolicy, "/") {
+		_, _, _, _, _, err := api.ParseSnapshotPolicyID(snapshotPolicy)
+		return err
+	}
+	if !snapshotPolicyRegex.MatchString(snapshotPolicy) {
+		return fmt.Errorf("snapshot policy %s must be a snapshot policy ID or a name consisting of letters, "+
+			"digits, hyphens, and underscores", snapshotPolicy)
+	}
+	return nil
+}
 
-		// Filter out volumes in an unavailable state
-		switch volume.ProvisioningState {
-		case api.StateDeleting, api.StateDeleted, api.StateError:
+// validateTagKey ensures a tag key is acceptable to Azure and does not collide with the tags Trident manages.
+func validateTagKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("tag key may not be empty")
+	}
+	if len(key) > api.MaxTagKeyLength {
+		return fmt.Errorf("tag key %s exceeds the maximum length of %d", key, api.MaxTagKeyLength)
+	}
+	if strings.ContainsAny(key, `<>%&\?/`) {
+		return fmt.Errorf("tag key %s may not contain any of the characters <>%%&\\?/", key)
+	}
+	if strings.EqualFold(key, drivers.TridentLabelTag) || strings.EqualFold(key, storage.ProvisioningLabelTag) ||
+		strings.EqualFold(key, TridentVersionTag) || strings.EqualFold(key, TridentNameTag) ||
+		strings.EqualFold(key, SnapshotReserveTag) {
+		return fmt.Errorf("tag key %s is reserved", key)
+	}
+	return nil
+}
+
+// getNamespaceAnnotationTags returns the volume tags derived from the requesting namespace's annotations
+// according to the namespaceAnnotationTags mapping.  Annotations not present on the namespace are skipped.
+func (d *NASStorageDriver) getNamespaceAnnotationTags(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+) map[string]string {
+	tags := make(map[string]string)
+
+	for annotation, tag := range d.Config.NamespaceAnnotationTags {
+		value, ok := volConfig.NamespaceAnnotations[annotation]
+		if !ok {
+			Logc(ctx).WithFields(LogFields{
+				"namespace":  volConfig.Namespace,
+				"annotation": annotation,
+			}).Debug("Namespace annotation not found, skipping tag.")
 			continue
 		}
-
-		// Filter out volumes without the prefix (pass all if prefix is empty)
-		if !strings.HasPrefix(volume.CreationToken, prefix) {
+		if len(value) > api.MaxLabelLength {
+			Logc(ctx).WithFields(LogFields{
+				"namespace":  volConfig.Namespace,
+				"annotation": annotation,
+			}).Warningf("Namespace annotation value exceeds %d characters, skipping tag.", api.MaxLabelLength)
 			continue
 		}
+		tags[tag] = value
+	}
 
-		channel <- &storage.VolumeExternalWrapper{Volume: d.getVolumeExternal(volume), Error: nil}
+	return tags
+}
+
+// getCustomTags returns the custom tags from the backend config.  Any tag that would replace one of Trident's
+// own tags or that Azure would reject is skipped, although validation should already have caught these.
+func (d *NASStorageDriver) getCustomTags(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+
+	for tag, value := range d.Config.CustomTags {
+		if err := validateTagKey(tag); err != nil {
+			Logc(ctx).WithField("tag", tag).WithError(err).Warning("Invalid custom tag, skipping it.")
+			continue
+		}
+		if len(value) > api.MaxLabelLength {
+			Logc(ctx).WithField("tag", tag).Warningf(
+				"Custom tag value exceeds %d characters, skipping it.", api.MaxLabelLength)
+			continue
+		}
+		tags[tag] = value
 	}
+
+	return tags
 }
 
-// getExternalVolume is a private method that accepts info about a volume
-// as returned by the storage backend and formats it as a VolumeExternal
-// object.
-func (d *NASStorageDriver) getVolumeExternal(volumeAttrs *api.FileSystem) *storage.VolumeExternal {
-	volumeConfig := &storage.VolumeConfig{
-		Version:         tridentconfig.OrchestratorAPIVersion,
-		Name:            volumeAttrs.Name,
-		InternalName:    volumeAttrs.CreationToken,
-		Size:            strconv.FormatInt(volumeAttrs.QuotaInBytes, 10),
-		Protocol:        tridentconfig.File,
-		SnapshotPolicy:  "",
-		ExportPolicy:    "",
-		SnapshotDir:     strconv.FormatBool(volumeAttrs.SnapshotDirectory),
-		UnixPermissions: volumeAttrs.UnixPermissions,
-		StorageClass:    "",
-		AccessMode:      tridentconfig.ReadWriteMany,
-		AccessInfo:      utils.VolumeAccessInfo{},
-		BlockSize:       "",
-		FileSystem:      "",
-		ServiceLevel:    volumeAttrs.ServiceLevel,
+// getVersionTags returns the tag recording the Trident version, if the backend config requests it.
+func (d *NASStorageDriver) getVersionTags(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+
+	if !d.Config.TagTridentVersion || d.telemetry == nil || d.telemetry.TridentVersion == "" {
+		return tags
 	}
 
-	return &storage.VolumeExternal{
-		Config: volumeConfig,
-		Pool:   drivers.UnsetPool,
+	version := d.telemetry.TridentVersion
+	if len(version) > api.MaxLabelLength {
+		Logc(ctx).WithField("version", version).Warningf(
+			"Trident version exceeds %d characters, skipping tag.", api.MaxLabelLength)
+		return tags
 	}
+	tags[TridentVersionTag] = version
+
+	return tags
 }
 
-// String implements stringer interface for the NASStorageDriver driver.
-func (d *NASStorageDriver) String() string {
-	return utils.ToStringRedacted(d, []string{"SDK"}, d.GetExternalConfig(context.Background()))
+// CacheStatus reports the age of this backend's cached ANF resources and whether the cache is stale.
+// Nothing is reported if the driver never got far enough to create its ANF client.
+func (d *NASStorageDriver) CacheStatus(ctx context.Context) *storage.BackendCacheStatus {
+	if d.SDK == nil {
+		return nil
+	}
+
+	return d.SDK.CacheStatus()
 }
 
-// GoString implements GoStringer interface for the NASStorageDriver driver.
-func (d *NASStorageDriver) GoString() string {
-	return d.String()
+// GetCommonConfig returns driver's CommonConfig
+func (d *NASStorageDriver) GetCommonConfig(context.Context) *drivers.CommonStorageDriverConfig {
+	return d.Config.CommonStorageDriverConfig
 }
 
-// GetUpdateType returns a bitmap populated with updates to the driver.
-func (d *NASStorageDriver) GetUpdateType(_ context.Context, driverOrig storage.Driver) *roaring.Bitmap {
-	bitmap := roaring.New()
-	dOrig, ok := driverOrig.(*NASStorageDriver)
-	if !ok {
-		bitmap.Add(storage.InvalidUpdate)
-		return bitmap
+// nfsVersionFromMountOptions returns the NFS version requested by a set of mount options, or the default version
+// if none is requested.  ANF doesn't offer NFSv4.0, so a request for it is an error rather than being quietly
+// served by an NFSv4.1 export, which clients pinned to 4.0 can't mount.
+func nfsVersionFromMountOptions(mountOptions, defaultVersion string) (string, error) {
+	nfsVersion, err := utils.GetNFSVersionFromMountOptions(mountOptions, defaultVersion, supportedNFSVersions)
+	if nfsVersion == nfsVersion40 {
+		return "", fmt.Errorf("ANF does not support NFS version %s; use nfsvers=%s, or nfsvers=%s to mount "+
+			"with the highest minor version offered", nfsVersion40, nfsVersion41, nfsVersion4)
 	}
+	return nfsVersion, err
+}
 
-	if !reflect.DeepEqual(d.Config.StoragePrefix, dOrig.Config.StoragePrefix) {
-		bitmap.Add(storage.PrefixChange)
+// nfsProtocolType returns the ANF protocol type of volumes mounted using the specified NFS version.
+func nfsProtocolType(nfsVersion string) string {
+	if nfsVersion == nfsVersion3 {
+		return api.ProtocolTypeNFSv3
 	}
+	return api.ProtocolTypeNFSv41
+}
 
-	if !drivers.AreSameCredentials(d.Config.Credentials, dOrig.Config.Credentials) {
-		bitmap.Add(storage.CredentialsChange)
+// matchingMountOption returns the first of a comma-separated list of mount options matching the specified
+// expression, or an empty string if none match.
+func matchingMountOption(mountOptions string, optionRegex *regexp.Regexp) string {
+	for _, option := range strings.Split(strings.TrimPrefix(mountOptions, "-o "), ",") {
+		option = strings.TrimSpace(option)
+		if optionRegex.MatchString(option) {
+			return option
+		}
 	}
-
-	return bitmap
+	return ""
 }
 
-// ReconcileNodeAccess updates a per-backend export policy to match the set of Kubernetes cluster
-// nodes.  Not supported by this driver.
-func (d *NASStorageDriver) ReconcileNodeAccess(ctx context.Context, _ []*utils.Node, _, _ string) error {
-	fields := LogFields{
-		"Method": "ReconcileNodeAccess",
-		"Type":   "NASStorageDriver",
+// volumeSupportsNASType returns whether a volume may be accessed using the specified NAS type.  Dual-protocol
+// volumes support both NFS and SMB, and only they support the dual NAS type.
+func volumeSupportsNASType(volume *api.FileSystem, nasType string) bool {
+	var supportsNFS, supportsSMB bool
+	for _, protocolType := range volume.ProtocolTypes {
+		switch protocolType {
+		case api.ProtocolTypeCIFS:
+			supportsSMB = true
+		case api.ProtocolTypeNFSv3, api.ProtocolTypeNFSv41:
+			supportsNFS = true
+		}
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> ReconcileNodeAccess")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< ReconcileNodeAccess")
 
-	return nil
-}
-
-// validateStoragePrefix ensures the storage prefix is valid
-func validateStoragePrefix(storagePrefix string) error {
-	if !storagePrefixRegex.MatchString(storagePrefix) {
-		return fmt.Errorf("storage prefix may only contain letters and hyphens and must begin with a letter")
+	switch nasType {
+	case sa.SMB:
+		return supportsSMB
+	case sa.NFS:
+		return supportsNFS
+	case NASTypeDual:
+		return supportsNFS && supportsSMB
 	}
-	return nil
+	return false
 }
 
-// GetCommonConfig returns driver's CommonConfig
-func (d *NASStorageDriver) GetCommonConfig(context.Context) *drivers.CommonStorageDriverConfig {
-	return d.Config.CommonStorageDriverConfig
+// mountTargetForProtocol returns the first of a volume's mount targets that can serve the specified protocol
+// (NFS or SMB).  SMB clients, and NFS clients of Kerberos volumes, need the server's FQDN, while other NFS
+// clients need its IP address, so a volume with several mount targets may not be reachable via all of them.
+func mountTargetForProtocol(volume *api.FileSystem, protocol string) (*api.MountTarget, error) {
+	for i := range volume.MountTargets {
+		mountTarget := &volume.MountTargets[i]
+		switch {
+		case protocol == sa.SMB || volume.KerberosEnabled:
+			if mountTarget.ServerFqdn != "" {
+				return mountTarget, nil
+			}
+		case mountTarget.IPAddress != "":
+			return mountTarget, nil
+		}
+	}
+	return nil, fmt.Errorf("volume %s has no %s mount target", volume.CreationToken, protocol)
 }
 
 func constructVolumeAccessPath(
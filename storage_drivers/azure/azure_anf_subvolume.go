@@ -7,7 +7,6 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -153,6 +152,9 @@ type NASBlockStorageDriver struct {
 
 	physicalPools map[string]storage.Pool
 	virtualPools  map[string]storage.Pool
+
+	// credentialProviders supply the Azure credentials; if empty, the built-in providers are used
+	credentialProviders []CredentialProvider
 }
 
 // Name returns the name of this driver.
@@ -355,6 +357,11 @@ func (d *NASBlockStorageDriver) populateConfigurationDefaults(
 	if config.LimitVolumeSize == "" {
 		config.LimitVolumeSize = defaultLimitVolumeSize
 	}
+
+	if config.Cloud == "" {
+		config.Cloud = api.CloudAzurePublic
+	}
+
 	Logc(ctx).WithFields(LogFields{
 		"StoragePrefix":   *config.StoragePrefix,
 		"Size":            config.Size,
@@ -375,7 +382,7 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 
 	// Need to identify the NFS protocol backend supports and make sure all of the filePoolVolumes follow the same
 	// protocol
-	nfsVersion, err := utils.GetNFSVersionFromMountOptions(d.Config.NfsMountOptions, "", supportedNFSVersions)
+	nfsVersion, err := nfsVersionFromMountOptions(d.Config.NfsMountOptions, "")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -577,29 +584,19 @@ func (d *NASBlockStorageDriver) initializeAzureSDKClient(
 		},
 		Location:          config.Location,
 		StorageDriverName: config.StorageDriverName,
+		CloudName:         config.Cloud,
 		DebugTraceFlags:   config.DebugTraceFlags,
 		SDKTimeout:        sdkTimeout,
 		MaxCacheAge:       maxCacheAge,
 	}
 
-	if config.ClientSecret == "" && config.ClientID == "" {
-		credFilePath := os.Getenv("AZURE_CREDENTIAL_FILE")
-		if credFilePath == "" {
-			credFilePath = DefaultConfigurationFilePath
-		}
-		Logc(ctx).WithField("credFilePath", credFilePath).Info("Using Azure credential config file.")
-		credFile, err := os.ReadFile(credFilePath)
-		if err != nil {
-			return errors.New("error reading from azure config file: " + err.Error())
-		}
-		if err = json.Unmarshal(credFile, &clientConfig); err != nil {
-			return errors.New("error parsing azureAuthConfig: " + err.Error())
-		}
-
-		// Set SubscriptionID
-		d.Config.SubscriptionID = clientConfig.SubscriptionID
+	if err := resolveAzureAuthConfig(ctx, d.credentialProviders, config, &clientConfig); err != nil {
+		return err
 	}
 
+	// Set SubscriptionID, which may have come from the credential file
+	d.Config.SubscriptionID = clientConfig.SubscriptionID
+
 	client, err := api.NewDriver(clientConfig)
 	if err != nil {
 		return err
@@ -639,6 +636,11 @@ func (d *NASBlockStorageDriver) validate(ctx context.Context) error {
 		return fmt.Errorf("storage prefix '%s' ends with '-'", storagePrefix)
 	}
 
+	// Validate the Azure environment
+	if _, err := api.CloudConfiguration(d.Config.Cloud); err != nil {
+		return fmt.Errorf("invalid value for cloud; %v", err)
+	}
+
 	// Ensure user does not provide "ro" mount option
 	if utils.AreMountOptionsInList(d.Config.NfsMountOptions, []string{"ro"}) {
 		return fmt.Errorf("ReadOnly (ro) option is not supported in ANF subvolume backend nfsMountOptions; %s",
@@ -1988,7 +1990,8 @@ func (d *NASBlockStorageDriver) GetUpdateType(_ context.Context, driverOrig stor
 		bitmap.Add(storage.PrefixChange)
 	}
 
-	if !drivers.AreSameCredentials(d.Config.Credentials, dOrig.Config.Credentials) {
+	if !drivers.AreSameCredentials(d.Config.Credentials, dOrig.Config.Credentials) ||
+		!sameAzureAuthMode(&d.Config, &dOrig.Config) {
 		bitmap.Add(storage.CredentialsChange)
 	}
 
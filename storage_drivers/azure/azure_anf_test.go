@@ -6,13 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/RoaringBitmap/roaring"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +25,7 @@ import (
 	. "github.com/netapp/trident/logging"
 	mockacp "github.com/netapp/trident/mocks/mock_acp"
 	mockapi "github.com/netapp/trident/mocks/mock_storage_drivers/mock_azure"
+	v1 "github.com/netapp/trident/persistent_store/crd/apis/netapp/v1"
 	"github.com/netapp/trident/storage"
 	storagefake "github.com/netapp/trident/storage/fake"
 	sa "github.com/netapp/trident/storage_attribute"
@@ -254,15 +258,18 @@ func TestInitialize(t *testing.T) {
 
 	// Have to at least one CapacityPool for ANF backends.
 	pool := &api.CapacityPool{
-		Name:          "CP1",
-		Location:      "fake-location",
-		NetAppAccount: "NA1",
-		ResourceGroup: "RG1",
+		Name:               "CP1",
+		Location:           "fake-location",
+		NetAppAccount:      "NA1",
+		ResourceGroup:      "RG1",
+		KerberosConfigured: true,
 	}
 
 	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(nil).Times(1)
 	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{pool}).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).
+		Return([]*api.CapacityPool{pool}).Times(1)
 
 	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig,
 		map[string]string{}, BackendUUID)
@@ -560,6 +567,221 @@ func TestInitialize_InvalidVolumeCreateTimeout(t *testing.T) {
 	assert.False(t, driver.Initialized(), "initialized")
 }
 
+func TestInitialize_DiscoveryFailed(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
+
+	configJSON := `
+    {
+		"version": 1,
+        "storageDriverName": "azure-netapp-files",
+        "location": "fake-location",
+        "subscriptionID": "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b",
+        "tenantID": "deadbeef-4746-4444-a919-3b34af5f0a3c",
+        "clientID": "deadbeef-784c-4b35-8329-460f52a3ad50",
+        "clientSecret": "myClientSecret",
+        "serviceLevel": "Premium",
+        "debugTraceFlags": {"method": true, "api": true, "discovery": true},
+	    "capacityPools": ["RG1/NA1/CP1", "RG1/NA1/CP2"],
+	    "virtualNetwork": "VN1",
+	    "subnet": "RG1/VN1/SN1"
+    }`
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	unavailableErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+
+	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(unavailableErr).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{}).Times(1)
+
+	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig, map[string]string{},
+		BackendUUID)
+
+	assert.NoError(t, result, "initialize failed")
+	assert.True(t, driver.Initialized(), "not initialized")
+	assert.True(t, driver.initialDiscoveryFailed, "discovery failure not recorded")
+}
+
+func TestInitialize_DiscoveryFailed_NotUnavailable(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
+
+	configJSON := `
+    {
+		"version": 1,
+        "storageDriverName": "azure-netapp-files",
+        "location": "fake-location",
+        "subscriptionID": "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b",
+        "tenantID": "deadbeef-4746-4444-a919-3b34af5f0a3c",
+        "clientID": "deadbeef-784c-4b35-8329-460f52a3ad50",
+        "clientSecret": "myClientSecret",
+        "serviceLevel": "Premium",
+        "debugTraceFlags": {"method": true, "api": true, "discovery": true},
+	    "capacityPools": ["RG1/NA1/CP1", "RG1/NA1/CP2"],
+	    "virtualNetwork": "VN1",
+	    "subnet": "RG1/VN1/SN1"
+    }`
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(errFailed).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Times(0)
+
+	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig, map[string]string{},
+		BackendUUID)
+
+	assert.Error(t, result, "initialize did not fail")
+	assert.False(t, driver.Initialized(), "initialized")
+}
+
+func TestInitialize_InvalidVolumeCreateConflictRetries(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
+
+	configJSON := `
+    {
+		"version": 1,
+        "storageDriverName": "azure-netapp-files",
+        "location": "fake-location",
+        "subscriptionID": "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b",
+        "tenantID": "deadbeef-4746-4444-a919-3b34af5f0a3c",
+        "clientID": "deadbeef-784c-4b35-8329-460f52a3ad50",
+        "clientSecret": "myClientSecret",
+        "serviceLevel": "Premium",
+        "debugTraceFlags": {"method": true, "api": true, "discovery": true},
+	    "capacityPools": ["RG1/NA1/CP1", "RG1/NA1/CP2"],
+	    "virtualNetwork": "VN1",
+	    "subnet": "RG1/VN1/SN1",
+        "volumeCreateConflictRetries": "-1"
+    }`
+
+	// Have to at least one CapacityPool for ANF backends.
+	pool := &api.CapacityPool{
+		Name:          "CP1",
+		Location:      "fake-location",
+		NetAppAccount: "NA1",
+		ResourceGroup: "RG1",
+	}
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{pool}).Times(1)
+
+	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig, map[string]string{},
+		BackendUUID)
+
+	assert.Error(t, result, "initialize did not fail")
+	assert.False(t, driver.Initialized(), "initialized")
+}
+
+func TestInitialize_InvalidFailedVolumeCleanupAge(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
+
+	configJSON := `
+    {
+		"version": 1,
+        "storageDriverName": "azure-netapp-files",
+        "location": "fake-location",
+        "subscriptionID": "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b",
+        "tenantID": "deadbeef-4746-4444-a919-3b34af5f0a3c",
+        "clientID": "deadbeef-784c-4b35-8329-460f52a3ad50",
+        "clientSecret": "myClientSecret",
+        "serviceLevel": "Premium",
+        "debugTraceFlags": {"method": true, "api": true, "discovery": true},
+	    "capacityPools": ["RG1/NA1/CP1", "RG1/NA1/CP2"],
+	    "virtualNetwork": "VN1",
+	    "subnet": "RG1/VN1/SN1",
+        "failedVolumeCleanupAge": "0"
+    }`
+
+	// Have to at least one CapacityPool for ANF backends.
+	pool := &api.CapacityPool{
+		Name:          "CP1",
+		Location:      "fake-location",
+		NetAppAccount: "NA1",
+		ResourceGroup: "RG1",
+	}
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{pool}).Times(1)
+
+	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig, map[string]string{},
+		BackendUUID)
+
+	assert.Error(t, result, "initialize did not fail")
+	assert.False(t, driver.Initialized(), "initialized")
+}
+
+func TestInitialize_InvalidCloneSnapshotReuseAge(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
+
+	configJSON := `
+    {
+		"version": 1,
+        "storageDriverName": "azure-netapp-files",
+        "location": "fake-location",
+        "subscriptionID": "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b",
+        "tenantID": "deadbeef-4746-4444-a919-3b34af5f0a3c",
+        "clientID": "deadbeef-784c-4b35-8329-460f52a3ad50",
+        "clientSecret": "myClientSecret",
+        "serviceLevel": "Premium",
+        "debugTraceFlags": {"method": true, "api": true, "discovery": true},
+	    "capacityPools": ["RG1/NA1/CP1", "RG1/NA1/CP2"],
+	    "virtualNetwork": "VN1",
+	    "subnet": "RG1/VN1/SN1",
+        "cloneSnapshotReuseAge": "-1"
+    }`
+
+	// Have to at least one CapacityPool for ANF backends.
+	pool := &api.CapacityPool{
+		Name:          "CP1",
+		Location:      "fake-location",
+		NetAppAccount: "NA1",
+		ResourceGroup: "RG1",
+	}
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{pool}).Times(1)
+
+	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig, map[string]string{},
+		BackendUUID)
+
+	assert.Error(t, result, "initialize did not fail")
+	assert.False(t, driver.Initialized(), "initialized")
+}
+
 func TestInitialize_InvalidSDKTimeout(t *testing.T) {
 	commonConfig := &drivers.CommonStorageDriverConfig{
 		Version:           1,
@@ -699,6 +921,23 @@ func TestTerminate(t *testing.T) {
 	assert.False(t, driver.initialized, "initialized not false")
 }
 
+func TestTerminate_StopsCacheRefresh(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initialized = true
+	driver.Config.BackgroundCacheRefresh = true
+
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{MaxCacheAge: time.Second}).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(gomock.Any()).Return(nil).AnyTimes()
+
+	driver.startCacheRefresh(ctx)
+	assert.NotNil(t, driver.cacheRefreshDone, "cache refresh not started")
+
+	driver.Terminate(ctx, "")
+
+	assert.Nil(t, driver.cacheRefreshDone, "cache refresh not stopped")
+	assert.False(t, driver.initialized, "initialized not false")
+}
+
 func TestPopulateConfigurationDefaults_NoneSet(t *testing.T) {
 	config := &drivers.AzureNASStorageDriverConfig{
 		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
@@ -715,10 +954,70 @@ func TestPopulateConfigurationDefaults_NoneSet(t *testing.T) {
 	assert.Equal(t, "trident-", *driver.Config.StoragePrefix)
 	assert.Equal(t, defaultVolumeSizeStr, driver.Config.Size)
 	assert.Equal(t, defaultUnixPermissions, driver.Config.UnixPermissions)
+	assert.Equal(t, defaultUnixPermissionsMode, driver.Config.DefaultUnixPermissionsMode)
+	assert.Equal(t, defaultExportRuleValidation, driver.Config.ExportRuleValidation)
+	assert.Equal(t, defaultFailedVolumeCleanup, driver.Config.FailedVolumeCleanup)
+	assert.Equal(t, api.CloudAzurePublic, driver.Config.Cloud)
 	assert.Equal(t, defaultNfsMountOptions, driver.Config.NfsMountOptions)
+	assert.Equal(t, defaultKerberosNfsVersion, driver.Config.KerberosNfsVersion)
 	assert.Equal(t, defaultSnapshotDir, driver.Config.SnapshotDir)
 	assert.Equal(t, defaultLimitVolumeSize, driver.Config.LimitVolumeSize)
 	assert.Equal(t, defaultExportRule, driver.Config.ExportRule)
+	assert.Equal(t, defaultCoolAccess, driver.Config.CoolAccess)
+	assert.Equal(t, defaultCoolnessPeriod, driver.Config.CoolnessPeriod)
+}
+
+func TestPopulateConfigurationDefaults_Kerberos(t *testing.T) {
+	tests := []struct {
+		Name                    string
+		KerberosNfsVersion      string
+		ExpectedVersion         string
+		ExpectedNfsMountOptions string
+	}{
+		{"Default", "", nfsVersion41, "nfsvers=4.1"},
+		{"NFSv4", nfsVersion4, nfsVersion4, "nfsvers=4"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config = drivers.AzureNASStorageDriverConfig{
+				CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+					DriverContext:   tridentconfig.ContextCSI,
+					DebugTraceFlags: debugTraceFlags,
+				},
+				KerberosNfsVersion: test.KerberosNfsVersion,
+				AzureNASStorageDriverPool: drivers.AzureNASStorageDriverPool{
+					Kerberos: api.MountOptionKerberos5,
+				},
+			}
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+
+			assert.Equal(t, test.ExpectedVersion, driver.Config.KerberosNfsVersion)
+			assert.Equal(t, test.ExpectedNfsMountOptions, driver.Config.NfsMountOptions)
+		})
+	}
+}
+
+func TestPopulateConfigurationDefaults_SMB(t *testing.T) {
+	config := &drivers.AzureNASStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+			DriverContext:   tridentconfig.ContextCSI,
+			DebugTraceFlags: debugTraceFlags,
+		},
+		AzureNASStorageDriverPool: drivers.AzureNASStorageDriverPool{
+			NASType: sa.SMB,
+		},
+	}
+
+	_, driver := newMockANFDriver(t)
+	driver.Config = *config
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+
+	assert.Equal(t, sa.SMB, driver.Config.NASType)
+	assert.Equal(t, "", driver.Config.ExportRule)
+	assert.Equal(t, "", driver.Config.NfsMountOptions)
 }
 
 func TestPopulateConfigurationDefaults_AllSet(t *testing.T) {
@@ -758,16 +1057,178 @@ func TestPopulateConfigurationDefaults_AllSet(t *testing.T) {
 	assert.Equal(t, "1.1.1.1/32", driver.Config.ExportRule)
 }
 
-func TestInitializeStoragePools_NoVirtualPools(t *testing.T) {
-	supportedTopologies := []map[string]string{
-		{"topology.kubernetes.io/region": "europe-west1", "topology.kubernetes.io/zone": "us-east-1c"},
-	}
+func TestResolveAzureAuthConfig(t *testing.T) {
+	credFilePath := t.TempDir() + "/azure.json"
+	credFile := `{"subscriptionId": "fileSubscription", "tenantId": "fileTenant", "aadClientId": "fileClient", ` +
+		`"useManagedIdentityExtension": true}`
+	assert.NoError(t, os.WriteFile(credFilePath, []byte(credFile), 0o600))
+	tokenFilePath := t.TempDir() + "/token"
+	assert.NoError(t, os.WriteFile(tokenFilePath, []byte("token"), 0o600))
 
-	config := &drivers.AzureNASStorageDriverConfig{
-		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
-			BackendName:     "myANFBackend",
-			DriverContext:   tridentconfig.ContextCSI,
-			DebugTraceFlags: debugTraceFlags,
+	tests := []struct {
+		name                 string
+		env                  map[string]string
+		config               drivers.AzureNASStorageDriverConfig
+		wantErr              bool
+		wantWorkloadIdentity bool
+		wantManagedIdentity  bool
+		wantClientID         string
+		wantTenantID         string
+		wantSecret           string
+		wantSubscription     string
+	}{
+		{
+			name: "WorkloadIdentityOverridesClientSecret",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": tokenFilePath, "AZURE_CLIENT_ID": "envClient",
+				"AZURE_TENANT_ID": "envTenant", "AZURE_CREDENTIAL_FILE": "/nonexistent",
+			},
+			config: drivers.AzureNASStorageDriverConfig{
+				SubscriptionID: "configSubscription", ClientID: "configClient", ClientSecret: "secret",
+			},
+			wantWorkloadIdentity: true,
+			wantClientID:         "envClient",
+			wantTenantID:         "envTenant",
+			wantSubscription:     "configSubscription",
+		},
+		{
+			name: "WorkloadIdentityCustomEnvNames",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "", "MY_TOKEN_FILE": tokenFilePath, "MY_CLIENT_ID": "envClient",
+				"MY_TENANT_ID": "envTenant", "AZURE_CREDENTIAL_FILE": "/nonexistent",
+			},
+			config: drivers.AzureNASStorageDriverConfig{
+				SubscriptionID: "configSubscription", WorkloadIdentityTokenFileEnv: "MY_TOKEN_FILE",
+				WorkloadIdentityClientIDEnv: "MY_CLIENT_ID", WorkloadIdentityTenantIDEnv: "MY_TENANT_ID",
+			},
+			wantWorkloadIdentity: true,
+			wantClientID:         "envClient",
+			wantTenantID:         "envTenant",
+			wantSubscription:     "configSubscription",
+		},
+		{
+			name: "WorkloadIdentityOverridesManagedIdentity",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": tokenFilePath, "AZURE_CLIENT_ID": "envClient",
+				"AZURE_TENANT_ID": "envTenant", "AZURE_CREDENTIAL_FILE": credFilePath,
+			},
+			wantWorkloadIdentity: true,
+			wantClientID:         "envClient",
+			wantTenantID:         "envTenant",
+			wantSubscription:     "fileSubscription",
+		},
+		{
+			name: "WorkloadIdentityNoClientID",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": tokenFilePath, "AZURE_CLIENT_ID": "", "AZURE_TENANT_ID": "envTenant",
+			},
+			config:  drivers.AzureNASStorageDriverConfig{SubscriptionID: "configSubscription"},
+			wantErr: true,
+		},
+		{
+			name: "WorkloadIdentityRequired",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": tokenFilePath, "AZURE_CLIENT_ID": "envClient",
+				"AZURE_TENANT_ID": "envTenant",
+			},
+			config: drivers.AzureNASStorageDriverConfig{
+				SubscriptionID: "configSubscription", UseWorkloadIdentity: true,
+			},
+			wantWorkloadIdentity: true,
+			wantClientID:         "envClient",
+			wantTenantID:         "envTenant",
+			wantSubscription:     "configSubscription",
+		},
+		{
+			name: "WorkloadIdentityRequiredNoTokenFileEnv",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "", "AZURE_CLIENT_ID": "envClient", "AZURE_TENANT_ID": "envTenant",
+			},
+			config: drivers.AzureNASStorageDriverConfig{
+				SubscriptionID: "configSubscription", ClientSecret: "secret", UseWorkloadIdentity: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "WorkloadIdentityTokenFileMissing",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "/nonexistent", "AZURE_CLIENT_ID": "envClient",
+				"AZURE_TENANT_ID": "envTenant",
+			},
+			config:  drivers.AzureNASStorageDriverConfig{SubscriptionID: "configSubscription"},
+			wantErr: true,
+		},
+		{
+			name: "ClientSecret",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "", "AZURE_CREDENTIAL_FILE": "/nonexistent",
+			},
+			config: drivers.AzureNASStorageDriverConfig{
+				SubscriptionID: "configSubscription", TenantID: "configTenant", ClientID: "configClient",
+				ClientSecret: "secret",
+			},
+			wantClientID:     "configClient",
+			wantTenantID:     "configTenant",
+			wantSecret:       "secret",
+			wantSubscription: "configSubscription",
+		},
+		{
+			name: "CredentialFileManagedIdentity",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "", "AZURE_CREDENTIAL_FILE": credFilePath,
+			},
+			wantManagedIdentity: true,
+			wantClientID:        "fileClient",
+			wantTenantID:        "fileTenant",
+			wantSubscription:    "fileSubscription",
+		},
+		{
+			name: "CredentialFileMissing",
+			env: map[string]string{
+				"AZURE_FEDERATED_TOKEN_FILE": "", "AZURE_CREDENTIAL_FILE": "/nonexistent",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+
+			clientConfig := &api.ClientConfig{SubscriptionID: test.config.SubscriptionID}
+			clientConfig.TenantID = test.config.TenantID
+			clientConfig.AADClientID = test.config.ClientID
+			clientConfig.AADClientSecret = test.config.ClientSecret
+
+			err := resolveAzureAuthConfig(ctx, nil, &test.config, clientConfig)
+
+			if test.wantErr {
+				assert.Error(t, err, "expected error")
+				return
+			}
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.wantWorkloadIdentity, clientConfig.UseFederatedWorkloadIdentityExtension)
+			assert.Equal(t, test.wantManagedIdentity, clientConfig.UseManagedIdentityExtension)
+			assert.Equal(t, test.wantClientID, clientConfig.AADClientID)
+			assert.Equal(t, test.wantTenantID, clientConfig.TenantID)
+			assert.Equal(t, test.wantSecret, clientConfig.AADClientSecret)
+			assert.Equal(t, test.wantSubscription, clientConfig.SubscriptionID)
+		})
+	}
+}
+
+func TestInitializeStoragePools_NoVirtualPools(t *testing.T) {
+	supportedTopologies := []map[string]string{
+		{"topology.kubernetes.io/region": "europe-west1", "topology.kubernetes.io/zone": "us-east-1c"},
+	}
+
+	config := &drivers.AzureNASStorageDriverConfig{
+		CommonStorageDriverConfig: &drivers.CommonStorageDriverConfig{
+			BackendName:     "myANFBackend",
+			DriverContext:   tridentconfig.ContextCSI,
+			DebugTraceFlags: debugTraceFlags,
 			LimitVolumeSize: "123456789000",
 		},
 		NfsMountOptions: "nfsvers=4.1",
@@ -791,6 +1252,8 @@ func TestInitializeStoragePools_NoVirtualPools(t *testing.T) {
 			Zone:                "zone1",
 			SupportedTopologies: supportedTopologies,
 			NASType:             "nfs",
+			SubnetSelection:     api.SubnetSelectionCapacity,
+			SnapshotPolicy:      "policy1",
 		},
 	}
 
@@ -815,6 +1278,7 @@ func TestInitializeStoragePools_NoVirtualPools(t *testing.T) {
 	pool.InternalAttributes()[ServiceLevel] = api.ServiceLevelUltra
 	pool.InternalAttributes()[SnapshotDir] = "true"
 	pool.InternalAttributes()[ExportRule] = "1.1.1.1/32"
+	pool.InternalAttributes()[ExportRules] = ""
 	pool.InternalAttributes()[VirtualNetwork] = "VN1"
 	pool.InternalAttributes()[Subnet] = "SN1"
 	pool.InternalAttributes()[NetworkFeatures] = api.NetworkFeaturesStandard
@@ -822,6 +1286,20 @@ func TestInitializeStoragePools_NoVirtualPools(t *testing.T) {
 	pool.InternalAttributes()[NetappAccounts] = "NA1,NA2"
 	pool.InternalAttributes()[CapacityPools] = "CP1,CP2"
 	pool.InternalAttributes()[Kerberos] = ""
+	pool.InternalAttributes()[BackupPolicy] = ""
+	pool.InternalAttributes()[BackupEnabled] = ""
+	pool.InternalAttributes()[LDAPEnabled] = ""
+	pool.InternalAttributes()[SnapshotReserve] = ""
+	pool.InternalAttributes()[Throughput] = ""
+	pool.InternalAttributes()[CoolAccess] = ""
+	pool.InternalAttributes()[CoolnessPeriod] = ""
+	pool.InternalAttributes()[CoolAccessRetrievalPolicy] = ""
+	pool.InternalAttributes()[SnapshotPolicy] = "policy1"
+	pool.InternalAttributes()[SubnetSelection] = api.SubnetSelectionCapacity
+	pool.InternalAttributes()[SplitOnClone] = ""
+	pool.InternalAttributes()[SMBContinuousAvailability] = ""
+	pool.InternalAttributes()[SMBEncryption] = ""
+	pool.InternalAttributes()[LargeVolume] = ""
 
 	pool.SetSupportedTopologies(supportedTopologies)
 
@@ -857,6 +1335,7 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 			ServiceLevel:   "Standard",
 			Region:         "region1",
 			Zone:           "zone1",
+			SnapshotPolicy: "policy1",
 		},
 		Storage: []drivers.AzureNASStorageDriverPool{
 			{
@@ -866,6 +1345,10 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 					},
 					UnixPermissions: "0700",
 					ExportRule:      "2.2.2.2/32",
+					ExportRules: []drivers.AzureNASExportRule{
+						{AllowedClients: "2.2.2.0/24", UnixReadOnly: true},
+						{AllowedClients: "2.2.2.2/32"},
+					},
 				},
 				VirtualNetwork:      "VN1",
 				Subnet:              "SN1",
@@ -879,6 +1362,7 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 				SupportedTopologies: supportedTopologies,
 				NASType:             "nfs",
 				Kerberos:            "sec=krb5i",
+				SubnetSelection:     api.SubnetSelectionFirst,
 			},
 			{
 				AzureNASStorageDriverConfigDefaults: drivers.AzureNASStorageDriverConfigDefaults{
@@ -892,6 +1376,10 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 				NetappAccounts:      []string{"NA1", "NA2"},
 				CapacityPools:       []string{"CP2"},
 				SupportedTopologies: supportedTopologies,
+				SnapshotPolicy:      "policy2",
+				CoolAccess:          "true",
+				CoolnessPeriod:      "60",
+				SplitOnClone:        "true",
 			},
 		},
 	}
@@ -918,6 +1406,9 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 	pool0.InternalAttributes()[ServiceLevel] = api.ServiceLevelUltra
 	pool0.InternalAttributes()[SnapshotDir] = "true"
 	pool0.InternalAttributes()[ExportRule] = "2.2.2.2/32"
+	pool0.InternalAttributes()[ExportRules] = `[{"allowedClients":"2.2.2.0/24","unixReadOnly":true,` +
+		`"unixReadWrite":false,"nfsv3":false,"nfsv41":false},{"allowedClients":"2.2.2.2/32","unixReadOnly":false,` +
+		`"unixReadWrite":false,"nfsv3":false,"nfsv41":false}]`
 	pool0.InternalAttributes()[VirtualNetwork] = "VN1"
 	pool0.InternalAttributes()[Subnet] = "SN1"
 	pool0.InternalAttributes()[NetworkFeatures] = api.NetworkFeaturesBasic
@@ -925,6 +1416,20 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 	pool0.InternalAttributes()[NetappAccounts] = "NA1,NA2"
 	pool0.InternalAttributes()[CapacityPools] = "CP1"
 	pool0.InternalAttributes()[Kerberos] = "sec=krb5i"
+	pool0.InternalAttributes()[BackupPolicy] = ""
+	pool0.InternalAttributes()[BackupEnabled] = "false"
+	pool0.InternalAttributes()[LDAPEnabled] = ""
+	pool0.InternalAttributes()[SnapshotReserve] = ""
+	pool0.InternalAttributes()[Throughput] = ""
+	pool0.InternalAttributes()[CoolAccess] = "false"
+	pool0.InternalAttributes()[CoolnessPeriod] = "31"
+	pool0.InternalAttributes()[CoolAccessRetrievalPolicy] = ""
+	pool0.InternalAttributes()[SnapshotPolicy] = "policy1"
+	pool0.InternalAttributes()[SubnetSelection] = api.SubnetSelectionFirst
+	pool0.InternalAttributes()[SplitOnClone] = ""
+	pool0.InternalAttributes()[SMBContinuousAvailability] = ""
+	pool0.InternalAttributes()[SMBEncryption] = ""
+	pool0.InternalAttributes()[LargeVolume] = ""
 
 	pool0.SetSupportedTopologies(supportedTopologies)
 
@@ -944,6 +1449,7 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 	pool1.InternalAttributes()[ServiceLevel] = "Standard"
 	pool1.InternalAttributes()[SnapshotDir] = "false"
 	pool1.InternalAttributes()[ExportRule] = "1.1.1.1/32"
+	pool1.InternalAttributes()[ExportRules] = ""
 	pool1.InternalAttributes()[VirtualNetwork] = "VN1"
 	pool1.InternalAttributes()[Subnet] = "SN2"
 	pool1.InternalAttributes()[NetworkFeatures] = ""
@@ -951,6 +1457,20 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 	pool1.InternalAttributes()[NetappAccounts] = "NA1,NA2"
 	pool1.InternalAttributes()[CapacityPools] = "CP2"
 	pool1.InternalAttributes()[Kerberos] = ""
+	pool1.InternalAttributes()[BackupPolicy] = ""
+	pool1.InternalAttributes()[BackupEnabled] = "false"
+	pool1.InternalAttributes()[LDAPEnabled] = ""
+	pool1.InternalAttributes()[SnapshotReserve] = ""
+	pool1.InternalAttributes()[Throughput] = ""
+	pool1.InternalAttributes()[CoolAccess] = "true"
+	pool1.InternalAttributes()[CoolnessPeriod] = "60"
+	pool1.InternalAttributes()[CoolAccessRetrievalPolicy] = ""
+	pool1.InternalAttributes()[SnapshotPolicy] = "policy2"
+	pool1.InternalAttributes()[SubnetSelection] = ""
+	pool1.InternalAttributes()[SplitOnClone] = "true"
+	pool1.InternalAttributes()[SMBContinuousAvailability] = ""
+	pool1.InternalAttributes()[SMBEncryption] = ""
+	pool1.InternalAttributes()[LargeVolume] = ""
 
 	pool1.SetSupportedTopologies(supportedTopologies)
 
@@ -962,6 +1482,35 @@ func TestInitializeStoragePools_VirtualPools(t *testing.T) {
 	assert.Equal(t, expectedPools, driver.pools, "pools do not match")
 }
 
+func TestInitializeStoragePools_ConcurrentAccess(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			pool, ok := driver.getPool("anf_pool")
+			assert.True(t, ok, "pool not found")
+			assert.NotNil(t, pool, "pool is nil")
+			assert.Len(t, driver.getPools(), 1, "pool count mismatch")
+			driver.poolsWithoutServiceLevel()
+		}()
+		go func() {
+			defer wg.Done()
+			driver.initializeStoragePools(ctx)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, driver.getPools(), 1, "pool count mismatch")
+}
+
 func TestInitialize_KerberosDisabledInPool(t *testing.T) {
 	defer acp.SetAPI(acp.API())
 
@@ -1000,15 +1549,18 @@ func TestInitialize_KerberosDisabledInPool(t *testing.T) {
 
 	// Have to at least one CapacityPool for ANF backends.
 	pool := &api.CapacityPool{
-		Name:          "CP1",
-		Location:      "fake-location",
-		NetAppAccount: "NA1",
-		ResourceGroup: "RG1",
+		Name:               "CP1",
+		Location:           "fake-location",
+		NetAppAccount:      "NA1",
+		ResourceGroup:      "RG1",
+		KerberosConfigured: true,
 	}
 
 	mockAPI.EXPECT().Init(ctx, gomock.Any()).Return(nil).Times(1)
 	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return([]*api.CapacityPool{pool}).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).
+		Return([]*api.CapacityPool{pool}).Times(1)
 
 	result := driver.Initialize(ctx, tridentconfig.ContextCSI, configJSON, commonConfig,
 		map[string]string{}, BackendUUID)
@@ -1046,6 +1598,85 @@ func TestValidate_InvalidExportRule(t *testing.T) {
 	assert.Error(t, result, "validate did not fail")
 }
 
+func TestValidate_ExportRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []drivers.AzureNASExportRule
+		valid bool
+	}{
+		{"Valid", []drivers.AzureNASExportRule{
+			{AllowedClients: "10.0.0.0/24", UnixReadOnly: true},
+			{AllowedClients: "10.0.1.0/24, 10.0.2.5", UnixReadWrite: true, Nfsv3: true},
+		}, true},
+		{"NoClients", []drivers.AzureNASExportRule{{UnixReadOnly: true}}, false},
+		{"InvalidCIDR", []drivers.AzureNASExportRule{{AllowedClients: "10.0.0.0/33"}}, false},
+		{"InvalidAddress", []drivers.AzureNASExportRule{{AllowedClients: "10.0.0.0/24,1.2.3.4.5"}}, false},
+		{"ConflictingAccess", []drivers.AzureNASExportRule{
+			{AllowedClients: "10.0.0.0/24", UnixReadOnly: true, UnixReadWrite: true},
+		}, false},
+		{"Shorthand", []drivers.AzureNASExportRule{
+			{CIDR: "10.0.0.0/24", Access: "ro", Protocol: "nfsv3"},
+			{CIDR: "10.0.1.0/24", Access: "RW", Protocol: "NFSv4.1"},
+		}, true},
+		{"ShorthandInvalidCIDR", []drivers.AzureNASExportRule{{CIDR: "10.0.0.0/33", Access: "rw"}}, false},
+		{"ShorthandCIDRAndClients", []drivers.AzureNASExportRule{
+			{CIDR: "10.0.0.0/24", AllowedClients: "10.0.1.0/24"},
+		}, false},
+		{"ShorthandInvalidAccess", []drivers.AzureNASExportRule{{CIDR: "10.0.0.0/24", Access: "wo"}}, false},
+		{"ShorthandConflictingAccess", []drivers.AzureNASExportRule{
+			{CIDR: "10.0.0.0/24", Access: "ro", UnixReadWrite: true},
+		}, false},
+		{"ShorthandInvalidProtocol", []drivers.AzureNASExportRule{
+			{CIDR: "10.0.0.0/24", Protocol: "cifs"},
+		}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.ExportRules = test.rules
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidExportRule_SMB(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = sa.SMB
+	driver.Config.NfsMountOptions = ""
+	driver.Config.ExportRule = "1.2.3.4.5"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
+
+	assert.NoError(t, result, "validate failed")
+}
+
+func TestValidate_NoExportRule_SMB(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = sa.SMB
+	driver.Config.NfsMountOptions = ""
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
+
+	assert.NoError(t, result, "validate failed")
+	for _, pool := range driver.pools {
+		assert.Equal(t, "", pool.InternalAttributes()[ExportRule], "export rule set for SMB pool")
+	}
+}
+
 func TestValidate_InvalidSnapshotDir(t *testing.T) {
 	_, driver := newMockANFDriver(t)
 	driver.Config.SnapshotDir = "yes"
@@ -1057,6 +1688,51 @@ func TestValidate_InvalidSnapshotDir(t *testing.T) {
 	assert.Error(t, result, "validate did not fail")
 }
 
+func TestValidate_InvalidSplitOnClone(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.SplitOnClone = "yes"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
+
+	assert.ErrorContains(t, result, "splitOnClone", "validate did not fail")
+}
+
+func TestValidate_LargeVolume(t *testing.T) {
+	tests := []struct {
+		Name            string
+		LargeVolume     string
+		LimitVolumeSize string
+		Valid           bool
+	}{
+		{"Unset", "", "", true},
+		{"Enabled", "true", "", true},
+		{"Disabled", "false", "1Ti", true},
+		{"EnabledWithLimit", "true", "60Ti", true},
+		{"EnabledBelowLimit", "true", "1Ti", false},
+		{"Invalid", "maybe", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.LargeVolume = test.LargeVolume
+			driver.Config.LimitVolumeSize = test.LimitVolumeSize
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
 func TestValidate_ValidUnixPermissions(t *testing.T) {
 	_, driver := newMockANFDriver(t)
 	driver.Config.UnixPermissions = "0777"
@@ -1090,6 +1766,295 @@ func TestValidate_InvalidSize(t *testing.T) {
 	assert.Error(t, result, "validate did not fail")
 }
 
+func TestValidate_LimitVolumeSize(t *testing.T) {
+	tests := []struct {
+		LimitVolumeSize string
+		Valid           bool
+	}{
+		{"", true},
+		{"100Gi", true},
+		{"1Ti", true},
+		{"107374182400", true},
+		{"99Gi", false},
+		{"50G", false},
+		{"107374182399", false},
+		{"abcde", false},
+	}
+	for _, test := range tests {
+		t.Run(test.LimitVolumeSize, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.LimitVolumeSize = test.LimitVolumeSize
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestValidate_MinimumVolumeSize(t *testing.T) {
+	tests := []struct {
+		MinimumVolumeSize string
+		LimitVolumeSize   string
+		Valid             bool
+	}{
+		{"", "", true},
+		{"50Gi", "", true},
+		{"1G", "", true},
+		{"1000000000", "", true},
+		{"200Gi", "", true},
+		{"50Gi", "50Gi", true},
+		{"50Gi", "49Gi", false},
+		{"200Gi", "100Gi", false},
+		{"999999999", "", false},
+		{"500M", "", false},
+		{"abcde", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.MinimumVolumeSize+"_"+test.LimitVolumeSize, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.MinimumVolumeSize = test.MinimumVolumeSize
+			driver.Config.LimitVolumeSize = test.LimitVolumeSize
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestMinimumVolumeSize(t *testing.T) {
+	tests := []struct {
+		MinimumVolumeSize string
+		Expected          uint64
+	}{
+		{"", MinimumANFVolumeSizeBytes},
+		{"50Gi", 53687091200},
+		{"1000000000", MinimumVolumeSizeBytes},
+		{"999999999", MinimumANFVolumeSizeBytes},
+		{"abcde", MinimumANFVolumeSizeBytes},
+	}
+	for _, test := range tests {
+		t.Run(test.MinimumVolumeSize, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.MinimumVolumeSize = test.MinimumVolumeSize
+
+			assert.Equal(t, test.Expected, driver.minimumVolumeSize(), "minimum size mismatch")
+		})
+	}
+}
+
+func TestValidate_LDAPEnabled(t *testing.T) {
+	tests := []struct {
+		Name                      string
+		NASType                   string
+		MountOptions              string
+		LDAPEnabled               string
+		ActiveDirectoryConfigured bool
+		Valid                     bool
+	}{
+		{"SMB", sa.SMB, "", "true", true, true},
+		{"Dual", NASTypeDual, "", "true", true, true},
+		{"SMBDisabled", sa.SMB, "", "false", false, true},
+		{"NoActiveDirectory", sa.SMB, "", "true", false, false},
+		{"NFSv3", sa.NFS, "", "true", true, false},
+		{"NFSv41", sa.NFS, "nfsvers=4.1", "true", true, true},
+		{"NFSv41NoActiveDirectory", sa.NFS, "nfsvers=4.1", "true", false, false},
+		{"Invalid", sa.SMB, "", "maybe", true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.NASType = test.NASType
+			driver.Config.NfsMountOptions = test.MountOptions
+			driver.Config.LDAPEnabled = test.LDAPEnabled
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			cPool := &api.CapacityPool{
+				Name:                      "CP1",
+				FullName:                  "RG1/NA1/CP1",
+				ActiveDirectoryConfigured: test.ActiveDirectoryConfigured,
+			}
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Return(
+				[]*api.CapacityPool{cPool}).AnyTimes()
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestValidate_SMBOptions(t *testing.T) {
+	tests := []struct {
+		Name                   string
+		NASType                string
+		ContinuousAvailability string
+		Encryption             string
+		Valid                  bool
+	}{
+		{"SMB", sa.SMB, "true", "true", true},
+		{"SMBDisabled", sa.SMB, "false", "false", true},
+		{"NFS", sa.NFS, "true", "", false},
+		{"Dual", NASTypeDual, "", "true", false},
+		{"NFSDisabled", sa.NFS, "false", "false", true},
+		{"InvalidContinuousAvailability", sa.SMB, "maybe", "", false},
+		{"InvalidEncryption", sa.SMB, "", "maybe", false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.NASType = test.NASType
+			driver.Config.SMBContinuousAvailability = test.ContinuousAvailability
+			driver.Config.SMBEncryption = test.Encryption
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestValidate_Kerberos(t *testing.T) {
+	tests := []struct {
+		Name               string
+		KerberosConfigured bool
+		Valid              bool
+	}{
+		{"RealmConfigured", true, true},
+		{"NoRealm", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			defer acp.SetAPI(acp.API())
+
+			mockCtrl := gomock.NewController(t)
+			mockACP := mockacp.NewMockTridentACP(mockCtrl)
+			acp.SetAPI(mockACP)
+
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.Kerberos = api.MountOptionKerberos5
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			cPool := &api.CapacityPool{
+				Name:                      "CP1",
+				FullName:                  "RG1/NA1/CP1",
+				ActiveDirectoryConfigured: true,
+				KerberosConfigured:        test.KerberosConfigured,
+			}
+			mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Return(
+				[]*api.CapacityPool{cPool}).Times(1)
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+				assert.Contains(t, result.Error(), "Kerberos realm", "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidate_NFSv41ACLExportOptions(t *testing.T) {
+	tests := []struct {
+		Name            string
+		NASType         string
+		NfsMountOptions string
+		HasRootAccess   string
+		ChownMode       string
+		Valid           bool
+	}{
+		{"NFSv41", sa.NFS, "nfsvers=4.1", "false", "restricted", true},
+		{"NFSv4", sa.NFS, "nfsvers=4", "true", api.ChownModeUnrestricted, true},
+		{"Dual", NASTypeDual, "nfsvers=4.1", "false", "", true},
+		{"NFSv3", sa.NFS, "nfsvers=3", "false", "", false},
+		{"DefaultVersion", sa.NFS, "", "", api.ChownModeRestricted, false},
+		{"SMB", sa.SMB, "", "false", "", false},
+		{"InvalidHasRootAccess", sa.NFS, "nfsvers=4.1", "maybe", "", false},
+		{"InvalidChownMode", sa.NFS, "nfsvers=4.1", "", "sometimes", false},
+		{"NotConfigured", sa.NFS, "nfsvers=3", "", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.NASType = test.NASType
+			driver.Config.NfsMountOptions = test.NfsMountOptions
+			driver.Config.HasRootAccess = test.HasRootAccess
+			driver.Config.ChownMode = test.ChownMode
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
+func TestValidate_KerberosNfsVersion(t *testing.T) {
+	tests := []struct {
+		KerberosNfsVersion string
+		Valid              bool
+	}{
+		{nfsVersion41, true},
+		{nfsVersion4, true},
+		{nfsVersion3, false},
+		{"5", false},
+	}
+	for _, test := range tests {
+		t.Run(test.KerberosNfsVersion, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.KerberosNfsVersion = test.KerberosNfsVersion
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			result := driver.validate(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
+
 func TestValidate_InvalidLabel(t *testing.T) {
 	_, driver := newMockANFDriver(t)
 	driver.Config.Labels = map[string]string{
@@ -1116,542 +2081,794 @@ func TestValidate_InvalidNetworkFeatures(t *testing.T) {
 	assert.Error(t, result, "validate did not fail")
 }
 
-func getStructsForCreateNFSVolume(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
-	*storage.VolumeConfig, *api.CapacityPool, *api.Subnet, *api.FilesystemCreateRequest, *api.FileSystem,
-) {
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-	}
+func TestValidate_InvalidSubnetSelection(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.SubnetSelection = "largest"
 
-	capacityPool := &api.CapacityPool{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		Name:              "CP1",
-		FullName:          "RG1/NA1/CP1",
-		Location:          Location,
-		ServiceLevel:      api.ServiceLevelUltra,
-		ProvisioningState: api.StateAvailable,
-		QosType:           "Auto",
-	}
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
 
-	subnet := &api.Subnet{
-		ID:             api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1"),
-		ResourceGroup:  "RG2",
-		VirtualNetwork: "VN1",
-		Name:           "SN1",
-		FullName:       "RG1/VN1/SN1",
-		Location:       Location,
-	}
+	assert.Error(t, result, "validate did not fail")
+}
 
-	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+func TestValidate_InitialDiscoveryFailed(t *testing.T) {
+	defer acp.SetAPI(acp.API())
 
-	apiExportRule := api.ExportRule{
-		AllowedClients: defaultExportRule,
-		Cifs:           false,
-		Nfsv3:          true,
-		Nfsv41:         false,
-		RuleIndex:      1,
-		UnixReadOnly:   false,
-		UnixReadWrite:  true,
-	}
-	exportPolicy := api.ExportPolicy{
-		Rules: []api.ExportRule{apiExportRule},
-	}
+	mockCtrl := gomock.NewController(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
-	labels[storage.ProvisioningLabelTag] = poolLabels
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.Kerberos = api.MountOptionKerberos5
+	driver.initialDiscoveryFailed = true
 
-	createRequest := &api.FilesystemCreateRequest{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              volConfig.Name,
-		SubnetID:          subnet.ID,
-		CreationToken:     volConfig.InternalName,
-		ExportPolicy:      exportPolicy,
-		Labels:            labels,
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-		UnixPermissions:   defaultUnixPermissions,
-	}
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
 
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-		SubnetID:          subnet.ID,
-		UnixPermissions:   defaultUnixPermissions,
-	}
+	result := driver.validate(ctx)
 
-	return volConfig, capacityPool, subnet, createRequest, filesystem
+	assert.NoError(t, result, "validate failed")
 }
 
-func getMultipleCapacityPoolsForCreateVolume() []*api.CapacityPool {
-	return []*api.CapacityPool{
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			Name:              "CP1",
-			FullName:          "RG1/NA1/CP1",
-			Location:          Location,
-			ServiceLevel:      api.ServiceLevelUltra,
-			ProvisioningState: api.StateAvailable,
-			QosType:           "Auto",
-		},
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			Name:              "CP2",
-			FullName:          "RG1/NA1/CP2",
-			Location:          Location,
-			ServiceLevel:      api.ServiceLevelUltra,
-			ProvisioningState: api.StateAvailable,
-			QosType:           "Auto",
-		},
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			Name:              "CP3",
-			FullName:          "RG1/NA1/CP3",
-			Location:          Location,
-			ServiceLevel:      api.ServiceLevelUltra,
-			ProvisioningState: api.StateAvailable,
-			QosType:           "Auto",
-		},
+func TestRefreshAzureResources_ValidatesAfterInitialDiscoveryFailed(t *testing.T) {
+	tests := []struct {
+		Name               string
+		KerberosConfigured bool
+		Valid              bool
+	}{
+		{"RealmConfigured", true, true},
+		{"NoRealm", false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.Kerberos = api.MountOptionKerberos5
+			driver.initialDiscoveryFailed = true
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			cPool := &api.CapacityPool{
+				Name:                      "CP1",
+				FullName:                  "RG1/NA1/CP1",
+				ActiveDirectoryConfigured: true,
+				KerberosConfigured:        test.KerberosConfigured,
+			}
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(2)
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Return(
+				[]*api.CapacityPool{cPool}).MinTimes(1)
+
+			result := driver.refreshAzureResources(ctx)
+
+			if test.Valid {
+				assert.NoError(t, result, "refresh failed")
+				assert.False(t, driver.initialDiscoveryFailed, "validation still pending")
+			} else {
+				assert.Error(t, result, "refresh did not fail")
+				assert.Contains(t, result.Error(), "Kerberos realm", "unexpected error")
+				assert.True(t, driver.initialDiscoveryFailed, "validation not pending")
+			}
+
+			// The pools are validated again by the next operation until they pass
+			result = driver.refreshAzureResources(ctx)
+
+			assert.Equal(t, test.Valid, result == nil, "unexpected result")
+		})
 	}
 }
 
-func TestCreate_NFSVolume(t *testing.T) {
+func TestRefreshAzureResources_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
+	driver.initialDiscoveryFailed = true
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	storagePool := driver.pools["anf_pool"]
+	result := driver.refreshAzureResources(ctx)
 
-	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	assert.Error(t, result, "refresh did not fail")
+	assert.True(t, driver.initialDiscoveryFailed, "validation not pending")
+}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+func TestValidate_MountOptionsForNASType(t *testing.T) {
+	tests := []struct {
+		name            string
+		nasType         string
+		nfsMountOptions string
+		kerberos        string
+		valid           bool
+	}{
+		{"NFS", sa.NFS, "nfsvers=4.1,hard", "", true},
+		{"NFSDefault", sa.NFS, "", "", true},
+		{"Dual", NASTypeDual, "nfsvers=3", "", true},
+		{"SMBDefault", sa.SMB, "", "", true},
+		{"SMBWithNFSOptions", sa.SMB, "nfsvers=4.1", "", false},
+		{"NFSWithSMBOptions", sa.NFS, "nfsvers=3,username=admin", "", false},
+		{"NFSWithNTLMSecurity", sa.NFS, "sec=ntlmssp", "", false},
+		{"DualWithSMBOptions", NASTypeDual, "-o domain=corp", "", false},
+		{"Kerberos", sa.NFS, "nfsvers=4.1,sec=krb5p", api.MountOptionKerberos5P, true},
+		{"KerberosOptionsWithoutKerberos", sa.NFS, "nfsvers=4.1,sec=krb5", "", false},
+		{"KerberosIntegrityWithoutKerberos", sa.NFS, "nfsvers=4.1, sec=krb5i", "", false},
+		{"KerberosFlavorsWithoutKerberos", sa.NFS, "nfsvers=4.1,sec=sys:krb5p", "", false},
+		{"SysSecurity", sa.NFS, "nfsvers=4.1,sec=sys", "", true},
+		{"NFSv4", sa.NFS, "nfsvers=4", "", true},
+		{"NFSv40", sa.NFS, "nfsvers=4.0", "", false},
+		{"NFSv4MinorVersion0", sa.NFS, "vers=4,minorversion=0", "", false},
+		{"UnsupportedNFSVersion", sa.NFS, "nfsvers=4.2", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer acp.SetAPI(acp.API())
+
+			mockCtrl := gomock.NewController(t)
+			mockACP := mockacp.NewMockTridentACP(mockCtrl)
+			acp.SetAPI(mockACP)
+
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.NASType = test.nasType
+			driver.Config.NfsMountOptions = test.nfsMountOptions
+			driver.Config.Kerberos = test.kerberos
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			cPool := &api.CapacityPool{
+				Name:                      "CP1",
+				FullName:                  "RG1/NA1/CP1",
+				ActiveDirectoryConfigured: true,
+				KerberosConfigured:        true,
+			}
+			mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), gomock.Any()).Return(
+				[]*api.CapacityPool{cPool}).AnyTimes()
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+			result := driver.validate(ctx)
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "0777", volConfig.UnixPermissions)
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+				assert.Contains(t, result.Error(), "nfsMountOptions", "error does not name the field")
+			}
+		})
+	}
 }
 
-func TestCreate_NFSVolume_MultipleCapacityPools_FirstSucceeds(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
+func TestValidate_SMBWithKerberos(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = sa.SMB
+	driver.Config.Kerberos = api.MountOptionKerberos5
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	storagePool := driver.pools["anf_pool"]
-
-	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+	result := driver.validate(ctx)
 
-	createRequest.ResourceGroup = capacityPools[0].ResourceGroup
-	createRequest.NetAppAccount = capacityPools[0].NetAppAccount
-	createRequest.CapacityPool = capacityPools[0].Name
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	assert.Error(t, result, "validate did not fail")
+}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return(capacityPools).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+func TestValidate_DualProtocolWithKerberos(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = NASTypeDual
+	driver.Config.Kerberos = api.MountOptionKerberos5
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "0777", volConfig.UnixPermissions)
+	assert.Error(t, result, "validate did not fail")
 }
 
-func TestCreate_NFSVolume_MultipleCapacityPools_SecondSucceeds(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
+func TestValidate_InvalidCloud(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.Cloud = "AzureMoonCloud"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	storagePool := driver.pools["anf_pool"]
+	result := driver.validate(ctx)
 
-	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+	assert.Error(t, result, "validate did not fail")
+}
 
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+func TestValidate_Cloud(t *testing.T) {
+	for _, cloudName := range []string{
+		"", api.CloudAzurePublic, api.CloudAzureUSGovernment, "AzureUSGovernmentCloud", api.CloudAzureChina,
+	} {
+		t.Run(cloudName, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.Cloud = cloudName
 
-	createRequest1 := *createRequest
-	createRequest1.ResourceGroup = capacityPools[0].ResourceGroup
-	createRequest1.NetAppAccount = capacityPools[0].NetAppAccount
-	createRequest1.CapacityPool = capacityPools[0].Name
-	createRequest2 := *createRequest
-	createRequest2.ResourceGroup = capacityPools[1].ResourceGroup
-	createRequest2.NetAppAccount = capacityPools[1].NetAppAccount
-	createRequest2.CapacityPool = capacityPools[1].Name
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.ResourceGroup = capacityPools[1].ResourceGroup
-	filesystem.NetAppAccount = capacityPools[1].NetAppAccount
-	filesystem.CapacityPool = capacityPools[1].Name
+			assert.NoError(t, result, "validate failed")
+		})
+	}
+}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return(capacityPools).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, &createRequest1).Return(nil, errFailed).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
-
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+func TestNewClientConfig_Cloud(t *testing.T) {
+	tests := []struct {
+		cloud    string
+		expected string
+	}{
+		{"", api.CloudAzurePublic},
+		{api.CloudAzurePublic, api.CloudAzurePublic},
+		{api.CloudAzureUSGovernment, api.CloudAzureUSGovernment},
+		{api.CloudAzureChina, api.CloudAzureChina},
+	}
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "0777", volConfig.UnixPermissions)
-}
+	for _, test := range tests {
+		t.Run(test.cloud, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.Cloud = test.cloud
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
 
-func TestCreate_NFSVolume_MultipleCapacityPools_NoneSucceeds(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
+			clientConfig, err := driver.newClientConfig(ctx, &driver.Config)
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expected, clientConfig.CloudName, "cloud mismatch")
+		})
+	}
+}
 
-	storagePool := driver.pools["anf_pool"]
+func TestNewClientConfig_SDKRetries(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxRetries      string
+		retryBackoff    string
+		retryTimeout    string
+		expectedRetries int32
+		expectedBackoff time.Duration
+		expectedTimeout time.Duration
+	}{
+		{"Defaults", "", "", "", 0, 0, 0},
+		{"Configured", "5", "3", "120", 5, 3 * time.Second, 2 * time.Minute},
+		{"NoRetries", "0", "", "", -1, 0, 0},
+	}
 
-	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.SDKMaxRetries = test.maxRetries
+			driver.Config.SDKRetryBackoff = test.retryBackoff
+			driver.Config.SDKRetryTimeout = test.retryTimeout
 
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+			clientConfig, err := driver.newClientConfig(ctx, &driver.Config)
 
-	createRequest1 := *createRequest
-	createRequest1.ResourceGroup = capacityPools[0].ResourceGroup
-	createRequest1.NetAppAccount = capacityPools[0].NetAppAccount
-	createRequest1.CapacityPool = capacityPools[0].Name
-	createRequest2 := *createRequest
-	createRequest2.ResourceGroup = capacityPools[1].ResourceGroup
-	createRequest2.NetAppAccount = capacityPools[1].NetAppAccount
-	createRequest2.CapacityPool = capacityPools[1].Name
-	createRequest3 := *createRequest
-	createRequest3.ResourceGroup = capacityPools[2].ResourceGroup
-	createRequest3.NetAppAccount = capacityPools[2].NetAppAccount
-	createRequest3.CapacityPool = capacityPools[2].Name
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedRetries, clientConfig.SDKMaxRetries, "max retries mismatch")
+			assert.Equal(t, test.expectedBackoff, clientConfig.SDKRetryBackoff, "retry backoff mismatch")
+			assert.Equal(t, test.expectedTimeout, clientConfig.SDKRetryTimeout, "retry timeout mismatch")
+		})
+	}
+}
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.ResourceGroup = capacityPools[1].ResourceGroup
-	filesystem.NetAppAccount = capacityPools[1].NetAppAccount
-	filesystem.CapacityPool = capacityPools[1].Name
+func TestNewClientConfig_InvalidSDKRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   string
+		retryBackoff string
+		retryTimeout string
+	}{
+		{"MaxRetriesNotInteger", "many", "", ""},
+		{"MaxRetriesNegative", "-1", "", ""},
+		{"RetryBackoffNotInteger", "", "2s", ""},
+		{"RetryBackoffNegative", "", "-2", ""},
+		{"RetryTimeoutNotInteger", "", "", "1m"},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return(capacityPools).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, &createRequest1).Return(nil, errFailed).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(nil, errFailed).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, &createRequest3).Return(nil, errFailed).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.SDKMaxRetries = test.maxRetries
+			driver.Config.SDKRetryBackoff = test.retryBackoff
+			driver.Config.SDKRetryTimeout = test.retryTimeout
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+			_, err := driver.newClientConfig(ctx, &driver.Config)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+			assert.Error(t, err, "expected error")
+		})
+	}
 }
 
-func TestCreate_NFSVolume_Kerberos_type5(t *testing.T) {
-	defer acp.SetAPI(acp.API())
-
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
-
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
-	driver.Config.NfsMountOptions = ""
-	driver.Config.Kerberos = "sec=krb5"
+func TestValidate_InvalidNASType(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = "iscsi"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	result := driver.validate(ctx)
 
-	storagePool := driver.pools["anf_pool"]
+	assert.Error(t, result, "validate did not fail")
+}
 
-	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+func TestValidate_SnapshotPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		valid  bool
+	}{
+		{"Name", "daily-policy_1", true},
+		{"ID", "/subscriptions/mySubscription/resourceGroups/RG1/providers/Microsoft.NetApp/netAppAccounts/NA1/snapshotPolicies/daily", true},
+		{"InvalidName", "daily policy", false},
+		{"InvalidID", "/subscriptions/mySubscription/snapshotPolicies/daily", false},
+	}
 
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.SnapshotPolicy = test.policy
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+func TestValidate_CoolAccess(t *testing.T) {
+	tests := []struct {
+		name            string
+		serviceLevel    string
+		coolAccess      string
+		coolnessPeriod  string
+		retrievalPolicy string
+		valid           bool
+	}{
+		{"Standard", api.ServiceLevelStandard, "true", "2", "", true},
+		{"NoServiceLevel", "", "true", "183", api.CoolAccessRetrievalPolicyDefault, true},
+		{"Disabled", api.ServiceLevelUltra, "false", "31", "", true},
+		{"InvalidCoolAccess", api.ServiceLevelStandard, "yes", "31", "", false},
+		{"UnsupportedServiceLevel", api.ServiceLevelPremium, "true", "31", "", false},
+		{"PeriodTooShort", api.ServiceLevelStandard, "true", "1", "", false},
+		{"PeriodTooLong", api.ServiceLevelStandard, "true", "184", "", false},
+		{"InvalidPeriod", api.ServiceLevelStandard, "true", "month", "", false},
+		{"UnsupportedRetrievalPolicy", api.ServiceLevelStandard, "true", "31", "OnRead", false},
+	}
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "0777", volConfig.UnixPermissions)
-}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.ServiceLevel = test.serviceLevel
+			driver.Config.CoolAccess = test.coolAccess
+			driver.Config.CoolnessPeriod = test.coolnessPeriod
+			driver.Config.CoolAccessRetrievalPolicy = test.retrievalPolicy
 
-func TestCreate_NFSVolume_Kerberos_type5_FailsEntitlementCheck(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
+}
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
+func TestValidate_CoolAccess_SMB(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = sa.SMB
 	driver.Config.NfsMountOptions = ""
-	driver.Config.Kerberos = "sec=krb5"
+	driver.Config.ServiceLevel = api.ServiceLevelPremium
+	driver.Config.CoolAccess = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	result := driver.validate(ctx)
 
-	storagePool := driver.pools["anf_pool"]
+	assert.NoError(t, result, "validate failed")
+}
 
-	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+func TestValidate_Throughput(t *testing.T) {
+	tests := []struct {
+		name       string
+		throughput string
+		qosType    string
+		wantErr    bool
+	}{
+		{"ManualQoS", "128", api.QosTypeManual, false},
+		{"AutoQoS", "128", api.QosTypeAuto, true},
+		{"NotANumber", "fast", api.QosTypeManual, true},
+		{"Zero", "0", api.QosTypeManual, true},
+	}
 
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.Throughput = test.throughput
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+			cPools := []*api.CapacityPool{
+				{Name: "CP1", ResourceGroup: "RG1", NetAppAccount: "NA1", FullName: "RG1/NA1/CP1", QosType: test.qosType},
+			}
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, gomock.Any(), "").Return(cPools).AnyTimes()
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+			if test.wantErr {
+				assert.Error(t, result, "validate did not fail")
+			} else {
+				assert.NoError(t, result, "validate failed")
+			}
+		})
+	}
+}
 
-	assert.Error(t, result, "create suceeded")
+func TestValidate_SnapshotReserve(t *testing.T) {
+	tests := []struct {
+		name            string
+		snapshotReserve string
+		wantErr         bool
+	}{
+		{"Unset", "", false},
+		{"Zero", "0", false},
+		{"Valid", "20", false},
+		{"Maximum", "90", false},
+		{"Negative", "-1", true},
+		{"TooLarge", "91", true},
+		{"NotANumber", "lots", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.SnapshotReserve = test.snapshotReserve
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			if test.wantErr {
+				assert.ErrorContains(t, result, "_pool", "validate did not fail")
+			} else {
+				assert.NoError(t, result, "validate failed")
+			}
+		})
+	}
 }
 
-func TestCreate_NFSVolume_Kerberos_type5I(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+func TestValidate_ExportRuleValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		exportRule string
+		allowOpen  bool
+		rejectErr  bool
+	}{
+		{"Normal", "10.0.0.0/8,192.168.1.1", false, false},
+		{"OpenIPv4", "0.0.0.0/0", false, true},
+		{"OpenIPv4Acknowledged", "0.0.0.0/0", true, false},
+		{"OpenIPv6", "::/0", false, true},
+		{"Unspecified", "0.0.0.0", false, true},
+		{"LoopbackAddress", "127.0.0.1", false, true},
+		{"LoopbackCIDR", "127.0.0.0/8", false, true},
+		{"LoopbackIPv6", "::1", false, true},
+		{"LinkLocal", "169.254.0.0/16", false, true},
+		{"LinkLocalIPv6", "fe80::1", false, true},
+		{"Multicast", "224.0.0.0/4", false, true},
+		{"MulticastAddress", "239.1.2.3", false, true},
+		{"MulticastIPv6", "ff02::1", false, true},
+	}
 
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	for _, test := range tests {
+		for _, mode := range []string{ExportRuleValidationLenient, ExportRuleValidationWarn, ExportRuleValidationReject} {
+			t.Run(test.name+"_"+mode, func(t *testing.T) {
+				_, driver := newMockANFDriver(t)
+				driver.Config.ExportRule = test.exportRule
+				driver.Config.ExportRuleValidation = mode
+				driver.Config.AllowOpenExportRule = test.allowOpen
+
+				driver.populateConfigurationDefaults(ctx, &driver.Config)
+				driver.initializeStoragePools(ctx)
+				result := driver.validate(ctx)
+
+				// Suspicious addresses are only logged in warn mode
+				if mode == ExportRuleValidationReject && test.rejectErr {
+					assert.Error(t, result, "validate did not fail")
+				} else {
+					assert.NoError(t, result, "validate failed")
+				}
+			})
+		}
+	}
+}
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5i"
+func TestValidate_ExportRuleValidation_StructuredRules(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.ExportRuleValidation = ExportRuleValidationReject
+	driver.Config.ExportRules = []drivers.AzureNASExportRule{
+		{AllowedClients: "10.0.0.0/8", Nfsv3: true, UnixReadWrite: true},
+		{AllowedClients: "10.1.0.0/16, 127.0.0.1", Nfsv3: true, UnixReadOnly: true},
+	}
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	result := driver.validate(ctx)
 
-	storagePool := driver.pools["anf_pool"]
+	assert.ErrorContains(t, result, "export rule 2", "validate did not reject loopback address")
 
-	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	// The wide-open default export rule is superseded by the structured rules, so it isn't checked
+	driver.Config.ExportRules[1].AllowedClients = "10.1.0.0/16"
+	driver.initializeStoragePools(ctx)
+	result = driver.validate(ctx)
 
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	assert.NoError(t, result, "validate failed")
+}
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+func TestValidate_InvalidExportRuleValidation(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.ExportRuleValidation = "invalid"
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+	assert.Error(t, result, "validate did not fail")
+}
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "0777", volConfig.UnixPermissions)
+func TestValidate_InvalidNamespaceAnnotationTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+	}{
+		{"Empty", ""},
+		{"TooLong", strings.Repeat("a", api.MaxTagKeyLength+1)},
+		{"InvalidCharacter", "cost/center"},
+		{"Reserved", drivers.TridentLabelTag},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.NamespaceAnnotationTags = map[string]string{"example.com/cost-center": test.tag}
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			assert.Error(t, result, "validate did not fail")
+		})
+	}
 }
 
-func TestCreate_NFSVolume_Kerberos_type5I_FailsEntitlementCheck(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+func TestGetNamespaceAnnotationTags(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NamespaceAnnotationTags = map[string]string{
+		"example.com/cost-center": "costCenter",
+		"example.com/team":        "team",
+		"example.com/missing":     "missing",
+	}
 
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	volConfig := &storage.VolumeConfig{
+		Namespace: "finance",
+		NamespaceAnnotations: map[string]string{
+			"example.com/cost-center": "cc-1234",
+			"example.com/team":        "payments",
+			"example.com/unmapped":    "ignored",
+		},
+	}
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5i"
+	expected := map[string]string{
+		"costCenter": "cc-1234",
+		"team":       "payments",
+	}
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
+	result := driver.getNamespaceAnnotationTags(ctx, volConfig)
+
+	assert.Equal(t, expected, result, "tags mismatch")
+}
+
+func TestGetNamespaceAnnotationTags_NoAnnotations(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NamespaceAnnotationTags = map[string]string{"example.com/cost-center": "costCenter"}
+
+	result := driver.getNamespaceAnnotationTags(ctx, &storage.VolumeConfig{})
+
+	assert.Empty(t, result, "expected no tags")
+}
+
+func TestGetVersionTags(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.telemetry.TridentVersion = "24.02.0"
 
-	storagePool := driver.pools["anf_pool"]
+	assert.Empty(t, driver.getVersionTags(ctx), "expected no tags when disabled")
 
-	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	driver.Config.TagTridentVersion = true
 
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	assert.Equal(t, map[string]string{TridentVersionTag: "24.02.0"}, driver.getVersionTags(ctx), "tag mismatch")
 
-	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	driver.telemetry.TridentVersion = strings.Repeat("a", api.MaxLabelLength+1)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
+	assert.Empty(t, driver.getVersionTags(ctx), "expected no tags for oversized version")
+}
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+func TestValidate_InvalidCustomTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		tag   string
+		value string
+	}{
+		{"EmptyKey", "", "value"},
+		{"KeyTooLong", strings.Repeat("a", api.MaxTagKeyLength+1), "value"},
+		{"InvalidCharacter", "cost/center", "value"},
+		{"Reserved", storage.ProvisioningLabelTag, "value"},
+		{"ValueTooLong", "costCenter", strings.Repeat("a", api.MaxLabelLength+1)},
+	}
 
-	assert.Error(t, result, "create succeeded")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.CustomTags = map[string]string{test.tag: test.value}
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			assert.ErrorContains(t, result, "custom tag", "validate did not fail")
+		})
+	}
 }
 
-func TestCreate_NFSVolume_Kerberos_type5P(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+func TestGetCustomTags(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.CustomTags = map[string]string{
+		"costCenter":            "cc-1234",
+		"team":                  "payments",
+		drivers.TridentLabelTag: "overwritten",
+		TridentVersionTag:       "overwritten",
+		"oversized":             strings.Repeat("a", api.MaxLabelLength+1),
+	}
 
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	expected := map[string]string{
+		"costCenter": "cc-1234",
+		"team":       "payments",
+	}
+
+	assert.Equal(t, expected, driver.getCustomTags(ctx), "tags mismatch")
+}
+
+func getStructsForCreateNFSVolume(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
+	*storage.VolumeConfig, *api.CapacityPool, *api.Subnet, *api.FilesystemCreateRequest, *api.FileSystem,
+) {
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+	}
+
+	capacityPool := &api.CapacityPool{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		Name:              "CP1",
+		FullName:          "RG1/NA1/CP1",
+		Location:          Location,
+		ServiceLevel:      api.ServiceLevelUltra,
+		ProvisioningState: api.StateAvailable,
+		QosType:           "Auto",
+	}
+
+	subnet := &api.Subnet{
+		ID:             api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1"),
+		ResourceGroup:  "RG2",
+		VirtualNetwork: "VN1",
+		Name:           "SN1",
+		FullName:       "RG1/VN1/SN1",
+		Location:       Location,
+	}
+
+	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+
+	apiExportRule := api.ExportRule{
+		AllowedClients: defaultExportRule,
+		Cifs:           false,
+		Nfsv3:          true,
+		Nfsv41:         false,
+		RuleIndex:      1,
+		UnixReadOnly:   false,
+		UnixReadWrite:  true,
+	}
+	exportPolicy := api.ExportPolicy{
+		Rules: []api.ExportRule{apiExportRule},
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+	labels[storage.ProvisioningLabelTag] = poolLabels
+	labels[StoragePoolTag] = storagePool.Name()
+
+	createRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              volConfig.Name,
+		SubnetID:          subnet.ID,
+		CreationToken:     volConfig.InternalName,
+		ExportPolicy:      exportPolicy,
+		Labels:            labels,
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+		SubnetID:          subnet.ID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	return volConfig, capacityPool, subnet, createRequest, filesystem
+}
+
+func getMultipleCapacityPoolsForCreateVolume() []*api.CapacityPool {
+	return []*api.CapacityPool{
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			Name:              "CP1",
+			FullName:          "RG1/NA1/CP1",
+			Location:          Location,
+			ServiceLevel:      api.ServiceLevelUltra,
+			ProvisioningState: api.StateAvailable,
+			QosType:           "Auto",
+		},
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			Name:              "CP2",
+			FullName:          "RG1/NA1/CP2",
+			Location:          Location,
+			ServiceLevel:      api.ServiceLevelUltra,
+			ProvisioningState: api.StateAvailable,
+			QosType:           "Auto",
+		},
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			Name:              "CP3",
+			FullName:          "RG1/NA1/CP3",
+			Location:          Location,
+			ServiceLevel:      api.ServiceLevelUltra,
+			ProvisioningState: api.StateAvailable,
+			QosType:           "Auto",
+		},
+	}
+}
 
+func TestCreate_NFSVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
 	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5p"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1660,26 +2877,15 @@ func TestCreate_NFSVolume_Kerberos_type5P(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
 	createRequest.UnixPermissions = "0777"
 	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
-
 	filesystem.UnixPermissions = "0777"
 	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
@@ -1691,25 +2897,20 @@ func TestCreate_NFSVolume_Kerberos_type5P(t *testing.T) {
 	assert.NoError(t, result, "create failed")
 	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
 	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "RG1/NA1/CP1", volConfig.CapacityPool, "capacity pool not set on volConfig")
 	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
 	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
 	assert.Equal(t, "false", volConfig.SnapshotDir)
 	assert.Equal(t, "0777", volConfig.UnixPermissions)
 }
 
-func TestCreate_NFSVolume_Kerberos_type5P_FailsEntitlementCheck(t *testing.T) {
-	defer acp.SetAPI(acp.API())
-
-	mockCtrl := gomock.NewController(t)
+func TestCreate_NFSVolume_Async(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
-
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
 	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5p"
+	driver.Config.AsyncVolumeCreate = true
+	defer driver.stopPendingVolumePoller()
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1717,43 +2918,36 @@ func TestCreate_NFSVolume_Kerberos_type5P_FailsEntitlementCheck(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-
-	createRequest.KerberosEnabled = true
-	createRequest.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
 	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
-
 	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
-	filesystem.KerberosEnabled = true
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	filesystem.ProvisioningState = api.StateAccepted
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
-
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
-	assert.Error(t, result, "create succeeded")
-}
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-func TestCreate_NFSVolume_Kerberos_type5P_failure(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+	assert.Error(t, result, "create did not return early")
+	assert.True(t, errors.IsVolumeCreatingError(result), "not VolumeCreatingError")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.True(t, driver.isPendingVolume(volConfig.InternalName), "volume not pending")
+	assert.NotNil(t, driver.pendingVolumesDone, "poller not started")
+}
 
-	mockCtrl := gomock.NewController(t)
+func TestCreate_NFSVolume_AsyncDocker(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
-
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
 	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5P"
+	driver.Config.AsyncVolumeCreate = true
+	driver.Config.DriverContext = tridentconfig.ContextDocker
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1761,29 +2955,33 @@ func TestCreate_NFSVolume_Kerberos_type5P_failure(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
 	createRequest.UnixPermissions = "0777"
-	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
-
 	filesystem.UnixPermissions = "0777"
-	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "unsupported kerberos type: sec=krb5P", "create succeeded")
-	assert.NotNil(t, result, "expected nil")
+	assert.NoError(t, result, "create failed")
+	assert.False(t, driver.isPendingVolume(volConfig.InternalName), "volume pending")
+	assert.Nil(t, driver.pendingVolumesDone, "poller started")
 }
 
-func TestCreate_DiscoveryFailed(t *testing.T) {
+func TestCreate_NFSVolume_AsyncRetryPending(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.AsyncVolumeCreate = true
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1791,20 +2989,84 @@ func TestCreate_DiscoveryFailed(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, _, _, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	filesystem.ProvisioningState = api.StateError
+	driver.pendingVolumes = map[string]*pendingVolume{
+		filesystem.CreationToken: {volume: filesystem, startTime: time.Now()},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.True(t, errors.IsVolumeCreatingError(result), "not VolumeCreatingError")
 }
 
-func TestCreate_InvalidVolumeName(t *testing.T) {
+func TestPollPendingVolumes(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		readErr   error
+		deleteErr error
+		age       time.Duration
+		deleted   bool
+		pending   bool
+	}{
+		{"Available", api.StateAvailable, nil, nil, 0, false, false},
+		{"Creating", api.StateCreating, nil, nil, 0, false, true},
+		{"Accepted", api.StateAccepted, nil, nil, 0, false, true},
+		{"CreatingTooLong", api.StateCreating, nil, nil, time.Hour, true, false},
+		{"CreatingTooLongDeleteFailed", api.StateCreating, nil, errFailed, time.Hour, true, true},
+		{"Error", api.StateError, nil, nil, 0, true, false},
+		{"ErrorDeleteFailed", api.StateError, nil, errFailed, 0, true, true},
+		{"ErrorDeleteFailedTooLong", api.StateError, nil, errFailed, time.Hour, true, false},
+		{"NotFound", "", errors.NotFoundError("not found"), nil, 0, false, false},
+		{"ReadFailed", "", errFailed, nil, 0, false, true},
+		{"ReadFailedTooLong", "", errFailed, nil, time.Hour, true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+
+			volume := &api.FileSystem{
+				ID:            api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1"),
+				CapacityPool:  "CP1",
+				CreationToken: "trident-testvol1",
+				ServiceLevel:  api.ServiceLevelUltra,
+			}
+			driver.pendingVolumes = map[string]*pendingVolume{
+				volume.CreationToken: {volume: volume, startTime: time.Now().Add(-test.age)},
+			}
+
+			var readVolume *api.FileSystem
+			deletedVolume := volume
+			if test.readErr == nil {
+				readVolume = &api.FileSystem{}
+				*readVolume = *volume
+				readVolume.ProvisioningState = test.state
+				deletedVolume = readVolume
+			}
+			mockAPI.EXPECT().VolumeByID(ctx, volume.ID).Return(readVolume, test.readErr).Times(1)
+			if test.deleted {
+				mockAPI.EXPECT().DeleteVolume(ctx, deletedVolume).Return(test.deleteErr).Times(1)
+			}
+
+			driver.pollPendingVolumes(ctx)
+
+			assert.Equal(t, test.pending, driver.isPendingVolume(volume.CreationToken), "pending mismatch")
+		})
+	}
+}
+
+func TestCreate_NFSVolume_DefaultServiceLevel(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.DefaultServiceLevel = "premium"
+	driver.Config.NASType = "nfs"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1812,21 +3074,61 @@ func TestCreate_InvalidVolumeName(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Name = "1testvol"
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelPremium).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, api.ServiceLevelPremium, volConfig.ServiceLevel)
 }
 
-func TestCreate_InvalidCreationToken(t *testing.T) {
+func getStructsForRestoreSnapshotToNewVolume(ctx context.Context, driver *NASStorageDriver) (
+	*storage.VolumeConfig, *api.FileSystem, *api.Snapshot, *api.FileSystem,
+) {
+	_, sourceVolume, _, snapshot := getStructsForCreateSnapshot(ctx, driver, time.Now())
+	sourceVolume.SnapshotDirectory = true
+	sourceVolume.ExportPolicy = api.ExportPolicy{
+		Rules: []api.ExportRule{{AllowedClients: "10.0.0.0/8", Nfsv3: true, RuleIndex: 1, UnixReadWrite: true}},
+	}
+
+	volConfig := &storage.VolumeConfig{
+		Version:             "1",
+		Name:                "restored",
+		InternalName:        "trident-restored",
+		Size:                VolumeSizeStr,
+		RestoreFromSnapshot: "trident-testvol1/snap1",
+	}
+
+	filesystem := &api.FileSystem{
+		ID:                api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "restored"),
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "restored",
+		FullName:          "RG1/NA1/CP1/restored",
+		ProvisioningState: api.StateCreating,
+		CreationToken:     "trident-restored",
+	}
+
+	return volConfig, sourceVolume, snapshot, filesystem
+}
+
+func TestCreate_RestoreFromSnapshot(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1834,21 +3136,53 @@ func TestCreate_InvalidCreationToken(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.InternalName = "1testvol"
+	volConfig, sourceVolume, snapshot, filesystem := getStructsForRestoreSnapshotToNewVolume(ctx, driver)
+	volConfig.Size = strconv.FormatInt(VolumeSizeI64*2, 10)
+
+	labels := map[string]string{
+		drivers.TridentLabelTag:      driver.getTelemetryLabels(ctx),
+		storage.ProvisioningLabelTag: "",
+	}
+
+	expectedRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "restored",
+		SubnetID:          sourceVolume.SubnetID,
+		CreationToken:     "trident-restored",
+		ExportPolicy:      sourceVolume.ExportPolicy,
+		Labels:            labels,
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64 * 2,
+		SnapshotDirectory: true,
+		SnapshotID:        SnapshotUUID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(sourceVolume, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceVolume, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, expectedRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().DeleteSnapshot(ctx, gomock.Any(), gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64*2, 10), volConfig.Size, "size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel, "service level mismatch")
+	assert.Equal(t, "RG1/NA1/CP1", volConfig.CapacityPool, "capacity pool mismatch")
+	assert.Equal(t, "true", volConfig.SnapshotDir, "snapshot directory mismatch")
 }
 
-func TestCreate_NoStoragePool(t *testing.T) {
+func TestCreate_RestoreFromSnapshot_SmallerThanSource(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1856,42 +3190,52 @@ func TestCreate_NoStoragePool(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, sourceVolume, snapshot, filesystem := getStructsForRestoreSnapshotToNewVolume(ctx, driver)
+	sourceVolume.QuotaInBytes = VolumeSizeI64 * 3
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(sourceVolume, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceVolume, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *api.FilesystemCreateRequest) (*api.FileSystem, error) {
+			assert.Equal(t, VolumeSizeI64*3, request.QuotaInBytes, "quota mismatch")
+			return filesystem, nil
+		}).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
-	result := driver.Create(ctx, volConfig, nil, nil)
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64*3, 10), volConfig.Size, "size mismatch")
 }
 
-func TestCreate_NonexistentStoragePool(t *testing.T) {
+func TestCreate_RestoreFromSnapshot_InvalidReference(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
 	storagePool := driver.pools["anf_pool"]
-	driver.pools = nil
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, _, _, _ := getStructsForRestoreSnapshotToNewVolume(ctx, driver)
+	volConfig.RestoreFromSnapshot = "snap1"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.ErrorContains(t, result, "invalid snapshot", "expected error")
 }
 
-func TestCreate_VolumeExistsCheckFailed(t *testing.T) {
+func TestCreate_RestoreFromSnapshot_SnapshotNotAvailable(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1899,45 +3243,64 @@ func TestCreate_VolumeExistsCheckFailed(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, sourceVolume, snapshot, _ := getStructsForRestoreSnapshotToNewVolume(ctx, driver)
+	snapshot.ProvisioningState = api.StateCreating
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(sourceVolume, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceVolume, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.Error(t, result, "expected error")
 }
 
-func TestCreate_VolumeExistsCreating(t *testing.T) {
+func TestCreate_NFSVolume_TridentVersionTag(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.TagTridentVersion = true
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.telemetry.TridentVersion = "24.02.0"
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	filesystem.ProvisioningState = api.StateCreating
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.Labels[TridentVersionTag] = "24.02.0"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.IsType(t, errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
 }
 
-func TestCreate_VolumeExists(t *testing.T) {
+func TestCreate_NFSVolume_CustomTags(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.CustomTags = map[string]string{
+		"costCenter": "cc-1234",
+		"team":       "payments",
+	}
+	driver.Config.NamespaceAnnotationTags = map[string]string{"example.com/cost-center": "costCenter"}
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1945,23 +3308,34 @@ func TestCreate_VolumeExists(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	filesystem.ProvisioningState = api.StateAvailable
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.NamespaceAnnotations = map[string]string{"example.com/cost-center": "cc-5678"}
+	createRequest.UnixPermissions = "0777"
+	createRequest.Labels["costCenter"] = "cc-5678"
+	createRequest.Labels["team"] = "payments"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create did not fail")
-	assert.IsType(t, drivers.NewVolumeExistsError(""), result, "not VolumeExistsError")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
 }
 
-func TestCreate_InvalidSize(t *testing.T) {
+func TestCreate_NFSVolume_SnapshotReserve(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.SnapshotReserve = "20"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -1969,46 +3343,129 @@ func TestCreate_InvalidSize(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = "invalid"
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.QuotaInBytes = VolumeSizeI64 * 100 / 80
+	createRequest.Labels[SnapshotReserveTag] = "20"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, "20", volConfig.SnapshotReserve, "snapshot reserve mismatch")
 }
 
-func TestCreate_NegativeSize(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestValidate_DefaultServiceLevel(t *testing.T) {
+	cPools := []*api.CapacityPool{
+		{Name: "CP1", ServiceLevel: api.ServiceLevelStandard},
+		{Name: "CP2", ServiceLevel: api.ServiceLevelPremium},
+	}
+
+	tests := []struct {
+		name                string
+		defaultServiceLevel string
+		wantErr             bool
+	}{
+		{"Discovered", "Premium", false},
+		{"DiscoveredLowercase", "standard", false},
+		{"NotDiscovered", "Ultra", true},
+		{"Unknown", "Platinum", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.DefaultServiceLevel = test.defaultServiceLevel
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+
+			mockAPI.EXPECT().CapacityPools().Return(&cPools).AnyTimes()
+
+			result := driver.validate(ctx)
+
+			if test.wantErr {
+				assert.ErrorContains(t, result, "defaultServiceLevel", "validate did not fail")
+			} else {
+				assert.NoError(t, result, "validate failed")
+			}
+		})
+	}
+}
+
+func TestPoolsWithoutServiceLevel(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.Storage = []drivers.AzureNASStorageDriverPool{
+		{ServiceLevel: api.ServiceLevelUltra},
+		{},
+	}
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
+	assert.Equal(t, []string{"anf_pool_1"}, driver.poolsWithoutServiceLevel())
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = "-1M"
+	// The warning about unconstrained pools must not fail validation
+	assert.NoError(t, driver.validate(ctx), "validate failed")
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	driver.Config.ServiceLevel = api.ServiceLevelPremium
+	driver.initializeStoragePools(ctx)
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+	assert.Empty(t, driver.poolsWithoutServiceLevel())
+}
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+func TestValidate_Backup(t *testing.T) {
+	tests := []struct {
+		name          string
+		backupEnabled string
+		backupPolicy  string
+		wantErr       bool
+	}{
+		{"Disabled", "false", "", false},
+		{"Enabled", "true", "", false},
+		{"EnabledWithPolicy", "true", "policy1", false},
+		{"PolicyWithoutBackup", "false", "policy1", true},
+		{"NotABoolean", "sometimes", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.BackupEnabled = test.backupEnabled
+			driver.Config.BackupPolicy = test.backupPolicy
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
+
+			if test.wantErr {
+				assert.ErrorContains(t, result, "_pool", "validate did not fail")
+			} else {
+				assert.NoError(t, result, "validate failed")
+			}
+		})
+	}
 }
 
-func TestCreate_ZeroSize(t *testing.T) {
+func TestCreate_NFSVolume_BackupEnabled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NASType = "nfs"
+	driver.Config.BackupEnabled = "true"
+	driver.Config.BackupPolicy = "policy1"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2017,12 +3474,15 @@ func TestCreate_ZeroSize(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = "0"
+	createRequest.UnixPermissions = "0777"
+	createRequest.BackupEnabled = true
+	createRequest.BackupPolicy = "policy1"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
@@ -2032,15 +3492,14 @@ func TestCreate_ZeroSize(t *testing.T) {
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.QuotaInBytes, DefaultVolumeSize, "request size mismatch")
-	assert.Equal(t, volConfig.Size, defaultVolumeSizeStr, "config size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestCreate_BelowAbsoluteMinimumSize(t *testing.T) {
+func TestCreate_NFSVolume_SnapshotReserveFromVolumeConfig(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.SnapshotReserve = "20"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2048,23 +3507,35 @@ func TestCreate_BelowAbsoluteMinimumSize(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = "1k"
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.SnapshotReserve = "50"
+	createRequest.UnixPermissions = "0777"
+	createRequest.QuotaInBytes = VolumeSizeI64 * 2
+	createRequest.Labels[SnapshotReserveTag] = "50"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, "50", volConfig.SnapshotReserve, "snapshot reserve mismatch")
 }
 
-func TestCreate_AboveMaximumSize(t *testing.T) {
+func TestCreate_NFSVolume_InvalidSnapshotReserve(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.LimitVolumeSize = "100Gi"
+	driver.Config.NASType = "nfs"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2073,21 +3544,45 @@ func TestCreate_AboveMaximumSize(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = "200Gi"
+	volConfig.SnapshotReserve = "91"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.ErrorContains(t, result, "snapshotReserve", "expected error")
 }
 
-func TestCreate_InvalidSnapshotDir(t *testing.T) {
+func TestSnapshotReserveSizes(t *testing.T) {
+	tests := []struct {
+		snapshotReserve int
+		quota           uint64
+	}{
+		{-1, 1000},
+		{0, 1000},
+		{20, 1250},
+		{30, 1429},
+		{90, 10000},
+	}
+
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.snapshotReserve), func(t *testing.T) {
+			quota := quotaForSnapshotReserve(1000, test.snapshotReserve)
+
+			assert.Equal(t, test.quota, quota, "quota mismatch")
+			assert.Equal(t, int64(1000), dataSizeForSnapshotReserve(int64(quota), test.snapshotReserve),
+				"data size mismatch")
+		})
+	}
+}
+
+func TestCreate_DualProtocolVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = NASTypeDual
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2095,50 +3590,77 @@ func TestCreate_InvalidSnapshotDir(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.SnapshotDir = "invalid"
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	createRequest.ExportPolicy.Rules[0].Cifs = true
+	createRequest.UnixPermissions = "0777"
+	createRequest.SecurityStyle = api.SecurityStyleUnix
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
 }
 
-func TestCreate_InvalidLabel(t *testing.T) {
+func TestCreate_DualProtocolVolume_LDAPEnabled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = NASTypeDual
+	driver.Config.LDAPEnabled = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
 	storagePool := driver.pools["anf_pool"]
-	storagePool.Attributes()[sa.Labels] = sa.NewLabelOffer(map[string]string{
-		"key1": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-		"key2": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-		"key3": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-	})
 
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPool.ActiveDirectoryConfigured = true
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	createRequest.ExportPolicy.Rules[0].Cifs = true
+	createRequest.UnixPermissions = "0777"
+	createRequest.SecurityStyle = api.SecurityStyleUnix
+	createRequest.LdapEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestCreate_NoCapacityPool(t *testing.T) {
+func TestCreate_NFSVolume_LDAPEnabled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = "nfsvers=4.1"
+	driver.Config.LDAPEnabled = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2146,25 +3668,37 @@ func TestCreate_NoCapacityPool(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, _, subnet, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPool.ActiveDirectoryConfigured = true
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.LdapEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	filesystem.LdapEnabled = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{}).Times(1)
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestCreate_NoSubnet(t *testing.T) {
+func TestCreate_NFSVolume_LDAPEnabled_NFSv3(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.LDAPEnabled = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2177,44 +3711,50 @@ func TestCreate_NoSubnet(t *testing.T) {
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
+	assert.ErrorContains(t, result, "LDAP is only supported for NFSv4.1 volumes", "create did not fail")
 	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
 }
 
-func TestCreate_NFSVolume_InvalidMountOptions(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	storagePool := driver.pools["anf_pool"]
-
-	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.MountOptions = "nfsvers=5"
+func TestDefaultVolumeUnixPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		permissions string
+		hasFeature  bool
+		expected    string
+	}{
+		{"FeatureGatedWithFeature", UnixPermissionsModeFeatureGated, "", true, "0777"},
+		{"FeatureGatedWithoutFeature", UnixPermissionsModeFeatureGated, "", false, ""},
+		{"Empty", UnixPermissionsModeEmpty, "", true, ""},
+		{"Explicit", UnixPermissionsModeExplicit, "0750", true, "0750"},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.DefaultUnixPermissionsMode = test.mode
+			driver.Config.DefaultUnixPermissions = test.permissions
 
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+			if test.mode == UnixPermissionsModeFeatureGated {
+				mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(test.hasFeature).Times(1)
+			}
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+			assert.Equal(t, test.expected, driver.defaultVolumeUnixPermissions())
+		})
+	}
 }
 
-func TestCreate_NFSVolume_DefaultMountOptions(t *testing.T) {
+func TestCreate_NFSVolume_ExplicitDefaultUnixPermissions(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NASType = "nfs"
-	driver.Config.NfsMountOptions = ""
+	driver.Config.DefaultUnixPermissionsMode = UnixPermissionsModeExplicit
+	driver.Config.DefaultUnixPermissions = "0750"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2223,11 +3763,13 @@ func TestCreate_NFSVolume_DefaultMountOptions(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0750"
+	filesystem.UnixPermissions = "0750"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Times(0)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
@@ -2237,15 +3779,15 @@ func TestCreate_NFSVolume_DefaultMountOptions(t *testing.T) {
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.NoError(t, result, "create failed")
-	assert.Equal(t, volConfig.Size, strconv.FormatInt(createRequest.QuotaInBytes, 10), "request size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "0750", volConfig.UnixPermissions)
 }
 
-func TestCreate_NFSVolume_VolConfigMountOptions(t *testing.T) {
+func TestCreate_NFSVolume_EmptyDefaultUnixPermissions(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NASType = "nfs"
+	driver.Config.DefaultUnixPermissionsMode = UnixPermissionsModeEmpty
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2254,15 +3796,11 @@ func TestCreate_NFSVolume_VolConfigMountOptions(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.MountOptions = "nfsvers=4"
-	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
-	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Times(0)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
@@ -2272,14 +3810,15 @@ func TestCreate_NFSVolume_VolConfigMountOptions(t *testing.T) {
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.NoError(t, result, "create failed")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "", volConfig.UnixPermissions)
 }
 
-func TestCreate_NFSVolume_CreateFailed(t *testing.T) {
+func TestCreate_NFSVolume_SnapshotPolicy(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NASType = "nfs"
+	driver.Config.SnapshotPolicy = "hourly"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2287,27 +3826,34 @@ func TestCreate_NFSVolume_CreateFailed(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.SnapshotPolicy = "hourly"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, "hourly", volConfig.SnapshotPolicy, "snapshot policy mismatch")
 }
 
-func TestCreate_NFSVolume_BelowANFMinimumSize(t *testing.T) {
+func TestCreate_NFSVolume_CoolAccess(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.ServiceLevel = api.ServiceLevelStandard
 	driver.Config.NASType = "nfs"
+	driver.Config.CoolAccess = "true"
+	driver.Config.CoolnessPeriod = "45"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2316,14 +3862,18 @@ func TestCreate_NFSVolume_BelowANFMinimumSize(t *testing.T) {
 	storagePool := driver.pools["anf_pool"]
 
 	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	volConfig.Size = strconv.FormatUint(MinimumANFVolumeSizeBytes-1, 10)
+	capacityPool.ServiceLevel = api.ServiceLevelStandard
+	createRequest.UnixPermissions = "0777"
+	createRequest.CoolAccess = true
+	createRequest.CoolnessPeriod = 45
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+		api.ServiceLevelStandard).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
 	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
 		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
@@ -2331,87 +3881,39 @@ func TestCreate_NFSVolume_BelowANFMinimumSize(t *testing.T) {
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.NoError(t, result, "create failed")
-	assert.Equal(t, uint64(createRequest.QuotaInBytes), MinimumANFVolumeSizeBytes, "request size mismatch")
-	assert.Equal(t, volConfig.Size, strconv.FormatUint(MinimumANFVolumeSizeBytes, 10), "config size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func getStructsForCreateSMBVolume(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
-	*storage.VolumeConfig, *api.CapacityPool, *api.Subnet, *api.FilesystemCreateRequest, *api.FileSystem,
-) {
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-	}
-
-	capacityPool := &api.CapacityPool{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		Name:              "CP1",
-		FullName:          "RG1/NA1/CP1",
-		Location:          Location,
-		ServiceLevel:      api.ServiceLevelUltra,
-		ProvisioningState: api.StateAvailable,
-		QosType:           "Auto",
-	}
-
-	subnet := &api.Subnet{
-		ID:             api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1"),
-		ResourceGroup:  "RG2",
-		VirtualNetwork: "VN1",
-		Name:           "SN1",
-		FullName:       "RG1/VN1/SN1",
-		Location:       Location,
-	}
+func TestCreate_NFSVolume_CoolAccessUnsupportedServiceLevel(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.CoolAccess = "true"
 
-	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
-	labels[storage.ProvisioningLabelTag] = poolLabels
+	storagePool := driver.pools["anf_pool"]
 
-	createRequest := &api.FilesystemCreateRequest{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              volConfig.Name,
-		SubnetID:          subnet.ID,
-		CreationToken:     volConfig.InternalName,
-		Labels:            labels,
-		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-	}
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.ServiceLevel = api.ServiceLevelUltra
 
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-		SubnetID:          subnet.ID,
-	}
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	return volConfig, capacityPool, subnet, createRequest, filesystem
+	assert.Error(t, result, "expected error")
 }
 
-func TestCreate_SMBVolume(t *testing.T) {
+func TestCreate_NFSVolume_LargeVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "smb"
+	driver.Config.NASType = "nfs"
+	driver.Config.LargeVolume = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2419,12 +3921,18 @@ func TestCreate_SMBVolume(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.QuotaInBytes = int64(MinimumANFLargeVolumeSizeBytes)
+	createRequest.IsLargeVolume = true
+	filesystem.UnixPermissions = "0777"
+	filesystem.QuotaInBytes = int64(MinimumANFLargeVolumeSizeBytes)
+	filesystem.IsLargeVolume = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
 	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
@@ -2434,19 +3942,14 @@ func TestCreate_SMBVolume(t *testing.T) {
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
 	assert.NoError(t, result, "create failed")
-	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
-	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
-	assert.Equal(t, "false", volConfig.SnapshotDir)
-	assert.Equal(t, "", volConfig.UnixPermissions)
+	assert.Equal(t, strconv.FormatUint(MinimumANFLargeVolumeSizeBytes, 10), volConfig.Size, "request size mismatch")
 }
 
-func TestCreate_SMBVolume_CreateFailed(t *testing.T) {
+func TestCreate_NFSVolume_LargeVolumeAutoDetected(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "smb"
+	driver.Config.NASType = "nfs"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2454,27 +3957,39 @@ func TestCreate_SMBVolume_CreateFailed(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	sizeBytes := MaximumANFVolumeSizeBytes * 2
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
+	createRequest.UnixPermissions = "0777"
+	createRequest.QuotaInBytes = int64(sizeBytes)
+	createRequest.IsLargeVolume = true
+	filesystem.UnixPermissions = "0777"
+	filesystem.QuotaInBytes = int64(sizeBytes)
+	filesystem.IsLargeVolume = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
-
-	result := driver.Create(ctx, volConfig, storagePool, nil)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, strconv.FormatUint(sizeBytes, 10), volConfig.Size, "request size mismatch")
 }
 
-func TestCreate_SMBVolume_BelowANFMinimumSize(t *testing.T) {
+func TestCreate_NFSVolume_LargeVolumeDisabled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "smb"
+	driver.Config.NASType = "nfs"
+	driver.Config.LargeVolume = "false"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2482,62 +3997,51 @@ func TestCreate_SMBVolume_BelowANFMinimumSize(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
-	volConfig.Size = strconv.FormatUint(MinimumANFVolumeSizeBytes-1, 10)
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = strconv.FormatUint(MaximumANFVolumeSizeBytes*2, 10)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, uint64(createRequest.QuotaInBytes), MinimumANFVolumeSizeBytes, "request size mismatch")
-	assert.Equal(t, volConfig.Size, strconv.FormatUint(MinimumANFVolumeSizeBytes, 10), "config size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.ErrorContains(t, result, LargeVolume, "expected error")
 }
 
-func TestCreate_NFSVolumeOnSMBPool_CreateFailed(t *testing.T) {
+func TestCreate_NFSVolume_LargeVolumeAboveSizeLimit(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "smb"
+	driver.Config.NASType = "nfs"
+	driver.Config.LargeVolume = "true"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.LimitVolumeSize = "1Ti"
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
-	_, _, _, createRequest, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	// The request fits within the limit, but not once it is increased to the minimum large volume size
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
-	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
-		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.NotEqual(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type matches")
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.Error(t, result, "expected error")
 }
 
-func TestCreate_SMBVolumeOnNFSPool_CreateFailed(t *testing.T) {
+func TestCreate_NFSVolume_AutoExportPolicy(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
 	driver.Config.NASType = "nfs"
+	driver.Config.AutoExportPolicy = true
+	driver.autoExportClients = "10.0.0.4,10.0.0.5"
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2545,145 +4049,135 @@ func TestCreate_SMBVolumeOnNFSPool_CreateFailed(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	volConfig, capacityPool, subnet, _, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
-	_, _, _, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.ExportPolicy.Rules[0].AllowedClients = "10.0.0.4,10.0.0.5"
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
-	mockAPI.EXPECT().RandomSubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
 	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
 		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
 	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.NotEqual(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type matches")
-	assert.Error(t, result, "create failed")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.NoError(t, result, "create failed")
 }
 
-func getStructsForCreateClone(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
-	*storage.VolumeConfig, *storage.VolumeConfig, *api.FilesystemCreateRequest, *api.FileSystem, *api.FileSystem,
-	*api.Snapshot,
-) {
-	sourceVolumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
-	cloneVolumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol2")
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+func TestCreate_NFSVolume_AutoExportPolicy_NotReconciled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.AutoExportPolicy = true
+	driver.Config.AutoExportCIDRs = []string{"10.0.0.0/24", "10.0.1.0/24"}
 
-	sourceVolConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   sourceVolumeID,
-	}
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	cloneVolConfig := &storage.VolumeConfig{
-		Version:                   "1",
-		Name:                      "testvol2",
-		InternalName:              "trident-testvol2",
-		CloneSourceVolume:         "testvol1",
-		CloneSourceVolumeInternal: "trident-testvol1",
-	}
+	storagePool := driver.pools["anf_pool"]
 
-	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.ExportPolicy.Rules[0].AllowedClients = "10.0.0.0/24,10.0.1.0/24"
+	filesystem.UnixPermissions = "0777"
 
-	apiExportRule := api.ExportRule{
-		AllowedClients: defaultExportRule,
-		Cifs:           false,
-		Nfsv3:          true,
-		Nfsv41:         false,
-		RuleIndex:      1,
-		UnixReadOnly:   false,
-		UnixReadWrite:  true,
-	}
-	exportPolicy := api.ExportPolicy{
-		Rules: []api.ExportRule{apiExportRule},
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
-	labels[storage.ProvisioningLabelTag] = poolLabels
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	createRequest := &api.FilesystemCreateRequest{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              cloneVolConfig.Name,
-		SubnetID:          subnetID,
-		CreationToken:     cloneVolConfig.InternalName,
-		ExportPolicy:      exportPolicy,
-		Labels:            labels,
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-		SnapshotID:        SnapshotUUID,
-		UnixPermissions:   defaultUnixPermissions,
+	assert.NoError(t, result, "create failed")
+}
+
+func TestAutoExportAllowedClients(t *testing.T) {
+	tests := []struct {
+		name     string
+		clients  string
+		cidrs    []string
+		expected string
+	}{
+		{"Reconciled", "10.0.0.4,10.0.0.5", []string{"10.0.0.0/24"}, "10.0.0.4,10.0.0.5"},
+		{"NotReconciled", "", []string{"10.0.0.0/24", "10.0.1.0/24"}, "10.0.0.0/24,10.0.1.0/24"},
 	}
 
-	sourceFilesystem := &api.FileSystem{
-		ID:                sourceVolumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		ExportPolicy:      exportPolicy,
-		Labels:            labels,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		SnapshotDirectory: snapshotDir,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.AutoExportPolicy = true
+			driver.Config.AutoExportCIDRs = test.cidrs
+			driver.autoExportClients = test.clients
+
+			assert.Equal(t, test.expected, driver.autoExportAllowedClients(), "allowed clients mismatch")
+		})
 	}
+}
 
-	cloneFilesystem := &api.FileSystem{
-		ID:                cloneVolumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol2",
-		FullName:          "RG1/NA1/CP1/testvol2",
-		Location:          Location,
-		ExportPolicy:      exportPolicy,
-		Labels:            labels,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol2",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: snapshotDir,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
+func TestCreate_NFSVolume_ExportRules(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.ExportRules = []drivers.AzureNASExportRule{
+		{AllowedClients: "10.0.0.0/24", UnixReadOnly: true},
+		{AllowedClients: "10.0.1.0/24", Nfsv41: true},
+		{AllowedClients: "10.0.2.0/24", Nfsv3: true},
 	}
 
-	snapshot := &api.Snapshot{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Volume:            "testvol1",
-		Name:              "snap1",
-		FullName:          "RG1/NA1/CP1/testvol1/snap1",
-		Location:          Location,
-		Created:           time.Now(),
-		SnapshotID:        SnapshotUUID,
-		ProvisioningState: api.StateAvailable,
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.ExportPolicy = api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{AllowedClients: "10.0.0.0/24", Nfsv3: true, RuleIndex: 1, UnixReadOnly: true},
+			{AllowedClients: "10.0.2.0/24", Nfsv3: true, RuleIndex: 2, UnixReadWrite: true},
+		},
 	}
+	filesystem.UnixPermissions = "0777"
 
-	return sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
 }
 
-func TestCreateClone_NoSnapshot(t *testing.T) {
+func TestCreate_NFSVolume_ExportRulesShorthand(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.Config.BackendName = "anf"
 	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NetworkFeatures = api.NetworkFeaturesBasic
 	driver.Config.NASType = "nfs"
+	driver.Config.ExportRules = []drivers.AzureNASExportRule{
+		{CIDR: "10.0.0.0/24", Access: "ro"},
+		{CIDR: "10.0.1.0/24", Access: "rw", Protocol: "nfsv4.1"},
+		{CIDR: "10.0.2.0/24", Access: "rw", Protocol: "nfsv3"},
+	}
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
@@ -2691,3831 +4185,10660 @@ func TestCreateClone_NoSnapshot(t *testing.T) {
 
 	storagePool := driver.pools["anf_pool"]
 
-	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	createRequest.NetworkFeatures = api.NetworkFeaturesBasic
-	sourceFilesystem.NetworkFeatures = api.NetworkFeaturesBasic
-	cloneFilesystem.NetworkFeatures = api.NetworkFeaturesBasic
-	sourceVolConfig.SnapshotDir = "false"
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.ExportPolicy = api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{AllowedClients: "10.0.0.0/24", Nfsv3: true, RuleIndex: 1, UnixReadOnly: true},
+			{AllowedClients: "10.0.2.0/24", Nfsv3: true, RuleIndex: 2, UnixReadWrite: true},
+		},
+	}
+	filesystem.UnixPermissions = "0777"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
 		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+}
+
+func TestCreate_NFSVolume_VolumeExportRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumeRule     string
+		expectedClient string
+	}{
+		{"VolumeRule", "10.1.0.0/16,10.2.0.1", "10.1.0.0/16,10.2.0.1"},
+		{"NoVolumeRule", "", "1.1.1.1/32"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.Config.ExportRule = "1.1.1.1/32"
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+
+			volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver,
+				storagePool)
+			volConfig.ExportRule = test.volumeRule
+			if test.volumeRule != "" {
+				createRequest.Labels[ExplicitExportRuleTag] = "true"
+			}
+			createRequest.UnixPermissions = "0777"
+			createRequest.ExportPolicy = api.ExportPolicy{
+				Rules: []api.ExportRule{
+					{AllowedClients: test.expectedClient, Nfsv3: true, RuleIndex: 1, UnixReadWrite: true},
+				},
+			}
+			filesystem.UnixPermissions = "0777"
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+			mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+				api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+			mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+			mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+				driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+			result := driver.Create(ctx, volConfig, storagePool, nil)
+
+			assert.NoError(t, result, "create failed")
+		})
+	}
+}
+
+func TestCreate_NFSVolume_InvalidVolumeExportRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		volumeRule string
+		validation string
+	}{
+		{"NotAnAddress", "10.1.0.0/16,myhost", ExportRuleValidationLenient},
+		{"Rejected", "0.0.0.0/0", ExportRuleValidationReject},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.Config.ExportRule = "1.1.1.1/32"
+			driver.Config.ExportRuleValidation = test.validation
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+
+			volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+			volConfig.ExportRule = test.volumeRule
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+			mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+			result := driver.Create(ctx, volConfig, storagePool, nil)
+
+			assert.ErrorContains(t, result, "exportRule of volume", "create did not fail")
+		})
+	}
+}
+
+func TestCreate_NFSVolume_ExportRules_NoneApply(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.ExportRules = []drivers.AzureNASExportRule{
+		{AllowedClients: "10.0.1.0/24", Nfsv41: true},
+	}
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+}
+
+func TestCreate_NFSVolume_NFSv41ACLExportOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = "nfsvers=4.1"
+	driver.Config.HasRootAccess = "false"
+	driver.Config.ChownMode = "unrestricted"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	hasRootAccess := false
+	createRequest.UnixPermissions = "0777"
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.ExportPolicy = api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{
+				AllowedClients: defaultExportRule,
+				Nfsv41:         true,
+				RuleIndex:      1,
+				UnixReadWrite:  true,
+				HasRootAccess:  &hasRootAccess,
+				ChownMode:      api.ChownModeUnrestricted,
+			},
+		},
+	}
+	filesystem.UnixPermissions = "0777"
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+}
+
+func TestCreate_NFSVolume_NFSv3ACLExportOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = "nfsvers=4.1"
+	driver.Config.ChownMode = api.ChownModeRestricted
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.MountOptions = "nfsvers=3"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+}
+
+func TestCreate_NFSVolume_InvalidSnapshotPolicy(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.SnapshotPolicy = "not a policy"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestCreate_NFSVolume_MultipleCapacityPools_FirstSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+
+	createRequest.ResourceGroup = capacityPools[0].ResourceGroup
+	createRequest.NetAppAccount = capacityPools[0].NetAppAccount
+	createRequest.CapacityPool = capacityPools[0].Name
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
+}
+
+func TestCreate_NFSVolume_MultipleCapacityPools_SecondSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	createRequest1 := *createRequest
+	createRequest1.ResourceGroup = capacityPools[0].ResourceGroup
+	createRequest1.NetAppAccount = capacityPools[0].NetAppAccount
+	createRequest1.CapacityPool = capacityPools[0].Name
+	createRequest2 := *createRequest
+	createRequest2.ResourceGroup = capacityPools[1].ResourceGroup
+	createRequest2.NetAppAccount = capacityPools[1].NetAppAccount
+	createRequest2.CapacityPool = capacityPools[1].Name
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.ResourceGroup = capacityPools[1].ResourceGroup
+	filesystem.NetAppAccount = capacityPools[1].NetAppAccount
+	filesystem.CapacityPool = capacityPools[1].Name
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest1).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
+}
+
+func TestCreate_NFSVolume_PreferredTopology(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.RequisiteTopologies = []map[string]string{
+		{topologyRegionLabel: Location},
+		{topologyZoneLabel: "other-location-1"},
+	}
+	volConfig.PreferredTopologies = []map[string]string{{topologyZoneLabel: "other-location-1"}}
+
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+	capacityPools[1].Location = "other-location"
+	capacityPools[2].Location = "third-location"
+
+	otherSubnet := *subnet
+	otherSubnet.ID = api.CreateSubnetID(SubscriptionID, "RG2", "VN2", "SN2")
+	otherSubnet.Location = "other-location"
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.SubnetID = otherSubnet.ID
+	createRequest.CapacityPool = capacityPools[1].Name
+	createRequest.Zone = "1"
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.CapacityPool = capacityPools[1].Name
+	filesystem.SubnetID = otherSubnet.ID
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return([]*api.Subnet{subnet, &otherSubnet}).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Times(0)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_RequisiteTopology_NoCapacityPools(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.RequisiteTopologies = []map[string]string{{topologyRegionLabel: "other-location"}}
+
+	otherSubnet := *subnet
+	otherSubnet.Location = "other-location"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return([]*api.Subnet{subnet, &otherSubnet}).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(getMultipleCapacityPoolsForCreateVolume()).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+}
+
+func TestCreate_NFSVolume_RequisiteTopology_NoSubnets(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.RequisiteTopologies = []map[string]string{{topologyRegionLabel: "other-location"}}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return([]*api.Subnet{subnet}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+}
+
+func TestCreate_NFSVolume_AvailabilityZone(t *testing.T) {
+	tests := []struct {
+		name         string
+		poolZone     string
+		topologyZone string
+		expectedZone string
+	}{
+		{"Topology", "", Location + "-2", "2"},
+		{"TopologyOverridesPool", "3", Location + "-2", "2"},
+		{"PoolZone", "3", "", "3"},
+		{"PoolZoneWithLocation", Location + "-1", "", "1"},
+		{"PoolZoneLabel", "zone1", "", ""},
+		{"NonZonalTopology", "", Location + "-0", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.Config.Zone = test.poolZone
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+
+			volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver,
+				storagePool)
+			createRequest.UnixPermissions = "0777"
+			createRequest.Zone = test.expectedZone
+			filesystem.UnixPermissions = "0777"
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+			if test.topologyZone != "" {
+				volConfig.PreferredTopologies = []map[string]string{{topologyZoneLabel: test.topologyZone}}
+				mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return([]*api.Subnet{subnet}).Times(1)
+			} else {
+				mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+			}
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+				api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+			mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+			mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+				driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+			result := driver.Create(ctx, volConfig, storagePool, nil)
+
+			assert.NoError(t, result, "create failed")
+		})
+	}
+}
+
+func TestCreate_NFSVolume_AvailabilityZone_NoCapacityPools(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.Zone = "other-location-1"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.ErrorContains(t, result, "availability zones", "create did not fail")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+}
+
+func TestCreate_NFSVolume_MultipleCapacityPools_NoneSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	createRequest1 := *createRequest
+	createRequest1.ResourceGroup = capacityPools[0].ResourceGroup
+	createRequest1.NetAppAccount = capacityPools[0].NetAppAccount
+	createRequest1.CapacityPool = capacityPools[0].Name
+	createRequest2 := *createRequest
+	createRequest2.ResourceGroup = capacityPools[1].ResourceGroup
+	createRequest2.NetAppAccount = capacityPools[1].NetAppAccount
+	createRequest2.CapacityPool = capacityPools[1].Name
+	createRequest3 := *createRequest
+	createRequest3.ResourceGroup = capacityPools[2].ResourceGroup
+	createRequest3.NetAppAccount = capacityPools[2].NetAppAccount
+	createRequest3.CapacityPool = capacityPools[2].Name
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.ResourceGroup = capacityPools[1].ResourceGroup
+	filesystem.NetAppAccount = capacityPools[1].NetAppAccount
+	filesystem.CapacityPool = capacityPools[1].Name
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest1).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest3).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_SubnetExhausted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	subnet2 := &api.Subnet{
+		ID:           api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN2"),
+		Name:         "SN2",
+		FullName:     "RG2/VN1/SN2",
+		Location:     Location,
+		AvailableIPs: 10,
+	}
+	subnet3 := &api.Subnet{
+		ID:           api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN3"),
+		Name:         "SN3",
+		FullName:     "RG2/VN1/SN3",
+		Location:     Location,
+		AvailableIPs: 100,
+	}
+	subnetFullErr := &azcore.ResponseError{
+		ErrorCode:   "SubnetIsFull",
+		RawResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+
+	createRequest2 := *createRequest
+	createRequest2.SubnetID = subnet2.ID
+	createRequest3 := *createRequest
+	createRequest3.SubnetID = subnet3.ID
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return(
+		[]*api.Subnet{subnet2, subnet, subnet3}).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	gomock.InOrder(
+		mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, subnetFullErr).Times(1),
+		mockAPI.EXPECT().CreateVolume(ctx, &createRequest3).Return(nil, subnetFullErr).Times(1),
+		mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(filesystem, nil).Times(1),
+	)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_SubnetExhausted_NoOtherSubnets(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	subnetFullErr := &azcore.ResponseError{
+		ErrorCode:   "SubnetIsFull",
+		RawResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().SubnetsForStoragePool(ctx, storagePool).Return([]*api.Subnet{subnet}).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, subnetFullErr).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.True(t, api.IsANFSubnetExhaustedError(result), "expected subnet exhausted error")
+}
+
+func getStructsForParallelCreateNFSVolume(
+	ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool,
+) (*storage.VolumeConfig, *api.Subnet, []*api.CapacityPool, []*api.FilesystemCreateRequest, []*api.FileSystem) {
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()
+
+	createRequests := make([]*api.FilesystemCreateRequest, 0, len(capacityPools))
+	filesystems := make([]*api.FileSystem, 0, len(capacityPools))
+
+	for _, cPool := range capacityPools {
+		request := *createRequest
+		request.ResourceGroup = cPool.ResourceGroup
+		request.NetAppAccount = cPool.NetAppAccount
+		request.CapacityPool = cPool.Name
+		createRequests = append(createRequests, &request)
+
+		fs := *filesystem
+		fs.ResourceGroup = cPool.ResourceGroup
+		fs.NetAppAccount = cPool.NetAppAccount
+		fs.CapacityPool = cPool.Name
+		fs.ID = api.CreateVolumeID(SubscriptionID, cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name, fs.Name)
+		fs.FullName = api.CreateVolumeFullName(cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name, fs.Name)
+		filesystems = append(filesystems, &fs)
+	}
+
+	return volConfig, subnet, capacityPools, createRequests, filesystems
+}
+
+func TestCreate_NFSVolume_ParallelCreate_SecondSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, createRequests, filesystems := getStructsForParallelCreateNFSVolume(
+		ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[0]).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[1]).Return(filesystems[1], nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[2]).Times(0)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, filesystems[0].ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystems[1], api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystems[1].ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ParallelCreate_ExtraVolumeDeleted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, createRequests, filesystems := getStructsForParallelCreateNFSVolume(
+		ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[0]).Return(filesystems[0], nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[1]).Return(filesystems[1], nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystems[1]).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystems[0], api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystems[0].ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ParallelCreate_CancelledVolumeDeleted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, createRequests, filesystems := getStructsForParallelCreateNFSVolume(
+		ctx, driver, storagePool)
+
+	// Azure accepted the second request, but the create was cancelled before it returned the volume
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[0]).Return(filesystems[0], nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[1]).Return(nil, context.Canceled).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, filesystems[1].ID).Return(true, filesystems[1], nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystems[1]).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystems[0], api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystems[0].ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ParallelCreate_ConflictNotRetried(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 2
+	driver.volumeCreateConflictRetries = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, createRequests, filesystems := getStructsForParallelCreateNFSVolume(
+		ctx, driver, storagePool)
+
+	// The first request conflicts with the second, which holds the creation token
+	conflictErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusConflict}}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[0]).Return(nil, conflictErr).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[1]).Return(filesystems[1], nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, filesystems[0].ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystems[1], api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystems[1].ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ParallelCreate_NextBatchSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, createRequests, filesystems := getStructsForParallelCreateNFSVolume(
+		ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[0]).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), createRequests[1]).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequests[2]).Return(filesystems[2], nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystems[2], api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystems[2].ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ParallelCreate_NoneSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.parallelCreatePools = 3
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, subnet, capacityPools, _, _ := getStructsForParallelCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(gomock.Any(), gomock.Any()).Return(nil, errFailed).Times(3)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_DuplicateCapacityPoolNames_NoneSucceeds(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+	capacityPools[1].ResourceGroup = "RG2"
+	capacityPools[1].NetAppAccount = "NA2"
+	capacityPools[1].Name = "CP1"
+	capacityPools[1].FullName = "RG2/NA2/CP1"
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	createRequest1 := *createRequest
+	createRequest1.ResourceGroup = "RG1"
+	createRequest1.NetAppAccount = "NA1"
+	createRequest1.CapacityPool = "CP1"
+	createRequest2 := *createRequest
+	createRequest2.ResourceGroup = "RG2"
+	createRequest2.NetAppAccount = "NA2"
+	createRequest2.CapacityPool = "CP1"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest1).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, &createRequest2).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Contains(t, result.Error(), "ANF pool RG1/NA1/CP1;", "error does not identify first capacity pool")
+	assert.Contains(t, result.Error(), "ANF pool RG2/NA2/CP1;", "error does not identify second capacity pool")
+}
+
+func TestCreate_NFSVolume_Throughput_ManualQoS(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Throughput = "128"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+	capacityPools[0].QosType = api.QosTypeAuto
+	capacityPools[1].QosType = api.QosTypeManual
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	createRequest.ResourceGroup = capacityPools[1].ResourceGroup
+	createRequest.NetAppAccount = capacityPools[1].NetAppAccount
+	createRequest.CapacityPool = capacityPools[1].Name
+	createRequest.ThroughputMibps = 128
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_NoThroughput_AutoQoS(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+	capacityPools[0].QosType = api.QosTypeAuto
+	capacityPools[1].QosType = api.QosTypeManual
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	createRequest.ResourceGroup = capacityPools[0].ResourceGroup
+	createRequest.NetAppAccount = capacityPools[0].NetAppAccount
+	createRequest.CapacityPool = capacityPools[0].Name
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPools[1], capacityPools[0]}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Zero(t, createRequest.ThroughputMibps, "throughput set for auto QoS pool")
+}
+
+func TestCreate_NFSVolume_Throughput_NoManualQoSPools(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Throughput = "128"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+	capacityPools[0].QosType = api.QosTypeAuto
+	capacityPools[1].QosType = api.QosTypeManual
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools[:1]).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_EnforcePoolCapacity_SkipsFullPool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.EnforcePoolCapacity = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+
+	capacityPools[0].Size = createRequest.QuotaInBytes * 4
+	capacityPools[1].Size = createRequest.QuotaInBytes * 4
+
+	createRequest.ResourceGroup = capacityPools[1].ResourceGroup
+	createRequest.NetAppAccount = capacityPools[1].NetAppAccount
+	createRequest.CapacityPool = capacityPools[1].Name
+
+	mockAPI.EXPECT().CapacityPoolUsedBytes(ctx, capacityPools[0]).Return(createRequest.QuotaInBytes*4, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolUsedBytes(ctx, capacityPools[1]).Return(createRequest.QuotaInBytes, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_EnforcePoolCapacity_PoolsFull(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.EnforcePoolCapacity = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+
+	capacityPools[0].Size = createRequest.QuotaInBytes * 4
+	capacityPools[1].Size = createRequest.QuotaInBytes * 4
+
+	mockAPI.EXPECT().CapacityPoolUsedBytes(ctx, capacityPools[0]).Return(createRequest.QuotaInBytes*4, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolUsedBytes(ctx, capacityPools[1]).Return(createRequest.QuotaInBytes*3+1, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Contains(t, result.Error(), "are full", "error does not indicate full pools")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_EnforcePoolCapacity_TooLarge(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.EnforcePoolCapacity = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+
+	capacityPools[0].Size = createRequest.QuotaInBytes / 2
+	capacityPools[1].Size = createRequest.QuotaInBytes - 1
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Contains(t, result.Error(), "larger than every capacity pool", "error does not indicate size")
+	assert.NotContains(t, result.Error(), "are full", "error indicates full pools")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_EnforcePoolCapacity_UnknownUsage(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.EnforcePoolCapacity = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	capacityPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return(capacityPools).Times(1)
+
+	capacityPools[0].Size = createRequest.QuotaInBytes * 4
+
+	createRequest.ResourceGroup = capacityPools[0].ResourceGroup
+	createRequest.NetAppAccount = capacityPools[0].NetAppAccount
+	createRequest.CapacityPool = capacityPools[0].Name
+
+	mockAPI.EXPECT().CapacityPoolUsedBytes(ctx, capacityPools[0]).Return(int64(0), errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+}
+
+func TestCreate_NFSVolume_Kerberos_type5(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = ""
+	driver.Config.Kerberos = "sec=krb5"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
+}
+
+func TestCreate_NFSVolume_Kerberos_KerberosNfsVersion(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = ""
+	driver.Config.Kerberos = "sec=krb5"
+	driver.Config.KerberosNfsVersion = nfsVersion4
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	// The Kerberos NFS version wins over the version in the volume's mount options
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.MountOptions = "nfsvers=3"
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, "nfsvers=4", driver.Config.NfsMountOptions, "mount options mismatch")
+}
+
+func TestCreate_NFSVolume_Kerberos_ReadOnlyMany(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = ""
+	driver.Config.Kerberos = "sec=krb5p"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.AccessMode = tridentconfig.ReadOnlyMany
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5PReadOnly = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5PReadWrite = false
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+}
+
+func TestCreate_NFSVolume_Kerberos_ReadOnlyMany_NFSv3(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = "nfsvers=3"
+	driver.Config.Kerberos = "sec=krb5"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.AccessMode = tridentconfig.ReadOnlyMany
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.ErrorContains(t, result, "read-only Kerberos access requires NFS version 4.1")
+}
+
+func TestCreate_NFSVolume_Kerberos_type5_FailsEntitlementCheck(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = ""
+	driver.Config.Kerberos = "sec=krb5"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create suceeded")
+}
+
+func TestCreate_NFSVolume_Kerberos_type5I(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5i"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
+}
+
+func TestCreate_NFSVolume_Kerberos_type5I_FailsEntitlementCheck(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5i"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create succeeded")
+}
+
+func TestCreate_NFSVolume_Kerberos_type5P(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5p"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "0777", volConfig.UnixPermissions)
+}
+
+func TestCreate_NFSVolume_Kerberos_type5P_FailsEntitlementCheck(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5p"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.KerberosEnabled = true
+	createRequest.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].UnixReadWrite = false
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	filesystem.KerberosEnabled = true
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create succeeded")
+}
+
+func TestCreate_NFSVolume_Kerberos_type5P_failure(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5P"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	createRequest.UnixPermissions = "0777"
+	createRequest.NetworkFeatures = api.NetworkFeaturesStandard
+
+	filesystem.UnixPermissions = "0777"
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "unsupported kerberos type: sec=krb5P", "create succeeded")
+	assert.NotNil(t, result, "expected nil")
+}
+
+func TestCreate_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_InvalidVolumeName(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Name = "1testvol"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_InvalidCreationToken(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.InternalName = "1testvol"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NoStoragePool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, nil, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NonexistentStoragePool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+	driver.pools = nil
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_VolumeExistsCheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_VolumeExistsCreating(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	filesystem.ProvisioningState = api.StateCreating
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.IsType(t, errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_VolumeExists(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	filesystem.ProvisioningState = api.StateAvailable
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.IsType(t, drivers.NewVolumeExistsError(""), result, "not VolumeExistsError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_InvalidSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "invalid"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NegativeSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "-1M"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_ZeroSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "0"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.QuotaInBytes, DefaultVolumeSize, "request size mismatch")
+	assert.Equal(t, volConfig.Size, defaultVolumeSizeStr, "config size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_BelowAbsoluteMinimumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "1k"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_AboveMaximumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.LimitVolumeSize = "100Gi"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "200Gi"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_InvalidSnapshotDir(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.SnapshotDir = "invalid"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_InvalidLabel(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+	storagePool.Attributes()[sa.Labels] = sa.NewLabelOffer(map[string]string{
+		"key1": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+		"key2": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+		"key3": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+	})
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NoCapacityPool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, subnet, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{}).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NoSubnet(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_InvalidMountOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.MountOptions = "nfsvers=5"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_NFSv40MountOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.MountOptions = "nfsvers=4.0"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Contains(t, result.Error(), "4.0", "error does not name the NFS version")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestNFSVersionFromMountOptions(t *testing.T) {
+	tests := []struct {
+		mountOptions string
+		expected     string
+		protocolType string
+		valid        bool
+	}{
+		{"", nfsVersion3, api.ProtocolTypeNFSv3, true},
+		{"hard", nfsVersion3, api.ProtocolTypeNFSv3, true},
+		{"nfsvers=3", nfsVersion3, api.ProtocolTypeNFSv3, true},
+		{"vers=3", nfsVersion3, api.ProtocolTypeNFSv3, true},
+		{"nfsvers=4", nfsVersion4, api.ProtocolTypeNFSv41, true},
+		{"nfsvers=4.1", nfsVersion41, api.ProtocolTypeNFSv41, true},
+		{"-o vers=4.1,hard", nfsVersion41, api.ProtocolTypeNFSv41, true},
+		{"nfsvers=4,minorversion=1", nfsVersion41, api.ProtocolTypeNFSv41, true},
+		{"nfsvers=4.0", "", "", false},
+		{"vers=4.0", "", "", false},
+		{"nfsvers=4,minorversion=0", "", "", false},
+		{"nfsvers=4.1,nfsvers=4.0", "", "", false},
+		{"nfsvers=4.2", "", "", false},
+		{"nfsvers=2", "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.mountOptions, func(t *testing.T) {
+			nfsVersion, err := nfsVersionFromMountOptions(test.mountOptions, nfsVersion3)
+
+			if test.valid {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, nfsVersion, "NFS version mismatch")
+				assert.Equal(t, test.protocolType, nfsProtocolType(nfsVersion), "protocol type mismatch")
+			} else {
+				assert.Error(t, err, "expected error")
+			}
+		})
+	}
+}
+
+func TestCreate_NFSVolume_DefaultMountOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.NfsMountOptions = ""
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, volConfig.Size, strconv.FormatInt(createRequest.QuotaInBytes, 10), "request size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_VolConfigMountOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.MountOptions = "nfsvers=4"
+	createRequest.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	createRequest.ExportPolicy.Rules[0].Nfsv3 = false
+	createRequest.ExportPolicy.Rules[0].Nfsv41 = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_CreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_ConflictCreateErrorRetried(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.volumeCreateConflictRetries = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	conflictErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusConflict}}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	gomock.InOrder(
+		mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, conflictErr).Times(1),
+		mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1),
+	)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_ConflictCreateErrorRetriesExhausted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.volumeCreateConflictRetries = 2
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	conflictErr := &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusConflict}}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, conflictErr).Times(3)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_NFSVolume_CreateErrorNotRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"BadRequest", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusBadRequest}}},
+		{"Throttled", &azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests}}},
+		{
+			"ServiceUnavailable",
+			&azcore.ResponseError{RawResponse: &http.Response{StatusCode: http.StatusServiceUnavailable}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.volumeCreateConflictRetries = 2
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+
+			volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver,
+				storagePool)
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+			mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+			mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+				api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+			mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, test.err).Times(1)
+
+			result := driver.Create(ctx, volConfig, storagePool, nil)
+
+			assert.Error(t, result, "expected error")
+		})
+	}
+}
+
+func TestCreate_NFSVolume_BelowANFMinimumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = strconv.FormatUint(MinimumANFVolumeSizeBytes-1, 10)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, uint64(createRequest.QuotaInBytes), MinimumANFVolumeSizeBytes, "request size mismatch")
+	assert.Equal(t, volConfig.Size, strconv.FormatUint(MinimumANFVolumeSizeBytes, 10), "config size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolume_BelowConfiguredMinimumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.MinimumVolumeSize = "50Gi"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "10Gi"
+	createRequest.QuotaInBytes = 53687091200
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, "53687091200", volConfig.Size, "config size mismatch")
+}
+
+func TestCreate_NFSVolume_AboveConfiguredMinimumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.MinimumVolumeSize = "50Gi"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.Size = "60Gi"
+	createRequest.QuotaInBytes = 64424509440
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, "64424509440", volConfig.Size, "config size mismatch")
+}
+
+func getStructsForCreateSMBVolume(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
+	*storage.VolumeConfig, *api.CapacityPool, *api.Subnet, *api.FilesystemCreateRequest, *api.FileSystem,
+) {
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+	}
+
+	capacityPool := &api.CapacityPool{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		Name:              "CP1",
+		FullName:          "RG1/NA1/CP1",
+		Location:          Location,
+		ServiceLevel:      api.ServiceLevelUltra,
+		ProvisioningState: api.StateAvailable,
+		QosType:           "Auto",
+	}
+
+	subnet := &api.Subnet{
+		ID:             api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1"),
+		ResourceGroup:  "RG2",
+		VirtualNetwork: "VN1",
+		Name:           "SN1",
+		FullName:       "RG1/VN1/SN1",
+		Location:       Location,
+	}
+
+	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+	labels[storage.ProvisioningLabelTag] = poolLabels
+	labels[StoragePoolTag] = storagePool.Name()
+
+	createRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              volConfig.Name,
+		SubnetID:          subnet.ID,
+		CreationToken:     volConfig.InternalName,
+		Labels:            labels,
+		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+	}
+
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+		SubnetID:          subnet.ID,
+	}
+
+	return volConfig, capacityPool, subnet, createRequest, filesystem
+}
+
+func TestCreate_SMBVolume_LDAPEnabled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+	driver.Config.LDAPEnabled = "true"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	capacityPool.ActiveDirectoryConfigured = true
+	createRequest.LdapEnabled = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_SMBVolume_LDAPEnabled_NoActiveDirectory(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+	driver.Config.LDAPEnabled = "true"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, _, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.True(t, errors.IsUnsupportedConfigError(result), "not UnsupportedConfigError")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_SMBVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, strconv.FormatInt(createRequest.QuotaInBytes, 10), volConfig.Size, "request size mismatch")
+	assert.Equal(t, api.ServiceLevelUltra, volConfig.ServiceLevel)
+	assert.Equal(t, "false", volConfig.SnapshotDir)
+	assert.Equal(t, "", volConfig.UnixPermissions)
+}
+
+func TestCreate_SMBVolume_ContinuousAvailabilityAndEncryption(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+	driver.Config.SMBContinuousAvailability = "true"
+	driver.Config.SMBEncryption = "true"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	createRequest.SmbContinuouslyAvailable = true
+	createRequest.SmbEncryption = true
+	filesystem.SmbContinuouslyAvailable = true
+	filesystem.SmbEncryption = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_SMBVolume_CoolAccessIgnored(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+	driver.Config.CoolAccess = "true"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.False(t, createRequest.CoolAccess, "cool access requested for SMB volume")
+}
+
+func TestCreate_SMBVolume_CreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_SMBVolume_BelowANFMinimumSize(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	volConfig.Size = strconv.FormatUint(MinimumANFVolumeSizeBytes-1, 10)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, uint64(createRequest.QuotaInBytes), MinimumANFVolumeSizeBytes, "request size mismatch")
+	assert.Equal(t, volConfig.Size, strconv.FormatUint(MinimumANFVolumeSizeBytes, 10), "config size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreate_NFSVolumeOnSMBPool_CreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, _, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	_, _, _, createRequest, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NotEqual(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type matches")
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreate_SMBVolume_Kerberos(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "smb"
+	driver.Config.Kerberos = api.MountOptionKerberos5P
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, _, _, _, _ := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Times(0)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Contains(t, result.Error(), "only supported for NFS", "unexpected error")
+}
+
+func TestCreate_SMBVolumeOnNFSPool_CreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	volConfig, capacityPool, subnet, _, filesystem := getStructsForCreateSMBVolume(ctx, driver, storagePool)
+	_, _, _, createRequest, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(false).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+
+	result := driver.Create(ctx, volConfig, storagePool, nil)
+
+	assert.NotEqual(t, createRequest.ProtocolTypes, filesystem.ProtocolTypes, "protocol type matches")
+	assert.Error(t, result, "create failed")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func getStructsForCreateClone(ctx context.Context, driver *NASStorageDriver, storagePool storage.Pool) (
+	*storage.VolumeConfig, *storage.VolumeConfig, *api.FilesystemCreateRequest, *api.FileSystem, *api.FileSystem,
+	*api.Snapshot,
+) {
+	sourceVolumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+	cloneVolumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol2")
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+
+	sourceVolConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   sourceVolumeID,
+	}
+
+	cloneVolConfig := &storage.VolumeConfig{
+		Version:                   "1",
+		Name:                      "testvol2",
+		InternalName:              "trident-testvol2",
+		CloneSourceVolume:         "testvol1",
+		CloneSourceVolumeInternal: "trident-testvol1",
+	}
+
+	snapshotDir, _ := strconv.ParseBool(defaultSnapshotDir)
+
+	apiExportRule := api.ExportRule{
+		AllowedClients: defaultExportRule,
+		Cifs:           false,
+		Nfsv3:          true,
+		Nfsv41:         false,
+		RuleIndex:      1,
+		UnixReadOnly:   false,
+		UnixReadWrite:  true,
+	}
+	exportPolicy := api.ExportPolicy{
+		Rules: []api.ExportRule{apiExportRule},
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	poolLabels, _ := storagePool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+	labels[storage.ProvisioningLabelTag] = poolLabels
+
+	createRequest := &api.FilesystemCreateRequest{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              cloneVolConfig.Name,
+		SubnetID:          subnetID,
+		CreationToken:     cloneVolConfig.InternalName,
+		ExportPolicy:      exportPolicy,
+		Labels:            labels,
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+		SnapshotID:        SnapshotUUID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	sourceFilesystem := &api.FileSystem{
+		ID:                sourceVolumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		ExportPolicy:      exportPolicy,
+		Labels:            labels,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		SnapshotDirectory: snapshotDir,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	cloneFilesystem := &api.FileSystem{
+		ID:                cloneVolumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol2",
+		FullName:          "RG1/NA1/CP1/testvol2",
+		Location:          Location,
+		ExportPolicy:      exportPolicy,
+		Labels:            labels,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol2",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: snapshotDir,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	snapshot := &api.Snapshot{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Volume:            "testvol1",
+		Name:              "snap1",
+		FullName:          "RG1/NA1/CP1/testvol1/snap1",
+		Location:          Location,
+		Created:           time.Now(),
+		SnapshotID:        SnapshotUUID,
+		ProvisioningState: api.StateAvailable,
+	}
+
+	return sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot
+}
+
+func TestRecordVolumePlacement(t *testing.T) {
+	tests := []struct {
+		name                 string
+		volumeServiceLevel   string
+		expectedServiceLevel string
+	}{
+		{"ServiceLevelReported", api.ServiceLevelPremium, api.ServiceLevelPremium},
+		{"ServiceLevelNotReported", "", api.ServiceLevelUltra},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			volConfig := &storage.VolumeConfig{ServiceLevel: api.ServiceLevelUltra}
+			volume := &api.FileSystem{
+				ResourceGroup: "RG1",
+				NetAppAccount: "NA1",
+				CapacityPool:  "CP2",
+				ServiceLevel:  test.volumeServiceLevel,
+			}
+
+			recordVolumePlacement(volConfig, volume)
+
+			assert.Equal(t, "RG1/NA1/CP2", volConfig.CapacityPool, "capacity pool mismatch")
+			assert.Equal(t, test.expectedServiceLevel, volConfig.ServiceLevel, "service level mismatch")
+		})
+	}
+}
+
+func TestCreateClone_NoSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NetworkFeatures = api.NetworkFeaturesBasic
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	createRequest.NetworkFeatures = api.NetworkFeaturesBasic
+	sourceFilesystem.NetworkFeatures = api.NetworkFeaturesBasic
+	cloneFilesystem.NetworkFeatures = api.NetworkFeaturesBasic
+	sourceVolConfig.SnapshotDir = "false"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "RG1/NA1/CP1", cloneVolConfig.CapacityPool, "capacity pool not set on volConfig")
+}
+
+func TestCreateClone_ReuseRecentSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.cloneSnapshotReuseAge = time.Hour
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	sourceVolConfig.SnapshotDir = "false"
+	snapshot.Created = time.Now().Add(-10 * time.Minute)
+
+	olderSnapshot := *snapshot
+	olderSnapshot.Name = "snap0"
+	olderSnapshot.SnapshotID = "older"
+	olderSnapshot.Created = time.Now().Add(-30 * time.Minute)
+
+	expiredSnapshot := *snapshot
+	expiredSnapshot.Name = "snap-expired"
+	expiredSnapshot.SnapshotID = "expired"
+	expiredSnapshot.Created = time.Now().Add(-2 * time.Hour)
+
+	creatingSnapshot := *snapshot
+	creatingSnapshot.Name = "snap2"
+	creatingSnapshot.SnapshotID = "creating"
+	creatingSnapshot.Created = time.Now()
+	creatingSnapshot.ProvisioningState = api.StateCreating
+
+	snapshots := []*api.Snapshot{&olderSnapshot, snapshot, &expiredSnapshot, &creatingSnapshot}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotsForVolume(ctx, sourceFilesystem).Return(&snapshots, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreateClone_NoRecentSnapshot(t *testing.T) {
+	tests := []struct {
+		name         string
+		snapshotsErr error
+	}{
+		{"NoneRecent", nil},
+		{"ListFailed", errFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.cloneSnapshotReuseAge = time.Hour
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+
+			sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem,
+				snapshot := getStructsForCreateClone(ctx, driver, storagePool)
+			sourceVolConfig.SnapshotDir = "false"
+
+			expiredSnapshot := *snapshot
+			expiredSnapshot.Created = time.Now().Add(-2 * time.Hour)
+			snapshots := []*api.Snapshot{&expiredSnapshot}
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+			mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().SnapshotsForVolume(ctx, sourceFilesystem).Return(&snapshots, test.snapshotsErr).Times(1)
+			mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+			mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable,
+				[]string{api.StateError}, api.SnapshotTimeout).Return(nil).Times(1)
+			mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+			mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+			mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+				driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+			result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+			assert.NoError(t, result, "create failed")
+			assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+		})
+	}
+}
+
+func TestCreateClone_SplitOnClone(t *testing.T) {
+	tests := []struct {
+		name              string
+		backendSplit      string
+		poolSplit         string
+		volumeSplit       string
+		asyncVolumeCreate bool
+		expectSplit       bool
+	}{
+		{"Default", "", "", "", false, false},
+		{"Backend", "true", "", "", false, true},
+		{"PoolOverridesBackend", "true", "false", "", false, false},
+		{"VolumeOverridesPool", "", "false", "true", false, true},
+		{"VolumeOverridesBackend", "true", "", "false", false, false},
+		{"AsyncVolumeCreate", "true", "", "", true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+			driver.Config.NASType = "nfs"
+			driver.Config.SplitOnClone = test.backendSplit
+			driver.Config.AsyncVolumeCreate = test.asyncVolumeCreate
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePool := driver.pools["anf_pool"]
+			storagePool.InternalAttributes()[SplitOnClone] = test.poolSplit
+
+			sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(
+				ctx, driver, storagePool)
+			cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+			cloneVolConfig.SplitOnClone = test.volumeSplit
+			sourceVolConfig.SnapshotDir = "false"
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+			mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+			mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+			mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Return(cloneFilesystem, nil).Times(1)
+			mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+				driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+			if test.expectSplit {
+				mockAPI.EXPECT().SplitCloneFromParent(gomock.Any(), cloneFilesystem).Return(nil).Times(1)
+			} else {
+				mockAPI.EXPECT().SplitCloneFromParent(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, storagePool)
+
+			assert.NoError(t, result, "create failed")
+			assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+		})
+	}
+}
+
+func TestCreateClone_SplitOnCloneFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	cloneVolConfig.SplitOnClone = "true"
+	sourceVolConfig.SnapshotDir = "false"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().SplitCloneFromParent(gomock.Any(), cloneFilesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, cloneFilesystem).Return(nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreateClone_InvalidSplitOnClone(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	cloneVolConfig.SplitOnClone = "maybe"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestCreateClone_Snapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreateClone_InheritSnapshotPolicy(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.CloneInheritSnapshotPolicy = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.SnapshotPolicyID = api.CreateSnapshotPolicyID(SubscriptionID, "RG1", "NA1", "policy1")
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().SnapshotPolicyExists(ctx, sourceFilesystem.SnapshotPolicyID).Return(true, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *api.FilesystemCreateRequest) (*api.FileSystem, error) {
+			assert.Equal(t, sourceFilesystem.SnapshotPolicyID, request.SnapshotPolicy, "snapshot policy not inherited")
+			return cloneFilesystem, nil
+		}).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, sourceFilesystem.SnapshotPolicyID, cloneVolConfig.SnapshotPolicy, "snapshot policy not set")
+}
+
+func TestCreateClone_InheritSnapshotPolicy_Disabled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.SnapshotPolicyID = api.CreateSnapshotPolicyID(SubscriptionID, "RG1", "NA1", "policy1")
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Empty(t, createRequest.SnapshotPolicy, "snapshot policy inherited")
+}
+
+func TestCreateClone_InheritSnapshotPolicy_PolicyMissing(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.CloneInheritSnapshotPolicy = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.SnapshotPolicyID = api.CreateSnapshotPolicyID(SubscriptionID, "RG1", "NA1", "policy1")
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().SnapshotPolicyExists(ctx, sourceFilesystem.SnapshotPolicyID).Return(false, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Empty(t, cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_InheritSnapshotPolicy_CheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.CloneInheritSnapshotPolicy = true
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.SnapshotPolicyID = api.CreateSnapshotPolicyID(SubscriptionID, "RG1", "NA1", "policy1")
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().SnapshotPolicyExists(ctx, sourceFilesystem.SnapshotPolicyID).Return(false, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "create did not fail")
+	assert.Empty(t, cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_ManualQoS(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.ThroughputMibps = 64
+	createRequest.ThroughputMibps = 64
+
+	cPools := []*api.CapacityPool{
+		{
+			Name: sourceFilesystem.CapacityPool, ResourceGroup: sourceFilesystem.ResourceGroup,
+			NetAppAccount: sourceFilesystem.NetAppAccount, QosType: api.QosTypeManual,
+		},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CapacityPools().Return(&cPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestCreateClone_AutoQoS_IgnoresSourceThroughput(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	sourceFilesystem.ThroughputMibps = 64
+
+	cPools := []*api.CapacityPool{
+		{
+			Name: sourceFilesystem.CapacityPool, ResourceGroup: sourceFilesystem.ResourceGroup,
+			NetAppAccount: sourceFilesystem.NetAppAccount, QosType: api.QosTypeAuto,
+		},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CapacityPools().Return(&cPools).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NoError(t, result, "create failed")
+	assert.Zero(t, createRequest.ThroughputMibps, "throughput set for auto QoS pool")
+}
+
+func TestCreateClone_ROClone(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceFilesystem.SnapshotDirectory = true
+	cloneVolConfig.ReadOnlyClone = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Nil(t, result, "create failed")
+	assert.NoError(t, result, "error occurred")
+}
+
+func TestCreateClone_ROCloneFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	sourceVolConfig.SnapshotDir = "false"
+	cloneVolConfig.ReadOnlyClone = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.NotNil(t, result, "create succeeded")
+	assert.Error(t, result, "expected error")
+}
+
+func TestCreateClone_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_InvalidVolumeName(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	cloneVolConfig.Name = "1testvol"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_InvalidCreationToken(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	cloneVolConfig.InternalName = "1testvol"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_NonexistentSourceVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SourceVolumeInOtherLocation(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	sourceFilesystem.Location = "other-location"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, gomock.Any()).Times(0)
+	mockAPI.EXPECT().CreateSnapshot(ctx, gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Contains(t, result.Error(), "cross-region replication", "error does not suggest replication")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestSameAzureLocation(t *testing.T) {
+	tests := []struct {
+		Location1 string
+		Location2 string
+		Expected  bool
+	}{
+		{"eastus", "eastus", true},
+		{"eastus", "East US", true},
+		{"EastUS", "eastus", true},
+		{"eastus", "eastus2", false},
+		{"eastus", "westus", false},
+	}
+	for _, test := range tests {
+		t.Run(test.Location1+"/"+test.Location2, func(t *testing.T) {
+			assert.Equal(t, test.Expected, sameAzureLocation(test.Location1, test.Location2))
+		})
+	}
+}
+
+func TestTopologyLocations(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []map[string]string
+		expected []string
+	}{
+		{"None", nil, []string{}},
+		{"Region", []map[string]string{{topologyRegionLabel: "eastus2"}}, []string{"eastus2"}},
+		{"Zone", []map[string]string{{topologyZoneLabel: "eastus2-1"}}, []string{"eastus2"}},
+		{"RegionAndZone", []map[string]string{
+			{topologyRegionLabel: "East US 2", topologyZoneLabel: "westus-1"},
+		}, []string{"eastus2"}},
+		{"Duplicates", []map[string]string{
+			{topologyZoneLabel: "eastus2-1"}, {topologyZoneLabel: "eastus2-2"}, {topologyZoneLabel: "westus-1"},
+		}, []string{"eastus2", "westus"}},
+		{"NonZonal", []map[string]string{{topologyZoneLabel: "0"}}, []string{}},
+		{"OtherLabels", []map[string]string{{"example.com/rack": "rack-1"}}, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, topologyLocations(test.segments))
+		})
+	}
+}
+
+func TestParseAvailabilityZone(t *testing.T) {
+	tests := []struct {
+		zone             string
+		expectedLocation string
+		expectedNumber   string
+		expectedOK       bool
+	}{
+		{"1", "", "1", true},
+		{"eastus2-3", "eastus2", "3", true},
+		{"East US 2-2", "eastus2", "2", true},
+		{"", "", "", false},
+		{"0", "", "", false},
+		{"eastus2-0", "", "", false},
+		{"us-east-1c", "", "", false},
+		{"zone1", "", "", false},
+		{"eastus2-01", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.zone, func(t *testing.T) {
+			location, number, ok := parseAvailabilityZone(test.zone)
+
+			assert.Equal(t, test.expectedLocation, location, "location mismatch")
+			assert.Equal(t, test.expectedNumber, number, "number mismatch")
+			assert.Equal(t, test.expectedOK, ok, "ok mismatch")
+		})
+	}
+}
+
+func TestFilterByTopology(t *testing.T) {
+	cPools := []*api.CapacityPool{
+		{Name: "CP1", Location: "eastus"},
+		{Name: "CP2", Location: "West US"},
+		{Name: "CP3", Location: "eastus2"},
+	}
+	location := func(cPool *api.CapacityPool) string { return cPool.Location }
+	names := func(cPools []*api.CapacityPool) []string {
+		result := make([]string, 0)
+		for _, cPool := range cPools {
+			result = append(result, cPool.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		requisite []string
+		preferred []string
+		expected  []string
+	}{
+		{"NoTopology", nil, nil, []string{"CP1", "CP2", "CP3"}},
+		{"Requisite", []string{"westus", "eastus2"}, nil, []string{"CP2", "CP3"}},
+		{"Preferred", nil, []string{"eastus2"}, []string{"CP3", "CP1", "CP2"}},
+		{"RequisiteAndPreferred", []string{"eastus", "westus"}, []string{"westus"}, []string{"CP2", "CP1"}},
+		{"NoMatch", []string{"centralus"}, nil, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, names(filterByTopology(cPools, location, test.requisite, test.preferred)))
+		})
+	}
+}
+
+func TestCreateClone_VolumeExistsCheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
+		storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_VolumeExistsCreating(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
+		storagePool)
+	cloneFilesystem.ProvisioningState = api.StateCreating
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(true, cloneFilesystem, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.IsType(t,
+		errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_VolumeExists(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
+		storagePool)
+	cloneFilesystem.ProvisioningState = api.StateAvailable
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(true, cloneFilesystem, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.IsType(t, drivers.NewVolumeExistsError(""), result, "not VolumeExistsError")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SnapshotNotFound(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
+		storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SnapshotNotAvailable(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	snapshot.ProvisioningState = api.StateError
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SnapshotCreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
+		storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SnapshotWaitFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_SnapshotRefetchFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_InvalidLabel(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.Labels = map[string]string{
+		"key1": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+		"key2": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+		"key3": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
+	}
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestCreateClone_CreateFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+
+	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
+		driver, storagePool)
+	sourceVolConfig.SnapshotDir = "false"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+
+	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+}
+
+func getStructsForImport(ctx context.Context, driver *NASStorageDriver) (*storage.VolumeConfig, *api.FileSystem) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+	}
+
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	apiExportRule := api.ExportRule{
+		AllowedClients:      defaultExportRule,
+		Cifs:                false,
+		Nfsv3:               false,
+		Nfsv41:              false,
+		RuleIndex:           1,
+		UnixReadOnly:        false,
+		UnixReadWrite:       false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5PReadWrite: false,
+		Kerberos5IReadWrite: false,
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	originalFilesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "importMe",
+		FullName:          "RG1/NA1/CP1/importMe",
+		Location:          Location,
+		Labels:            make(map[string]string),
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "importMe",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+		ExportPolicy:      api.ExportPolicy{Rules: []api.ExportRule{apiExportRule}},
+	}
+
+	return volConfig, originalFilesystem
+}
+
+func getStructsForSMBImport(ctx context.Context, driver *NASStorageDriver) (*storage.VolumeConfig, *api.FileSystem) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+	}
+
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	originalFilesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "importMe",
+		FullName:          "RG1/NA1/CP1/importMe",
+		Location:          Location,
+		Labels:            make(map[string]string),
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "importMe",
+		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+	}
+
+	return volConfig, originalFilesystem
+}
+
+func TestImport_Managed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_Managed_SnapshotReserve(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.SnapshotReserve = "20"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.QuotaInBytes = VolumeSizeI64 * 100 / 80
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+		SnapshotReserveTag:      "20",
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, "20", volConfig.SnapshotReserve, "snapshot reserve mismatch")
+}
+
+func TestImport_Managed_SnapshotReserveFromVolumeConfig(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.SnapshotReserve = "20"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig.SnapshotReserve = "50"
+	originalFilesystem.QuotaInBytes = VolumeSizeI64 * 2
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+		SnapshotReserveTag:      "50",
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, "50", volConfig.SnapshotReserve, "snapshot reserve mismatch")
+}
+
+func TestImport_Managed_InvalidSnapshotReserve(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig.SnapshotReserve = "95"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Times(0)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestImport_Managed_SnapshotPolicy(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.SnapshotPolicy = "daily"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeSnapshotPolicy(ctx, originalFilesystem, "daily").Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, "daily", volConfig.SnapshotPolicy, "snapshot policy mismatch")
+}
+
+func TestImport_Managed_SnapshotPolicyFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.SnapshotPolicy = "daily"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeSnapshotPolicy(ctx, originalFilesystem, "daily").Return(errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestImport_Managed_CoolAccess(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelStandard
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.CoolAccess = "true"
+	driver.Config.CoolnessPeriod = "10"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.ServiceLevel = api.ServiceLevelStandard
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeCoolAccess(ctx, originalFilesystem, true, int32(10)).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+}
+
+func TestImport_Managed_CoolAccessAlreadyEnabled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelStandard
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.CoolAccess = "true"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.ServiceLevel = api.ServiceLevelStandard
+	originalFilesystem.CoolAccess = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeCoolAccess(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+}
+
+func TestImport_Managed_CoolAccessWrongServiceLevel(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.CoolAccess = "true"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.ServiceLevel = api.ServiceLevelUltra
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeCoolAccess(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestImport_ManagedWithKerberos5(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              true,
+		Kerberos5ReadWrite:  true,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = true
+	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
+	originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite, "kerberos protocol type mismatch")
+}
+
+func TestImport_ManagedWithKerberos5I(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5i"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              true,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: true,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = true
+	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
+	originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite, "kerberos protocol type mismatch")
+}
+
+func TestImport_ManagedWithKerberos5P(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5p"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              true,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: true,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = true
+	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
+	originalFilesystem.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5PReadWrite, "kerberos protocol type mismatch")
+}
+
+func TestImport_ManagedWithKerberos_IncorrectProtocolType(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              true,
+		Kerberos5ReadWrite:  true,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = true
+	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
+	originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite = false
+	originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels, &expectedUnixPermissions, &snapshotDirAccess,
+		&exportRule).Return(errors.New("Could not import volume, volume modify failed.")).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import succeeded")
+	assert.False(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite, "kerberos protocol type mismatch")
+}
+
+func TestImport_ManagedWithKerberosDisabled(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = false
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import succeeded")
+	assert.False(t, originalFilesystem.KerberosEnabled, "kerberosEnabled flag is set")
+}
+
+func TestImport_ManagedWithKerberosNotSetInConfig(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.KerberosEnabled = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import succeeded")
+	assert.True(t, originalFilesystem.KerberosEnabled, "kerberosEnabled flag is set")
+}
+
+func TestImport_Managed_LDAPVolumeOnNonLDAPBackend(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.LdapEnabled = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Times(0)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.ErrorContains(t, result, "LDAP", "import succeeded")
+}
+
+func TestImport_Managed_LDAPVolumeOnLDAPBackend(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+	driver.Config.LDAPEnabled = "true"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.LdapEnabled = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_ManagedWithSnapshotDir(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	volConfig.SnapshotDir = "true"
+	snapshotDirAccess := true
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_ManagedWithSnapshotDirFalse(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	volConfig.SnapshotDir = "false"
+	snapshotDirAccess := false
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_ManagedWithInvalidSnapshotDirValue(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	volConfig.SnapshotDir = "xxxffa"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import succeeded")
+	assert.NotNil(t, result, "received nil")
+}
+
+func TestImport_SMB_Managed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{}
+
+	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		nil, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_SMB_Managed_SMBOptions(t *testing.T) {
+	tests := []struct {
+		Name                    string
+		ContinuousAvailability  string
+		Encryption              string
+		VolumeContinuouslyAvail bool
+		VolumeEncryption        bool
+		Valid                   bool
+	}{
+		{"NotRequired", "", "", false, false, true},
+		{"BothOffered", "true", "true", true, true, true},
+		{"NoContinuousAvailability", "true", "", false, true, false},
+		{"NoEncryption", "", "true", true, false, false},
+		{"Invalid", "maybe", "", true, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.BackendName = "anf"
+			driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			driver.initializeTelemetry(ctx, BackendUUID)
+			driver.Config.NASType = "smb"
+			driver.Config.SMBContinuousAvailability = test.ContinuousAvailability
+			driver.Config.SMBEncryption = test.Encryption
+
+			originalName := "importMe"
+
+			volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+			originalFilesystem.SmbContinuouslyAvailable = test.VolumeContinuouslyAvail
+			originalFilesystem.SmbEncryption = test.VolumeEncryption
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+			mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+			if test.Valid {
+				mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), nil, gomock.Any(),
+					gomock.Any()).Return(nil).Times(1)
+				mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable,
+					[]string{api.StateError}, driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+			}
+
+			result := driver.Import(ctx, volConfig, originalName)
+
+			if test.Valid {
+				assert.NoError(t, result, "import failed")
+			} else {
+				assert.Error(t, result, "import did not fail")
+			}
+		})
+	}
+}
+
+func TestImport_SMB_Managed_CoolAccessIgnored(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelStandard
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
+	driver.Config.CoolAccess = "true"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeCoolAccess(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+}
+
+func TestImport_SMB_Failed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{}
+
+	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		nil, &snapshotDirAccess, &exportRule).Return(errors.New("unix permissions not applicable for SMB")).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import failed")
+}
+
+func TestImport_DualProtocolVolume_NFS(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	// Dual-protocol volume has ProtocolTypes as [NFSv3, CIFS]
+	originalFilesystem.ProtocolTypes = append(originalFilesystem.ProtocolTypes, api.ProtocolTypeCIFS)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &api.ExportRule{}).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+}
+
+func TestImport_DualProtocolVolume_SMB(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	// Dual-protocol volume has ProtocolTypes as [NFSv3, CIFS]
+	originalFilesystem.ProtocolTypes = append(originalFilesystem.ProtocolTypes, api.ProtocolTypeCIFS)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		nil, &snapshotDirAccess, &api.ExportRule{}).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+}
+
+func TestVolumeSupportsNASType(t *testing.T) {
+	tests := []struct {
+		name          string
+		protocolTypes []string
+		nfs           bool
+		smb           bool
+	}{
+		{"NFSv3", []string{api.ProtocolTypeNFSv3}, true, false},
+		{"NFSv41", []string{api.ProtocolTypeNFSv41}, true, false},
+		{"CIFS", []string{api.ProtocolTypeCIFS}, false, true},
+		{"DualProtocol", []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}, true, true},
+		{"None", nil, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			volume := &api.FileSystem{ProtocolTypes: test.protocolTypes}
+
+			assert.Equal(t, test.nfs, volumeSupportsNASType(volume, sa.NFS), "NFS mismatch")
+			assert.Equal(t, test.smb, volumeSupportsNASType(volume, sa.SMB), "SMB mismatch")
+			assert.Equal(t, test.nfs && test.smb, volumeSupportsNASType(volume, NASTypeDual), "dual mismatch")
+		})
+	}
+}
+
+func TestImport_ManagedWithLabels(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = ""
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.UnixPermissions = "0700"
+	originalFilesystem.Labels = map[string]string{
+		storage.ProvisioningLabelTag: `{"provisioning":{"cloud":"anf","clusterName":"dev-test-cluster-1"}}`,
+	}
+
+	expectedLabels := map[string]string{
+		storage.ProvisioningLabelTag: "",
+		drivers.TridentLabelTag:      driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0700"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_NotManaged(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig.ImportNotManaged = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.NoError(t, result, "import failed")
+	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestImport_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+
+	volConfig, _ := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_NotFound(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+
+	volConfig, _ := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(nil, errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_InvalidCreationToken(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	originalName := "1_import.me"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	originalFilesystem.CreationToken = originalName
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_InvalidCapacityPool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_InvalidUnixPermissions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "8888"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_ModifyVolumeFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_VolumeWaitFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.UnixPermissions = "0770"
+	driver.Config.NASType = "nfs"
+
+	originalName := "importMe"
+	var snapshotDirAccess bool
+
+	exportRule := api.ExportRule{
+		Nfsv41:              false,
+		Kerberos5ReadWrite:  false,
+		Kerberos5IReadWrite: false,
+		Kerberos5PReadWrite: false,
+	}
+
+	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+
+	expectedLabels := map[string]string{
+		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	}
+	expectedUnixPermissions := "0770"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
+		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return("", errFailed).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
+	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+}
+
+func TestImport_BackendVolumeMismatch(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	originalName := "importMe"
+
+	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+
+	result := driver.Import(ctx, volConfig, originalName)
+
+	assert.Error(t, result, "import should fail")
+}
+
+func TestRename(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:          "testvol1",
+		CreationToken: "oldName",
+		Labels:        map[string]string{drivers.TridentLabelTag: "telemetry"},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "oldName").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, filesystem, map[string]string{TridentNameTag: "newName"}, nil, nil,
+		nil).Return(nil).Times(1)
+
+	result := driver.Rename(ctx, "oldName", "newName")
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, "oldName", filesystem.CreationToken, "creation token changed")
+}
+
+func TestRename_AlreadyRenamed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:          "testvol1",
+		CreationToken: "oldName",
+		Labels:        map[string]string{TridentNameTag: "newName"},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "oldName").Return(filesystem, nil).Times(1)
+
+	result := driver.Rename(ctx, "oldName", "newName")
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestRename_ImportCleanup(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	// Import failure cleanup renames the volume to its original name, which is also its creation token
+	result := driver.Rename(ctx, "importMe", "importMe")
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestRename_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.Rename(ctx, "oldName", "newName")
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestRename_NotFound(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "oldName").Return(nil, errFailed).Times(1)
+
+	result := driver.Rename(ctx, "oldName", "newName")
+
+	assert.Error(t, result, "expected error")
+}
+
+func TestRename_ModifyFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{Name: "testvol1", CreationToken: "oldName"}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "oldName").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, filesystem, map[string]string{TridentNameTag: "newName"}, nil, nil,
+		nil).Return(errFailed).Times(1)
+
+	result := driver.Rename(ctx, "oldName", "newName")
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "oldName", filesystem.CreationToken, "creation token changed")
+}
+
+func TestGetTelemetryLabels(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	result := driver.getTelemetryLabels(ctx)
+
+	assert.True(t, strings.HasPrefix(result, `{"trident":{`))
+}
+
+func TestUpdateTelemetryLabels(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	result := driver.updateTelemetryLabels(ctx, &api.FileSystem{})
+
+	assert.NotNil(t, result)
+	assert.True(t, strings.HasPrefix(result[drivers.TridentLabelTag], `{"trident":{`))
+}
+
+func TestWaitForVolumeCreate_Available(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result)
+}
+
+func TestWaitForVolumeCreate_ReadFailedThenAvailable(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	gomock.InOrder(
+		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+			driver.volumeCreateTimeout).Return("", errFailed).Times(1),
+		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+			volumeCreateReadRetryTimeout).Return(api.StateAvailable, nil).Times(1),
+	)
+	mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result)
+}
+
+func TestWaitForVolumeCreate_ReadFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return("", errFailed).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		volumeCreateReadRetryTimeout).Return("", errFailed).Times(maxVolumeCreateReadAttempts - 1)
+	mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Times(0)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Error(t, result, "expected error")
+	assert.True(t, errors.IsVolumeCreatingError(result), "not VolumeCreatingError")
+}
+
+func TestWaitForVolumeCreate_Creating(t *testing.T) {
+	for _, state := range []string{api.StateAccepted, api.StateCreating} {
+
+		mockAPI, driver := newMockANFDriver(t)
+
+		filesystem := &api.FileSystem{
+			Name:              "testvol1",
+			CreationToken:     "netapp-testvol1",
+			ProvisioningState: api.StateCreating,
+		}
+
+		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+			driver.volumeCreateTimeout).Return(state, errFailed).Times(1)
+
+		result := driver.waitForVolumeCreate(ctx, filesystem)
+
+		assert.Error(t, result, "expected error")
+		assert.IsType(t, errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
+	}
+}
+
+func TestWaitForVolumeCreate_ContextCancelled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	mockAPI.EXPECT().WaitForVolumeState(cancelledCtx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return("", context.Canceled).Times(1)
+	mockAPI.EXPECT().DeleteVolume(cancelledCtx, gomock.Any()).Times(0)
+
+	result := driver.waitForVolumeCreate(cancelledCtx, filesystem)
+
+	assert.Error(t, result, "expected error")
+	assert.True(t, errors.IsVolumeCreatingError(result), "not VolumeCreatingError")
+}
+
+func TestWaitForVolumeCreate_DeletingDeleteFinished(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestWaitForVolumeCreate_DeletingDeleteNotFinished(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleting, errFailed).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestWaitForVolumeCreate_ErrorDelete(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateError, errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestWaitForVolumeCreate_ErrorDeleteFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateError, errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(errFailed).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestWaitForVolumeCreate_OtherStates(t *testing.T) {
+	for _, state := range []string{api.StateMoving, "unknown"} {
+
+		mockAPI, driver := newMockANFDriver(t)
+
+		filesystem := &api.FileSystem{
+			Name:              "testvol1",
+			CreationToken:     "netapp-testvol1",
+			ProvisioningState: api.StateCreating,
+		}
+
+		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+			driver.volumeCreateTimeout).Return(state, errFailed).Times(1)
+
+		result := driver.waitForVolumeCreate(ctx, filesystem)
+
+		assert.Nil(t, result, "not nil")
+	}
+}
+
+func getStructsForDestroyNFSVolume(
+	ctx context.Context, driver *NASStorageDriver,
+) (*storage.VolumeConfig, *api.FileSystem) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   volumeID,
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	apiExportRule := api.ExportRule{
+		AllowedClients: defaultExportRule,
+		Cifs:           false,
+		Nfsv3:          true,
+		Nfsv41:         false,
+		RuleIndex:      1,
+		UnixReadOnly:   false,
+		UnixReadWrite:  true,
+	}
+	exportPolicy := api.ExportPolicy{
+		Rules: []api.ExportRule{apiExportRule},
+	}
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		ExportPolicy:      exportPolicy,
+		Labels:            make(map[string]string),
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+		NetworkFeatures:   api.NetworkFeaturesStandard,
+	}
+
+	return volConfig, filesystem
+}
+
+func getStructsForDestroySMBVolume(
+	ctx context.Context, driver *NASStorageDriver,
+) (*storage.VolumeConfig, *api.FileSystem) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   volumeID,
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		Labels:            make(map[string]string),
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+		NetworkFeatures:   api.NetworkFeaturesStandard,
+	}
+
+	return volConfig, filesystem
+}
+
+func TestDestroy_NFSVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestDestroy_NFSVolume_ContextDeadline(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	mockAPI.EXPECT().RefreshAzureResources(deadlineCtx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(deadlineCtx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(deadlineCtx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(deadlineCtx, filesystem, api.StateDeleted, []string{api.StateError},
+		gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *api.FileSystem, _ string, _ []string, timeout time.Duration) (string, error) {
+			assert.LessOrEqual(t, timeout, 5*time.Second, "timeout exceeds context deadline")
+			assert.Less(t, timeout, driver.defaultTimeout(), "timeout not shortened")
+			return api.StateDeleted, nil
+		}).Times(1)
+
+	result := driver.Destroy(deadlineCtx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestEffectiveTimeout(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	// No deadline
+	assert.Equal(t, 2*time.Minute, driver.effectiveTimeout(ctx, 2*time.Minute))
+
+	// Deadline later than timeout
+	laterCtx, cancelLater := context.WithTimeout(ctx, time.Hour)
+	defer cancelLater()
+	assert.Equal(t, 2*time.Minute, driver.effectiveTimeout(laterCtx, 2*time.Minute))
+
+	// Deadline sooner than timeout
+	soonerCtx, cancelSooner := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelSooner()
+	result := driver.effectiveTimeout(soonerCtx, 2*time.Minute)
+	assert.LessOrEqual(t, result, 10*time.Second)
+	assert.Greater(t, result, time.Duration(0))
+
+	// Deadline already passed
+	expiredCtx, cancelExpired := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancelExpired()
+	assert.Equal(t, minEffectiveTimeout, driver.effectiveTimeout(expiredCtx, 2*time.Minute))
+
+	// Deadline imminent
+	imminentCtx, cancelImminent := context.WithTimeout(ctx, time.Millisecond)
+	defer cancelImminent()
+	assert.Equal(t, minEffectiveTimeout, driver.effectiveTimeout(imminentCtx, 2*time.Minute))
+}
+
+func TestDestroy_NFSVolume_ContextDeadlinePassed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	expiredCtx, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+
+	mockAPI.EXPECT().RefreshAzureResources(expiredCtx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(expiredCtx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(expiredCtx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(expiredCtx, filesystem, api.StateDeleted, []string{api.StateError},
+		minEffectiveTimeout).Return(api.StateDeleting, errFailed).Times(1)
+
+	result := driver.Destroy(expiredCtx, volConfig)
+
+	assert.True(t, errors.IsVolumeDeletingError(result), "not VolumeDeletingError")
+}
+
+func TestDestroy_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDestroy_VolumeExistsCheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDestroy_AlreadyDeleted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestDestroy_StillDeletingDeleted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ProvisioningState = api.StateDeleting
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateDeleted, nil).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestDestroy_StillDeleting(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ProvisioningState = api.StateDeleting
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDestroy_DeleteFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(errFailed).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDestroy_VolumeWaitFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleting, errFailed).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDestroy_VolumeWaitCancelled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	mockAPI.EXPECT().RefreshAzureResources(cancelCtx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(cancelCtx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(cancelCtx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(cancelCtx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).DoAndReturn(
+		func(_ context.Context, _ *api.FileSystem, _ string, _ []string, _ time.Duration) (string, error) {
+			cancel()
+			return api.StateDeleting, context.Canceled
+		}).Times(1)
+
+	result := driver.Destroy(cancelCtx, volConfig)
+
+	assert.Error(t, result, "expected error")
+	assert.True(t, errors.IsVolumeDeletingError(result), "not VolumeDeletingError")
+}
+
+func TestDestroy_SMBVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroySMBVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func getStructsForPublishNFSVolume(
+	ctx context.Context, driver *NASStorageDriver,
+) (*storage.VolumeConfig, *api.FileSystem, *utils.VolumePublishInfo) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   volumeID,
+		AccessInfo: utils.VolumeAccessInfo{
+			NfsAccessInfo: utils.NfsAccessInfo{
+				NfsPath: "/trident-testvol1",
+			},
+		},
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	mountTargets := []api.MountTarget{
+		{
+			MountTargetID: "mountTargetID",
+			FileSystemID:  "filesystemID",
+			IPAddress:     "1.1.1.1",
+			ServerFqdn:    "",
+		},
+	}
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		Labels:            labels,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+		MountTargets:      mountTargets,
+	}
+
+	publishInfo := &utils.VolumePublishInfo{}
+
+	return volConfig, filesystem, publishInfo
+}
+
+func getStructsForPublishSMBVolume(
+	ctx context.Context, driver *NASStorageDriver,
+) (*storage.VolumeConfig, *api.FileSystem, *utils.VolumePublishInfo) {
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   volumeID,
+		AccessInfo: utils.VolumeAccessInfo{
+			SMBAccessInfo: utils.SMBAccessInfo{
+				SMBPath: "\\trident-testvol1",
+			},
+		},
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+	labels[storage.ProvisioningLabelTag] = ""
+
+	mountTargets := []api.MountTarget{
+		{
+			MountTargetID: "mountTargetID",
+			FileSystemID:  "filesystemID",
+			IPAddress:     "1.1.1.1",
+			ServerFqdn:    "trident-1234.trident.com",
+		},
+	}
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		Labels:            labels,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SnapshotDirectory: true,
+		SubnetID:          subnetID,
+		MountTargets:      mountTargets,
+	}
+
+	publishInfo := &utils.VolumePublishInfo{}
+
+	return volConfig, filesystem, publishInfo
+}
+
+func TestPublish_NFSVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "nfsvers=3", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_NFSVolume_Kerberos_Type5(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	filesystem.KerberosEnabled = true
+
+	volConfig.MountOptions = "nfsvers=4.1"
+
+	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "trident-1234.trident.com", publishInfo.NfsServerIP, "server fqdn mismatch")
+	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "nfsvers=4.1", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_NFSVolume_Kerberos_Type5_NotSet(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+	driver.Config.Kerberos = "sec=krb5"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	filesystem.KerberosEnabled = false
+
+	volConfig.MountOptions = "nfsvers=4.1"
+
+	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotEqual(t, "trident-1234.trident.com", publishInfo.NfsServerIP, "server fqdn mismatch")
+}
+
+func TestPublish_ROClone_NFSVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig.AccessInfo.NfsPath = "/trident-testvol1/.snapshot/" + SnapshotUUID
+	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+	volConfig.CloneSourceVolumeInternal = volConfig.Name
+	volConfig.ReadOnlyClone = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.NoError(t, result, "error occurred")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "/trident-testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", publishInfo.NfsPath,
+		"NFS path mismatch")
+	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "nfsvers=3", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_SMBVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishSMBVolume(ctx, driver)
+	publishInfo.SMBPath = volConfig.AccessInfo.SMBPath
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "\\trident-testvol1", publishInfo.SMBPath, "SMB path mismatch")
+	assert.Equal(t, "trident-1234.trident.com", publishInfo.SMBServer, "SMB server mismatch")
+	assert.Equal(t, "smb", publishInfo.FilesystemType, "filesystem type mismatch")
+}
+
+func TestPublish_MultipleMountTargets(t *testing.T) {
+	tests := []struct {
+		nasType   string
+		kerberos  bool
+		nfsServer string
+		smbServer string
+	}{
+		{sa.NFS, false, "1.1.1.2", ""},
+		{sa.NFS, true, "trident-1234.trident.com", ""},
+		{sa.SMB, false, "", "trident-1234.trident.com"},
+		{NASTypeDual, false, "1.1.1.2", "trident-1234.trident.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.nasType+"/kerberos="+strconv.FormatBool(test.kerberos), func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.initializeTelemetry(ctx, BackendUUID)
+			driver.Config.NASType = test.nasType
+
+			volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+			filesystem.KerberosEnabled = test.kerberos
+			filesystem.MountTargets = []api.MountTarget{
+				{MountTargetID: "mountTarget1"},
+				{MountTargetID: "mountTarget2", IPAddress: "1.1.1.2"},
+				{MountTargetID: "mountTarget3", IPAddress: "1.1.1.3", ServerFqdn: "trident-1234.trident.com"},
+			}
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+			result := driver.Publish(ctx, volConfig, publishInfo)
+
+			assert.Nil(t, result, "not nil")
+			assert.Equal(t, test.nfsServer, publishInfo.NfsServerIP, "NFS server mismatch")
+			assert.Equal(t, test.smbServer, publishInfo.SMBServer, "SMB server mismatch")
+		})
+	}
+}
+
+func TestPublish_NoMatchingMountTarget(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = sa.SMB
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets = []api.MountTarget{{MountTargetID: "mountTarget1", IPAddress: "1.1.1.1"}}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Error(t, result, "expected error")
+	assert.Empty(t, publishInfo.SMBServer, "SMB server set")
+}
+
+func TestPublish_MountOptions(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "nfs"
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig.InternalID = ""
+	volConfig.MountOptions = "nfsvers=4.1"
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "nfsvers=4.1", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_NonexistentVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_ROClone_NonexistentVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig.AccessInfo.NfsPath = "/trident-testvol1/.snapshot/" + SnapshotUUID
+	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+	volConfig.CloneSourceVolumeInternal = volConfig.Name
+	volConfig.ReadOnlyClone = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(nil, errFailed).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.NotNil(t, result, "got nil")
+	assert.Error(t, result, "expected error")
+	assert.NotEqual(t, "", publishInfo.NfsPath, "NFS path is empty")
+	assert.Equal(t, "/trident-testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", publishInfo.NfsPath,
+		"NFS path mismatch")
+	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func TestPublish_NoMountTargets(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets = nil
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+
+	result := driver.Publish(ctx, volConfig, publishInfo)
+
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
+	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
+}
+
+func getStructsForCreateSnapshot(ctx context.Context, driver *NASStorageDriver, snapTime time.Time) (
+	*storage.VolumeConfig, *api.FileSystem, *storage.SnapshotConfig, *api.Snapshot,
+) {
+	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
+	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
+
+	volConfig := &storage.VolumeConfig{
+		Version:      "1",
+		Name:         "testvol1",
+		InternalName: "trident-testvol1",
+		Size:         VolumeSizeStr,
+		InternalID:   volumeID,
+	}
+
+	labels := make(map[string]string)
+	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
+
+	filesystem := &api.FileSystem{
+		ID:                volumeID,
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Name:              "testvol1",
+		FullName:          "RG1/NA1/CP1/testvol1",
+		Location:          Location,
+		Labels:            labels,
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "trident-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		QuotaInBytes:      VolumeSizeI64,
+		ServiceLevel:      api.ServiceLevelUltra,
+		SubnetID:          subnetID,
+		UnixPermissions:   defaultUnixPermissions,
+	}
+
+	snapConfig := &storage.SnapshotConfig{
+		Version:            "1",
+		Name:               "snap1",
+		InternalName:       "snap1",
+		VolumeName:         "testvol1",
+		VolumeInternalName: "trident-testvol1",
+	}
+
+	snapshot := &api.Snapshot{
+		ResourceGroup:     "RG1",
+		NetAppAccount:     "NA1",
+		CapacityPool:      "CP1",
+		Volume:            "testvol1",
+		Name:              "snap1",
+		FullName:          "RG1/NA1/CP1/testvol1/snap1",
+		Location:          Location,
+		Created:           snapTime,
+		SnapshotID:        SnapshotUUID,
+		ProvisioningState: api.StateAvailable,
+	}
+
+	return volConfig, filesystem, snapConfig, snapshot
+}
+
+func TestCanSnapshot(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	result := driver.CanSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestGetSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, snapConfig, result.Config, "snapshot mismatch")
+	assert.Equal(t, VolumeSizeI64, result.SizeBytes, "snapshot mismatch")
+	assert.Equal(t, storage.SnapshotStateOnline, result.State, "snapshot mismatch")
+}
+
+func TestGetSnapshot_SizeFromVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem.QuotaInBytes = 2 * VolumeSizeI64
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, 2*VolumeSizeI64, result.SizeBytes, "snapshot size mismatch")
+
+	// The size must survive conversion to the persistent and external forms
+	persistent := result.ConstructPersistent()
+	assert.Equal(t, 2*VolumeSizeI64, persistent.SizeBytes, "persistent snapshot size mismatch")
+	assert.Equal(t, 2*VolumeSizeI64, persistent.ConstructExternal().SizeBytes, "external snapshot size mismatch")
+}
+
+func TestGetSnapshot_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
+}
+
+func TestGetSnapshot_VolumeExistsCheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
+}
+
+func TestGetSnapshot_NonexistentVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.Nil(t, resultErr, "not nil")
+}
+
+func TestGetSnapshot_NonexistentSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(nil,
+		errors.NotFoundError("not found")).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.Nil(t, resultErr, "not nil")
+}
+
+func TestGetSnapshot_GetSnapshotFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(nil, errFailed).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
+}
+
+func TestGetSnapshot_SnapshotNotAvailable(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	snapshot.ProvisioningState = api.StateError
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+
+	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
+}
+
+func getSnapshotsForList(snapTime time.Time) *[]*api.Snapshot {
+	return &[]*api.Snapshot{
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			CapacityPool:      "CP1",
+			Volume:            "testvol1",
+			Name:              "snap1",
+			FullName:          "RG1/NA1/CP1/testvol1/snap1",
+			Location:          Location,
+			Created:           snapTime,
+			SnapshotID:        SnapshotUUID,
+			ProvisioningState: api.StateAvailable,
+		},
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			CapacityPool:      "CP1",
+			Volume:            "testvol1",
+			Name:              "snap2",
+			FullName:          "RG1/NA1/CP1/testvol1/snap2",
+			Location:          Location,
+			Created:           snapTime,
+			SnapshotID:        SnapshotUUID,
+			ProvisioningState: api.StateAvailable,
+		},
+		{
+			ResourceGroup:     "RG1",
+			NetAppAccount:     "NA1",
+			CapacityPool:      "CP1",
+			Volume:            "testvol1",
+			Name:              "snap3",
+			FullName:          "RG1/NA1/CP1/testvol1/snap3",
+			Location:          Location,
+			Created:           time.Now(),
+			SnapshotID:        "",
+			ProvisioningState: api.StateError,
+		},
+	}
+}
+
+func TestGetSnapshots(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, filesystem, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	snapshots := getSnapshotsForList(snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotsForVolume(ctx, filesystem).Return(snapshots, nil).Times(1)
+
+	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+
+	expectedSnapshot0 := &storage.Snapshot{
+		Config: &storage.SnapshotConfig{
+			Version:            tridentconfig.OrchestratorAPIVersion,
+			Name:               "snap1",
+			InternalName:       "snap1",
+			VolumeName:         "testvol1",
+			VolumeInternalName: "trident-testvol1",
+		},
+		Created:   snapTime.UTC().Format(utils.TimestampFormat),
+		SizeBytes: VolumeSizeI64,
+		State:     storage.SnapshotStateOnline,
+	}
+
+	expectedSnapshot1 := &storage.Snapshot{
+		Config: &storage.SnapshotConfig{
+			Version:            tridentconfig.OrchestratorAPIVersion,
+			Name:               "snap2",
+			InternalName:       "snap2",
+			VolumeName:         "testvol1",
+			VolumeInternalName: "trident-testvol1",
+		},
+		Created:   snapTime.UTC().Format(utils.TimestampFormat),
+		SizeBytes: VolumeSizeI64,
+		State:     storage.SnapshotStateOnline,
+	}
+
+	assert.Nil(t, resultErr, "not nil")
+	assert.Len(t, result, 2)
+
+	assert.Equal(t, result[0], expectedSnapshot0, "snapshot 0 mismatch")
+	assert.Equal(t, result[1], expectedSnapshot1, "snapshot 1 mismatch")
+}
+
+func TestGetSnapshots_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
+}
+
+func TestGetSnapshots_NonexistentVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
+
+	result, resultErr := driver.GetSnapshots(ctx, volConfig)
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_Snapshot(t *testing.T) {
+func TestGetSnapshots_GetSnapshotsFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "nfs"
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
-	sourceVolConfig.SnapshotDir = "false"
+	snapTime := time.Now()
+	volConfig, filesystem, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(cloneFilesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, cloneFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotsForVolume(ctx, filesystem).Return(nil, errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.GetSnapshots(ctx, volConfig)
 
-	assert.NoError(t, result, "create failed")
-	assert.Equal(t, cloneFilesystem.ID, cloneVolConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_ROClone(t *testing.T) {
+func TestCreateSnapshot(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "nfs"
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
-	sourceFilesystem.SnapshotDirectory = true
-	cloneVolConfig.ReadOnlyClone = true
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Nil(t, result, "create failed")
-	assert.NoError(t, result, "error occurred")
+	expectedSnapshot := &storage.Snapshot{
+		Config:    snapConfig,
+		Created:   snapTime.UTC().Format(utils.TimestampFormat),
+		SizeBytes: VolumeSizeI64,
+		State:     storage.SnapshotStateOnline,
+	}
+
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, expectedSnapshot, result)
 }
 
-func TestCreateClone_ROCloneFailed(t *testing.T) {
+func TestCreateSnapshot_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "nfs"
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
-	sourceVolConfig.SnapshotDir = "false"
-	cloneVolConfig.ReadOnlyClone = true
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.NotNil(t, result, "create succeeded")
-	assert.Error(t, result, "expected error")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_DiscoveryFailed(t *testing.T) {
+func TestCreateSnapshot_VolumeExistsCheckFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_InvalidVolumeName(t *testing.T) {
+func TestCreateSnapshot_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
-	cloneVolConfig.Name = "1testvol"
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_InvalidCreationToken(t *testing.T) {
+func TestCreateSnapshot_SnapshotCreateFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
-	cloneVolConfig.InternalName = "1testvol"
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_NonexistentSourceVolume(t *testing.T) {
+func TestCreateSnapshot_SnapshotWaitFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, _, _, _ := getStructsForCreateClone(ctx, driver, storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "expected error")
 }
 
-func TestCreateClone_VolumeExistsCheckFailed(t *testing.T) {
+func TestCreateSnapshot_BackupEnabled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
-		storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem.BackupEnabled = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().CreateBackup(ctx, filesystem, snapConfig.InternalName).Return(nil).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, resultErr, "not nil")
+	assert.NotNil(t, result, "snapshot is nil")
 }
 
-func TestCreateClone_VolumeExistsCreating(t *testing.T) {
+func TestCreateSnapshot_BackupFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
-		storagePool)
-	cloneFilesystem.ProvisioningState = api.StateCreating
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem.BackupEnabled = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(true, cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().CreateBackup(ctx, filesystem, snapConfig.InternalName).Return(errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.IsType(t,
-		errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.ErrorContains(t, resultErr, "could not be backed up", "expected error")
 }
 
-func TestCreateClone_VolumeExists(t *testing.T) {
+func TestRestoreSnapshot(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable,
+		[]string{api.StateError, api.StateDeleting, api.StateDeleted}, api.DefaultSDKTimeout).
+		Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+
+	assert.Nil(t, result, "not nil")
+}
+
+func TestRestoreSnapshot_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
-		storagePool)
-	cloneFilesystem.ProvisioningState = api.StateAvailable
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(true, cloneFilesystem, nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.IsType(t, drivers.NewVolumeExistsError(""), result, "not VolumeExistsError")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_SnapshotNotFound(t *testing.T) {
+func TestRestoreSnapshot_VolumeExistsCheckFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
-		storagePool)
-	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_SnapshotNotAvailable(t *testing.T) {
+func TestRestoreSnapshot_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	cloneVolConfig.CloneSourceSnapshotInternal = "snap1"
-	snapshot.ProvisioningState = api.StateError
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_SnapshotCreateFailed(t *testing.T) {
+func TestRestoreSnapshot_NonexistentSnapshot(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, _ := getStructsForCreateClone(ctx, driver,
-		storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_SnapshotWaitFailed(t *testing.T) {
+func TestRestoreSnapshot_GetSnapshotFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_SnapshotRefetchFailed(t *testing.T) {
+func TestRestoreSnapshot_SnapshotRestoreFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
-
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_InvalidLabel(t *testing.T) {
+func TestRestoreSnapshot_VolumeWaitFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.Labels = map[string]string{
-		"key1": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-		"key2": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-		"key3": "1234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890",
-	}
-
-	storagePool := driver.pools["anf_pool"]
 
-	sourceVolConfig, cloneVolConfig, _, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable,
+		[]string{api.StateError, api.StateDeleting, api.StateDeleted},
+		api.DefaultSDKTimeout).Return("", errFailed).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestCreateClone_CreateFailed(t *testing.T) {
+func TestRestoreSnapshot_VolumeWaitCancelled(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-	driver.Config.NASType = "nfs"
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePool := driver.pools["anf_pool"]
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	sourceVolConfig, cloneVolConfig, createRequest, sourceFilesystem, cloneFilesystem, snapshot := getStructsForCreateClone(ctx,
-		driver, storagePool)
-	sourceVolConfig.SnapshotDir = "false"
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, sourceVolConfig).Return(sourceFilesystem, nil).Times(1)
-	mockAPI.EXPECT().VolumeExistsByID(ctx, cloneFilesystem.ID).Return(false, nil, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, sourceFilesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, sourceFilesystem, gomock.Any()).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(cancelCtx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(cancelCtx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(cancelCtx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().RestoreSnapshot(cancelCtx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(cancelCtx, filesystem, api.StateAvailable,
+		[]string{api.StateError, api.StateDeleting, api.StateDeleted},
+		api.DefaultSDKTimeout).DoAndReturn(
+		func(_ context.Context, _ *api.FileSystem, _ string, _ []string, _ time.Duration) (string, error) {
+			cancel()
+			return api.StateReverting, context.Canceled
+		}).Times(1)
 
-	result := driver.CreateClone(ctx, sourceVolConfig, cloneVolConfig, nil)
+	result := driver.RestoreSnapshot(cancelCtx, snapConfig, volConfig)
 
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", cloneVolConfig.InternalID, "internal ID set on volConfig")
+	assert.True(t, errors.IsTimeoutError(result), "not TimeoutError")
 }
 
-func getStructsForImport(ctx context.Context, driver *NASStorageDriver) (*storage.VolumeConfig, *api.FileSystem) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-	}
-
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+func TestDeleteSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	apiExportRule := api.ExportRule{
-		AllowedClients:      defaultExportRule,
-		Cifs:                false,
-		Nfsv3:               false,
-		Nfsv41:              false,
-		RuleIndex:           1,
-		UnixReadOnly:        false,
-		UnixReadWrite:       false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5PReadWrite: false,
-		Kerberos5IReadWrite: false,
-	}
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateDeleted, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
 
-	originalFilesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "importMe",
-		FullName:          "RG1/NA1/CP1/importMe",
-		Location:          Location,
-		Labels:            make(map[string]string),
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "importMe",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
-		ExportPolicy:      api.ExportPolicy{Rules: []api.ExportRule{apiExportRule}},
-	}
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	return volConfig, originalFilesystem
+	assert.Nil(t, result, "not nil")
 }
 
-func getStructsForSMBImport(ctx context.Context, driver *NASStorageDriver) (*storage.VolumeConfig, *api.FileSystem) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-	}
+func TestDeleteSnapshot_BackupEnabled(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem.BackupEnabled = true
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().DeleteBackup(ctx, filesystem, snapConfig.InternalName).Return(nil).Times(1)
+	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateDeleted, []string{api.StateError},
+		api.SnapshotTimeout).Return(nil).Times(1)
 
-	originalFilesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "importMe",
-		FullName:          "RG1/NA1/CP1/importMe",
-		Location:          Location,
-		Labels:            make(map[string]string),
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "importMe",
-		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-	}
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	return volConfig, originalFilesystem
+	assert.Nil(t, result, "not nil")
 }
 
-func TestImport_Managed(t *testing.T) {
+func TestDeleteSnapshot_BackupDeleteFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem.BackupEnabled = true
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().DeleteBackup(ctx, filesystem, snapConfig.InternalName).Return(errFailed).Times(1)
+
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDeleteSnapshot_DiscoveryFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDeleteSnapshot_VolumeExistsCheckFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.NotNil(t, result, "expected error")
 }
 
-func TestImport_ManagedWithKerberos5(t *testing.T) {
-	defer acp.SetAPI(acp.API())
-
-	mockCtrl := gomock.NewController(t)
+func TestDeleteSnapshot_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	snapTime := time.Now()
+	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5"
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	exportRule := api.ExportRule{
-		Nfsv41:              true,
-		Kerberos5ReadWrite:  true,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
+	assert.Nil(t, result, "not nil")
+}
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = true
-	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
-	originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite = true
+func TestDeleteSnapshot_NonexistentSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
 
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	assert.Nil(t, result, "not nil")
+}
 
-	result := driver.Import(ctx, volConfig, originalName)
+func TestDeleteSnapshot_GetSnapshotFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite, "kerberos protocol type mismatch")
-}
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-func TestImport_ManagedWithKerberos5I(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
 
-	mockCtrl := gomock.NewController(t)
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDeleteSnapshot_SnapshotDeleteFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(errFailed).Times(1)
+
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func TestDeleteSnapshot_SnapshotWaitFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5i"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	snapTime := time.Now()
+	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
 
-	exportRule := api.ExportRule{
-		Nfsv41:              true,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: true,
-		Kerberos5PReadWrite: false,
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateDeleted, []string{api.StateError},
+		api.SnapshotTimeout).Return(errFailed).Times(1)
+
+	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+
+	assert.NotNil(t, result, "expected error")
+}
+
+func getVolumesForList() *[]*api.FileSystem {
+	return &[]*api.FileSystem{
+		{
+			ProvisioningState: api.StateAvailable,
+			CreationToken:     "myPrefix-testvol1",
+		},
+		{
+			ProvisioningState: api.StateAvailable,
+			CreationToken:     "myPrefix-testvol2",
+		},
+		{
+			ProvisioningState: api.StateDeleting,
+			CreationToken:     "myPrefix-testvol3",
+		},
+		{
+			ProvisioningState: api.StateDeleted,
+			CreationToken:     "myPrefix-testvol4",
+		},
+		{
+			ProvisioningState: api.StateError,
+			CreationToken:     "myPrefix-testvol5",
+		},
+		{
+			ProvisioningState: api.StateAvailable,
+			CreationToken:     "testvol6",
+		},
 	}
+}
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = true
-	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
-	originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
-
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+func TestList(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
+	volumes := getVolumesForList()
 
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite, "kerberos protocol type mismatch")
+	assert.Nil(t, result, "expected no error")
+	assert.Equal(t, list, []string{"testvol1", "testvol2"}, "list not nil")
 }
 
-func TestImport_ManagedWithKerberos5P(t *testing.T) {
-	defer acp.SetAPI(acp.API())
+func getVolumesForFailedVolumeCleanup(driver *NASStorageDriver) *[]*api.FileSystem {
+	otherBackend := &NASStorageDriver{}
+	otherBackend.initializeTelemetry(ctx, "other-backend-uuid")
 
-	mockCtrl := gomock.NewController(t)
-	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	ourLabels := map[string]string{drivers.TridentLabelTag: driver.getTelemetryLabels(ctx)}
+	otherLabels := map[string]string{drivers.TridentLabelTag: otherBackend.getTelemetryLabels(ctx)}
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	return &[]*api.FileSystem{
+		{
+			FullName: "RG1/NA1/CP1/old", CreationToken: "myPrefix-old",
+			Labels: ourLabels, ProvisioningState: api.StateError,
+		},
+		{
+			FullName: "RG1/NA1/CP1/new", CreationToken: "myPrefix-new",
+			Labels: ourLabels, ProvisioningState: api.StateError,
+		},
+		{
+			FullName: "RG1/NA1/CP1/ok", CreationToken: "myPrefix-ok",
+			Labels: ourLabels, ProvisioningState: api.StateAvailable,
+		},
+		{
+			FullName: "RG1/NA1/CP1/other", CreationToken: "myPrefix-other",
+			Labels: otherLabels, ProvisioningState: api.StateError,
+		},
+		{
+			FullName: "RG1/NA1/CP1/unlabeled", CreationToken: "myPrefix-unlabeled",
+			ProvisioningState: api.StateError,
+		},
+		{
+			FullName: "RG1/NA1/CP1/unscoped", CreationToken: "other-old",
+			Labels: ourLabels, ProvisioningState: api.StateError,
+		},
+	}
+}
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
+func TestList_FailedVolumeCleanup(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5p"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+	driver.Config.FailedVolumeCleanup = FailedVolumeCleanupDelete
+	driver.failedVolumeCleanupAge = time.Hour
 
-	exportRule := api.ExportRule{
-		Nfsv41:              true,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: true,
+	longAgo := time.Now().Add(-2 * time.Hour)
+	driver.failedVolumes = map[string]time.Time{
+		"RG1/NA1/CP1/old":       longAgo,
+		"RG1/NA1/CP1/ok":        longAgo,
+		"RG1/NA1/CP1/other":     longAgo,
+		"RG1/NA1/CP1/unlabeled": longAgo,
+		"RG1/NA1/CP1/unscoped":  longAgo,
 	}
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = true
-	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
-	originalFilesystem.ExportPolicy.Rules[0].Kerberos5PReadWrite = true
-
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+	volumes := getVolumesForFailedVolumeCleanup(driver)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).Times(1)
-
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, (*volumes)[0]).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.True(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5PReadWrite, "kerberos protocol type mismatch")
+	assert.NoError(t, result, "expected no error")
+	assert.Equal(t, []string{"ok"}, list, "list mismatch")
+	assert.Len(t, driver.failedVolumes, 1, "failed volumes mismatch")
+	assert.Contains(t, driver.failedVolumes, "RG1/NA1/CP1/new", "recently failed volume not tracked")
 }
 
-func TestImport_ManagedWithKerberos_IncorrectProtocolType(t *testing.T) {
-	defer acp.SetAPI(acp.API())
-
-	mockCtrl := gomock.NewController(t)
+func TestList_FailedVolumeCleanupDryRun(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+	driver.Config.FailedVolumeCleanup = FailedVolumeCleanupDryRun
+	driver.failedVolumeCleanupAge = time.Hour
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5"
+	longAgo := time.Now().Add(-2 * time.Hour)
+	driver.failedVolumes = map[string]time.Time{"RG1/NA1/CP1/old": longAgo}
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	volumes := getVolumesForFailedVolumeCleanup(driver)
 
-	exportRule := api.ExportRule{
-		Nfsv41:              true,
-		Kerberos5ReadWrite:  true,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Times(0)
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = true
-	originalFilesystem.ExportPolicy.Rules[0].Nfsv41 = true
-	originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite = false
-	originalFilesystem.ExportPolicy.Rules[0].Kerberos5IReadWrite = true
+	list, result := driver.List(ctx)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	assert.NoError(t, result, "expected no error")
+	assert.Equal(t, []string{"ok"}, list, "list mismatch")
+	assert.Equal(t, longAgo, driver.failedVolumes["RG1/NA1/CP1/old"], "failed time not kept")
+	assert.Contains(t, driver.failedVolumes, "RG1/NA1/CP1/new", "recently failed volume not tracked")
+}
+
+func TestList_FailedVolumeCleanupAge(t *testing.T) {
+	tests := []struct {
+		name      string
+		failedFor time.Duration
+		deleted   bool
+	}{
+		{"FirstSeen", 0, false},
+		{"YoungerThanAge", 30 * time.Minute, false},
+		{"OlderThanAge", 90 * time.Minute, true},
 	}
-	expectedUnixPermissions := "0770"
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.initializeTelemetry(ctx, BackendUUID)
+
+			storagePrefix := "myPrefix-"
+			driver.Config.StoragePrefix = &storagePrefix
+			driver.Config.FailedVolumeCleanup = FailedVolumeCleanupDelete
+			driver.failedVolumeCleanupAge = time.Hour
+			if test.failedFor > 0 {
+				driver.failedVolumes = map[string]time.Time{"RG1/NA1/CP1/old": time.Now().Add(-test.failedFor)}
+			}
 
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+			volumes := &[]*api.FileSystem{(*getVolumesForFailedVolumeCleanup(driver))[0]}
 
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels, &expectedUnixPermissions, &snapshotDirAccess,
-		&exportRule).Return(errors.New("Could not import volume, volume modify failed.")).Times(1)
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+			if test.deleted {
+				mockAPI.EXPECT().DeleteVolume(ctx, (*volumes)[0]).Return(nil).Times(1)
+			} else {
+				mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Times(0)
+			}
 
-	result := driver.Import(ctx, volConfig, originalName)
+			_, result := driver.List(ctx)
 
-	assert.Error(t, result, "import succeeded")
-	assert.False(t, originalFilesystem.ExportPolicy.Rules[0].Kerberos5ReadWrite, "kerberos protocol type mismatch")
+			assert.NoError(t, result, "expected no error")
+			assert.Equal(t, !test.deleted, driver.failedVolumes["RG1/NA1/CP1/old"] != time.Time{},
+				"failed volume tracking mismatch")
+		})
+	}
 }
 
-func TestImport_ManagedWithKerberosDisabled(t *testing.T) {
-	defer acp.SetAPI(acp.API())
-
-	mockCtrl := gomock.NewController(t)
+func TestList_FailedVolumeCleanupDeleteFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	mockACP := mockacp.NewMockTridentACP(mockCtrl)
-	acp.SetAPI(mockACP)
-
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5"
 
-	originalName := "importMe"
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+	driver.Config.FailedVolumeCleanup = FailedVolumeCleanupDelete
+	driver.failedVolumeCleanupAge = time.Hour
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = false
+	longAgo := time.Now().Add(-2 * time.Hour)
+	driver.failedVolumes = map[string]time.Time{"RG1/NA1/CP1/old": longAgo}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	volumes := &[]*api.FileSystem{(*getVolumesForFailedVolumeCleanup(driver))[0]}
 
-	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureInflightEncryption).Return(nil).AnyTimes()
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, (*volumes)[0]).Return(errFailed).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.Error(t, result, "import succeeded")
-	assert.False(t, originalFilesystem.KerberosEnabled, "kerberosEnabled flag is set")
+	assert.NoError(t, result, "expected no error")
+	assert.Empty(t, list, "list not empty")
+	assert.Equal(t, longAgo, driver.failedVolumes["RG1/NA1/CP1/old"], "failed volume not kept")
 }
 
-func TestImport_ManagedWithKerberosNotSetInConfig(t *testing.T) {
+func TestList_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-
-	originalName := "importMe"
-
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.KerberosEnabled = true
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Times(0)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.Error(t, result, "import succeeded")
-	assert.True(t, originalFilesystem.KerberosEnabled, "kerberosEnabled flag is set")
+	assert.Error(t, result, "expected error")
+	assert.Nil(t, list, "list not nil")
 }
 
-func TestImport_ManagedWithSnapshotDir(t *testing.T) {
+func TestList_ListFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-
-	originalName := "importMe"
 
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(nil, errFailed).Times(1)
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	list, result := driver.List(ctx)
 
-	volConfig.SnapshotDir = "true"
-	snapshotDirAccess := true
+	assert.Error(t, result, "expected error")
+	assert.Nil(t, list, "list not nil")
+}
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+func TestList_ListNone(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(&[]*api.FileSystem{}, nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "expected nil")
+	assert.Equal(t, []string{}, list, "list not empty")
 }
 
-func TestImport_ManagedWithSnapshotDirFalse(t *testing.T) {
+func TestGet(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
+	filesystem := &api.FileSystem{}
 
-	originalName := "importMe"
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Return(filesystem, nil).Times(1)
 
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
+	result := driver.Get(ctx, "volume1")
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	assert.NoError(t, result, "expected no error")
+}
 
-	volConfig.SnapshotDir = "false"
-	snapshotDirAccess := false
+func TestGet_BackgroundCacheRefresh(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackgroundCacheRefresh = true
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
-	expectedUnixPermissions := "0770"
+	filesystem := &api.FileSystem{}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{LastUpdateTime: time.Now()}).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Times(0)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Return(filesystem, nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Get(ctx, "volume1")
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.NoError(t, result, "expected no error")
 }
 
-func TestImport_ManagedWithInvalidSnapshotDirValue(t *testing.T) {
+func TestGet_BackgroundCacheRefresh_CacheNotPopulated(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.BackgroundCacheRefresh = true
 
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{Stale: true}).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Times(0)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Times(0)
 
-	originalName := "importMe"
+	result := driver.Get(ctx, "volume1")
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	assert.Error(t, result, "expected error")
+}
 
-	volConfig.SnapshotDir = "xxxffa"
+func TestList_BackgroundCacheRefresh(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackgroundCacheRefresh = true
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{LastUpdateTime: time.Now()}).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Times(0)
+	mockAPI.EXPECT().Volumes(ctx).Return(&[]*api.FileSystem{}, nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	list, result := driver.List(ctx)
 
-	assert.Error(t, result, "import succeeded")
-	assert.NotNil(t, result, "received nil")
+	assert.Nil(t, result, "expected nil")
+	assert.Equal(t, []string{}, list, "list not empty")
 }
 
-func TestImport_SMB_Managed(t *testing.T) {
+func TestGet_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "smb"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Times(0)
 
-	exportRule := api.ExportRule{}
+	result := driver.Get(ctx, "volume1")
 
-	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+	assert.Error(t, result, "expected error")
+}
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
+func TestGet_NotFound(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		nil, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Return(nil, errFailed).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Get(ctx, "volume1")
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Error(t, result, "expected error")
 }
 
-func TestImport_SMB_Failed(t *testing.T) {
+func TestResize(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "smb"
-	driver.Config.UnixPermissions = "0770"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.InternalID = ""
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	exportRule := api.ExportRule{}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
-	}
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+}
+
+func TestResize_SnapshotReserve(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.Labels = map[string]string{SnapshotReserveTag: "20"}
+	filesystem.QuotaInBytes = VolumeSizeI64 * 100 / 80
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		nil, &snapshotDirAccess, &exportRule).Return(errors.New("unix permissions not applicable for SMB")).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)*100/80).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Error(t, result, "import failed")
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
 }
 
-func TestImport_DualProtocolVolume(t *testing.T) {
+func TestResize_SnapshotReserve_SameSize(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	originalName := "importMe"
-
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-
-	// Dual-protocol volume has ProtocolTypes as [NFSv3, CIFS]
-	originalFilesystem.ProtocolTypes = append(originalFilesystem.ProtocolTypes, "CIFS")
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.Labels = map[string]string{SnapshotReserveTag: "20"}
+	filesystem.QuotaInBytes = VolumeSizeI64 * 100 / 80
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, uint64(VolumeSizeI64))
 
-	assert.Error(t, result, "import failed")
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
 }
 
-func TestImport_ManagedWithLabels(t *testing.T) {
+func TestResize_ServiceLevelChange(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = ""
-	driver.Config.NASType = "nfs"
-
-	originalName := "importMe"
-	var snapshotDirAccess bool
-
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	originalFilesystem.UnixPermissions = "0700"
-	originalFilesystem.Labels = map[string]string{
-		storage.ProvisioningLabelTag: `{"provisioning":{"cloud":"anf","clusterName":"dev-test-cluster-1"}}`,
-	}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = api.ServiceLevelPremium
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	expectedLabels := map[string]string{
-		storage.ProvisioningLabelTag: "",
-		drivers.TridentLabelTag:      driver.getTelemetryLabels(ctx),
+	cPools := []*api.CapacityPool{
+		{Name: "CP2", ResourceGroup: "RG2", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelPremium},
+		{Name: "CP3", ResourceGroup: "RG1", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelStandard},
+		{Name: "CP4", ResourceGroup: "RG1", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelPremium},
 	}
-	expectedUnixPermissions := "0700"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(cPools).Times(1)
+	mockAPI.EXPECT().RelocateVolume(ctx, filesystem, cPools[2]).DoAndReturn(
+		func(_ context.Context, fs *api.FileSystem, cPool *api.CapacityPool) error {
+			fs.CapacityPool = cPool.Name
+			fs.ServiceLevel = cPool.ServiceLevel
+			fs.ID = "newID"
+			return nil
+		}).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
 		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, "newID", volConfig.InternalID, "internal ID not updated on volConfig")
+	assert.Equal(t, "CP4", filesystem.CapacityPool, "capacity pool mismatch")
+	assert.Equal(t, "RG1/NA1/CP4", volConfig.CapacityPool, "capacity pool not updated on volConfig")
+	assert.Equal(t, api.ServiceLevelPremium, volConfig.ServiceLevel, "service level mismatch")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
 }
 
-func TestImport_NotManaged(t *testing.T) {
+func TestResize_ServiceLevelUnchanged(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
 
-	originalName := "importMe"
-
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
-	volConfig.ImportNotManaged = true
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = strings.ToLower(filesystem.ServiceLevel)
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Times(0)
+	mockAPI.EXPECT().RelocateVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NoError(t, result, "import failed")
-	assert.Equal(t, originalName, volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, originalFilesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID changed")
 }
 
-func TestImport_DiscoveryFailed(t *testing.T) {
+func TestResize_SnapshotDirChange(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-
-	originalName := "importMe"
+	driver.Config.SnapshotDir = "true"
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
 
-	volConfig, _ := getStructsForImport(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.SnapshotDir = "false"
+	filesystem.SnapshotDirectory = false
+	newSize := uint64(VolumeSizeI64 * 2)
+	snapshotDirAccess := true
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, filesystem, nil, nil, &snapshotDirAccess, nil).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+	assert.Nil(t, result, "not nil")
+	assert.True(t, filesystem.SnapshotDirectory, "snapshot directory not visible")
+	assert.Equal(t, "true", volConfig.SnapshotDir, "snapshotDir mismatch")
 }
 
-func TestImport_NotFound(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
+func TestResize_SnapshotDirUnchanged(t *testing.T) {
+	tests := []struct {
+		name       string
+		backend    string
+		vpool      string
+		volConfig  string
+		volume     bool
+		notManaged bool
+	}{
+		{"unset", "", "", "true", true, false},
+		{"default", "", "", "", true, false},
+		{"visible", "true", "", "", true, false},
+		{"hidden", "false", "", "", false, false},
+		{"unmanaged", "true", "", "", false, true},
+		{"virtualPoolOverride", "false", "true", "true", true, false},
+		{"createdWithSetting", "true", "", "true", false, false},
+	}
 
-	originalName := "importMe"
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.initializeTelemetry(ctx, BackendUUID)
+			driver.Config.SnapshotDir = test.backend
+			if test.vpool != "" {
+				driver.Config.Storage = []drivers.AzureNASStorageDriverPool{{SnapshotDir: test.vpool}}
+			}
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
 
-	volConfig, _ := getStructsForImport(ctx, driver)
+			volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+			volConfig.SnapshotDir = test.volConfig
+			volConfig.ImportNotManaged = test.notManaged
+			filesystem.SnapshotDirectory = test.volume
+			newSize := uint64(VolumeSizeI64 * 2)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(nil, errFailed).Times(1)
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+			mockAPI.EXPECT().ModifyVolume(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any()).Times(0)
+			mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	result := driver.Import(ctx, volConfig, originalName)
+			result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
+			assert.Nil(t, result, "not nil")
+			assert.Equal(t, test.volume, filesystem.SnapshotDirectory, "snapshot directory changed")
+		})
+	}
 }
 
-func TestImport_InvalidCapacityPool(t *testing.T) {
+func TestResize_SnapshotDirChangeFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-
-	originalName := "importMe"
+	driver.Config.SnapshotDir = "false"
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.SnapshotDirectory = true
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolume(ctx, filesystem, nil, nil, gomock.Any(), nil).Return(errFailed).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
 }
 
-func TestImport_InvalidUnixPermissions(t *testing.T) {
+func TestResize_ServiceLevelChange_NotAvailable(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "8888"
-	driver.Config.NASType = "nfs"
-
-	originalName := "importMe"
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = api.ServiceLevelPremium
+	filesystem.ProvisioningState = api.StateMoving
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().RelocateVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
 }
 
-func TestImport_ModifyVolumeFailed(t *testing.T) {
+func TestResize_ServiceLevelChange_NoCapacityPool(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
-
-	originalName := "importMe"
-	var snapshotDirAccess bool
-
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
 
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = api.ServiceLevelPremium
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	cPools := []*api.CapacityPool{
+		{Name: "CP1", ResourceGroup: "RG1", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelUltra},
+		{Name: "CP2", ResourceGroup: "RG1", NetAppAccount: "NA2", ServiceLevel: api.ServiceLevelPremium},
 	}
-	expectedUnixPermissions := "0770"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(cPools).Times(1)
+	mockAPI.EXPECT().RelocateVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
 }
 
-func TestImport_VolumeWaitFailed(t *testing.T) {
+func TestResize_ServiceLevelChange_RelocateFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.UnixPermissions = "0770"
-	driver.Config.NASType = "nfs"
 
-	originalName := "importMe"
-	var snapshotDirAccess bool
-
-	exportRule := api.ExportRule{
-		Nfsv41:              false,
-		Kerberos5ReadWrite:  false,
-		Kerberos5IReadWrite: false,
-		Kerberos5PReadWrite: false,
-	}
-
-	volConfig, originalFilesystem := getStructsForImport(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = api.ServiceLevelPremium
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	expectedLabels := map[string]string{
-		drivers.TridentLabelTag: driver.getTelemetryLabels(ctx),
+	cPools := []*api.CapacityPool{
+		{Name: "CP2", ResourceGroup: "RG1", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelPremium},
 	}
-	expectedUnixPermissions := "0770"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().ModifyVolume(ctx, originalFilesystem, expectedLabels,
-		&expectedUnixPermissions, &snapshotDirAccess, &exportRule).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, originalFilesystem, api.StateAvailable, []string{api.StateError},
-		driver.defaultTimeout()).Return("", errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(cPools).Times(1)
+	mockAPI.EXPECT().RelocateVolume(ctx, filesystem, cPools[0]).Return(errFailed).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Import(ctx, volConfig, originalName)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "trident-testvol1", volConfig.InternalName, "internal name mismatch")
-	assert.Equal(t, "", volConfig.InternalID, "internal ID set on volConfig")
 }
 
-func TestImport_BackendVolumeMismatch(t *testing.T) {
+func TestResize_ScaleThroughput(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
-
-	driver.populateConfigurationDefaults(ctx, &driver.Config)
-	driver.initializeStoragePools(ctx)
+	driver.Config.ScaleThroughputOnResize = true
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	originalName := "importMe"
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ThroughputMibps = 50
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	volConfig, originalFilesystem := getStructsForSMBImport(ctx, driver)
+	cPools := []*api.CapacityPool{
+		{
+			Name: filesystem.CapacityPool, ResourceGroup: filesystem.ResourceGroup,
+			NetAppAccount: filesystem.NetAppAccount, QosType: api.QosTypeManual,
+		},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, originalName).Return(originalFilesystem, nil).Times(1)
-	mockAPI.EXPECT().EnsureVolumeInValidCapacityPool(ctx, originalFilesystem).Return(nil).Times(1)
-
-	result := driver.Import(ctx, volConfig, originalName)
-
-	assert.Error(t, result, "import should fail")
-}
-
-func TestRename(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPools().Return(&cPools).Times(1)
+	mockAPI.EXPECT().ModifyVolumeThroughput(ctx, filesystem, float32(100)).Return(nil).Times(1)
 
-	result := driver.Rename(ctx, "oldName", "newName")
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Nil(t, result, "not nil")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
 }
 
-func TestGetTelemetryLabels(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+func TestResize_ScaleThroughput_AutoQoS(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ScaleThroughputOnResize = true
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	result := driver.getTelemetryLabels(ctx)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ThroughputMibps = 50
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	assert.True(t, strings.HasPrefix(result, `{"trident":{`))
-}
+	cPools := []*api.CapacityPool{
+		{
+			Name: filesystem.CapacityPool, ResourceGroup: filesystem.ResourceGroup,
+			NetAppAccount: filesystem.NetAppAccount, QosType: api.QosTypeAuto,
+		},
+	}
 
-func TestUpdateTelemetryLabels(t *testing.T) {
-	_, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPools().Return(&cPools).Times(1)
 
-	result := driver.updateTelemetryLabels(ctx, &api.FileSystem{})
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NotNil(t, result)
-	assert.True(t, strings.HasPrefix(result[drivers.TridentLabelTag], `{"trident":{`))
+	assert.Nil(t, result, "not nil")
 }
 
-func TestWaitForVolumeCreate_Available(t *testing.T) {
+func TestResize_ScaleThroughput_ModifyFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ScaleThroughputOnResize = true
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "netapp-testvol1",
-		ProvisioningState: api.StateCreating,
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ThroughputMibps = 50
+	newSize := uint64(VolumeSizeI64 * 2)
+
+	cPools := []*api.CapacityPool{
+		{
+			Name: filesystem.CapacityPool, ResourceGroup: filesystem.ResourceGroup,
+			NetAppAccount: filesystem.NetAppAccount, QosType: api.QosTypeManual,
+		},
 	}
 
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
+	mockAPI.EXPECT().CapacityPools().Return(&cPools).Times(1)
+	mockAPI.EXPECT().ModifyVolumeThroughput(ctx, filesystem, float32(100)).Return(errFailed).Times(1)
 
-	result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result)
+	assert.Error(t, result, "expected error")
 }
 
-func TestWaitForVolumeCreate_Creating(t *testing.T) {
-	for _, state := range []string{api.StateAccepted, api.StateCreating} {
+func TestResize_ServiceLevelChange_WaitFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-		mockAPI, driver := newMockANFDriver(t)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig.ServiceLevel = api.ServiceLevelPremium
+	newSize := uint64(VolumeSizeI64 * 2)
 
-		filesystem := &api.FileSystem{
-			Name:              "testvol1",
-			CreationToken:     "netapp-testvol1",
-			ProvisioningState: api.StateCreating,
-		}
+	cPools := []*api.CapacityPool{
+		{Name: "CP2", ResourceGroup: "RG1", NetAppAccount: "NA1", ServiceLevel: api.ServiceLevelPremium},
+	}
 
-		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-			driver.volumeCreateTimeout).Return(state, errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(cPools).Times(1)
+	mockAPI.EXPECT().RelocateVolume(ctx, filesystem, cPools[0]).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateMoving, errFailed).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-		result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-		assert.Error(t, result, "expected error")
-		assert.IsType(t, errors.VolumeCreatingError(""), result, "not VolumeCreatingError")
-	}
+	assert.Error(t, result, "expected error")
 }
 
-func TestWaitForVolumeCreate_DeletingDeleteFinished(t *testing.T) {
+func TestResize_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "netapp-testvol1",
-		ProvisioningState: api.StateCreating,
-	}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestWaitForVolumeCreate_DeletingDeleteNotFinished(t *testing.T) {
+func TestResize_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "netapp-testvol1",
-		ProvisioningState: api.StateCreating,
-	}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateDeleting, errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestWaitForVolumeCreate_ErrorDelete(t *testing.T) {
+func TestResize_VolumeNotAvailable(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "netapp-testvol1",
-		ProvisioningState: api.StateCreating,
-	}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ProvisioningState = api.StateError
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateError, errFailed).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestWaitForVolumeCreate_ErrorDeleteFailed(t *testing.T) {
+func TestResize_NoSizeChange(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "netapp-testvol1",
-		ProvisioningState: api.StateCreating,
-	}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := uint64(VolumeSizeI64)
 
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateError, errFailed).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Nil(t, result, "not nil")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestWaitForVolumeCreate_OtherStates(t *testing.T) {
-	for _, state := range []string{api.StateMoving, "unknown"} {
-
-		mockAPI, driver := newMockANFDriver(t)
+func TestResize_ShrinkingVolume(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-		filesystem := &api.FileSystem{
-			Name:              "testvol1",
-			CreationToken:     "netapp-testvol1",
-			ProvisioningState: api.StateCreating,
-		}
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := uint64(VolumeSizeI64 / 2)
 
-		mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
-			driver.volumeCreateTimeout).Return(state, errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-		result := driver.waitForVolumeCreate(ctx, filesystem)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-		assert.Nil(t, result, "not nil")
-	}
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func getStructsForDestroyNFSVolume(
-	ctx context.Context, driver *NASStorageDriver,
-) (*storage.VolumeConfig, *api.FileSystem) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+func TestResize_ShrinkingVolume_Allowed(t *testing.T) {
+	defer acp.SetAPI(acp.API())
 
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   volumeID,
-	}
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.QuotaInBytes = VolumeSizeI64 * 4
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	apiExportRule := api.ExportRule{
-		AllowedClients: defaultExportRule,
-		Cifs:           false,
-		Nfsv3:          true,
-		Nfsv41:         false,
-		RuleIndex:      1,
-		UnixReadOnly:   false,
-		UnixReadWrite:  true,
-	}
-	exportPolicy := api.ExportPolicy{
-		Rules: []api.ExportRule{apiExportRule},
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(VolumeSizeI64, int64(0), nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		ExportPolicy:      exportPolicy,
-		Labels:            make(map[string]string),
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
-		NetworkFeatures:   api.NetworkFeaturesStandard,
-	}
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	return volConfig, filesystem
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
 }
 
-func getStructsForDestroySMBVolume(
-	ctx context.Context, driver *NASStorageDriver,
-) (*storage.VolumeConfig, *api.FileSystem) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+func TestResize_ShrinkingVolume_ACPDisabled(t *testing.T) {
+	defer acp.SetAPI(acp.API())
 
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   volumeID,
-	}
+	mockCtrl := gomock.NewController(t)
+	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.QuotaInBytes = VolumeSizeI64 * 4
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		Labels:            make(map[string]string),
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-		NetworkFeatures:   api.NetworkFeaturesStandard,
-	}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(errFailed).Times(1)
 
-	return volConfig, filesystem
+	result := driver.Resize(ctx, volConfig, newSize)
+
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64*4, 10), volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_NFSVolume(t *testing.T) {
+func TestResize_ShrinkingVolume_BelowMinimum(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
 	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
 	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := MinimumANFVolumeSizeBytes - 1
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(nil).Times(1)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result, "not nil")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_DiscoveryFailed(t *testing.T) {
+func TestResize_ShrinkingVolume_BelowUsedBytes(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
 	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
-	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.QuotaInBytes = VolumeSizeI64 * 4
+	newSize := uint64(VolumeSizeI64 * 2)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(VolumeSizeI64*3, int64(0), nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64*4, 10), volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_VolumeExistsCheckFailed(t *testing.T) {
+func TestResize_ShrinkingVolume_UsedBytesUnknown(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
 	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
-	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.QuotaInBytes = VolumeSizeI64 * 4
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), errFailed).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64*4, 10), volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_AlreadyDeleted(t *testing.T) {
+func TestResize_AboveMaximumSize(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	driver.Config.LimitVolumeSize = strconv.FormatInt(VolumeSizeI64+1, 10)
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestDestroy_StillDeletingDeleted(t *testing.T) {
+func TestResize_LargeVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
 	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	filesystem.ProvisioningState = api.StateDeleting
+	filesystem.QuotaInBytes = int64(MaximumANFVolumeSizeBytes)
+	filesystem.IsLargeVolume = true
+	newSize := MaximumANFVolumeSizeBytes * 2
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateDeleted, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.Nil(t, result, "not nil")
+	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_StillDeleting(t *testing.T) {
+func TestResize_LargeVolume_BelowMinimum(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+
+	mockCtrl := gomock.NewController(t)
 	mockAPI, driver := newMockANFDriver(t)
+	mockACP := mockacp.NewMockTridentACP(mockCtrl)
+	acp.SetAPI(mockACP)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AllowVolumeShrink = true
 
 	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	filesystem.ProvisioningState = api.StateDeleting
+	filesystem.QuotaInBytes = int64(MaximumANFVolumeSizeBytes)
+	filesystem.IsLargeVolume = true
+	newSize := MinimumANFLargeVolumeSizeBytes - 1
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.volumeCreateTimeout).Return(api.StateDeleting, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureVolumeShrink).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(VolumeSizeI64, int64(0), nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, strconv.FormatUint(MaximumANFVolumeSizeBytes, 10), volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_DeleteFailed(t *testing.T) {
+func TestResize_RegularVolume_AboveMaximum(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
 	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := MaximumANFVolumeSizeBytes + 1
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
 }
 
-func TestDestroy_VolumeWaitFailed(t *testing.T) {
+func TestResize_VolumeResizeFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
 	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	newSize := uint64(VolumeSizeI64 * 2)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateDeleting, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(errFailed).Times(1)
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.Resize(ctx, volConfig, newSize)
 
 	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
+	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
 }
 
-func TestDestroy_SMBVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetStorageBackendSpecs(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 
-	volConfig, filesystem := getStructsForDestroySMBVolume(ctx, driver)
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
-		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
+	backend := &storage.StorageBackend{}
+	backend.SetStorage(make(map[string]storage.Pool))
 
-	result := driver.Destroy(ctx, volConfig)
+	result := driver.GetStorageBackendSpecs(ctx, backend)
 
 	assert.Nil(t, result, "not nil")
+	assert.Equal(t, "azurenetappfiles_1-cli", backend.Name(), "backend name mismatch")
+	for _, pool := range driver.pools {
+		assert.Equal(t, backend, pool.Backend(), "pool-backend mismatch")
+		assert.Equal(t, pool, backend.Storage()["azurenetappfiles_1-cli_pool"], "backend-pool mismatch")
+	}
 }
 
-func getStructsForPublishNFSVolume(
-	ctx context.Context, driver *NASStorageDriver,
-) (*storage.VolumeConfig, *api.FileSystem, *utils.VolumePublishInfo) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
-
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   volumeID,
-		AccessInfo: utils.VolumeAccessInfo{
-			NfsAccessInfo: utils.NfsAccessInfo{
-				NfsPath: "/trident-testvol1",
-			},
+func TestOntapSanStorageDriverGetStorageBackendPools(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.SubscriptionID = "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b"
+	resourceGroup := "RG1"
+	account := "NA1"
+	location := "fake-location"
+	pools := []*api.CapacityPool{
+		{
+			Name:          "CP1",
+			Location:      location,
+			NetAppAccount: account,
+			ResourceGroup: resourceGroup,
 		},
-	}
-
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
-
-	mountTargets := []api.MountTarget{
 		{
-			MountTargetID: "mountTargetID",
-			FileSystemID:  "filesystemID",
-			IPAddress:     "1.1.1.1",
-			ServerFqdn:    "",
+			Name:          "CP2",
+			Location:      location,
+			NetAppAccount: account,
+			ResourceGroup: resourceGroup,
 		},
 	}
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		Labels:            labels,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
-		MountTargets:      mountTargets,
-	}
+	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(pools)
 
-	publishInfo := &utils.VolumePublishInfo{}
+	backendPools := driver.getStorageBackendPools(ctx)
+	assert.Equal(t, len(pools), len(backendPools))
 
-	return volConfig, filesystem, publishInfo
+	// expected is an api.CapacityPools which contains all information present in the actual ANFStorageBackendPool
+	// (except for the subscription ID).
+	expected, actual := pools[0], backendPools[0]
+	assert.NotNil(t, expected)
+	assert.NotNil(t, actual)
+	assert.Equal(t, driver.Config.SubscriptionID, actual.SubscriptionID)
+	assert.Equal(t, expected.ResourceGroup, actual.ResourceGroup)
+	assert.Equal(t, expected.NetAppAccount, actual.NetappAccount)
+	assert.Equal(t, expected.Location, actual.Location)
+	assert.Equal(t, expected.Name, actual.CapacityPool)
+
+	expected, actual = pools[1], backendPools[1]
+	assert.NotNil(t, expected)
+	assert.NotNil(t, actual)
+	assert.Equal(t, driver.Config.SubscriptionID, actual.SubscriptionID)
+	assert.Equal(t, expected.ResourceGroup, actual.ResourceGroup)
+	assert.Equal(t, expected.NetAppAccount, actual.NetappAccount)
+	assert.Equal(t, expected.Location, actual.Location)
+	assert.Equal(t, expected.Name, actual.CapacityPool)
 }
 
-func getStructsForPublishSMBVolume(
-	ctx context.Context, driver *NASStorageDriver,
-) (*storage.VolumeConfig, *api.FileSystem, *utils.VolumePublishInfo) {
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "importMe")
+func TestCreatePrepare(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   volumeID,
-		AccessInfo: utils.VolumeAccessInfo{
-			SMBAccessInfo: utils.SMBAccessInfo{
-				SMBPath: "\\trident-testvol1",
-			},
-		},
-	}
+	tridentconfig.UsingPassthroughStore = true
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-	labels[storage.ProvisioningLabelTag] = ""
+	volConfig := &storage.VolumeConfig{Name: "testvol1"}
 
-	mountTargets := []api.MountTarget{
-		{
-			MountTargetID: "mountTargetID",
-			FileSystemID:  "filesystemID",
-			IPAddress:     "1.1.1.1",
-			ServerFqdn:    "trident-1234.trident.com",
-		},
-	}
+	driver.CreatePrepare(ctx, volConfig)
 
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		Labels:            labels,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeCIFS},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SnapshotDirectory: true,
-		SubnetID:          subnetID,
-		MountTargets:      mountTargets,
-	}
+	assert.Equal(t, "myPrefix-testvol1", volConfig.InternalName)
+}
 
-	publishInfo := &utils.VolumePublishInfo{}
+func TestGetStorageBackendPhysicalPoolNames(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 
-	return volConfig, filesystem, publishInfo
+	result := driver.GetStorageBackendPhysicalPoolNames(ctx)
+
+	assert.Equal(t, []string{}, result, "physical pool names mismatch")
 }
 
-func TestPublish_NFSVolume(t *testing.T) {
+func TestGetInternalVolumeName_PassthroughStore(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	tridentconfig.UsingPassthroughStore = true
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+
+	result := driver.GetInternalVolumeName(ctx, "testvol1")
+
+	assert.Equal(t, "myPrefix-testvol1", result, "internal name mismatch")
+}
+
+func TestGetInternalVolumeName_CSI(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	tridentconfig.UsingPassthroughStore = false
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+
+	result := driver.GetInternalVolumeName(ctx, "pvc-5e522901-b891-41d8-9e83-5496d2e62e71")
+
+	assert.Equal(t, "pvc-5e522901-b891-41d8-9e83-5496d2e62e71", result, "internal name mismatch")
+}
+
+func TestGetInternalVolumeName_NonCSI(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	tridentconfig.UsingPassthroughStore = false
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+
+	result := driver.GetInternalVolumeName(ctx, "testvol1")
+
+	anfRegex := regexp.MustCompile(`^anf-[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	assert.True(t, anfRegex.MatchString(result), "internal name mismatch")
+}
+
+func TestCreateFollowup_NFSVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 	driver.Config.NASType = "nfs"
 
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Publish(ctx, volConfig, publishInfo)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "nfsvers=3", publishInfo.MountOptions, "mount options mismatch")
+	assert.Equal(t, (filesystem.MountTargets)[0].IPAddress, volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "/"+filesystem.CreationToken, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestPublish_NFSVolume_Kerberos_Type5(t *testing.T) {
+func TestCreateFollowup_NFSVolume_Kerberos_Type5(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 	driver.Config.NASType = "nfs"
 	driver.Config.Kerberos = "sec=krb5"
 
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
 
 	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
 	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
 	filesystem.KerberosEnabled = true
 
-	volConfig.MountOptions = "nfsvers=4.1"
-
-	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
-
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Publish(ctx, volConfig, publishInfo)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "trident-1234.trident.com", publishInfo.NfsServerIP, "server fqdn mismatch")
-	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "nfsvers=4.1", publishInfo.MountOptions, "mount options mismatch")
+	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.NfsServerIP,
+		"server fqdn mismatch")
+	assert.Equal(t, "/"+filesystem.CreationToken, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestPublish_NFSVolume_Kerberos_Type5_NotSet(t *testing.T) {
+func TestCreateFollowup_NFSVolume_Kerberos_Type5_NotSet(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 	driver.Config.NASType = "nfs"
 	driver.Config.Kerberos = "sec=krb5"
 
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
 
 	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
 	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
 	filesystem.KerberosEnabled = false
 
-	volConfig.MountOptions = "nfsvers=4.1"
-
-	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
-
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Publish(ctx, volConfig, publishInfo)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.NotEqual(t, "trident-1234.trident.com", publishInfo.NfsServerIP, "server fqdn mismatch")
+	assert.NotEqual(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.NfsServerIP,
+		"server fqdn mismatch")
 }
 
-func TestPublish_ROClone_NFSVolume(t *testing.T) {
+func TestCreateFollowup_ROClone_NFSVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 	driver.Config.NASType = "nfs"
 
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.AccessInfo.NfsPath = "/trident-testvol1/.snapshot/" + SnapshotUUID
-	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
 	volConfig.CloneSourceVolumeInternal = volConfig.Name
+	volConfig.CloneSourceSnapshot = SnapshotUUID
 	volConfig.ReadOnlyClone = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(filesystem, nil).Times(1)
 
-	result := driver.Publish(ctx, volConfig, publishInfo)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
 	assert.NoError(t, result, "error occurred")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, "/trident-testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", publishInfo.NfsPath,
+	assert.Equal(t, (filesystem.MountTargets)[0].IPAddress, volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "/testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", volConfig.AccessInfo.NfsPath,
 		"NFS path mismatch")
-	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "nfsvers=3", publishInfo.MountOptions, "mount options mismatch")
+	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestPublish_SMBVolume(t *testing.T) {
+func TestCreateFollowup_SMBVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 	driver.Config.NASType = "smb"
 
-	volConfig, filesystem, publishInfo := getStructsForPublishSMBVolume(ctx, driver)
-	publishInfo.SMBPath = volConfig.AccessInfo.SMBPath
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, "\\trident-testvol1", publishInfo.SMBPath, "SMB path mismatch")
-	assert.Equal(t, "trident-1234.trident.com", publishInfo.SMBServer, "SMB server mismatch")
-	assert.Equal(t, "smb", publishInfo.FilesystemType, "filesystem type mismatch")
-}
-
-func TestPublish_MountOptions(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "nfs"
-
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.InternalID = ""
-	volConfig.MountOptions = "nfsvers=4.1"
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-	assert.Equal(t, "/trident-testvol1", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "1.1.1.1", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "nfs", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "nfsvers=4.1", publishInfo.MountOptions, "mount options mismatch")
-}
-
-func TestPublish_DiscoveryFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
-
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
-}
-
-func TestPublish_NonexistentVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
-
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
-}
-
-func TestPublish_ROClone_NonexistentVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.AccessInfo.NfsPath = "/trident-testvol1/.snapshot/" + SnapshotUUID
-	publishInfo.NfsPath = volConfig.AccessInfo.NfsPath
-	volConfig.CloneSourceVolumeInternal = volConfig.Name
-	volConfig.ReadOnlyClone = true
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(nil, errFailed).Times(1)
-
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.NotNil(t, result, "got nil")
-	assert.Error(t, result, "expected error")
-	assert.NotEqual(t, "", publishInfo.NfsPath, "NFS path is empty")
-	assert.Equal(t, "/trident-testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", publishInfo.NfsPath,
-		"NFS path mismatch")
-	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
-}
-
-func TestPublish_NoMountTargets(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, filesystem, publishInfo := getStructsForPublishNFSVolume(ctx, driver)
-	filesystem.MountTargets = nil
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
 	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result := driver.Publish(ctx, volConfig, publishInfo)
-
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", publishInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", publishInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "", publishInfo.FilesystemType, "filesystem type mismatch")
-	assert.Equal(t, "", publishInfo.MountOptions, "mount options mismatch")
-}
-
-func getStructsForCreateSnapshot(ctx context.Context, driver *NASStorageDriver, snapTime time.Time) (
-	*storage.VolumeConfig, *api.FileSystem, *storage.SnapshotConfig, *api.Snapshot,
-) {
-	volumeID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
-	subnetID := api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1")
-
-	volConfig := &storage.VolumeConfig{
-		Version:      "1",
-		Name:         "testvol1",
-		InternalName: "trident-testvol1",
-		Size:         VolumeSizeStr,
-		InternalID:   volumeID,
-	}
-
-	labels := make(map[string]string)
-	labels[drivers.TridentLabelTag] = driver.getTelemetryLabels(ctx)
-
-	filesystem := &api.FileSystem{
-		ID:                volumeID,
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Name:              "testvol1",
-		FullName:          "RG1/NA1/CP1/testvol1",
-		Location:          Location,
-		Labels:            labels,
-		ProvisioningState: api.StateAvailable,
-		CreationToken:     "trident-testvol1",
-		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
-		QuotaInBytes:      VolumeSizeI64,
-		ServiceLevel:      api.ServiceLevelUltra,
-		SubnetID:          subnetID,
-		UnixPermissions:   defaultUnixPermissions,
-	}
-
-	snapConfig := &storage.SnapshotConfig{
-		Version:            "1",
-		Name:               "snap1",
-		InternalName:       "snap1",
-		VolumeName:         "testvol1",
-		VolumeInternalName: "trident-testvol1",
-	}
-
-	snapshot := &api.Snapshot{
-		ResourceGroup:     "RG1",
-		NetAppAccount:     "NA1",
-		CapacityPool:      "CP1",
-		Volume:            "testvol1",
-		Name:              "snap1",
-		FullName:          "RG1/NA1/CP1/testvol1/snap1",
-		Location:          Location,
-		Created:           snapTime,
-		SnapshotID:        SnapshotUUID,
-		ProvisioningState: api.StateAvailable,
-	}
-
-	return volConfig, filesystem, snapConfig, snapshot
-}
-
-func TestCanSnapshot(t *testing.T) {
-	_, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
-
-	result := driver.CanSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
+	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
+	assert.Equal(t, "\\"+filesystem.CreationToken, volConfig.AccessInfo.SMBPath, "SMB path mismatch")
+	assert.Equal(t, "smb", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshot(t *testing.T) {
+func TestCreateFollowup_DualProtocolVolume_SMB(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
-	assert.Nil(t, resultErr, "not nil")
-	assert.Equal(t, snapConfig, result.Config, "snapshot mismatch")
-	assert.Equal(t, int64(0), result.SizeBytes, "snapshot mismatch")
-	assert.Equal(t, storage.SnapshotStateOnline, result.State, "snapshot mismatch")
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, "\\"+filesystem.CreationToken, volConfig.AccessInfo.SMBPath, "SMB path mismatch")
+	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
+	assert.Equal(t, "smb", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshot_DiscoveryFailed(t *testing.T) {
+func TestCreateFollowup_DualProtocolVolume_Dual(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = NASTypeDual
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Equal(t, (filesystem.MountTargets)[0].IPAddress, volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "/"+filesystem.CreationToken, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
+	assert.Equal(t, "\\"+filesystem.CreationToken, volConfig.AccessInfo.SMBPath, "SMB path mismatch")
+	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshot_VolumeExistsCheckFailed(t *testing.T) {
+func TestCreateFollowup_MultipleMountTargets(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = NASTypeDual
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	filesystem.MountTargets = []api.MountTarget{
+		{MountTargetID: "mountTarget1", ServerFqdn: "trident-1234.trident.com"},
+		{MountTargetID: "mountTarget2", IPAddress: "1.1.1.2"},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Equal(t, "1.1.1.2", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "trident-1234.trident.com", volConfig.AccessInfo.SMBServer, "SMB server mismatch")
 }
 
-func TestGetSnapshot_NonexistentVolume(t *testing.T) {
+func TestCreateFollowup_NoMatchingMountTarget(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = sa.NFS
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets = []api.MountTarget{{MountTargetID: "mountTarget1", ServerFqdn: "trident-1234.trident.com"}}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.Nil(t, resultErr, "not nil")
+	assert.Error(t, result, "expected error")
 }
 
-func TestGetSnapshot_NonexistentSnapshot(t *testing.T) {
+func TestCreateFollowup_ROClone_SMBVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NASType = "smb"
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
+	volConfig.CloneSourceVolumeInternal = volConfig.Name
+	volConfig.CloneSourceSnapshot = SnapshotUUID
+	volConfig.ReadOnlyClone = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(nil,
-		errors.NotFoundError("not found")).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
 	assert.Nil(t, result, "not nil")
-	assert.Nil(t, resultErr, "not nil")
+	assert.NoError(t, result, "error occurred")
+	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
+	assert.Equal(t, "\\testvol1\\~snapshot\\deadbeef-5c0d-4afa-8cd8-afa3fba5665c", volConfig.AccessInfo.SMBPath,
+		"SMB path mismatch")
+	assert.Equal(t, "smb", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshot_GetSnapshotFailed(t *testing.T) {
+func TestCreateFollowup_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
+	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshot_SnapshotNotAvailable(t *testing.T) {
+func TestCreateFollowup_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
-	snapshot.ProvisioningState = api.StateError
+	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
-
-	result, resultErr := driver.GetSnapshot(ctx, snapConfig, volConfig)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
-}
+	result := driver.CreateFollowup(ctx, volConfig)
 
-func getSnapshotsForList(snapTime time.Time) *[]*api.Snapshot {
-	return &[]*api.Snapshot{
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			CapacityPool:      "CP1",
-			Volume:            "testvol1",
-			Name:              "snap1",
-			FullName:          "RG1/NA1/CP1/testvol1/snap1",
-			Location:          Location,
-			Created:           snapTime,
-			SnapshotID:        SnapshotUUID,
-			ProvisioningState: api.StateAvailable,
-		},
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			CapacityPool:      "CP1",
-			Volume:            "testvol1",
-			Name:              "snap2",
-			FullName:          "RG1/NA1/CP1/testvol1/snap2",
-			Location:          Location,
-			Created:           snapTime,
-			SnapshotID:        SnapshotUUID,
-			ProvisioningState: api.StateAvailable,
-		},
-		{
-			ResourceGroup:     "RG1",
-			NetAppAccount:     "NA1",
-			CapacityPool:      "CP1",
-			Volume:            "testvol1",
-			Name:              "snap3",
-			FullName:          "RG1/NA1/CP1/testvol1/snap3",
-			Location:          Location,
-			Created:           time.Now(),
-			SnapshotID:        "",
-			ProvisioningState: api.StateError,
-		},
-	}
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
+	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshots(t *testing.T) {
+func TestCreateFollowup_ROClone_NonexistentVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
-	snapshots := getSnapshotsForList(snapTime)
+	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig.CloneSourceVolumeInternal = volConfig.Name
+	volConfig.ReadOnlyClone = true
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotsForVolume(ctx, filesystem).Return(snapshots, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(nil,
+		errors.NotFoundError("not found")).Times(1)
 
-	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
-	expectedSnapshot0 := &storage.Snapshot{
-		Config: &storage.SnapshotConfig{
-			Version:            tridentconfig.OrchestratorAPIVersion,
-			Name:               "snap1",
-			InternalName:       "snap1",
-			VolumeName:         "testvol1",
-			VolumeInternalName: "trident-testvol1",
-		},
-		Created:   snapTime.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
-	}
+	assert.NotNil(t, result, "received nil")
+	assert.Error(t, result, "expected error")
+	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
+	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
+}
 
-	expectedSnapshot1 := &storage.Snapshot{
-		Config: &storage.SnapshotConfig{
-			Version:            tridentconfig.OrchestratorAPIVersion,
-			Name:               "snap2",
-			InternalName:       "snap2",
-			VolumeName:         "testvol1",
-			VolumeInternalName: "trident-testvol1",
-		},
-		Created:   snapTime.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
+func TestCreateFollowup_VolumeNotAvailable(t *testing.T) {
+	nonAvailableStates := []string{
+		api.StateAccepted, api.StateCreating, api.StateDeleting, api.StateDeleted,
+		api.StateMoving, api.StateError, api.StateReverting,
 	}
 
-	assert.Nil(t, resultErr, "not nil")
-	assert.Len(t, result, 2)
-
-	assert.Equal(t, result[0], expectedSnapshot0, "snapshot 0 mismatch")
-	assert.Equal(t, result[1], expectedSnapshot1, "snapshot 1 mismatch")
-}
+	for _, state := range nonAvailableStates {
 
-func TestGetSnapshots_DiscoveryFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+		mockAPI, driver := newMockANFDriver(t)
+		driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+		volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+		filesystem.ProvisioningState = state
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+		mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+		mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+		result := driver.CreateFollowup(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+		assert.NotNil(t, result, "expected error")
+		assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+		assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
+		assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+		assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
+	}
 }
 
-func TestGetSnapshots_NonexistentVolume(t *testing.T) {
+func TestCreateFollowup_NoMountTargets(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.MountTargets = nil
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
 
-	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+	result := driver.CreateFollowup(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
+	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestGetSnapshots_GetSnapshotsFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetProtocol(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	result := driver.GetProtocol(ctx)
+
+	assert.Equal(t, tridentconfig.File, result)
+}
+
+func TestStoreConfig(t *testing.T) {
+	commonConfig := &drivers.CommonStorageDriverConfig{
+		Version:           1,
+		StorageDriverName: "azure-netapp-files",
+		BackendName:       "myANFBackend",
+		DriverContext:     tridentconfig.ContextCSI,
+		DebugTraceFlags:   debugTraceFlags,
+	}
 
-	snapTime := time.Now()
-	volConfig, filesystem, _, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, driver := newMockANFDriver(t)
+	driver.Config.CommonStorageDriverConfig = commonConfig
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotsForVolume(ctx, filesystem).Return(nil, errFailed).Times(1)
+	persistentConfig := &storage.PersistentStorageBackendConfig{}
 
-	result, resultErr := driver.GetSnapshots(ctx, volConfig)
+	driver.StoreConfig(ctx, persistentConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Equal(t, json.RawMessage("{}"), driver.Config.CommonStorageDriverConfig.StoragePrefixRaw,
+		"raw prefix mismatch")
+	assert.Equal(t, driver.Config, *persistentConfig.AzureConfig, "azure config mismatch")
 }
 
-func TestCreateSnapshot(t *testing.T) {
+func TestGetVolumeExternal(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "myPrefix-testvol1",
+		ProvisioningState: api.StateAvailable,
+	}
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), nil).Times(1)
 
-	expectedSnapshot := &storage.Snapshot{
-		Config:    snapConfig,
-		Created:   snapTime.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
-	}
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
 	assert.Nil(t, resultErr, "not nil")
-	assert.Equal(t, expectedSnapshot, result)
+	assert.IsType(t, &storage.VolumeExternal{}, result, "type mismatch")
+	assert.Equal(t, "1", result.Config.Version)
+	assert.Equal(t, "testvol1", result.Config.Name)
+	assert.Equal(t, "myPrefix-testvol1", result.Config.InternalName)
+	assert.Empty(t, result.Config.ExportPolicy, "export policy mismatch")
+	assert.Empty(t, result.Config.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Empty(t, result.Config.AccessInfo.SMBPath, "SMB path mismatch")
 }
 
-func TestCreateSnapshot_DiscoveryFailed(t *testing.T) {
+func TestGetVolumeExternal_NFSVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.SnapshotPolicyID = api.CreateSnapshotPolicyID(SubscriptionID, "RG1", "NA1", "SP1")
+	filesystem.ExportPolicy = api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{AllowedClients: "10.0.0.0/24", Nfsv3: true, RuleIndex: 1, UnixReadWrite: true},
+			{AllowedClients: "10.0.1.0/24", Nfsv3: true, RuleIndex: 2, UnixReadOnly: true},
+		},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), nil).Times(1)
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, "10.0.0.0/24,10.0.1.0/24", result.Config.ExportPolicy, "export policy mismatch")
+	assert.Equal(t, filesystem.SnapshotPolicyID, result.Config.SnapshotPolicy, "snapshot policy mismatch")
+	assert.Equal(t, "true", result.Config.SnapshotDir, "snapshot dir mismatch")
+	assert.Equal(t, "/trident-testvol1", result.Config.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "1.1.1.1", result.Config.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Empty(t, result.Config.AccessInfo.SMBPath, "SMB path mismatch")
+	assert.Empty(t, result.Config.AccessInfo.SMBServer, "SMB server mismatch")
 }
 
-func TestCreateSnapshot_VolumeExistsCheckFailed(t *testing.T) {
+func TestGetVolumeExternal_KerberosVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
+	filesystem.KerberosEnabled = true
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), nil).Times(1)
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, "trident-1234.trident.com", result.Config.AccessInfo.NfsServerIP, "NFS server mismatch")
 }
 
-func TestCreateSnapshot_NonexistentVolume(t *testing.T) {
+func TestGetVolumeExternal_DualProtocolVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv3, api.ProtocolTypeCIFS}
+	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), nil).Times(1)
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, "/trident-testvol1", result.Config.AccessInfo.NfsPath, "NFS path mismatch")
+	assert.Equal(t, "1.1.1.1", result.Config.AccessInfo.NfsServerIP, "NFS server IP mismatch")
+	assert.Equal(t, "\\trident-testvol1", result.Config.AccessInfo.SMBPath, "SMB path mismatch")
+	assert.Equal(t, "trident-1234.trident.com", result.Config.AccessInfo.SMBServer, "SMB server mismatch")
 }
 
-func TestCreateSnapshot_SnapshotCreateFailed(t *testing.T) {
+func TestGetVolumeExternal_SnapshotReserve(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "test-testvol1",
+		ProvisioningState: api.StateAvailable,
+		QuotaInBytes:      VolumeSizeI64 * 100 / 80,
+		Labels:            map[string]string{SnapshotReserveTag: "20"},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), nil).Times(1)
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, VolumeSizeStr, result.Config.Size, "size mismatch")
+	assert.Equal(t, "20", result.Config.SnapshotReserve, "snapshot reserve mismatch")
 }
 
-func TestCreateSnapshot_SnapshotWaitFailed(t *testing.T) {
+func TestGetVolumeExternal_UsedBytes(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "test-testvol1",
+		ProvisioningState: api.StateAvailable,
+		QuotaInBytes:      VolumeSizeI64,
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().CreateSnapshot(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateAvailable, []string{api.StateError},
-		api.SnapshotTimeout).Return(errFailed).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(VolumeSizeI64/4, VolumeSizeI64/10, nil).Times(1)
 
-	result, resultErr := driver.CreateSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "expected error")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, strconv.FormatInt(VolumeSizeI64, 10), result.Config.Size, "size mismatch")
+	assert.Equal(t, VolumeSizeI64/4, result.UsedBytes, "used bytes mismatch")
 }
 
-func TestRestoreSnapshot(t *testing.T) {
+func TestGetVolumeExternal_UsedBytesUnknown(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "test-testvol1",
+		ProvisioningState: api.StateAvailable,
+		QuotaInBytes:      VolumeSizeI64,
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable,
-		[]string{api.StateError, api.StateDeleting, api.StateDeleted}, api.DefaultSDKTimeout).
-		Return(api.StateAvailable, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{}).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), errFailed).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.Nil(t, result, "not nil")
+	assert.Nil(t, resultErr, "not nil")
+	assert.Equal(t, int64(0), result.UsedBytes, "used bytes mismatch")
 }
 
-func TestRestoreSnapshot_DiscoveryFailed(t *testing.T) {
+func TestGetVolumeExternal_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.NotNil(t, result, "expected error")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "error expected")
 }
 
-func TestRestoreSnapshot_VolumeExistsCheckFailed(t *testing.T) {
+func TestGetVolumeExternal_GetFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(nil, errFailed).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
 
-	assert.NotNil(t, result, "expected error")
+	assert.Nil(t, result, "not nil")
+	assert.NotNil(t, resultErr, "error expected")
 }
 
-func TestRestoreSnapshot_NonexistentVolume(t *testing.T) {
+func TestGetVolumeExternalWrappers(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
-	assert.NotNil(t, result, "expected error")
-}
+	filesystems := getVolumesForList()
+	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
 
-func TestRestoreSnapshot_NonexistentSnapshot(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(filesystems, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(&storage.BackendCacheStatus{MaxCacheAge: time.Minute}).Times(2)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, gomock.Any()).Times(0)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	// Only usage that was read recently is reported
+	driver.volumeUsage.put("myPrefix-testvol1", VolumeUsage{UsedBytes: VolumeSizeI64 / 4})
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errors.NotFoundError("not found")).Times(1)
+	driver.GetVolumeExternalWrappers(ctx, channel)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	// Read the volumes from the channel
+	volumes := make([]*storage.VolumeExternal, 0)
+	for wrapper := range channel {
+		if wrapper.Error != nil {
+			t.FailNow()
+		} else {
+			volumes = append(volumes, wrapper.Volume)
+		}
+	}
 
-	assert.NotNil(t, result, "expected error")
+	assert.Len(t, volumes, 2, "wrong number of volumes")
+	for _, volume := range volumes {
+		if volume.Config.InternalName == "myPrefix-testvol1" {
+			assert.Equal(t, VolumeSizeI64/4, volume.UsedBytes, "used bytes mismatch")
+		} else {
+			assert.Equal(t, int64(0), volume.UsedBytes, "used bytes mismatch")
+		}
+	}
 }
 
-func TestRestoreSnapshot_GetSnapshotFailed(t *testing.T) {
+func TestGetVolumeExternalWrappers_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
+	filesystems := getVolumesForList()
+	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+
+	driver.GetVolumeExternalWrappers(ctx, channel)
+
+	// Read the volumes from the channel
+	var result error
+	for wrapper := range channel {
+		if wrapper.Error != nil {
+			result = wrapper.Error
+			break
+		}
+	}
 
 	assert.NotNil(t, result, "expected error")
 }
 
-func TestRestoreSnapshot_SnapshotRestoreFailed(t *testing.T) {
+func TestGetVolumeExternalWrappers_ListFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+
+	filesystems := getVolumesForList()
+	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(errFailed).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(nil, errFailed).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	driver.GetVolumeExternalWrappers(ctx, channel)
+
+	// Read the volumes from the channel
+	var result error
+	for wrapper := range channel {
+		if wrapper.Error != nil {
+			result = wrapper.Error
+			break
+		}
+	}
 
 	assert.NotNil(t, result, "expected error")
 }
 
-func TestRestoreSnapshot_VolumeWaitFailed(t *testing.T) {
+func TestOrphanedVolumes(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	storagePrefix := "myPrefix-"
+	driver.Config.StoragePrefix = &storagePrefix
+
+	otherBackend := &NASStorageDriver{}
+	otherBackend.initializeTelemetry(ctx, "other-backend-uuid")
+
+	ourLabels := map[string]string{drivers.TridentLabelTag: driver.getTelemetryLabels(ctx)}
+	otherLabels := map[string]string{drivers.TridentLabelTag: otherBackend.getTelemetryLabels(ctx)}
+
+	filesystems := &[]*api.FileSystem{
+		{CreationToken: "myPrefix-tracked", Labels: ourLabels, ProvisioningState: api.StateAvailable},
+		{CreationToken: "myPrefix-orphan", Labels: ourLabels, ProvisioningState: api.StateAvailable},
+		{CreationToken: "myPrefix-failed", Labels: ourLabels, ProvisioningState: api.StateError},
+		{CreationToken: "myPrefix-deleting", Labels: ourLabels, ProvisioningState: api.StateDeleting},
+		{CreationToken: "myPrefix-other", Labels: otherLabels, ProvisioningState: api.StateAvailable},
+		{CreationToken: "myPrefix-unlabeled", ProvisioningState: api.StateAvailable},
+		{CreationToken: "otherPrefix-orphan", Labels: ourLabels, ProvisioningState: api.StateAvailable},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().RestoreSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable,
-		[]string{api.StateError, api.StateDeleting, api.StateDeleted},
-		api.DefaultSDKTimeout).Return("", errFailed).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(filesystems, nil).Times(1)
 
-	result := driver.RestoreSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.OrphanedVolumes(ctx, []string{"myPrefix-tracked"})
 
-	assert.NotNil(t, result, "expected error")
+	assert.NoError(t, resultErr, "unexpected error")
+	orphanNames := make([]string, 0, len(result))
+	for _, volume := range result {
+		orphanNames = append(orphanNames, volume.Config.InternalName)
+	}
+	assert.ElementsMatch(t, []string{"myPrefix-orphan", "myPrefix-failed"}, orphanNames, "orphan mismatch")
 }
 
-func TestDeleteSnapshot(t *testing.T) {
+func TestOrphanedVolumes_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, snapConfig.InternalName).Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateDeleted, []string{api.StateError},
-		api.SnapshotTimeout).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	result, resultErr := driver.OrphanedVolumes(ctx, nil)
 
-	assert.Nil(t, result, "not nil")
+	assert.Error(t, resultErr, "expected error")
+	assert.Nil(t, result, "expected nil result")
 }
 
-func TestDeleteSnapshot_DiscoveryFailed(t *testing.T) {
+func TestOrphanedVolumes_ListFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(nil, errFailed).Times(1)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	result, resultErr := driver.OrphanedVolumes(ctx, nil)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	assert.Error(t, resultErr, "expected error")
+	assert.Nil(t, result, "expected nil result")
+}
 
-	assert.NotNil(t, result, "expected error")
+func TestStringAndGoString(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+
+	stringFunc := func(d *NASStorageDriver) string { return d.String() }
+	goStringFunc := func(d *NASStorageDriver) string { return d.GoString() }
+
+	for _, toString := range []func(*NASStorageDriver) string{stringFunc, goStringFunc} {
+
+		assert.Contains(t, toString(driver), "<REDACTED>",
+			"ANF driver does not contain <REDACTED>")
+		assert.Contains(t, toString(driver), "SDK:<REDACTED>",
+			"ANF driver does not redact SDK information")
+		assert.Contains(t, toString(driver), "SubscriptionID:<REDACTED>",
+			"ANF driver does not redact API URL")
+		assert.Contains(t, toString(driver), "TenantID:<REDACTED>",
+			"ANF driver does not redact Tenant ID")
+		assert.Contains(t, toString(driver), "ClientID:<REDACTED>",
+			"ANF driver does not redact Client ID")
+		assert.Contains(t, toString(driver), "ClientSecret:<REDACTED>",
+			"ANF driver does not redact Client Secret")
+		assert.NotContains(t, toString(driver), SubscriptionID,
+			"ANF driver contains Subscription ID")
+		assert.NotContains(t, toString(driver), TenantID,
+			"ANF driver contains Tenant ID")
+		assert.NotContains(t, toString(driver), ClientID,
+			"ANF driver contains Client ID")
+		assert.NotContains(t, toString(driver), ClientSecret,
+			"ANF driver contains Client Secret")
+	}
 }
 
-func TestDeleteSnapshot_VolumeExistsCheckFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_NoFlaggedChanges(t *testing.T) {
+	_, oldDriver := newMockANFDriver(t)
+	oldDriver.volumeCreateTimeout = 1 * time.Second
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, newDriver := newMockANFDriver(t)
+	newDriver.volumeCreateTimeout = 2 * time.Second
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, errFailed).Times(1)
+	result := newDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	expectedBitmap := &roaring.Bitmap{}
 
-	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
 }
 
-func TestDeleteSnapshot_NonexistentVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_WrongDriverType(t *testing.T) {
+	oldDriver := &fake.StorageDriver{
+		Config:             drivers.FakeStorageDriverConfig{},
+		Volumes:            make(map[string]storagefake.Volume),
+		DestroyedVolumes:   make(map[string]bool),
+		Snapshots:          make(map[string]map[string]*storage.Snapshot),
+		DestroyedSnapshots: make(map[string]bool),
+		Secret:             "secret",
+	}
 
-	snapTime := time.Now()
-	volConfig, _, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, newDriver := newMockANFDriver(t)
+	newDriver.volumeCreateTimeout = 2 * time.Second
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	result := newDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	expectedBitmap := &roaring.Bitmap{}
+	expectedBitmap.Add(storage.InvalidUpdate)
 
-	assert.Nil(t, result, "not nil")
+	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
 }
 
-func TestDeleteSnapshot_NonexistentSnapshot(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_AuthModeChange(t *testing.T) {
+	_, oldDriver := newMockANFDriver(t)
+	oldDriver.Config.ClientSecret = "secret"
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, newDriver := newMockANFDriver(t)
+	newDriver.Config.ClientSecret = ""
+	newDriver.Config.UseManagedIdentity = true
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errors.NotFoundError("not found")).Times(1)
+	result := newDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	expectedBitmap := &roaring.Bitmap{}
+	expectedBitmap.Add(storage.CredentialsChange)
 
-	assert.Nil(t, result, "not nil")
+	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
 }
 
-func TestDeleteSnapshot_GetSnapshotFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_OtherChanges(t *testing.T) {
+	_, oldDriver := newMockANFDriver(t)
+	prefix1 := "prefix1-"
+	oldDriver.Config.StoragePrefix = &prefix1
+	oldDriver.Config.Credentials = map[string]string{
+		drivers.KeyName: "secret1",
+		drivers.KeyType: string(drivers.CredentialStoreK8sSecret),
+	}
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, _ := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, newDriver := newMockANFDriver(t)
+	prefix2 := "prefix2-"
+	newDriver.Config.StoragePrefix = &prefix2
+	newDriver.Config.Credentials = map[string]string{
+		drivers.KeyName: "secret2",
+		drivers.KeyType: string(drivers.CredentialStoreK8sSecret),
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(nil, errFailed).Times(1)
+	result := newDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	expectedBitmap := &roaring.Bitmap{}
+	expectedBitmap.Add(storage.PrefixChange)
+	expectedBitmap.Add(storage.CredentialsChange)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
 }
 
-func TestDeleteSnapshot_SnapshotDeleteFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_VolumeAccessInfoChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(config *drivers.AzureNASStorageDriverConfig)
+	}{
+		{"UnixPermissions", func(config *drivers.AzureNASStorageDriverConfig) { config.UnixPermissions = "0700" }},
+		{"ExportRule", func(config *drivers.AzureNASStorageDriverConfig) { config.ExportRule = "10.0.0.0/24" }},
+		{"SnapshotDir", func(config *drivers.AzureNASStorageDriverConfig) { config.SnapshotDir = "true" }},
+		{"VirtualPoolUnixPermissions", func(config *drivers.AzureNASStorageDriverConfig) {
+			config.Storage[0].UnixPermissions = "0700"
+		}},
+		{"VirtualPoolExportRule", func(config *drivers.AzureNASStorageDriverConfig) {
+			config.Storage[0].ExportRule = "10.0.0.0/24"
+		}},
+		{"VirtualPoolSnapshotDir", func(config *drivers.AzureNASStorageDriverConfig) {
+			config.Storage[0].SnapshotDir = "true"
+		}},
+	}
+
+	newDriver := func(update func(config *drivers.AzureNASStorageDriverConfig)) *NASStorageDriver {
+		_, driver := newMockANFDriver(t)
+		driver.Config.BackendName = "anf"
+		driver.Config.Storage = []drivers.AzureNASStorageDriverPool{{}}
+		if update != nil {
+			update(&driver.Config)
+		}
+		driver.populateConfigurationDefaults(ctx, &driver.Config)
+		driver.initializeStoragePools(ctx)
+		return driver
+	}
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldDriver := newDriver(nil)
+			updatedDriver := newDriver(test.update)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(errFailed).Times(1)
+			result := updatedDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+			expectedBitmap := &roaring.Bitmap{}
+			expectedBitmap.Add(storage.VolumeAccessInfoChange)
 
-	assert.NotNil(t, result, "expected error")
+			assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
+		})
+	}
 }
 
-func TestDeleteSnapshot_SnapshotWaitFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+func TestGetUpdateType_VolumeAccessInfoUnchanged(t *testing.T) {
+	_, oldDriver := newMockANFDriver(t)
+	oldDriver.Config.BackendName = "anf"
+	oldDriver.populateConfigurationDefaults(ctx, &oldDriver.Config)
+	oldDriver.initializeStoragePools(ctx)
 
-	snapTime := time.Now()
-	volConfig, filesystem, snapConfig, snapshot := getStructsForCreateSnapshot(ctx, driver, snapTime)
+	_, newDriver := newMockANFDriver(t)
+	newDriver.Config.BackendName = "anf"
+	newDriver.populateConfigurationDefaults(ctx, &newDriver.Config)
+	newDriver.initializeStoragePools(ctx)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
-	mockAPI.EXPECT().SnapshotForVolume(ctx, filesystem, "snap1").Return(snapshot, nil).Times(1)
-	mockAPI.EXPECT().DeleteSnapshot(ctx, filesystem, snapshot).Return(nil).Times(1)
-	mockAPI.EXPECT().WaitForSnapshotState(ctx, snapshot, filesystem, api.StateDeleted, []string{api.StateError},
-		api.SnapshotTimeout).Return(errFailed).Times(1)
+	result := newDriver.GetUpdateType(ctx, oldDriver)
 
-	result := driver.DeleteSnapshot(ctx, snapConfig, volConfig)
+	assert.Equal(t, &roaring.Bitmap{}, result, "bitmap mismatch")
+}
 
-	assert.NotNil(t, result, "expected error")
+func TestReconcileNodeAccess(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAPI := mockapi.NewMockAzure(mockCtrl)
+
+	driver := newTestANFDriver(mockAPI)
+
+	result := driver.ReconcileNodeAccess(ctx, nil, "", "")
+
+	assert.Nil(t, result, "not nil")
 }
 
-func getVolumesForList() *[]*api.FileSystem {
-	return &[]*api.FileSystem{
+func getStructsForReconcileNodeAccess() ([]*utils.Node, *[]*api.FileSystem) {
+	nodes := []*utils.Node{
+		{Name: "node1", IPs: []string{"10.0.0.5", "192.168.1.1"}},
+		{Name: "node2", IPs: []string{"10.0.0.4"}},
+	}
+
+	volumes := &[]*api.FileSystem{
 		{
+			Name:              "testvol1",
 			ProvisioningState: api.StateAvailable,
-			CreationToken:     "myPrefix-testvol1",
+			CreationToken:     "test-testvol1",
+			ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+			ExportPolicy: api.ExportPolicy{
+				Rules: []api.ExportRule{{AllowedClients: "10.0.0.5, 10.0.0.4", Nfsv3: true, RuleIndex: 1}},
+			},
 		},
 		{
+			Name:              "testvol2",
 			ProvisioningState: api.StateAvailable,
-			CreationToken:     "myPrefix-testvol2",
-		},
-		{
-			ProvisioningState: api.StateDeleting,
-			CreationToken:     "myPrefix-testvol3",
+			CreationToken:     "test-testvol2",
+			ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+			ExportPolicy: api.ExportPolicy{
+				Rules: []api.ExportRule{{AllowedClients: "10.0.0.1", Nfsv3: true, RuleIndex: 1}},
+			},
 		},
 		{
-			ProvisioningState: api.StateDeleted,
-			CreationToken:     "myPrefix-testvol4",
+			Name:              "testvol3",
+			ProvisioningState: api.StateAvailable,
+			CreationToken:     "test-testvol3",
+			ProtocolTypes:     []string{api.ProtocolTypeCIFS},
 		},
 		{
-			ProvisioningState: api.StateError,
-			CreationToken:     "myPrefix-testvol5",
+			Name:              "testvol4",
+			ProvisioningState: api.StateAvailable,
+			CreationToken:     "other-testvol4",
+			ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+			ExportPolicy: api.ExportPolicy{
+				Rules: []api.ExportRule{{AllowedClients: "10.0.0.1", Nfsv3: true, RuleIndex: 1}},
+			},
 		},
 		{
-			ProvisioningState: api.StateAvailable,
-			CreationToken:     "testvol6",
+			Name:              "testvol5",
+			ProvisioningState: api.StateDeleting,
+			CreationToken:     "test-testvol5",
+			ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+			ExportPolicy: api.ExportPolicy{
+				Rules: []api.ExportRule{{AllowedClients: "10.0.0.1", Nfsv3: true, RuleIndex: 1}},
+			},
 		},
 	}
-}
-
-func TestList(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
 
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	return nodes, volumes
+}
 
-	volumes := getVolumesForList()
+func TestReconcileNodeAccess_DefaultExportRule(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.ExportRule = defaultExportRule
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	nodes, _ := getStructsForReconcileNodeAccess()
 
-	list, result := driver.List(ctx)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.Nil(t, result, "expected no error")
-	assert.Equal(t, list, []string{"testvol1", "testvol2"}, "list not nil")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestList_DiscoveryFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestReconcileNodeAccess_SMB(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.NASType = sa.SMB
+	driver.Config.ExportRule = "10.0.0.0/24"
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Times(0)
+	nodes, _ := getStructsForReconcileNodeAccess()
 
-	list, result := driver.List(ctx)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.Error(t, result, "expected error")
-	assert.Nil(t, list, "list not nil")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestList_ListFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestReconcileNodeAccess_DriverContext(t *testing.T) {
+	tests := []struct {
+		context    tridentconfig.DriverContext
+		reconciled bool
+	}{
+		{tridentconfig.ContextCSI, true},
+		{tridentconfig.ContextDocker, false},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.context), func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.Config.DriverContext = test.context
+			driver.Config.ExportRule = defaultExportRule
+			driver.Config.AutoExportPolicy = true
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+
+			nodes, volumes := getStructsForReconcileNodeAccess()
+
+			expectedCalls := 0
+			if test.reconciled {
+				expectedCalls = 1
+			}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Return(nil, errFailed).Times(1)
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(expectedCalls)
+			mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(expectedCalls)
+			mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	list, result := driver.List(ctx)
+			result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.Error(t, result, "expected error")
-	assert.Nil(t, list, "list not nil")
+			assert.Nil(t, result, "not nil")
+			assert.Equal(t, test.reconciled, driver.autoExportClients != "", "auto export clients mismatch")
+		})
+	}
 }
 
-func TestList_ListNone(t *testing.T) {
+func TestReconcileNodeAccess_UpdatesChangedVolumes(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ExportRule = "10.0.0.0/24"
+
+	nodes, volumes := getStructsForReconcileNodeAccess()
+
+	expectedPolicy := &api.ExportPolicy{
+		Rules: []api.ExportRule{{AllowedClients: "10.0.0.4,10.0.0.5", Nfsv3: true, RuleIndex: 1}},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Return(&[]*api.FileSystem{}, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[1], expectedPolicy).Return(nil).Times(1)
 
-	list, result := driver.List(ctx)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.Nil(t, result, "expected nil")
-	assert.Equal(t, []string{}, list, "list not empty")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestGet(t *testing.T) {
+func TestReconcileNodeAccess_NoMatchingNodes(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ExportRule = "172.16.0.0/16"
 
-	filesystem := &api.FileSystem{}
+	nodes, volumes := getStructsForReconcileNodeAccess()
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Get(ctx, "volume1")
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.NoError(t, result, "expected no error")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestGet_DiscoveryFailed(t *testing.T) {
+func TestReconcileNodeAccess_DiscoveryFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ExportRule = "10.0.0.4,10.0.0.5"
+
+	nodes, _ := getStructsForReconcileNodeAccess()
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Times(0)
 
-	result := driver.Get(ctx, "volume1")
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
 	assert.Error(t, result, "expected error")
 }
 
-func TestGet_NotFound(t *testing.T) {
+func TestReconcileNodeAccess_ModifyFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.ExportRule = "10.0.0.4,10.0.0.5"
+
+	nodes, volumes := getStructsForReconcileNodeAccess()
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, "volume1").Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[1], gomock.Any()).Return(errFailed).Times(1)
 
-	result := driver.Get(ctx, "volume1")
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
 	assert.Error(t, result, "expected error")
 }
 
-func TestResize(t *testing.T) {
+func TestReconcileNodeAccess_AutoExportPolicy_NodeAdded(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.ExportRule = defaultExportRule
+	driver.Config.AutoExportPolicy = true
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
 
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	volConfig.InternalID = ""
-	newSize := uint64(VolumeSizeI64 * 2)
+	nodes, volumes := getStructsForReconcileNodeAccess()
+	nodes = append(nodes, &utils.Node{Name: "node3", IPs: []string{"10.0.0.6"}})
+
+	expectedRule := api.ExportRule{
+		AllowedClients: "10.0.0.4,10.0.0.5,10.0.0.6,192.168.1.1", Nfsv3: true, RuleIndex: 1,
+	}
+	expectedPolicy := &api.ExportPolicy{Rules: []api.ExportRule{expectedRule}}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[0], expectedPolicy).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[1], expectedPolicy).Return(nil).Times(1)
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
 	assert.Nil(t, result, "not nil")
-	assert.Equal(t, strconv.FormatUint(newSize, 10), volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, expectedRule.AllowedClients, driver.autoExportClients, "auto export clients mismatch")
 }
 
-func TestResize_DiscoveryFailed(t *testing.T) {
+func TestReconcileNodeAccess_AutoExportPolicy_NodeRemoved(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AutoExportPolicy = true
+	driver.Config.AutoExportCIDRs = []string{"10.0.0.0/24"}
 
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	newSize := uint64(VolumeSizeI64 * 2)
+	nodes, volumes := getStructsForReconcileNodeAccess()
+	nodes = nodes[1:]
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	expectedPolicy := &api.ExportPolicy{
+		Rules: []api.ExportRule{{AllowedClients: "10.0.0.4", Nfsv3: true, RuleIndex: 1}},
+	}
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[0], expectedPolicy).Return(nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, (*volumes)[1], expectedPolicy).Return(nil).Times(1)
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
+
+	assert.Nil(t, result, "not nil")
+	assert.Equal(t, "10.0.0.4", driver.autoExportClients, "auto export clients mismatch")
 }
 
-func TestResize_NonexistentVolume(t *testing.T) {
+func TestReconcileNodeAccess_AutoExportPolicy_Unchanged(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.AutoExportPolicy = true
+	driver.Config.AutoExportCIDRs = []string{"10.0.0.0/24"}
 
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	newSize := uint64(VolumeSizeI64 * 2)
+	nodes, volumes := getStructsForReconcileNodeAccess()
+	(*volumes)[1].ExportPolicy.Rules[0].AllowedClients = "10.0.0.5,10.0.0.4"
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestResize_VolumeNotAvailable(t *testing.T) {
+func TestReconcileNodeAccess_VirtualPoolExportRules(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	filesystem.ProvisioningState = api.StateError
-	newSize := uint64(VolumeSizeI64 * 2)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	driver.Config.ExportRule = defaultExportRule
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	pool := storage.NewStoragePool(nil, "pool1")
+	pool.InternalAttributes()[ExportRule] = defaultExportRule
+	pool.InternalAttributes()[ExportRules] = encodeExportRules([]drivers.AzureNASExportRule{
+		{AllowedClients: "10.0.0.0/24", Nfsv3: true},
+		{AllowedClients: "10.1.0.0/16", Nfsv41: true},
+		{AllowedClients: "192.168.0.0/16", UnixReadOnly: true},
+	})
+	driver.pools = map[string]storage.Pool{pool.Name(): pool}
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
-}
+	nodes, _ := getStructsForReconcileNodeAccess()
 
-func TestResize_NoSizeChange(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	// The NFSv4.1 rule doesn't apply to this NFSv3 volume, and the third rule was added outside of Trident
+	volume := &api.FileSystem{
+		Name:              "testvol1",
+		ProvisioningState: api.StateAvailable,
+		CreationToken:     "test-testvol1",
+		ProtocolTypes:     []string{api.ProtocolTypeNFSv3},
+		Labels:            map[string]string{StoragePoolTag: pool.Name()},
+		ExportPolicy: api.ExportPolicy{
+			Rules: []api.ExportRule{
+				{AllowedClients: "10.0.0.1", Nfsv3: true, RuleIndex: 1, UnixReadWrite: true},
+				{AllowedClients: "192.168.0.0/16", Nfsv3: true, RuleIndex: 2, UnixReadOnly: true},
+				{AllowedClients: "172.16.0.1", Nfsv3: true, RuleIndex: 3, UnixReadWrite: true},
+			},
+		},
+	}
+	volumes := &[]*api.FileSystem{volume}
 
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	newSize := uint64(VolumeSizeI64)
+	expectedPolicy := &api.ExportPolicy{
+		Rules: []api.ExportRule{
+			{AllowedClients: "10.0.0.4,10.0.0.5", Nfsv3: true, RuleIndex: 1, UnixReadWrite: true},
+			{AllowedClients: "192.168.1.1", Nfsv3: true, RuleIndex: 2, UnixReadOnly: true},
+			{AllowedClients: "172.16.0.1", Nfsv3: true, RuleIndex: 3, UnixReadWrite: true},
+		},
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, volume, expectedPolicy).Return(nil).Times(1)
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
 	assert.Nil(t, result, "not nil")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Equal(t, "10.0.0.1", volume.ExportPolicy.Rules[0].AllowedClients, "volume export policy modified")
 }
 
-func TestResize_ShrinkingVolume(t *testing.T) {
+func TestReconcileNodeAccess_SkippedVolumes(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.ExportRule = "10.0.0.0/24"
 
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	newSize := uint64(VolumeSizeI64 / 2)
+	nodes, volumes := getStructsForReconcileNodeAccess()
+	(*volumes)[0].ExportPolicy.Rules[0].AllowedClients = "10.0.0.1"
+	(*volumes)[0].Labels = map[string]string{ExplicitExportRuleTag: "true"}
+	(*volumes)[1].VolumeSpecName = api.VolumeSpecNameData
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().Volumes(ctx).Return(volumes, nil).Times(1)
+	mockAPI.EXPECT().ModifyVolumeExportPolicy(ctx, gomock.Any(), gomock.Any()).Times(0)
 
-	result := driver.Resize(ctx, volConfig, newSize)
+	result := driver.ReconcileNodeAccess(ctx, nodes, "", "")
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestResize_AboveMaximumSize(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	driver.Config.LimitVolumeSize = strconv.FormatInt(VolumeSizeI64+1, 10)
-	newSize := uint64(VolumeSizeI64 * 2)
+func TestValidate_DefaultUnixPermissionsMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		permissions string
+		valid       bool
+	}{
+		{"FeatureGated", UnixPermissionsModeFeatureGated, "", true},
+		{"Empty", UnixPermissionsModeEmpty, "", true},
+		{"Explicit", UnixPermissionsModeExplicit, "0755", true},
+		{"ExplicitMissing", UnixPermissionsModeExplicit, "", false},
+		{"ExplicitInvalid", UnixPermissionsModeExplicit, "0999", false},
+		{"PermissionsWithoutExplicit", UnixPermissionsModeEmpty, "0755", false},
+		{"InvalidMode", "always", "", false},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.DefaultUnixPermissionsMode = test.mode
+			driver.Config.DefaultUnixPermissions = test.permissions
 
-	result := driver.Resize(ctx, volConfig, newSize)
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
 }
 
-func TestResize_VolumeResizeFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
-	newSize := uint64(VolumeSizeI64 * 2)
+func TestValidate_FailedVolumeCleanup(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		valid bool
+	}{
+		{"Default", "", true},
+		{"Disabled", FailedVolumeCleanupDisabled, true},
+		{"DryRun", FailedVolumeCleanupDryRun, true},
+		{"Delete", FailedVolumeCleanupDelete, true},
+		{"InvalidMode", "always", false},
+	}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
-	mockAPI.EXPECT().ResizeVolume(ctx, filesystem, int64(newSize)).Return(errFailed).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.FailedVolumeCleanup = test.mode
 
-	result := driver.Resize(ctx, volConfig, newSize)
+			driver.populateConfigurationDefaults(ctx, &driver.Config)
+			driver.initializeStoragePools(ctx)
+			result := driver.validate(ctx)
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, VolumeSizeStr, volConfig.Size, "size mismatch")
-	assert.Equal(t, filesystem.ID, volConfig.InternalID, "internal ID not set on volConfig")
+			if test.valid {
+				assert.NoError(t, result, "validate failed")
+			} else {
+				assert.Error(t, result, "validate did not fail")
+			}
+		})
+	}
 }
 
-func TestGetStorageBackendSpecs(t *testing.T) {
+func TestValidate_InvalidAutoExportCIDRs(t *testing.T) {
 	_, driver := newMockANFDriver(t)
+	driver.Config.AutoExportPolicy = true
+	driver.Config.AutoExportCIDRs = []string{"10.0.0.0/33"}
 
 	driver.populateConfigurationDefaults(ctx, &driver.Config)
 	driver.initializeStoragePools(ctx)
-	driver.initializeTelemetry(ctx, BackendUUID)
+	result := driver.validate(ctx)
 
-	backend := &storage.StorageBackend{}
-	backend.SetStorage(make(map[string]storage.Pool))
+	assert.Error(t, result, "validate did not fail")
+}
 
-	result := driver.GetStorageBackendSpecs(ctx, backend)
+func TestHasUnscopedEmptyPrefix(t *testing.T) {
+	tests := []struct {
+		name           string
+		prefix         string
+		resourceGroups []string
+		netappAccounts []string
+		capacityPools  []string
+		expected       bool
+	}{
+		{"EmptyPrefixBroadScope", "", nil, nil, nil, true},
+		{"Prefix", "trident-", nil, nil, nil, false},
+		{"ResourceGroups", "", []string{"RG1"}, nil, nil, false},
+		{"NetappAccounts", "", nil, []string{"RG1/NA1"}, nil, false},
+		{"CapacityPools", "", nil, nil, []string{"RG1/NA1/CP1"}, false},
+	}
 
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, "azurenetappfiles_1-cli", backend.Name(), "backend name mismatch")
-	for _, pool := range driver.pools {
-		assert.Equal(t, backend, pool.Backend(), "pool-backend mismatch")
-		assert.Equal(t, pool, backend.Storage()["azurenetappfiles_1-cli_pool"], "backend-pool mismatch")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.Config.StoragePrefix = &test.prefix
+			driver.Config.ResourceGroups = test.resourceGroups
+			driver.Config.NetappAccounts = test.netappAccounts
+			driver.Config.CapacityPools = test.capacityPools
+
+			assert.Equal(t, test.expected, driver.hasUnscopedEmptyPrefix(), "unscoped empty prefix mismatch")
+		})
 	}
 }
 
-func TestOntapSanStorageDriverGetStorageBackendPools(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.Config.SubscriptionID = "deadbeef-173f-4bf4-b5b8-f17f8d2fe43b"
-	resourceGroup := "RG1"
-	account := "NA1"
-	location := "fake-location"
-	pools := []*api.CapacityPool{
+func TestValidateStoragePrefix(t *testing.T) {
+	tests := []struct {
+		Name          string
+		StoragePrefix string
+		Valid         bool
+	}{
+		// Invalid storage prefixes
+		{
+			Name:          "storage prefix starts with plus",
+			StoragePrefix: "+abcd-ABC",
+		},
+		{
+			Name:          "storage prefix starts with digit",
+			StoragePrefix: "1abcd-ABC",
+		},
+		{
+			Name:          "storage prefix starts with underscore",
+			StoragePrefix: "_abcd-ABC",
+		},
+		{
+			Name:          "storage prefix contains digits",
+			StoragePrefix: "abcd-123-ABC",
+		},
+		{
+			Name:          "storage prefix contains underscore",
+			StoragePrefix: "ABCD_abc",
+		},
+		{
+			Name:          "storage prefix has plus",
+			StoragePrefix: "abcd+ABC",
+		},
+		{
+			Name:          "storage prefix is single digit",
+			StoragePrefix: "1",
+		},
+		{
+			Name:          "storage prefix is single underscore",
+			StoragePrefix: "_",
+		},
+		{
+			Name:          "storage prefix is single colon",
+			StoragePrefix: ":",
+		},
+		{
+			Name:          "storage prefix is single dash",
+			StoragePrefix: "-",
+		},
+		// Valid storage prefixes
+		{
+			Name:          "storage prefix is single letter",
+			StoragePrefix: "a",
+			Valid:         true,
+		},
+		{
+			Name:          "storage prefix has only letters and dash",
+			StoragePrefix: "abcd-efgh",
+			Valid:         true,
+		},
+		{
+			Name:          "storage prefix ends with dash",
+			StoragePrefix: "abcd-efgh-",
+			Valid:         true,
+		},
+		{
+			Name:          "storage prefix has capital letters",
+			StoragePrefix: "ABCD",
+			Valid:         true,
+		},
 		{
-			Name:          "CP1",
-			Location:      location,
-			NetAppAccount: account,
-			ResourceGroup: resourceGroup,
+			Name:          "storage prefix has letters and capital letters",
+			StoragePrefix: "abcd-EFGH",
+			Valid:         true,
 		},
 		{
-			Name:          "CP2",
-			Location:      location,
-			NetAppAccount: account,
-			ResourceGroup: resourceGroup,
+			Name:          "storage prefix is empty",
+			StoragePrefix: "",
+			Valid:         true,
 		},
 	}
-
-	mockAPI.EXPECT().CapacityPoolsForStoragePools(ctx).Return(pools)
-
-	backendPools := driver.getStorageBackendPools(ctx)
-	assert.Equal(t, len(pools), len(backendPools))
-
-	// expected is an api.CapacityPools which contains all information present in the actual ANFStorageBackendPool
-	// (except for the subscription ID).
-	expected, actual := pools[0], backendPools[0]
-	assert.NotNil(t, expected)
-	assert.NotNil(t, actual)
-	assert.Equal(t, driver.Config.SubscriptionID, actual.SubscriptionID)
-	assert.Equal(t, expected.ResourceGroup, actual.ResourceGroup)
-	assert.Equal(t, expected.NetAppAccount, actual.NetappAccount)
-	assert.Equal(t, expected.Location, actual.Location)
-	assert.Equal(t, expected.Name, actual.CapacityPool)
-
-	expected, actual = pools[1], backendPools[1]
-	assert.NotNil(t, expected)
-	assert.NotNil(t, actual)
-	assert.Equal(t, driver.Config.SubscriptionID, actual.SubscriptionID)
-	assert.Equal(t, expected.ResourceGroup, actual.ResourceGroup)
-	assert.Equal(t, expected.NetAppAccount, actual.NetappAccount)
-	assert.Equal(t, expected.Location, actual.Location)
-	assert.Equal(t, expected.Name, actual.CapacityPool)
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := validateStoragePrefix(test.StoragePrefix)
+			if test.Valid {
+				assert.NoError(t, err, "should be valid")
+			} else {
+				assert.Error(t, err, "should be invalid")
+			}
+		})
+	}
 }
 
-func TestCreatePrepare(t *testing.T) {
-	_, driver := newMockANFDriver(t)
-
-	tridentconfig.UsingPassthroughStore = true
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
-
-	volConfig := &storage.VolumeConfig{Name: "testvol1"}
-
-	driver.CreatePrepare(ctx, volConfig)
+func TestCacheStatus(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 
-	assert.Equal(t, "myPrefix-testvol1", volConfig.InternalName)
-}
+	lastUpdateTime := time.Now().Add(-20 * time.Minute)
+	cacheStatus := &storage.BackendCacheStatus{
+		LastUpdateTime: lastUpdateTime,
+		Age:            20 * time.Minute,
+		MaxCacheAge:    api.DefaultMaxCacheAge,
+		Stale:          true,
+	}
 
-func TestGetStorageBackendPhysicalPoolNames(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+	mockAPI.EXPECT().CacheStatus().Return(cacheStatus).Times(1)
 
-	result := driver.GetStorageBackendPhysicalPoolNames(ctx)
+	result := driver.CacheStatus(ctx)
 
-	assert.Equal(t, []string{}, result, "physical pool names mismatch")
+	assert.Equal(t, lastUpdateTime, result.LastUpdateTime, "last update time mismatch")
+	assert.Equal(t, 20*time.Minute, result.Age, "age mismatch")
+	assert.Equal(t, api.DefaultMaxCacheAge, result.MaxCacheAge, "max cache age mismatch")
+	assert.True(t, result.Stale, "expected stale cache")
 }
 
-func TestGetInternalVolumeName_PassthroughStore(t *testing.T) {
+func TestCacheStatus_NoSDK(t *testing.T) {
 	_, driver := newMockANFDriver(t)
+	driver.SDK = nil
 
-	tridentconfig.UsingPassthroughStore = true
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
-
-	result := driver.GetInternalVolumeName(ctx, "testvol1")
-
-	assert.Equal(t, "myPrefix-testvol1", result, "internal name mismatch")
+	assert.Nil(t, driver.CacheStatus(ctx), "expected no cache status")
 }
 
-func TestGetInternalVolumeName_CSI(t *testing.T) {
-	_, driver := newMockANFDriver(t)
-
-	tridentconfig.UsingPassthroughStore = false
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+func TestVolumeUsage(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 
-	result := driver.GetInternalVolumeName(ctx, "pvc-5e522901-b891-41d8-9e83-5496d2e62e71")
+	volConfig := &storage.VolumeConfig{InternalName: "testvol1"}
+	filesystem := &api.FileSystem{
+		Name:          "testvol1",
+		CreationToken: "testvol1",
+		QuotaInBytes:  VolumeSizeI64,
+	}
+	cacheStatus := &storage.BackendCacheStatus{MaxCacheAge: api.DefaultMaxCacheAge}
 
-	assert.Equal(t, "pvc-5e522901-b891-41d8-9e83-5496d2e62e71", result, "internal name mismatch")
-}
+	mockAPI.EXPECT().CacheStatus().Return(cacheStatus).Times(3)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(VolumeSizeI64/4, VolumeSizeI64/10, nil).Times(1)
 
-func TestGetInternalVolumeName_NonCSI(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+	result, err := driver.VolumeUsage(ctx, volConfig)
 
-	tridentconfig.UsingPassthroughStore = false
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, VolumeSizeI64, result.ProvisionedBytes, "provisioned bytes mismatch")
+	assert.Equal(t, VolumeSizeI64/4, result.UsedBytes, "used bytes mismatch")
+	assert.Equal(t, VolumeSizeI64/10, result.SnapshotUsedBytes, "snapshot used bytes mismatch")
 
-	result := driver.GetInternalVolumeName(ctx, "testvol1")
+	// A second request within the maximum cache age is served from the cache
+	cachedResult, err := driver.VolumeUsage(ctx, volConfig)
 
-	anfRegex := regexp.MustCompile(`^anf-[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
-	assert.True(t, anfRegex.MatchString(result), "internal name mismatch")
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, result, cachedResult, "cached usage mismatch")
 }
 
-func TestCreateFollowup_NFSVolume(t *testing.T) {
+func TestVolumeUsage_MetricsFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "nfs"
 
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	volConfig := &storage.VolumeConfig{InternalName: "testvol1"}
+	filesystem := &api.FileSystem{
+		Name:          "testvol1",
+		CreationToken: "testvol1",
+		QuotaInBytes:  VolumeSizeI64,
+	}
+	cacheStatus := &storage.BackendCacheStatus{MaxCacheAge: api.DefaultMaxCacheAge}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(cacheStatus).Times(2)
 	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeUsedBytes(ctx, filesystem).Return(int64(0), int64(0), errFailed).Times(1)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result, err := driver.VolumeUsage(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, (filesystem.MountTargets)[0].IPAddress, volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "/"+filesystem.CreationToken, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
+	assert.Error(t, err, "expected error")
+	assert.Nil(t, result, "expected nil usage")
 }
 
-func TestCreateFollowup_NFSVolume_Kerberos_Type5(t *testing.T) {
+func TestVolumeUsage_VolumeNotFound(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5"
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
 
-	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	filesystem.KerberosEnabled = true
+	volConfig := &storage.VolumeConfig{InternalName: "testvol1"}
+	cacheStatus := &storage.BackendCacheStatus{MaxCacheAge: api.DefaultMaxCacheAge}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().CacheStatus().Return(cacheStatus).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errFailed).Times(1)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result, err := driver.VolumeUsage(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.NfsServerIP,
-		"server fqdn mismatch")
-	assert.Equal(t, "/"+filesystem.CreationToken, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
+	assert.Error(t, err, "expected error")
+	assert.Nil(t, result, "expected nil usage")
 }
 
-func TestCreateFollowup_NFSVolume_Kerberos_Type5_NotSet(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "nfs"
-	driver.Config.Kerberos = "sec=krb5"
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-
-	filesystem.MountTargets[0].ServerFqdn = "trident-1234.trident.com"
-	filesystem.ProtocolTypes = []string{api.ProtocolTypeNFSv41}
-	filesystem.KerberosEnabled = false
+func TestGetCommonConfig(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAPI := mockapi.NewMockAzure(mockCtrl)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	driver := newTestANFDriver(mockAPI)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result := driver.GetCommonConfig(ctx)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotEqual(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.NfsServerIP,
-		"server fqdn mismatch")
+	assert.Equal(t, driver.Config.CommonStorageDriverConfig, result, "common config mismatch")
 }
 
-func TestCreateFollowup_ROClone_NFSVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestConstructVolumeAccessPath(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "nfs"
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.CloneSourceVolumeInternal = volConfig.Name
-	volConfig.CloneSourceSnapshot = SnapshotUUID
-	volConfig.ReadOnlyClone = true
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(filesystem, nil).Times(1)
+	volConfig, fileSystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	tests := []struct {
+		Protocol string
+		Valid    bool
+	}{
+		// Valid protocols
+		{
+			Protocol: "nfs",
+			Valid:    true,
+		},
+		{
+			Protocol: "smb",
+			Valid:    true,
+		},
 
-	result := driver.CreateFollowup(ctx, volConfig)
+		// Invalid protocols
+		{
+			Protocol: "abc",
+			Valid:    false,
+		},
+		{
+			Protocol: "",
+			Valid:    false,
+		},
+	}
 
-	assert.Nil(t, result, "not nil")
-	assert.NoError(t, result, "error occurred")
-	assert.Equal(t, (filesystem.MountTargets)[0].IPAddress, volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.Equal(t, "/testvol1/.snapshot/deadbeef-5c0d-4afa-8cd8-afa3fba5665c", volConfig.AccessInfo.NfsPath,
-		"NFS path mismatch")
-	assert.Equal(t, "nfs", volConfig.FileSystem, "filesystem type mismatch")
+	for _, test := range tests {
+		t.Run(test.Protocol, func(t *testing.T) {
+			result := constructVolumeAccessPath(volConfig, fileSystem, test.Protocol)
+			if test.Valid {
+				assert.NotEmpty(t, result, "access path should not be empty")
+			} else {
+				assert.Empty(t, result, "access path should be empty")
+			}
+		})
+	}
 }
 
-func TestCreateFollowup_SMBVolume(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "smb"
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+func getStructsForMirror() (*api.FileSystem, string) {
+	sourceID := api.CreateVolumeID(SubscriptionID, "RG1", "NA1", "CP1", "testvol1")
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	destination := &api.FileSystem{
+		ID:                        api.CreateVolumeID(SubscriptionID, "RG2", "NA2", "CP2", "testvol1"),
+		ResourceGroup:             "RG2",
+		NetAppAccount:             "NA2",
+		CapacityPool:              "CP2",
+		Name:                      "testvol1",
+		CreationToken:             "trident-testvol1",
+		ProvisioningState:         api.StateAvailable,
+		VolumeType:                api.VolumeTypeDataProtection,
+		ReplicationEndpointType:   api.ReplicationEndpointTypeDestination,
+		ReplicationRemoteVolumeID: sourceID,
+		ReplicationSchedule:       api.ReplicationScheduleHourly,
+	}
 
-	assert.Nil(t, result, "not nil")
-	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
-	assert.Equal(t, "\\"+filesystem.CreationToken, volConfig.AccessInfo.SMBPath, "SMB path mismatch")
-	assert.Equal(t, "smb", volConfig.FileSystem, "filesystem type mismatch")
+	return destination, sourceID
 }
 
-func TestCreateFollowup_ROClone_SMBVolume(t *testing.T) {
+func TestEstablishMirror(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-	driver.Config.NASType = "smb"
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.CloneSourceVolumeInternal = volConfig.Name
-	volConfig.CloneSourceSnapshot = SnapshotUUID
-	volConfig.ReadOnlyClone = true
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{
+		MirrorState:        api.MirrorStateUninitialized,
+		RelationshipStatus: api.RelationshipStatusIdle,
+	}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().AuthorizeReplication(ctx, sourceID, destination).Return(nil).Times(1)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result := driver.EstablishMirror(ctx, "trident-testvol1", sourceID, "", "")
 
-	assert.Nil(t, result, "not nil")
-	assert.NoError(t, result, "error occurred")
-	assert.Equal(t, (filesystem.MountTargets)[0].ServerFqdn, volConfig.AccessInfo.SMBServer, "SMB server mismatch")
-	assert.Equal(t, "\\testvol1\\~snapshot\\deadbeef-5c0d-4afa-8cd8-afa3fba5665c", volConfig.AccessInfo.SMBPath,
-		"SMB path mismatch")
-	assert.Equal(t, "smb", volConfig.FileSystem, "filesystem type mismatch")
+	assert.True(t, errors.IsNotReadyError(result), "expected not ready error")
 }
 
-func TestCreateFollowup_DiscoveryFailed(t *testing.T) {
+func TestEstablishMirror_Established(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
-
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
-
-	result := driver.CreateFollowup(ctx, volConfig)
-
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
-	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{
+		MirrorState:        api.MirrorStateMirrored,
+		RelationshipStatus: api.RelationshipStatusIdle,
+		Healthy:            true,
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().AuthorizeReplication(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+	result := driver.EstablishMirror(ctx, "trident-testvol1", sourceID, "", "")
+
+	assert.NoError(t, result, "establish mirror failed")
 }
 
-func TestCreateFollowup_NonexistentVolume(t *testing.T) {
+func TestEstablishMirror_AuthorizeFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateUninitialized}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(nil, errors.NotFoundError("not found")).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().AuthorizeReplication(ctx, sourceID, destination).Return(errFailed).Times(1)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result := driver.EstablishMirror(ctx, "trident-testvol1", sourceID, "", "")
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
-	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
+	assert.Error(t, result, "expected error")
+	assert.False(t, errors.IsNotReadyError(result), "unexpected not ready error")
 }
 
-func TestCreateFollowup_ROClone_NonexistentVolume(t *testing.T) {
+func TestEstablishMirror_NotDataProtectionVolume(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, _, _ := getStructsForPublishNFSVolume(ctx, driver)
-	volConfig.CloneSourceVolumeInternal = volConfig.Name
-	volConfig.ReadOnlyClone = true
+	destination, sourceID := getStructsForMirror()
+	destination.VolumeType = ""
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, volConfig.CloneSourceVolumeInternal).Return(nil,
-		errors.NotFoundError("not found")).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, gomock.Any()).Times(0)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	result := driver.EstablishMirror(ctx, "trident-testvol1", sourceID, "", "")
 
-	assert.NotNil(t, result, "received nil")
 	assert.Error(t, result, "expected error")
-	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
-	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
 }
 
-func TestCreateFollowup_VolumeNotAvailable(t *testing.T) {
-	nonAvailableStates := []string{
-		api.StateAccepted, api.StateCreating, api.StateDeleting, api.StateDeleted,
-		api.StateMoving, api.StateError, api.StateReverting,
-	}
+func TestReestablishMirror(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateBroken}
 
-	for _, state := range nonAvailableStates {
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().ResyncReplication(ctx, destination).Return(nil).Times(1)
 
-		mockAPI, driver := newMockANFDriver(t)
-		driver.initializeTelemetry(ctx, BackendUUID)
+	result := driver.ReestablishMirror(ctx, "trident-testvol1", sourceID, "", "")
 
-		volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-		filesystem.ProvisioningState = state
+	assert.True(t, errors.IsNotReadyError(result), "expected not ready error")
+}
 
-		mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-		mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+func TestReestablishMirror_Established(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateMirrored}
 
-		result := driver.CreateFollowup(ctx, volConfig)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().ResyncReplication(ctx, gomock.Any()).Times(0)
 
-		assert.NotNil(t, result, "expected error")
-		assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-		assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
-		assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-		assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
-	}
+	result := driver.ReestablishMirror(ctx, "trident-testvol1", sourceID, "", "")
+
+	assert.NoError(t, result, "reestablish mirror failed")
 }
 
-func TestCreateFollowup_NoMountTargets(t *testing.T) {
+func TestPromoteMirror(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-	driver.initializeTelemetry(ctx, BackendUUID)
-
-	volConfig, filesystem, _ := getStructsForPublishNFSVolume(ctx, driver)
-	filesystem.MountTargets = nil
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateMirrored}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().BreakReplication(ctx, destination).Return(nil).Times(1)
 
-	result := driver.CreateFollowup(ctx, volConfig)
+	wait, result := driver.PromoteMirror(ctx, "trident-testvol1", sourceID, "")
 
-	assert.NotNil(t, result, "expected error")
-	assert.Equal(t, "", volConfig.AccessInfo.NfsServerIP, "NFS server IP mismatch")
-	assert.NotEqual(t, "", volConfig.AccessInfo.NfsPath, "NFS path is empty")
-	assert.Equal(t, "/"+volConfig.InternalName, volConfig.AccessInfo.NfsPath, "NFS path mismatch")
-	assert.Equal(t, "", volConfig.FileSystem, "filesystem type mismatch")
+	assert.NoError(t, result, "promote mirror failed")
+	assert.False(t, wait, "unexpected wait")
 }
 
-func TestGetProtocol(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+func TestPromoteMirror_WaitForSnapshot(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateMirrored}
+	snapshotHandle := storage.MakeSnapshotID("pvc-1", "snap1")
 
-	result := driver.GetProtocol(ctx)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().SnapshotForVolume(ctx, destination, "snap1").Return(nil,
+		errors.NotFoundError("not found")).Times(1)
+	mockAPI.EXPECT().BreakReplication(ctx, gomock.Any()).Times(0)
 
-	assert.Equal(t, tridentconfig.File, result)
-}
+	wait, result := driver.PromoteMirror(ctx, "trident-testvol1", sourceID, snapshotHandle)
 
-func TestStoreConfig(t *testing.T) {
-	commonConfig := &drivers.CommonStorageDriverConfig{
-		Version:           1,
-		StorageDriverName: "azure-netapp-files",
-		BackendName:       "myANFBackend",
-		DriverContext:     tridentconfig.ContextCSI,
-		DebugTraceFlags:   debugTraceFlags,
-	}
+	assert.NoError(t, result, "promote mirror failed")
+	assert.True(t, wait, "expected wait for snapshot")
+}
 
-	_, driver := newMockANFDriver(t)
-	driver.Config.CommonStorageDriverConfig = commonConfig
+func TestPromoteMirror_AlreadyPromoted(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	destination, sourceID := getStructsForMirror()
+	status := &api.ReplicationStatus{MirrorState: api.MirrorStateBroken}
 
-	persistentConfig := &storage.PersistentStorageBackendConfig{}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+	mockAPI.EXPECT().BreakReplication(ctx, gomock.Any()).Times(0)
 
-	driver.StoreConfig(ctx, persistentConfig)
+	wait, result := driver.PromoteMirror(ctx, "trident-testvol1", sourceID, "")
 
-	assert.Equal(t, json.RawMessage("{}"), driver.Config.CommonStorageDriverConfig.StoragePrefixRaw,
-		"raw prefix mismatch")
-	assert.Equal(t, driver.Config, *persistentConfig.AzureConfig, "azure config mismatch")
+	assert.NoError(t, result, "promote mirror failed")
+	assert.False(t, wait, "unexpected wait")
 }
 
-func TestGetVolumeExternal(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestGetMirrorStatus(t *testing.T) {
+	tests := []struct {
+		mirrorState        string
+		relationshipStatus string
+		expected           string
+	}{
+		{api.MirrorStateUninitialized, api.RelationshipStatusTransferring, v1.MirrorStateEstablishing},
+		{api.MirrorStateMirrored, api.RelationshipStatusIdle, v1.MirrorStateEstablished},
+		{api.MirrorStateBroken, api.RelationshipStatusIdle, v1.MirrorStatePromoted},
+		{api.MirrorStateBroken, api.RelationshipStatusTransferring, v1.MirrorStateEstablishing},
+		{"Unknown", api.RelationshipStatusIdle, ""},
+	}
 
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	for _, test := range tests {
+		t.Run(test.mirrorState+"_"+test.relationshipStatus, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			destination, sourceID := getStructsForMirror()
+			status := &api.ReplicationStatus{
+				MirrorState:        test.mirrorState,
+				RelationshipStatus: test.relationshipStatus,
+			}
 
-	filesystem := &api.FileSystem{
-		Name:              "testvol1",
-		CreationToken:     "myPrefix-testvol1",
-		ProvisioningState: api.StateAvailable,
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
+			mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+
+			result, err := driver.GetMirrorStatus(ctx, "trident-testvol1", sourceID)
+
+			assert.NoError(t, err, "get mirror status failed")
+			assert.Equal(t, test.expected, result)
+		})
 	}
+}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(filesystem, nil).Times(1)
+func TestGetMirrorStatus_NoRemote(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 
-	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
+	result, err := driver.GetMirrorStatus(ctx, "trident-testvol1", "")
 
-	assert.Nil(t, resultErr, "not nil")
-	assert.IsType(t, &storage.VolumeExternal{}, result, "type mismatch")
-	assert.Equal(t, "1", result.Config.Version)
-	assert.Equal(t, "testvol1", result.Config.Name)
-	assert.Equal(t, "myPrefix-testvol1", result.Config.InternalName)
+	assert.NoError(t, err, "get mirror status failed")
+	assert.Equal(t, "", result)
 }
 
-func TestGetVolumeExternal_DiscoveryFailed(t *testing.T) {
-	mockAPI, driver := newMockANFDriver(t)
+func TestVolumeReplicationStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		healthy            bool
+		mirrorState        string
+		relationshipStatus string
+		lastTransferAge    time.Duration
+		expected           string
+	}{
+		{"healthy", true, api.MirrorStateMirrored, api.RelationshipStatusIdle, 30 * time.Minute,
+			ReplicationStateHealthy},
+		{"lagging", true, api.MirrorStateMirrored, api.RelationshipStatusIdle, 3 * time.Hour,
+			ReplicationStateLagging},
+		{"broken", true, api.MirrorStateBroken, api.RelationshipStatusIdle, 30 * time.Minute,
+			ReplicationStateBroken},
+		{"unhealthy", false, api.MirrorStateMirrored, api.RelationshipStatusIdle, 30 * time.Minute,
+			ReplicationStateBroken},
+		{"initializing", true, api.MirrorStateUninitialized, api.RelationshipStatusTransferring, 0,
+			ReplicationStateInitializing},
+	}
 
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			destination, _ := getStructsForMirror()
+			volConfig := &storage.VolumeConfig{InternalName: "trident-testvol1"}
+			status := &api.ReplicationStatus{
+				Healthy:            test.healthy,
+				MirrorState:        test.mirrorState,
+				RelationshipStatus: test.relationshipStatus,
+			}
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+			lastTransferTime := time.Now().Add(-test.lastTransferAge)
+			snapshots := []*api.Snapshot{
+				{Name: "snap1", Created: time.Now()},
+				{Name: "snapmirror.old", Created: lastTransferTime.Add(-time.Hour)},
+			}
+			if test.lastTransferAge > 0 {
+				snapshots = append(snapshots, &api.Snapshot{Name: "snapmirror.new", Created: lastTransferTime})
+			}
 
-	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, volConfig).Return(destination, nil).Times(1)
+			mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(status, nil).Times(1)
+			mockAPI.EXPECT().SnapshotsForVolume(ctx, destination).Return(&snapshots, nil).Times(1)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "error expected")
+			result, err := driver.VolumeReplicationStatus(ctx, volConfig)
+
+			assert.NoError(t, err, "get replication status failed")
+			assert.Equal(t, test.expected, result.State, "state mismatch")
+			assert.Equal(t, api.ReplicationEndpointTypeDestination, result.EndpointType, "endpoint type mismatch")
+			assert.Equal(t, test.mirrorState, result.MirrorState, "mirror state mismatch")
+			if test.lastTransferAge > 0 {
+				assert.True(t, lastTransferTime.Equal(*result.LastTransferTime), "last transfer time mismatch")
+				assert.GreaterOrEqual(t, result.Lag, test.lastTransferAge-time.Second, "lag too small")
+			}
+		})
+	}
 }
 
-func TestGetVolumeExternal_GetFailed(t *testing.T) {
+func TestVolumeReplicationStatus_NotReplicated(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().VolumeByCreationToken(ctx, "testvol1").Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, gomock.Any()).Times(0)
+	mockAPI.EXPECT().SnapshotsForVolume(ctx, gomock.Any()).Times(0)
 
-	result, resultErr := driver.GetVolumeExternal(ctx, "testvol1")
+	result, err := driver.VolumeReplicationStatus(ctx, volConfig)
 
-	assert.Nil(t, result, "not nil")
-	assert.NotNil(t, resultErr, "error expected")
+	assert.NoError(t, err, "get replication status failed")
+	assert.Equal(t, ReplicationStateNone, result.State, "state mismatch")
+	assert.Nil(t, result.LastTransferTime, "unexpected last transfer time")
 }
 
-func TestGetVolumeExternalWrappers(t *testing.T) {
+func TestVolumeReplicationStatus_StatusFailed(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
-
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
-
-	filesystems := getVolumesForList()
-	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
+	destination, _ := getStructsForMirror()
+	volConfig := &storage.VolumeConfig{InternalName: "trident-testvol1"}
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Return(filesystems, nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(destination, nil).Times(1)
+	mockAPI.EXPECT().ReplicationStatus(ctx, destination).Return(nil, errFailed).Times(1)
 
-	driver.GetVolumeExternalWrappers(ctx, channel)
-
-	// Read the volumes from the channel
-	volumes := make([]*storage.VolumeExternal, 0)
-	for wrapper := range channel {
-		if wrapper.Error != nil {
-			t.FailNow()
-		} else {
-			volumes = append(volumes, wrapper.Volume)
-		}
-	}
+	result, err := driver.VolumeReplicationStatus(ctx, volConfig)
 
-	assert.Len(t, volumes, 2, "wrong number of volumes")
+	assert.Error(t, err, "expected error")
+	assert.Nil(t, result, "expected nil result")
 }
 
-func TestGetVolumeExternalWrappers_DiscoveryFailed(t *testing.T) {
+func TestGetReplicationDetails(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	destination, sourceID := getStructsForMirror()
 
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeByCreationToken(ctx, "trident-testvol1").Return(destination, nil).Times(1)
 
-	filesystems := getVolumesForList()
-	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
+	policy, schedule, svm, err := driver.GetReplicationDetails(ctx, "trident-testvol1", sourceID)
 
-	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(errFailed).Times(1)
+	assert.NoError(t, err, "get replication details failed")
+	assert.Equal(t, "", policy)
+	assert.Equal(t, api.ReplicationScheduleHourly, schedule)
+	assert.Equal(t, "", svm)
+}
 
-	driver.GetVolumeExternalWrappers(ctx, channel)
+func TestUpdateMirror_Unsupported(t *testing.T) {
+	_, driver := newMockANFDriver(t)
 
-	// Read the volumes from the channel
-	var result error
-	for wrapper := range channel {
-		if wrapper.Error != nil {
-			result = wrapper.Error
-			break
-		}
-	}
+	result := driver.UpdateMirror(ctx, "trident-testvol1", "snap1")
 
-	assert.NotNil(t, result, "expected error")
+	assert.True(t, errors.IsUnsupportedError(result), "expected unsupported error")
 }
 
-func TestGetVolumeExternalWrappers_ListFailed(t *testing.T) {
+func TestDestroy_MirrorDestination(t *testing.T) {
 	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	storagePrefix := "myPrefix-"
-	driver.Config.StoragePrefix = &storagePrefix
-
-	filesystems := getVolumesForList()
-	channel := make(chan *storage.VolumeExternalWrapper, len(*filesystems))
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.VolumeType = api.VolumeTypeDataProtection
+	filesystem.ReplicationEndpointType = api.ReplicationEndpointTypeDestination
 
 	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
-	mockAPI.EXPECT().Volumes(ctx).Return(nil, errFailed).Times(1)
-
-	driver.GetVolumeExternalWrappers(ctx, channel)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteReplication(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateDeleted, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateDeleted, nil).Times(1)
 
-	// Read the volumes from the channel
-	var result error
-	for wrapper := range channel {
-		if wrapper.Error != nil {
-			result = wrapper.Error
-			break
-		}
-	}
+	result := driver.Destroy(ctx, volConfig)
 
-	assert.NotNil(t, result, "expected error")
+	assert.Nil(t, result, "not nil")
 }
 
-func TestStringAndGoString(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+func TestDestroy_MirrorDestination_DeleteReplicationFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	stringFunc := func(d *NASStorageDriver) string { return d.String() }
-	goStringFunc := func(d *NASStorageDriver) string { return d.GoString() }
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.ReplicationEndpointType = api.ReplicationEndpointTypeDestination
 
-	for _, toString := range []func(*NASStorageDriver) string{stringFunc, goStringFunc} {
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(true, filesystem, nil).Times(1)
+	mockAPI.EXPECT().DeleteReplication(ctx, filesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Times(0)
 
-		assert.Contains(t, toString(driver), "<REDACTED>",
-			"ANF driver does not contain <REDACTED>")
-		assert.Contains(t, toString(driver), "SDK:<REDACTED>",
-			"ANF driver does not redact SDK information")
-		assert.Contains(t, toString(driver), "SubscriptionID:<REDACTED>",
-			"ANF driver does not redact API URL")
-		assert.Contains(t, toString(driver), "TenantID:<REDACTED>",
-			"ANF driver does not redact Tenant ID")
-		assert.Contains(t, toString(driver), "ClientID:<REDACTED>",
-			"ANF driver does not redact Client ID")
-		assert.Contains(t, toString(driver), "ClientSecret:<REDACTED>",
-			"ANF driver does not redact Client Secret")
-		assert.NotContains(t, toString(driver), SubscriptionID,
-			"ANF driver contains Subscription ID")
-		assert.NotContains(t, toString(driver), TenantID,
-			"ANF driver contains Tenant ID")
-		assert.NotContains(t, toString(driver), ClientID,
-			"ANF driver contains Client ID")
-		assert.NotContains(t, toString(driver), ClientSecret,
-			"ANF driver contains Client Secret")
-	}
+	result := driver.Destroy(ctx, volConfig)
+
+	assert.Error(t, result, "expected error")
 }
 
-func TestGetUpdateType_NoFlaggedChanges(t *testing.T) {
-	_, oldDriver := newMockANFDriver(t)
-	oldDriver.volumeCreateTimeout = 1 * time.Second
+func TestCreate_NFSVolume_MirrorDestination(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+	driver.Config.ReplicationSchedule = api.ReplicationScheduleDaily
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	storagePool := driver.pools["anf_pool"]
+	_, sourceID := getStructsForMirror()
 
-	_, newDriver := newMockANFDriver(t)
-	newDriver.volumeCreateTimeout = 2 * time.Second
+	volConfig, capacityPool, subnet, createRequest, filesystem := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.IsMirrorDestination = true
+	volConfig.PeerVolumeHandle = sourceID
+	createRequest.UnixPermissions = "0777"
+	createRequest.ReplicationSourceID = sourceID
+	createRequest.ReplicationSchedule = api.ReplicationScheduleDaily
+	filesystem.UnixPermissions = "0777"
 
-	result := newDriver.GetUpdateType(ctx, oldDriver)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).Times(1)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool,
+		api.ServiceLevelUltra).Return([]*api.CapacityPool{capacityPool}).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, createRequest).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
 
-	expectedBitmap := &roaring.Bitmap{}
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
+	assert.NoError(t, result, "create failed")
+	assert.True(t, storagePool.Attributes()[sa.Replication].ToString() == "true", "replication not offered")
 }
 
-func TestGetUpdateType_WrongDriverType(t *testing.T) {
-	oldDriver := &fake.StorageDriver{
-		Config:             drivers.FakeStorageDriverConfig{},
-		Volumes:            make(map[string]storagefake.Volume),
-		DestroyedVolumes:   make(map[string]bool),
-		Snapshots:          make(map[string]map[string]*storage.Snapshot),
-		DestroyedSnapshots: make(map[string]bool),
-		Secret:             "secret",
-	}
+func TestCreate_NFSVolume_MirrorDestination_ReplicationNotConfigured(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
 
-	_, newDriver := newMockANFDriver(t)
-	newDriver.volumeCreateTimeout = 2 * time.Second
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
 
-	result := newDriver.GetUpdateType(ctx, oldDriver)
+	storagePool := driver.pools["anf_pool"]
+	_, sourceID := getStructsForMirror()
 
-	expectedBitmap := &roaring.Bitmap{}
-	expectedBitmap.Add(storage.InvalidUpdate)
+	volConfig, _, _, _, _ := getStructsForCreateNFSVolume(ctx, driver, storagePool)
+	volConfig.IsMirrorDestination = true
+	volConfig.PeerVolumeHandle = sourceID
 
-	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
-}
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, volConfig).Return(false, nil, nil).Times(1)
+	mockAPI.EXPECT().CreateVolume(ctx, gomock.Any()).Times(0)
 
-func TestGetUpdateType_OtherChanges(t *testing.T) {
-	_, oldDriver := newMockANFDriver(t)
-	prefix1 := "prefix1-"
-	oldDriver.Config.StoragePrefix = &prefix1
-	oldDriver.Config.Credentials = map[string]string{
-		drivers.KeyName: "secret1",
-		drivers.KeyType: string(drivers.CredentialStoreK8sSecret),
-	}
+	result := driver.Create(ctx, volConfig, storagePool, nil)
 
-	_, newDriver := newMockANFDriver(t)
-	prefix2 := "prefix2-"
-	newDriver.Config.StoragePrefix = &prefix2
-	newDriver.Config.Credentials = map[string]string{
-		drivers.KeyName: "secret2",
-		drivers.KeyType: string(drivers.CredentialStoreK8sSecret),
-	}
+	assert.Error(t, result, "create did not fail")
+}
 
-	result := newDriver.GetUpdateType(ctx, oldDriver)
+func TestValidate_InvalidReplicationSchedule(t *testing.T) {
+	_, driver := newMockANFDriver(t)
+	driver.Config.ReplicationSchedule = "weekly"
 
-	expectedBitmap := &roaring.Bitmap{}
-	expectedBitmap.Add(storage.PrefixChange)
-	expectedBitmap.Add(storage.CredentialsChange)
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	result := driver.validate(ctx)
 
-	assert.Equal(t, expectedBitmap, result, "bitmap mismatch")
+	assert.Error(t, result, "validate did not fail")
 }
 
-func TestReconcileNodeAccess(t *testing.T) {
-	mockCtrl := gomock.NewController(t)
-	mockAPI := mockapi.NewMockAzure(mockCtrl)
+func TestUpdate_NetworkFeaturesUpgrade(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
 
-	driver := *newTestANFDriver(mockAPI)
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.NetworkFeatures = api.NetworkFeaturesBasic
+	updateInfo := &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}
 
-	result := driver.ReconcileNodeAccess(ctx, nil, "", "")
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().UpgradeVolumeNetworkFeatures(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	result, err := driver.Update(ctx, volConfig, updateInfo, map[string]*storage.Volume{})
 
+	assert.NoError(t, err, "unexpected error")
 	assert.Nil(t, result, "not nil")
 }
 
-func TestValidateStoragePrefix(t *testing.T) {
+func TestUpdate_NetworkFeaturesFromStoragePool(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NetworkFeatures = api.NetworkFeaturesBasic
+
+	pool := storage.NewStoragePool(nil, "myANFBackend_pool_0")
+	pool.InternalAttributes()[NetworkFeatures] = api.NetworkFeaturesStandard
+	driver.pools = map[string]storage.Pool{pool.Name(): pool}
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.NetworkFeatures = api.NetworkFeaturesBasic
+	updateInfo := &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}
+	allVolumes := map[string]*storage.Volume{
+		volConfig.Name: {Config: volConfig, Pool: pool.Name()},
+	}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().UpgradeVolumeNetworkFeatures(ctx, filesystem).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.defaultTimeout()).Return(api.StateAvailable, nil).Times(1)
+
+	_, err := driver.Update(ctx, volConfig, updateInfo, allVolumes)
+
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdate_NetworkFeaturesUnchanged(t *testing.T) {
 	tests := []struct {
-		Name          string
-		StoragePrefix string
-		Valid         bool
+		name    string
+		backend string
+		volume  string
 	}{
-		// Invalid storage prefixes
-		{
-			Name:          "storage prefix starts with plus",
-			StoragePrefix: "+abcd-ABC",
-		},
-		{
-			Name:          "storage prefix starts with digit",
-			StoragePrefix: "1abcd-ABC",
-		},
-		{
-			Name:          "storage prefix starts with underscore",
-			StoragePrefix: "_abcd-ABC",
-		},
-		{
-			Name:          "storage prefix contains digits",
-			StoragePrefix: "abcd-123-ABC",
-		},
-		{
-			Name:          "storage prefix contains underscore",
-			StoragePrefix: "ABCD_abc",
-		},
-		{
-			Name:          "storage prefix has plus",
-			StoragePrefix: "abcd+ABC",
-		},
-		{
-			Name:          "storage prefix is single digit",
-			StoragePrefix: "1",
-		},
-		{
-			Name:          "storage prefix is single underscore",
-			StoragePrefix: "_",
-		},
-		{
-			Name:          "storage prefix is single colon",
-			StoragePrefix: ":",
-		},
-		{
-			Name:          "storage prefix is single dash",
-			StoragePrefix: "-",
-		},
-		// Valid storage prefixes
-		{
-			Name:          "storage prefix is single letter",
-			StoragePrefix: "a",
-			Valid:         true,
-		},
-		{
-			Name:          "storage prefix has only letters and dash",
-			StoragePrefix: "abcd-efgh",
-			Valid:         true,
-		},
-		{
-			Name:          "storage prefix ends with dash",
-			StoragePrefix: "abcd-efgh-",
-			Valid:         true,
-		},
-		{
-			Name:          "storage prefix has capital letters",
-			StoragePrefix: "ABCD",
-			Valid:         true,
-		},
-		{
-			Name:          "storage prefix has letters and capital letters",
-			StoragePrefix: "abcd-EFGH",
-			Valid:         true,
-		},
-		{
-			Name:          "storage prefix is empty",
-			StoragePrefix: "",
-			Valid:         true,
-		},
+		{"unset", "", api.NetworkFeaturesBasic},
+		{"basic", api.NetworkFeaturesBasic, api.NetworkFeaturesBasic},
+		{"standard", api.NetworkFeaturesStandard, api.NetworkFeaturesStandard},
 	}
+
 	for _, test := range tests {
-		t.Run(test.Name, func(t *testing.T) {
-			err := validateStoragePrefix(test.StoragePrefix)
-			if test.Valid {
-				assert.NoError(t, err, "should be valid")
-			} else {
-				assert.Error(t, err, "should be invalid")
-			}
+		t.Run(test.name, func(t *testing.T) {
+			mockAPI, driver := newMockANFDriver(t)
+			driver.initializeTelemetry(ctx, BackendUUID)
+			driver.Config.NetworkFeatures = test.backend
+
+			volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+			filesystem.NetworkFeatures = test.volume
+			updateInfo := &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}
+
+			mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+			mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+			mockAPI.EXPECT().UpgradeVolumeNetworkFeatures(ctx, gomock.Any()).Times(0)
+
+			_, err := driver.Update(ctx, volConfig, updateInfo, map[string]*storage.Volume{})
+
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.volume, filesystem.NetworkFeatures, "network features changed")
 		})
 	}
 }
 
-func TestGetCommonConfig(t *testing.T) {
-	mockCtrl := gomock.NewController(t)
-	mockAPI := mockapi.NewMockAzure(mockCtrl)
+func TestUpdate_NetworkFeaturesDowngrade(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.initializeTelemetry(ctx, BackendUUID)
+	driver.Config.NetworkFeatures = api.NetworkFeaturesBasic
 
-	driver := *newTestANFDriver(mockAPI)
-	driver.Config.BackendName = "anf"
-	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.NetworkFeatures = api.NetworkFeaturesStandard
+	updateInfo := &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}
 
-	result := driver.GetCommonConfig(ctx)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().UpgradeVolumeNetworkFeatures(ctx, gomock.Any()).Times(0)
 
-	assert.Equal(t, driver.Config.CommonStorageDriverConfig, result, "common config mismatch")
+	_, err := driver.Update(ctx, volConfig, updateInfo, map[string]*storage.Volume{})
+
+	assert.Error(t, err, "expected error")
+	assert.True(t, errors.IsUnsupportedError(err), "not an unsupported error")
 }
 
-func TestConstructVolumeAccessPath(t *testing.T) {
-	_, driver := newMockANFDriver(t)
+func TestUpdate_NetworkFeaturesUpgradeFailed(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
 	driver.initializeTelemetry(ctx, BackendUUID)
-	volConfig, fileSystem, _ := getStructsForPublishNFSVolume(ctx, driver)
+	driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+
+	volConfig, filesystem := getStructsForDestroyNFSVolume(ctx, driver)
+	filesystem.NetworkFeatures = api.NetworkFeaturesBasic
+	updateInfo := &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}
+
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().Volume(ctx, volConfig).Return(filesystem, nil).Times(1)
+	mockAPI.EXPECT().UpgradeVolumeNetworkFeatures(ctx, filesystem).Return(errFailed).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := driver.Update(ctx, volConfig, updateInfo, map[string]*storage.Volume{})
+
+	assert.Error(t, err, "expected error")
+}
+
+func TestUpdate_InvalidRequest(t *testing.T) {
 	tests := []struct {
-		Protocol string
-		Valid    bool
+		name        string
+		updateInfo  *utils.VolumeUpdateInfo
+		notManaged  bool
+		unsupported bool
 	}{
-		// Valid protocols
-		{
-			Protocol: "nfs",
-			Valid:    true,
-		},
-		{
-			Protocol: "smb",
-			Valid:    true,
-		},
-
-		// Invalid protocols
-		{
-			Protocol: "abc",
-			Valid:    false,
-		},
-		{
-			Protocol: "",
-			Valid:    false,
-		},
+		{"nil", nil, false, false},
+		{"empty", &utils.VolumeUpdateInfo{}, false, false},
+		{"snapshotDir", &utils.VolumeUpdateInfo{SnapshotDirectory: "true", UpgradeNetworkFeatures: true}, false, true},
+		{"unmanaged", &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true}, true, true},
 	}
 
 	for _, test := range tests {
-		t.Run(test.Protocol, func(t *testing.T) {
-			result := constructVolumeAccessPath(volConfig, fileSystem, test.Protocol)
-			if test.Valid {
-				assert.NotEmpty(t, result, "access path should not be empty")
-			} else {
-				assert.Empty(t, result, "access path should be empty")
-			}
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			driver.initializeTelemetry(ctx, BackendUUID)
+			driver.Config.NetworkFeatures = api.NetworkFeaturesStandard
+
+			volConfig, _ := getStructsForDestroyNFSVolume(ctx, driver)
+			volConfig.ImportNotManaged = test.notManaged
+
+			_, err := driver.Update(ctx, volConfig, test.updateInfo, map[string]*storage.Volume{})
+
+			assert.Error(t, err, "expected error")
+			assert.Equal(t, test.unsupported, errors.IsUnsupportedError(err), "unexpected error type")
 		})
 	}
 }
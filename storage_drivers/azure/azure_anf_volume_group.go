@@ -0,0 +1,423 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"go.uber.org/multierr"
+
+	"github.com/netapp/trident/acp"
+	. "github.com/netapp/trident/logging"
+	"github.com/netapp/trident/storage"
+	sa "github.com/netapp/trident/storage_attribute"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/azure/api"
+	"github.com/netapp/trident/utils"
+	"github.com/netapp/trident/utils/errors"
+)
+
+var sapSystemIDRegex = regexp.MustCompile(`^[A-Z][A-Z\d]{2}$`)
+
+// ApplicationVolumeGroup describes an ANF application volume group, whose member volumes are created together
+// and placed by ANF according to the needs of the application.  Only SAP HANA groups are supported.
+type ApplicationVolumeGroup struct {
+	// Name is the name of the volume group, which must be unique within its NetApp account
+	Name string
+	// ApplicationType is the application served by the group, which defaults to SAP HANA
+	ApplicationType string
+	// ApplicationIdentifier is the SAP system ID (SID) of the SAP HANA database
+	ApplicationIdentifier string
+	// ProximityPlacementGroup is the resource ID of the proximity placement group of the SAP HANA hosts,
+	// which ANF uses to place the volumes close to them
+	ProximityPlacementGroup string
+	Description             string
+	Volumes                 []*ApplicationVolume
+}
+
+// ApplicationVolume is a member volume of an application volume group.
+type ApplicationVolume struct {
+	// SpecName is the volume's role in the application, such as data, log, or shared
+	SpecName string
+	// ThroughputMibps is the volume's throughput, since group members live in manual QoS capacity pools
+	ThroughputMibps float32
+	VolumeConfig    *storage.VolumeConfig
+}
+
+// CreateApplicationVolumeGroup creates the member volumes of an application volume group in one request,
+// waits for all of them to become available, and returns their access info keyed by volume name.  The
+// members are placed in the first matching manual QoS capacity pool in which the group can be created.
+// Unlike single volumes, group members are always created synchronously.
+func (d *NASStorageDriver) CreateApplicationVolumeGroup(
+	ctx context.Context, group *ApplicationVolumeGroup, storagePool storage.Pool,
+) (map[string]utils.VolumeAccessInfo, error) {
+	fields := LogFields{
+		"Method":      "CreateApplicationVolumeGroup",
+		"Type":        "NASStorageDriver",
+		"volumeGroup": group.Name,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(
+		">>>> CreateApplicationVolumeGroup")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(
+		"<<<< CreateApplicationVolumeGroup")
+
+	// Application volume groups are only available with ACP
+	if err := acp.API().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups); err != nil {
+		Logc(ctx).WithField(
+			"feature", acp.FeatureApplicationVolumeGroups,
+		).WithError(err).Error("Failed to create volume group.")
+		return nil, fmt.Errorf("feature %s requires ACP; %w", acp.FeatureApplicationVolumeGroups, err)
+	}
+
+	// Update resource cache as needed
+	if err := d.refreshAzureResources(ctx); err != nil {
+		return nil, fmt.Errorf("could not update ANF resource cache; %v", err)
+	}
+
+	if group.ApplicationType == "" {
+		group.ApplicationType = api.ApplicationTypeSAPHANA
+	}
+	if err := d.validateApplicationVolumeGroup(group); err != nil {
+		return nil, err
+	}
+
+	// SAP HANA volumes are NFSv4.1 volumes, so SMB and dual-protocol backends can't host them
+	if d.Config.NASType != sa.NFS {
+		return nil, fmt.Errorf("volume groups are only supported for NFS volumes, not SMB or dual-protocol")
+	}
+
+	// Get the pool since most default values are pool-specific
+	if storagePool == nil {
+		return nil, errors.New("pool not specified")
+	}
+	pool, ok := d.getPool(storagePool.Name())
+	if !ok {
+		return nil, fmt.Errorf("pool %s does not exist", storagePool.Name())
+	}
+
+	// If any member volume already exists, bail out
+	for _, member := range group.Volumes {
+		volumeExists, _, err := d.SDK.VolumeExists(ctx, member.VolumeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error checking for existing volume %s; %v", member.VolumeConfig.InternalName,
+				err)
+		}
+		if volumeExists {
+			return nil, drivers.NewVolumeExistsError(member.VolumeConfig.InternalName)
+		}
+	}
+
+	// Determine the size of each member volume in bytes
+	sizes := make([]uint64, len(group.Volumes))
+	var totalBytes uint64
+	for i, member := range group.Volumes {
+		sizeBytes, err := d.applicationVolumeSize(ctx, member.VolumeConfig, pool)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = sizeBytes
+		totalBytes += sizeBytes
+	}
+
+	serviceLevel := pool.InternalAttributes()[ServiceLevel]
+	if serviceLevel == "" {
+		serviceLevel = utils.Title(d.Config.DefaultServiceLevel)
+	}
+
+	snapshotDirBool, err := strconv.ParseBool(pool.InternalAttributes()[SnapshotDir])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for snapshotDir; %v", err)
+	}
+
+	unixPermissions := pool.InternalAttributes()[UnixPermissions]
+	if unixPermissions == "" {
+		unixPermissions = d.defaultVolumeUnixPermissions()
+	}
+
+	exportPolicy, err := d.applicationVolumeExportPolicy(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Custom tags go first so that any tag set by Trident takes precedence
+	labels := d.getCustomTags(ctx)
+	labels[drivers.TridentLabelTag] = d.getTelemetryLabels(ctx)
+
+	poolLabels, err := pool.GetLabelsJSON(ctx, storage.ProvisioningLabelTag, api.MaxLabelLength)
+	if err != nil {
+		return nil, err
+	}
+	labels[storage.ProvisioningLabelTag] = poolLabels
+
+	for tag, value := range d.getVersionTags(ctx) {
+		labels[tag] = value
+	}
+
+	// Find a subnet
+	subnet := d.SDK.SubnetForStoragePool(ctx, pool)
+	if subnet == nil {
+		return nil, errors.UnsupportedConfigError("no subnets found for storage pool %s", pool.Name())
+	}
+
+	// Group members have their own throughputs, so only manual QoS capacity pools will do
+	cPools := filterCapacityPoolsByQosType(d.SDK.CapacityPoolsForStoragePool(ctx, pool, serviceLevel), true)
+	if len(cPools) == 0 {
+		return nil, errors.UnsupportedConfigError("no manual QoS capacity pools found for storage pool %s", pool.Name())
+	}
+
+	// Rule out capacity pools that cannot hold the whole group, if so configured
+	if d.Config.EnforcePoolCapacity {
+		if cPools, err = d.capacityPoolsWithFreeSpace(ctx, pool, cPools, totalBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the group in the first capacity pool that works
+	var volumes []*api.FileSystem
+	createErrors := multierr.Combine()
+	for _, cPool := range cPools {
+
+		request := &api.VolumeGroupCreateRequest{
+			ResourceGroup:         cPool.ResourceGroup,
+			NetAppAccount:         cPool.NetAppAccount,
+			Name:                  group.Name,
+			ApplicationType:       group.ApplicationType,
+			ApplicationIdentifier: group.ApplicationIdentifier,
+			Description:           group.Description,
+		}
+
+		for i, member := range group.Volumes {
+			request.Volumes = append(request.Volumes, &api.FilesystemCreateRequest{
+				ResourceGroup:           cPool.ResourceGroup,
+				NetAppAccount:           cPool.NetAppAccount,
+				CapacityPool:            cPool.Name,
+				Name:                    member.VolumeConfig.Name,
+				SubnetID:                subnet.ID,
+				CreationToken:           member.VolumeConfig.InternalName,
+				ExportPolicy:            exportPolicy,
+				Labels:                  labels,
+				ProtocolTypes:           []string{api.ProtocolTypeNFSv41},
+				QuotaInBytes:            int64(sizes[i]),
+				SnapshotDirectory:       snapshotDirBool,
+				UnixPermissions:         unixPermissions,
+				NetworkFeatures:         pool.InternalAttributes()[NetworkFeatures],
+				ThroughputMibps:         member.ThroughputMibps,
+				VolumeSpecName:          member.SpecName,
+				ProximityPlacementGroup: group.ProximityPlacementGroup,
+			})
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"capacityPool": api.CreateCapacityPoolFullName(cPool.ResourceGroup, cPool.NetAppAccount, cPool.Name),
+			"volumeGroup":  group.Name,
+			"volumes":      len(request.Volumes),
+			"serviceLevel": serviceLevel,
+		}).Debug("Creating volume group.")
+
+		if volumes, err = d.SDK.CreateVolumeGroup(ctx, request); err == nil {
+			break
+		}
+
+		createErrors = multierr.Combine(createErrors, err)
+		Logc(ctx).WithFields(LogFields{
+			"capacityPool": cPool.Name,
+			"volumeGroup":  group.Name,
+		}).WithError(err).Warning("Could not create volume group in capacity pool.")
+	}
+	if volumes == nil {
+		return nil, createErrors
+	}
+
+	// Wait for each member to become available so that its mount targets are known
+	accessInfo := make(map[string]utils.VolumeAccessInfo, len(volumes))
+	for i, volume := range volumes {
+		volConfig := group.Volumes[i].VolumeConfig
+
+		volConfig.InternalID = volume.ID
+		volConfig.Size = strconv.FormatUint(sizes[i], 10)
+		volConfig.ServiceLevel = serviceLevel
+		volConfig.SnapshotDir = strconv.FormatBool(snapshotDirBool)
+		volConfig.UnixPermissions = unixPermissions
+		recordVolumePlacement(volConfig, volume)
+
+		if err = d.waitForVolumeCreate(ctx, volume); err == nil {
+			err = d.CreateFollowup(ctx, volConfig)
+		}
+		if err != nil {
+			// A partial group is of no use to the application, so remove the whole group
+			d.rollbackApplicationVolumeGroup(ctx, group.Name, volumes)
+			return nil, fmt.Errorf("could not create volume group %s; %v", group.Name, err)
+		}
+		accessInfo[volConfig.Name] = volConfig.AccessInfo
+	}
+
+	return accessInfo, nil
+}
+
+// rollbackApplicationVolumeGroup deletes the member volumes of a volume group that could not be completed,
+// followed by the group itself, which ANF only deletes once it is empty.  Any failure is logged, since the
+// original error is more useful to the caller, and anything left behind must be deleted manually.
+func (d *NASStorageDriver) rollbackApplicationVolumeGroup(
+	ctx context.Context, groupName string, volumes []*api.FileSystem,
+) {
+	logFields := LogFields{"volumeGroup": groupName}
+
+	membersDeleted := true
+	for _, volume := range volumes {
+		if err := d.SDK.DeleteVolume(ctx, volume); err != nil {
+			Logc(ctx).WithFields(logFields).WithField("volume", volume.Name).WithError(err).Error(
+				"Volume group member could not be cleaned up and must be manually deleted.")
+			membersDeleted = false
+			continue
+		}
+		if _, err := d.SDK.WaitForVolumeState(ctx, volume, api.StateDeleted, []string{api.StateError},
+			d.effectiveTimeout(ctx, d.defaultTimeout())); err != nil {
+			Logc(ctx).WithFields(logFields).WithField("volume", volume.Name).WithError(err).Error(
+				"Volume group member could not be cleaned up and must be manually deleted.")
+			membersDeleted = false
+		}
+	}
+
+	if !membersDeleted {
+		Logc(ctx).WithFields(logFields).Error("Volume group could not be cleaned up and must be manually deleted.")
+		return
+	}
+
+	if err := d.SDK.DeleteVolumeGroup(ctx, volumes[0].ResourceGroup, volumes[0].NetAppAccount,
+		groupName); err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error(
+			"Volume group could not be cleaned up and must be manually deleted.")
+		return
+	}
+
+	Logc(ctx).WithFields(logFields).Info("Incomplete volume group deleted.")
+}
+
+// validateApplicationVolumeGroup checks that a volume group has the details and member volumes required by
+// its application.
+func (d *NASStorageDriver) validateApplicationVolumeGroup(group *ApplicationVolumeGroup) error {
+	if group.ApplicationType != api.ApplicationTypeSAPHANA {
+		return fmt.Errorf("application type %s is not supported; only %s volume groups are supported",
+			group.ApplicationType, api.ApplicationTypeSAPHANA)
+	}
+	if err := d.validateVolumeName(group.Name); err != nil {
+		return fmt.Errorf("invalid volume group name; %v", err)
+	}
+	if !sapSystemIDRegex.MatchString(group.ApplicationIdentifier) {
+		return fmt.Errorf("SAP system ID '%s' is not allowed; it must be 3 characters long, begin with an "+
+			"uppercase letter, and contain only uppercase letters and digits", group.ApplicationIdentifier)
+	}
+	if group.ProximityPlacementGroup == "" {
+		return errors.New("a proximity placement group is required for SAP HANA volume groups")
+	}
+
+	specCounts := make(map[string]int)
+	for _, member := range group.Volumes {
+		switch member.SpecName {
+		case api.VolumeSpecNameData, api.VolumeSpecNameLog, api.VolumeSpecNameShared,
+			api.VolumeSpecNameDataBackup, api.VolumeSpecNameLogBackup:
+		default:
+			return fmt.Errorf("volume spec name '%s' is not supported for SAP HANA volume groups",
+				member.SpecName)
+		}
+		if specCounts[member.SpecName]++; specCounts[member.SpecName] > 1 {
+			return fmt.Errorf("volume group has more than one %s volume", member.SpecName)
+		}
+
+		if member.VolumeConfig == nil {
+			return fmt.Errorf("%s volume has no volume config", member.SpecName)
+		}
+		if err := d.validateVolumeName(member.VolumeConfig.Name); err != nil {
+			return err
+		}
+		if err := d.validateCreationToken(member.VolumeConfig.InternalName); err != nil {
+			return err
+		}
+		if member.ThroughputMibps <= 0 {
+			return fmt.Errorf("%s volume %s must have a throughput", member.SpecName, member.VolumeConfig.Name)
+		}
+	}
+
+	for _, specName := range []string{api.VolumeSpecNameData, api.VolumeSpecNameLog, api.VolumeSpecNameShared} {
+		if specCounts[specName] == 0 {
+			return fmt.Errorf("SAP HANA volume groups require a %s volume", specName)
+		}
+	}
+
+	return nil
+}
+
+// applicationVolumeSize determines the size in bytes of a volume group member, using the pool's default size
+// if none was requested and raising it to the minimum volume size if needed.
+func (d *NASStorageDriver) applicationVolumeSize(
+	ctx context.Context, volConfig *storage.VolumeConfig, pool storage.Pool,
+) (uint64, error) {
+	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return 0, fmt.Errorf("could not convert volume size %s; %v", volConfig.Size, err)
+	}
+	sizeBytes, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v is an invalid volume size; %v", volConfig.Size, err)
+	}
+	if sizeBytes == 0 {
+		defaultSize, _ := utils.ConvertSizeToBytes(pool.InternalAttributes()[Size])
+		sizeBytes, _ = strconv.ParseUint(defaultSize, 10, 64)
+	}
+
+	if minimumBytes := d.minimumVolumeSize(); sizeBytes < minimumBytes {
+		Logc(ctx).WithFields(LogFields{
+			"name":    volConfig.InternalName,
+			"size":    sizeBytes,
+			"minimum": minimumBytes,
+		}).Warningf("Requested size is too small. Setting volume size to the minimum allowable.")
+
+		sizeBytes = minimumBytes
+	}
+
+	if _, _, err = drivers.CheckVolumeSizeLimits(ctx, sizeBytes, d.Config.CommonStorageDriverConfig); err != nil {
+		return 0, err
+	}
+
+	return sizeBytes, nil
+}
+
+// applicationVolumeExportPolicy builds the NFSv4.1 export policy shared by the members of a volume group from
+// the pool's export rules, or from the known cluster nodes if the export policy is managed automatically.
+func (d *NASStorageDriver) applicationVolumeExportPolicy(pool storage.Pool) (api.ExportPolicy, error) {
+	var exportPolicy api.ExportPolicy
+
+	exportRules, err := poolExportRules(pool)
+	if err != nil {
+		return exportPolicy, err
+	}
+	if d.Config.AutoExportPolicy {
+		exportRules = []drivers.AzureNASExportRule{{AllowedClients: d.autoExportAllowedClients()}}
+	}
+
+	for _, rule := range exportRules {
+
+		// Skip any rules limited to NFSv3
+		if rule.Nfsv3 && !rule.Nfsv41 {
+			continue
+		}
+
+		exportPolicy.Rules = append(exportPolicy.Rules, api.ExportRule{
+			AllowedClients: rule.AllowedClients,
+			Nfsv41:         true,
+			RuleIndex:      int32(len(exportPolicy.Rules) + 1),
+			UnixReadOnly:   rule.UnixReadOnly,
+			UnixReadWrite:  !rule.UnixReadOnly,
+		})
+	}
+
+	if len(exportPolicy.Rules) == 0 {
+		return exportPolicy, fmt.Errorf("no export rules in pool %s apply to protocol %s", pool.Name(),
+			api.ProtocolTypeNFSv41)
+	}
+
+	return exportPolicy, nil
+}
@@ -0,0 +1,390 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netapp/trident/acp"
+	mockacp "github.com/netapp/trident/mocks/mock_acp"
+	mockapi "github.com/netapp/trident/mocks/mock_storage_drivers/mock_azure"
+	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/storage_drivers/azure/api"
+	"github.com/netapp/trident/utils/errors"
+)
+
+const testProximityPlacementGroup = "/subscriptions/1-subid-23456789876454321/resourceGroups/RG1/providers/" +
+	"Microsoft.Compute/proximityPlacementGroups/PPG1"
+
+func newVolumeGroupTestDriver(t *testing.T) (*mockapi.MockAzure, *NASStorageDriver, storage.Pool) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.BackendName = "anf"
+	driver.Config.ServiceLevel = api.ServiceLevelUltra
+	driver.Config.NASType = "nfs"
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+	driver.initializeTelemetry(ctx, BackendUUID)
+
+	return mockAPI, driver, driver.pools["anf_pool"]
+}
+
+func getStructsForCreateVolumeGroup() (*ApplicationVolumeGroup, []*api.CapacityPool, *api.Subnet) {
+	group := &ApplicationVolumeGroup{
+		Name:                    "SH1-group",
+		ApplicationIdentifier:   "SH1",
+		ProximityPlacementGroup: testProximityPlacementGroup,
+	}
+	for _, specName := range []string{api.VolumeSpecNameData, api.VolumeSpecNameLog, api.VolumeSpecNameShared} {
+		group.Volumes = append(group.Volumes, &ApplicationVolume{
+			SpecName:        specName,
+			ThroughputMibps: 64,
+			VolumeConfig: &storage.VolumeConfig{
+				Version:      "1",
+				Name:         "sh1-" + specName,
+				InternalName: "trident-sh1-" + specName,
+				Size:         VolumeSizeStr,
+			},
+		})
+	}
+
+	cPools := getMultipleCapacityPoolsForCreateVolume()[:2]
+	for _, cPool := range cPools {
+		cPool.QosType = api.QosTypeManual
+	}
+
+	subnet := &api.Subnet{
+		ID:             api.CreateSubnetID(SubscriptionID, "RG2", "VN1", "SN1"),
+		ResourceGroup:  "RG2",
+		VirtualNetwork: "VN1",
+		Name:           "SN1",
+		FullName:       "RG1/VN1/SN1",
+		Location:       Location,
+	}
+
+	return group, cPools, subnet
+}
+
+// volumesForVolumeGroup returns the available volumes that a volume group request would create.
+func volumesForVolumeGroup(request *api.VolumeGroupCreateRequest) []*api.FileSystem {
+	volumes := make([]*api.FileSystem, 0, len(request.Volumes))
+	for i, volumeRequest := range request.Volumes {
+		volumes = append(volumes, &api.FileSystem{
+			ID: api.CreateVolumeID(SubscriptionID, volumeRequest.ResourceGroup, volumeRequest.NetAppAccount,
+				volumeRequest.CapacityPool, volumeRequest.Name),
+			ResourceGroup:     volumeRequest.ResourceGroup,
+			NetAppAccount:     volumeRequest.NetAppAccount,
+			CapacityPool:      volumeRequest.CapacityPool,
+			Name:              volumeRequest.Name,
+			CreationToken:     volumeRequest.CreationToken,
+			ProvisioningState: api.StateAvailable,
+			ProtocolTypes:     volumeRequest.ProtocolTypes,
+			QuotaInBytes:      volumeRequest.QuotaInBytes,
+			MountTargets: []api.MountTarget{{
+				MountTargetID: "mountTargetID",
+				FileSystemID:  "filesystemID",
+				IPAddress:     "1.1.1." + string(rune('1'+i)),
+			}},
+		})
+	}
+	return volumes
+}
+
+func TestCreateApplicationVolumeGroup(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, cPools, subnet := getStructsForCreateVolumeGroup()
+
+	var request *api.VolumeGroupCreateRequest
+	var volumes []*api.FileSystem
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).AnyTimes()
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().VolumeExists(ctx, gomock.Any()).Return(false, nil, nil).Times(3)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool, api.ServiceLevelUltra).Return(cPools).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, r *api.VolumeGroupCreateRequest) ([]*api.FileSystem, error) {
+			request = r
+			volumes = volumesForVolumeGroup(r)
+			return volumes, nil
+		}).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(3)
+	for i, member := range group.Volumes {
+		i := i
+		mockAPI.EXPECT().Volume(ctx, member.VolumeConfig).DoAndReturn(
+			func(context.Context, *storage.VolumeConfig) (*api.FileSystem, error) { return volumes[i], nil }).Times(1)
+	}
+
+	accessInfo, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.NoError(t, err, "create failed")
+	assert.Equal(t, "SH1-group", request.Name, "group name mismatch")
+	assert.Equal(t, api.ApplicationTypeSAPHANA, request.ApplicationType, "application type mismatch")
+	assert.Equal(t, "SH1", request.ApplicationIdentifier, "application identifier mismatch")
+	assert.Len(t, request.Volumes, 3, "volume count mismatch")
+	for i, volumeRequest := range request.Volumes {
+		member := group.Volumes[i]
+		assert.Equal(t, "CP1", volumeRequest.CapacityPool, "capacity pool mismatch")
+		assert.Equal(t, member.SpecName, volumeRequest.VolumeSpecName, "volume spec name mismatch")
+		assert.Equal(t, testProximityPlacementGroup, volumeRequest.ProximityPlacementGroup, "PPG mismatch")
+		assert.Equal(t, float32(64), volumeRequest.ThroughputMibps, "throughput mismatch")
+		assert.Equal(t, []string{api.ProtocolTypeNFSv41}, volumeRequest.ProtocolTypes, "protocol mismatch")
+		assert.True(t, volumeRequest.ExportPolicy.Rules[0].Nfsv41, "export rule not NFSv4.1")
+
+		assert.Equal(t, volumes[i].ID, member.VolumeConfig.InternalID, "internal ID not set on volConfig")
+		assert.Equal(t, "RG1/NA1/CP1", member.VolumeConfig.CapacityPool, "capacity pool not set on volConfig")
+		assert.Equal(t, volumes[i].MountTargets[0].IPAddress, accessInfo[member.VolumeConfig.Name].NfsServerIP,
+			"NFS server IP mismatch")
+		assert.Equal(t, "/"+member.VolumeConfig.InternalName, accessInfo[member.VolumeConfig.Name].NfsPath,
+			"NFS path mismatch")
+	}
+}
+
+func TestCreateApplicationVolumeGroup_SecondCapacityPool(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, cPools, subnet := getStructsForCreateVolumeGroup()
+
+	var volumes []*api.FileSystem
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).AnyTimes()
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().VolumeExists(ctx, gomock.Any()).Return(false, nil, nil).Times(3)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool, api.ServiceLevelUltra).Return(cPools).Times(1)
+	gomock.InOrder(
+		mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).Return(nil, errFailed).Times(1),
+		mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, r *api.VolumeGroupCreateRequest) ([]*api.FileSystem, error) {
+				volumes = volumesForVolumeGroup(r)
+				return volumes, nil
+			}).Times(1),
+	)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(3)
+	for i, member := range group.Volumes {
+		i := i
+		mockAPI.EXPECT().Volume(ctx, member.VolumeConfig).DoAndReturn(
+			func(context.Context, *storage.VolumeConfig) (*api.FileSystem, error) { return volumes[i], nil }).Times(1)
+	}
+
+	accessInfo, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.NoError(t, err, "create failed")
+	assert.Len(t, accessInfo, 3, "access info count mismatch")
+	for _, member := range group.Volumes {
+		assert.Equal(t, "RG1/NA1/CP2", member.VolumeConfig.CapacityPool, "capacity pool not set on volConfig")
+	}
+}
+
+func TestCreateApplicationVolumeGroup_MemberFailedRollback(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, cPools, subnet := getStructsForCreateVolumeGroup()
+
+	var volumes []*api.FileSystem
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).AnyTimes()
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().VolumeExists(ctx, gomock.Any()).Return(false, nil, nil).Times(3)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool, api.ServiceLevelUltra).Return(cPools).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, r *api.VolumeGroupCreateRequest) ([]*api.FileSystem, error) {
+			volumes = volumesForVolumeGroup(r)
+			return volumes, nil
+		}).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(2)
+	mockAPI.EXPECT().Volume(ctx, group.Volumes[0].VolumeConfig).DoAndReturn(
+		func(context.Context, *storage.VolumeConfig) (*api.FileSystem, error) { return volumes[0], nil }).Times(1)
+	mockAPI.EXPECT().Volume(ctx, group.Volumes[1].VolumeConfig).Return(nil, errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, gomock.Any()).Return(nil).Times(3)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), api.StateDeleted, []string{api.StateError},
+		gomock.Any()).Return(api.StateDeleted, nil).Times(3)
+	mockAPI.EXPECT().DeleteVolumeGroup(ctx, "RG1", "NA1", "SH1-group").Return(nil).Times(1)
+
+	accessInfo, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.Error(t, err, "expected error")
+	assert.Nil(t, accessInfo, "access info not nil")
+}
+
+func TestCreateApplicationVolumeGroup_RollbackMemberNotDeleted(t *testing.T) {
+	mockAPI, driver, _ := newVolumeGroupTestDriver(t)
+	_, cPools, _ := getStructsForCreateVolumeGroup()
+
+	volumes := []*api.FileSystem{
+		{ResourceGroup: "RG1", NetAppAccount: "NA1", CapacityPool: cPools[0].Name, Name: "sh1-data"},
+		{ResourceGroup: "RG1", NetAppAccount: "NA1", CapacityPool: cPools[0].Name, Name: "sh1-log"},
+	}
+
+	mockAPI.EXPECT().DeleteVolume(ctx, volumes[0]).Return(errFailed).Times(1)
+	mockAPI.EXPECT().DeleteVolume(ctx, volumes[1]).Return(nil).Times(1)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, volumes[1], api.StateDeleted, []string{api.StateError},
+		gomock.Any()).Return(api.StateDeleted, nil).Times(1)
+	mockAPI.EXPECT().DeleteVolumeGroup(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	driver.rollbackApplicationVolumeGroup(ctx, "SH1-group", volumes)
+}
+
+func TestCreateApplicationVolumeGroup_AllCapacityPoolsFailed(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, cPools, subnet := getStructsForCreateVolumeGroup()
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().VolumeExists(ctx, gomock.Any()).Return(false, nil, nil).Times(3)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool, api.ServiceLevelUltra).Return(cPools).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).Return(nil, errFailed).Times(2)
+	mockAPI.EXPECT().WaitForVolumeState(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	accessInfo, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.Error(t, err, "expected create error")
+	assert.Nil(t, accessInfo, "access info not nil")
+}
+
+func TestCreateApplicationVolumeGroup_NoManualQosCapacityPools(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, _, subnet := getStructsForCreateVolumeGroup()
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().HasFeature(api.FeatureUnixPermissions).Return(true).AnyTimes()
+	mockAPI.EXPECT().VolumeExists(ctx, gomock.Any()).Return(false, nil, nil).Times(3)
+	mockAPI.EXPECT().SubnetForStoragePool(ctx, storagePool).Return(subnet).Times(1)
+	mockAPI.EXPECT().CapacityPoolsForStoragePool(ctx, storagePool, api.ServiceLevelUltra).Return(
+		getMultipleCapacityPoolsForCreateVolume()).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).Times(0)
+
+	_, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.ErrorContains(t, err, "no manual QoS capacity pools", "unexpected error")
+	assert.True(t, errors.IsUnsupportedConfigError(err), "not UnsupportedConfigError")
+}
+
+func TestCreateApplicationVolumeGroup_ACPDisabled(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, _, _ := getStructsForCreateVolumeGroup()
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(errFailed).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).Times(0)
+
+	accessInfo, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.ErrorIs(t, err, errFailed, "expected ACP error")
+	assert.Nil(t, accessInfo, "access info not nil")
+}
+
+func TestCreateApplicationVolumeGroup_VolumeExists(t *testing.T) {
+	defer acp.SetAPI(acp.API())
+	mockACP := mockacp.NewMockTridentACP(gomock.NewController(t))
+	acp.SetAPI(mockACP)
+
+	mockAPI, driver, storagePool := newVolumeGroupTestDriver(t)
+	group, _, _ := getStructsForCreateVolumeGroup()
+
+	mockACP.EXPECT().IsFeatureEnabled(ctx, acp.FeatureApplicationVolumeGroups).Return(nil).Times(1)
+	mockAPI.EXPECT().RefreshAzureResources(ctx).Return(nil).Times(1)
+	mockAPI.EXPECT().VolumeExists(ctx, group.Volumes[0].VolumeConfig).Return(true, &api.FileSystem{}, nil).Times(1)
+	mockAPI.EXPECT().CreateVolumeGroup(ctx, gomock.Any()).Times(0)
+
+	_, err := driver.CreateApplicationVolumeGroup(ctx, group, storagePool)
+
+	assert.Error(t, err, "expected error")
+}
+
+func TestValidateApplicationVolumeGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(group *ApplicationVolumeGroup)
+		valid  bool
+	}{
+		{"Valid", func(group *ApplicationVolumeGroup) {}, true},
+		{"WithBackupVolume", func(group *ApplicationVolumeGroup) {
+			group.Volumes = append(group.Volumes, &ApplicationVolume{
+				SpecName:        api.VolumeSpecNameLogBackup,
+				ThroughputMibps: 32,
+				VolumeConfig:    &storage.VolumeConfig{Name: "sh1-log-backup", InternalName: "trident-sh1-log-backup"},
+			})
+		}, true},
+		{"OracleApplication", func(group *ApplicationVolumeGroup) { group.ApplicationType = "ORACLE" }, false},
+		{"InvalidGroupName", func(group *ApplicationVolumeGroup) { group.Name = "1group" }, false},
+		{"LowercaseSID", func(group *ApplicationVolumeGroup) { group.ApplicationIdentifier = "sh1" }, false},
+		{"LongSID", func(group *ApplicationVolumeGroup) { group.ApplicationIdentifier = "SH12" }, false},
+		{"NoPPG", func(group *ApplicationVolumeGroup) { group.ProximityPlacementGroup = "" }, false},
+		{"NoSharedVolume", func(group *ApplicationVolumeGroup) { group.Volumes = group.Volumes[:2] }, false},
+		{"DuplicateDataVolume", func(group *ApplicationVolumeGroup) {
+			group.Volumes[1].SpecName = api.VolumeSpecNameData
+		}, false},
+		{"UnknownSpecName", func(group *ApplicationVolumeGroup) { group.Volumes[2].SpecName = "scratch" }, false},
+		{"NoThroughput", func(group *ApplicationVolumeGroup) { group.Volumes[0].ThroughputMibps = 0 }, false},
+		{"InvalidVolumeName", func(group *ApplicationVolumeGroup) {
+			group.Volumes[0].VolumeConfig.Name = "-data"
+		}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, driver := newMockANFDriver(t)
+			group, _, _ := getStructsForCreateVolumeGroup()
+			group.ApplicationType = api.ApplicationTypeSAPHANA
+			test.modify(group)
+
+			err := driver.validateApplicationVolumeGroup(group)
+
+			if test.valid {
+				assert.NoError(t, err, "expected valid group")
+			} else {
+				assert.Error(t, err, "expected invalid group")
+			}
+		})
+	}
+}
+
+func TestApplicationVolumeExportPolicy(t *testing.T) {
+	_, driver, storagePool := newVolumeGroupTestDriver(t)
+
+	exportPolicy, err := driver.applicationVolumeExportPolicy(storagePool)
+
+	assert.NoError(t, err, "export policy failed")
+	assert.Equal(t, []api.ExportRule{{
+		AllowedClients: defaultExportRule,
+		Nfsv41:         true,
+		RuleIndex:      1,
+		UnixReadWrite:  true,
+	}}, exportPolicy.Rules, "export rules mismatch")
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	. "github.com/netapp/trident/logging"
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/azure/api"
+	"github.com/netapp/trident/utils/errors"
+)
+
+// CredentialProvider supplies the credentials the ANF drivers use to authenticate with Azure.  Each
+// provider handles one authentication method, and the first provider that applies to a backend is used.
+type CredentialProvider interface {
+	// Name identifies the authentication method in logs.
+	Name() string
+
+	// Applies reports whether this provider should supply the credentials for a backend.
+	Applies(config *drivers.AzureNASStorageDriverConfig) bool
+
+	// Configure sets the authentication parameters in the API client config.  A provider that obtains
+	// its own token credential may set clientConfig.Credential, which the API client then uses directly.
+	Configure(ctx context.Context, config *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig) error
+}
+
+// defaultCredentialProviders returns the built-in credential providers in order of precedence.
+func defaultCredentialProviders() []CredentialProvider {
+	return []CredentialProvider{
+		&workloadIdentityCredentialProvider{},
+		&managedIdentityCredentialProvider{},
+		&clientSecretCredentialProvider{},
+		&credentialFileCredentialProvider{},
+	}
+}
+
+// resolveAzureAuthConfig determines how the driver authenticates with Azure, using the first of the
+// supplied credential providers that applies to the backend, or the built-in providers if none are supplied.
+// By default, it uses workload identity if a federated token file is present in the environment, then a
+// managed identity if the backend config asks for one, then any client secret in the backend config, and
+// finally the Azure credential file, which may also specify a managed identity.
+func resolveAzureAuthConfig(
+	ctx context.Context, providers []CredentialProvider, config *drivers.AzureNASStorageDriverConfig,
+	clientConfig *api.ClientConfig,
+) error {
+	if len(providers) == 0 {
+		providers = defaultCredentialProviders()
+	}
+
+	for _, provider := range providers {
+		if provider.Applies(config) {
+			Logc(ctx).WithField("credentialProvider", provider.Name()).Debug("Selected Azure credential provider.")
+			return provider.Configure(ctx, config, clientConfig)
+		}
+	}
+
+	return errors.New("no Azure credential provider applies to this backend")
+}
+
+// sameAzureAuthMode reports whether two backend configs authenticate with Azure in the same way, so that
+// switching between a managed identity and a client secret is treated as a change of credentials.
+func sameAzureAuthMode(config, origConfig *drivers.AzureNASStorageDriverConfig) bool {
+	return config.UseWorkloadIdentity == origConfig.UseWorkloadIdentity &&
+		config.UseManagedIdentity == origConfig.UseManagedIdentity &&
+		config.UserAssignedIdentityClientID == origConfig.UserAssignedIdentityClientID &&
+		(config.ClientSecret == "") == (origConfig.ClientSecret == "")
+}
+
+// readAzureCredentialFile fills in the API client config from the Azure credential file, which is found
+// at the path in the AZURE_CREDENTIAL_FILE environment variable, or else at the default path.
+func readAzureCredentialFile(ctx context.Context, clientConfig *api.ClientConfig) error {
+	credFilePath := os.Getenv("AZURE_CREDENTIAL_FILE")
+	if credFilePath == "" {
+		credFilePath = DefaultConfigurationFilePath
+	}
+	Logc(ctx).WithField("credFilePath", credFilePath).Info("Using Azure credential config file.")
+	credFile, err := os.ReadFile(credFilePath)
+	if err != nil {
+		return errors.New("error reading from azure config file: " + err.Error())
+	}
+	if err = json.Unmarshal(credFile, clientConfig); err != nil {
+		return errors.New("error parsing azureAuthConfig: " + err.Error())
+	}
+	return nil
+}
+
+// workloadIdentityCredentialProvider authenticates using an Azure workload identity, which applies
+// whenever a federated token file is present in the environment, or if the backend config requires it.
+type workloadIdentityCredentialProvider struct{}
+
+func (p *workloadIdentityCredentialProvider) Name() string {
+	return "workloadIdentity"
+}
+
+// envNames returns the names of the environment variables holding the workload identity settings.
+func (p *workloadIdentityCredentialProvider) envNames(
+	config *drivers.AzureNASStorageDriverConfig,
+) (tokenFileEnv, clientIDEnv, tenantIDEnv string) {
+	tokenFileEnv = config.WorkloadIdentityTokenFileEnv
+	if tokenFileEnv == "" {
+		tokenFileEnv = DefaultFederatedTokenFileEnv
+	}
+	clientIDEnv = config.WorkloadIdentityClientIDEnv
+	if clientIDEnv == "" {
+		clientIDEnv = DefaultClientIDEnv
+	}
+	tenantIDEnv = config.WorkloadIdentityTenantIDEnv
+	if tenantIDEnv == "" {
+		tenantIDEnv = DefaultTenantIDEnv
+	}
+	return
+}
+
+func (p *workloadIdentityCredentialProvider) Applies(config *drivers.AzureNASStorageDriverConfig) bool {
+	if config.UseWorkloadIdentity {
+		return true
+	}
+	tokenFileEnv, _, _ := p.envNames(config)
+	return os.Getenv(tokenFileEnv) != ""
+}
+
+func (p *workloadIdentityCredentialProvider) Configure(
+	ctx context.Context, config *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig,
+) error {
+	tokenFileEnv, clientIDEnv, tenantIDEnv := p.envNames(config)
+	tokenFile := os.Getenv(tokenFileEnv)
+
+	// Catch a missing token now, since the SDK would report it only when first asked for a token
+	if tokenFile == "" {
+		return fmt.Errorf("workload identity requires a federated token file, set in %s", tokenFileEnv)
+	}
+	if _, err := os.Stat(tokenFile); err != nil {
+		return fmt.Errorf("could not find workload identity federated token file; %v", err)
+	}
+
+	// The credential file is needed only for the subscription, if the backend config doesn't supply one
+	if config.SubscriptionID == "" {
+		if err := readAzureCredentialFile(ctx, clientConfig); err != nil {
+			return err
+		}
+	}
+
+	Logc(ctx).WithField("tokenFile", tokenFile).Info("Using Azure workload identity.")
+
+	clientConfig.AADFederatedTokenFile = tokenFile
+	clientConfig.UseFederatedWorkloadIdentityExtension = true
+	clientConfig.UseManagedIdentityExtension = false
+	clientConfig.AADClientSecret = ""
+	if clientID := os.Getenv(clientIDEnv); clientID != "" {
+		clientConfig.AADClientID = clientID
+	}
+	if tenantID := os.Getenv(tenantIDEnv); tenantID != "" {
+		clientConfig.TenantID = tenantID
+	}
+	if clientConfig.AADClientID == "" || clientConfig.TenantID == "" {
+		return fmt.Errorf("workload identity requires a client ID and tenant ID, set in %s and %s",
+			clientIDEnv, tenantIDEnv)
+	}
+
+	return nil
+}
+
+// managedIdentityCredentialProvider authenticates using the managed identity of the host, either the
+// user-assigned identity named in the backend config or else the system-assigned identity.  It applies
+// only if the backend config asks for it and doesn't also supply a client secret.
+type managedIdentityCredentialProvider struct{}
+
+func (p *managedIdentityCredentialProvider) Name() string {
+	return "managedIdentity"
+}
+
+func (p *managedIdentityCredentialProvider) Applies(config *drivers.AzureNASStorageDriverConfig) bool {
+	return config.UseManagedIdentity && config.ClientSecret == ""
+}
+
+func (p *managedIdentityCredentialProvider) Configure(
+	ctx context.Context, config *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig,
+) error {
+	// The credential file is needed only for the subscription, if the backend config doesn't supply one
+	if config.SubscriptionID == "" {
+		if err := readAzureCredentialFile(ctx, clientConfig); err != nil {
+			return err
+		}
+	}
+
+	Logc(ctx).WithField("userAssignedIdentityClientID", config.UserAssignedIdentityClientID).Info(
+		"Using Azure managed identity.")
+
+	clientConfig.UseManagedIdentityExtension = true
+	clientConfig.UserAssignedIdentityID = config.UserAssignedIdentityClientID
+	clientConfig.UseFederatedWorkloadIdentityExtension = false
+	clientConfig.AADClientID = ""
+	clientConfig.AADClientSecret = ""
+	return nil
+}
+
+// clientSecretCredentialProvider authenticates using the service principal in the backend config.
+type clientSecretCredentialProvider struct{}
+
+func (p *clientSecretCredentialProvider) Name() string {
+	return "clientSecret"
+}
+
+func (p *clientSecretCredentialProvider) Applies(config *drivers.AzureNASStorageDriverConfig) bool {
+	return config.ClientSecret != "" || config.ClientID != ""
+}
+
+func (p *clientSecretCredentialProvider) Configure(
+	_ context.Context, config *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig,
+) error {
+	clientConfig.TenantID = config.TenantID
+	clientConfig.AADClientID = config.ClientID
+	clientConfig.AADClientSecret = config.ClientSecret
+	return nil
+}
+
+// credentialFileCredentialProvider authenticates using the Azure credential file, which may specify
+// either a service principal or a managed identity.  It applies to any backend, so it should be last.
+type credentialFileCredentialProvider struct{}
+
+func (p *credentialFileCredentialProvider) Name() string {
+	return "credentialFile"
+}
+
+func (p *credentialFileCredentialProvider) Applies(_ *drivers.AzureNASStorageDriverConfig) bool {
+	return true
+}
+
+func (p *credentialFileCredentialProvider) Configure(
+	ctx context.Context, _ *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig,
+) error {
+	return readAzureCredentialFile(ctx, clientConfig)
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+
+	drivers "github.com/netapp/trident/storage_drivers"
+	"github.com/netapp/trident/storage_drivers/azure/api"
+)
+
+type fakeTokenCredential struct{}
+
+func (c *fakeTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+type fakeCredentialProvider struct {
+	applies      bool
+	configured   bool
+	subscription string
+	credential   azcore.TokenCredential
+	err          error
+}
+
+func (p *fakeCredentialProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeCredentialProvider) Applies(_ *drivers.AzureNASStorageDriverConfig) bool {
+	return p.applies
+}
+
+func (p *fakeCredentialProvider) Configure(
+	_ context.Context, _ *drivers.AzureNASStorageDriverConfig, clientConfig *api.ClientConfig,
+) error {
+	p.configured = true
+	if p.err != nil {
+		return p.err
+	}
+	clientConfig.SubscriptionID = p.subscription
+	clientConfig.Credential = p.credential
+	return nil
+}
+
+func TestResolveAzureAuthConfig_FirstApplicableProvider(t *testing.T) {
+	skipped := &fakeCredentialProvider{applies: false}
+	selected := &fakeCredentialProvider{applies: true, subscription: "fakeSubscription"}
+	unused := &fakeCredentialProvider{applies: true}
+
+	config := &drivers.AzureNASStorageDriverConfig{}
+	clientConfig := &api.ClientConfig{}
+
+	err := resolveAzureAuthConfig(ctx, []CredentialProvider{skipped, selected, unused}, config, clientConfig)
+
+	assert.NoError(t, err, "unexpected error")
+	assert.False(t, skipped.configured, "inapplicable provider was used")
+	assert.True(t, selected.configured, "applicable provider was not used")
+	assert.False(t, unused.configured, "later provider was used")
+	assert.Equal(t, "fakeSubscription", clientConfig.SubscriptionID, "subscription mismatch")
+}
+
+func TestResolveAzureAuthConfig_ProviderFailed(t *testing.T) {
+	provider := &fakeCredentialProvider{applies: true, err: errFailed}
+
+	err := resolveAzureAuthConfig(ctx, []CredentialProvider{provider}, &drivers.AzureNASStorageDriverConfig{},
+		&api.ClientConfig{})
+
+	assert.ErrorIs(t, err, errFailed, "expected error")
+}
+
+func TestResolveAzureAuthConfig_NoProviderApplies(t *testing.T) {
+	provider := &fakeCredentialProvider{applies: false}
+
+	err := resolveAzureAuthConfig(ctx, []CredentialProvider{provider}, &drivers.AzureNASStorageDriverConfig{},
+		&api.ClientConfig{})
+
+	assert.Error(t, err, "expected error")
+	assert.False(t, provider.configured, "inapplicable provider was used")
+}
+
+func TestDefaultCredentialProviders(t *testing.T) {
+	t.Setenv(DefaultFederatedTokenFileEnv, "")
+
+	providers := defaultCredentialProviders()
+
+	names := make([]string, 0)
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	assert.Equal(t, []string{"workloadIdentity", "managedIdentity", "clientSecret", "credentialFile"}, names,
+		"provider order")
+
+	secretConfig := &drivers.AzureNASStorageDriverConfig{ClientID: "client", ClientSecret: "secret"}
+	msiConfig := &drivers.AzureNASStorageDriverConfig{UseManagedIdentity: true}
+	assert.False(t, providers[0].Applies(secretConfig), "workload identity applies without a token file")
+	assert.True(t, providers[0].Applies(&drivers.AzureNASStorageDriverConfig{UseWorkloadIdentity: true}),
+		"required workload identity does not apply")
+	assert.True(t, providers[1].Applies(msiConfig), "managed identity does not apply")
+	assert.False(t, providers[1].Applies(secretConfig), "managed identity applies")
+	assert.False(t, providers[1].Applies(&drivers.AzureNASStorageDriverConfig{UseManagedIdentity: true,
+		ClientSecret: "secret"}), "managed identity applies with a client secret")
+	assert.True(t, providers[2].Applies(secretConfig), "client secret does not apply")
+	assert.False(t, providers[2].Applies(&drivers.AzureNASStorageDriverConfig{}), "client secret applies")
+	assert.True(t, providers[3].Applies(&drivers.AzureNASStorageDriverConfig{}), "credential file does not apply")
+
+	t.Setenv(DefaultFederatedTokenFileEnv, "/var/run/token")
+	assert.True(t, providers[0].Applies(secretConfig), "workload identity does not apply")
+}
+
+func TestInitializeAzureSDKClient_CredentialProvider(t *testing.T) {
+	mockAPI, driver := newMockANFDriver(t)
+	driver.Config.SubscriptionID = ""
+
+	driver.populateConfigurationDefaults(ctx, &driver.Config)
+	driver.initializeStoragePools(ctx)
+
+	provider := &fakeCredentialProvider{
+		applies:      true,
+		subscription: "fakeSubscription",
+		credential:   &fakeTokenCredential{},
+	}
+	driver.credentialProviders = []CredentialProvider{provider}
+
+	mockAPI.EXPECT().Init(ctx, driver.pools).Return(nil).Times(1)
+
+	err := driver.initializeAzureSDKClient(ctx, &driver.Config)
+
+	assert.NoError(t, err, "unexpected error")
+	assert.True(t, provider.configured, "credential provider was not used")
+	assert.Equal(t, "fakeSubscription", driver.Config.SubscriptionID, "subscription mismatch")
+}
+
+func TestManagedIdentityCredentialProvider_Configure(t *testing.T) {
+	config := &drivers.AzureNASStorageDriverConfig{
+		SubscriptionID:               "fakeSubscription",
+		ClientID:                     "client",
+		UseManagedIdentity:           true,
+		UserAssignedIdentityClientID: "identity",
+	}
+	clientConfig := &api.ClientConfig{SubscriptionID: config.SubscriptionID}
+	clientConfig.AADClientID = config.ClientID
+
+	err := resolveAzureAuthConfig(ctx, nil, config, clientConfig)
+
+	assert.NoError(t, err, "unexpected error")
+	assert.True(t, clientConfig.UseManagedIdentityExtension, "managed identity not enabled")
+	assert.Equal(t, "identity", clientConfig.UserAssignedIdentityID, "identity mismatch")
+	assert.Empty(t, clientConfig.AADClientID, "client ID set")
+	assert.Empty(t, clientConfig.AADClientSecret, "client secret set")
+	assert.Equal(t, "fakeSubscription", clientConfig.SubscriptionID, "subscription mismatch")
+}
+
+func TestSameAzureAuthMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   drivers.AzureNASStorageDriverConfig
+		expected bool
+	}{
+		{"same secret", drivers.AzureNASStorageDriverConfig{ClientSecret: "secret"}, true},
+		{"new secret", drivers.AzureNASStorageDriverConfig{ClientSecret: "secret2"}, true},
+		{"no secret", drivers.AzureNASStorageDriverConfig{}, false},
+		{"managed identity", drivers.AzureNASStorageDriverConfig{UseManagedIdentity: true}, false},
+	}
+
+	origConfig := &drivers.AzureNASStorageDriverConfig{ClientSecret: "secret"}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sameAzureAuthMode(&test.config, origConfig), "auth mode mismatch")
+		})
+	}
+
+	msiConfig := &drivers.AzureNASStorageDriverConfig{UseManagedIdentity: true}
+	userMSIConfig := &drivers.AzureNASStorageDriverConfig{UseManagedIdentity: true, UserAssignedIdentityClientID: "id"}
+	assert.False(t, sameAzureAuthMode(userMSIConfig, msiConfig), "identity change not detected")
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	tridentconfig "github.com/netapp/trident/config"
+)
+
+var provisioningDurationHistogram = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: tridentconfig.OrchestratorName,
+		Subsystem: "anf",
+		Name:      "volume_provisioning_duration_seconds",
+		Help:      "The time taken for ANF volumes to become available after creation",
+		Buckets:   []float64{5, 10, 20, 30, 60, 120, 300, 600},
+	},
+	[]string{"service_level", "capacity_pool"},
+)
+
+// ProvisioningLatency summarizes how long ANF volumes took to become available after creation.
+type ProvisioningLatency struct {
+	Count   int           `json:"count"`
+	Total   time.Duration `json:"total"`
+	Min     time.Duration `json:"min"`
+	Max     time.Duration `json:"max"`
+	Average time.Duration `json:"average"`
+}
+
+// add folds a single provisioning duration into the summary.
+func (l *ProvisioningLatency) add(duration time.Duration) {
+	if l.Count == 0 || duration < l.Min {
+		l.Min = duration
+	}
+	if duration > l.Max {
+		l.Max = duration
+	}
+	l.Count++
+	l.Total += duration
+	l.Average = l.Total / time.Duration(l.Count)
+}
+
+// merge folds another summary into this one.
+func (l *ProvisioningLatency) merge(other ProvisioningLatency) {
+	if other.Count == 0 {
+		return
+	}
+	if l.Count == 0 || other.Min < l.Min {
+		l.Min = other.Min
+	}
+	if other.Max > l.Max {
+		l.Max = other.Max
+	}
+	l.Count += other.Count
+	l.Total += other.Total
+	l.Average = l.Total / time.Duration(l.Count)
+}
+
+// provisioningLatencyKey identifies the service level and capacity pool in which a volume was created.
+type provisioningLatencyKey struct {
+	ServiceLevel string
+	CapacityPool string
+}
+
+// provisioningLatencyTracker accumulates provisioning durations for a single backend.
+type provisioningLatencyTracker struct {
+	mutex     sync.Mutex
+	latencies map[provisioningLatencyKey]*ProvisioningLatency
+}
+
+// record adds a provisioning duration for the specified service level and capacity pool.  Nothing is
+// collected unless metrics are enabled.
+func (t *provisioningLatencyTracker) record(serviceLevel, capacityPool string, duration time.Duration) {
+	if !tridentconfig.MetricsEnabled {
+		return
+	}
+
+	provisioningDurationHistogram.WithLabelValues(serviceLevel, capacityPool).Observe(duration.Seconds())
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.latencies == nil {
+		t.latencies = make(map[provisioningLatencyKey]*ProvisioningLatency)
+	}
+
+	key := provisioningLatencyKey{ServiceLevel: serviceLevel, CapacityPool: capacityPool}
+	latency, ok := t.latencies[key]
+	if !ok {
+		latency = &ProvisioningLatency{}
+		t.latencies[key] = latency
+	}
+	latency.add(duration)
+}
+
+// byServiceLevel returns the provisioning latency summaries aggregated by service level.
+func (t *provisioningLatencyTracker) byServiceLevel() map[string]ProvisioningLatency {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make(map[string]ProvisioningLatency)
+	for key, latency := range t.latencies {
+		aggregate := result[key.ServiceLevel]
+		aggregate.merge(*latency)
+		result[key.ServiceLevel] = aggregate
+	}
+	return result
+}
+
+// byCapacityPool returns the provisioning latency summaries keyed by service level and then capacity pool.
+func (t *provisioningLatencyTracker) byCapacityPool() map[string]map[string]ProvisioningLatency {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make(map[string]map[string]ProvisioningLatency)
+	for key, latency := range t.latencies {
+		if _, ok := result[key.ServiceLevel]; !ok {
+			result[key.ServiceLevel] = make(map[string]ProvisioningLatency)
+		}
+		result[key.ServiceLevel][key.CapacityPool] = *latency
+	}
+	return result
+}
+
+var (
+	volumeProvisionedBytesGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: tridentconfig.OrchestratorName,
+			Subsystem: "anf",
+			Name:      "volume_provisioned_bytes",
+			Help:      "The provisioned size of ANF volumes",
+		},
+		[]string{"volume"},
+	)
+	volumeUsedBytesGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: tridentconfig.OrchestratorName,
+			Subsystem: "anf",
+			Name:      "volume_used_bytes",
+			Help:      "The space consumed by ANF volumes",
+		},
+		[]string{"volume"},
+	)
+	volumeSnapshotUsedBytesGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: tridentconfig.OrchestratorName,
+			Subsystem: "anf",
+			Name:      "volume_snapshot_used_bytes",
+			Help:      "The space consumed by snapshots of ANF volumes",
+		},
+		[]string{"volume"},
+	)
+)
+
+// VolumeUsage reports the provisioned size of an ANF volume and how much of it is in use.
+type VolumeUsage struct {
+	ProvisionedBytes  int64 `json:"provisionedBytes"`
+	UsedBytes         int64 `json:"usedBytes"`
+	SnapshotUsedBytes int64 `json:"snapshotUsedBytes"`
+}
+
+// volumeUsageEntry is a volume's usage along with the time it was read.
+type volumeUsageEntry struct {
+	usage    VolumeUsage
+	readTime time.Time
+}
+
+// volumeUsageCache holds the most recently read usage of each volume, keyed by the volume's internal name,
+// so that frequent metrics requests don't each result in a call to Azure.
+type volumeUsageCache struct {
+	mutex   sync.Mutex
+	entries map[string]volumeUsageEntry
+}
+
+// get returns the cached usage of a volume, if it was read no longer ago than the specified maximum age.
+func (c *volumeUsageCache) get(name string, maxAge time.Duration) (VolumeUsage, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[name]
+	if !ok || time.Since(entry.readTime) > maxAge {
+		return VolumeUsage{}, false
+	}
+	return entry.usage, true
+}
+
+// put caches the usage of a volume and, if metrics are enabled, publishes it.
+func (c *volumeUsageCache) put(name string, usage VolumeUsage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]volumeUsageEntry)
+	}
+	c.entries[name] = volumeUsageEntry{usage: usage, readTime: time.Now()}
+
+	if !tridentconfig.MetricsEnabled {
+		return
+	}
+
+	volumeProvisionedBytesGauge.WithLabelValues(name).Set(float64(usage.ProvisionedBytes))
+	volumeUsedBytesGauge.WithLabelValues(name).Set(float64(usage.UsedBytes))
+	volumeSnapshotUsedBytesGauge.WithLabelValues(name).Set(float64(usage.SnapshotUsedBytes))
+}
+
+// remove forgets the usage of a volume, such as after it is deleted, and stops publishing it.
+func (c *volumeUsageCache) remove(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, name)
+
+	volumeProvisionedBytesGauge.DeleteLabelValues(name)
+	volumeUsedBytesGauge.DeleteLabelValues(name)
+	volumeSnapshotUsedBytesGauge.DeleteLabelValues(name)
+}
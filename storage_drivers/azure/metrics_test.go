@@ -0,0 +1,116 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tridentconfig "github.com/netapp/trident/config"
+	"github.com/netapp/trident/storage_drivers/azure/api"
+)
+
+func TestProvisioningLatency_ByServiceLevel(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	tracker := &provisioningLatencyTracker{}
+	tracker.record(api.ServiceLevelStandard, "CP1", 30*time.Second)
+	tracker.record(api.ServiceLevelStandard, "CP1", 10*time.Second)
+	tracker.record(api.ServiceLevelStandard, "CP2", 50*time.Second)
+	tracker.record(api.ServiceLevelUltra, "CP3", 5*time.Second)
+
+	expected := map[string]ProvisioningLatency{
+		api.ServiceLevelStandard: {
+			Count: 3, Total: 90 * time.Second, Min: 10 * time.Second, Max: 50 * time.Second, Average: 30 * time.Second,
+		},
+		api.ServiceLevelUltra: {
+			Count: 1, Total: 5 * time.Second, Min: 5 * time.Second, Max: 5 * time.Second, Average: 5 * time.Second,
+		},
+	}
+
+	assert.Equal(t, expected, tracker.byServiceLevel())
+}
+
+func TestProvisioningLatency_ByCapacityPool(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	tracker := &provisioningLatencyTracker{}
+	tracker.record(api.ServiceLevelStandard, "CP1", 30*time.Second)
+	tracker.record(api.ServiceLevelStandard, "CP1", 10*time.Second)
+	tracker.record(api.ServiceLevelStandard, "CP2", 50*time.Second)
+
+	expected := map[string]map[string]ProvisioningLatency{
+		api.ServiceLevelStandard: {
+			"CP1": {
+				Count: 2, Total: 40 * time.Second, Min: 10 * time.Second, Max: 30 * time.Second,
+				Average: 20 * time.Second,
+			},
+			"CP2": {
+				Count: 1, Total: 50 * time.Second, Min: 50 * time.Second, Max: 50 * time.Second,
+				Average: 50 * time.Second,
+			},
+		},
+	}
+
+	assert.Equal(t, expected, tracker.byCapacityPool())
+}
+
+func TestProvisioningLatency_MetricsDisabled(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = false
+
+	tracker := &provisioningLatencyTracker{}
+	tracker.record(api.ServiceLevelStandard, "CP1", 30*time.Second)
+
+	assert.Empty(t, tracker.byServiceLevel())
+	assert.Nil(t, tracker.latencies, "latencies collected while metrics disabled")
+}
+
+func TestWaitForVolumeCreate_RecordsProvisioningLatency(t *testing.T) {
+	defer func(enabled bool) { tridentconfig.MetricsEnabled = enabled }(tridentconfig.MetricsEnabled)
+	tridentconfig.MetricsEnabled = true
+
+	mockAPI, driver := newMockANFDriver(t)
+
+	filesystem := &api.FileSystem{
+		Name:              "testvol1",
+		CreationToken:     "netapp-testvol1",
+		CapacityPool:      "CP1",
+		ServiceLevel:      api.ServiceLevelPremium,
+		ProvisioningState: api.StateCreating,
+	}
+
+	mockAPI.EXPECT().WaitForVolumeState(ctx, filesystem, api.StateAvailable, []string{api.StateError},
+		driver.volumeCreateTimeout).Return(api.StateAvailable, nil).Times(1)
+
+	result := driver.waitForVolumeCreate(ctx, filesystem)
+
+	assert.Nil(t, result)
+	latencies := driver.ProvisioningLatencyByCapacityPool()
+	assert.Equal(t, 1, latencies[api.ServiceLevelPremium]["CP1"].Count, "provisioning latency not recorded")
+}
+
+func TestVolumeUsageCache(t *testing.T) {
+	cache := &volumeUsageCache{}
+
+	_, ok := cache.get("vol1", time.Minute)
+	assert.False(t, ok, "expected cache miss")
+
+	usage := VolumeUsage{ProvisionedBytes: 100, UsedBytes: 40, SnapshotUsedBytes: 10}
+	cache.put("vol1", usage)
+
+	result, ok := cache.get("vol1", time.Minute)
+	assert.True(t, ok, "expected cache hit")
+	assert.Equal(t, usage, result)
+
+	_, ok = cache.get("vol1", 0)
+	assert.False(t, ok, "expected expired entry")
+
+	cache.remove("vol1")
+	_, ok = cache.get("vol1", time.Minute)
+	assert.False(t, ok, "expected cache miss after remove")
+}
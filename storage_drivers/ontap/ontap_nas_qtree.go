@@ -2301,6 +2301,13 @@ func (d *NASQtreeStorageDriver) Update(
 		return nil, err
 	}
 
+	if updateInfo.UpgradeNetworkFeatures {
+		msg := fmt.Sprintf("upgrading network features of volume %v is not supported", volConfig.Name)
+		err := errors.UnsupportedError(msg)
+		Logc(ctx).WithError(err).Error(updateGenericError)
+		return nil, err
+	}
+
 	// Get the qtree and parent flexvol
 	volumePattern, name, err := d.SetVolumePatternToFindQtree(ctx, volConfig.InternalID, volConfig.InternalName,
 		d.FlexvolNamePrefix())
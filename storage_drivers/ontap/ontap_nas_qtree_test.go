@@ -4507,7 +4507,15 @@ func TestNASQtreeStorageDriver_UpdateVolume_Failure(t *testing.T) {
 	assert.True(t, errors.IsInvalidInputError(resultErr))
 	assert.Nil(t, result)
 
-	// CASE 2: Invalid Internal ID
+	// CASE 2: Network features upgrade requested
+	result, resultErr = driver.Update(ctx, mockVol1.Config, &utils.VolumeUpdateInfo{UpgradeNetworkFeatures: true},
+		allVolumes)
+
+	assert.Error(t, resultErr)
+	assert.True(t, errors.IsUnsupportedError(resultErr))
+	assert.Nil(t, result)
+
+	// CASE 3: Invalid Internal ID
 	mockVol1.Config.InternalID = "invalid"
 
 	result, resultErr = driver.Update(ctx, mockVol1.Config, updateInfo, allVolumes)
@@ -4515,7 +4523,7 @@ func TestNASQtreeStorageDriver_UpdateVolume_Failure(t *testing.T) {
 	assert.Error(t, resultErr)
 	assert.Nil(t, result)
 
-	// CASE 3: Error in checking if qtree exists
+	// CASE 4: Error in checking if qtree exists
 	mockVol1.Config.InternalID = internalID1
 	mockAPI.EXPECT().QtreeExists(gomock.Any(), "trident_pvc_99138d85_6259_4830_ada0_30e45e21f854", gomock.Any()).Return(false, "", fakeErr)
 
@@ -4525,7 +4533,7 @@ func TestNASQtreeStorageDriver_UpdateVolume_Failure(t *testing.T) {
 	assert.Equal(t, fakeErr.Error(), resultErr.Error())
 	assert.Nil(t, result)
 
-	// CASE 4: Qtree does not exist
+	// CASE 5: Qtree does not exist
 	mockVol1.Config.InternalID = internalID1
 	mockAPI.EXPECT().QtreeExists(gomock.Any(), "trident_pvc_99138d85_6259_4830_ada0_30e45e21f854", gomock.Any()).Return(false, "", nil)
 
@@ -4535,7 +4543,7 @@ func TestNASQtreeStorageDriver_UpdateVolume_Failure(t *testing.T) {
 	assert.Equal(t, "volume trident_pvc_99138d85_6259_4830_ada0_30e45e21f854 does not exist", resultErr.Error())
 	assert.Nil(t, result)
 
-	// CASE 5: Error while modifying snapshot directory
+	// CASE 6: Error while modifying snapshot directory
 	mockVol1.Config.InternalID = internalID1
 	mockAPI.EXPECT().QtreeExists(gomock.Any(), "trident_pvc_99138d85_6259_4830_ada0_30e45e21f854", gomock.Any()).Return(true, "trident_qtree_pool_trident_XHPULXSCYE", nil)
 	mockAPI.EXPECT().VolumeModifySnapshotDirectoryAccess(gomock.Any(), "trident_qtree_pool_trident_XHPULXSCYE", false).Return(fakeErr)
@@ -443,6 +443,74 @@ type AzureNASStorageDriverConfig struct {
 	VolumeCreateTimeout string `json:"volumeCreateTimeout"`
 	SDKTimeout          string `json:"sdkTimeout"`
 	MaxCacheAge         string `json:"maxCacheAge"`
+	AllowVolumeShrink   bool   `json:"allowVolumeShrink"`
+	// NamespaceAnnotationTags maps namespace annotation keys to the ANF tag keys that should carry their values
+	NamespaceAnnotationTags map[string]string `json:"namespaceAnnotationTags"`
+	AutoExportPolicy        bool              `json:"autoExportPolicy"`
+	AutoExportCIDRs         []string          `json:"autoExportCIDRs"`
+	// DefaultUnixPermissionsMode controls the permissions of volumes for which none were requested or configured
+	DefaultUnixPermissionsMode string `json:"defaultUnixPermissionsMode"`
+	DefaultUnixPermissions     string `json:"defaultUnixPermissions"`
+	// ReplicationSchedule enables cross-region replication to volumes on this backend at the given interval
+	ReplicationSchedule string `json:"replicationSchedule"`
+	// CloneInheritSnapshotPolicy attaches the source volume's snapshot policy, if any, to new clones
+	CloneInheritSnapshotPolicy bool `json:"cloneInheritSnapshotPolicy"`
+	// ScaleThroughputOnResize changes the throughput of manual QoS volumes in proportion to their size on resize
+	ScaleThroughputOnResize bool `json:"scaleThroughputOnResize"`
+	// UseWorkloadIdentity requires authentication with an Azure workload identity, which is otherwise used only
+	// if a federated token file is found in the environment
+	UseWorkloadIdentity bool `json:"useWorkloadIdentity"`
+	// Names of the environment variables from which workload identity settings are read, if not the defaults
+	WorkloadIdentityTokenFileEnv string `json:"workloadIdentityTokenFileEnv"`
+	WorkloadIdentityClientIDEnv  string `json:"workloadIdentityClientIDEnv"`
+	WorkloadIdentityTenantIDEnv  string `json:"workloadIdentityTenantIDEnv"`
+	// UseManagedIdentity authenticates with the Azure managed identity of the host, if no client secret is set
+	UseManagedIdentity bool `json:"useManagedIdentity"`
+	// UserAssignedIdentityClientID selects a user-assigned managed identity; empty means the system-assigned one
+	UserAssignedIdentityClientID string `json:"userAssignedIdentityClientID"`
+	// EnforcePoolCapacity skips capacity pools without enough free space for a new volume
+	EnforcePoolCapacity bool `json:"enforcePoolCapacity"`
+	// ExportRuleValidation controls whether suspicious export rule addresses are accepted, warned about, or rejected
+	ExportRuleValidation string `json:"exportRuleValidation"`
+	// AllowOpenExportRule acknowledges export rules that allow access from any address, such as 0.0.0.0/0
+	AllowOpenExportRule bool `json:"allowOpenExportRule"`
+	// Cloud is the Azure environment hosting the backend, such as AzurePublicCloud or AzureUSGovernment
+	Cloud string `json:"cloud"`
+	// TagTridentVersion adds a tag recording the Trident version to volumes as they are created or cloned
+	TagTridentVersion bool `json:"tagTridentVersion"`
+	// DefaultServiceLevel is used for volumes whose service level is not set by the volume, pool, or backend
+	DefaultServiceLevel string `json:"defaultServiceLevel"`
+	// SDKMaxRetries and SDKRetryBackoff (in seconds) control the retries of failed or throttled Azure API calls
+	SDKMaxRetries   string `json:"sdkMaxRetries"`
+	SDKRetryBackoff string `json:"sdkRetryBackoff"`
+	// SDKRetryTimeout (in seconds) limits the time spent on an Azure API call, including all of its retries
+	SDKRetryTimeout string `json:"sdkRetryTimeout"`
+	// DisableSDKMetrics stops the collection of Azure API call metrics, even if Trident's metrics are enabled
+	DisableSDKMetrics bool `json:"disableSDKMetrics"`
+	// VolumeCreateConflictRetries limits how often a volume create request rejected with a 409 Conflict is
+	// repeated.  Requests issued in parallel with parallelCreatePools are never repeated.
+	VolumeCreateConflictRetries string `json:"volumeCreateConflictRetries"`
+	// ParallelCreatePools is how many candidate capacity pools a new volume is attempted in at once
+	ParallelCreatePools string `json:"parallelCreatePools"`
+	// CloneSnapshotReuseAge (in seconds), if set, lets a clone made without a source snapshot use the source
+	// volume's newest snapshot no older than this, rather than always creating a new snapshot
+	CloneSnapshotReuseAge string `json:"cloneSnapshotReuseAge"`
+	// FailedVolumeCleanup controls whether volumes whose creation failed are deleted (delete), only logged
+	// (dryRun), or left alone (disabled) once they have been failed for FailedVolumeCleanupAge (in seconds)
+	FailedVolumeCleanup    string `json:"failedVolumeCleanup"`
+	FailedVolumeCleanupAge string `json:"failedVolumeCleanupAge"`
+	// MinimumVolumeSize is the size to which smaller volume requests are increased, 100Gi unless lowered for
+	// regions that allow smaller volumes
+	MinimumVolumeSize string `json:"minimumVolumeSize"`
+	// BackgroundCacheRefresh refreshes the Azure resource cache periodically instead of during driver operations
+	BackgroundCacheRefresh bool `json:"backgroundCacheRefresh"`
+	// AsyncVolumeCreate returns from volume creates once ANF accepts them, leaving a background poller to watch
+	// the new volumes while Trident retries the creates; it is ignored in Docker
+	AsyncVolumeCreate bool `json:"asyncVolumeCreate"`
+	// CustomTags are added to every new volume, such as for cost allocation, but never replace Trident's own tags
+	CustomTags map[string]string `json:"customTags"`
+	// KerberosNfsVersion is the NFS version of Kerberos volumes and of their default mount options
+	KerberosNfsVersion string `json:"kerberosNfsVersion"`
 	AzureNASStorageDriverPool
 	Storage []AzureNASStorageDriverPool `json:"storage"`
 }
@@ -464,6 +532,22 @@ type AzureNASStorageDriverPool struct {
 	FilePoolVolumes                     []string            `json:"filePoolVolumes"`
 	NASType                             string              `json:"nasType"`
 	Kerberos                            string              `json:"kerberos"`
+	SubnetSelection                     string              `json:"subnetSelection"`
+	SnapshotPolicy                      string              `json:"snapshotPolicy"`
+	CoolAccess                          string              `json:"coolAccess"`
+	CoolnessPeriod                      string              `json:"coolnessPeriod"`
+	CoolAccessRetrievalPolicy           string              `json:"coolAccessRetrievalPolicy"`
+	Throughput                          string              `json:"throughput"`
+	SnapshotReserve                     string              `json:"snapshotReserve"`
+	BackupEnabled                       string              `json:"backupEnabled"`
+	BackupPolicy                        string              `json:"backupPolicy"`
+	LDAPEnabled                         string              `json:"ldapEnabled"`
+	HasRootAccess                       string              `json:"hasRootAccess"`
+	ChownMode                           string              `json:"chownMode"`
+	SplitOnClone                        string              `json:"splitOnClone"`
+	SMBContinuousAvailability           string              `json:"smbContinuousAvailability"`
+	SMBEncryption                       string              `json:"smbEncryption"`
+	LargeVolume                         string              `json:"largeVolume"`
 	AzureNASStorageDriverConfigDefaults `json:"defaults"`
 }
 
@@ -488,9 +572,25 @@ type AzureNASStorageDriverConfigDefaults struct {
 	ExportRule      string `json:"exportRule"`
 	SnapshotDir     string `json:"snapshotDir"`
 	UnixPermissions string `json:"unixPermissions"`
+	// ExportRules, if specified, supersede ExportRule and allow distinct access for different clients
+	ExportRules []AzureNASExportRule `json:"exportRules"`
 	CommonStorageDriverConfigDefaults
 }
 
+// AzureNASExportRule is an export policy rule for ANF NFS volumes.  A rule applies to both NFS versions
+// unless one is specified, and grants read-write access unless read-only access is specified.  CIDR, Access
+// (ro or rw), and Protocol (nfsv3 or nfsv4.1) are shorthand for the clients, access, and NFS version fields.
+type AzureNASExportRule struct {
+	AllowedClients string `json:"allowedClients"`
+	UnixReadOnly   bool   `json:"unixReadOnly"`
+	UnixReadWrite  bool   `json:"unixReadWrite"`
+	Nfsv3          bool   `json:"nfsv3"`
+	Nfsv41         bool   `json:"nfsv41"`
+	CIDR           string `json:"cidr,omitempty"`
+	Access         string `json:"access,omitempty"`
+	Protocol       string `json:"protocol,omitempty"`
+}
+
 // Implement stringer interface for the AzureNASStorageDriverConfig driver
 func (d AzureNASStorageDriverConfig) String() string {
 	return utils.ToStringRedacted(&d, []string{"SubscriptionID", "TenantID", "ClientID", "ClientSecret"}, nil)
@@ -154,8 +154,9 @@ func (v *VolumePublication) ConstructExternal() *VolumePublicationExternal {
 }
 
 type VolumeUpdateInfo struct {
-	SnapshotDirectory string `json:"snapshotDirectory"`
-	PoolLevel         bool   `json:"poolLevel"`
+	SnapshotDirectory      string `json:"snapshotDirectory"`
+	PoolLevel              bool   `json:"poolLevel"`
+	UpgradeNetworkFeatures bool   `json:"upgradeNetworkFeatures"`
 }
 
 type Node struct {